@@ -0,0 +1,264 @@
+// Package apiversion serves version 2 of the API, which differs from version 1 only in how
+// nullable fields are written: version 1 writes the sql.Null* wrappers as encoding/json does,
+// e.g. {"String": "London", "Valid": true}, and version 2 writes them as plain values or null.
+package apiversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	// V2MediaType selects version 2 in an Accept header
+	V2MediaType = "application/vnd.data-co.v2+json"
+	// v2Prefix selects version 2 in a request path; it is served by the version 1 routes
+	v2Prefix = "/api/v2/"
+)
+
+// Handler serves version 2 requests, /api/v2/... paths or those accepting V2MediaType, through
+// the version 1 routes of next, rewriting nullable fields in their JSON responses. Other
+// requests are passed through unchanged.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v2 := acceptsV2(r.Header.Values("Accept"))
+		if rest, ok := strings.CutPrefix(r.URL.Path, v2Prefix); ok {
+			r.URL.Path = "/api/" + rest
+			r.URL.RawPath = ""
+			v2 = true
+		}
+		w.Header().Add("Vary", "Accept")
+		if !v2 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		vw := &versionWriter{w: w}
+		next.ServeHTTP(vw, r)
+		vw.finish()
+	})
+}
+
+// acceptsV2 reports whether Accept header values include V2MediaType
+func acceptsV2(accept []string) bool {
+	for _, value := range accept {
+		for _, part := range strings.Split(value, ",") {
+			if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part)); err == nil && mediaType == V2MediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Ways a versionWriter handles a response body, decided by its Content-Type
+const (
+	modeUndecided = iota
+	modeDocument  // application/json: held until the handler returns, then rewritten
+	modeLines     // application/x-ndjson: each line rewritten as it is completed
+	modePassThrough
+)
+
+// versionWriter rewrites the JSON a version 1 handler writes. Headers are set on the
+// underlying writer directly.
+type versionWriter struct {
+	w       http.ResponseWriter
+	mode    int
+	status  int
+	body    bytes.Buffer // The document, in modeDocument
+	pending []byte       // The incomplete last line, in modeLines
+}
+
+func (v *versionWriter) Header() http.Header { return v.w.Header() }
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (v *versionWriter) Unwrap() http.ResponseWriter { return v.w }
+
+func (v *versionWriter) WriteHeader(status int) {
+	if v.mode != modeUndecided {
+		return
+	}
+	v.status = status
+	mediaType, _, _ := mime.ParseMediaType(v.w.Header().Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		v.mode = modeDocument
+		return
+	case "application/x-ndjson":
+		v.mode = modeLines
+	default:
+		v.mode = modePassThrough
+	}
+	v.w.WriteHeader(status)
+}
+
+func (v *versionWriter) Write(p []byte) (int, error) {
+	if v.mode == modeUndecided {
+		v.WriteHeader(http.StatusOK)
+	}
+	switch v.mode {
+	case modeDocument:
+		return v.body.Write(p)
+	case modeLines:
+		v.pending = append(v.pending, p...)
+		for {
+			end := bytes.IndexByte(v.pending, '\n')
+			if end < 0 {
+				break
+			}
+			line, err := Clean(v.pending[:end])
+			if err != nil {
+				line = v.pending[:end+1]
+			}
+			v.pending = v.pending[end+1:]
+			if _, err := v.w.Write(line); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	default:
+		return v.w.Write(p)
+	}
+}
+
+// Flush sends completed lines and passed-through writes; documents are only sent by finish
+func (v *versionWriter) Flush() error {
+	if v.mode == modeDocument {
+		return nil
+	}
+	return http.NewResponseController(v.w).Flush()
+}
+
+// finish sends what is still held once the handler has returned
+func (v *versionWriter) finish() {
+	switch v.mode {
+	case modeDocument:
+		body := v.body.Bytes()
+		if cleaned, err := Clean(body); err == nil {
+			body = cleaned
+			v.w.Header().Set("Content-Type", V2MediaType)
+		}
+		v.w.Header().Del("Content-Length")
+		v.w.WriteHeader(v.status)
+		v.w.Write(body)
+	case modeLines:
+		v.w.Write(v.pending)
+	}
+}
+
+// nullValueKeys are the value fields of the sql.Null* wrappers, written alongside "Valid"
+var nullValueKeys = map[string]bool{
+	"String": true, "Float64": true, "Int64": true, "Int32": true, "Int16": true, "Byte": true, "Bool": true, "Time": true,
+}
+
+// Clean rewrites every sql.Null* wrapper in a JSON document, an object with exactly a "Valid"
+// member and one of nullValueKeys, as its value when valid and null otherwise. Everything else,
+// including the order of object members, is kept.
+func Clean(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out bytes.Buffer
+	if err := cleanValue(dec, &out); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// cleanValue copies the next JSON value from dec to out, rewriting sql.Null* wrappers
+func cleanValue(dec *json.Decoder, out *bytes.Buffer) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('['):
+		out.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := cleanValue(dec, out); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		out.WriteByte(']')
+		return nil
+	case json.Delim('{'):
+		return cleanObject(dec, out)
+	}
+
+	encoded, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	out.Write(encoded)
+	return nil
+}
+
+// cleanObject copies the members of an object whose opening brace has been read
+func cleanObject(dec *json.Decoder, out *bytes.Buffer) error {
+	var keys []string
+	var values [][]byte
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("object key is not a string")
+		}
+		var value bytes.Buffer
+		if err := cleanValue(dec, &value); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values = append(values, value.Bytes())
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	if len(keys) == 2 && (keys[0] == "Valid" || keys[1] == "Valid") {
+		valid, value := 1, 0
+		if keys[0] == "Valid" {
+			valid, value = 0, 1
+		}
+		if nullValueKeys[keys[value]] {
+			if string(values[valid]) == "true" {
+				out.Write(values[value])
+			} else {
+				out.WriteString("null")
+			}
+			return nil
+		}
+	}
+
+	out.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		encoded, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		out.Write(encoded)
+		out.WriteByte(':')
+		out.Write(values[i])
+	}
+	out.WriteByte('}')
+	return nil
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Principal identifies the caller of an authenticated request
+type Principal struct {
+	// KeyID is the api_keys row for key-authenticated callers, or 0 for JWT and bootstrap callers
+	KeyID int
+	Name  string
+	Role  Role
+	// OrgID is the organization the caller acts for, or 0 outside any organization
+	OrgID int
+	// Tier selects the caller's rate limits
+	Tier string
+	// Monthly quotas for key-authenticated callers (nil = unlimited)
+	MonthlyRequestQuota *int64
+	MonthlyRowQuota     *int64
+}
+
+type contextKey struct{}
+
+// Organization returns the organization the principal acts for, or nil for platform callers
+// outside any organization, who may manage every organization
+func (p *Principal) Organization() *int {
+	if p == nil || p.OrgID == 0 {
+		return nil
+	}
+	return &p.OrgID
+}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal of the request, or nil for anonymous requests
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(contextKey{}).(*Principal)
+	return principal
+}
+
+// OwnerID returns the tenant whose stored objects the principal sees: its organization, or the
+// principal itself outside one. Anonymous requests (authentication disabled) share the empty owner.
+func (p *Principal) OwnerID() string {
+	switch {
+	case p == nil:
+		return ""
+	case p.OrgID != 0:
+		return fmt.Sprintf("org:%d", p.OrgID)
+	case p.KeyID != 0:
+		return fmt.Sprintf("key:%d", p.KeyID)
+	default:
+		return "sub:" + p.Name
+	}
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"data-co/api/config"
+)
+
+// jwtValidator verifies bearer JWTs signed with HS256 and/or RS256
+type jwtValidator struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	roleClaim  string
+	keyTier    func(string) bool // Reports whether a tier claim names a tier keys can have
+	parser     *jwt.Parser
+}
+
+// newJWTValidator builds a validator from config, or returns nil when no signing key is
+// configured. Tier claims keyTier rejects get the standard tier.
+func newJWTValidator(cfg config.JWTConfig, keyTier func(string) bool) (*jwtValidator, error) {
+	if cfg.HMACSecret == "" && cfg.RSAPublicKeyFile == "" {
+		return nil, nil
+	}
+
+	v := &jwtValidator{roleClaim: cfg.RoleClaim, keyTier: keyTier}
+	methods := make([]string, 0, 2)
+
+	if cfg.HMACSecret != "" {
+		v.hmacSecret = []byte(cfg.HMACSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if cfg.RSAPublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+		v.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(options...)
+
+	return v, nil
+}
+
+// validate verifies a token and maps its subject and role claim to a principal
+func (v *jwtValidator) validate(tokenString string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.Alg() {
+		case jwt.SigningMethodHS256.Alg():
+			return v.hmacSecret, nil
+		case jwt.SigningMethodRS256.Alg():
+			return v.rsaKey, nil
+		}
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims.GetSubject()
+
+	role, ok := highestRole(claimStrings(claims[v.roleClaim]))
+	if !ok {
+		return nil, fmt.Errorf("token has no recognised %q claim", v.roleClaim)
+	}
+
+	tier, _ := claims["tier"].(string)
+	if !v.keyTier(tier) {
+		tier = "standard"
+	}
+
+	return &Principal{Name: subject, Role: role, Tier: tier}, nil
+}
+
+// claimStrings accepts a role claim given either as a string or an array of strings
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"data-co/api/config"
+)
+
+func TestJWTTierClaim(t *testing.T) {
+	keyTier := func(tier string) bool { return tier == "standard" || tier == "premium" }
+	v, err := newJWTValidator(config.JWTConfig{HMACSecret: "secret", RoleClaim: "role"}, keyTier)
+	if err != nil {
+		t.Fatalf("newJWTValidator: %v", err)
+	}
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   string
+	}{
+		{"configured tier", jwt.MapClaims{"tier": "premium"}, "premium"},
+		{"no tier", jwt.MapClaims{}, "standard"},
+		{"unknown tier", jwt.MapClaims{"tier": "unlimited"}, "standard"},
+		{"not a string", jwt.MapClaims{"tier": 3}, "standard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims["sub"] = "alice"
+			tt.claims["role"] = "reader"
+			tt.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString([]byte("secret"))
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			principal, err := v.validate(token)
+			if err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			if principal.Tier != tt.want {
+				t.Errorf("tier = %q, want %q", principal.Tier, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// keyPrefix marks Data-Co API keys so they are recognisable in logs and secret scanners
+const keyPrefix = "dco_"
+
+// displayPrefixLength is how much of a key is kept in clear for identifying it in listings
+const displayPrefixLength = len(keyPrefix) + 8
+
+// GenerateKey creates a new random API key, returning the plaintext key,
+// its display prefix and the hash to store
+func GenerateKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key = keyPrefix + hex.EncodeToString(buf)
+	return key, key[:displayPrefixLength], HashKey(key), nil
+}
+
+// HashKey returns the hex-encoded SHA-256 hash of a plaintext key
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
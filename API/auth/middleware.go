@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// Authenticator validates API keys and JWTs on incoming requests
+type Authenticator struct {
+	db       *database.DB
+	enabled  bool
+	adminKey string
+	jwt      *jwtValidator
+	public   map[string]bool
+}
+
+// NewAuthenticator creates an authenticator. keyTier reports whether a JWT's tier claim names a
+// tier keys can have (see ratelimit.Limiter.KeyTier). Requests to publicPaths are never
+// authenticated.
+func NewAuthenticator(db *database.DB, cfg config.AuthConfig, keyTier func(string) bool, publicPaths ...string) (*Authenticator, error) {
+	public := make(map[string]bool, len(publicPaths))
+	for _, path := range publicPaths {
+		public[path] = true
+	}
+
+	validator, err := newJWTValidator(cfg.JWT, keyTier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Authenticator{
+		db:       db,
+		enabled:  cfg.Enabled,
+		adminKey: cfg.AdminAPIKey,
+		jwt:      validator,
+		public:   public,
+	}, nil
+}
+
+// Middleware rejects requests without valid credentials and attaches the caller's principal
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.enabled || r.Method == http.MethodOptions || a.public[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		credential, isJWT := extractCredential(r)
+		if credential == "" {
+			respondWithError(w, http.StatusUnauthorized, "Missing credentials", "Provide an API key or JWT via 'Authorization: Bearer <token>', or an API key via 'X-API-Key'")
+			return
+		}
+
+		if isJWT {
+			if a.jwt == nil {
+				respondWithError(w, http.StatusUnauthorized, "Invalid credentials", "JWT authentication is not configured")
+				return
+			}
+			principal, err := a.jwt.validate(credential)
+			if err != nil {
+				respondWithError(w, http.StatusUnauthorized, "Invalid token", err.Error())
+				return
+			}
+			orgID, found, err := a.db.UserOrganization(r.Context(), principal.Name)
+			if err != nil {
+				log.Printf("User lookup error: %v", err)
+				respondWithError(w, http.StatusInternalServerError, "Failed to authenticate request", err.Error())
+				return
+			}
+			if !found {
+				// Only subjects added as a user are known; any other would be a platform admin
+				respondWithError(w, http.StatusUnauthorized, "Invalid token", "The token's subject is not a user of any organization")
+				return
+			}
+			principal.OrgID = orgID
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
+			return
+		}
+
+		principal, err := a.authenticateKey(r, credential)
+		if err != nil {
+			log.Printf("API key lookup error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to authenticate request", err.Error())
+			return
+		}
+		if principal == nil {
+			respondWithError(w, http.StatusUnauthorized, "Invalid API key", "The key is unknown or has been revoked")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
+	})
+}
+
+// RequireRole only allows requests from principals holding at least the given role through
+func (a *Authenticator) RequireRole(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.enabled {
+			principal := FromContext(r.Context())
+			if principal == nil || !principal.Role.Includes(role) {
+				respondWithError(w, http.StatusForbidden, "Forbidden", fmt.Sprintf("This endpoint requires the %q role", role))
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// RequirePlatformAdmin only allows admins outside any organization through, for endpoints
+// whose effects are shared by every organization
+func (a *Authenticator) RequirePlatformAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return a.RequireRole(RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		if a.enabled && FromContext(r.Context()).OrgID != 0 {
+			respondWithError(w, http.StatusForbidden, "Forbidden", "This endpoint is not available to organization admins")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// authenticateKey resolves a plaintext API key to a principal, or nil if the key is not valid
+func (a *Authenticator) authenticateKey(r *http.Request, key string) (*Principal, error) {
+	// The bootstrap admin key from the environment is never stored in the database
+	if a.adminKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(a.adminKey)) == 1 {
+		return &Principal{Name: "bootstrap-admin", Role: RoleAdmin, Tier: "standard"}, nil
+	}
+
+	apiKey, err := a.db.AuthenticateAPIKey(r.Context(), HashKey(key))
+	if err != nil || apiKey == nil {
+		return nil, err
+	}
+
+	role, ok := ParseRole(apiKey.Role)
+	if !ok {
+		return nil, fmt.Errorf("api key %d has unknown role %q", apiKey.ID, apiKey.Role)
+	}
+
+	principal := &Principal{
+		KeyID:               apiKey.ID,
+		Name:                apiKey.Name,
+		Role:                role,
+		Tier:                apiKey.Tier,
+		MonthlyRequestQuota: apiKey.MonthlyRequestQuota,
+		MonthlyRowQuota:     apiKey.MonthlyRowQuota,
+	}
+	if apiKey.OrganizationID != nil {
+		principal.OrgID = *apiKey.OrganizationID
+	}
+	return principal, nil
+}
+
+// extractCredential reads the caller's credential from the Authorization or X-API-Key header.
+// Bearer tokens that are not Data-Co API keys are treated as JWTs.
+func extractCredential(r *http.Request) (credential string, isJWT bool) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			token = strings.TrimSpace(token)
+			return token, !strings.HasPrefix(token, keyPrefix) && strings.Count(token, ".") == 2
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key")), false
+}
+
+func respondWithError(w http.ResponseWriter, statusCode int, error string, message string) {
+	response, _ := json.Marshal(models.ErrorResponse{
+		Error:   error,
+		Message: message,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(response)
+}
@@ -0,0 +1,43 @@
+package auth
+
+// Role is an access level granted to an API key or JWT subject
+type Role string
+
+const (
+	// RoleReader can search and read company data
+	RoleReader Role = "reader"
+	// RoleExporter can additionally run exports
+	RoleExporter Role = "exporter"
+	// RoleAdmin can additionally use admin endpoints
+	RoleAdmin Role = "admin"
+)
+
+// roleRank orders roles so that higher roles include the permissions of lower ones
+var roleRank = map[Role]int{
+	RoleReader:   1,
+	RoleExporter: 2,
+	RoleAdmin:    3,
+}
+
+// ParseRole converts a string to a Role, reporting whether it is a known role
+func ParseRole(value string) (Role, bool) {
+	role := Role(value)
+	_, ok := roleRank[role]
+	return role, ok
+}
+
+// Includes reports whether r grants at least the permissions of required
+func (r Role) Includes(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
+
+// highestRole returns the most privileged known role in values
+func highestRole(values []string) (Role, bool) {
+	var best Role
+	for _, value := range values {
+		if role, ok := ParseRole(value); ok && roleRank[role] > roleRank[best] {
+			best = role
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,66 @@
+// Package certs obtains and renews the API server's TLS certificates from an ACME CA such as
+// Let's Encrypt, using autocert. Certificates are obtained for each configured domain on its
+// first handshake and renewed 30 days before they expire.
+package certs
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"data-co/api/config"
+)
+
+// Manager serves certificates for the configured domains. Certificates and the ACME account key
+// are kept in the cache directory, so restarts reuse them.
+type Manager struct {
+	*autocert.Manager
+	httpPort string
+}
+
+// NewManager creates a certificate manager. It returns nil when no autocert domains are
+// configured.
+func NewManager(cfg config.TLSConfig) (*Manager, error) {
+	if len(cfg.AutocertDomains) == 0 {
+		return nil, nil
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		return nil, errors.New("TLS_AUTOCERT_DOMAINS cannot be combined with TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	return &Manager{
+		Manager: &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+			Client:     &acme.Client{DirectoryURL: cfg.AutocertDirectoryURL},
+		},
+		httpPort: cfg.AutocertHTTPPort,
+	}, nil
+}
+
+// TLSConfig returns a server TLS configuration serving the managed certificates and answering
+// tls-alpn-01 challenges
+func (m *Manager) TLSConfig() *tls.Config {
+	cfg := m.Manager.TLSConfig()
+	cfg.MinVersion = tls.VersionTLS12
+	return cfg
+}
+
+// ChallengeServer returns a plain HTTP server answering http-01 challenges on the configured
+// port and redirecting every other request to HTTPS, or nil when the port is 0
+func (m *Manager) ChallengeServer() *http.Server {
+	if m.httpPort == "0" {
+		return nil
+	}
+	return &http.Server{
+		Addr:              ":" + m.httpPort,
+		Handler:           m.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,172 @@
+// Package client is a Go client for the Data-Co API, for internal services that would
+// otherwise call it over raw HTTP.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/models"
+)
+
+// maxRetries bounds how often a rate-limited or failed request is retried
+const maxRetries = 5
+
+// ErrNotFound is returned when a company does not exist
+var ErrNotFound = errors.New("not found")
+
+// APIError is an error response from the API
+type APIError struct {
+	StatusCode int
+	Err        string // Short error, e.g. "Invalid request body"
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("data-co api: %d %s", e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("data-co api: %d %s: %s", e.StatusCode, e.Err, e.Message)
+}
+
+// Client calls the Data-Co API
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the API at baseURL (e.g. http://localhost:8080). token is an
+// API key or JWT, sent as a bearer token; it may be empty when authentication is disabled.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// SetHTTPClient replaces the HTTP client used for requests, e.g. to change the timeout
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.http = hc
+}
+
+// Search returns one page of companies matching filters
+func (c *Client) Search(ctx context.Context, filters models.CompanySearchFilters) (*models.SearchResponse, error) {
+	var resp models.SearchResponse
+	if err := c.do(ctx, http.MethodPost, "/api/companies/search", filters, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Count returns the number of companies matching filters
+func (c *Client) Count(ctx context.Context, filters models.CompanySearchFilters) (*models.CountResponse, error) {
+	var resp models.CountResponse
+	if err := c.do(ctx, http.MethodPost, "/api/companies/count", filters, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Get returns a company by its Companies House number, or ErrNotFound
+func (c *Client) Get(ctx context.Context, companyNumber string) (*models.Company, error) {
+	var company models.Company
+	if err := c.do(ctx, http.MethodGet, "/api/companies/number/"+url.PathEscape(companyNumber), nil, &company); err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+// Batch returns up to 500 companies by number, in the order requested, with the numbers that
+// do not exist in NotFound
+func (c *Client) Batch(ctx context.Context, companyNumbers []string) (*models.BatchResponse, error) {
+	var resp models.BatchResponse
+	if err := c.do(ctx, http.MethodPost, "/api/companies/batch", models.BatchRequest{CompanyNumbers: companyNumbers}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// do sends a request with body encoded as JSON and decodes the response into v. Rate-limited
+// (429) and server error responses are retried with backoff.
+func (c *Client) do(ctx context.Context, method, path string, body, v any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("invalid request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || attempt == maxRetries {
+				return fmt.Errorf("%s %s failed: %w", method, path, err)
+			}
+		} else {
+			retry, err := decode(resp, v)
+			if !retry || attempt == maxRetries {
+				return err
+			}
+			if wait, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+				delay = time.Duration(wait) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, time.Minute)
+	}
+}
+
+// decode reads a response into v, reporting whether the request should be retried
+func decode(resp *http.Response, v any) (bool, error) {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return false, fmt.Errorf("invalid response: %w", err)
+		}
+		return false, nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		io.Copy(io.Discard, resp.Body)
+		return false, ErrNotFound
+	}
+
+	apiErr := &APIError{StatusCode: resp.StatusCode, Err: resp.Status}
+	var errResp models.ErrorResponse
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+		apiErr.Err, apiErr.Message = errResp.Error, errResp.Message
+	}
+	// A used-up monthly quota also responds 429, but will not clear by retrying
+	retry := (resp.StatusCode == http.StatusTooManyRequests && apiErr.Err != "Quota exceeded") || resp.StatusCode >= 500
+	return retry, apiErr
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/models"
+)
+
+// ExportColumns are the CSV columns written by Export
+var ExportColumns = []string{
+	"company_number", "company_name", "company_status", "company_type", "locality", "region", "postal_code",
+	"latitude", "longitude", "primary_sic_code", "incorporation_date", "dissolved_on", "turnover", "profit_after_tax", "total_assets",
+	"net_worth", "latest_accounts_date", "accounts_category", "next_accounts_due",
+	"confirmation_statement_last_made_up_to", "confirmation_statement_next_due", "active_officers_count", "health", "risk_band",
+}
+
+// Export writes every company matching filters to w as CSV, paging through the search, and
+// returns the number of companies written
+func (c *Client) Export(ctx context.Context, filters models.CompanySearchFilters, w io.Writer) (int, error) {
+	out := csv.NewWriter(w)
+	if err := out.Write(ExportColumns); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	it := c.Companies(ctx, filters)
+	for it.Next() {
+		if err := out.Write(CSVRecord(it.Company())); err != nil {
+			return written, err
+		}
+		written++
+	}
+	if err := it.Err(); err != nil {
+		return written, err
+	}
+
+	out.Flush()
+	return written, out.Error()
+}
+
+// CSVRecord formats a company as a row of ExportColumns. Missing values are empty.
+func CSVRecord(c models.Company) []string {
+	return []string{
+		c.CompanyNumber, c.CompanyName, c.CompanyStatus, c.CompanyType.String, c.Locality.String, c.Region.String, c.PostalCode.String,
+		formatCoordinate(c.Latitude), formatCoordinate(c.Longitude), c.PrimarySICCode.String, formatDate(c.IncorporationDate), formatDate(c.DissolvedOn), formatFloat(c.Turnover.Float64, c.Turnover.Valid),
+		formatFloat(c.ProfitAfterTax.Float64, c.ProfitAfterTax.Valid), formatFloat(c.TotalAssets.Float64, c.TotalAssets.Valid),
+		formatFloat(c.NetWorth.Float64, c.NetWorth.Valid), formatDate(c.LatestAccountsDate), c.AccountsCategory.String, formatDate(c.NextAccountsDue),
+		formatDate(c.ConfStmtLastMadeUp), formatDate(c.ConfStmtNextDue),
+		strconv.Itoa(c.ActiveOfficersCount), c.Health.String, c.RiskBand.String,
+	}
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func formatCoordinate(v sql.NullFloat64) string {
+	if !v.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(v.Float64, 'f', -1, 64)
+}
+
+func formatFloat(v float64, valid bool) string {
+	if !valid {
+		return ""
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.2f", v), ".00")
+}
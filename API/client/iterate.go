@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+
+	"data-co/api/models"
+)
+
+// defaultPageSize is the page size Companies requests when filters set no limit
+const defaultPageSize = 500
+
+// Iterator pages through search results. Use it as:
+//
+//	it := c.Companies(ctx, filters)
+//	for it.Next() {
+//		company := it.Company()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator struct {
+	client  *Client
+	ctx     context.Context
+	filters models.CompanySearchFilters
+	page    []models.Company
+	index   int
+	done    bool
+	err     error
+	total   int
+}
+
+// Companies returns an iterator over every company matching filters, starting at
+// filters.Offset and requesting filters.Limit companies per page
+func (c *Client) Companies(ctx context.Context, filters models.CompanySearchFilters) *Iterator {
+	if filters.Limit <= 0 {
+		filters.Limit = defaultPageSize
+	}
+	return &Iterator{client: c, ctx: ctx, filters: filters, index: -1}
+}
+
+// Next advances to the next company, fetching the next page when needed. It returns false when
+// the results are exhausted or a request failed.
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.index++
+	if it.index < len(it.page) {
+		return true
+	}
+	if it.done {
+		return false
+	}
+
+	resp, err := it.client.Search(it.ctx, it.filters)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page, it.index = resp.Companies, 0
+	if resp.Total != nil {
+		it.total = *resp.Total
+	}
+	it.filters.Offset += len(resp.Companies)
+	it.done = !resp.HasMore || len(resp.Companies) == 0
+	return len(it.page) > 0
+}
+
+// Company returns the current company
+func (it *Iterator) Company() models.Company {
+	return it.page[it.index]
+}
+
+// Total returns the total matching companies reported by the last page fetched
+func (it *Iterator) Total() int {
+	return it.total
+}
+
+// Err returns the error that stopped iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
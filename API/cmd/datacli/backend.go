@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"data-co/api/client"
+	"data-co/api/companieshouse"
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// exportPageSize is the page size used when exporting from the database
+const exportPageSize = 1000
+
+// backend runs queries against the API or, offline, the database
+type backend interface {
+	search(ctx context.Context, filters models.CompanySearchFilters) (*models.SearchResponse, error)
+	count(ctx context.Context, filters models.CompanySearchFilters) (int, error)
+	get(ctx context.Context, companyNumber string) (*models.Company, error)
+	// export calls write for every company matching filters and returns how many there were
+	export(ctx context.Context, filters models.CompanySearchFilters, write func(models.Company) error) (int, error)
+	close()
+}
+
+func connect(ctx context.Context, opts *options) (backend, error) {
+	if !opts.offline {
+		return apiBackend{client.NewClient(opts.api, opts.token)}, nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	// Exports can take longer than an API query is allowed to
+	cfg.Database.StatementTimeout = 0
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return dbBackend{db}, nil
+}
+
+type apiBackend struct {
+	client *client.Client
+}
+
+func (b apiBackend) search(ctx context.Context, filters models.CompanySearchFilters) (*models.SearchResponse, error) {
+	return b.client.Search(ctx, filters)
+}
+
+func (b apiBackend) count(ctx context.Context, filters models.CompanySearchFilters) (int, error) {
+	resp, err := b.client.Count(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Total, nil
+}
+
+func (b apiBackend) get(ctx context.Context, companyNumber string) (*models.Company, error) {
+	company, err := b.client.Get(ctx, companyNumber)
+	if errors.Is(err, client.ErrNotFound) {
+		return nil, fmt.Errorf("company %s not found", companyNumber)
+	}
+	return company, err
+}
+
+func (b apiBackend) export(ctx context.Context, filters models.CompanySearchFilters, write func(models.Company) error) (int, error) {
+	written := 0
+	it := b.client.Companies(ctx, filters)
+	for it.Next() {
+		if err := write(it.Company()); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, it.Err()
+}
+
+func (b apiBackend) close() {}
+
+// dbBackend queries the database directly, applying the same defaults as the API search
+type dbBackend struct {
+	db *database.DB
+}
+
+func (b dbBackend) search(ctx context.Context, filters models.CompanySearchFilters) (*models.SearchResponse, error) {
+	filters, err := withDefaults(filters)
+	if err != nil {
+		return nil, err
+	}
+	companies, err := b.db.FindCompanies(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	total, err := b.db.CountCompanies(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SearchResponse{
+		Companies: companies,
+		Total:     &total,
+		Limit:     filters.Limit,
+		Offset:    filters.Offset,
+		HasMore:   filters.Offset+len(companies) < total,
+	}, nil
+}
+
+func (b dbBackend) count(ctx context.Context, filters models.CompanySearchFilters) (int, error) {
+	filters, err := withDefaults(filters)
+	if err != nil {
+		return 0, err
+	}
+	return b.db.CountCompanies(ctx, filters)
+}
+
+func (b dbBackend) get(ctx context.Context, companyNumber string) (*models.Company, error) {
+	number := companieshouse.NormalizeCompanyNumber(companyNumber)
+	company, err := b.db.GetCompanyByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if company == nil {
+		return nil, fmt.Errorf("company %s not found", number)
+	}
+	return company, nil
+}
+
+func (b dbBackend) export(ctx context.Context, filters models.CompanySearchFilters, write func(models.Company) error) (int, error) {
+	filters, err := withDefaults(filters)
+	if err != nil {
+		return 0, err
+	}
+	filters.Limit = exportPageSize
+	written := 0
+	for {
+		companies, err := b.db.FindCompanies(ctx, filters)
+		if err != nil {
+			return written, err
+		}
+		for _, c := range companies {
+			if err := write(c); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if len(companies) < filters.Limit {
+			return written, nil
+		}
+		filters.Offset += len(companies)
+	}
+}
+
+func (b dbBackend) close() {
+	b.db.Close()
+}
+
+// withDefaults applies the API search defaults, and rejects filters the API would reject
+func withDefaults(filters models.CompanySearchFilters) (models.CompanySearchFilters, error) {
+	if filters.Limit == 0 {
+		filters.Limit = 100
+	}
+	if filters.CompanyStatus == "" {
+		filters.CompanyStatus = "active"
+	}
+	if err := database.InvalidFiltersError(database.ValidateFilters(filters)); err != nil {
+		return filters, fmt.Errorf("invalid filters: %w", err)
+	}
+	return filters, nil
+}
@@ -0,0 +1,357 @@
+// Command datacli searches, counts, fetches and exports companies from the command line, for
+// scripted access without writing code. It calls the API, or with -offline queries the staging
+// database directly using the API's database settings.
+//
+// Usage:
+//
+//	go run ./cmd/datacli search [flags] [FILTERS]
+//	go run ./cmd/datacli count [flags] [FILTERS]
+//	go run ./cmd/datacli get [flags] COMPANY_NUMBER
+//	go run ./cmd/datacli export [flags] [FILTERS]
+//
+// Output is a table, CSV or JSON (-format); export writes CSV unless asked otherwise. The API
+// address and key come from -api and -token, or DATA_CO_API_URL and DATA_CO_API_KEY.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"data-co/api/models"
+)
+
+// options are the flags shared by every subcommand
+type options struct {
+	api     string
+	token   string
+	offline bool
+	format  string
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	command, args := os.Args[1], os.Args[2:]
+
+	// Load environment variables from .env file if it exists
+	_ = godotenv.Load("../.env") // Ignore error, env vars may come from the shell
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch command {
+	case "search":
+		err = runSearch(ctx, args)
+	case "count":
+		err = runCount(ctx, args)
+	case "get":
+		err = runGet(ctx, args)
+	case "export":
+		err = runExport(ctx, args)
+	case "help", "-h", "-help", "--help":
+		usage(os.Stdout)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "datacli: unknown command %q\n\n", command)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "datacli %s: %v\n", command, err)
+		os.Exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: datacli COMMAND [flags]
+
+Commands:
+  search   print one page of companies matching the filters
+  count    print the number of companies matching the filters
+  get NUM  print one company by company number
+  export   write every company matching the filters
+
+Run "datacli COMMAND -h" for a command's flags.
+`)
+}
+
+// newFlagSet creates a subcommand's flag set with the shared flags
+func newFlagSet(name, defaultFormat string) (*flag.FlagSet, *options) {
+	fs := flag.NewFlagSet("datacli "+name, flag.ExitOnError)
+	opts := &options{}
+	fs.StringVar(&opts.api, "api", envOr("DATA_CO_API_URL", "http://localhost:8080"), "API base URL")
+	fs.StringVar(&opts.token, "token", os.Getenv("DATA_CO_API_KEY"), "API key or JWT")
+	fs.BoolVar(&opts.offline, "offline", false, "query the staging database directly instead of the API")
+	fs.StringVar(&opts.format, "format", defaultFormat, `output format: "table", "csv" or "json"`)
+	return fs, opts
+}
+
+// addFilterFlags binds the search filters to flags named after their JSON fields
+func addFilterFlags(fs *flag.FlagSet, f *models.CompanySearchFilters) {
+	fs.StringVar(&f.Industry, "industry", "", "industry, e.g. tech or finance, or a SIC code")
+	fs.StringVar(&f.SICSection, "sic-section", "", "SIC sections, e.g. J or J,M")
+	fs.StringVar(&f.SICDivision, "sic-division", "", "SIC divisions, e.g. 62")
+	fs.StringVar(&f.SICClass, "sic-class", "", "SIC classes or codes, e.g. 6202 or 62020")
+	fs.StringVar(&f.Location, "location", "", "locality or region, e.g. london")
+	fs.StringVar(&f.Country, "country", "", `UK country(s), e.g. scotland or "wales,northern-ireland"`)
+	fs.StringVar(&f.UKRegion, "uk-region", "", "UK region(s), e.g. north-west")
+	fs.StringVar(&f.Locality, "locality", "", `post town(s), e.g. "salford,bolton"`)
+	fs.StringVar(&f.Revenue, "revenue", "", "turnover range, e.g. 1m-10m")
+	fs.StringVar(&f.EmployeeCount, "employee-count", "", "employee range reported in accounts, e.g. 11-50")
+	fs.StringVar(&f.OfficerCount, "officers", "", "active officer range, e.g. 1-10")
+	fs.StringVar(&f.Employees, "employees", "", "deprecated: same as -officers")
+	fs.StringVar(&f.Profitability, "profitability", "", "profitable, loss_making or breakeven")
+	fs.StringVar(&f.CompanySize, "size", "", "company size: micro, small, medium or large")
+	fs.StringVar(&f.CompanyAge, "age", "", "years since incorporation, e.g. 3-5 or 21+")
+	fs.StringVar(&f.CompanyStatus, "status", "", `company status, e.g. all or dissolved (default "active")`)
+	fs.StringVar(&f.NetAssets, "net-assets", "", "net assets range, e.g. 100k-1m or negative")
+	fs.StringVar(&f.DebtLevel, "debt-level", "", "debt level: none, low, medium or high")
+	fs.StringVar(&f.SearchTerm, "q", "", "company name search term")
+	fs.StringVar(&f.RevenueGrowth, "revenue-growth", "", "YoY turnover growth, e.g. 20+ or declining")
+	fs.StringVar(&f.Health, "health", "", "financial health: strong, moderate or weak")
+	fs.StringVar(&f.RiskBand, "risk-band", "", "credit risk: low, medium or high")
+	fs.StringVar(&f.PSCType, "psc-type", "", "PSC type: individual, corporate or none_declared")
+	fs.StringVar(&f.PostcodeArea, "postcode-area", "", `postcode area(s), e.g. EC or "M,BS"`)
+	fs.StringVar(&f.PostcodeDistrict, "postcode-district", "", "postcode district(s), e.g. EC1V")
+	fs.StringVar(&f.AddressContains, "address-contains", "", `text in the registered office address, e.g. "20-22 wenlock road"`)
+	fs.StringVar(&f.CompanyType, "company-type", "", `company type(s), e.g. ltd or "plc,llp"`)
+	fs.StringVar(&f.AccountsCategory, "accounts-category", "", `accounts category(s), e.g. "small,full", or "!micro-entity" to exclude`)
+	fs.StringVar(&f.OrderBy, "order-by", "", "sort key, e.g. turnover or company_name")
+	fs.Func("outstanding-charges", "true or false: has outstanding charges", boolFilter(&f.HasOutstandingCharges))
+	fs.Func("in-administration", "true or false: in administration", boolFilter(&f.InAdministration))
+	fs.Func("in-liquidation", "true or false: in liquidation", boolFilter(&f.InLiquidation))
+	fs.Func("insolvency-history", "true or false: has insolvency history", boolFilter(&f.HasInsolvencyHistory))
+	fs.Func("accounts-overdue", "true or false: accounts filing is overdue", boolFilter(&f.AccountsOverdue))
+	fs.Func("confirmation-statement-overdue", "true or false: confirmation statement is overdue", boolFilter(&f.ConfStmtOverdue))
+	fs.Func("vat-registered", "true or false: VAT registration confirmed by HMRC", boolFilter(&f.VATRegistered))
+	fs.Func("has-website", "true or false: a website was matched to the company", boolFilter(&f.HasWebsite))
+	fs.BoolVar(&f.ExcludeDormant, "exclude-dormant", false, "leave out dormant companies")
+	fs.StringVar(&f.DissolvedFrom, "dissolved-from", "", "dissolved on or after YYYY-MM-DD (with -status dissolved)")
+	fs.StringVar(&f.DissolvedTo, "dissolved-to", "", "dissolved on or before YYYY-MM-DD (with -status dissolved)")
+	fs.Func("accounts-due-within", "days until the next accounts are due, e.g. 30", intFilter(&f.AccountsDueWithinDays))
+	fs.Func("profitable-years", "profitable in each of the latest N financial periods, e.g. 3", intFilter(&f.ProfitableYears))
+	fs.Func("consistent-growth", "true or false: turnover grew in each of the latest three periods", boolFilter(&f.ConsistentGrowth))
+	fs.Func("cash-min", "minimum cash at bank in GBP, e.g. 100000", floatFilter(&f.CashMin))
+	fs.Func("current-ratio-min", "minimum current assets over current liabilities, e.g. 1.5", floatFilter(&f.CurrentRatioMin))
+	fs.Func("fixed-assets-min", "minimum fixed assets in GBP", floatFilter(&f.FixedAssetsMin))
+	fs.Func("fixed-assets-max", "maximum fixed assets in GBP", floatFilter(&f.FixedAssetsMax))
+	fs.Func("intangible-assets-min", "minimum intangible assets in GBP", floatFilter(&f.IntangibleAssetsMin))
+	fs.Func("intangible-assets-max", "maximum intangible assets in GBP", floatFilter(&f.IntangibleAssetsMax))
+	fs.Func("shareholder-funds-min", "minimum shareholder funds in GBP", floatFilter(&f.ShareholderFundsMin))
+	fs.Func("shareholder-funds-max", "maximum shareholder funds in GBP", floatFilter(&f.ShareholderFundsMax))
+	fs.Func("near", `postcode or "lat,lng" to search around, e.g. "EC1V 9LT" or 51.5,-0.1`, nearFilter(&f.Near))
+	fs.Func("radius-km", "radius around -near in km (default 10)", radiusFilter(&f.Near))
+}
+
+// nearFilter parses a -near flag: a postcode, or a latitude and longitude separated by a comma
+func nearFilter(target **models.NearFilter) func(string) error {
+	return func(value string) error {
+		if *target == nil {
+			*target = &models.NearFilter{}
+		}
+		near := *target
+		if latText, lngText, ok := strings.Cut(value, ","); ok {
+			lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+			lng, err2 := strconv.ParseFloat(strings.TrimSpace(lngText), 64)
+			if err != nil || err2 != nil {
+				return fmt.Errorf("must be a postcode or lat,lng")
+			}
+			near.Latitude, near.Longitude = &lat, &lng
+			return nil
+		}
+		near.Postcode = value
+		return nil
+	}
+}
+
+// radiusFilter parses a -radius-km flag
+func radiusFilter(target **models.NearFilter) func(string) error {
+	return func(value string) error {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil || radius <= 0 {
+			return fmt.Errorf("must be a positive number")
+		}
+		if *target == nil {
+			*target = &models.NearFilter{}
+		}
+		(*target).RadiusKm = radius
+		return nil
+	}
+}
+
+// boolFilter parses an optional boolean filter flag
+func boolFilter(target **bool) func(string) error {
+	return func(value string) error {
+		switch value {
+		case "true":
+			v := true
+			*target = &v
+		case "false":
+			v := false
+			*target = &v
+		default:
+			return fmt.Errorf("must be true or false")
+		}
+		return nil
+	}
+}
+
+// intFilter parses an optional integer filter flag
+func intFilter(target **int) func(string) error {
+	return func(value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		*target = &v
+		return nil
+	}
+}
+
+// floatFilter parses an optional number filter flag
+func floatFilter(target **float64) func(string) error {
+	return func(value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		*target = &v
+		return nil
+	}
+}
+
+func runSearch(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("search", "table")
+	var filters models.CompanySearchFilters
+	addFilterFlags(fs, &filters)
+	fs.IntVar(&filters.Limit, "limit", 20, "companies per page")
+	fs.IntVar(&filters.Offset, "offset", 0, "companies to skip")
+	fs.Parse(args)
+
+	b, err := connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	resp, err := b.search(ctx, filters)
+	if err != nil {
+		return err
+	}
+	if err := writeCompanies(os.Stdout, opts.format, resp.Companies); err != nil {
+		return err
+	}
+	if opts.format == "table" && resp.Total != nil {
+		fmt.Fprintf(os.Stderr, "%d-%d of %d\n", filters.Offset+min(1, len(resp.Companies)), filters.Offset+len(resp.Companies), *resp.Total)
+	}
+	return nil
+}
+
+func runCount(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("count", "table")
+	var filters models.CompanySearchFilters
+	addFilterFlags(fs, &filters)
+	fs.Parse(args)
+
+	b, err := connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	total, err := b.count(ctx, filters)
+	if err != nil {
+		return err
+	}
+	if opts.format == "json" {
+		return writeJSON(os.Stdout, models.CountResponse{Total: total})
+	}
+	fmt.Println(total)
+	return nil
+}
+
+func runGet(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("get", "table")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: datacli get [flags] COMPANY_NUMBER\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	b, err := connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	company, err := b.get(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writeCompanies(os.Stdout, opts.format, []models.Company{*company})
+}
+
+func runExport(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("export", "csv")
+	var filters models.CompanySearchFilters
+	addFilterFlags(fs, &filters)
+	output := fs.String("o", "", "write to this file instead of stdout")
+	fs.Parse(args)
+	if filters.OrderBy == "" {
+		// A unique sort key keeps pages from overlapping
+		filters.OrderBy = "company_number"
+	}
+
+	b, err := connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	w := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	out, err := newCompanyWriter(w, opts.format)
+	if err != nil {
+		return err
+	}
+	written, err := b.export(ctx, filters, out.write)
+	if err != nil {
+		return err
+	}
+	if err := out.flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d companies\n", written)
+	return nil
+}
+
+func envOr(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"data-co/api/client"
+	"data-co/api/models"
+)
+
+// tableColumns are the columns printed in table format, a subset of client.ExportColumns
+var tableColumns = []string{"company_number", "company_name", "company_status", "locality", "turnover", "net_worth", "health", "risk_band"}
+
+// companyRow is a company with plain nullable values, for JSON output
+type companyRow struct {
+	CompanyNumber       string     `json:"company_number"`
+	CompanyName         string     `json:"company_name"`
+	CompanyStatus       string     `json:"company_status"`
+	CompanyType         *string    `json:"company_type"`
+	Locality            *string    `json:"locality"`
+	Region              *string    `json:"region"`
+	PostalCode          *string    `json:"postal_code"`
+	Latitude            *float64   `json:"latitude"`
+	Longitude           *float64   `json:"longitude"`
+	PrimarySICCode      *string    `json:"primary_sic_code"`
+	IncorporationDate   *time.Time `json:"incorporation_date"`
+	DissolvedOn         *time.Time `json:"dissolved_on"`
+	Turnover            *float64   `json:"turnover"`
+	ProfitAfterTax      *float64   `json:"profit_after_tax"`
+	TotalAssets         *float64   `json:"total_assets"`
+	NetWorth            *float64   `json:"net_worth"`
+	LatestAccountsDate  *time.Time `json:"latest_accounts_date"`
+	AccountsCategory    *string    `json:"accounts_category"`
+	NextAccountsDue     *time.Time `json:"next_accounts_due"`
+	ConfStmtLastMadeUp  *time.Time `json:"confirmation_statement_last_made_up_to"`
+	ConfStmtNextDue     *time.Time `json:"confirmation_statement_next_due"`
+	ActiveOfficersCount int        `json:"active_officers_count"`
+	Health              *string    `json:"health"`
+	RiskBand            *string    `json:"risk_band"`
+	RiskFlags           []string   `json:"risk_flags"`
+}
+
+func newCompanyRow(c models.Company) companyRow {
+	str := func(valid bool, s string) *string {
+		if !valid {
+			return nil
+		}
+		return &s
+	}
+	num := func(valid bool, f float64) *float64 {
+		if !valid {
+			return nil
+		}
+		return &f
+	}
+	return companyRow{
+		CompanyNumber:       c.CompanyNumber,
+		CompanyName:         c.CompanyName,
+		CompanyStatus:       c.CompanyStatus,
+		CompanyType:         str(c.CompanyType.Valid, c.CompanyType.String),
+		Locality:            str(c.Locality.Valid, c.Locality.String),
+		Region:              str(c.Region.Valid, c.Region.String),
+		PostalCode:          str(c.PostalCode.Valid, c.PostalCode.String),
+		Latitude:            num(c.Latitude.Valid, c.Latitude.Float64),
+		Longitude:           num(c.Longitude.Valid, c.Longitude.Float64),
+		PrimarySICCode:      str(c.PrimarySICCode.Valid, c.PrimarySICCode.String),
+		IncorporationDate:   c.IncorporationDate,
+		DissolvedOn:         c.DissolvedOn,
+		Turnover:            num(c.Turnover.Valid, c.Turnover.Float64),
+		ProfitAfterTax:      num(c.ProfitAfterTax.Valid, c.ProfitAfterTax.Float64),
+		TotalAssets:         num(c.TotalAssets.Valid, c.TotalAssets.Float64),
+		NetWorth:            num(c.NetWorth.Valid, c.NetWorth.Float64),
+		LatestAccountsDate:  c.LatestAccountsDate,
+		AccountsCategory:    str(c.AccountsCategory.Valid, c.AccountsCategory.String),
+		NextAccountsDue:     c.NextAccountsDue,
+		ConfStmtLastMadeUp:  c.ConfStmtLastMadeUp,
+		ConfStmtNextDue:     c.ConfStmtNextDue,
+		ActiveOfficersCount: c.ActiveOfficersCount,
+		Health:              str(c.Health.Valid, c.Health.String),
+		RiskBand:            str(c.RiskBand.Valid, c.RiskBand.String),
+		RiskFlags:           c.RiskFlags,
+	}
+}
+
+// companyWriter writes companies one at a time in an output format
+type companyWriter struct {
+	write func(models.Company) error
+	flush func() error
+}
+
+func newCompanyWriter(w io.Writer, format string) (*companyWriter, error) {
+	switch format {
+	case "csv":
+		out := csv.NewWriter(w)
+		if err := out.Write(client.ExportColumns); err != nil {
+			return nil, err
+		}
+		return &companyWriter{
+			write: func(c models.Company) error { return out.Write(client.CSVRecord(c)) },
+			flush: func() error { out.Flush(); return out.Error() },
+		}, nil
+	case "json":
+		// One object per line, so large exports can be streamed
+		enc := json.NewEncoder(w)
+		return &companyWriter{
+			write: func(c models.Company) error { return enc.Encode(newCompanyRow(c)) },
+			flush: func() error { return nil },
+		}, nil
+	case "table":
+		out := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(out, strings.ToUpper(strings.Join(tableColumns, "\t")))
+		return &companyWriter{
+			write: func(c models.Company) error {
+				_, err := fmt.Fprintln(out, strings.Join(tableRecord(c), "\t"))
+				return err
+			},
+			flush: out.Flush,
+		}, nil
+	}
+	return nil, fmt.Errorf(`unknown format %q: use "table", "csv" or "json"`, format)
+}
+
+// writeCompanies writes a list of companies. JSON output is an array rather than one per line.
+func writeCompanies(w io.Writer, format string, companies []models.Company) error {
+	if format == "json" {
+		rows := make([]companyRow, len(companies))
+		for i, c := range companies {
+			rows[i] = newCompanyRow(c)
+		}
+		return writeJSON(w, rows)
+	}
+
+	out, err := newCompanyWriter(w, format)
+	if err != nil {
+		return err
+	}
+	for _, c := range companies {
+		if err := out.write(c); err != nil {
+			return err
+		}
+	}
+	return out.flush()
+}
+
+// tableRecord picks the table columns out of a company's CSV record
+func tableRecord(c models.Company) []string {
+	record := client.CSVRecord(c)
+	fields := make([]string, len(tableColumns))
+	for i, column := range tableColumns {
+		for j, name := range client.ExportColumns {
+			if name == column {
+				fields[i] = record[j]
+			}
+		}
+		if fields[i] == "" {
+			fields[i] = "-"
+		}
+	}
+	return fields
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
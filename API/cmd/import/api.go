@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"data-co/api/companieshouse"
+	"data-co/api/database"
+)
+
+// apiPageSize is how many companies are selected for fetching at a time
+const apiPageSize = 1000
+
+// apiResource is a per-company Companies House REST API resource the importer can fetch
+type apiResource struct {
+	name string
+	// targets returns up to limit companies not fetched since staleBefore
+	targets func(db *database.DB, ctx context.Context, staleBefore time.Time, limit int) ([]string, error)
+	// fetch fetches and stores one company's records, returning how many were fetched and written
+	fetch func(imp apiImporter, ctx context.Context, companyNumber string) (int, int64, error)
+}
+
+// apiResources are the -type values served from the REST API rather than snapshot files
+var apiResources = map[string]apiResource{
+	"charges": {
+		name:    "charges",
+		targets: (*database.DB).CompaniesNeedingCharges,
+		fetch: func(imp apiImporter, ctx context.Context, number string) (int, int64, error) {
+			charges, err := imp.client.CompanyCharges(ctx, number)
+			if err != nil {
+				return 0, 0, err
+			}
+			written, err := imp.db.ReplaceCompanyCharges(ctx, imp.batchID, number, charges)
+			return len(charges), written, err
+		},
+	},
+	"insolvency": {
+		name:    "insolvency cases",
+		targets: (*database.DB).CompaniesNeedingInsolvency,
+		fetch: func(imp apiImporter, ctx context.Context, number string) (int, int64, error) {
+			cases, err := imp.client.CompanyInsolvency(ctx, number)
+			if err != nil {
+				return 0, 0, err
+			}
+			written, err := imp.db.ReplaceCompanyInsolvency(ctx, imp.batchID, number, cases)
+			return len(cases), written, err
+		},
+	},
+}
+
+// apiImporter fetches a per-company resource from the REST API into staging
+type apiImporter struct {
+	db               *database.DB
+	client           *companieshouse.Client
+	resource         apiResource
+	batchID          string
+	searchName       string
+	refreshAfter     time.Duration
+	limit            int
+	progressInterval time.Duration
+}
+
+// run fetches the resource for every eligible company that was never fetched or was fetched
+// longer ago than refreshAfter, recording the batch in the ingestion log
+func (imp apiImporter) run(ctx context.Context) error {
+	db, batchID := imp.db, imp.batchID
+
+	if err := db.StartIngestionLog(ctx, batchID, imp.searchName, nil); err != nil {
+		return fmt.Errorf("failed to start import: %w", err)
+	}
+
+	err := imp.fetchAll(ctx)
+	if err != nil {
+		// Record the failure even if the failure was the context being cancelled
+		if logErr := db.FinishIngestionLog(context.WithoutCancel(ctx), batchID, err.Error()); logErr != nil {
+			log.Printf("Failed to record import failure: %v", logErr)
+		}
+		return err
+	}
+
+	if err := db.FinishIngestionLog(ctx, batchID, ""); err != nil {
+		log.Printf("Failed to record import completion: %v", err)
+	}
+	return nil
+}
+
+func (imp apiImporter) fetchAll(ctx context.Context) error {
+	db, name := imp.db, imp.resource.name
+	started := time.Now()
+	lastProgress := started
+	staleBefore := started.Add(-imp.refreshAfter)
+
+	var fetched, failed, records, written int64
+	for imp.limit == 0 || fetched+failed < int64(imp.limit) {
+		pageSize := apiPageSize
+		if imp.limit > 0 {
+			pageSize = min(pageSize, imp.limit-int(fetched+failed))
+		}
+		numbers, err := imp.resource.targets(db, ctx, staleBefore, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(numbers) == 0 {
+			break
+		}
+
+		// Companies that fail stay eligible, so stop rather than retry a page that made no progress
+		pageFetched := 0
+		for _, number := range numbers {
+			n, w, err := imp.resource.fetch(imp, ctx, number)
+			written += w
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				log.Printf("Skipping %s for %s: %v", name, number, err)
+				failed++
+				continue
+			}
+			fetched++
+			pageFetched++
+			records += int64(n)
+
+			if time.Since(lastProgress) >= imp.progressInterval {
+				lastProgress = time.Now()
+				rate := float64(fetched) / time.Since(started).Seconds()
+				log.Printf("  %d companies fetched, %d failed - %d %s, %d written (%.1f companies/s)", fetched, failed, records, name, written, rate)
+				if err := db.UpdateIngestionProgress(ctx, imp.batchID, 0, number, 0, fetched); err != nil {
+					log.Printf("Failed to record import progress: %v", err)
+				}
+			}
+		}
+		if pageFetched == 0 {
+			return fmt.Errorf("failed to fetch %s for any of %d companies", name, len(numbers))
+		}
+	}
+
+	if err := db.UpdateIngestionProgress(ctx, imp.batchID, 0, "", 100, fetched); err != nil {
+		log.Printf("Failed to record import progress: %v", err)
+	}
+	log.Printf("Import %s completed in %s: %d companies fetched, %d failed, %d %s, %d written",
+		imp.batchID, time.Since(started).Round(time.Second), fetched, failed, records, name, written)
+	return nil
+}
@@ -0,0 +1,277 @@
+// Command import loads bulk snapshot files into staging: Companies House BasicCompanyData
+// into staging_companies, the PSC snapshot into staging_pscs, the ONS Postcode Directory into
+// postcode_lookup (for the geocoding job), a VAT number lookup onto staging_companies (for
+// the VAT check job), the ECB's reference exchange rates into fx_rates (converting financials
+// reported in other currencies to GBP), or the Companies House SIC code list into sic_taxonomy
+// (for the SIC hierarchy filters). Companies House publishes no charges or insolvency snapshot, so -type
+// charges and -type insolvency instead fetch them from the REST API for every staged company
+// that has charges (or an insolvency status or history) and has not been fetched recently.
+//
+// Usage:
+//
+//	go run ./cmd/import [-type companies|psc|postcodes|vat|fx|sic] [-batch-size N] FILE...
+//	go run ./cmd/import -type charges|insolvency [-refresh-after D] [-limit N]
+//
+// Rows are COPYed in batches and upserted with a change-detection hash (for companies, the same
+// hash as the Python loader), so re-importing a snapshot only rewrites records that changed.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"data-co/api/companieshouse"
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/snapshot"
+)
+
+// totals accumulates counts across all imported files
+type totals struct {
+	read      int64
+	written   int64
+	malformed int64
+}
+
+// rowSource is a snapshot file reader
+type rowSource[T any] interface {
+	Next() (T, error)
+	Progress() float64
+	Close() error
+}
+
+func main() {
+	kind := flag.String("type", "companies", `snapshot type: "companies" (BasicCompanyData), "psc", "postcodes" (ONS Postcode Directory), "vat" (VAT number lookup), "fx" (ECB reference rates), "sic" (Companies House SIC code list), "charges" or "insolvency"`)
+	batchSize := flag.Int("batch-size", 50000, "rows per COPY batch")
+	progressInterval := flag.Duration("progress-interval", 10*time.Second, "how often progress is logged")
+	refreshAfter := flag.Duration("refresh-after", 30*24*time.Hour, "charges/insolvency: refetch companies fetched longer ago than this")
+	limit := flag.Int("limit", 0, "charges/insolvency: maximum companies to fetch (0 for no limit)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] FILE...\n       %s -type charges|insolvency [flags]\n\nFILE is a BasicCompanyData .zip or .csv file, a PSC snapshot .zip or .txt file, an ONS Postcode Directory .zip or .csv file, a VAT number lookup .zip or .csv file with company_number and vat_number columns, the ECB's eurofxref-hist .zip or .csv file, or the Companies House SIC07_CH_condensed_list_en .csv file.\n\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files := flag.Args()
+	resource, fromAPI := apiResources[*kind]
+	switch {
+	case fromAPI:
+		if len(files) != 0 || *limit < 0 {
+			flag.Usage()
+			os.Exit(2)
+		}
+	case len(files) == 0 || *batchSize < 1 || (*kind != "companies" && *kind != "psc" && *kind != "postcodes" && *kind != "vat" && *kind != "fx" && *kind != "sic"):
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables from .env file if it exists
+	_ = godotenv.Load("../.env") // Ignore error, env vars may come from docker-compose
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if fromAPI && cfg.CompaniesHouse.APIKey == "" {
+		log.Fatalf("COMPANIES_HOUSE_API_KEY is required to import %s", resource.name)
+	}
+	// Import batches can take longer than an API query is allowed to
+	cfg.Database.StatementTimeout = 0
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	log.Printf("Connected to database: %s", cfg.Database.Name)
+
+	batchID := fmt.Sprintf("import_%s", time.Now().Format("20060102_150405"))
+	if fromAPI {
+		client := companieshouse.NewClient(cfg.CompaniesHouse.BaseURL, cfg.CompaniesHouse.APIKey, cfg.CompaniesHouse.RequestsPerSecond)
+		imp := apiImporter{
+			db: db, client: client, resource: resource, batchID: batchID, searchName: *kind + "_api_import",
+			refreshAfter: *refreshAfter, limit: *limit, progressInterval: *progressInterval,
+		}
+		if err := imp.run(ctx); err != nil {
+			log.Fatalf("Import %s failed: %v", batchID, err)
+		}
+		return
+	}
+
+	if err := db.StartIngestionLog(ctx, batchID, *kind+"_snapshot_import", files); err != nil {
+		log.Fatalf("Failed to start import: %v", err)
+	}
+
+	started := time.Now()
+	var sum totals
+	for i, path := range files {
+		imp := importer{db: db, batchID: batchID, index: i, files: len(files), batchSize: *batchSize, progressInterval: *progressInterval, sum: &sum}
+		var err error
+		switch *kind {
+		case "psc":
+			err = importFile(ctx, imp, path, openPSC, db.ImportPSCs)
+		case "postcodes":
+			err = importFile(ctx, imp, path, openPostcodes, db.ImportPostcodes)
+		case "vat":
+			err = importFile(ctx, imp, path, openVAT, db.ImportVATNumbers)
+		case "fx":
+			err = importFile(ctx, imp, path, openFX, db.ImportFXRates)
+		case "sic":
+			err = importFile(ctx, imp, path, openSIC, db.ImportSICCodes)
+		default:
+			err = importFile(ctx, imp, path, openCompanies, db.ImportCompanies)
+		}
+		if err != nil {
+			// Record the failure even if the failure was the context being cancelled
+			if logErr := db.FinishIngestionLog(context.WithoutCancel(ctx), batchID, err.Error()); logErr != nil {
+				log.Printf("Failed to record import failure: %v", logErr)
+			}
+			log.Fatalf("Import %s failed: %v", batchID, err)
+		}
+	}
+
+	if err := db.FinishIngestionLog(ctx, batchID, ""); err != nil {
+		log.Printf("Failed to record import completion: %v", err)
+	}
+
+	log.Printf("Import %s completed in %s: %d rows read, %d written, %d unchanged, %d malformed",
+		batchID, time.Since(started).Round(time.Second), sum.read, sum.written, sum.read-sum.written, sum.malformed)
+
+	if *kind == "fx" {
+		// Search results pick the new rates up at the next summary refresh
+		converted, err := db.ConvertFinancials(ctx)
+		if err != nil {
+			log.Fatalf("Failed to convert financials: %v", err)
+		}
+		log.Printf("Converted %d financial periods to GBP", converted)
+	}
+}
+
+// importer holds the settings and running totals shared by every file of an import
+type importer struct {
+	db               *database.DB
+	batchID          string
+	index            int
+	files            int
+	batchSize        int
+	progressInterval time.Duration
+	sum              *totals
+}
+
+func openCompanies(path string) (rowSource[database.StagingCompany], error) {
+	return snapshot.Open(path)
+}
+
+func openPSC(path string) (rowSource[database.StagingPSC], error) {
+	return snapshot.OpenPSC(path)
+}
+
+func openPostcodes(path string) (rowSource[database.Postcode], error) {
+	return snapshot.OpenPostcodes(path)
+}
+
+func openVAT(path string) (rowSource[database.VATNumber], error) {
+	return snapshot.OpenVAT(path)
+}
+
+func openFX(path string) (rowSource[database.FXRate], error) {
+	return snapshot.OpenFX(path)
+}
+
+func openSIC(path string) (rowSource[database.SICCode], error) {
+	return snapshot.OpenSIC(path)
+}
+
+// importFile streams one snapshot file into staging in batches, loading each with load
+func importFile[T any](ctx context.Context, imp importer, path string, open func(string) (rowSource[T], error), load func(context.Context, string, []T) (int64, error)) error {
+	db, batchID, index, batchSize, sum := imp.db, imp.batchID, imp.index, imp.batchSize, imp.sum
+
+	reader, err := open(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	name := filepath.Base(path)
+	log.Printf("Importing %s (%d of %d)", name, index+1, imp.files)
+
+	started := time.Now()
+	lastProgress := started
+	var fileRead, fileWritten int64
+
+	batch := make([]T, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		written, err := load(ctx, batchID, batch)
+		if err != nil {
+			return err
+		}
+		fileWritten += written
+		sum.written += written
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *snapshot.RowError
+		if errors.As(err, &rowErr) {
+			log.Printf("Skipping malformed row in %s: %v", name, rowErr)
+			sum.malformed++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		batch = append(batch, row)
+		fileRead++
+		sum.read++
+
+		if len(batch) < batchSize {
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+
+		if time.Since(lastProgress) >= imp.progressInterval {
+			lastProgress = time.Now()
+			percent := int(reader.Progress() * 100)
+			rate := float64(fileRead) / time.Since(started).Seconds()
+			log.Printf("  %s: %d%% - %d rows read, %d written (%.0f rows/s)", name, percent, fileRead, fileWritten, rate)
+			if err := db.UpdateIngestionProgress(ctx, batchID, index, name, percent, sum.written); err != nil {
+				log.Printf("Failed to record import progress: %v", err)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if err := db.UpdateIngestionProgress(ctx, batchID, index+1, name, 100, sum.written); err != nil {
+		log.Printf("Failed to record import progress: %v", err)
+	}
+	log.Printf("Imported %s in %s: %d rows read, %d written, %d unchanged",
+		name, time.Since(started).Round(time.Second), fileRead, fileWritten, fileRead-fileWritten)
+	return nil
+}
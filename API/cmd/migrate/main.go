@@ -0,0 +1,92 @@
+// Command migrate applies the API's pending schema migrations to the staging database, or with
+// -status lists which are applied.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/migrations"
+)
+
+func main() {
+	status := flag.Bool("status", false, "list migrations and whether each is applied, without applying any")
+	flag.Parse()
+
+	// Load environment variables from .env file if it exists
+	_ = godotenv.Load("../.env") // Ignore error, env vars may come from docker-compose
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Migrations run on the primary even when a read replica is configured
+	cfg.Database.ReplicaDSN = ""
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if *status {
+		if err := printStatus(ctx, db); err != nil {
+			log.Fatalf("Failed to get migration status: %v", err)
+		}
+		return
+	}
+
+	applied, err := migrations.Apply(ctx, db)
+	for _, m := range applied {
+		log.Printf("Applied %s", m.Name)
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	if len(applied) == 0 {
+		log.Printf("Schema is up to date")
+	}
+}
+
+// printStatus lists every embedded migration with when it was applied
+func printStatus(ctx context.Context, db *database.DB) error {
+	all, err := migrations.All()
+	if err != nil {
+		return err
+	}
+	applied, err := migrations.ListApplied(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	appliedAt := make(map[int]string, len(applied))
+	for _, a := range applied {
+		appliedAt[a.Version] = a.AppliedAt.Format("2006-01-02 15:04:05")
+	}
+	for _, m := range all {
+		at, ok := appliedAt[m.Version]
+		if !ok {
+			at = "pending"
+		}
+		fmt.Fprintf(os.Stdout, "%-40s %s\n", m.Name, at)
+		delete(appliedAt, m.Version)
+	}
+	for _, a := range applied {
+		if _, unknown := appliedAt[a.Version]; unknown {
+			fmt.Fprintf(os.Stdout, "%-40s %s (not in this build)\n", a.Name, appliedAt[a.Version])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,484 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"data-co/api/database"
+)
+
+// town is somewhere seeded companies are registered, with its postcode areas' approximate centre
+type town struct {
+	name      string
+	region    string
+	country   string
+	onsRegion string // ONS code of the region, as in the Postcode Directory
+	outward   []string
+	latitude  float64
+	longitude float64
+	weight    int
+}
+
+var towns = []town{
+	{"London", "Greater London", "England", "E12000007", []string{"EC1A", "EC2M", "W1D", "SE1", "N1", "E14"}, 51.5074, -0.1278, 30},
+	{"Manchester", "Greater Manchester", "England", "E12000002", []string{"M1", "M2", "M3", "M4"}, 53.4808, -2.2426, 8},
+	{"Birmingham", "West Midlands", "England", "E12000005", []string{"B1", "B2", "B3", "B5"}, 52.4862, -1.8904, 7},
+	{"Leeds", "West Yorkshire", "England", "E12000003", []string{"LS1", "LS2", "LS11"}, 53.8008, -1.5491, 5},
+	{"Bristol", "Avon", "England", "E12000009", []string{"BS1", "BS2", "BS8"}, 51.4545, -2.5879, 4},
+	{"Liverpool", "Merseyside", "England", "E12000002", []string{"L1", "L2", "L3"}, 53.4084, -2.9916, 4},
+	{"Sheffield", "South Yorkshire", "England", "E12000003", []string{"S1", "S2", "S3"}, 53.3811, -1.4701, 3},
+	{"Newcastle upon Tyne", "Tyne and Wear", "England", "E12000001", []string{"NE1", "NE2"}, 54.9783, -1.6178, 3},
+	{"Nottingham", "Nottinghamshire", "England", "E12000004", []string{"NG1", "NG2"}, 52.9548, -1.1581, 3},
+	{"Reading", "Berkshire", "England", "E12000008", []string{"RG1", "RG2"}, 51.4543, -0.9781, 2},
+	{"Cambridge", "Cambridgeshire", "England", "E12000006", []string{"CB1", "CB2"}, 52.2053, 0.1218, 2},
+	{"Oxford", "Oxfordshire", "England", "E12000008", []string{"OX1", "OX2"}, 51.7520, -1.2577, 2},
+	{"Norwich", "Norfolk", "England", "E12000006", []string{"NR1", "NR2"}, 52.6309, 1.2974, 2},
+	{"Brighton", "East Sussex", "England", "E12000008", []string{"BN1", "BN2"}, 50.8225, -0.1372, 2},
+	{"Southampton", "Hampshire", "England", "E12000008", []string{"SO14", "SO15"}, 50.9097, -1.4044, 2},
+	{"Cardiff", "South Glamorgan", "Wales", "W99999999", []string{"CF10", "CF11"}, 51.4816, -3.1791, 3},
+	{"Swansea", "West Glamorgan", "Wales", "W99999999", []string{"SA1"}, 51.6214, -3.9436, 1},
+	{"Glasgow", "Lanarkshire", "Scotland", "S99999999", []string{"G1", "G2", "G3"}, 55.8642, -4.2518, 4},
+	{"Edinburgh", "Midlothian", "Scotland", "S99999999", []string{"EH1", "EH2", "EH3"}, 55.9533, -3.1883, 4},
+	{"Aberdeen", "Aberdeenshire", "Scotland", "S99999999", []string{"AB10", "AB11"}, 57.1497, -2.0943, 1},
+	{"Belfast", "County Antrim", "Northern Ireland", "N99999999", []string{"BT1", "BT2"}, 54.5973, -5.9301, 2},
+}
+
+// countryCodes are the ONS codes of the countries towns are in
+var countryCodes = map[string]string{
+	"England":          "E92000001",
+	"Scotland":         "S92000003",
+	"Wales":            "W92000004",
+	"Northern Ireland": "N92000002",
+}
+
+// industry is a kind of business seeded companies do, with the words their names use
+type industry struct {
+	sicCodes []string
+	nouns    []string
+	// revenuePerEmployee is a typical annual turnover per employee
+	revenuePerEmployee float64
+	weight             int
+}
+
+var industries = []industry{
+	{[]string{"62012", "62020", "62090"}, []string{"SOFTWARE", "DIGITAL", "TECHNOLOGIES", "SYSTEMS", "SOLUTIONS"}, 120000, 12},
+	{[]string{"70229", "74909", "82990"}, []string{"CONSULTING", "ADVISORY", "PARTNERS", "SERVICES", "MANAGEMENT"}, 90000, 12},
+	{[]string{"68209", "68100", "68320"}, []string{"PROPERTIES", "ESTATES", "HOLDINGS", "INVESTMENTS", "LETTINGS"}, 150000, 10},
+	{[]string{"41100", "41202", "43999", "43210"}, []string{"CONSTRUCTION", "BUILDERS", "DEVELOPMENTS", "ELECTRICAL", "BUILDING SERVICES"}, 110000, 9},
+	{[]string{"56101", "56302", "56210"}, []string{"KITCHEN", "RESTAURANTS", "CATERING", "INNS", "COFFEE"}, 45000, 6},
+	{[]string{"47910", "47190", "46900"}, []string{"RETAIL", "TRADING", "SUPPLIES", "GOODS", "STORES"}, 140000, 7},
+	{[]string{"49410", "52290", "53202"}, []string{"LOGISTICS", "HAULAGE", "FREIGHT", "COURIERS", "TRANSPORT"}, 100000, 4},
+	{[]string{"25620", "28990", "25110"}, []string{"ENGINEERING", "FABRICATION", "PRECISION", "MANUFACTURING", "INDUSTRIES"}, 95000, 4},
+	{[]string{"86900", "86230", "87100"}, []string{"HEALTHCARE", "CARE", "CLINIC", "DENTAL", "WELLBEING"}, 55000, 4},
+	{[]string{"69201", "69102"}, []string{"ACCOUNTANCY", "ACCOUNTANTS", "LEGAL", "TAX", "BOOKKEEPING"}, 80000, 4},
+	{[]string{"10710", "11050", "10890"}, []string{"BAKERY", "BREWING", "FOODS", "KITCHENS", "PROVISIONS"}, 85000, 2},
+	{[]string{"85590", "85600"}, []string{"TRAINING", "ACADEMY", "EDUCATION", "LEARNING", "TUTORS"}, 50000, 3},
+	{[]string{"96020", "96090", "93130"}, []string{"STUDIO", "FITNESS", "SALON", "BEAUTY", "LEISURE"}, 40000, 4},
+	{[]string{"64209", "64999", "66220"}, []string{"CAPITAL", "FINANCE", "HOLDINGS", "GROUP", "VENTURES"}, 200000, 3},
+	{[]string{"01110", "01500", "01410"}, []string{"FARMS", "AGRICULTURE", "GROWERS", "LIVESTOCK", "PRODUCE"}, 70000, 1},
+}
+
+var nameWords = []string{
+	"ABBEY", "ALDER", "APEX", "ASHFORD", "ATLAS", "BEACON", "BIRCH", "BLUE HORIZON", "BRIDGEWATER",
+	"CASTLEGATE", "CEDAR", "CROWN", "DOVETAIL", "EVERGREEN", "FALCON", "FIELDHOUSE", "GRANITE",
+	"HARBOUR", "HARTLEY", "HAWTHORN", "HIGHGATE", "IRONBRIDGE", "KESTREL", "KINGFISHER", "LANTERN",
+	"LINDEN", "MAPLE", "MERIDIAN", "MILLSTONE", "NORTHGATE", "NOVA", "OAKWOOD", "ORCHARD", "PEAK",
+	"PENNINE", "QUAYSIDE", "RAVEN", "REDWOOD", "RIVERSIDE", "ROWAN", "SILVERLINE", "SOUTHBANK",
+	"SPRINGFIELD", "STERLING", "SUMMIT", "THISTLE", "TRINITY", "VALE", "VANGUARD", "WESTBROOK",
+	"WILLOW", "WINDMILL", "YORKSTONE",
+}
+
+var surnames = []string{
+	"SMITH", "JONES", "TAYLOR", "BROWN", "WILLIAMS", "WILSON", "JOHNSON", "DAVIES", "PATEL", "ROBINSON",
+	"WRIGHT", "THOMPSON", "EVANS", "WALKER", "WHITE", "ROBERTS", "GREEN", "HALL", "THOMAS", "CLARKE",
+	"JACKSON", "WOOD", "HARRIS", "EDWARDS", "TURNER", "MARTIN", "COOPER", "HILL", "WARD", "HUGHES",
+	"MOORE", "CLARK", "KING", "HARRISON", "LEWIS", "BAKER", "LEE", "ALLEN", "MORRIS", "KHAN",
+	"SCOTT", "WATSON", "DAVIS", "PARKER", "JAMES", "BENNETT", "YOUNG", "PHILLIPS", "CAMPBELL", "MACDONALD",
+	"SINGH", "MURPHY", "KELLY", "O'BRIEN", "NGUYEN", "KOWALSKI", "ROSSI", "MULLER", "AHMED", "CHEN",
+}
+
+var forenames = []string{
+	"James", "Oliver", "David", "John", "Michael", "Daniel", "Thomas", "Richard", "Mark", "Paul",
+	"Andrew", "Christopher", "Mohammed", "Robert", "Matthew", "Stephen", "Peter", "William", "Rajesh", "Tomasz",
+	"Sarah", "Emma", "Claire", "Laura", "Rebecca", "Charlotte", "Helen", "Sophie", "Rachel", "Emily",
+	"Katherine", "Victoria", "Jennifer", "Hannah", "Amelia", "Priya", "Fatima", "Anna", "Louise", "Grace",
+}
+
+var streets = []string{
+	"High Street", "Station Road", "Church Street", "Market Place", "King Street", "Victoria Road",
+	"Mill Lane", "Queen Street", "Park Road", "London Road", "Bridge Street", "The Parade", "Castle Street",
+	"Wellington Road", "Albert Street", "New Street", "George Street", "Commercial Road",
+}
+
+// weighted picks an index of weights at random in proportion to its weight
+func weighted(rng *rand.Rand, weights ...int) int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	n := rng.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return i
+		}
+		n -= w
+	}
+	return len(weights) - 1
+}
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.Intn(len(values))]
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func day(t time.Time) *string {
+	return ptr(t.Format("2006-01-02"))
+}
+
+// money rounds an amount to whole pounds
+func money(v float64) *float64 {
+	return ptr(math.Round(v))
+}
+
+// person is an officer who may hold appointments at several seeded companies
+type person struct {
+	name        string
+	dateOfBirth string
+	nationality string
+}
+
+// seeded is one generated company with its officers and financial history
+type seeded struct {
+	company    database.StagingCompany
+	officers   []database.StagingOfficer
+	financials []database.StagingFinancial
+	postcode   database.Postcode
+}
+
+// generator produces realistic synthetic companies. A generator with the same seed and clock
+// produces the same companies.
+type generator struct {
+	rng             *rand.Rand
+	now             time.Time
+	people          []person
+	townWeights     []int
+	industryWeights []int
+}
+
+func newGenerator(seed int64, now time.Time) *generator {
+	g := &generator{rng: rand.New(rand.NewSource(seed)), now: now.Truncate(24 * time.Hour)}
+	for _, t := range towns {
+		g.townWeights = append(g.townWeights, t.weight)
+	}
+	for _, ind := range industries {
+		g.industryWeights = append(g.industryWeights, ind.weight)
+	}
+	return g
+}
+
+// company generates the nth company. Numbers start with 99, which Companies House has not
+// issued, so seeded companies are easy to tell apart from real ones.
+func (g *generator) company(n int) seeded {
+	rng := g.rng
+	number := fmt.Sprintf("99%06d", n)
+
+	where := towns[weighted(rng, g.townWeights...)]
+	what := industries[weighted(rng, g.industryWeights...)]
+
+	companyType, suffix := "Private Limited Company", "LIMITED"
+	switch weighted(rng, 86, 5, 2, 3, 4) {
+	case 0:
+		if rng.Intn(5) == 0 {
+			suffix = "LTD"
+		}
+	case 1:
+		companyType, suffix = "Limited Liability Partnership", "LLP"
+	case 2:
+		companyType, suffix = "Public Limited Company", "PLC"
+	case 3:
+		companyType, suffix = "Community Interest Company", "CIC"
+	case 4:
+		companyType = "PRI/LTD BY GUAR/NSC (Private, limited by guarantee, no share capital)"
+	}
+	name := pick(rng, nameWords)
+	switch rng.Intn(3) {
+	case 0:
+		name = pick(rng, surnames) + " & " + pick(rng, surnames)
+	case 1:
+		name = strings.ToUpper(where.name)
+	}
+	name = fmt.Sprintf("%s %s %s", name, pick(rng, what.nouns), suffix)
+
+	// Most companies are young; a few date back decades
+	age := time.Duration(math.Min(rng.ExpFloat64()*7, 45)*365.25*24) * time.Hour
+	incorporated := g.now.Add(-age - 30*24*time.Hour)
+
+	status := []string{"Active", "Dissolved", "Liquidation", "In Administration", "Active - Proposal to Strike off"}[weighted(rng, 80, 12, 4, 1, 3)]
+	var dissolved *time.Time
+	if status == "Dissolved" && g.now.Sub(incorporated) > 400*24*time.Hour {
+		dissolved = ptr(incorporated.Add(time.Duration(rng.Int63n(int64(g.now.Sub(incorporated) - 365*24*time.Hour)))).Add(365 * 24 * time.Hour))
+	} else if status == "Dissolved" {
+		status = "Active"
+	}
+	until := g.now
+	if dissolved != nil {
+		until = *dissolved
+	}
+
+	outward := pick(rng, where.outward)
+	postcode := fmt.Sprintf("%s %d%c%c", outward, rng.Intn(10), 'A'+rune(rng.Intn(26)), 'A'+rune(rng.Intn(26)))
+	latitude := where.latitude + rng.NormFloat64()*0.02
+	longitude := where.longitude + rng.NormFloat64()*0.03
+
+	sicCodes := []string{pick(rng, what.sicCodes)}
+	if rng.Intn(4) == 0 {
+		if other := pick(rng, what.sicCodes); other != sicCodes[0] {
+			sicCodes = append(sicCodes, other)
+		}
+	}
+
+	c := database.StagingCompany{
+		CompanyNumber:     number,
+		CompanyName:       &name,
+		CompanyStatus:     &status,
+		CompanyType:       &companyType,
+		Locality:          ptr(where.name),
+		PostalCode:        &postcode,
+		AddressLine1:      ptr(fmt.Sprintf("%d %s", 1+rng.Intn(200), pick(rng, streets))),
+		Region:            ptr(where.region),
+		Country:           ptr(where.country),
+		SICCodes:          sicCodes,
+		IncorporationDate: day(incorporated),
+	}
+	if rng.Intn(4) == 0 {
+		c.AddressLine2 = ptr(fmt.Sprintf("Unit %d", 1+rng.Intn(30)))
+	}
+	if dissolved != nil {
+		c.DissolvedOn = day(*dissolved)
+	}
+
+	// Accounts are made up to the end of the month the company was incorporated in, and
+	// filed within nine months
+	refMonth := incorporated.Month()
+	monthEnd := func(year int) time.Time { return time.Date(year, refMonth+1, 0, 0, 0, 0, 0, time.UTC) }
+	filingDue := func(end time.Time) time.Time { return time.Date(end.Year(), end.Month()+10, 0, 0, 0, 0, 0, time.UTC) }
+	c.AccountsRefDate = ptr(monthEnd(incorporated.Year()).Format("01-02"))
+	var periodEnds []time.Time
+	for year := incorporated.Year() + 1; !filingDue(monthEnd(year)).After(until); year++ {
+		periodEnds = append(periodEnds, monthEnd(year))
+	}
+	if len(periodEnds) > 0 {
+		last := periodEnds[len(periodEnds)-1]
+		c.AccountsLastMadeUpDate = day(last)
+		c.AccountsNextDueDate = day(filingDue(monthEnd(last.Year() + 1)))
+	} else {
+		c.AccountsNextDueDate = day(incorporated.AddDate(0, 21, 0))
+	}
+
+	confLast := time.Date(until.Year(), incorporated.Month(), min(incorporated.Day(), 28), 0, 0, 0, 0, time.UTC)
+	if confLast.After(until) {
+		confLast = confLast.AddDate(-1, 0, 0)
+	}
+	if confLast.After(incorporated) {
+		c.ConfStmtLastMadeUpDate = day(confLast)
+		c.ConfStmtNextDueDate = day(confLast.AddDate(1, 0, 14))
+	} else {
+		c.ConfStmtNextDueDate = day(incorporated.AddDate(1, 0, 14))
+	}
+
+	charges := 0
+	if rng.Intn(5) == 0 {
+		charges = 1 + rng.Intn(4)
+	}
+	outstanding := 0
+	if charges > 0 {
+		outstanding = rng.Intn(charges + 1)
+	}
+	c.NumMortCharges, c.NumMortOutstanding, c.NumMortPartSatisfied = ptr(charges), ptr(outstanding), ptr(0)
+
+	if rng.Intn(10) == 0 && g.now.Sub(incorporated) > 2*365*24*time.Hour {
+		previous := fmt.Sprintf("%s %s %s", pick(rng, nameWords), pick(rng, what.nouns), suffix)
+		renamed := incorporated.Add(time.Duration(rng.Int63n(int64(until.Sub(incorporated)))))
+		c.PreviousNames = &previous
+		c.PreviousNameHistory = []database.StagingPreviousName{{Name: previous, EffectiveFrom: day(incorporated), CeasedOn: day(renamed)}}
+	}
+
+	// Size follows a long tail: most companies are micro, a few employ hundreds
+	employees := int(math.Max(1, math.Round(math.Exp(rng.NormFloat64()*1.4+1.2))))
+	dormant := rng.Intn(12) == 0
+	switch {
+	case len(periodEnds) == 0:
+	case dormant:
+		c.AccountCategory = ptr("DORMANT")
+	case employees >= 250:
+		c.AccountCategory = ptr([]string{"FULL", "GROUP"}[rng.Intn(2)])
+	case employees >= 50:
+		c.AccountCategory = ptr([]string{"MEDIUM", "FULL"}[rng.Intn(2)])
+	case employees >= 10:
+		c.AccountCategory = ptr([]string{"SMALL", "TOTAL EXEMPTION FULL", "UNAUDITED ABRIDGED"}[rng.Intn(3)])
+	default:
+		c.AccountCategory = ptr([]string{"MICRO ENTITY", "MICRO ENTITY", "TOTAL EXEMPTION FULL", "UNAUDITED ABRIDGED"}[rng.Intn(4)])
+	}
+
+	s := seeded{
+		company: c,
+		postcode: database.Postcode{
+			Postcode: strings.ReplaceAll(postcode, " ", ""), Latitude: latitude, Longitude: longitude,
+			CountryCode: ptr(countryCodes[where.country]), RegionCode: ptr(where.onsRegion),
+		},
+	}
+	s.officers = g.officers(c, incorporated, until, employees)
+	if len(periodEnds) > 0 {
+		// At most the six most recent periods are filed with the bulk accounts data
+		periodEnds = periodEnds[max(0, len(periodEnds)-6):]
+		s.financials = g.financials(c, periodEnds, what, employees, dormant)
+	}
+	s.company.DataHash = s.company.Hash()
+	return s
+}
+
+// officers appoints a company's directors, and sometimes a secretary, some of whom have
+// resigned. Some are people already appointed elsewhere, so officer networks link companies.
+func (g *generator) officers(c database.StagingCompany, incorporated, until time.Time, employees int) []database.StagingOfficer {
+	rng := g.rng
+	count := 1 + weighted(rng, 40, 35, 15, 10)
+	if employees >= 50 {
+		count += 2 + rng.Intn(3)
+	}
+
+	officers := make([]database.StagingOfficer, 0, count+1)
+	appointed := make(map[string]bool)
+	for i := 0; i <= count; i++ {
+		role := "director"
+		if i == count {
+			if rng.Intn(3) != 0 {
+				break
+			}
+			role = "secretary"
+		}
+
+		var p person
+		if len(g.people) > 50 && rng.Intn(7) == 0 {
+			p = pick(rng, g.people)
+		} else {
+			p = g.person()
+			g.people = append(g.people, p)
+		}
+		if appointed[p.name+p.dateOfBirth] {
+			continue
+		}
+		appointed[p.name+p.dateOfBirth] = true
+
+		// Founding directors are appointed on incorporation; others join later
+		appointedOn := incorporated
+		if i > 0 && rng.Intn(2) == 0 {
+			appointedOn = incorporated.Add(time.Duration(rng.Int63n(int64(until.Sub(incorporated)) + 1)))
+		}
+		o := database.StagingOfficer{
+			CompanyNumber: c.CompanyNumber,
+			OfficerName:   ptr(p.name),
+			OfficerRole:   ptr(role),
+			AppointedOn:   day(appointedOn),
+			AddressLine1:  c.AddressLine1,
+			AddressLine2:  c.AddressLine2,
+			Locality:      c.Locality,
+			PostalCode:    c.PostalCode,
+			Country:       c.Country,
+		}
+		if role == "director" {
+			o.DateOfBirth = ptr(p.dateOfBirth)
+			o.Nationality = ptr(p.nationality)
+		}
+		if until.Sub(appointedOn) > 365*24*time.Hour && rng.Intn(6) == 0 {
+			o.ResignedOn = day(appointedOn.Add(time.Duration(rng.Int63n(int64(until.Sub(appointedOn))))))
+		}
+		o.DataHash = o.Hash()
+		officers = append(officers, o)
+	}
+	return officers
+}
+
+// person makes up a new officer. Officer dates of birth are published as month and year only,
+// so they fall on the first of the month.
+func (g *generator) person() person {
+	rng := g.rng
+	forename := pick(rng, forenames)
+	if rng.Intn(3) == 0 {
+		forename += " " + pick(rng, forenames)
+	}
+	born := time.Date(g.now.Year()-22-rng.Intn(50), time.Month(1+rng.Intn(12)), 1, 0, 0, 0, 0, time.UTC)
+	return person{
+		name:        pick(rng, surnames) + ", " + forename,
+		dateOfBirth: born.Format("2006-01-02"),
+		nationality: []string{"British", "Irish", "Indian", "Polish", "Italian", "Chinese", "American"}[weighted(rng, 85, 3, 4, 3, 2, 2, 1)],
+	}
+}
+
+// financials generates a company's accounts for each period, most companies growing a little
+// each year. Micro and abridged accounts omit the profit and loss account, as they may when filed.
+func (g *generator) financials(c database.StagingCompany, periodEnds []time.Time, what industry, employees int, dormant bool) []database.StagingFinancial {
+	rng := g.rng
+	category := ""
+	if c.AccountCategory != nil {
+		category = *c.AccountCategory
+	}
+	withProfitAndLoss := category == "SMALL" || category == "MEDIUM" || category == "FULL" || category == "GROUP"
+
+	growth := 1 + rng.NormFloat64()*0.08 + 0.03
+	margin := rng.NormFloat64()*0.08 + 0.05
+	latest := float64(employees) * what.revenuePerEmployee * math.Exp(rng.NormFloat64()*0.3)
+	// Work back from the latest period, so the latest figures match the company's current size
+	turnover := latest / math.Pow(growth, float64(len(periodEnds)-1))
+	netAssets := turnover * (rng.Float64()*0.3 - 0.05)
+
+	financials := make([]database.StagingFinancial, 0, len(periodEnds))
+	for _, end := range periodEnds {
+		f := database.StagingFinancial{
+			CompanyNumber: c.CompanyNumber,
+			PeriodStart:   day(end.AddDate(-1, 0, 1)),
+			PeriodEnd:     end.Format("2006-01-02"),
+			Source:        "seed",
+		}
+		if dormant {
+			f.TotalAssets, f.CurrentAssets, f.NetAssets, f.ShareholderFunds = money(100), money(100), money(100), money(100)
+			f.TotalLiabilities, f.CurrentLiabilities, f.Cash = money(0), money(0), money(100)
+			f.NetCurrentAssets = money(100)
+			financials = append(financials, f)
+			continue
+		}
+
+		profit := turnover * (margin + rng.NormFloat64()*0.03)
+		netAssets += profit * 0.8
+		currentAssets := turnover * (0.15 + rng.Float64()*0.25)
+		fixedAssets := turnover * rng.Float64() * 0.3
+		totalAssets := currentAssets + fixedAssets
+		totalLiabilities := totalAssets - netAssets
+		currentLiabilities := math.Min(totalLiabilities, totalLiabilities*(0.5+rng.Float64()*0.5))
+
+		f.TotalAssets, f.CurrentAssets, f.FixedAssets = money(totalAssets), money(currentAssets), money(fixedAssets)
+		f.IntangibleAssets = money(fixedAssets * 0.2)
+		f.TotalLiabilities, f.CurrentLiabilities = money(totalLiabilities), money(currentLiabilities)
+		f.NetCurrentAssets = money(currentAssets - currentLiabilities)
+		f.NetAssets, f.ShareholderFunds = money(netAssets), money(netAssets)
+		f.Cash = money(currentAssets * (0.2 + rng.Float64()*0.5))
+		f.Employees = ptr(max(1, int(math.Round(float64(employees)*turnover/latest))))
+		if withProfitAndLoss {
+			f.Turnover = money(turnover)
+			f.GrossProfit = money(turnover * (0.25 + rng.Float64()*0.35))
+			f.OperatingProfit = money(profit * 1.2)
+			f.ProfitLoss = money(profit)
+		} else if rng.Intn(3) == 0 {
+			f.ProfitLoss = money(profit)
+		}
+		financials = append(financials, f)
+
+		turnover *= growth + rng.NormFloat64()*0.04
+	}
+	return financials
+}
@@ -0,0 +1,193 @@
+// Command seed fills a local staging database with realistic synthetic companies, officers and
+// financials, so the API can be run and tried out without the Companies House bulk data.
+//
+// Usage:
+//
+//	go run ./cmd/seed [-companies N] [-seed N] [-force]
+//
+// Seeded company numbers start with 99, which Companies House has not issued. Each run replaces
+// the companies the previous one seeded, and the same -seed produces the same companies on the
+// same day. The derived data the background jobs maintain (search summaries, health scores,
+// risk ratings and coordinates) is computed afterwards, so every endpoint has data to serve.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/jobs"
+	"data-co/api/migrations"
+)
+
+const (
+	// batchPrefix starts the batch ID of every seed run, so the next run can find its companies
+	batchPrefix = "seed_"
+	// writeBatchSize is how many companies are written per round trip
+	writeBatchSize = 1000
+)
+
+func main() {
+	count := flag.Int("companies", 3000, "number of companies to generate")
+	seed := flag.Int64("seed", 1, "random seed; the same seed generates the same companies")
+	force := flag.Bool("force", false, "seed even if staging holds companies that were not seeded")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n\nReplaces previously seeded companies in the staging database with freshly generated ones.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *count < 1 || *count > 999999 || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables from .env file if it exists
+	_ = godotenv.Load("../.env") // Ignore error, env vars may come from docker-compose
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	// Seeding writes to the primary, and the derived data jobs can take longer than an API
+	// query is allowed to
+	cfg.Database.ReplicaDSN = ""
+	cfg.Database.StatementTimeout = 0
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	log.Printf("Connected to database: %s", cfg.Database.Name)
+
+	pending, err := migrations.Pending(ctx, db)
+	if err != nil {
+		log.Fatalf("Failed to check schema: %v", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("%d schema migrations are pending, starting with %s; run the migrate command first", len(pending), pending[0].Name)
+	}
+
+	if real, err := db.HasCompaniesOutsideBatches(ctx, batchPrefix); err != nil {
+		log.Fatalf("Failed to check staging: %v", err)
+	} else if real && !*force {
+		log.Fatalf("Staging holds companies that were not seeded; pass -force to seed alongside them")
+	}
+
+	if err := run(ctx, db, *count, *seed); err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+}
+
+// run replaces the seeded companies with count generated ones, then computes their derived data
+func run(ctx context.Context, db *database.DB, count int, seed int64) error {
+	started := time.Now()
+	batchID := batchPrefix + started.Format("20060102_150405")
+
+	deleted, err := db.DeleteCompanyBatches(ctx, batchPrefix)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d previously seeded companies", deleted)
+	}
+
+	if err := db.StartIngestionLog(ctx, batchID, "seed", []string{}); err != nil {
+		return err
+	}
+	officers, financials, postcodes, err := write(ctx, db, batchID, newGenerator(seed, started), count)
+	if err != nil {
+		if logErr := db.FinishIngestionLog(context.WithoutCancel(ctx), batchID, err.Error()); logErr != nil {
+			log.Printf("Failed to record seed failure: %v", logErr)
+		}
+		return err
+	}
+	if err := db.UpdateIngestionProgress(ctx, batchID, 0, "", 100, int64(count)); err != nil {
+		log.Printf("Failed to record seed progress: %v", err)
+	}
+	if err := db.FinishIngestionLog(ctx, batchID, ""); err != nil {
+		log.Printf("Failed to record seed completion: %v", err)
+	}
+	log.Printf("Seeded %d companies, %d officers and %d financial periods", count, officers, financials)
+
+	// Seeded postcodes would overwrite accurate coordinates from a loaded postcode directory
+	if loaded, err := db.HasPostcodesOutsideBatches(ctx, batchPrefix); err != nil {
+		return err
+	} else if !loaded {
+		if _, err := db.ImportPostcodes(ctx, batchID, postcodes); err != nil {
+			return err
+		}
+	}
+
+	if err := db.RefreshSearchSummaries(ctx); err != nil {
+		return err
+	}
+	scored, err := jobs.ScoreHealth(ctx, db)
+	if err != nil {
+		return fmt.Errorf("health scoring failed: %w", err)
+	}
+	rated, err := jobs.RateRisk(ctx, db)
+	if err != nil {
+		return fmt.Errorf("risk rating failed: %w", err)
+	}
+	geocoded, err := jobs.Geocode(ctx, db)
+	if err != nil {
+		return fmt.Errorf("geocoding failed: %w", err)
+	}
+	log.Printf("Scored %d companies, rated %d and geocoded %d", scored, rated, geocoded)
+
+	log.Printf("Seed %s completed in %s", batchID, time.Since(started).Round(time.Second))
+	return nil
+}
+
+// write generates and stores count companies in batches, and returns how many officers and
+// financial periods they have, with their postcodes
+func write(ctx context.Context, db *database.DB, batchID string, g *generator, count int) (int64, int64, []database.Postcode, error) {
+	var officerCount, financialCount int64
+	postcodes := make([]database.Postcode, 0, count)
+	seen := make(map[string]bool, count)
+
+	for start := 1; start <= count; start += writeBatchSize {
+		size := min(writeBatchSize, count-start+1)
+		companies := make([]database.StagingCompany, 0, size)
+		var officers []database.StagingOfficer
+		var financials []database.StagingFinancial
+		for n := start; n < start+size; n++ {
+			s := g.company(n)
+			companies = append(companies, s.company)
+			officers = append(officers, s.officers...)
+			financials = append(financials, s.financials...)
+			if !seen[s.postcode.Postcode] {
+				seen[s.postcode.Postcode] = true
+				postcodes = append(postcodes, s.postcode)
+			}
+		}
+
+		if _, err := db.ImportCompanies(ctx, batchID, companies); err != nil {
+			return 0, 0, nil, err
+		}
+		written, err := db.SeedOfficers(ctx, officers)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		officerCount += written
+		if written, err = db.SeedFinancials(ctx, batchID, financials); err != nil {
+			return 0, 0, nil, err
+		}
+		financialCount += written
+	}
+	return officerCount, financialCount, postcodes, nil
+}
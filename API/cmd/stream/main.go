@@ -0,0 +1,66 @@
+// Command stream consumes the Companies House streaming API and upserts changed companies
+// and officers into the staging tables as they are published.
+package main
+
+import (
+	"context"
+	"log"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/streaming"
+)
+
+func main() {
+	// Load environment variables from .env file if it exists
+	_ = godotenv.Load("../.env") // Ignore error, env vars may come from docker-compose
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.Stream.APIKey == "" {
+		log.Fatalf("COMPANIES_HOUSE_STREAM_KEY is required")
+	}
+
+	// Cancelled on SIGINT/SIGTERM; each stream checkpoints its position before exiting
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	log.Printf("Connected to database: %s", cfg.Database.Name)
+
+	for _, stream := range cfg.Stream.Streams {
+		if !streaming.ValidStream(stream) {
+			log.Fatalf("Unknown stream %q in STREAM_RESOURCES", stream)
+		}
+	}
+
+	client := streaming.NewClient(cfg.Stream.BaseURL, cfg.Stream.APIKey)
+
+	var wg sync.WaitGroup
+	for _, stream := range cfg.Stream.Streams {
+		ingester := streaming.NewIngester(db, client, stream)
+		wg.Add(1)
+		go func(stream string) {
+			defer wg.Done()
+			if err := ingester.Run(ctx); err != nil {
+				log.Printf("%s stream failed: %v", stream, err)
+				stop()
+			}
+		}(stream)
+	}
+
+	wg.Wait()
+	log.Printf("Stream ingester stopped")
+}
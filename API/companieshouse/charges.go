@@ -0,0 +1,111 @@
+package companieshouse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"data-co/api/database"
+)
+
+// chargesPageSize is the largest page the charges endpoint returns
+const chargesPageSize = 100
+
+// CompanyCharges fetches every charge registered against a company. A company with no
+// charges register returns an empty list.
+func (c *Client) CompanyCharges(ctx context.Context, companyNumber string) ([]database.StagingCharge, error) {
+	charges := make([]database.StagingCharge, 0)
+	for start := 0; ; start += chargesPageSize {
+		var page struct {
+			TotalCount int               `json:"total_count"`
+			Items      []json.RawMessage `json:"items"`
+		}
+		query := url.Values{
+			"items_per_page": {strconv.Itoa(chargesPageSize)},
+			"start_index":    {strconv.Itoa(start)},
+		}
+		err := c.get(ctx, "/company/"+url.PathEscape(companyNumber)+"/charges", query, &page)
+		if errors.Is(err, ErrNotFound) {
+			return charges, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Items {
+			charge, err := ParseCharge(companyNumber, item)
+			if err != nil {
+				return nil, err
+			}
+			charges = append(charges, charge)
+		}
+
+		if len(page.Items) < chargesPageSize || start+len(page.Items) >= page.TotalCount {
+			return charges, nil
+		}
+	}
+}
+
+// chargeData is the subset of a charge resource stored in staging
+type chargeData struct {
+	ChargeCode     *string `json:"charge_code"`
+	ChargeNumber   *int    `json:"charge_number"`
+	Classification struct {
+		Description *string `json:"description"`
+	} `json:"classification"`
+	Status          *string `json:"status"`
+	CreatedOn       *string `json:"created_on"`
+	DeliveredOn     *string `json:"delivered_on"`
+	SatisfiedOn     *string `json:"satisfied_on"`
+	PersonsEntitled []struct {
+		Name string `json:"name"`
+	} `json:"persons_entitled"`
+	Particulars struct {
+		Description *string `json:"description"`
+	} `json:"particulars"`
+	Links struct {
+		Self string `json:"self"`
+	} `json:"links"`
+}
+
+// ParseCharge converts a charge resource, as returned by both the REST and streaming APIs,
+// into a staging row
+func ParseCharge(companyNumber string, data json.RawMessage) (database.StagingCharge, error) {
+	var d chargeData
+	if err := json.Unmarshal(data, &d); err != nil {
+		return database.StagingCharge{}, fmt.Errorf("invalid charge: %w", err)
+	}
+
+	chargeID := path.Base(d.Links.Self)
+	if d.Links.Self == "" || chargeID == "/" || chargeID == "." {
+		return database.StagingCharge{}, fmt.Errorf("charge for %s has no self link", companyNumber)
+	}
+
+	charge := database.StagingCharge{
+		CompanyNumber:   companyNumber,
+		ChargeID:        chargeID,
+		ChargeCode:      d.ChargeCode,
+		ChargeNumber:    d.ChargeNumber,
+		Classification:  d.Classification.Description,
+		Status:          d.Status,
+		CreatedOn:       d.CreatedOn,
+		DeliveredOn:     d.DeliveredOn,
+		SatisfiedOn:     d.SatisfiedOn,
+		PersonsEntitled: make([]string, 0, len(d.PersonsEntitled)),
+		Particulars:     d.Particulars.Description,
+		RawData:         data,
+	}
+	for _, person := range d.PersonsEntitled {
+		if name := strings.TrimSpace(person.Name); name != "" {
+			charge.PersonsEntitled = append(charge.PersonsEntitled, name)
+		}
+	}
+
+	charge.DataHash = charge.Hash()
+	return charge, nil
+}
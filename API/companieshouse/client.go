@@ -0,0 +1,105 @@
+// Package companieshouse is a minimal client for the Companies House public data REST API.
+package companieshouse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxRetries bounds how often a rate-limited or failed request is retried
+const maxRetries = 5
+
+// ErrNotFound is returned when a resource does not exist
+var ErrNotFound = errors.New("not found")
+
+// Client calls the Companies House REST API, staying under the per-key rate limit
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+	limiter *rate.Limiter
+}
+
+// NewClient creates a REST API client making at most requestsPerSecond requests
+func NewClient(baseURL, apiKey string, requestsPerSecond float64) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 30 * time.Second},
+		limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+	}
+}
+
+// get fetches path into v. Rate-limited (429) and server error responses are retried with backoff.
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return fmt.Errorf("invalid request: %w", err)
+		}
+		req.SetBasicAuth(c.apiKey, "")
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || attempt == maxRetries {
+				return fmt.Errorf("GET %s failed: %w", path, err)
+			}
+		} else {
+			retry, err := c.decode(resp, path, v)
+			if !retry || attempt == maxRetries {
+				return err
+			}
+			if wait, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+				delay = time.Duration(wait) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, time.Minute)
+	}
+}
+
+// decode reads a response into v, reporting whether the request should be retried
+func (c *Client) decode(resp *http.Response, path string, v any) (bool, error) {
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return false, fmt.Errorf("invalid response from %s: %w", path, err)
+		}
+		return false, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		io.Copy(io.Discard, resp.Body)
+		return true, fmt.Errorf("GET %s responded %s", path, resp.Status)
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, fmt.Errorf("GET %s responded %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+}
@@ -0,0 +1,116 @@
+package companieshouse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"data-co/api/database"
+)
+
+// insolvencyEndDates are the case date types that mean a case is over
+var insolvencyEndDates = map[string]bool{
+	"concluded-winding-up-on":        true,
+	"completion-of-winding-up-on":    true,
+	"dissolved-on":                   true,
+	"case-end-on":                    true,
+	"administration-ended-on":        true,
+	"administration-discharged-on":   true,
+	"voluntary-arrangement-ended-on": true,
+	"moratorium-ended-on":            true,
+}
+
+// CompanyInsolvency fetches every insolvency case recorded against a company. A company with
+// no insolvency history returns an empty list.
+func (c *Client) CompanyInsolvency(ctx context.Context, companyNumber string) ([]database.StagingInsolvencyCase, error) {
+	var resource json.RawMessage
+	err := c.get(ctx, "/company/"+url.PathEscape(companyNumber)+"/insolvency", nil, &resource)
+	if errors.Is(err, ErrNotFound) {
+		return []database.StagingInsolvencyCase{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseInsolvency(companyNumber, resource)
+}
+
+// insolvencyResource is the subset of a company insolvency resource stored in staging
+type insolvencyResource struct {
+	Cases []json.RawMessage `json:"cases"`
+}
+
+type insolvencyCase struct {
+	Type   string      `json:"type"`
+	Number json.Number `json:"number"`
+	Dates  []struct {
+		Type string `json:"type"`
+		Date string `json:"date"`
+	} `json:"dates"`
+	Practitioners []struct {
+		Name string `json:"name"`
+	} `json:"practitioners"`
+}
+
+// ParseInsolvency converts a company insolvency resource, as returned by both the REST API and
+// the insolvency-cases stream, into staging rows, one per case
+func ParseInsolvency(companyNumber string, data json.RawMessage) ([]database.StagingInsolvencyCase, error) {
+	var r insolvencyResource
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid insolvency resource: %w", err)
+	}
+
+	cases := make([]database.StagingInsolvencyCase, 0, len(r.Cases))
+	for _, raw := range r.Cases {
+		var d insolvencyCase
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return nil, fmt.Errorf("invalid insolvency case for %s: %w", companyNumber, err)
+		}
+		number, err := d.Number.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("insolvency case for %s has no case number", companyNumber)
+		}
+		if d.Type == "" {
+			return nil, fmt.Errorf("insolvency case %d for %s has no type", number, companyNumber)
+		}
+
+		// Dates are YYYY-MM-DD, so they order correctly as strings
+		sort.SliceStable(d.Dates, func(i, j int) bool { return d.Dates[i].Date < d.Dates[j].Date })
+		dates, err := json.Marshal(d.Dates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode insolvency case dates: %w", err)
+		}
+
+		ins := database.StagingInsolvencyCase{
+			CompanyNumber: companyNumber,
+			CaseNumber:    int(number),
+			CaseType:      d.Type,
+			Dates:         dates,
+			Practitioners: make([]string, 0, len(d.Practitioners)),
+			RawData:       raw,
+		}
+		for _, date := range d.Dates {
+			if date.Date == "" {
+				continue
+			}
+			value := date.Date
+			if insolvencyEndDates[date.Type] {
+				ins.EndedOn = &value
+			} else if ins.StartedOn == nil {
+				ins.StartedOn = &value
+			}
+		}
+		for _, practitioner := range d.Practitioners {
+			if name := strings.TrimSpace(practitioner.Name); name != "" {
+				ins.Practitioners = append(ins.Practitioners, name)
+			}
+		}
+
+		ins.DataHash = ins.Hash()
+		cases = append(cases, ins)
+	}
+	return cases, nil
+}
@@ -0,0 +1,13 @@
+package companieshouse
+
+import "strings"
+
+// NormalizeCompanyNumber uppercases a Companies House number and restores leading zeros
+// dropped by spreadsheets (e.g. "1234567" -> "01234567")
+func NormalizeCompanyNumber(number string) string {
+	number = strings.ToUpper(strings.TrimSpace(number))
+	if number != "" && len(number) < 8 && strings.Trim(number, "0123456789") == "" {
+		number = strings.Repeat("0", 8-len(number)) + number
+	}
+	return number
+}
@@ -0,0 +1,17 @@
+package companieshouse
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// CompanyProfile fetches the profile resource of a company, in the same form the streaming API
+// publishes it. It returns ErrNotFound if Companies House has no such company.
+func (c *Client) CompanyProfile(ctx context.Context, companyNumber string) (json.RawMessage, error) {
+	var profile json.RawMessage
+	if err := c.get(ctx, "/company/"+url.PathEscape(companyNumber), nil, &profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
@@ -1,13 +1,32 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all application configuration
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	Database  DatabaseConfig
+	Server    ServerConfig
+	Jobs      JobsConfig
+	Auth      AuthConfig
+	RateLimit RateLimitConfig
+	Webhooks  WebhooksConfig
+	Exports   ExportsConfig
+	Mail      MailConfig
+	Stream    StreamConfig
+
+	CompaniesHouse CompaniesHouseConfig
+	HMRC           HMRCConfig
+	Websites       WebsitesConfig
+	Contacts       ContactsConfig
+	Salesforce     SalesforceConfig
+	HubSpot        HubSpotConfig
+	Slack          SlackConfig
 }
 
 // DatabaseConfig holds database connection settings
@@ -18,16 +37,246 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	SSLMode  string
+
+	// StatementTimeout bounds every query, both server-side and via the request context
+	StatementTimeout time.Duration
+	// MaxStatementTimeout bounds the query timeout a request can ask for. It is also the
+	// server-side bound when longer than StatementTimeout.
+	MaxStatementTimeout time.Duration
+
+	// Connection pool settings
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	HealthCheckPeriod time.Duration
+
+	// ReplicaDSN is a read replica search, count and detail queries are routed to
+	ReplicaDSN           string
+	ReplicaCheckInterval time.Duration
+
+	// RetryAttempts is how many times a statement is tried while it fails with a transient
+	// error, waiting about RetryBackoff before the second and doubling that each time
+	RetryAttempts int
+	RetryBackoff  time.Duration
+	// CircuitThreshold consecutive failures to reach the primary open its circuit breaker,
+	// failing calls fast for CircuitCooldown; 0 disables it
+	CircuitThreshold int
+	CircuitCooldown  time.Duration
+
+	// AutoMigrate applies pending schema migrations at API startup instead of refusing to start
+	AutoMigrate bool
 }
 
 // ServerConfig holds server settings
 type ServerConfig struct {
-	Port string
+	Port            string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	TLS             TLSConfig
+
+	// MaxSearchLimit bounds the limit of a company search
+	MaxSearchLimit int
+}
+
+// TLSConfig holds native TLS settings, for deployments without a TLS-terminating proxy. The
+// server speaks plain HTTP unless a certificate file or autocert domains are set.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// AutocertDomains are obtained and renewed from an ACME CA instead of read from files
+	AutocertDomains      []string
+	AutocertEmail        string
+	AutocertCacheDir     string
+	AutocertDirectoryURL string
+	// AutocertHTTPPort answers http-01 challenges and redirects to HTTPS; "0" leaves
+	// tls-alpn-01 on the TLS port as the only challenge
+	AutocertHTTPPort string
+}
+
+// AuthConfig holds API authentication settings
+type AuthConfig struct {
+	// Enabled requires a valid API key on every /api route except health checks
+	Enabled bool
+	// AdminAPIKey is a bootstrap admin key accepted without a database record
+	AdminAPIKey string
+	JWT         JWTConfig
+}
+
+// JWTConfig holds bearer JWT validation settings. JWTs are accepted when either key is set.
+type JWTConfig struct {
+	HMACSecret       string // HS256 shared secret
+	RSAPublicKeyFile string // PEM file with the RS256 verification key
+	Issuer           string // Required "iss" claim, if set
+	Audience         string // Required "aud" claim, if set
+	RoleClaim        string // Claim holding the caller's role(s)
+}
+
+// RateLimitConfig holds per-caller rate limiting settings
+type RateLimitConfig struct {
+	Enabled bool
+	// Tiers maps a tier name to its limits. Callers with an unknown tier get the "standard" limits
+	// and anonymous callers (rate limited per IP) get the "anonymous" limits.
+	Tiers map[string]RateLimit
+	// TrustForwardedFor keys anonymous callers by X-Forwarded-For (only safe behind a proxy)
+	TrustForwardedFor bool
+}
+
+// RateLimit is a token bucket refilled at RequestsPerSecond holding up to Burst tokens
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// JobsConfig holds background job settings
+type JobsConfig struct {
+	SummaryRefreshInterval  time.Duration
+	ChangeDetectionInterval time.Duration
+	HealthScoreInterval     time.Duration
+	RiskRatingInterval      time.Duration
+	GeocodeInterval         time.Duration
+	IndustryRefreshInterval time.Duration
+	SavedSearchInterval     time.Duration
+}
+
+// WebhooksConfig holds webhook delivery settings
+type WebhooksConfig struct {
+	PollInterval time.Duration // How often the dispatcher looks for due deliveries
+	MaxAttempts  int           // Attempts before a delivery is dead-lettered
+	Timeout      time.Duration // Per-request timeout when calling subscriber URLs
+
+	SearchInterval time.Duration // How often new companies are matched against saved search subscriptions
+}
+
+// ExportsConfig holds background export settings
+type ExportsConfig struct {
+	Dir          string        // Directory export files are written to, and kept in with local storage
+	Workers      int           // Exports run at the same time
+	PollInterval time.Duration // How often idle workers look for queued exports
+	Retention    time.Duration // How long finished files can be downloaded
+	MaxAttempts  int           // Claims before an export that keeps stopping is failed
+	Storage      string        // Where finished files are kept: "local", "s3" or "gcs"
+	Bucket       BucketConfig
+	PublicURL    string // Base URL of the API in emailed download links
+}
+
+// MailConfig holds the SMTP server export emails are sent through, e.g. Amazon SES's SMTP
+// interface
+type MailConfig struct {
+	Host          string // Empty disables email delivery
+	Port          int    // 465 connects with TLS; other ports upgrade with STARTTLS when offered
+	Username      string
+	Password      string
+	From          string
+	Timeout       time.Duration // For sending one email, including attachments
+	MaxAttachment int64         // Largest file attached, in bytes; larger files are linked
+}
+
+// BucketConfig holds the S3 or GCS bucket finished exports are uploaded to
+type BucketConfig struct {
+	Name            string
+	Prefix          string // Prepended to every object key, before the per-tenant prefix
+	Region          string // Defaults to us-east-1 for S3 and auto for GCS
+	Endpoint        string // S3-compatible endpoint; defaults to AWS S3 for the region or storage.googleapis.com
+	AccessKeyID     string // For GCS, an HMAC key of a service account
+	SecretAccessKey string
+	URLExpiry       time.Duration // How long signed download URLs are valid
+}
+
+// SalesforceConfig holds the Salesforce connected app companies are pushed through
+type SalesforceConfig struct {
+	LoginURL        string // My Domain URL, e.g. https://acme.my.salesforce.com
+	ClientID        string // Consumer key; empty disables the integration
+	ClientSecret    string
+	APIVersion      string
+	ExternalIDField string // External ID field holding the company number, used to dedupe
+	AccountFields   string // Account field mapping, "SalesforceField=company_field,..."
+	LeadFields      string // Lead field mapping
+	Timeout         time.Duration
+}
+
+// HubSpotConfig holds the HubSpot private app companies are synced through
+type HubSpotConfig struct {
+	AccessToken       string // Private app access token; empty disables the integration
+	BaseURL           string
+	IDProperty        string // Unique company property holding the company number, used to dedupe
+	Fields            string // Company property mapping, "hubspotProperty=company_field,..."
+	RequestsPerSecond float64
+	PollInterval      time.Duration // How often the sync worker looks for queued syncs
+	MaxAttempts       int           // Claims before a sync that keeps stopping is failed
+	Timeout           time.Duration
+}
+
+// SlackConfig holds settings for posting watchlist changes to Slack incoming webhooks
+type SlackConfig struct {
+	Timeout time.Duration
 }
 
-// LoadConfig loads configuration from environment variables
-func LoadConfig() *Config {
-	return &Config{
+// StreamConfig holds Companies House streaming API ingester settings
+type StreamConfig struct {
+	APIKey  string   // Streaming API key (distinct from the REST API key)
+	BaseURL string   // Streaming API base URL
+	Streams []string // Streams to consume, e.g. "companies", "officers"
+}
+
+// HMRCConfig holds the HMRC application VAT numbers are checked with
+type HMRCConfig struct {
+	BaseURL           string
+	ClientID          string // Application client ID; empty disables VAT checks
+	ClientSecret      string
+	RequestsPerSecond float64       // HMRC allows 3 requests per second per application
+	CheckInterval     time.Duration // How often the VAT check job runs
+	RecheckAfter      time.Duration // How long before a checked VAT number is checked again
+	Timeout           time.Duration
+}
+
+// WebsitesConfig holds website enrichment settings
+type WebsitesConfig struct {
+	Providers         []string      // Tried in order until one finds a website: "guess" and "http"; none disables enrichment
+	ProviderURL       string        // Lookup endpoint of the "http" provider
+	ProviderAPIKey    string        // Bearer token sent to ProviderURL
+	MinConfidence     float64       // Lowest match score, from 0 to 1, a website is stored with
+	Interval          time.Duration // How often the enrichment job runs
+	MaxPerRun         int           // Companies looked up per run, bounding outbound requests
+	RecheckAfter      time.Duration // How long before a company is looked up again
+	RequestsPerSecond float64       // Outbound requests, to providers and guessed domains together
+	Timeout           time.Duration
+}
+
+// ContactsConfig holds contact enrichment settings
+type ContactsConfig struct {
+	HunterAPIKey   string // Hunter.io API key; empty disables contact enrichment
+	HunterURL      string
+	MonthlyCredits int           // Lookups each tenant can make a month; 0 is unlimited
+	CacheTTL       time.Duration // How long a tenant's enriched contacts are returned without a new lookup
+	Timeout        time.Duration
+}
+
+// CompaniesHouseConfig holds Companies House REST API settings used by importers and the live
+// lookup of companies missing from the database
+type CompaniesHouseConfig struct {
+	APIKey            string
+	BaseURL           string
+	RequestsPerSecond float64       // Companies House allows 600 requests per 5 minutes per key
+	LiveFallback      bool          // Fetch companies missing from the database from the REST API
+	LiveCacheTTL      time.Duration // How long a live lookup's result, found or not, is reused
+	LiveTimeout       time.Duration // Of a live lookup, including retries
+}
+
+// LoadConfig loads configuration from environment variables, after filling unset ones from
+// the JSON file named by CONFIG_FILE. Malformed values and missing required settings
+// are reported together in the error.
+func LoadConfig() (*Config, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l := &loader{}
+	statementTimeout := l.getDuration("DB_STATEMENT_TIMEOUT", 30*time.Second)
+	cfg := &Config{
 		Database: DatabaseConfig{
 			Host:     os.Getenv("STAGING_DB_HOST"),
 			Port:     os.Getenv("STAGING_DB_PORT"),
@@ -35,11 +284,171 @@ func LoadConfig() *Config {
 			User:     os.Getenv("STAGING_DB_USER"),
 			Password: os.Getenv("STAGING_DB_PASSWORD"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			StatementTimeout:    statementTimeout,
+			MaxStatementTimeout: l.getDuration("DB_MAX_STATEMENT_TIMEOUT", statementTimeout),
+
+			MaxConns:          int32(l.getInt("DB_MAX_CONNS", 25)),
+			MinConns:          int32(l.getInt("DB_MIN_CONNS", 5)),
+			MaxConnLifetime:   l.getDuration("DB_MAX_CONN_LIFETIME", time.Hour),
+			HealthCheckPeriod: l.getDuration("DB_HEALTH_CHECK_PERIOD", time.Minute),
+
+			ReplicaDSN:           os.Getenv("DB_REPLICA_DSN"),
+			ReplicaCheckInterval: l.getDuration("DB_REPLICA_CHECK_INTERVAL", 10*time.Second),
+
+			RetryAttempts:    l.getInt("DB_RETRY_ATTEMPTS", 3),
+			RetryBackoff:     l.getDuration("DB_RETRY_BACKOFF", 50*time.Millisecond),
+			CircuitThreshold: l.getInt("DB_CIRCUIT_THRESHOLD", 5),
+			CircuitCooldown:  l.getDuration("DB_CIRCUIT_COOLDOWN", 10*time.Second),
+
+			AutoMigrate: l.getBool("DB_AUTO_MIGRATE", false),
 		},
 		Server: ServerConfig{
-			Port: os.Getenv("API_PORT"),
+			Port:            os.Getenv("API_PORT"),
+			ReadTimeout:     l.getDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:    l.getDuration("SERVER_WRITE_TIMEOUT", 60*time.Second),
+			IdleTimeout:     l.getDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
+			ShutdownTimeout: l.getDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			MaxSearchLimit:  l.getInt("SEARCH_MAX_LIMIT", 10000),
+			TLS: TLSConfig{
+				CertFile:             os.Getenv("TLS_CERT_FILE"),
+				KeyFile:              os.Getenv("TLS_KEY_FILE"),
+				AutocertDomains:      getList("TLS_AUTOCERT_DOMAINS", ""),
+				AutocertEmail:        os.Getenv("TLS_AUTOCERT_EMAIL"),
+				AutocertCacheDir:     getEnv("TLS_AUTOCERT_CACHE_DIR", "certs"),
+				AutocertDirectoryURL: getEnv("TLS_AUTOCERT_DIRECTORY_URL", "https://acme-v02.api.letsencrypt.org/directory"),
+				AutocertHTTPPort:     getEnv("TLS_AUTOCERT_HTTP_PORT", "80"),
+			},
+		},
+		Auth: AuthConfig{
+			Enabled:     l.getBool("AUTH_ENABLED", false),
+			AdminAPIKey: os.Getenv("ADMIN_API_KEY"),
+			JWT: JWTConfig{
+				HMACSecret:       os.Getenv("JWT_HS256_SECRET"),
+				RSAPublicKeyFile: os.Getenv("JWT_RS256_PUBLIC_KEY_FILE"),
+				Issuer:           os.Getenv("JWT_ISSUER"),
+				Audience:         os.Getenv("JWT_AUDIENCE"),
+				RoleClaim:        getEnv("JWT_ROLE_CLAIM", "role"),
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           l.getBool("RATE_LIMIT_ENABLED", true),
+			Tiers:             l.getRateLimits("RATE_LIMIT_TIERS", "anonymous=2:10,standard=10:20,premium=50:100"),
+			TrustForwardedFor: l.getBool("RATE_LIMIT_TRUST_FORWARDED_FOR", false),
+		},
+		Jobs: JobsConfig{
+			SummaryRefreshInterval:  l.getDuration("SUMMARY_REFRESH_INTERVAL", time.Hour),
+			ChangeDetectionInterval: l.getDuration("CHANGE_DETECTION_INTERVAL", 15*time.Minute),
+			HealthScoreInterval:     l.getDuration("HEALTH_SCORE_INTERVAL", time.Hour),
+			RiskRatingInterval:      l.getDuration("RISK_RATING_INTERVAL", 24*time.Hour),
+			GeocodeInterval:         l.getDuration("GEOCODE_INTERVAL", 24*time.Hour),
+			IndustryRefreshInterval: l.getDuration("INDUSTRY_REFRESH_INTERVAL", 5*time.Minute),
+			SavedSearchInterval:     l.getDuration("SAVED_SEARCH_INTERVAL", 24*time.Hour),
 		},
+		Webhooks: WebhooksConfig{
+			PollInterval: l.getDuration("WEBHOOK_POLL_INTERVAL", 10*time.Second),
+			MaxAttempts:  l.getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			Timeout:      l.getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+			SearchInterval: l.getDuration("WEBHOOK_SEARCH_INTERVAL", 15*time.Minute),
+		},
+		Exports: ExportsConfig{
+			Dir:          getEnv("EXPORT_DIR", "exports"),
+			Workers:      l.getInt("EXPORT_WORKERS", 2),
+			PollInterval: l.getDuration("EXPORT_POLL_INTERVAL", 5*time.Second),
+			Retention:    l.getDuration("EXPORT_RETENTION", 24*time.Hour),
+			MaxAttempts:  l.getInt("EXPORT_MAX_ATTEMPTS", 3),
+			Storage:      getEnv("EXPORT_STORAGE", "local"),
+			Bucket: BucketConfig{
+				Name:            os.Getenv("EXPORT_BUCKET"),
+				Prefix:          getEnv("EXPORT_BUCKET_PREFIX", "exports/"),
+				Region:          getEnv("EXPORT_BUCKET_REGION", os.Getenv("AWS_REGION")),
+				Endpoint:        os.Getenv("EXPORT_BUCKET_ENDPOINT"),
+				AccessKeyID:     getEnv("EXPORT_BUCKET_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID")),
+				SecretAccessKey: getEnv("EXPORT_BUCKET_SECRET_ACCESS_KEY", os.Getenv("AWS_SECRET_ACCESS_KEY")),
+				URLExpiry:       l.getDuration("EXPORT_URL_EXPIRY", 15*time.Minute),
+			},
+			PublicURL: strings.TrimSuffix(getEnv("EXPORT_PUBLIC_URL", "http://localhost:"+getEnv("API_PORT", "8080")), "/"),
+		},
+		Mail: MailConfig{
+			Host:          os.Getenv("SMTP_HOST"),
+			Port:          l.getInt("SMTP_PORT", 587),
+			Username:      os.Getenv("SMTP_USERNAME"),
+			Password:      os.Getenv("SMTP_PASSWORD"),
+			From:          os.Getenv("MAIL_FROM"),
+			Timeout:       l.getDuration("SMTP_TIMEOUT", 2*time.Minute),
+			MaxAttachment: int64(l.getInt("EXPORT_EMAIL_MAX_ATTACHMENT_MB", 10)) << 20,
+		},
+		Salesforce: SalesforceConfig{
+			LoginURL:        os.Getenv("SALESFORCE_LOGIN_URL"),
+			ClientID:        os.Getenv("SALESFORCE_CLIENT_ID"),
+			ClientSecret:    os.Getenv("SALESFORCE_CLIENT_SECRET"),
+			APIVersion:      getEnv("SALESFORCE_API_VERSION", "v60.0"),
+			ExternalIDField: getEnv("SALESFORCE_EXTERNAL_ID_FIELD", "Company_Number__c"),
+			AccountFields: getEnv("SALESFORCE_ACCOUNT_FIELDS",
+				"Name=company_name,BillingCity=locality,BillingState=region,BillingPostalCode=postal_code,Sic=primary_sic_code,AnnualRevenue=turnover"),
+			LeadFields: getEnv("SALESFORCE_LEAD_FIELDS",
+				"Company=company_name,LastName=company_name,City=locality,State=region,PostalCode=postal_code,AnnualRevenue=turnover"),
+			Timeout: l.getDuration("SALESFORCE_TIMEOUT", 60*time.Second),
+		},
+		HubSpot: HubSpotConfig{
+			AccessToken:       os.Getenv("HUBSPOT_ACCESS_TOKEN"),
+			BaseURL:           getEnv("HUBSPOT_API_URL", "https://api.hubapi.com"),
+			IDProperty:        getEnv("HUBSPOT_ID_PROPERTY", "company_number"),
+			Fields:            getEnv("HUBSPOT_FIELDS", "name=company_name,city=locality,state=region,zip=postal_code,annualrevenue=turnover"),
+			RequestsPerSecond: l.getFloat("HUBSPOT_REQUESTS_PER_SECOND", 9),
+			PollInterval:      l.getDuration("HUBSPOT_POLL_INTERVAL", 5*time.Second),
+			MaxAttempts:       l.getInt("HUBSPOT_MAX_ATTEMPTS", 3),
+			Timeout:           l.getDuration("HUBSPOT_TIMEOUT", 30*time.Second),
+		},
+		Slack: SlackConfig{
+			Timeout: l.getDuration("SLACK_TIMEOUT", 10*time.Second),
+		},
+		Stream: StreamConfig{
+			APIKey:  os.Getenv("COMPANIES_HOUSE_STREAM_KEY"),
+			BaseURL: getEnv("COMPANIES_HOUSE_STREAM_URL", "https://stream.companieshouse.gov.uk"),
+			Streams: getList("STREAM_RESOURCES", "companies,officers,persons-with-significant-control,charges,insolvency-cases"),
+		},
+		HMRC: HMRCConfig{
+			BaseURL:           getEnv("HMRC_API_URL", "https://api.service.hmrc.gov.uk"),
+			ClientID:          os.Getenv("HMRC_CLIENT_ID"),
+			ClientSecret:      os.Getenv("HMRC_CLIENT_SECRET"),
+			RequestsPerSecond: l.getFloat("HMRC_REQUESTS_PER_SECOND", 2.5),
+			CheckInterval:     l.getDuration("VAT_CHECK_INTERVAL", 24*time.Hour),
+			RecheckAfter:      l.getDuration("VAT_RECHECK_AFTER", 90*24*time.Hour),
+			Timeout:           l.getDuration("HMRC_TIMEOUT", 30*time.Second),
+		},
+		Websites: WebsitesConfig{
+			Providers:         getList("WEBSITE_PROVIDERS", ""),
+			ProviderURL:       os.Getenv("WEBSITE_PROVIDER_URL"),
+			ProviderAPIKey:    os.Getenv("WEBSITE_PROVIDER_API_KEY"),
+			MinConfidence:     l.getFloat("WEBSITE_MIN_CONFIDENCE", 0.8),
+			Interval:          l.getDuration("WEBSITE_ENRICHMENT_INTERVAL", 24*time.Hour),
+			MaxPerRun:         l.getInt("WEBSITE_MAX_PER_RUN", 1000),
+			RecheckAfter:      l.getDuration("WEBSITE_RECHECK_AFTER", 180*24*time.Hour),
+			RequestsPerSecond: l.getFloat("WEBSITE_REQUESTS_PER_SECOND", 5),
+			Timeout:           l.getDuration("WEBSITE_TIMEOUT", 10*time.Second),
+		},
+		Contacts: ContactsConfig{
+			HunterAPIKey:   os.Getenv("HUNTER_API_KEY"),
+			HunterURL:      getEnv("HUNTER_API_URL", "https://api.hunter.io/v2"),
+			MonthlyCredits: l.getInt("CONTACT_CREDITS_PER_MONTH", 100),
+			CacheTTL:       l.getDuration("CONTACT_ENRICHMENT_TTL", 30*24*time.Hour),
+			Timeout:        l.getDuration("CONTACT_PROVIDER_TIMEOUT", 15*time.Second),
+		},
+		CompaniesHouse: CompaniesHouseConfig{
+			APIKey:            os.Getenv("COMPANIES_HOUSE_API_KEY"),
+			BaseURL:           getEnv("COMPANIES_HOUSE_API_URL", "https://api.company-information.service.gov.uk"),
+			RequestsPerSecond: l.getFloat("COMPANIES_HOUSE_API_RPS", 1.8),
+			LiveFallback:      l.getBool("COMPANIES_HOUSE_LIVE_FALLBACK", false),
+			LiveCacheTTL:      l.getDuration("COMPANIES_HOUSE_LIVE_CACHE_TTL", 15*time.Minute),
+			LiveTimeout:       l.getDuration("COMPANIES_HOUSE_LIVE_TIMEOUT", 5*time.Second),
+		},
+	}
+	if err := cfg.validate(l.errs); err != nil {
+		return nil, err
 	}
+	return cfg, nil
 }
 
 // getEnv gets an environment variable with a fallback default value
@@ -50,3 +459,104 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// loader parses typed environment variables, recording those that are set but malformed
+type loader struct {
+	errs []error
+}
+
+// invalid records a malformed value
+func (l *loader) invalid(key, want string) {
+	l.errs = append(l.errs, fmt.Errorf("%s=%q is not %s", key, os.Getenv(key), want))
+}
+
+// getDuration parses a duration environment variable (e.g. "30s", "1h") with a fallback default value
+func (l *loader) getDuration(key string, defaultValue time.Duration) time.Duration {
+	if os.Getenv(key) == "" {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		l.invalid(key, "a duration")
+		return defaultValue
+	}
+	return value
+}
+
+// getInt parses an integer environment variable with a fallback default value
+func (l *loader) getInt(key string, defaultValue int) int {
+	if os.Getenv(key) == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		l.invalid(key, "an integer")
+		return defaultValue
+	}
+	return value
+}
+
+// getFloat parses a floating point environment variable with a fallback default value
+func (l *loader) getFloat(key string, defaultValue float64) float64 {
+	if os.Getenv(key) == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		l.invalid(key, "a number")
+		return defaultValue
+	}
+	return value
+}
+
+// getBool parses a boolean environment variable with a fallback default value
+func (l *loader) getBool(key string, defaultValue bool) bool {
+	if os.Getenv(key) == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		l.invalid(key, "true or false")
+		return defaultValue
+	}
+	return value
+}
+
+// getList parses a comma-separated environment variable with a fallback default value
+func getList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getRateLimits parses tier limits written as "tier=rps:burst,..." falling back to defaultValue
+func (l *loader) getRateLimits(key, defaultValue string) map[string]RateLimit {
+	limits := make(map[string]RateLimit)
+	for _, entry := range strings.Split(getEnv(key, defaultValue), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(entry, "=")
+		rps, burst, ok2 := strings.Cut(spec, ":")
+		if !ok || !ok2 {
+			l.errs = append(l.errs, fmt.Errorf("%s entry %q is not tier=rps:burst", key, entry))
+			continue
+		}
+
+		rate, err := strconv.ParseFloat(rps, 64)
+		size, err2 := strconv.Atoi(burst)
+		if err != nil || err2 != nil || rate <= 0 || size <= 0 {
+			l.errs = append(l.errs, fmt.Errorf("%s entry %q is not tier=rps:burst", key, entry))
+			continue
+		}
+
+		limits[strings.TrimSpace(name)] = RateLimit{RequestsPerSecond: rate, Burst: size}
+	}
+	return limits
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// loadFile reads a JSON config file and sets each setting it holds as the environment variable
+// named by its key path, unless that variable is already set. The file is an object whose
+// values are scalars, lists of scalars or nested objects, so
+//
+//	{"staging_db": {"host": "localhost"}}
+//
+// sets STAGING_DB_HOST. Lists are joined with commas.
+func loadFile(path string) error {
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return fmt.Errorf("config file %s must be .json", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	settings, err := parseJSON(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for key, value := range settings {
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return nil
+}
+
+// parseJSON reads a JSON object into settings named by their environment variables
+func parseJSON(r io.Reader) (map[string]string, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber() // Keep numbers as written, e.g. 0.5 or 10000
+	var root map[string]any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("malformed JSON: %w", err)
+	}
+	if root == nil {
+		return nil, errors.New("expected a JSON object")
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON object")
+	}
+
+	settings := make(map[string]string)
+	if err := flatten(settings, nil, root); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// flatten adds the settings of an object at a key path, in key order so errors are stable
+func flatten(settings map[string]string, path []string, object map[string]any) error {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := append(append([]string(nil), path...), key)
+		switch value := object[key].(type) {
+		case nil:
+			// Left unset, as if the key were missing
+		case map[string]any:
+			if err := flatten(settings, keyPath, value); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				parsed, ok := scalar(item)
+				if !ok {
+					return fmt.Errorf("%s: lists can only hold strings, numbers and booleans", strings.Join(keyPath, "."))
+				}
+				items = append(items, parsed)
+			}
+			settings[envName(keyPath)] = strings.Join(items, ",")
+		default:
+			parsed, _ := scalar(value)
+			settings[envName(keyPath)] = parsed
+		}
+	}
+	return nil
+}
+
+// scalar returns a string, number or boolean as the text of an environment variable
+func scalar(value any) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case json.Number:
+		return value.String(), true
+	case bool:
+		return fmt.Sprint(value), true
+	}
+	return "", false
+}
+
+// envName returns the environment variable a key path sets, e.g. staging_db.host is
+// STAGING_DB_HOST
+func envName(path []string) string {
+	name := strings.ToUpper(strings.Join(path, "_"))
+	return strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name)
+}
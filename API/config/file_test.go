@@ -0,0 +1,63 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	settings, err := parseJSON(strings.NewReader(`{
+		"api_port": 8080,
+		"auth_enabled": true,
+		"staging_db": {"host": "db.internal", "password": null},
+		"tls": {"autocert": {"domains": ["a.example.com", "b.example.com"]}},
+		"rate-limit": {"tiers": "standard:10:20"},
+		"ratio": 0.5
+	}`))
+	if err != nil {
+		t.Fatalf("parseJSON: %v", err)
+	}
+	want := map[string]string{
+		"API_PORT":             "8080",
+		"AUTH_ENABLED":         "true",
+		"STAGING_DB_HOST":      "db.internal",
+		"TLS_AUTOCERT_DOMAINS": "a.example.com,b.example.com",
+		"RATE_LIMIT_TIERS":     "standard:10:20",
+		"RATIO":                "0.5",
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("settings = %v, want %v", settings, want)
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"yaml", "api_port: 8080\n", "malformed JSON"},
+		{"not an object", `[1, 2]`, "malformed JSON"},
+		{"null", `null`, "expected a JSON object"},
+		{"trailing data", `{"api_port": 8080} {}`, "after the JSON object"},
+		{"nested list", `{"tls": {"autocert": {"domains": [["a"]]}}}`, "tls.autocert.domains: lists can only hold"},
+		{"object in list", `{"domains": [{"a": 1}]}`, "domains: lists can only hold"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJSON(strings.NewReader(tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileRequiresJSON(t *testing.T) {
+	for _, path := range []string{"config.yaml", "config.toml", "config"} {
+		if err := loadFile(path); err == nil || !strings.Contains(err.Error(), "must be .json") {
+			t.Errorf("loadFile(%q) = %v, want an error naming .json", path, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// validate reports the parse errors recorded while loading together with missing required
+// settings
+func (c *Config) validate(errs []error) error {
+	required := []struct{ key, value string }{
+		{"STAGING_DB_HOST", c.Database.Host},
+		{"STAGING_DB_PORT", c.Database.Port},
+		{"STAGING_DB_NAME", c.Database.Name},
+		{"STAGING_DB_USER", c.Database.User},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", r.key))
+		}
+	}
+	if c.Database.ReplicaDSN != "" && c.Database.ReplicaCheckInterval <= 0 {
+		errs = append(errs, errors.New("DB_REPLICA_CHECK_INTERVAL must be positive"))
+	}
+	if c.Database.MinConns > c.Database.MaxConns {
+		errs = append(errs, errors.New("DB_MIN_CONNS cannot be more than DB_MAX_CONNS"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
+
+// ValidateServer checks the settings only the API server needs
+func (c *Config) ValidateServer() error {
+	var errs []error
+	if c.Server.Port == "" {
+		errs = append(errs, errors.New("API_PORT is required"))
+	}
+	if tls := c.Server.TLS; (tls.CertFile == "") != (tls.KeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if ch := c.CompaniesHouse; ch.LiveFallback && ch.APIKey == "" {
+		errs = append(errs, errors.New("COMPANIES_HOUSE_API_KEY is required with COMPANIES_HOUSE_LIVE_FALLBACK"))
+	}
+	if ch := c.CompaniesHouse; ch.LiveFallback && ch.LiveTimeout <= 0 {
+		errs = append(errs, errors.New("COMPANIES_HOUSE_LIVE_TIMEOUT must be positive"))
+	}
+	if c.Contacts.MonthlyCredits < 0 {
+		errs = append(errs, errors.New("CONTACT_CREDITS_PER_MONTH cannot be negative"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration with secrets masked, for logging
+func (c Config) Redacted() Config {
+	for _, secret := range []*string{
+		&c.Database.Password,
+		&c.Database.ReplicaDSN,
+		&c.Auth.AdminAPIKey,
+		&c.Auth.JWT.HMACSecret,
+		&c.Exports.Bucket.SecretAccessKey,
+		&c.Mail.Password,
+		&c.Salesforce.ClientSecret,
+		&c.HubSpot.AccessToken,
+		&c.Stream.APIKey,
+		&c.CompaniesHouse.APIKey,
+		&c.HMRC.ClientSecret,
+		&c.Websites.ProviderAPIKey,
+		&c.Contacts.HunterAPIKey,
+	} {
+		if *secret != "" {
+			*secret = "[redacted]"
+		}
+	}
+	return c
+}
@@ -0,0 +1,295 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/models"
+)
+
+// MaxAggregateGroups bounds how many groups an aggregation returns
+const MaxAggregateGroups = 1000
+
+// AnalyticsDimensions are the dimensions analytics can group companies by
+var AnalyticsDimensions = []string{"industry", "region", "size_band", "status"}
+
+// groupDimension returns the expression grouping companies by dimension and any join it needs
+// after companyJoins, adding the join's arguments to qb. Companies without a value group under
+// NULL.
+func groupDimension(qb *QueryBuilder, dimension string) (string, string, error) {
+	switch dimension {
+	case "industry":
+		// The industry whose longest SIC prefix matches the primary SIC code
+		names, prefixes := industryPrefixList()
+		qb.argCount += 2
+		qb.args = append(qb.args, names, prefixes)
+		join := fmt.Sprintf(`
+	LEFT JOIN LATERAL (
+		SELECT ind.name FROM unnest($%d::text[], $%d::text[]) AS ind(name, prefix)
+		WHERE c.sic_codes[1] LIKE ind.prefix || '%%'
+		ORDER BY length(ind.prefix) DESC, ind.name
+		LIMIT 1
+	) industry ON true`, qb.argCount-1, qb.argCount)
+		return "industry.name", join, nil
+	case "region":
+		// Regions are free text; initcap merges those differing only in case
+		return "initcap(NULLIF(btrim(c.region), ''))", "", nil
+	case "size_band":
+		return bucketCase("officer_counts.active_officers", companySizeBuckets), "", nil
+	case "status":
+		return "lower(c.company_status)", "", nil
+	}
+	return "", "", fmt.Errorf("unknown dimension %q", dimension)
+}
+
+// bucketCase returns a CASE expression naming the bucket expr falls in, or NULL if none.
+// Bucket names are constants, so are written into the SQL.
+func bucketCase(expr string, buckets []bucket) string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	for _, b := range buckets {
+		lower := strconv.FormatFloat(b.min, 'f', -1, 64)
+		if b.max == 0 {
+			fmt.Fprintf(&sb, " WHEN %s >= %s THEN '%s'", expr, lower, b.name)
+		} else {
+			fmt.Fprintf(&sb, " WHEN %s BETWEEN %s AND %s THEN '%s'", expr, lower, strconv.FormatFloat(b.max, 'f', -1, 64), b.name)
+		}
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+// industryPrefixList returns every industry's SIC prefixes as parallel lists of industry
+// names and prefixes
+func industryPrefixList() ([]string, []string) {
+	var names, prefixes []string
+	for _, name := range industryNames() {
+		list, _ := industrySICPrefixes(name)
+		for _, prefix := range list {
+			names = append(names, name)
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return names, prefixes
+}
+
+// AggregateCompanies groups the companies matching filters by dimensions and summarises each
+// group's latest financials. It returns up to MaxAggregateGroups groups, largest first, with
+// how many groups there were in all.
+func (db *DB) AggregateCompanies(ctx context.Context, dimensions []string, filters models.CompanySearchFilters) ([]models.AggregateGroup, int, error) {
+	qb := NewQueryBuilder()
+	applyExpression(qb, filters)
+
+	exprs := make([]string, len(dimensions))
+	positions := make([]string, len(dimensions))
+	var joins strings.Builder
+	for i, dimension := range dimensions {
+		expr, join, err := groupDimension(qb, dimension)
+		if err != nil {
+			return nil, 0, err
+		}
+		exprs[i] = expr + " AS " + dimension
+		positions[i] = strconv.Itoa(i + 1)
+		joins.WriteString(join)
+	}
+
+	rows, err := db.Read().Query(ctx, fmt.Sprintf(`
+	SELECT %s,
+		COUNT(*),
+		SUM(latest_fin.turnover)::float8,
+		AVG(latest_fin.turnover)::float8,
+		percentile_cont(0.5) WITHIN GROUP (ORDER BY latest_fin.net_worth),
+		COUNT(*) OVER ()
+	%s%s%s
+	GROUP BY %s
+	ORDER BY COUNT(*) DESC, %s
+	LIMIT %d
+	`, strings.Join(exprs, ", "), companyJoins, joins.String(), qb.where(),
+		strings.Join(positions, ", "), strings.Join(positions, ", "), MaxAggregateGroups), qb.GetArgs()...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to aggregate companies: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make([]models.AggregateGroup, 0)
+	total := 0
+	for rows.Next() {
+		keys := make([]*string, len(dimensions))
+		var g models.AggregateGroup
+		dest := make([]any, 0, len(dimensions)+5)
+		for i := range keys {
+			dest = append(dest, &keys[i])
+		}
+		dest = append(dest, &g.Companies, &g.TurnoverSum, &g.TurnoverAvg, &g.NetWorthMedian, &total)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan aggregate: %w", err)
+		}
+		g.Keys = make(map[string]*string, len(dimensions))
+		for i, dimension := range dimensions {
+			g.Keys[dimension] = keys[i]
+		}
+		groups = append(groups, g)
+	}
+	return groups, total, rows.Err()
+}
+
+// histogramField is a figure companies can be bucketed by: into powers of ten when log is set,
+// or else into units
+type histogramField struct {
+	expr string
+	log  bool
+}
+
+// histogramFields maps histogram fields to the figures they bucket. Company age is in whole
+// years, so companies incorporated this year are 0.
+var histogramFields = map[string]histogramField{
+	"turnover":     {"latest_fin.turnover", true},
+	"total_assets": {"latest_fin.total_assets", true},
+	"company_age":  {"EXTRACT(YEAR FROM AGE(c.incorporation_date))", false},
+}
+
+// HistogramFields are the fields a histogram can be drawn of
+var HistogramFields = []string{"turnover", "total_assets", "company_age"}
+
+// Histogram buckets the companies matching filters by field. Log-scale buckets run from 10^k
+// up to 10^(k+1), except the first, which holds everything under 10; linear buckets are one
+// unit wide. Buckets between the lowest and highest are listed even when empty.
+func (db *DB) Histogram(ctx context.Context, field string, filters models.CompanySearchFilters) (models.HistogramResponse, error) {
+	response := models.HistogramResponse{Field: field, Scale: "linear"}
+	f, ok := histogramFields[field]
+	if !ok {
+		return response, fmt.Errorf("unknown histogram field %q", field)
+	}
+	bucketExpr := fmt.Sprintf("GREATEST(floor(%s), 0)::int", f.expr)
+	if f.log {
+		response.Scale = "log"
+		bucketExpr = fmt.Sprintf("CASE WHEN %[1]s < 10 THEN 0 ELSE floor(log(%[1]s::numeric))::int END", f.expr)
+	}
+
+	qb := NewQueryBuilder()
+	applyExpression(qb, filters)
+
+	rows, err := db.Read().Query(ctx, fmt.Sprintf(`
+	SELECT bucket, COUNT(*)
+	FROM (
+		SELECT %s AS bucket
+		%s%s
+	) buckets
+	GROUP BY bucket
+	ORDER BY bucket NULLS LAST
+	`, bucketExpr, companyJoins, qb.where()), qb.GetArgs()...)
+	if err != nil {
+		return response, fmt.Errorf("failed to bucket companies: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int64)
+	first, last := 0, -1
+	for rows.Next() {
+		var bucket *int
+		var count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return response, fmt.Errorf("failed to scan bucket: %w", err)
+		}
+		if bucket == nil {
+			response.Missing = count
+			continue
+		}
+		if last < first {
+			first = *bucket
+		}
+		counts[*bucket] = count
+		last = *bucket
+	}
+	if err := rows.Err(); err != nil {
+		return response, err
+	}
+
+	response.Buckets = make([]models.HistogramBucket, 0, last-first+1)
+	for k := first; k <= last; k++ {
+		b := models.HistogramBucket{Count: counts[k]}
+		if f.log {
+			b.Max = math.Pow10(k + 1)
+			if k > 0 {
+				b.Min = math.Pow10(k)
+			}
+		} else {
+			b.Min, b.Max = float64(k), float64(k+1)
+		}
+		response.Buckets = append(response.Buckets, b)
+	}
+	return response, nil
+}
+
+// trendIntervals maps incorporation trend groupings to their period length
+var trendIntervals = map[string]string{
+	"month":   "1 month",
+	"quarter": "3 months",
+	"year":    "1 year",
+}
+
+// TrendGroupings are the periods incorporation trends can be grouped by
+var TrendGroupings = []string{"month", "quarter", "year"}
+
+// IncorporationTrend counts the companies incorporated and dissolved in each period from from
+// to to, of those with a SIC code starting with sic, in industry and in location, where each is
+// set. Every period in the range is listed, including those with neither.
+func (db *DB) IncorporationTrend(ctx context.Context, groupBy string, from, to time.Time, sic, industry, location string) ([]models.TrendPeriod, error) {
+	interval, ok := trendIntervals[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown grouping %q", groupBy)
+	}
+
+	qb := NewQueryBuilder()
+	if sic != "" {
+		qb.addCondition("EXISTS (SELECT 1 FROM unnest(c.sic_codes) AS sic WHERE sic LIKE $%d)", sic+"%")
+	}
+	qb.AddIndustryFilter(industry)
+	qb.AddLocationFilter(location)
+	qb.argCount += 2
+	qb.args = append(qb.args, from, to)
+	fromArg, toArg := qb.argCount-1, qb.argCount
+	qb.conditions = append(qb.conditions, fmt.Sprintf("(c.incorporation_date BETWEEN $%[1]d AND $%[2]d OR c.dissolved_on BETWEEN $%[1]d AND $%[2]d)", fromArg, toArg))
+
+	rows, err := db.Read().Query(ctx, fmt.Sprintf(`
+	WITH matching AS (
+		SELECT c.incorporation_date, c.dissolved_on
+		FROM staging_companies c%[1]s
+	), incorporated AS (
+		SELECT date_trunc('%[2]s', incorporation_date)::date AS period, COUNT(*) AS companies
+		FROM matching
+		WHERE incorporation_date BETWEEN $%[4]d AND $%[5]d
+		GROUP BY 1
+	), dissolved AS (
+		SELECT date_trunc('%[2]s', dissolved_on)::date AS period, COUNT(*) AS companies
+		FROM matching
+		WHERE dissolved_on BETWEEN $%[4]d AND $%[5]d
+		GROUP BY 1
+	)
+	SELECT p.period, COALESCE(incorporated.companies, 0), COALESCE(dissolved.companies, 0)
+	FROM generate_series(date_trunc('%[2]s', $%[4]d::date), $%[5]d::date, interval '%[3]s') AS p(period)
+	LEFT JOIN incorporated ON incorporated.period = p.period::date
+	LEFT JOIN dissolved ON dissolved.period = p.period::date
+	ORDER BY p.period
+	`, qb.where(), groupBy, interval, fromArg, toArg), qb.GetArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count incorporations: %w", err)
+	}
+	defer rows.Close()
+
+	periods := make([]models.TrendPeriod, 0)
+	for rows.Next() {
+		var p models.TrendPeriod
+		var period time.Time
+		if err := rows.Scan(&period, &p.Incorporations, &p.Dissolutions); err != nil {
+			return nil, fmt.Errorf("failed to scan period: %w", err)
+		}
+		p.Period = period.Format("2006-01-02")
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+const apiKeyColumns = "id, name, key_prefix, role, tier, organization_id, monthly_request_quota, monthly_row_quota, created_at, last_used_at, revoked_at"
+
+// CreateAPIKey stores a new API key by its hash. It returns ErrOrganizationNotFound when the
+// requested organization does not exist.
+func (db *DB) CreateAPIKey(ctx context.Context, req models.CreateAPIKeyRequest, keyPrefix, keyHash string) (models.APIKey, error) {
+	query := `
+	INSERT INTO api_keys (name, key_prefix, key_hash, role, tier, organization_id, monthly_request_quota, monthly_row_quota)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(db.QueryRow(ctx, query, req.Name, keyPrefix, keyHash, req.Role, req.Tier, req.OrganizationID, req.MonthlyRequestQuota, req.MonthlyRowQuota))
+	if isForeignKeyViolation(err) {
+		return models.APIKey{}, ErrOrganizationNotFound
+	}
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+	return key, nil
+}
+
+// AuthenticateAPIKey looks up an active key by hash and records its use.
+// It returns nil when no active key matches.
+func (db *DB) AuthenticateAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+	UPDATE api_keys SET last_used_at = NOW()
+	WHERE key_hash = $1 AND revoked_at IS NULL
+	RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(db.QueryRow(ctx, query, keyHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate api key: %w", err)
+	}
+	return &key, nil
+}
+
+// ListAPIKeys returns all keys, including revoked ones, or only an organization's when orgID
+// is not nil
+func (db *DB) ListAPIKeys(ctx context.Context, orgID *int) ([]models.APIKey, error) {
+	rows, err := db.Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE $1::int IS NULL OR organization_id = $1 ORDER BY id", orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks a key as revoked, if it belongs to the organization when orgID is not nil.
+// It returns false when no such active key has that ID.
+func (db *DB) RevokeAPIKey(ctx context.Context, id int, orgID *int) (bool, error) {
+	tag, err := db.Exec(ctx, `
+	UPDATE api_keys SET revoked_at = NOW()
+	WHERE id = $1 AND revoked_at IS NULL AND ($2::int IS NULL OR organization_id = $2)
+	`, id, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanAPIKey(row pgx.Row) (models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.KeyPrefix,
+		&key.Role,
+		&key.Tier,
+		&key.OrganizationID,
+		&key.MonthlyRequestQuota,
+		&key.MonthlyRowQuota,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	return key, err
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// GetCompanyAsOf reconstructs a company as it stood at the end of the day asOf, or returns nil
+// if it does not exist or had not been incorporated by then. Fields in the change history
+// (company_changes) are reverted to their value before the first change after asOf; financials
+// are those of the latest period ending by asOf. Fields that are not versioned and would leak
+// later information (scores, risk ratings, due dates, the last accounts type and VAT
+// registration) are left empty; the address and website are current.
+func (db *DB) GetCompanyAsOf(ctx context.Context, companyNumber string, asOf time.Time) (*models.Company, error) {
+	c, err := db.GetCompanyByNumber(ctx, companyNumber)
+	if err != nil || c == nil {
+		return c, err
+	}
+	until := asOf.AddDate(0, 0, 1)
+	if c.IncorporationDate != nil && !c.IncorporationDate.Before(until) {
+		return nil, nil
+	}
+
+	rows, err := db.Read().Query(ctx, `
+	SELECT DISTINCT ON (field) field, old_value
+	FROM company_changes
+	WHERE company_number = $1 AND changed_at >= $2
+	ORDER BY field, changed_at, id
+	`, companyNumber, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read company changes: %w", err)
+	}
+	earlier, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.CompanyFieldChange, error) {
+		var ch models.CompanyFieldChange
+		err := row.Scan(&ch.Field, &ch.OldValue)
+		return ch, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan company change: %w", err)
+	}
+	for _, ch := range earlier {
+		if ch.OldValue == nil {
+			continue
+		}
+		switch ch.Field {
+		case "company_name":
+			c.CompanyName = *ch.OldValue
+		case "company_status":
+			c.CompanyStatus = *ch.OldValue
+		case "active_officers":
+			if n, err := strconv.Atoi(*ch.OldValue); err == nil {
+				c.ActiveOfficersCount = n
+			}
+		}
+	}
+
+	c.Turnover, c.ProfitAfterTax, c.TotalAssets, c.NetWorth, c.ProfitMargin = sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}
+	c.AccountsCurrency = sql.NullString{}
+	c.Cash, c.CurrentAssets, c.CurrentLiabilities, c.CurrentRatio = sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}
+	c.FixedAssets, c.IntangibleAssets, c.ShareholderFunds = sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}
+	c.TurnoverOriginal, c.ProfitOriginal, c.TotalAssetsOriginal, c.NetWorthOriginal = sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}, sql.NullFloat64{}
+	c.EmployeeCount = sql.NullInt64{}
+	if c.ProfitableYears, c.GrowthYears, err = db.financialStreaksAsOf(ctx, companyNumber, until); err != nil {
+		return nil, err
+	}
+	if c.Dormant, err = db.dormantAsOf(ctx, companyNumber, until); err != nil {
+		return nil, err
+	}
+	var periodEnd time.Time
+	// Converted to GBP as in staging_latest_financials
+	err = db.Read().QueryRow(ctx, `
+	SELECT period_end, COALESCE(currency, 'GBP')::text,
+		ROUND(turnover * fx.rate, 2)::float8, ROUND(profit_loss * fx.rate, 2)::float8,
+		ROUND(total_assets * fx.rate, 2)::float8, ROUND(net_assets_liabilities * fx.rate, 2)::float8,
+		ROUND(cash_bank_on_hand * fx.rate, 2)::float8, ROUND(current_assets * fx.rate, 2)::float8,
+		ROUND(cur.liabilities * fx.rate, 2)::float8,
+		(CASE WHEN cur.liabilities > 0 THEN ROUND(current_assets / cur.liabilities, 4) END)::float8,
+		ROUND(fixed_assets * fx.rate, 2)::float8, ROUND(intangible_assets * fx.rate, 2)::float8,
+		ROUND(shareholder_funds * fx.rate, 2)::float8,
+		turnover::float8, profit_loss::float8, total_assets::float8, net_assets_liabilities::float8,
+		average_number_employees_during_period::int8
+	FROM staging_financials,
+		LATERAL (SELECT CASE WHEN COALESCE(currency, 'GBP') = 'GBP' THEN 1 ELSE gbp_rate END as rate) fx,
+		LATERAL (SELECT COALESCE(current_assets - net_current_assets_liabilities, current_liabilities) as liabilities) cur
+	WHERE company_number = $1 AND period_end < $2
+	ORDER BY period_end DESC, id DESC
+	LIMIT 1
+	`, companyNumber, until).Scan(
+		&periodEnd, &c.AccountsCurrency, &c.Turnover, &c.ProfitAfterTax, &c.TotalAssets, &c.NetWorth,
+		&c.Cash, &c.CurrentAssets, &c.CurrentLiabilities, &c.CurrentRatio,
+		&c.FixedAssets, &c.IntangibleAssets, &c.ShareholderFunds,
+		&c.TurnoverOriginal, &c.ProfitOriginal, &c.TotalAssetsOriginal, &c.NetWorthOriginal, &c.EmployeeCount,
+	)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.LatestAccountsDate = nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read financials: %w", err)
+	default:
+		// As in staging_latest_financials
+		c.LatestAccountsDate, c.ProfitMargin = &periodEnd, sql.NullFloat64{Valid: true}
+	}
+
+	if c.DissolvedOn != nil && !c.DissolvedOn.Before(until) {
+		c.DissolvedOn = nil
+	}
+	if c.ConfStmtLastMadeUp != nil && !c.ConfStmtLastMadeUp.Before(until) {
+		c.ConfStmtLastMadeUp = nil
+	}
+	c.NextAccountsDue, c.ConfStmtNextDue, c.AccountsCategory = nil, nil, sql.NullString{}
+	c.HealthScore, c.Health, c.RiskBand, c.RiskFlags = sql.NullFloat64{}, sql.NullString{}, sql.NullString{}, nil
+	c.VATNumber, c.VATRegistered = sql.NullString{}, nil
+	c.AsOf = &asOf
+	return c, nil
+}
+
+// dormantAsOf reports whether the company's accounts ending before until declare it dormant
+// or show no trading, as staging_latest_financials does. Its accounts category is current, so
+// is not used.
+func (db *DB) dormantAsOf(ctx context.Context, companyNumber string, until time.Time) (bool, error) {
+	var dormant bool
+	err := db.Read().QueryRow(ctx, `
+	SELECT COALESCE(
+		(array_agg(lower(btrim(entity_dormant)) ORDER BY period_end DESC, id DESC))[1] = 'true'
+		OR (
+			COUNT(*) FILTER (WHERE turnover <> 0 OR profit_loss <> 0) = 0
+			AND (
+				COUNT(*) FILTER (WHERE turnover = 0) > 0
+				OR (COUNT(net_assets_liabilities) > 1 AND MIN(net_assets_liabilities) = MAX(net_assets_liabilities))
+			)
+		),
+	false)
+	FROM staging_financials
+	WHERE company_number = $1 AND period_end < $2
+	`, companyNumber, until).Scan(&dormant)
+	if err != nil {
+		return false, fmt.Errorf("failed to read dormancy: %w", err)
+	}
+	return dormant, nil
+}
+
+// financialStreaksAsOf counts the latest financial periods in a row ending before until with a
+// profit after tax, and with turnover above the period before's, as staging_latest_financials
+// does. Both are null for a company without periods by then.
+func (db *DB) financialStreaksAsOf(ctx context.Context, companyNumber string, until time.Time) (sql.NullInt64, sql.NullInt64, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT COALESCE(currency, 'GBP')::text, turnover::float8, profit_loss::float8,
+		(CASE WHEN COALESCE(currency, 'GBP') = 'GBP' THEN 1 ELSE gbp_rate END)::float8
+	FROM staging_financials
+	WHERE company_number = $1 AND period_end < $2
+	ORDER BY period_end DESC
+	`, companyNumber, until)
+	if err != nil {
+		return sql.NullInt64{}, sql.NullInt64{}, fmt.Errorf("failed to read financial history: %w", err)
+	}
+	defer rows.Close()
+
+	type period struct {
+		currency               string
+		turnover, profit, rate *float64
+	}
+	var periods []period
+	for rows.Next() {
+		var p period
+		if err := rows.Scan(&p.currency, &p.turnover, &p.profit, &p.rate); err != nil {
+			return sql.NullInt64{}, sql.NullInt64{}, fmt.Errorf("failed to scan financial period: %w", err)
+		}
+		periods = append(periods, p)
+	}
+	if err := rows.Err(); err != nil || len(periods) == 0 {
+		return sql.NullInt64{}, sql.NullInt64{}, err
+	}
+
+	// Compared as reported when both periods share a currency, otherwise in GBP
+	grew := func(latest, before period) bool {
+		if latest.turnover == nil || before.turnover == nil {
+			return false
+		}
+		if latest.currency == before.currency {
+			return *latest.turnover > *before.turnover
+		}
+		return latest.rate != nil && before.rate != nil && *latest.turnover*(*latest.rate) > *before.turnover*(*before.rate)
+	}
+
+	profitable, growth := sql.NullInt64{Valid: true}, sql.NullInt64{Valid: true}
+	for _, p := range periods {
+		if p.profit == nil || *p.profit <= 0 {
+			break
+		}
+		profitable.Int64++
+	}
+	for i := 0; i+1 < len(periods) && grew(periods[i], periods[i+1]); i++ {
+		growth.Int64++
+	}
+	return profitable, growth, nil
+}
@@ -0,0 +1,327 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// CompanyState is the set of fields compared between change detection runs
+type CompanyState struct {
+	CompanyNumber          string
+	CompanyStatus          *string
+	AccountsLastMadeUpDate *time.Time
+	LatestPeriodEnd        *time.Time
+	TotalOfficers          int
+	ResignedOfficers       int
+
+	// FieldsTracked is false for snapshots taken before the history fields below were
+	FieldsTracked     bool
+	CompanyName       *string
+	RegisteredAddress *string
+	LatestTurnover    *float64
+	LatestNetAssets   *float64
+}
+
+// CompanyHistoryFields are the fields recorded in company_changes
+var CompanyHistoryFields = []string{
+	"company_name", "company_status", "registered_address", "active_officers", "latest_period_end", "turnover", "net_assets",
+}
+
+// FieldChange is a change to one field of a company, recorded in company_changes
+type FieldChange struct {
+	CompanyNumber string
+	Field         string
+	OldValue      *string
+	NewValue      *string
+}
+
+// WatchedCompanyNumbers returns every company number on at least one watchlist
+func (db *DB) WatchedCompanyNumbers(ctx context.Context) ([]string, error) {
+	rows, err := db.Query(ctx, "SELECT DISTINCT company_number FROM watchlist_companies ORDER BY company_number")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watched companies: %w", err)
+	}
+	defer rows.Close()
+
+	numbers := make([]string, 0)
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("failed to scan company number: %w", err)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, rows.Err()
+}
+
+// CompaniesUpdatedSince returns companies whose staging rows were written after since,
+// along with the newest write time seen (or since itself if there were none)
+func (db *DB) CompaniesUpdatedSince(ctx context.Context, since time.Time) ([]string, time.Time, error) {
+	rows, err := db.Query(ctx, `
+	SELECT company_number, MAX(changed_at)
+	FROM (
+		SELECT company_number, last_updated AS changed_at FROM staging_companies WHERE last_updated > $1
+		UNION ALL
+		SELECT company_number, ingested_at FROM staging_financials WHERE ingested_at > $1
+		UNION ALL
+		SELECT company_number, last_updated FROM staging_officers WHERE last_updated > $1
+	) updates
+	GROUP BY company_number
+	`, since)
+	if err != nil {
+		return nil, since, fmt.Errorf("failed to list updated companies: %w", err)
+	}
+	defer rows.Close()
+
+	latest := since
+	numbers := make([]string, 0)
+	for rows.Next() {
+		var number string
+		var changedAt time.Time
+		if err := rows.Scan(&number, &changedAt); err != nil {
+			return nil, since, fmt.Errorf("failed to scan updated company: %w", err)
+		}
+		numbers = append(numbers, number)
+		if changedAt.After(latest) {
+			latest = changedAt
+		}
+	}
+	return numbers, latest, rows.Err()
+}
+
+// CurrentCompanyStates reads the live state of companies from the staging tables
+func (db *DB) CurrentCompanyStates(ctx context.Context, companyNumbers []string) (map[string]CompanyState, error) {
+	rows, err := db.Query(ctx, `
+	SELECT
+		c.company_number,
+		c.company_status,
+		c.accounts_last_made_up_date,
+		latest.period_end,
+		(SELECT COUNT(*) FROM staging_officers o WHERE o.company_number = c.company_number),
+		(SELECT COUNT(*) FROM staging_officers o WHERE o.company_number = c.company_number AND o.resigned_on IS NOT NULL),
+		true,
+		c.company_name,
+		NULLIF(concat_ws(', ',
+			NULLIF(btrim(c.address_line_1), ''), NULLIF(btrim(c.address_line_2), ''), NULLIF(btrim(c.locality), ''),
+			NULLIF(btrim(c.region), ''), NULLIF(btrim(c.postal_code), ''), NULLIF(btrim(c.country), '')
+		), ''),
+		latest.turnover,
+		latest.net_assets_liabilities
+	FROM staging_companies c
+	LEFT JOIN LATERAL (
+		SELECT f.period_end, f.turnover, f.net_assets_liabilities
+		FROM staging_financials f
+		WHERE f.company_number = c.company_number AND f.period_end IS NOT NULL
+		ORDER BY f.period_end DESC
+		LIMIT 1
+	) latest ON true
+	WHERE c.company_number = ANY($1)
+	`, companyNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read company states: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCompanyStates(rows)
+}
+
+// SnapshotCompanyStates reads the states recorded by the previous detection run
+func (db *DB) SnapshotCompanyStates(ctx context.Context, companyNumbers []string) (map[string]CompanyState, error) {
+	rows, err := db.Query(ctx, `
+	SELECT company_number, company_status, accounts_last_made_up_date, latest_period_end, total_officers, resigned_officers,
+		fields_tracked, company_name, registered_address, latest_turnover, latest_net_assets
+	FROM company_snapshots
+	WHERE company_number = ANY($1)
+	`, companyNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read company snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCompanyStates(rows)
+}
+
+// SaveCompanyChanges records detected change events and field changes, and replaces the
+// snapshots of the given companies
+func (db *DB) SaveCompanyChanges(ctx context.Context, changes []models.CompanyChange, fieldChanges []FieldChange, states []CompanyState) ([]models.CompanyChange, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	saved := make([]models.CompanyChange, 0, len(changes))
+	for _, change := range changes {
+		err := tx.QueryRow(ctx, `
+		INSERT INTO company_change_events (company_number, change_type, old_value, new_value)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, detected_at
+		`, change.CompanyNumber, change.ChangeType, change.OldValue, change.NewValue).Scan(&change.ID, &change.DetectedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record company change: %w", err)
+		}
+		saved = append(saved, change)
+	}
+
+	if len(fieldChanges) > 0 {
+		numbers := make([]string, len(fieldChanges))
+		fields := make([]string, len(fieldChanges))
+		oldValues := make([]*string, len(fieldChanges))
+		newValues := make([]*string, len(fieldChanges))
+		for i, c := range fieldChanges {
+			numbers[i], fields[i], oldValues[i], newValues[i] = c.CompanyNumber, c.Field, c.OldValue, c.NewValue
+		}
+		_, err := tx.Exec(ctx, `
+		INSERT INTO company_changes (company_number, field, old_value, new_value)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[])
+		`, numbers, fields, oldValues, newValues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record company field changes: %w", err)
+		}
+	}
+
+	for _, state := range states {
+		_, err := tx.Exec(ctx, `
+		INSERT INTO company_snapshots (
+			company_number, company_status, accounts_last_made_up_date, latest_period_end, total_officers, resigned_officers,
+			fields_tracked, company_name, registered_address, latest_turnover, latest_net_assets, captured_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, true, $7, $8, $9, $10, NOW())
+		ON CONFLICT (company_number) DO UPDATE SET
+			company_status = EXCLUDED.company_status,
+			accounts_last_made_up_date = EXCLUDED.accounts_last_made_up_date,
+			latest_period_end = EXCLUDED.latest_period_end,
+			total_officers = EXCLUDED.total_officers,
+			resigned_officers = EXCLUDED.resigned_officers,
+			fields_tracked = EXCLUDED.fields_tracked,
+			company_name = EXCLUDED.company_name,
+			registered_address = EXCLUDED.registered_address,
+			latest_turnover = EXCLUDED.latest_turnover,
+			latest_net_assets = EXCLUDED.latest_net_assets,
+			captured_at = EXCLUDED.captured_at
+		`, state.CompanyNumber, state.CompanyStatus, state.AccountsLastMadeUpDate, state.LatestPeriodEnd, state.TotalOfficers, state.ResignedOfficers,
+			state.CompanyName, state.RegisteredAddress, state.LatestTurnover, state.LatestNetAssets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save company snapshot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit company changes: %w", err)
+	}
+	return saved, nil
+}
+
+func scanCompanyStates(rows pgx.Rows) (map[string]CompanyState, error) {
+	states := make(map[string]CompanyState)
+	for rows.Next() {
+		var s CompanyState
+		err := rows.Scan(
+			&s.CompanyNumber,
+			&s.CompanyStatus,
+			&s.AccountsLastMadeUpDate,
+			&s.LatestPeriodEnd,
+			&s.TotalOfficers,
+			&s.ResignedOfficers,
+			&s.FieldsTracked,
+			&s.CompanyName,
+			&s.RegisteredAddress,
+			&s.LatestTurnover,
+			&s.LatestNetAssets,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company state: %w", err)
+		}
+		states[s.CompanyNumber] = s
+	}
+	return states, rows.Err()
+}
+
+// ListCompanyChanges returns up to limit recorded changes to a company, newest first, optionally
+// only those after since or to one field. It returns nil if the company does not exist.
+func (db *DB) ListCompanyChanges(ctx context.Context, companyNumber string, since *time.Time, field string, limit int) ([]models.CompanyFieldChange, error) {
+	var exists bool
+	if err := db.Read().QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM staging_companies WHERE company_number = $1)", companyNumber).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check company: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := db.Read().Query(ctx, `
+	SELECT id, field, old_value, new_value, changed_at
+	FROM company_changes
+	WHERE company_number = $1 AND ($2::timestamp IS NULL OR changed_at > $2) AND ($3 = '' OR field = $3)
+	ORDER BY changed_at DESC, id DESC
+	LIMIT $4
+	`, companyNumber, since, field, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list company changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]models.CompanyFieldChange, 0)
+	for rows.Next() {
+		var c models.CompanyFieldChange
+		if err := rows.Scan(&c.ID, &c.Field, &c.OldValue, &c.NewValue, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan company change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// changedCompanyTypes are the kinds of data a company can have changed in, with the staging
+// table and write time column each is read from
+var changedCompanyTypes = []struct{ name, table, column string }{
+	{"company", "staging_companies", "last_updated"},
+	{"financials", "staging_financials", "ingested_at"},
+	{"officers", "staging_officers", "last_updated"},
+	{"pscs", "staging_pscs", "last_updated"},
+	{"charges", "staging_charges", "last_updated"},
+	{"insolvency", "staging_insolvency_cases", "last_updated"},
+}
+
+// ChangedCompanies returns up to limit companies with staging rows written after since, ordered
+// by their latest write and then company number, starting after the company afterNumber last
+// written at afterTime when afterNumber is set. Each lists the kinds of data written.
+func (db *DB) ChangedCompanies(ctx context.Context, since, afterTime time.Time, afterNumber string, limit int) ([]models.ChangedCompany, error) {
+	var updates strings.Builder
+	for i, t := range changedCompanyTypes {
+		if i > 0 {
+			updates.WriteString("\n\t\tUNION ALL\n\t\t")
+		}
+		fmt.Fprintf(&updates, "SELECT company_number, %s AS changed_at, '%s' AS change_type FROM %s WHERE %s > $1", t.column, t.name, t.table, t.column)
+	}
+
+	rows, err := db.Read().Query(ctx, `
+	SELECT company_number, MAX(changed_at), array_agg(DISTINCT change_type ORDER BY change_type)
+	FROM (
+		`+updates.String()+`
+	) updates
+	GROUP BY company_number
+	HAVING $3 = '' OR (MAX(changed_at), company_number) > ($2::timestamp, $3)
+	ORDER BY 2, 1
+	LIMIT $4
+	`, since, afterTime, afterNumber, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed companies: %w", err)
+	}
+	defer rows.Close()
+
+	changed := make([]models.ChangedCompany, 0)
+	for rows.Next() {
+		var c models.ChangedCompany
+		if err := rows.Scan(&c.CompanyNumber, &c.ChangedAt, &c.ChangeTypes); err != nil {
+			return nil, fmt.Errorf("failed to scan changed company: %w", err)
+		}
+		changed = append(changed, c)
+	}
+	return changed, rows.Err()
+}
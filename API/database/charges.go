@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"data-co/api/models"
+)
+
+// StagingCharge is a staging_charges row as written by ingesters
+type StagingCharge struct {
+	CompanyNumber   string
+	ChargeID        string
+	ChargeCode      *string
+	ChargeNumber    *int
+	Classification  *string
+	Status          *string
+	CreatedOn       *string // YYYY-MM-DD
+	DeliveredOn     *string
+	SatisfiedOn     *string
+	PersonsEntitled []string
+	Particulars     *string
+	RawData         []byte
+	DataHash        string
+}
+
+// Hash computes the change detection hash over the stored charge fields
+func (c StagingCharge) Hash() string {
+	return hashFields(
+		c.CompanyNumber, c.ChargeID, c.ChargeCode, c.ChargeNumber, c.Classification, c.Status,
+		c.CreatedOn, c.DeliveredOn, c.SatisfiedOn, strings.Join(c.PersonsEntitled, "|"), c.Particulars,
+	)
+}
+
+// upsertCharge writes one charge, skipping it if unchanged
+const upsertCharge = `
+	INSERT INTO staging_charges (
+		company_number, charge_id, charge_code, charge_number, classification, status,
+		created_on, delivered_on, satisfied_on, persons_entitled, particulars,
+		raw_data, data_hash, batch_id, last_updated
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW())
+	ON CONFLICT (company_number, charge_id) DO UPDATE SET
+		charge_code = EXCLUDED.charge_code,
+		charge_number = EXCLUDED.charge_number,
+		classification = EXCLUDED.classification,
+		status = EXCLUDED.status,
+		created_on = EXCLUDED.created_on,
+		delivered_on = EXCLUDED.delivered_on,
+		satisfied_on = EXCLUDED.satisfied_on,
+		persons_entitled = EXCLUDED.persons_entitled,
+		particulars = EXCLUDED.particulars,
+		raw_data = EXCLUDED.raw_data,
+		data_hash = EXCLUDED.data_hash,
+		batch_id = EXCLUDED.batch_id,
+		last_updated = EXCLUDED.last_updated
+	WHERE staging_charges.data_hash IS DISTINCT FROM EXCLUDED.data_hash
+	`
+
+func (c StagingCharge) values(batchID string) []any {
+	return []any{
+		c.CompanyNumber, c.ChargeID, c.ChargeCode, c.ChargeNumber, c.Classification, c.Status,
+		c.CreatedOn, c.DeliveredOn, c.SatisfiedOn, c.PersonsEntitled, c.Particulars,
+		c.RawData, c.DataHash, batchID,
+	}
+}
+
+// CompaniesNeedingCharges returns up to limit companies with registered charges whose
+// charges have never been fetched or were last fetched before staleBefore, oldest first
+func (db *DB) CompaniesNeedingCharges(ctx context.Context, staleBefore time.Time, limit int) ([]string, error) {
+	rows, err := db.Query(ctx, `
+	SELECT c.company_number
+	FROM staging_companies c
+	LEFT JOIN staging_charge_fetches f ON f.company_number = c.company_number
+	WHERE c.num_mort_charges > 0 AND (f.fetched_at IS NULL OR f.fetched_at < $1)
+	ORDER BY f.fetched_at NULLS FIRST, c.company_number
+	LIMIT $2
+	`, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list companies needing charges: %w", err)
+	}
+	defer rows.Close()
+
+	numbers := make([]string, 0)
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("failed to scan company number: %w", err)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, rows.Err()
+}
+
+// ReplaceCompanyCharges stores a company's complete charges register as fetched from the API:
+// new and changed charges are upserted, charges no longer listed are removed, and the fetch is
+// recorded. It returns the number of charges written.
+func (db *DB) ReplaceCompanyCharges(ctx context.Context, batchID, companyNumber string, charges []StagingCharge) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var written int64
+	ids := make([]string, len(charges))
+	for i, charge := range charges {
+		ids[i] = charge.ChargeID
+		tag, err := tx.Exec(ctx, upsertCharge, charge.values(batchID)...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to upsert charge for %s: %w", companyNumber, err)
+		}
+		written += tag.RowsAffected()
+	}
+
+	tag, err := tx.Exec(ctx, "DELETE FROM staging_charges WHERE company_number = $1 AND NOT (charge_id = ANY($2))", companyNumber, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove stale charges for %s: %w", companyNumber, err)
+	}
+	written += tag.RowsAffected()
+
+	_, err = tx.Exec(ctx, `
+	INSERT INTO staging_charge_fetches (company_number, fetched_at) VALUES ($1, NOW())
+	ON CONFLICT (company_number) DO UPDATE SET fetched_at = EXCLUDED.fetched_at
+	`, companyNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record charges fetch for %s: %w", companyNumber, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit charges for %s: %w", companyNumber, err)
+	}
+	return written, nil
+}
+
+// UpsertStreamCharge inserts or updates a charge from the streaming API. It returns false
+// if the stored row was already identical.
+func (db *DB) UpsertStreamCharge(ctx context.Context, c StagingCharge) (bool, error) {
+	tag, err := db.Exec(ctx, upsertCharge, c.values(streamBatchID)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert charge for %s: %w", c.CompanyNumber, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteStreamCharge removes a charge deleted upstream
+func (db *DB) DeleteStreamCharge(ctx context.Context, companyNumber, chargeID string) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM staging_charges WHERE company_number = $1 AND charge_id = $2", companyNumber, chargeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete charge for %s: %w", companyNumber, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListCompanyCharges returns a company's charges, outstanding ones first
+func (db *DB) ListCompanyCharges(ctx context.Context, companyNumber string) ([]models.Charge, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT charge_id, charge_code, charge_number, classification, status,
+		status IN ('outstanding', 'part-satisfied'),
+		created_on, delivered_on, satisfied_on, persons_entitled, particulars
+	FROM staging_charges
+	WHERE company_number = $1
+	ORDER BY status IN ('outstanding', 'part-satisfied') DESC, created_on DESC NULLS LAST, id
+	`, companyNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list charges: %w", err)
+	}
+	defer rows.Close()
+
+	charges := make([]models.Charge, 0)
+	for rows.Next() {
+		var c models.Charge
+		var outstanding *bool
+		err := rows.Scan(
+			&c.ID, &c.ChargeCode, &c.ChargeNumber, &c.Classification, &c.Status, &outstanding,
+			&c.CreatedOn, &c.DeliveredOn, &c.SatisfiedOn, &c.PersonsEntitled, &c.Particulars,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan charge: %w", err)
+		}
+		c.Outstanding = outstanding != nil && *outstanding
+		charges = append(charges, c)
+	}
+	return charges, rows.Err()
+}
@@ -0,0 +1,303 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// companyField is a field of search results: its JSON name, which is also the name of its
+// result column and the db tag of the models.Company field scanCompany stores it in, and the
+// expression it is selected from over companyJoins
+type companyField struct {
+	name   string
+	column string
+	index  []int // Of the models.Company field
+}
+
+// companyFields are the fields of search results, in the order they are selected
+var companyFields = indexCompanyFields([]companyField{
+	{name: "company_number", column: "c.company_number"},
+	{name: "company_name", column: "c.company_name"},
+	{name: "company_status", column: "c.company_status"},
+	{name: "company_type", column: "c.company_type"},
+	{name: "locality", column: "c.locality"},
+	{name: "region", column: "c.region"},
+	{name: "country", column: countryNameColumn + " as country"},
+	{name: "uk_region", column: ukRegionNameColumn + " as uk_region"},
+	{name: "postal_code", column: "c.postal_code"},
+	{name: "latitude", column: "c.latitude::float8"},
+	{name: "longitude", column: "c.longitude::float8"},
+	{name: "primary_sic_code", column: "c.sic_codes[1] as primary_sic_code"},
+	{name: "industry_category", column: "NULL::text as industry_category"},
+	{name: "incorporation_date", column: "c.incorporation_date"},
+	{name: "dissolved_on", column: "c.dissolved_on"},
+	{name: "turnover", column: "latest_fin.turnover::float8"},
+	{name: "profit_after_tax", column: "latest_fin.profit_after_tax::float8"},
+	{name: "total_assets", column: "latest_fin.total_assets::float8"},
+	{name: "net_worth", column: "latest_fin.net_worth::float8"},
+	{name: "profit_margin", column: "latest_fin.profit_margin::float8"},
+	{name: "cash", column: "latest_fin.cash::float8"},
+	{name: "current_assets", column: "latest_fin.current_assets::float8"},
+	{name: "current_liabilities", column: "latest_fin.current_liabilities::float8"},
+	{name: "current_ratio", column: "latest_fin.current_ratio::float8"},
+	{name: "fixed_assets", column: "latest_fin.fixed_assets::float8"},
+	{name: "intangible_assets", column: "latest_fin.intangible_assets::float8"},
+	{name: "shareholder_funds", column: "latest_fin.shareholder_funds::float8"},
+	{name: "accounts_currency", column: "latest_fin.currency::text as accounts_currency"},
+	{name: "turnover_original", column: "latest_fin.turnover_original::float8"},
+	{name: "profit_after_tax_original", column: "latest_fin.profit_after_tax_original::float8"},
+	{name: "total_assets_original", column: "latest_fin.total_assets_original::float8"},
+	{name: "net_worth_original", column: "latest_fin.net_worth_original::float8"},
+	{name: "latest_accounts_date", column: "latest_fin.period_end as latest_accounts_date"},
+	{name: "accounts_category", column: "c.account_category as accounts_category"},
+	{name: "dormant", column: dormantCondition + " as dormant"},
+	{name: "next_accounts_due", column: "c.accounts_next_due_date as next_accounts_due"},
+	{name: "confirmation_statement_last_made_up_to", column: "c.conf_stm_last_made_up_date as confirmation_statement_last_made_up_to"},
+	{name: "confirmation_statement_next_due", column: "c.conf_stm_next_due_date as confirmation_statement_next_due"},
+	{name: "active_officers_count", column: "COALESCE(officer_counts.active_officers, 0) as active_officers_count"},
+	{name: "employee_count", column: "latest_fin.employee_count::int8"},
+	{name: "profitable_years", column: "latest_fin.profitable_years::int8"},
+	{name: "growth_years", column: "latest_fin.growth_years::int8"},
+	{name: "health_score", column: "health.score::float8 as health_score"},
+	{name: "health", column: "health.band as health"},
+	{name: "risk_band", column: "risk.band as risk_band"},
+	{name: "risk_flags", column: "risk.flags as risk_flags"},
+	{name: "vat_number", column: "c.vat_number"},
+	{name: "vat_registered", column: "c.vat_registered"},
+	{name: "domain", column: "c.website_domain as domain"},
+})
+
+// indexCompanyFields finds the models.Company field of each of fields by its db tag. It panics
+// if one has none, or a tagged field is not among fields, so the two cannot drift apart.
+func indexCompanyFields(fields []companyField) []companyField {
+	tagged := make(map[string][]int)
+	for _, f := range reflect.VisibleFields(reflect.TypeOf(models.Company{})) {
+		if tag := f.Tag.Get("db"); tag != "" && tag != "-" {
+			tagged[tag] = f.Index
+		}
+	}
+	for i, f := range fields {
+		index, ok := tagged[f.name]
+		if !ok {
+			panic(fmt.Sprintf("models.Company has no field tagged db:%q", f.name))
+		}
+		fields[i].index = index
+		delete(tagged, f.name)
+	}
+	for tag := range tagged {
+		panic(fmt.Sprintf("models.Company field tagged db:%q is not a search result field", tag))
+	}
+	return fields
+}
+
+// dest returns a pointer to the field of c that f is scanned into
+func (f companyField) dest(c *models.Company) any {
+	return reflect.ValueOf(c).Elem().FieldByIndex(f.index).Addr().Interface()
+}
+
+// matchedOnField is the result field computed from company_name rather than selected
+const matchedOnField = "matched_on"
+
+// companyColumns are the columns scanned by scanCompany, selected over companyJoins
+var companyColumns = companySelectList(nil)
+
+// CompanyFieldNames returns the fields a search can be limited to, in result order
+func CompanyFieldNames() []string {
+	names := make([]string, 0, len(companyFields)+1)
+	for _, f := range companyFields {
+		names = append(names, f.name)
+	}
+	return append(names, matchedOnField)
+}
+
+// selectedFields returns the companyFields needed for a search limited to fields, or all of
+// them when fields is empty. matched_on needs company_name. Unknown names are skipped.
+func selectedFields(fields []string) []companyField {
+	if len(fields) == 0 {
+		return companyFields
+	}
+	selected := make([]companyField, 0, len(fields))
+	for _, f := range companyFields {
+		if slices.Contains(fields, f.name) || (f.name == "company_name" && slices.Contains(fields, matchedOnField)) {
+			selected = append(selected, f)
+		}
+	}
+	return selected
+}
+
+// companySelectList returns the columns of selectedFields(fields)
+func companySelectList(fields []string) string {
+	var b strings.Builder
+	for i, f := range selectedFields(fields) {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n\t\t" + f.column)
+	}
+	b.WriteString("\n\t")
+	return b.String()
+}
+
+// scanCompany scans a row of companyColumns, followed by any extra columns into extra
+func scanCompany(row pgx.Row, extra ...any) (models.Company, error) {
+	return ScanCompanyFields(row, nil, extra...)
+}
+
+// ScanCompanyFields scans a row of a search limited to fields (see BuildCompanyQuery),
+// followed by any extra columns into extra. Fields that were not selected are left empty. When
+// the row is from Query, its columns are checked by name, so a select list that does not match
+// fails rather than filling the wrong fields.
+func ScanCompanyFields(row pgx.Row, fields []string, extra ...any) (models.Company, error) {
+	var c models.Company
+	selected := selectedFields(fields)
+	if rows, ok := row.(pgx.Rows); ok {
+		columns := rows.FieldDescriptions()
+		if len(columns) != len(selected)+len(extra) {
+			return c, fmt.Errorf("company row has %d columns, expected %d", len(columns), len(selected)+len(extra))
+		}
+		for i, f := range selected {
+			if columns[i].Name != f.name {
+				return c, fmt.Errorf("company row column %d is %s, expected %s", i+1, columns[i].Name, f.name)
+			}
+		}
+	}
+	dest := make([]any, 0, len(selected)+len(extra))
+	for _, f := range selected {
+		dest = append(dest, f.dest(&c))
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return c, err
+}
+
+// FindCompanies returns one page of the companies matching filters. Unlike the REST search it
+// applies no defaults, so callers set CompanyStatus and Limit themselves.
+func (db *DB) FindCompanies(ctx context.Context, filters models.CompanySearchFilters) ([]models.Company, error) {
+	query, args := BuildCompanyQuery(filters)
+	rows, err := db.Read().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search companies: %w", err)
+	}
+	defer rows.Close()
+
+	companies := make([]models.Company, 0)
+	for rows.Next() {
+		c, err := ScanSearchResult(rows, filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		c.MatchedOn = SearchTermMatch(c.CompanyName, filters.SearchTerm)
+		companies = append(companies, c)
+	}
+	return companies, rows.Err()
+}
+
+// ExplainFindCompanies is FindCompanies, also returning the query it ran and how long running it
+// and scanning its rows took
+func (db *DB) ExplainFindCompanies(ctx context.Context, filters models.CompanySearchFilters) ([]models.Company, *models.SearchDebug, error) {
+	query, args := BuildCompanyQuery(filters)
+	started := time.Now()
+	rows, err := db.Read().Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search companies: %w", err)
+	}
+	defer rows.Close()
+	queried := time.Now()
+
+	companies := make([]models.Company, 0)
+	for rows.Next() {
+		c, err := ScanSearchResult(rows, filters)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		c.MatchedOn = SearchTermMatch(c.CompanyName, filters.SearchTerm)
+		companies = append(companies, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return companies, &models.SearchDebug{
+		SQL:     query,
+		Args:    args,
+		QueryMs: queried.Sub(started).Milliseconds(),
+		ScanMs:  time.Since(queried).Milliseconds(),
+	}, nil
+}
+
+// StreamCompanies calls each with the companies FindCompanies would return, one at a time as
+// their rows are scanned, and stops at the first error each returns. Rows that cannot be
+// scanned are logged and skipped.
+func (db *DB) StreamCompanies(ctx context.Context, filters models.CompanySearchFilters, each func(models.Company) error) error {
+	query, args := BuildCompanyQuery(filters)
+	rows, err := db.Read().Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to search companies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := ScanSearchResult(rows, filters)
+		if err != nil {
+			log.Printf("Row scan error: %v", err)
+			continue
+		}
+		c.MatchedOn = SearchTermMatch(c.CompanyName, filters.SearchTerm)
+		if err := each(c); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CountCompanies returns the exact number of companies matching filters
+func (db *DB) CountCompanies(ctx context.Context, filters models.CompanySearchFilters) (int, error) {
+	query, args := BuildCompanyCountQuery(filters)
+	var total int
+	if err := db.Read().QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count companies: %w", err)
+	}
+	return total, nil
+}
+
+// GetCompanyByNumber returns a company by its Companies House number, or nil if there is none
+func (db *DB) GetCompanyByNumber(ctx context.Context, companyNumber string) (*models.Company, error) {
+	row := db.Read().QueryRow(ctx, `SELECT `+companyColumns+companyJoins+` WHERE c.company_number = $1`, companyNumber)
+	c, err := scanCompany(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	return &c, nil
+}
+
+// GetCompaniesByNumber returns the given companies keyed by company number. Companies that do
+// not exist are absent from the map.
+func (db *DB) GetCompaniesByNumber(ctx context.Context, companyNumbers []string) (map[string]models.Company, error) {
+	rows, err := db.Read().Query(ctx, `SELECT `+companyColumns+companyJoins+` WHERE c.company_number = ANY($1)`, companyNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get companies: %w", err)
+	}
+	defer rows.Close()
+
+	companies := make(map[string]models.Company, len(companyNumbers))
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		companies[c.CompanyNumber] = c
+	}
+	return companies, rows.Err()
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"data-co/api/models"
+)
+
+// CompareCompanies returns side-by-side metrics for the given companies, keyed by company number.
+// Companies that do not exist are absent from the map.
+func (db *DB) CompareCompanies(ctx context.Context, companyNumbers []string) (map[string]models.CompanyComparison, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT
+		c.company_number,
+		c.company_name,
+		c.company_status,
+		c.incorporation_date,
+		EXTRACT(YEAR FROM AGE(c.incorporation_date))::int as age_years,
+		latest_fin.period_end,
+		latest_fin.turnover::float8,
+		latest_fin.profit_after_tax::float8,
+		latest_fin.total_assets::float8,
+		latest_fin.net_worth::float8,
+		latest_fin.cash::float8,
+		latest_fin.current_ratio::float8,
+		latest_fin.currency::text,
+		latest_fin.employee_count,
+		COALESCE(officer_counts.active_officers, 0)
+	`+companyJoins+`
+	WHERE c.company_number = ANY($1)
+	`, companyNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare companies: %w", err)
+	}
+	defer rows.Close()
+
+	companies := make(map[string]models.CompanyComparison, len(companyNumbers))
+	for rows.Next() {
+		var c models.CompanyComparison
+		err := rows.Scan(
+			&c.CompanyNumber, &c.CompanyName, &c.CompanyStatus, &c.IncorporationDate, &c.AgeYears,
+			&c.LatestAccountsDate, &c.Turnover, &c.ProfitAfterTax, &c.TotalAssets, &c.NetWorth,
+			&c.Cash, &c.CurrentRatio, &c.AccountsCurrency,
+			&c.EmployeeCount, &c.ActiveOfficers,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company comparison: %w", err)
+		}
+		companies[c.CompanyNumber] = c
+	}
+	return companies, rows.Err()
+}
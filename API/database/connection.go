@@ -1,20 +1,34 @@
 package database
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
+	"sync/atomic"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"data-co/api/config"
 )
 
-// DB wraps the database connection
+// DB wraps the database connection pool. The embedded pool is the primary; read-only company
+// data queries go through Read, which prefers the replica when one is configured.
 type DB struct {
-	*sql.DB
+	*pgxpool.Pool
+	statementTimeout    time.Duration
+	maxStatementTimeout time.Duration // Bounds requested timeouts
+
+	// Calls on the primary go through the breaker, and transient errors are retried
+	breaker       breaker
+	retryAttempts int
+	retryBackoff  time.Duration
+
+	replica     *pgxpool.Pool
+	replicaUp   atomic.Bool
+	stopMonitor chan struct{}
 }
 
-// NewConnection creates a new database connection
+// NewConnection creates a new database connection pool
 func NewConnection(cfg config.DatabaseConfig) (*DB, error) {
 	connStr := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
@@ -26,19 +40,111 @@ func NewConnection(cfg config.DatabaseConfig) (*DB, error) {
 		cfg.SSLMode,
 	)
 
-	db, err := sql.Open("postgres", connStr)
+	pool, err := openPool(connStr, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
 	// Test the connection
-	if err := db.Ping(); err != nil {
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	db := &DB{
+		Pool:                pool,
+		statementTimeout:    cfg.StatementTimeout,
+		maxStatementTimeout: cfg.MaxStatementTimeout,
+		breaker:             breaker{threshold: cfg.CircuitThreshold, cooldown: cfg.CircuitCooldown},
+		retryAttempts:       cfg.RetryAttempts,
+		retryBackoff:        cfg.RetryBackoff,
+	}
+	if cfg.ReplicaDSN == "" {
+		return db, nil
+	}
+
+	// An unreachable replica does not stop startup; reads use the primary until it is up
+	if db.replica, err = openPool(cfg.ReplicaDSN, cfg); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("read replica: %w", err)
+	}
+	db.stopMonitor = make(chan struct{})
+	go db.monitorReplica(cfg.ReplicaCheckInterval, db.stopMonitor)
+
+	return db, nil
+}
+
+// openPool creates a connection pool with the configured pool settings. The server-side statement
+// timeout is the longer of the statement timeouts, so that requests may ask for up to the
+// maximum; shorter deadlines are kept through the query context.
+func openPool(connStr string, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database config: %w", err)
+	}
+
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	serverTimeout := max(cfg.StatementTimeout, cfg.MaxStatementTimeout)
+	if cfg.StatementTimeout <= 0 || cfg.MaxStatementTimeout <= 0 {
+		serverTimeout = 0
+	}
+	poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = fmt.Sprintf("%d", serverTimeout.Milliseconds())
 
-	return &DB{db}, nil
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return pool, nil
+}
+
+// Close closes the replica pool, if any, and then the primary
+func (db *DB) Close() {
+	if db.replica != nil {
+		close(db.stopMonitor)
+		db.replica.Close()
+	}
+	db.Pool.Close()
+}
+
+// ReplicaStatus reports whether a read replica is configured, and whether read queries are
+// currently routed to it
+func (db *DB) ReplicaStatus() (configured, up bool) {
+	return db.replica != nil, db.upReplica() != nil
+}
+
+// requestedTimeoutKey is the context key of a timeout requested by the caller
+type requestedTimeoutKey struct{}
+
+// MaxRequestedTimeout is the longest timeout a caller can request, whatever the configured
+// maximum, so a request cannot run an unbounded query when statement timeouts are disabled
+const MaxRequestedTimeout = 5 * time.Minute
+
+// WithRequestedTimeout returns a copy of ctx in which WithTimeout applies timeout instead of
+// the configured statement timeout, up to the configured maximum and MaxRequestedTimeout
+func WithRequestedTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestedTimeoutKey{}, timeout)
+}
+
+// WithTimeout derives a context that is cancelled after the configured statement timeout, or
+// the timeout requested in ctx capped at the maximum statement timeout and MaxRequestedTimeout.
+// A nil DB sets no timeout.
+func (db *DB) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db == nil {
+		return context.WithCancel(ctx)
+	}
+	timeout := db.statementTimeout
+	if requested, ok := ctx.Value(requestedTimeoutKey{}).(time.Duration); ok {
+		timeout = min(requested, MaxRequestedTimeout)
+		if db.maxStatementTimeout > 0 {
+			timeout = min(timeout, db.maxStatementTimeout)
+		}
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
 }
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithTimeoutCapsRequestedTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		db        *DB
+		requested time.Duration
+		want      time.Duration
+	}{
+		{"within the maximum", &DB{statementTimeout: time.Second, maxStatementTimeout: time.Minute}, 10 * time.Second, 10 * time.Second},
+		{"over the maximum", &DB{statementTimeout: time.Second, maxStatementTimeout: time.Minute}, time.Hour, time.Minute},
+		{"no statement timeout", &DB{}, time.Hour, MaxRequestedTimeout},
+		{"maximum over the ceiling", &DB{maxStatementTimeout: time.Hour}, time.Hour, MaxRequestedTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			ctx, cancel := tt.db.WithTimeout(WithRequestedTimeout(context.Background(), tt.requested))
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("no deadline set")
+			}
+			if got := deadline.Sub(start); got < tt.want || got > tt.want+time.Second {
+				t.Errorf("timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
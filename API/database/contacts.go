@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// GetCompanyContacts returns the contacts a tenant last enriched a company with, or nil if it
+// has not
+func (db *DB) GetCompanyContacts(ctx context.Context, ownerID, companyNumber string) (*models.CompanyContacts, error) {
+	contacts := models.CompanyContacts{CompanyNumber: companyNumber}
+	err := db.QueryRow(ctx, `
+	SELECT source, emails, phones, enriched_at
+	FROM company_contacts
+	WHERE owner_id = $1 AND company_number = $2
+	`, ownerID, companyNumber).Scan(&contacts.Source, &contacts.Emails, &contacts.Phones, &contacts.EnrichedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
+	}
+	return &contacts, nil
+}
+
+// SaveCompanyContacts stores the contacts a tenant enriched a company with, replacing those of
+// an earlier enrichment
+func (db *DB) SaveCompanyContacts(ctx context.Context, ownerID string, contacts models.CompanyContacts) error {
+	_, err := db.Exec(ctx, `
+	INSERT INTO company_contacts (owner_id, company_number, source, emails, phones, enriched_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (owner_id, company_number) DO UPDATE SET
+		source = EXCLUDED.source,
+		emails = EXCLUDED.emails,
+		phones = EXCLUDED.phones,
+		enriched_at = EXCLUDED.enriched_at
+	`, ownerID, contacts.CompanyNumber, contacts.Source, contacts.Emails, contacts.Phones, contacts.EnrichedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save contacts: %w", err)
+	}
+	return nil
+}
+
+// GetContactCredits returns how many contact enrichment credits a tenant has used this month
+func (db *DB) GetContactCredits(ctx context.Context, ownerID string) (int, error) {
+	var used int
+	err := db.QueryRow(ctx,
+		"SELECT credits_used FROM contact_credits WHERE owner_id = $1 AND period = $2",
+		ownerID, usagePeriod(time.Now()),
+	).Scan(&used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read contact credits: %w", err)
+	}
+	return used, nil
+}
+
+// ChargeContactCredit charges a tenant one contact enrichment credit this month unless it has
+// used allowance already (0 is unlimited). It returns the credits used after the charge, and
+// false with those used if none was left. Concurrent charges cannot overdraw the allowance.
+func (db *DB) ChargeContactCredit(ctx context.Context, ownerID string, allowance int) (int, bool, error) {
+	var used int
+	err := db.QueryRow(ctx, `
+	INSERT INTO contact_credits (owner_id, period, credits_used)
+	VALUES ($1, $2, 1)
+	ON CONFLICT (owner_id, period) DO UPDATE SET
+		credits_used = contact_credits.credits_used + 1,
+		updated_at = NOW()
+	WHERE $3::int = 0 OR contact_credits.credits_used < $3::int
+	RETURNING credits_used
+	`, ownerID, usagePeriod(time.Now()), allowance).Scan(&used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		used, err = db.GetContactCredits(ctx, ownerID)
+		return used, false, err
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to charge contact credit: %w", err)
+	}
+	return used, true, nil
+}
+
+// RefundContactCredit gives back a credit charged for a lookup that failed or found nothing
+func (db *DB) RefundContactCredit(ctx context.Context, ownerID string) error {
+	_, err := db.Exec(ctx, `
+	UPDATE contact_credits SET credits_used = credits_used - 1, updated_at = NOW()
+	WHERE owner_id = $1 AND period = $2 AND credits_used > 0
+	`, ownerID, usagePeriod(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to refund contact credit: %w", err)
+	}
+	return nil
+}
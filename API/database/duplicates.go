@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"data-co/api/models"
+)
+
+// DuplicateCompanies returns up to limit companies staged under several forms of their number,
+// ordered by published number, with the total number of such companies. Each group's rows are
+// listed in the order distinctCompanies prefers them, so the first is the one search returns.
+func (db *DB) DuplicateCompanies(ctx context.Context, limit int) (models.DuplicateCompaniesResponse, error) {
+	rows, err := db.Query(ctx, `
+	WITH variants AS (
+		SELECT company_number_key(company_number) AS key, company_number, company_name, company_status, last_updated, batch_id
+		FROM staging_companies
+		WHERE company_number <> company_number_key(company_number)
+	), members AS (
+		SELECT * FROM variants
+		UNION ALL
+		SELECT c.company_number, c.company_number, c.company_name, c.company_status, c.last_updated, c.batch_id
+		FROM staging_companies c
+		WHERE c.company_number IN (SELECT key FROM variants)
+	), groups AS (
+		SELECT key, COUNT(*) OVER () AS total
+		FROM members
+		GROUP BY key
+		HAVING COUNT(*) > 1
+		ORDER BY key
+		LIMIT $1
+	)
+	SELECT g.key, g.total, m.company_number, m.company_name, m.company_status, m.last_updated, m.batch_id
+	FROM groups g
+	JOIN members m ON m.key = g.key
+	ORDER BY g.key, m.company_number = g.key DESC, m.last_updated DESC NULLS LAST, m.company_number DESC
+	`, limit)
+	if err != nil {
+		return models.DuplicateCompaniesResponse{}, fmt.Errorf("failed to find duplicate companies: %w", err)
+	}
+	defer rows.Close()
+
+	result := models.DuplicateCompaniesResponse{Groups: make([]models.DuplicateCompanyGroup, 0)}
+	for rows.Next() {
+		var key string
+		var row models.DuplicateCompany
+		if err := rows.Scan(&key, &result.TotalGroups, &row.CompanyNumber, &row.CompanyName, &row.CompanyStatus, &row.LastUpdated, &row.BatchID); err != nil {
+			return result, fmt.Errorf("failed to scan duplicate company: %w", err)
+		}
+		if n := len(result.Groups); n == 0 || result.Groups[n-1].CompanyNumber != key {
+			result.Groups = append(result.Groups, models.DuplicateCompanyGroup{CompanyNumber: key})
+			row.Kept = true
+		}
+		group := &result.Groups[len(result.Groups)-1]
+		group.Rows = append(group.Rows, row)
+	}
+	return result, rows.Err()
+}
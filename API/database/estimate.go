@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"data-co/api/models"
+)
+
+// explainPlan is the subset of EXPLAIN (FORMAT JSON) output needed for row estimates
+type explainPlan struct {
+	Plan struct {
+		PlanRows float64 `json:"Plan Rows"`
+	} `json:"Plan"`
+}
+
+// EstimateCompanyCount returns an approximate number of companies matching filters
+// without scanning the joined tables
+func (db *DB) EstimateCompanyCount(ctx context.Context, filters models.CompanySearchFilters) (int, error) {
+	qb := NewQueryBuilder()
+	applyExpression(qb, filters)
+
+	query, fromExplain := qb.BuildEstimateQuery()
+	if !fromExplain {
+		var total int
+		if err := db.Read().QueryRow(ctx, query).Scan(&total); err != nil {
+			return 0, fmt.Errorf("failed to read table statistics: %w", err)
+		}
+		return total, nil
+	}
+
+	var raw string
+	if err := db.Read().QueryRow(ctx, query, qb.GetArgs()...).Scan(&raw); err != nil {
+		return 0, fmt.Errorf("failed to explain count query: %w", err)
+	}
+
+	var plans []explainPlan
+	if err := json.Unmarshal([]byte(raw), &plans); err != nil {
+		return 0, fmt.Errorf("failed to parse explain output: %w", err)
+	}
+	if len(plans) == 0 {
+		return 0, fmt.Errorf("explain returned no plan")
+	}
+
+	return int(plans[0].Plan.PlanRows), nil
+}
@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// maxListedExports bounds how many of an owner's most recent exports are listed
+const maxListedExports = 100
+
+// ExportTask is an export job claimed by a worker
+type ExportTask struct {
+	ID       int64
+	OwnerID  string
+	Filters  models.CompanySearchFilters
+	Format   string
+	Attempts int
+	Email    *models.ExportEmail
+}
+
+// ExpiredExport is a completed export whose file is due for deletion
+type ExpiredExport struct {
+	ID      int64
+	FileKey string
+}
+
+// exportJobColumns are the columns scanned by scanExportJob
+const exportJobColumns = `id, status, format, rows_written, total_rows, COALESCE(error, ''), created_at, started_at, completed_at, expires_at,
+	email_to, email_attach, email_sent_at, COALESCE(email_error, '')`
+
+// scanExportJob scans a row of exportJobColumns
+func scanExportJob(row pgx.Row) (models.ExportJob, error) {
+	var job models.ExportJob
+	var email models.ExportEmail
+	err := row.Scan(&job.ID, &job.Status, &job.Format, &job.RowsWritten, &job.TotalRows, &job.Error,
+		&job.CreatedAt, &job.StartedAt, &job.CompletedAt, &job.ExpiresAt,
+		&email.To, &email.Attach, &job.EmailSentAt, &job.EmailError)
+	if len(email.To) > 0 {
+		job.Email = &email
+	}
+	if err == nil && job.TotalRows != nil {
+		progress := 1.0
+		if *job.TotalRows > 0 {
+			progress = min(float64(job.RowsWritten)/float64(*job.TotalRows), 1)
+		}
+		job.Progress = &progress
+	}
+	return job, err
+}
+
+// CreateExportJob queues an export of the companies matching filters for an owner, emailed
+// when finished unless email is nil
+func (db *DB) CreateExportJob(ctx context.Context, ownerID string, filters models.CompanySearchFilters, format string, email *models.ExportEmail) (models.ExportJob, error) {
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return models.ExportJob{}, fmt.Errorf("failed to encode export filters: %w", err)
+	}
+	var emailTo []string
+	attach := false
+	if email != nil {
+		emailTo, attach = email.To, email.Attach
+	}
+	job, err := scanExportJob(db.QueryRow(ctx, `
+	INSERT INTO export_jobs (owner_id, filters, format, email_to, email_attach) VALUES ($1, $2, $3, $4, $5)
+	RETURNING `+exportJobColumns, ownerID, data, format, emailTo, attach))
+	if err != nil {
+		return job, fmt.Errorf("failed to create export job: %w", err)
+	}
+	return job, nil
+}
+
+// ListExportJobs returns an owner's most recent exports, newest first
+func (db *DB) ListExportJobs(ctx context.Context, ownerID string) ([]models.ExportJob, error) {
+	rows, err := db.Query(ctx, `
+	SELECT `+exportJobColumns+`
+	FROM export_jobs
+	WHERE owner_id = $1
+	ORDER BY created_at DESC, id DESC
+	LIMIT $2
+	`, ownerID, maxListedExports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]models.ExportJob, 0)
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan export job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// GetExportJob returns one of an owner's exports, or nil if it does not exist
+func (db *DB) GetExportJob(ctx context.Context, ownerID string, id int64) (*models.ExportJob, error) {
+	job, err := scanExportJob(db.QueryRow(ctx, `
+	SELECT `+exportJobColumns+` FROM export_jobs WHERE owner_id = $1 AND id = $2
+	`, ownerID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch export job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetExportFile returns the storage key of the file of one of an owner's completed exports,
+// or "" if there is no such export
+func (db *DB) GetExportFile(ctx context.Context, ownerID string, id int64) (string, error) {
+	var key string
+	err := db.QueryRow(ctx, `
+	SELECT file_path FROM export_jobs
+	WHERE owner_id = $1 AND id = $2 AND status = 'completed' AND file_path IS NOT NULL
+	`, ownerID, id).Scan(&key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch export file: %w", err)
+	}
+	return key, nil
+}
+
+// ClaimExportJob marks the oldest queued export, or running export whose lease has passed, as
+// running under a new lease, and returns it. Jobs that have been claimed maxAttempts times are
+// left for FailAbandonedExportJobs. It returns nil when there is nothing to run.
+func (db *DB) ClaimExportJob(ctx context.Context, lease time.Duration, maxAttempts int) (*ExportTask, error) {
+	var task ExportTask
+	var filters []byte
+	var email models.ExportEmail
+	err := db.QueryRow(ctx, `
+	WITH next AS (
+		SELECT id FROM export_jobs
+		WHERE (status = 'queued' OR (status = 'running' AND lease_until < NOW())) AND attempts < $2
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE export_jobs j
+	SET status = 'running', attempts = j.attempts + 1, lease_until = NOW() + make_interval(secs => $1),
+		started_at = NOW(), rows_written = 0, total_rows = NULL, error = NULL
+	FROM next
+	WHERE j.id = next.id
+	RETURNING j.id, j.owner_id, j.filters, j.format, j.attempts, j.email_to, j.email_attach
+	`, lease.Seconds(), maxAttempts).Scan(&task.ID, &task.OwnerID, &filters, &task.Format, &task.Attempts, &email.To, &email.Attach)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim export job: %w", err)
+	}
+	if err := json.Unmarshal(filters, &task.Filters); err != nil {
+		return nil, fmt.Errorf("invalid filters in export job %d: %w", task.ID, err)
+	}
+	if len(email.To) > 0 {
+		task.Email = &email
+	}
+	return &task, nil
+}
+
+// UpdateExportProgress records how far a running export has got and extends its lease
+func (db *DB) UpdateExportProgress(ctx context.Context, id int64, rowsWritten int, totalRows *int, lease time.Duration) error {
+	_, err := db.Exec(ctx, `
+	UPDATE export_jobs
+	SET rows_written = $2, total_rows = COALESCE($3, total_rows), lease_until = NOW() + make_interval(secs => $4)
+	WHERE id = $1 AND status = 'running'
+	`, id, rowsWritten, totalRows, lease.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to update export progress: %w", err)
+	}
+	return nil
+}
+
+// CompleteExportJob records a finished export and the storage key of its file, kept until
+// expiresAt
+func (db *DB) CompleteExportJob(ctx context.Context, id int64, rowsWritten int, fileKey string, expiresAt time.Time) error {
+	_, err := db.Exec(ctx, `
+	UPDATE export_jobs
+	SET status = 'completed', rows_written = $2, file_path = $3, completed_at = NOW(), expires_at = $4, lease_until = NULL
+	WHERE id = $1
+	`, id, rowsWritten, fileKey, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete export job: %w", err)
+	}
+	return nil
+}
+
+// FailExportJob records that an export failed
+func (db *DB) FailExportJob(ctx context.Context, id int64, message string) error {
+	_, err := db.Exec(ctx, `
+	UPDATE export_jobs SET status = 'failed', error = $2, completed_at = NOW(), lease_until = NULL
+	WHERE id = $1
+	`, id, message)
+	if err != nil {
+		return fmt.Errorf("failed to mark export job failed: %w", err)
+	}
+	return nil
+}
+
+// RecordExportEmail records that a finished export was emailed, or why it could not be when
+// sendErr is not nil
+func (db *DB) RecordExportEmail(ctx context.Context, id int64, sendErr error) error {
+	var err error
+	if sendErr == nil {
+		_, err = db.Exec(ctx, `UPDATE export_jobs SET email_sent_at = NOW(), email_error = NULL WHERE id = $1`, id)
+	} else {
+		_, err = db.Exec(ctx, `UPDATE export_jobs SET email_error = $2 WHERE id = $1`, id, sendErr.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record export email: %w", err)
+	}
+	return nil
+}
+
+// FailAbandonedExportJobs fails running exports whose lease has passed after maxAttempts
+// claims, e.g. because every attempt crashed its worker, and returns how many there were
+func (db *DB) FailAbandonedExportJobs(ctx context.Context, maxAttempts int) (int, error) {
+	tag, err := db.Exec(ctx, `
+	UPDATE export_jobs
+	SET status = 'failed', error = 'export did not finish after ' || attempts || ' attempts', completed_at = NOW(), lease_until = NULL
+	WHERE status = 'running' AND lease_until < NOW() AND attempts >= $1
+	`, maxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail abandoned export jobs: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ListExpiredExports returns completed exports whose files are past their expiry
+func (db *DB) ListExpiredExports(ctx context.Context) ([]ExpiredExport, error) {
+	rows, err := db.Query(ctx, `
+	SELECT id, COALESCE(file_path, '') FROM export_jobs
+	WHERE status = 'completed' AND expires_at <= NOW()
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired exports: %w", err)
+	}
+	defer rows.Close()
+
+	expired := make([]ExpiredExport, 0)
+	for rows.Next() {
+		var e ExpiredExport
+		if err := rows.Scan(&e.ID, &e.FileKey); err != nil {
+			return nil, fmt.Errorf("failed to scan expired export: %w", err)
+		}
+		expired = append(expired, e)
+	}
+	return expired, rows.Err()
+}
+
+// MarkExportExpired records that an export's file has been deleted
+func (db *DB) MarkExportExpired(ctx context.Context, id int64) error {
+	_, err := db.Exec(ctx, `UPDATE export_jobs SET status = 'expired', file_path = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark export expired: %w", err)
+	}
+	return nil
+}
+
+// ExportCompaniesPage returns up to limit companies matching filters, ordered by company
+// number and starting after the company number after (or from the first when it is empty),
+// with every field.
+// Paging on the company number rather than an offset keeps each page as cheap as the first,
+// so pages of a long export each finish within the statement timeout.
+func (db *DB) ExportCompaniesPage(ctx context.Context, filters models.CompanySearchFilters, after string, limit int) ([]models.Company, error) {
+	qb := NewQueryBuilder()
+	applyExpression(qb, filters)
+	if after != "" {
+		qb.addCondition("c.company_number > $%d", after)
+	}
+	filters.OrderBy, filters.Limit, filters.Offset, filters.Fields = "company_number", limit, 0, nil
+	filters.Sample, filters.ScoreWeights = 0, nil
+
+	rows, err := db.Read().Query(ctx, qb.BuildQuery(filters), qb.GetArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export companies: %w", err)
+	}
+	defer rows.Close()
+
+	companies := make([]models.Company, 0, limit)
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		c.MatchedOn = SearchTermMatch(c.CompanyName, filters.SearchTerm)
+		companies = append(companies, c)
+	}
+	return companies, rows.Err()
+}
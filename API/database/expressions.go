@@ -0,0 +1,55 @@
+package database
+
+import (
+	"strings"
+
+	"data-co/api/models"
+)
+
+const (
+	// maxFilterDepth bounds how deeply and/or groups can be nested in a search
+	maxFilterDepth = 5
+	// maxFilterGroups bounds how many and/or entries a search can have in total
+	maxFilterGroups = 50
+	// maxWhereClauses bounds how many where clauses a search can have in total
+	maxWhereClauses = 50
+)
+
+// applyExpression adds the filters of an expression tree: the node's own filters, every entry
+// of its and group, and at least one entry of its or group. Each entry is itself a node, with
+// its filters combined the same way.
+func applyExpression(qb *QueryBuilder, filters models.CompanySearchFilters) {
+	applyFilters(qb, filters)
+
+	for _, sub := range filters.And {
+		qb.conditions = append(qb.conditions, qb.collect(sub)...)
+	}
+
+	if len(filters.Or) > 0 {
+		args, argCount := len(qb.args), qb.argCount
+		alternatives := make([]string, 0, len(filters.Or))
+		for _, sub := range filters.Or {
+			conditions := qb.collect(sub)
+			if len(conditions) == 0 {
+				// An entry without filters matches everything, and so does the group, so drop
+				// the arguments of the alternatives already collected along with them
+				qb.args, qb.argCount = qb.args[:args], argCount
+				return
+			}
+			alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+		}
+		qb.conditions = append(qb.conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+}
+
+// collect compiles an expression node on its own and returns its conditions, leaving the
+// builder's conditions as they were. Arguments are added to the builder as usual, so the
+// conditions can be combined into the query in any order.
+func (qb *QueryBuilder) collect(filters models.CompanySearchFilters) []string {
+	saved := qb.conditions
+	qb.conditions = make([]string, 0)
+	applyExpression(qb, filters)
+	conditions := qb.conditions
+	qb.conditions = saved
+	return conditions
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"data-co/api/models"
+)
+
+// placeholderPattern matches a positional parameter in SQL
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// checkPlaceholders fails unless the conditions use exactly the parameters $1 to $N of the N
+// arguments, which Postgres requires of a prepared statement
+func checkPlaceholders(t *testing.T, qb *QueryBuilder) {
+	t.Helper()
+	if qb.argCount != len(qb.args) {
+		t.Errorf("argCount = %d, but there are %d args", qb.argCount, len(qb.args))
+	}
+	checkQueryPlaceholders(t, strings.Join(qb.conditions, " "), qb.args)
+}
+
+// checkQueryPlaceholders fails unless query uses exactly the parameters $1 to $N of args
+func checkQueryPlaceholders(t *testing.T, query string, args []interface{}) {
+	t.Helper()
+	used := make(map[int]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(query, -1) {
+		n, _ := strconv.Atoi(match[1])
+		used[n] = true
+	}
+	for n := 1; n <= len(args); n++ {
+		if !used[n] {
+			t.Errorf("argument $%d is never used in %q", n, query)
+		}
+	}
+	if len(used) > len(args) {
+		t.Errorf("%q uses %d parameters for %d args", query, len(used), len(args))
+	}
+}
+
+func TestApplyExpressionGroups(t *testing.T) {
+	qb := NewQueryBuilder()
+	applyExpression(qb, models.CompanySearchFilters{
+		SearchTerm: "a",
+		And: []models.CompanySearchFilters{
+			{Where: []models.WhereClause{{Field: "turnover", Op: "gt", Value: 1.0}}},
+			{Or: []models.CompanySearchFilters{
+				{SearchTerm: "b"},
+				{SearchTerm: "c", Where: []models.WhereClause{{Field: "cash", Op: "lt", Value: 2.0}}},
+			}},
+		},
+	})
+	want := []string{
+		"(c.company_name ILIKE $1 OR c.previous_names ILIKE $1)",
+		"latest_fin.turnover > $2::numeric",
+		"(((c.company_name ILIKE $3 OR c.previous_names ILIKE $3)) OR ((c.company_name ILIKE $4 OR c.previous_names ILIKE $4) AND latest_fin.cash < $5::numeric))",
+	}
+	if strings.Join(qb.conditions, "\n") != strings.Join(want, "\n") {
+		t.Errorf("conditions =\n%s\nwant\n%s", strings.Join(qb.conditions, "\n"), strings.Join(want, "\n"))
+	}
+	if got := fmt.Sprint(qb.args); got != "[%a% 1 %b% %c% 2]" {
+		t.Errorf("args = %s, want [%%a%% 1 %%b%% %%c%% 2]", got)
+	}
+	checkPlaceholders(t, qb)
+}
+
+func TestBuildCompanyQueryPlaceholders(t *testing.T) {
+	seed := int64(7)
+	tests := []struct {
+		name    string
+		filters models.CompanySearchFilters
+	}{
+		{"no filters", models.CompanySearchFilters{}},
+		{"page", models.CompanySearchFilters{CompanyStatus: "active", Limit: 10, Offset: 20}},
+		{"groups", models.CompanySearchFilters{
+			CompanyStatus: "active",
+			Or: []models.CompanySearchFilters{
+				{SearchTerm: "a", Where: []models.WhereClause{{Field: "locality", Op: "in", Value: []any{"Leeds"}}}},
+				{And: []models.CompanySearchFilters{{SearchTerm: "b"}, {Where: []models.WhereClause{{Field: "cash", Op: "is_null", Value: true}}}}},
+			},
+		}},
+		{"sample", models.CompanySearchFilters{SearchTerm: "a", Sample: 5, SampleSeed: &seed}},
+		{"score", models.CompanySearchFilters{SearchTerm: "a", ScoreWeights: map[string]float64{"health": 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := BuildCompanyQuery(tt.filters)
+			checkQueryPlaceholders(t, query, args)
+			query, args = BuildCompanyCountQuery(tt.filters)
+			checkQueryPlaceholders(t, query, args)
+		})
+	}
+}
+
+func TestApplyExpressionOrWithEmptyEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters models.CompanySearchFilters
+		args    []interface{}
+	}{
+		{
+			name:    "empty entry last",
+			filters: models.CompanySearchFilters{Or: []models.CompanySearchFilters{{SearchTerm: "a"}, {SearchTerm: "b"}, {}}},
+		},
+		{
+			name:    "empty entry first",
+			filters: models.CompanySearchFilters{Or: []models.CompanySearchFilters{{}, {SearchTerm: "a"}}},
+		},
+		{
+			name: "inside an and group",
+			filters: models.CompanySearchFilters{
+				SearchTerm: "x",
+				And: []models.CompanySearchFilters{
+					{Or: []models.CompanySearchFilters{{SearchTerm: "a"}, {}}},
+					{SearchTerm: "y"},
+				},
+			},
+			args: []interface{}{"%x%", "%y%"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qb := NewQueryBuilder()
+			applyExpression(qb, tt.filters)
+			checkPlaceholders(t, qb)
+			if fmt.Sprint(qb.args) != fmt.Sprint(tt.args) {
+				t.Errorf("args = %v, want %v", qb.args, tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"data-co/api/models"
+)
+
+// ListCompanyFinancials returns a company's financial history, most recent period first
+func (db *DB) ListCompanyFinancials(ctx context.Context, companyNumber string) ([]models.FinancialPeriod, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT period_start, period_end, COALESCE(currency, 'GBP')::text,
+		CASE WHEN COALESCE(currency, 'GBP') = 'GBP' THEN 1 ELSE gbp_rate::float8 END, turnover::float8, gross_profit_loss::float8,
+		operating_profit_loss::float8, profit_loss::float8, total_assets::float8,
+		fixed_assets::float8, intangible_assets::float8,
+		current_assets::float8, COALESCE(current_assets - net_current_assets_liabilities, current_liabilities)::float8,
+		total_liabilities::float8, net_assets_liabilities::float8, shareholder_funds::float8,
+		cash_bank_on_hand::float8, average_number_employees_during_period
+	FROM staging_financials
+	WHERE company_number = $1
+	ORDER BY period_end DESC
+	`, companyNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list financials: %w", err)
+	}
+	defer rows.Close()
+
+	periods := make([]models.FinancialPeriod, 0)
+	for rows.Next() {
+		var p models.FinancialPeriod
+		err := rows.Scan(
+			&p.PeriodStart, &p.PeriodEnd, &p.Currency, &p.GBPRate, &p.Turnover, &p.GrossProfit,
+			&p.OperatingProfit, &p.ProfitAfterTax, &p.TotalAssets, &p.FixedAssets, &p.IntangibleAssets,
+			&p.CurrentAssets, &p.CurrentLiabilities, &p.TotalLiabilities, &p.NetWorth, &p.ShareholderFunds,
+			&p.Cash, &p.Employees,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan financial period: %w", err)
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+// ListCompanyFilings returns the accounts filings ingested for a company, most recent first
+func (db *DB) ListCompanyFilings(ctx context.Context, companyNumber string) ([]models.Filing, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT 'accounts', period_end, report_title, source, ingested_at
+	FROM staging_financials
+	WHERE company_number = $1
+	ORDER BY period_end DESC
+	`, companyNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list filings: %w", err)
+	}
+	defer rows.Close()
+
+	filings := make([]models.Filing, 0)
+	for rows.Next() {
+		var f models.Filing
+		if err := rows.Scan(&f.Category, &f.MadeUpTo, &f.Title, &f.Source, &f.IngestedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan filing: %w", err)
+		}
+		filings = append(filings, f)
+	}
+	return filings, rows.Err()
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fxRateWindow is how far before a period end the latest rate may be, to cover weekends and
+// holidays when none is published
+const fxRateWindow = 7
+
+// FXRate is the GBP value of one unit of a currency on a day
+type FXRate struct {
+	Currency string // ISO 4217, e.g. "EUR"
+	Date     time.Time
+	GBPRate  float64
+}
+
+// ImportFXRates COPYs a batch of FX rates into a temporary table and upserts them into fx_rates
+// in one transaction. It returns the number of rates inserted or changed.
+func (db *DB) ImportFXRates(ctx context.Context, batchID string, rates []FXRate) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+	CREATE TEMP TABLE import_fx_rates (
+		currency CHAR(3) NOT NULL,
+		rate_date DATE NOT NULL,
+		gbp_rate NUMERIC(20, 10) NOT NULL
+	) ON COMMIT DROP
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	rows := make([][]any, len(rates))
+	for i, r := range rates {
+		rows[i] = []any{r.Currency, r.Date, r.GBPRate}
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_fx_rates"}, []string{"currency", "rate_date", "gbp_rate"}, pgx.CopyFromRows(rows)); err != nil {
+		return 0, fmt.Errorf("failed to copy FX rates: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+	INSERT INTO fx_rates (currency, rate_date, gbp_rate, batch_id)
+	SELECT DISTINCT ON (currency, rate_date) currency, rate_date, gbp_rate, $1
+	FROM import_fx_rates
+	ORDER BY currency, rate_date
+	ON CONFLICT (currency, rate_date) DO UPDATE SET
+		gbp_rate = EXCLUDED.gbp_rate,
+		batch_id = EXCLUDED.batch_id,
+		updated_at = NOW()
+	WHERE fx_rates.gbp_rate IS DISTINCT FROM EXCLUDED.gbp_rate
+	`, batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store FX rates of %s: %w", batchID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit import batch: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ConvertFinancials sets the gbp_rate of financial periods reported in another currency to the
+// latest rate on or in the week before their period end, and returns how many changed. Periods
+// with no rate that close are left NULL, which leaves their GBP figures unknown.
+func (db *DB) ConvertFinancials(ctx context.Context) (int64, error) {
+	tag, err := db.Exec(ctx, `
+	UPDATE staging_financials f SET gbp_rate = rate.gbp_rate
+	FROM (
+		SELECT p.id, (
+			SELECT r.gbp_rate FROM fx_rates r
+			WHERE r.currency = p.currency AND r.rate_date <= p.period_end AND r.rate_date > p.period_end - $1::int
+			ORDER BY r.rate_date DESC
+			LIMIT 1
+		) as gbp_rate
+		FROM staging_financials p
+		WHERE p.currency <> 'GBP'
+	) rate
+	WHERE f.id = rate.id AND f.gbp_rate IS DISTINCT FROM rate.gbp_rate
+	`, fxRateWindow)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert financials: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Postcode is a row of postcode_lookup. Postcode is upper case without spaces.
+type Postcode struct {
+	Postcode     string
+	Latitude     float64
+	Longitude    float64
+	TerminatedOn *time.Time
+	CountryCode  *string // ONS codes, e.g. "E92000001" and "E12000002"; nil if the directory has none
+	RegionCode   *string
+}
+
+// ImportPostcodes COPYs a batch of postcodes into a temporary table and upserts them into
+// postcode_lookup in one transaction, skipping unchanged rows. It returns the number of rows written.
+func (db *DB) ImportPostcodes(ctx context.Context, batchID string, postcodes []Postcode) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+	CREATE TEMP TABLE import_postcodes (
+		postcode TEXT NOT NULL,
+		latitude FLOAT8 NOT NULL,
+		longitude FLOAT8 NOT NULL,
+		terminated_on DATE,
+		country_code TEXT,
+		region_code TEXT
+	) ON COMMIT DROP
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	rows := make([][]any, len(postcodes))
+	for i, p := range postcodes {
+		rows[i] = []any{p.Postcode, p.Latitude, p.Longitude, p.TerminatedOn, p.CountryCode, p.RegionCode}
+	}
+
+	columns := []string{"postcode", "latitude", "longitude", "terminated_on", "country_code", "region_code"}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_postcodes"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return 0, fmt.Errorf("failed to copy postcodes: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+	INSERT INTO postcode_lookup (postcode, latitude, longitude, terminated_on, country_code, region_code, batch_id, updated_at)
+	SELECT DISTINCT ON (t.postcode) t.postcode, t.latitude, t.longitude, t.terminated_on, t.country_code, t.region_code, $1, NOW()
+	FROM import_postcodes t
+	ORDER BY t.postcode
+	ON CONFLICT (postcode) DO UPDATE SET
+		latitude = EXCLUDED.latitude,
+		longitude = EXCLUDED.longitude,
+		terminated_on = EXCLUDED.terminated_on,
+		country_code = EXCLUDED.country_code,
+		region_code = EXCLUDED.region_code,
+		batch_id = EXCLUDED.batch_id,
+		updated_at = EXCLUDED.updated_at
+	WHERE (postcode_lookup.latitude, postcode_lookup.longitude, postcode_lookup.terminated_on, postcode_lookup.country_code, postcode_lookup.region_code)
+		IS DISTINCT FROM (EXCLUDED.latitude, EXCLUDED.longitude, EXCLUDED.terminated_on, EXCLUDED.country_code, EXCLUDED.region_code)
+	`, batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert postcodes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit import batch: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GeocodeCompanies looks up the coordinates, country and region of the next limit companies
+// after the given company number and stores any that changed, clearing the coordinates for
+// postcodes postcode_lookup does not have. Those companies still get the country their address
+// names, with its region if it has only one. It returns how many companies were updated and the
+// last company number examined, which is empty once every company has been.
+func (db *DB) GeocodeCompanies(ctx context.Context, after string, limit int) (int, string, error) {
+	var updated int
+	var last string
+	err := db.QueryRow(ctx, `
+	WITH pending AS (
+		SELECT
+			c.company_number,
+			NULLIF(upper(regexp_replace(c.postal_code, '\s', '', 'g')), '') as postcode,
+			c.country
+		FROM staging_companies c
+		WHERE c.company_number > $1
+		ORDER BY c.company_number
+		LIMIT $2
+	), located AS (
+		SELECT
+			pending.company_number,
+			pending.postcode,
+			p.latitude,
+			p.longitude,
+			country.code as country_code,
+			COALESCE(p.region_code, (
+				SELECT min(r.code) FROM geography_areas r
+				WHERE r.level = 'region' AND r.parent_code = country.code
+				HAVING COUNT(*) = 1
+			)) as region_code
+		FROM pending
+		LEFT JOIN postcode_lookup p ON p.postcode = pending.postcode
+		CROSS JOIN LATERAL (SELECT COALESCE(p.country_code, country_code_of(pending.country)) as code) country
+	), updated AS (
+		UPDATE staging_companies c SET
+			latitude = l.latitude,
+			longitude = l.longitude,
+			country_code = l.country_code,
+			region_code = l.region_code,
+			geocoded_postcode = l.postcode,
+			geocoded_at = NOW()
+		FROM located l
+		WHERE c.company_number = l.company_number
+			AND (c.geocoded_postcode, c.latitude, c.longitude, c.country_code, c.region_code)
+				IS DISTINCT FROM (l.postcode, l.latitude, l.longitude, l.country_code, l.region_code)
+		RETURNING c.company_number
+	)
+	SELECT
+		(SELECT COUNT(*) FROM updated),
+		COALESCE((SELECT MAX(company_number) FROM pending), '')
+	`, after, limit).Scan(&updated, &last)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to geocode companies: %w", err)
+	}
+	return updated, last, nil
+}
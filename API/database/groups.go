@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"data-co/api/models"
+)
+
+// maxGroupDepth bounds how many levels of ownership are followed up or down a group, so
+// circular or very deep chains of PSCs cannot run away
+const maxGroupDepth = 20
+
+// maxGroupRows bounds the rows read for a group of at most maxNodes companies. Companies with
+// several parents in the group are reached once per parent, so there can be more rows than
+// companies.
+func maxGroupRows(maxNodes int) int {
+	return maxNodes * 2
+}
+
+// CompanyGroup returns the corporate group a company belongs to. Parents are the
+// parent_company_number of a company's current corporate PSCs; the ultimate parent is found by
+// following them up from the company (the deepest chain, when a company has several), and the
+// tree is built down from there, with at most maxNodes companies. A company with more than
+// one parent in the group appears once, under the first. It returns nil if the company does
+// not exist.
+func (db *DB) CompanyGroup(ctx context.Context, companyNumber string, maxNodes int) (*models.GroupResponse, error) {
+	var exists bool
+	if err := db.Read().QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM staging_companies WHERE company_number = $1)", companyNumber).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check company: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	group := &models.GroupResponse{CompanyNumber: companyNumber}
+	err := db.Read().QueryRow(ctx, `
+	WITH RECURSIVE up AS (
+		SELECT $1::varchar as company_number, 0 as depth, ARRAY[$1::varchar] as path
+		UNION ALL
+		SELECT p.parent_company_number, up.depth + 1, up.path || p.parent_company_number
+		FROM up
+		JOIN staging_pscs p ON p.company_number = up.company_number
+			AND p.parent_company_number IS NOT NULL AND p.ceased_on IS NULL
+		WHERE p.parent_company_number <> ALL(up.path) AND up.depth < $2
+	)
+	SELECT company_number FROM up ORDER BY depth DESC, company_number LIMIT 1
+	`, companyNumber, maxGroupDepth).Scan(&group.UltimateParent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find ultimate parent: %w", err)
+	}
+
+	rows, err := db.Read().Query(ctx, `
+	WITH RECURSIVE down AS (
+		SELECT $1::varchar as company_number, NULL::varchar as parent, '{}'::text[] as natures, 0 as depth, ARRAY[$1::varchar] as path
+		UNION ALL
+		SELECT p.company_number, p.parent_company_number, p.natures_of_control, down.depth + 1, down.path || p.company_number
+		FROM down
+		JOIN staging_pscs p ON p.parent_company_number = down.company_number AND p.ceased_on IS NULL
+		WHERE p.company_number <> ALL(down.path) AND down.depth < $2
+	)
+	SELECT down.company_number, down.parent, down.natures, c.company_name, c.company_status,
+		latest_fin.turnover::float8, latest_fin.period_end
+	FROM down
+	LEFT JOIN staging_companies c ON c.company_number = down.company_number
+	LEFT JOIN staging_latest_financials latest_fin ON latest_fin.company_number = down.company_number
+	ORDER BY down.depth, down.parent, down.company_number
+	LIMIT $3
+	`, group.UltimateParent, maxGroupDepth, maxGroupRows(maxNodes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group companies: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := make(map[string]*models.GroupNode)
+	var total float64
+	read := 0
+	for rows.Next() {
+		read++
+		var parent *string
+		n := &models.GroupNode{Subsidiaries: make([]*models.GroupNode, 0)}
+		err := rows.Scan(&n.CompanyNumber, &parent, &n.NaturesOfControl, &n.CompanyName, &n.CompanyStatus, &n.Turnover, &n.LatestAccountsDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group company: %w", err)
+		}
+		if _, seen := nodes[n.CompanyNumber]; seen {
+			continue
+		}
+		if len(nodes) >= maxNodes {
+			group.Truncated = true
+			break
+		}
+
+		nodes[n.CompanyNumber] = n
+		if parent == nil {
+			group.Tree = n
+		} else {
+			nodes[*parent].Subsidiaries = append(nodes[*parent].Subsidiaries, n)
+		}
+		if n.Turnover != nil {
+			total += *n.Turnover
+			group.CompaniesWithTurnover++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get group companies: %w", err)
+	}
+	if read == maxGroupRows(maxNodes) {
+		group.Truncated = true
+	}
+
+	group.CompanyCount = len(nodes)
+	if group.CompaniesWithTurnover > 0 {
+		group.GroupTurnover = &total
+	}
+	return group, nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"data-co/api/scoring"
+)
+
+// HealthScoreInput is a company's latest financial period, due to be scored
+type HealthScoreInput struct {
+	CompanyNumber string
+	PeriodEnd     time.Time
+	Financials    scoring.Financials
+}
+
+// HealthScore is a computed health score ready to store. Score and Band are nil when the
+// period could not be scored.
+type HealthScore struct {
+	CompanyNumber string
+	PeriodEnd     time.Time
+	Score         *float64
+	Band          *string
+}
+
+// PendingHealthScores returns up to limit companies whose latest financial period has not
+// been scored yet
+func (db *DB) PendingHealthScores(ctx context.Context, limit int) ([]HealthScoreInput, error) {
+	rows, err := db.Query(ctx, `
+	SELECT
+		lf.company_number,
+		lf.period_end,
+		f.total_assets::float8,
+		f.total_liabilities::float8,
+		f.net_current_assets_liabilities::float8,
+		f.net_assets_liabilities::float8,
+		f.operating_profit_loss::float8,
+		f.profit_loss::float8
+	FROM staging_latest_financials lf
+	LEFT JOIN company_health_scores h ON h.company_number = lf.company_number
+	JOIN LATERAL (
+		SELECT * FROM staging_financials sf
+		WHERE sf.company_number = lf.company_number AND sf.period_end = lf.period_end
+		ORDER BY sf.id DESC
+		LIMIT 1
+	) f ON true
+	WHERE h.company_number IS NULL OR h.period_end IS DISTINCT FROM lf.period_end
+	ORDER BY lf.company_number
+	LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending health scores: %w", err)
+	}
+	defer rows.Close()
+
+	inputs := make([]HealthScoreInput, 0)
+	for rows.Next() {
+		var in HealthScoreInput
+		f := &in.Financials
+		err := rows.Scan(
+			&in.CompanyNumber, &in.PeriodEnd,
+			&f.TotalAssets, &f.TotalLiabilities, &f.NetCurrentAssets, &f.NetAssets, &f.OperatingProfit, &f.ProfitAfterTax,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan health score input: %w", err)
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, rows.Err()
+}
+
+// SaveHealthScores stores computed health scores, replacing any earlier score for the company
+func (db *DB) SaveHealthScores(ctx context.Context, scores []HealthScore) error {
+	numbers := make([]string, len(scores))
+	periods := make([]time.Time, len(scores))
+	values := make([]*float64, len(scores))
+	bands := make([]*string, len(scores))
+	for i, s := range scores {
+		numbers[i], periods[i], values[i], bands[i] = s.CompanyNumber, s.PeriodEnd, s.Score, s.Band
+	}
+
+	_, err := db.Exec(ctx, `
+	INSERT INTO company_health_scores (company_number, period_end, score, band, computed_at)
+	SELECT number, period_end, score, band, NOW()
+	FROM UNNEST($1::text[], $2::date[], $3::numeric[], $4::text[]) AS s(number, period_end, score, band)
+	ON CONFLICT (company_number) DO UPDATE SET
+		period_end = EXCLUDED.period_end,
+		score = EXCLUDED.score,
+		band = EXCLUDED.band,
+		computed_at = EXCLUDED.computed_at
+	`, numbers, periods, values, bands)
+	if err != nil {
+		return fmt.Errorf("failed to save health scores: %w", err)
+	}
+	return nil
+}
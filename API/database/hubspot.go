@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+const (
+	// maxListedHubSpotSyncs bounds how many of an owner's most recent syncs are listed
+	maxListedHubSpotSyncs = 100
+	// maxCompanyErrors bounds how many per-company errors a sync keeps
+	maxCompanyErrors = 20
+)
+
+// HubSpotSyncTask is a HubSpot sync claimed by the worker
+type HubSpotSyncTask struct {
+	ID           int64
+	OwnerID      string
+	WatchlistID  *int
+	Filters      *models.CompanySearchFilters
+	MaxCompanies int
+}
+
+// HubSpotSyncProgress is how far a running sync has got
+type HubSpotSyncProgress struct {
+	Total         *int
+	Processed     int
+	Created       int
+	Updated       int
+	Failed        int
+	CompanyErrors []string
+}
+
+// Fail counts a company that could not be synced, keeping the first maxCompanyErrors reasons
+func (p *HubSpotSyncProgress) Fail(companyNumber, reason string) {
+	p.Failed++
+	if len(p.CompanyErrors) < maxCompanyErrors {
+		p.CompanyErrors = append(p.CompanyErrors, companyNumber+": "+reason)
+	}
+}
+
+// hubSpotSyncColumns are the columns scanned by scanHubSpotSync
+const hubSpotSyncColumns = `id, status, watchlist_id, total_companies, processed, created, updated, failed, company_errors,
+	COALESCE(error, ''), created_at, started_at, completed_at`
+
+// scanHubSpotSync scans a row of hubSpotSyncColumns
+func scanHubSpotSync(row pgx.Row) (models.HubSpotSync, error) {
+	var s models.HubSpotSync
+	err := row.Scan(&s.ID, &s.Status, &s.WatchlistID, &s.TotalCompanies, &s.Processed, &s.Created, &s.Updated, &s.Failed,
+		&s.CompanyErrors, &s.Error, &s.CreatedAt, &s.StartedAt, &s.CompletedAt)
+	return s, err
+}
+
+// CreateHubSpotSync queues a sync of a watchlist, or of up to maxCompanies companies matching
+// filters, for an owner
+func (db *DB) CreateHubSpotSync(ctx context.Context, ownerID string, watchlistID *int, filters *models.CompanySearchFilters, maxCompanies int) (models.HubSpotSync, error) {
+	var data []byte
+	if filters != nil {
+		var err error
+		if data, err = json.Marshal(filters); err != nil {
+			return models.HubSpotSync{}, fmt.Errorf("failed to encode sync filters: %w", err)
+		}
+	}
+	sync, err := scanHubSpotSync(db.QueryRow(ctx, `
+	INSERT INTO hubspot_syncs (owner_id, watchlist_id, filters, max_companies) VALUES ($1, $2, $3, $4)
+	RETURNING `+hubSpotSyncColumns, ownerID, watchlistID, data, maxCompanies))
+	if err != nil {
+		return sync, fmt.Errorf("failed to create HubSpot sync: %w", err)
+	}
+	return sync, nil
+}
+
+// ListHubSpotSyncs returns an owner's most recent syncs, newest first
+func (db *DB) ListHubSpotSyncs(ctx context.Context, ownerID string) ([]models.HubSpotSync, error) {
+	rows, err := db.Query(ctx, `
+	SELECT `+hubSpotSyncColumns+`
+	FROM hubspot_syncs
+	WHERE owner_id = $1
+	ORDER BY created_at DESC, id DESC
+	LIMIT $2
+	`, ownerID, maxListedHubSpotSyncs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list HubSpot syncs: %w", err)
+	}
+	defer rows.Close()
+
+	syncs := make([]models.HubSpotSync, 0)
+	for rows.Next() {
+		s, err := scanHubSpotSync(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan HubSpot sync: %w", err)
+		}
+		syncs = append(syncs, s)
+	}
+	return syncs, rows.Err()
+}
+
+// GetHubSpotSync returns one of an owner's syncs, or nil if it does not exist
+func (db *DB) GetHubSpotSync(ctx context.Context, ownerID string, id int64) (*models.HubSpotSync, error) {
+	s, err := scanHubSpotSync(db.QueryRow(ctx, `
+	SELECT `+hubSpotSyncColumns+` FROM hubspot_syncs WHERE owner_id = $1 AND id = $2
+	`, ownerID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch HubSpot sync: %w", err)
+	}
+	return &s, nil
+}
+
+// ClaimHubSpotSync marks the oldest queued sync, or running sync whose lease has passed, as
+// running under a new lease and returns it. A sync claimed again starts over; upserts make
+// that safe. Syncs claimed maxAttempts times are left for FailAbandonedHubSpotSyncs. It
+// returns nil when there is nothing to run.
+func (db *DB) ClaimHubSpotSync(ctx context.Context, lease time.Duration, maxAttempts int) (*HubSpotSyncTask, error) {
+	var task HubSpotSyncTask
+	var filters []byte
+	err := db.QueryRow(ctx, `
+	WITH next AS (
+		SELECT id FROM hubspot_syncs
+		WHERE (status = 'queued' OR (status = 'running' AND lease_until < NOW())) AND attempts < $2
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE hubspot_syncs s
+	SET status = 'running', attempts = s.attempts + 1, lease_until = NOW() + make_interval(secs => $1),
+		started_at = NOW(), total_companies = NULL, processed = 0, created = 0, updated = 0, failed = 0,
+		company_errors = '{}', error = NULL
+	FROM next
+	WHERE s.id = next.id
+	RETURNING s.id, s.owner_id, s.watchlist_id, s.filters, s.max_companies
+	`, lease.Seconds(), maxAttempts).Scan(&task.ID, &task.OwnerID, &task.WatchlistID, &filters, &task.MaxCompanies)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim HubSpot sync: %w", err)
+	}
+	if filters != nil {
+		task.Filters = &models.CompanySearchFilters{}
+		if err := json.Unmarshal(filters, task.Filters); err != nil {
+			return nil, fmt.Errorf("invalid filters in HubSpot sync %d: %w", task.ID, err)
+		}
+	}
+	return &task, nil
+}
+
+// UpdateHubSpotSyncProgress records how far a running sync has got and extends its lease
+func (db *DB) UpdateHubSpotSyncProgress(ctx context.Context, id int64, p HubSpotSyncProgress, lease time.Duration) error {
+	_, err := db.Exec(ctx, `
+	UPDATE hubspot_syncs
+	SET total_companies = $2, processed = $3, created = $4, updated = $5, failed = $6, company_errors = $7,
+		lease_until = NOW() + make_interval(secs => $8)
+	WHERE id = $1 AND status = 'running'
+	`, id, p.Total, p.Processed, p.Created, p.Updated, p.Failed, p.CompanyErrors, lease.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to update HubSpot sync progress: %w", err)
+	}
+	return nil
+}
+
+// CompleteHubSpotSync records a finished sync
+func (db *DB) CompleteHubSpotSync(ctx context.Context, id int64, p HubSpotSyncProgress) error {
+	_, err := db.Exec(ctx, `
+	UPDATE hubspot_syncs
+	SET status = 'completed', total_companies = $2, processed = $3, created = $4, updated = $5, failed = $6,
+		company_errors = $7, completed_at = NOW(), lease_until = NULL
+	WHERE id = $1
+	`, id, p.Total, p.Processed, p.Created, p.Updated, p.Failed, p.CompanyErrors)
+	if err != nil {
+		return fmt.Errorf("failed to complete HubSpot sync: %w", err)
+	}
+	return nil
+}
+
+// FailHubSpotSync records that a sync failed as a whole, keeping its progress
+func (db *DB) FailHubSpotSync(ctx context.Context, id int64, message string) error {
+	_, err := db.Exec(ctx, `
+	UPDATE hubspot_syncs SET status = 'failed', error = $2, completed_at = NOW(), lease_until = NULL
+	WHERE id = $1
+	`, id, message)
+	if err != nil {
+		return fmt.Errorf("failed to mark HubSpot sync failed: %w", err)
+	}
+	return nil
+}
+
+// FailAbandonedHubSpotSyncs fails running syncs whose lease has passed after maxAttempts
+// claims, and returns how many there were
+func (db *DB) FailAbandonedHubSpotSyncs(ctx context.Context, maxAttempts int) (int, error) {
+	tag, err := db.Exec(ctx, `
+	UPDATE hubspot_syncs
+	SET status = 'failed', error = 'sync did not finish after ' || attempts || ' attempts', completed_at = NOW(), lease_until = NULL
+	WHERE status = 'running' AND lease_until < NOW() AND attempts >= $1
+	`, maxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail abandoned HubSpot syncs: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
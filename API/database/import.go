@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// importColumns are the staging_companies columns loaded from the bulk snapshot
+var importColumns = []string{
+	"company_number", "company_name", "company_status", "company_type",
+	"locality", "postal_code", "address_line_1", "address_line_2", "region", "country",
+	"sic_codes", "incorporation_date", "accounts_last_made_up_date", "accounts_ref_date",
+	"accounts_next_due_date", "account_category", "returns_next_due_date", "returns_last_made_up_date",
+	"num_mort_charges", "num_mort_outstanding", "num_mort_part_satisfied",
+	"previous_names", "conf_stm_next_due_date", "conf_stm_last_made_up_date", "dissolved_on",
+	"previous_name_history", "data_hash",
+}
+
+// ImportCompanies COPYs a batch of snapshot rows into a temporary table and upserts them
+// into staging_companies in one transaction. Rows whose hash matches the stored row are left
+// untouched, so re-running an import is a no-op. It returns the number of rows written.
+func (db *DB) ImportCompanies(ctx context.Context, batchID string, companies []StagingCompany) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Dates are staged as text and cast on insert, since COPY's binary format needs typed values
+	_, err = tx.Exec(ctx, `
+	CREATE TEMP TABLE import_companies (
+		company_number VARCHAR(8) NOT NULL,
+		company_name TEXT,
+		company_status TEXT,
+		company_type TEXT,
+		locality TEXT,
+		postal_code TEXT,
+		address_line_1 TEXT,
+		address_line_2 TEXT,
+		region TEXT,
+		country TEXT,
+		sic_codes TEXT[],
+		incorporation_date TEXT,
+		accounts_last_made_up_date TEXT,
+		accounts_ref_date TEXT,
+		accounts_next_due_date TEXT,
+		account_category TEXT,
+		returns_next_due_date TEXT,
+		returns_last_made_up_date TEXT,
+		num_mort_charges INTEGER,
+		num_mort_outstanding INTEGER,
+		num_mort_part_satisfied INTEGER,
+		previous_names TEXT,
+		conf_stm_next_due_date TEXT,
+		conf_stm_last_made_up_date TEXT,
+		dissolved_on TEXT,
+		previous_name_history JSONB,
+		data_hash TEXT
+	) ON COMMIT DROP
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	rows := make([][]any, len(companies))
+	for i, c := range companies {
+		rows[i] = []any{
+			c.CompanyNumber, c.CompanyName, c.CompanyStatus, c.CompanyType,
+			c.Locality, c.PostalCode, c.AddressLine1, c.AddressLine2, c.Region, c.Country,
+			c.SICCodes, c.IncorporationDate, c.AccountsLastMadeUpDate, c.AccountsRefDate,
+			c.AccountsNextDueDate, c.AccountCategory, c.ReturnsNextDueDate, c.ReturnsLastMadeUpDate,
+			c.NumMortCharges, c.NumMortOutstanding, c.NumMortPartSatisfied,
+			c.PreviousNames, c.ConfStmtNextDueDate, c.ConfStmtLastMadeUpDate, c.DissolvedOn,
+			previousNameHistory(c.PreviousNameHistory), c.DataHash,
+		}
+	}
+
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_companies"}, importColumns, pgx.CopyFromRows(rows)); err != nil {
+		return 0, fmt.Errorf("failed to copy companies: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+	INSERT INTO staging_companies (
+		company_number, company_name, company_status, company_type,
+		locality, postal_code, address_line_1, address_line_2, region, country,
+		sic_codes, incorporation_date, accounts_last_made_up_date, accounts_ref_date,
+		accounts_next_due_date, account_category, returns_next_due_date, returns_last_made_up_date,
+		num_mort_charges, num_mort_outstanding, num_mort_part_satisfied,
+		previous_names, conf_stm_next_due_date, conf_stm_last_made_up_date, dissolved_on,
+		previous_name_history, data_hash, last_updated, change_detected, raw_data, batch_id
+	)
+	SELECT DISTINCT ON (t.company_number)
+		t.company_number, t.company_name, t.company_status, t.company_type,
+		t.locality, t.postal_code, t.address_line_1, t.address_line_2, t.region, t.country,
+		t.sic_codes, t.incorporation_date::date, t.accounts_last_made_up_date::date, t.accounts_ref_date,
+		t.accounts_next_due_date::date, t.account_category, t.returns_next_due_date::date, t.returns_last_made_up_date::date,
+		t.num_mort_charges, t.num_mort_outstanding, t.num_mort_part_satisfied,
+		t.previous_names, t.conf_stm_next_due_date::date, t.conf_stm_last_made_up_date::date, t.dissolved_on::date,
+		t.previous_name_history, t.data_hash, NOW(), FALSE, '{}'::jsonb, $1
+	FROM import_companies t
+	ORDER BY t.company_number
+	ON CONFLICT (company_number) DO UPDATE SET
+		company_name = EXCLUDED.company_name,
+		company_status = EXCLUDED.company_status,
+		company_type = EXCLUDED.company_type,
+		locality = EXCLUDED.locality,
+		postal_code = EXCLUDED.postal_code,
+		address_line_1 = EXCLUDED.address_line_1,
+		address_line_2 = EXCLUDED.address_line_2,
+		region = EXCLUDED.region,
+		country = EXCLUDED.country,
+		sic_codes = EXCLUDED.sic_codes,
+		incorporation_date = EXCLUDED.incorporation_date,
+		accounts_last_made_up_date = EXCLUDED.accounts_last_made_up_date,
+		accounts_ref_date = EXCLUDED.accounts_ref_date,
+		accounts_next_due_date = EXCLUDED.accounts_next_due_date,
+		account_category = EXCLUDED.account_category,
+		returns_next_due_date = EXCLUDED.returns_next_due_date,
+		returns_last_made_up_date = EXCLUDED.returns_last_made_up_date,
+		num_mort_charges = EXCLUDED.num_mort_charges,
+		num_mort_outstanding = EXCLUDED.num_mort_outstanding,
+		num_mort_part_satisfied = EXCLUDED.num_mort_part_satisfied,
+		previous_names = EXCLUDED.previous_names,
+		conf_stm_next_due_date = EXCLUDED.conf_stm_next_due_date,
+		conf_stm_last_made_up_date = EXCLUDED.conf_stm_last_made_up_date,
+		dissolved_on = EXCLUDED.dissolved_on,
+		previous_name_history = EXCLUDED.previous_name_history,
+		data_hash = EXCLUDED.data_hash,
+		last_updated = EXCLUDED.last_updated,
+		batch_id = EXCLUDED.batch_id,
+		merged_at = NULL,
+		change_detected = TRUE
+	WHERE staging_companies.data_hash IS DISTINCT FROM EXCLUDED.data_hash
+	`, batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert companies: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit import batch: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// StartIngestionLog records a new ingestion batch in staging_ingestion_log
+func (db *DB) StartIngestionLog(ctx context.Context, batchID, searchName string, files []string) error {
+	metadata, err := json.Marshal(map[string]any{"files": files})
+	if err != nil {
+		return fmt.Errorf("failed to encode ingestion metadata: %w", err)
+	}
+
+	_, err = db.Exec(ctx, `
+	INSERT INTO staging_ingestion_log (batch_id, search_name, status, files_total, metadata)
+	VALUES ($1, $2, 'running', $3, $4)
+	`, batchID, searchName, len(files), metadata)
+	if err != nil {
+		return fmt.Errorf("failed to create ingestion log: %w", err)
+	}
+	return nil
+}
+
+// UpdateIngestionProgress records how far an ingestion batch has got
+func (db *DB) UpdateIngestionProgress(ctx context.Context, batchID string, filesCompleted int, currentFile string, fileProgress int, companies int64) error {
+	_, err := db.Exec(ctx, `
+	UPDATE staging_ingestion_log
+	SET files_completed = $2, current_file = $3, current_file_progress = $4, companies_count = $5
+	WHERE batch_id = $1
+	`, batchID, filesCompleted, currentFile, fileProgress, companies)
+	if err != nil {
+		return fmt.Errorf("failed to update ingestion progress: %w", err)
+	}
+	return nil
+}
+
+// FinishIngestionLog marks an ingestion batch completed, or failed if errMessage is set
+func (db *DB) FinishIngestionLog(ctx context.Context, batchID string, errMessage string) error {
+	status := "completed"
+	var errValue *string
+	if errMessage != "" {
+		status = "failed"
+		errValue = &errMessage
+	}
+
+	_, err := db.Exec(ctx, `
+	UPDATE staging_ingestion_log
+	SET status = $2, error_message = $3, completed_at = NOW()
+	WHERE batch_id = $1
+	`, batchID, status, errValue)
+	if err != nil {
+		return fmt.Errorf("failed to finish ingestion log: %w", err)
+	}
+	return nil
+}
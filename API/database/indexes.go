@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// searchIndexes are the indexes search filters and sorts rely on. Without them searches still
+// work, but scan every company.
+var searchIndexes = []string{
+	"idx_staging_companies_name",
+	"idx_staging_companies_name_trgm",
+	"idx_staging_companies_previous_names_trgm",
+	"idx_staging_companies_sic_codes",
+	"idx_staging_companies_locality_lower",
+	"idx_staging_companies_region_lower",
+	"idx_staging_companies_locality_trgm",
+	"idx_staging_companies_region_trgm",
+	"idx_staging_companies_status_lower",
+	"idx_staging_companies_incorporation_date",
+	"idx_staging_companies_type_code",
+	"idx_staging_companies_accounts_category_code",
+	"idx_staging_companies_accounts_next_due",
+	"idx_staging_companies_conf_stm_next_due",
+	"idx_staging_companies_dissolved_on",
+	"idx_staging_companies_postcode_area",
+	"idx_staging_companies_postcode_district",
+	"idx_staging_companies_address_trgm",
+	"idx_staging_companies_lat_lng",
+	"idx_staging_companies_country_code",
+	"idx_staging_companies_region_code",
+	"idx_staging_financials_company_period",
+	"idx_staging_latest_financials_company",
+	"idx_staging_latest_financials_turnover",
+	"idx_staging_latest_financials_net_worth",
+	"idx_staging_officer_counts_company",
+}
+
+// MissingSearchIndexes returns the search indexes that do not exist, or are invalid because
+// building them failed
+func (db *DB) MissingSearchIndexes(ctx context.Context) ([]string, error) {
+	rows, err := db.Query(ctx, `
+	SELECT name
+	FROM unnest($1::text[]) WITH ORDINALITY AS expected(name, n)
+	LEFT JOIN pg_index i ON i.indexrelid = to_regclass(expected.name)
+	WHERE i.indisvalid IS NOT TRUE
+	ORDER BY n
+	`, searchIndexes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check search indexes: %w", err)
+	}
+	defer rows.Close()
+
+	missing := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan index name: %w", err)
+		}
+		missing = append(missing, name)
+	}
+	return missing, rows.Err()
+}
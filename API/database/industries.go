@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"data-co/api/models"
+)
+
+// defaultIndustries maps industry names to SIC code prefixes until the industries table has been
+// loaded, e.g. when the table does not exist yet.
+// See: https://resources.companieshouse.gov.uk/sic/
+var defaultIndustries = map[string][]string{
+	"tech":          {"62", "63"},       // Computer programming, IT services, data processing
+	"finance":       {"64", "65", "66"}, // Financial services, insurance
+	"retail":        {"47"},             // Retail trade
+	"manufacturing": {"10", "11", "12", "13", "14", "15", "16", "17", "18", "19", "20", "21", "22", "23", "24", "25", "26", "27", "28", "29", "30", "31", "32", "33"},
+	"professional":  {"69", "70", "71", "72", "73", "74"}, // Professional, scientific and technical
+}
+
+// industryPrefixes caches the industries table for the industry filter. It is replaced
+// wholesale by LoadIndustries, so queries being built never see a partial update.
+var industryPrefixes atomic.Pointer[map[string][]string]
+
+// industrySICPrefixes returns the SIC code prefixes of an industry
+func industrySICPrefixes(industry string) ([]string, bool) {
+	industries := industryPrefixes.Load()
+	if industries == nil {
+		industries = &defaultIndustries
+	}
+	prefixes, ok := (*industries)[industry]
+	return prefixes, ok
+}
+
+// industryNames returns the names the industry filter accepts, sorted
+func industryNames() []string {
+	industries := industryPrefixes.Load()
+	if industries == nil {
+		industries = &defaultIndustries
+	}
+	names := make([]string, 0, len(*industries))
+	for name := range *industries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadIndustries reloads the industry filter's cache from the industries table
+func (db *DB) LoadIndustries(ctx context.Context) error {
+	industries, err := db.ListIndustries(ctx)
+	if err != nil {
+		return err
+	}
+
+	prefixes := make(map[string][]string, len(industries))
+	for _, industry := range industries {
+		prefixes[industry.Name] = industry.SICPrefixes
+	}
+	industryPrefixes.Store(&prefixes)
+	return nil
+}
+
+// ListIndustries returns every industry, ordered by name
+func (db *DB) ListIndustries(ctx context.Context) ([]models.Industry, error) {
+	rows, err := db.Query(ctx, "SELECT name, description, sic_prefixes, created_at, updated_at FROM industries ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list industries: %w", err)
+	}
+	defer rows.Close()
+
+	industries := make([]models.Industry, 0)
+	for rows.Next() {
+		var i models.Industry
+		if err := rows.Scan(&i.Name, &i.Description, &i.SICPrefixes, &i.CreatedAt, &i.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan industry: %w", err)
+		}
+		industries = append(industries, i)
+	}
+	return industries, rows.Err()
+}
+
+// SaveIndustry creates an industry or replaces its description and SIC prefixes. It returns
+// true if the industry was created.
+func (db *DB) SaveIndustry(ctx context.Context, name string, req models.IndustryRequest) (models.Industry, bool, error) {
+	i := models.Industry{Name: name}
+	var created bool
+	err := db.QueryRow(ctx, `
+	INSERT INTO industries (name, description, sic_prefixes) VALUES ($1, $2, $3)
+	ON CONFLICT (name) DO UPDATE SET
+		description = EXCLUDED.description,
+		sic_prefixes = EXCLUDED.sic_prefixes,
+		updated_at = NOW()
+	RETURNING description, sic_prefixes, created_at, updated_at, xmax = 0
+	`, name, req.Description, req.SICPrefixes).Scan(&i.Description, &i.SICPrefixes, &i.CreatedAt, &i.UpdatedAt, &created)
+	if err != nil {
+		return models.Industry{}, false, fmt.Errorf("failed to save industry: %w", err)
+	}
+	return i, created, nil
+}
+
+// DeleteIndustry removes an industry. It returns false if it did not exist.
+func (db *DB) DeleteIndustry(ctx context.Context, name string) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM industries WHERE name = $1", name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete industry: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"data-co/api/models"
+)
+
+// Lowercased company statuses, as written by the bulk loader and the stream, that indicate
+// insolvency proceedings
+var (
+	liquidationStatuses    = []string{"liquidation"}
+	administrationStatuses = []string{"administration", "in administration"}
+	insolvencyStatuses     = append(append([]string{
+		"receivership", "receivership action", "administrative receiver",
+		"voluntary-arrangement", "voluntary arrangement", "insolvency-proceedings",
+	}, liquidationStatuses...), administrationStatuses...)
+)
+
+// Insolvency case types, as published by Companies House, for each kind of proceeding
+var (
+	liquidationCaseTypes    = []string{"compulsory-liquidation", "creditors-voluntary-liquidation", "members-voluntary-liquidation"}
+	administrationCaseTypes = []string{"in-administration", "administration-order"}
+)
+
+// inProceedingsCondition matches companies whose status is one of statuses or that have an
+// open insolvency case of one of caseTypes
+func inProceedingsCondition(statuses, caseTypes []string) string {
+	return fmt.Sprintf("(LOWER(c.company_status) IN (%s) OR EXISTS (SELECT 1 FROM staging_insolvency_cases i WHERE i.company_number = c.company_number AND i.ended_on IS NULL AND i.case_type IN (%s)))",
+		quoteList(statuses), quoteList(caseTypes))
+}
+
+// insolvencyHistoryCondition matches companies with an insolvency status, an insolvency case,
+// or the has_insolvency_history flag from the API
+func insolvencyHistoryCondition() string {
+	return fmt.Sprintf("(LOWER(c.company_status) IN (%s) OR c.raw_data->>'has_insolvency_history' = 'true' OR EXISTS (SELECT 1 FROM staging_insolvency_cases i WHERE i.company_number = c.company_number))",
+		quoteList(insolvencyStatuses))
+}
+
+// quoteList formats constant values as a SQL list of string literals
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// StagingInsolvencyCase is a staging_insolvency_cases row as written by ingesters
+type StagingInsolvencyCase struct {
+	CompanyNumber string
+	CaseNumber    int
+	CaseType      string
+	StartedOn     *string // YYYY-MM-DD
+	EndedOn       *string
+	Dates         []byte // JSON array of {"type", "date"}
+	Practitioners []string
+	RawData       []byte
+	DataHash      string
+}
+
+// Hash computes the change detection hash over the stored case fields
+func (c StagingInsolvencyCase) Hash() string {
+	return hashFields(
+		c.CompanyNumber, &c.CaseNumber, c.CaseType, c.StartedOn, c.EndedOn,
+		string(c.Dates), strings.Join(c.Practitioners, "|"),
+	)
+}
+
+// CompaniesNeedingInsolvency returns up to limit companies with an insolvency status or
+// history whose cases have never been fetched or were last fetched before staleBefore,
+// oldest first
+func (db *DB) CompaniesNeedingInsolvency(ctx context.Context, staleBefore time.Time, limit int) ([]string, error) {
+	rows, err := db.Query(ctx, `
+	SELECT c.company_number
+	FROM staging_companies c
+	LEFT JOIN staging_insolvency_fetches f ON f.company_number = c.company_number
+	WHERE `+insolvencyHistoryCondition()+`
+		AND (f.fetched_at IS NULL OR f.fetched_at < $1)
+	ORDER BY f.fetched_at NULLS FIRST, c.company_number
+	LIMIT $2
+	`, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list companies needing insolvency cases: %w", err)
+	}
+	defer rows.Close()
+
+	numbers := make([]string, 0)
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, fmt.Errorf("failed to scan company number: %w", err)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, rows.Err()
+}
+
+// ReplaceCompanyInsolvency stores a company's complete list of insolvency cases: new and
+// changed cases are upserted, cases no longer listed are removed, and the fetch is recorded.
+// It returns the number of cases written.
+func (db *DB) ReplaceCompanyInsolvency(ctx context.Context, batchID, companyNumber string, cases []StagingInsolvencyCase) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var written int64
+	numbers := make([]int, len(cases))
+	for i, c := range cases {
+		numbers[i] = c.CaseNumber
+		tag, err := tx.Exec(ctx, `
+		INSERT INTO staging_insolvency_cases (
+			company_number, case_number, case_type, started_on, ended_on, dates, practitioners,
+			raw_data, data_hash, batch_id, last_updated
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		ON CONFLICT (company_number, case_number) DO UPDATE SET
+			case_type = EXCLUDED.case_type,
+			started_on = EXCLUDED.started_on,
+			ended_on = EXCLUDED.ended_on,
+			dates = EXCLUDED.dates,
+			practitioners = EXCLUDED.practitioners,
+			raw_data = EXCLUDED.raw_data,
+			data_hash = EXCLUDED.data_hash,
+			batch_id = EXCLUDED.batch_id,
+			last_updated = EXCLUDED.last_updated
+		WHERE staging_insolvency_cases.data_hash IS DISTINCT FROM EXCLUDED.data_hash
+		`,
+			c.CompanyNumber, c.CaseNumber, c.CaseType, c.StartedOn, c.EndedOn, c.Dates, c.Practitioners,
+			c.RawData, c.DataHash, batchID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to upsert insolvency case for %s: %w", companyNumber, err)
+		}
+		written += tag.RowsAffected()
+	}
+
+	tag, err := tx.Exec(ctx, "DELETE FROM staging_insolvency_cases WHERE company_number = $1 AND NOT (case_number = ANY($2))", companyNumber, numbers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove stale insolvency cases for %s: %w", companyNumber, err)
+	}
+	written += tag.RowsAffected()
+
+	_, err = tx.Exec(ctx, `
+	INSERT INTO staging_insolvency_fetches (company_number, fetched_at) VALUES ($1, NOW())
+	ON CONFLICT (company_number) DO UPDATE SET fetched_at = EXCLUDED.fetched_at
+	`, companyNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record insolvency fetch for %s: %w", companyNumber, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit insolvency cases for %s: %w", companyNumber, err)
+	}
+	return written, nil
+}
+
+// ReplaceStreamInsolvency stores a company's insolvency cases from the streaming API, which
+// publishes the company's full list of cases on every change
+func (db *DB) ReplaceStreamInsolvency(ctx context.Context, companyNumber string, cases []StagingInsolvencyCase) (int64, error) {
+	return db.ReplaceCompanyInsolvency(ctx, streamBatchID, companyNumber, cases)
+}
+
+// GetCompanyInsolvency returns a company's insolvency status and cases, open cases first
+func (db *DB) GetCompanyInsolvency(ctx context.Context, companyNumber, companyStatus string) (*models.InsolvencySummary, error) {
+	cases, err := db.ListCompanyInsolvencyCases(ctx, companyNumber)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeInsolvency(companyStatus, cases), nil
+}
+
+// GetCompanyInsolvencyAsOf returns a company's insolvency status and cases as they stood at the
+// end of the day asOf, given its status then: cases started later are left out, and cases that
+// ended later are open.
+func (db *DB) GetCompanyInsolvencyAsOf(ctx context.Context, companyNumber, companyStatus string, asOf time.Time) (*models.InsolvencySummary, error) {
+	cases, err := db.ListCompanyInsolvencyCases(ctx, companyNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	until := asOf.AddDate(0, 0, 1)
+	known := make([]models.InsolvencyCase, 0, len(cases))
+	for _, c := range cases {
+		if c.StartedOn != nil && !c.StartedOn.Before(until) {
+			continue
+		}
+		if c.EndedOn != nil && !c.EndedOn.Before(until) {
+			c.EndedOn, c.Open = nil, true
+		}
+		dates := make([]models.InsolvencyDate, 0, len(c.Dates))
+		for _, d := range c.Dates {
+			// Dates are YYYY-MM-DD, so they compare as strings
+			if d.Date <= asOf.Format("2006-01-02") {
+				dates = append(dates, d)
+			}
+		}
+		c.Dates = dates
+		known = append(known, c)
+	}
+	// Cases that ended later were listed after the open ones
+	sort.SliceStable(known, func(i, j int) bool { return known[i].Open && !known[j].Open })
+	return summarizeInsolvency(companyStatus, known), nil
+}
+
+// summarizeInsolvency derives a company's insolvency status from its status and cases
+func summarizeInsolvency(companyStatus string, cases []models.InsolvencyCase) *models.InsolvencySummary {
+	status := strings.ToLower(companyStatus)
+	summary := &models.InsolvencySummary{
+		InLiquidation:        slices.Contains(liquidationStatuses, status),
+		InAdministration:     slices.Contains(administrationStatuses, status),
+		HasInsolvencyHistory: slices.Contains(insolvencyStatuses, status) || len(cases) > 0,
+		Cases:                cases,
+	}
+	for _, c := range cases {
+		if !c.Open {
+			continue
+		}
+		summary.InLiquidation = summary.InLiquidation || slices.Contains(liquidationCaseTypes, c.CaseType)
+		summary.InAdministration = summary.InAdministration || slices.Contains(administrationCaseTypes, c.CaseType)
+	}
+	return summary
+}
+
+// ListCompanyInsolvencyCases returns a company's insolvency cases, open ones first
+func (db *DB) ListCompanyInsolvencyCases(ctx context.Context, companyNumber string) ([]models.InsolvencyCase, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT case_number, case_type, started_on, ended_on, dates, practitioners
+	FROM staging_insolvency_cases
+	WHERE company_number = $1
+	ORDER BY ended_on IS NULL DESC, started_on DESC NULLS LAST, case_number DESC
+	`, companyNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list insolvency cases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := make([]models.InsolvencyCase, 0)
+	for rows.Next() {
+		var c models.InsolvencyCase
+		if err := rows.Scan(&c.CaseNumber, &c.CaseType, &c.StartedOn, &c.EndedOn, &c.Dates, &c.Practitioners); err != nil {
+			return nil, fmt.Errorf("failed to scan insolvency case: %w", err)
+		}
+		c.Open = c.EndedOn == nil
+		cases = append(cases, c)
+	}
+	return cases, rows.Err()
+}
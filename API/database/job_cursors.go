@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetJobCursor returns the last processed position of a background job, or nil if it has never run
+func (db *DB) GetJobCursor(ctx context.Context, name string) (*time.Time, error) {
+	var position time.Time
+	err := db.QueryRow(ctx, "SELECT position FROM job_cursors WHERE name = $1", name).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job cursor %s: %w", name, err)
+	}
+	return &position, nil
+}
+
+// SetJobCursor records the last processed position of a background job
+func (db *DB) SetJobCursor(ctx context.Context, name string, position time.Time) error {
+	_, err := db.Exec(ctx, `
+	INSERT INTO job_cursors (name, position) VALUES ($1, $2)
+	ON CONFLICT (name) DO UPDATE SET position = EXCLUDED.position, updated_at = NOW()
+	`, name, position)
+	if err != nil {
+		return fmt.Errorf("failed to save job cursor %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"data-co/api/models"
+)
+
+// ErrLocationConflict is returned when a location name or alias is already taken
+var ErrLocationConflict = errors.New("location name or alias already exists")
+
+// ErrParentNotFound is returned when a new location's parent does not exist
+var ErrParentNotFound = errors.New("parent location not found")
+
+const locationQuery = `
+	SELECT l.id, l.name, l.kind, l.parent_id, l.created_at,
+		COALESCE(array_agg(a.alias ORDER BY a.alias) FILTER (WHERE a.alias IS NOT NULL), '{}')
+	FROM locations l
+	LEFT JOIN location_aliases a ON a.location_id = l.id
+	`
+
+func scanLocation(row pgx.Row) (models.Location, error) {
+	var l models.Location
+	err := row.Scan(&l.ID, &l.Name, &l.Kind, &l.ParentID, &l.CreatedAt, &l.Aliases)
+	return l, err
+}
+
+// ListLocations returns every location with its aliases, ordered by name
+func (db *DB) ListLocations(ctx context.Context) ([]models.Location, error) {
+	rows, err := db.Query(ctx, locationQuery+"GROUP BY l.id ORDER BY l.name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+	defer rows.Close()
+
+	locations := make([]models.Location, 0)
+	for rows.Next() {
+		l, err := scanLocation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan location: %w", err)
+		}
+		locations = append(locations, l)
+	}
+	return locations, rows.Err()
+}
+
+// GetLocation returns a location with its aliases, or nil if it does not exist
+func (db *DB) GetLocation(ctx context.Context, id int) (*models.Location, error) {
+	l, err := scanLocation(db.QueryRow(ctx, locationQuery+"WHERE l.id = $1 GROUP BY l.id", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch location: %w", err)
+	}
+	return &l, nil
+}
+
+// CreateLocation stores a location and its aliases, which must be lower case. It returns
+// ErrLocationConflict if the name or an alias is taken and ErrParentNotFound if the parent
+// does not exist.
+func (db *DB) CreateLocation(ctx context.Context, req models.CreateLocationRequest) (models.Location, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return models.Location{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, "INSERT INTO locations (name, kind, parent_id) VALUES ($1, $2, $3) RETURNING id", req.Name, req.Kind, req.ParentID).Scan(&id)
+	if err != nil {
+		return models.Location{}, locationError("failed to create location", err)
+	}
+	if err := addLocationAliases(ctx, tx, id, req.Aliases); err != nil {
+		return models.Location{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.Location{}, fmt.Errorf("failed to commit location: %w", err)
+	}
+
+	l, err := db.GetLocation(ctx, id)
+	if err != nil {
+		return models.Location{}, err
+	}
+	return *l, nil
+}
+
+// DeleteLocation removes a location, its aliases and every location inside it. It returns
+// false if it did not exist.
+func (db *DB) DeleteLocation(ctx context.Context, id int) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM locations WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete location: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// AddLocationAliases adds lower-case aliases to a location, ignoring ones it already has. It
+// returns ErrLocationConflict if an alias belongs to another location.
+func (db *DB) AddLocationAliases(ctx context.Context, id int, aliases []string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := addLocationAliases(ctx, tx, id, aliases); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func addLocationAliases(ctx context.Context, tx pgx.Tx, id int, aliases []string) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	// Aliases that already exist are skipped, then reported as a conflict (rolling back the
+	// transaction) if another location holds any of them
+	tag, err := tx.Exec(ctx, `
+	INSERT INTO location_aliases (alias, location_id)
+	SELECT DISTINCT unnest($2::text[]), $1::int
+	ON CONFLICT (alias) DO NOTHING
+	`, id, aliases)
+	if err != nil {
+		return locationError("failed to add location aliases", err)
+	}
+	if int(tag.RowsAffected()) == len(aliases) {
+		return nil
+	}
+
+	var taken bool
+	err = tx.QueryRow(ctx, `
+	SELECT EXISTS (SELECT 1 FROM location_aliases WHERE alias = ANY($2::text[]) AND location_id <> $1::int)
+	`, id, aliases).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("failed to check location aliases: %w", err)
+	}
+	if taken {
+		return ErrLocationConflict
+	}
+	return nil
+}
+
+// RemoveLocationAlias removes an alias from a location. It returns false if the location did
+// not have it.
+func (db *DB) RemoveLocationAlias(ctx context.Context, id int, alias string) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM location_aliases WHERE location_id = $1 AND alias = $2", id, alias)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove location alias: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// locationError maps unique and foreign key violations to ErrLocationConflict and
+// ErrParentNotFound, and wraps other errors with msg
+func locationError(msg string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return ErrLocationConflict
+		case "23503": // foreign_key_violation
+			return ErrParentNotFound
+		}
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"data-co/api/models"
+)
+
+// matchConfidence scores a candidate from its name similarity, moving it up by a quarter when
+// it is at the query's postcode and down by a quarter when it is known to be elsewhere
+const matchConfidence = `
+	round((CASE
+		WHEN score.postcode_match THEN 0.75 * score.name_similarity + 0.25
+		WHEN NOT score.postcode_match THEN 0.75 * score.name_similarity
+		ELSE score.name_similarity
+	END)::numeric, 3)::float8`
+
+// MatchCompanies returns up to limit candidates for each query, best first, in query order.
+// Candidates are companies whose normalized name is trigram-similar to the query's (pg_trgm's
+// similarity threshold, 0.3 by default), so a query can have none.
+func (db *DB) MatchCompanies(ctx context.Context, queries []models.MatchQuery, limit int) ([][]models.MatchCandidate, error) {
+	names := make([]string, len(queries))
+	postcodes := make([]string, len(queries))
+	for i, q := range queries {
+		names[i] = q.Name
+		postcodes[i] = q.Postcode
+	}
+
+	rows, err := db.Read().Query(ctx, `
+	WITH q AS (
+		SELECT
+			ord,
+			normalize_company_name(name) as name,
+			NULLIF(upper(regexp_replace(postcode, '\s', '', 'g')), '') as postcode
+		FROM unnest($1::text[], $2::text[]) WITH ORDINALITY AS t(name, postcode, ord)
+	)
+	SELECT m.*, q.ord
+	FROM q
+	CROSS JOIN LATERAL (
+		SELECT `+companyColumns+`,
+			score.name_similarity,
+			score.postcode_match,
+			`+matchConfidence+` as confidence
+		`+companyJoins+`
+		CROSS JOIN LATERAL (
+			SELECT
+				similarity(normalize_company_name(c.company_name), q.name)::float8 as name_similarity,
+				upper(regexp_replace(c.postal_code, '\s', '', 'g')) = q.postcode as postcode_match
+		) score
+		WHERE normalize_company_name(c.company_name) % q.name
+		ORDER BY confidence DESC, c.company_number
+		LIMIT $3
+	) m
+	ORDER BY q.ord, m.confidence DESC, m.company_number
+	`, names, postcodes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match companies: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([][]models.MatchCandidate, len(queries))
+	for rows.Next() {
+		var ord int
+		var m models.MatchCandidate
+		m.Company, err = scanCompany(rows, &m.NameSimilarity, &m.PostcodeMatch, &m.Confidence, &ord)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match candidate: %w", err)
+		}
+		results[ord-1] = append(results[ord-1], m)
+	}
+	return results, rows.Err()
+}
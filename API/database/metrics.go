@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"data-co/api/models"
+)
+
+// TurnoverSeries returns a company's turnover for each financial period, oldest first, with the
+// change from the previous period. Where several accounts cover the same period, the most
+// recently ingested is used. With asOf, only periods ending by then are included.
+func (db *DB) TurnoverSeries(ctx context.Context, companyNumber string, asOf *time.Time) ([]models.TurnoverPeriod, error) {
+	rows, err := db.Read().Query(ctx, `
+	WITH periods AS (
+		SELECT DISTINCT ON (period_end) period_start, period_end, turnover::float8 as turnover
+		FROM staging_financials
+		WHERE company_number = $1 AND period_end IS NOT NULL AND ($2::date IS NULL OR period_end <= $2)
+		ORDER BY period_end, id DESC
+	)
+	SELECT
+		period_start,
+		period_end,
+		turnover,
+		turnover - LAG(turnover) OVER w,
+		CASE WHEN LAG(turnover) OVER w > 0
+			THEN ROUND(((turnover - LAG(turnover) OVER w) / LAG(turnover) OVER w * 100)::numeric, 2)::float8
+		END
+	FROM periods
+	WINDOW w AS (ORDER BY period_end)
+	ORDER BY period_end
+	`, companyNumber, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get turnover series: %w", err)
+	}
+	defer rows.Close()
+
+	periods := make([]models.TurnoverPeriod, 0)
+	for rows.Next() {
+		var p models.TurnoverPeriod
+		if err := rows.Scan(&p.PeriodStart, &p.PeriodEnd, &p.Turnover, &p.YoYChange, &p.YoYChangePct); err != nil {
+			return nil, fmt.Errorf("failed to scan turnover period: %w", err)
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
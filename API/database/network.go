@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"data-co/api/models"
+)
+
+// CompanyNetwork returns the companies connected to companyNumber through shared current
+// officers, up to depth hops away, and an edge for each officer shared between two of them.
+// Officers are matched across companies by officer_person_id. The network is explored a hop at
+// a time, nearest companies first, and stops growing once it has maxNodes companies, when the
+// response is marked truncated. It returns nil if the company does not exist.
+func (db *DB) CompanyNetwork(ctx context.Context, companyNumber string, depth, maxNodes int) (*models.NetworkResponse, error) {
+	network := &models.NetworkResponse{CompanyNumber: companyNumber, Depth: depth, Edges: make([]models.NetworkEdge, 0)}
+	hops := map[string]int{companyNumber: 0}
+	order := []string{companyNumber}
+	seenEdges := make(map[[3]string]bool)
+
+	frontier := []string{companyNumber}
+	for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+		rows, err := db.Read().Query(ctx, `
+		SELECT a.company_number, b.company_number, officer_person_id(a.officer_name, a.date_of_birth),
+			b.officer_name, a.officer_role, b.officer_role, a.appointed_on, b.appointed_on
+		FROM staging_officers a
+		JOIN staging_officers b
+			ON officer_person_id(b.officer_name, b.date_of_birth) = officer_person_id(a.officer_name, a.date_of_birth)
+			AND b.company_number <> a.company_number
+			AND b.resigned_on IS NULL
+		WHERE a.company_number = ANY($1) AND a.resigned_on IS NULL
+		ORDER BY a.company_number, b.company_number, b.id
+		`, frontier)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find shared officers: %w", err)
+		}
+
+		var next []string
+		for rows.Next() {
+			var e models.NetworkEdge
+			err := rows.Scan(&e.From, &e.To, &e.OfficerID, &e.OfficerName, &e.FromRole, &e.ToRole, &e.FromAppointedOn, &e.ToAppointedOn)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan shared officer: %w", err)
+			}
+
+			if _, ok := hops[e.To]; !ok {
+				if len(order) >= maxNodes {
+					network.Truncated = true
+					continue
+				}
+				hops[e.To] = hop
+				order = append(order, e.To)
+				next = append(next, e.To)
+			}
+
+			// Each shared officer is one edge, whichever side it was found from
+			key := [3]string{min(e.From, e.To), max(e.From, e.To), e.OfficerID}
+			if !seenEdges[key] {
+				seenEdges[key] = true
+				network.Edges = append(network.Edges, e)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to find shared officers: %w", err)
+		}
+		frontier = next
+	}
+
+	rows, err := db.Read().Query(ctx, "SELECT company_number, company_name, company_status FROM staging_companies WHERE company_number = ANY($1)", order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network companies: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := make(map[string]models.NetworkNode, len(order))
+	for rows.Next() {
+		var n models.NetworkNode
+		if err := rows.Scan(&n.CompanyNumber, &n.CompanyName, &n.CompanyStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan network company: %w", err)
+		}
+		nodes[n.CompanyNumber] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get network companies: %w", err)
+	}
+	if _, ok := nodes[companyNumber]; !ok {
+		return nil, nil
+	}
+
+	network.Nodes = make([]models.NetworkNode, len(order))
+	for i, number := range order {
+		n := nodes[number]
+		n.CompanyNumber = number
+		n.Hops = hops[number]
+		network.Nodes[i] = n
+	}
+	return network, nil
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// ListCompanyOfficers returns a company's officer appointments, current ones first
+func (db *DB) ListCompanyOfficers(ctx context.Context, companyNumber string) ([]models.Officer, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT id, officer_name, officer_role, appointed_on, resigned_on, resigned_on IS NULL,
+		nationality, to_char(date_of_birth, 'YYYY-MM'), address_line_1, locality, postal_code, country
+	FROM staging_officers
+	WHERE company_number = $1
+	ORDER BY resigned_on IS NOT NULL, appointed_on DESC NULLS LAST, id
+	`, companyNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list officers: %w", err)
+	}
+	defer rows.Close()
+
+	officers := make([]models.Officer, 0)
+	for rows.Next() {
+		var o models.Officer
+		err := rows.Scan(
+			&o.ID, &o.Name, &o.Role, &o.AppointedOn, &o.ResignedOn, &o.Active,
+			&o.Nationality, &o.DateOfBirth, &o.AddressLine1, &o.Locality, &o.PostalCode, &o.Country,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan officer: %w", err)
+		}
+		officers = append(officers, o)
+	}
+	return officers, rows.Err()
+}
+
+// officerNameMatch matches officers whose name has the same surname as $1 and a compatible
+// first forename: the same, an initial of it, or either one missing. Middle names are ignored.
+const officerNameMatch = `
+	officer_surname(o.officer_name) = officer_surname($1)
+	AND (officer_forename($1) = ''
+		OR officer_forename(o.officer_name) = officer_forename($1)
+		OR officer_forename(o.officer_name) = left(officer_forename($1), 1)
+		OR (length(officer_forename($1)) = 1 AND officer_forename(o.officer_name) LIKE officer_forename($1) || '%'))`
+
+// officerAppointmentColumns are the columns scanOfficerProfiles reads, from staging_officers o
+// and staging_companies c
+const officerAppointmentColumns = `
+	officer_person_id(o.officer_name, o.date_of_birth), o.officer_name, to_char(o.date_of_birth, 'YYYY-MM'), o.nationality,
+	o.id, o.company_number, c.company_name, c.company_status, o.officer_role,
+	o.appointed_on, o.resigned_on, o.resigned_on IS NULL`
+
+// SearchOfficers returns the people whose officer name matches name, with all their matching
+// appointments. Appointments are deduplicated into people by officer_person_id (normalized
+// name and month of birth); people are ordered by their number of current appointments, and
+// limit and offset page through people rather than appointments.
+func (db *DB) SearchOfficers(ctx context.Context, name string, limit, offset int) ([]models.OfficerProfile, error) {
+	rows, err := db.Read().Query(ctx, `
+	WITH people AS (
+		SELECT officer_person_id(o.officer_name, o.date_of_birth) as person_id, row_number() OVER (
+			ORDER BY count(*) FILTER (WHERE o.resigned_on IS NULL) DESC, count(*) DESC,
+				officer_person_id(o.officer_name, o.date_of_birth)
+		) as ord
+		FROM staging_officers o
+		WHERE `+officerNameMatch+`
+		GROUP BY 1
+		ORDER BY ord
+		LIMIT $2 OFFSET $3
+	)
+	SELECT `+officerAppointmentColumns+`
+	FROM people p
+	JOIN staging_officers o ON officer_person_id(o.officer_name, o.date_of_birth) = p.person_id
+	LEFT JOIN staging_companies c ON c.company_number = o.company_number
+	ORDER BY p.ord, o.resigned_on IS NOT NULL, o.appointed_on DESC NULLS LAST, o.id
+	`, name, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search officers: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOfficerProfiles(rows)
+}
+
+// GetOfficer returns the person with the given officer_person_id and every one of their
+// appointments, current ones first. It returns nil if no appointment has that ID.
+func (db *DB) GetOfficer(ctx context.Context, id string) (*models.OfficerProfile, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT `+officerAppointmentColumns+`
+	FROM staging_officers o
+	LEFT JOIN staging_companies c ON c.company_number = o.company_number
+	WHERE officer_person_id(o.officer_name, o.date_of_birth) = $1
+	ORDER BY o.resigned_on IS NOT NULL, o.appointed_on DESC NULLS LAST, o.id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get officer: %w", err)
+	}
+	defer rows.Close()
+
+	people, err := scanOfficerProfiles(rows)
+	if err != nil || len(people) == 0 {
+		return nil, err
+	}
+	return &people[0], nil
+}
+
+// scanOfficerProfiles groups rows of officerAppointmentColumns, ordered by person, into one
+// profile per person. Name and nationality are taken from the first appointment that has them.
+func scanOfficerProfiles(rows pgx.Rows) ([]models.OfficerProfile, error) {
+	people := make([]models.OfficerProfile, 0)
+	for rows.Next() {
+		var id string
+		var officerName, nationality, dob *string
+		var a models.OfficerAppointment
+		err := rows.Scan(
+			&id, &officerName, &dob, &nationality,
+			&a.ID, &a.CompanyNumber, &a.CompanyName, &a.CompanyStatus, &a.Role,
+			&a.AppointedOn, &a.ResignedOn, &a.Active,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan officer appointment: %w", err)
+		}
+		if len(people) == 0 || people[len(people)-1].ID != id {
+			people = append(people, models.OfficerProfile{ID: id, DateOfBirth: dob})
+		}
+		p := &people[len(people)-1]
+		if p.Name == "" && officerName != nil {
+			p.Name = *officerName
+		}
+		if p.Nationality == nil {
+			p.Nationality = nationality
+		}
+		if a.Active {
+			p.ActiveAppointments++
+		} else {
+			p.ResignedAppointments++
+		}
+		p.Appointments = append(p.Appointments, a)
+	}
+	return people, rows.Err()
+}
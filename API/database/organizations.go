@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"data-co/api/models"
+)
+
+// ErrOrganizationNotFound is returned when an organization referred to does not exist
+var ErrOrganizationNotFound = errors.New("organization not found")
+
+// ErrUserConflict is returned when a JWT subject already belongs to an organization
+var ErrUserConflict = errors.New("user already exists")
+
+// tenantTables hold the stored objects of API callers, scoped by their owner_id column
+var tenantTables = []string{"watchlists", "webhook_subscriptions", "export_jobs", "hubspot_syncs"}
+
+const organizationColumns = "id, name, tier, monthly_request_quota, monthly_row_quota, created_at"
+
+func scanOrganization(row pgx.Row) (models.Organization, error) {
+	var org models.Organization
+	err := row.Scan(&org.ID, &org.Name, &org.Tier, &org.MonthlyRequestQuota, &org.MonthlyRowQuota, &org.CreatedAt)
+	return org, err
+}
+
+// CreateOrganization stores a new organization
+func (db *DB) CreateOrganization(ctx context.Context, req models.CreateOrganizationRequest) (models.Organization, error) {
+	org, err := scanOrganization(db.QueryRow(ctx, `
+	INSERT INTO organizations (name, tier, monthly_request_quota, monthly_row_quota) VALUES ($1, $2, $3, $4)
+	RETURNING `+organizationColumns, req.Name, req.Tier, req.MonthlyRequestQuota, req.MonthlyRowQuota))
+	if err != nil {
+		return org, fmt.Errorf("failed to create organization: %w", err)
+	}
+	return org, nil
+}
+
+// ListOrganizations returns every organization
+func (db *DB) ListOrganizations(ctx context.Context) ([]models.Organization, error) {
+	rows, err := db.Query(ctx, "SELECT "+organizationColumns+" FROM organizations ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := make([]models.Organization, 0)
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
+
+// GetOrganization returns an organization, or nil if it does not exist
+func (db *DB) GetOrganization(ctx context.Context, id int) (*models.Organization, error) {
+	org, err := scanOrganization(db.QueryRow(ctx, "SELECT "+organizationColumns+" FROM organizations WHERE id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch organization: %w", err)
+	}
+	return &org, nil
+}
+
+// CreateUser adds a JWT subject to an organization. Objects the subject stored before joining
+// move to the organization, so they stay visible to it. It returns ErrOrganizationNotFound or
+// ErrUserConflict when the organization does not exist or the subject already has one.
+func (db *DB) CreateUser(ctx context.Context, orgID int, req models.CreateUserRequest) (models.User, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var user models.User
+	err = tx.QueryRow(ctx, `
+	INSERT INTO users (organization_id, subject, email) VALUES ($1, $2, $3)
+	RETURNING id, organization_id, subject, email, created_at
+	`, orgID, req.Subject, req.Email).Scan(&user.ID, &user.OrganizationID, &user.Subject, &user.Email, &user.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return models.User{}, ErrUserConflict
+		case "23503": // foreign_key_violation
+			return models.User{}, ErrOrganizationNotFound
+		}
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	from, to := "sub:"+req.Subject, fmt.Sprintf("org:%d", orgID)
+	for _, table := range tenantTables {
+		if _, err := tx.Exec(ctx, "UPDATE "+table+" SET owner_id = $2 WHERE owner_id = $1", from, to); err != nil {
+			return models.User{}, fmt.Errorf("failed to move %s to organization: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.User{}, fmt.Errorf("failed to commit user: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers returns an organization's users
+func (db *DB) ListUsers(ctx context.Context, orgID int) ([]models.User, error) {
+	rows, err := db.Query(ctx, `
+	SELECT id, organization_id, subject, email, created_at
+	FROM users
+	WHERE organization_id = $1
+	ORDER BY id
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.OrganizationID, &user.Subject, &user.Email, &user.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// DeleteUser removes a user from an organization; what they stored stays with it. It returns
+// false if the organization has no such user.
+func (db *DB) DeleteUser(ctx context.Context, orgID, id int) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM users WHERE organization_id = $1 AND id = $2", orgID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete user: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UserOrganization returns the organization of a JWT subject; found is false if the subject is
+// not a user of any organization
+func (db *DB) UserOrganization(ctx context.Context, subject string) (orgID int, found bool, err error) {
+	err = db.QueryRow(ctx, "SELECT organization_id FROM users WHERE subject = $1", subject).Scan(&orgID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up user: %w", err)
+	}
+	return orgID, true, nil
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
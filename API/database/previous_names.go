@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// StagingPreviousName is an entry of staging_companies.previous_name_history
+type StagingPreviousName struct {
+	Name          string  `json:"name"`
+	EffectiveFrom *string `json:"effective_from"` // YYYY-MM-DD
+	CeasedOn      *string `json:"ceased_on"`
+}
+
+// previousNameHistory encodes previous names for the previous_name_history column, or nil
+// when there are none
+func previousNameHistory(names []StagingPreviousName) []byte {
+	if len(names) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(names)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// GetPreviousNames returns a company's previous names, most recent first. Names loaded before
+// their dates were recorded are returned without dates. It returns nil if the company does not
+// exist.
+func (db *DB) GetPreviousNames(ctx context.Context, companyNumber string) ([]models.PreviousName, error) {
+	var pipeSeparated *string
+	var history []byte
+	err := db.Read().QueryRow(ctx, "SELECT previous_names, previous_name_history FROM staging_companies WHERE company_number = $1", companyNumber).
+		Scan(&pipeSeparated, &history)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous names: %w", err)
+	}
+
+	names := make([]models.PreviousName, 0)
+	if history != nil {
+		var entries []StagingPreviousName
+		if err := json.Unmarshal(history, &entries); err != nil {
+			return nil, fmt.Errorf("invalid previous name history for %s: %w", companyNumber, err)
+		}
+		for _, e := range entries {
+			names = append(names, models.PreviousName{Name: e.Name, EffectiveFrom: parseDate(e.EffectiveFrom), CeasedOn: parseDate(e.CeasedOn)})
+		}
+		return names, nil
+	}
+
+	if pipeSeparated != nil {
+		for _, name := range strings.Split(*pipeSeparated, "|") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, models.PreviousName{Name: name})
+			}
+		}
+	}
+	return names, nil
+}
+
+// parseDate parses a YYYY-MM-DD date, or returns nil if it is missing or invalid
+func parseDate(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", *s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// StagingPSC is a staging_pscs row as written by ingesters
+type StagingPSC struct {
+	CompanyNumber       string
+	PSCID               string
+	Kind                string
+	PSCType             string
+	Name                *string
+	Nationality         *string
+	CountryOfResidence  *string
+	DateOfBirth         *string // YYYY-MM-01
+	NaturesOfControl    []string
+	NotifiedOn          *string // YYYY-MM-DD
+	CeasedOn            *string
+	Statement           *string
+	LegalForm           *string
+	RegistrationNumber  *string
+	PlaceRegistered     *string
+	CountryRegistered   *string
+	ParentCompanyNumber *string // Set for corporate PSCs registered at Companies House
+	AddressLine1        *string
+	Locality            *string
+	PostalCode          *string
+	Country             *string
+	RawData             []byte
+	DataHash            string
+}
+
+// Hash computes the change detection hash over the stored PSC fields
+func (p StagingPSC) Hash() string {
+	return hashFields(
+		p.CompanyNumber, p.PSCID, p.Kind, p.Name, p.Nationality, p.CountryOfResidence, p.DateOfBirth,
+		strings.Join(p.NaturesOfControl, "|"), p.NotifiedOn, p.CeasedOn, p.Statement,
+		p.LegalForm, p.RegistrationNumber, p.AddressLine1, p.Locality, p.PostalCode, p.Country,
+		p.PlaceRegistered, p.CountryRegistered,
+	)
+}
+
+// pscColumns are the staging_pscs columns written by ingesters, in StagingPSC order
+var pscColumns = []string{
+	"company_number", "psc_id", "kind", "psc_type", "name", "nationality", "country_of_residence",
+	"date_of_birth", "natures_of_control", "notified_on", "ceased_on", "statement",
+	"legal_form", "registration_number", "place_registered", "country_registered", "parent_company_number",
+	"address_line_1", "locality", "postal_code", "country",
+	"raw_data", "data_hash",
+}
+
+func (p StagingPSC) values() []any {
+	return []any{
+		p.CompanyNumber, p.PSCID, p.Kind, p.PSCType, p.Name, p.Nationality, p.CountryOfResidence,
+		p.DateOfBirth, p.NaturesOfControl, p.NotifiedOn, p.CeasedOn, p.Statement,
+		p.LegalForm, p.RegistrationNumber, p.PlaceRegistered, p.CountryRegistered, p.ParentCompanyNumber,
+		p.AddressLine1, p.Locality, p.PostalCode, p.Country,
+		p.RawData, p.DataHash,
+	}
+}
+
+// pscUpsert is the ON CONFLICT clause shared by the importer and the stream ingester
+const pscUpsert = `
+	ON CONFLICT (company_number, psc_id) DO UPDATE SET
+		kind = EXCLUDED.kind,
+		psc_type = EXCLUDED.psc_type,
+		name = EXCLUDED.name,
+		nationality = EXCLUDED.nationality,
+		country_of_residence = EXCLUDED.country_of_residence,
+		date_of_birth = EXCLUDED.date_of_birth,
+		natures_of_control = EXCLUDED.natures_of_control,
+		notified_on = EXCLUDED.notified_on,
+		ceased_on = EXCLUDED.ceased_on,
+		statement = EXCLUDED.statement,
+		legal_form = EXCLUDED.legal_form,
+		registration_number = EXCLUDED.registration_number,
+		place_registered = EXCLUDED.place_registered,
+		country_registered = EXCLUDED.country_registered,
+		parent_company_number = EXCLUDED.parent_company_number,
+		address_line_1 = EXCLUDED.address_line_1,
+		locality = EXCLUDED.locality,
+		postal_code = EXCLUDED.postal_code,
+		country = EXCLUDED.country,
+		raw_data = EXCLUDED.raw_data,
+		data_hash = EXCLUDED.data_hash,
+		batch_id = EXCLUDED.batch_id,
+		last_updated = EXCLUDED.last_updated
+	WHERE staging_pscs.data_hash IS DISTINCT FROM EXCLUDED.data_hash
+	`
+
+// ImportPSCs COPYs a batch of PSC snapshot records into a temporary table and upserts them
+// into staging_pscs in one transaction, skipping unchanged rows. It returns the number of rows written.
+func (db *DB) ImportPSCs(ctx context.Context, batchID string, pscs []StagingPSC) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Dates are staged as text and cast on insert, since COPY's binary format needs typed values
+	_, err = tx.Exec(ctx, `
+	CREATE TEMP TABLE import_pscs (
+		company_number VARCHAR(8) NOT NULL,
+		psc_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		psc_type TEXT NOT NULL,
+		name TEXT,
+		nationality TEXT,
+		country_of_residence TEXT,
+		date_of_birth TEXT,
+		natures_of_control TEXT[],
+		notified_on TEXT,
+		ceased_on TEXT,
+		statement TEXT,
+		legal_form TEXT,
+		registration_number TEXT,
+		place_registered TEXT,
+		country_registered TEXT,
+		parent_company_number TEXT,
+		address_line_1 TEXT,
+		locality TEXT,
+		postal_code TEXT,
+		country TEXT,
+		raw_data JSONB,
+		data_hash TEXT
+	) ON COMMIT DROP
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	rows := make([][]any, len(pscs))
+	for i, p := range pscs {
+		rows[i] = p.values()
+	}
+
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_pscs"}, pscColumns, pgx.CopyFromRows(rows)); err != nil {
+		return 0, fmt.Errorf("failed to copy PSCs: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+	INSERT INTO staging_pscs (`+strings.Join(pscColumns, ", ")+`, batch_id, last_updated)
+	SELECT DISTINCT ON (t.company_number, t.psc_id)
+		t.company_number, t.psc_id, t.kind, t.psc_type, t.name, t.nationality, t.country_of_residence,
+		t.date_of_birth::date, t.natures_of_control, t.notified_on::date, t.ceased_on::date, t.statement,
+		t.legal_form, t.registration_number, t.place_registered, t.country_registered, t.parent_company_number,
+		t.address_line_1, t.locality, t.postal_code, t.country,
+		t.raw_data, t.data_hash, $1, NOW()
+	FROM import_pscs t
+	ORDER BY t.company_number, t.psc_id
+	`+pscUpsert, batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert PSCs: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit import batch: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UpsertStreamPSC inserts or updates a PSC from the streaming API. It returns false if the
+// stored row was already identical.
+func (db *DB) UpsertStreamPSC(ctx context.Context, p StagingPSC) (bool, error) {
+	tag, err := db.Exec(ctx, `
+	INSERT INTO staging_pscs (`+strings.Join(pscColumns, ", ")+`, batch_id, last_updated)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, NOW())
+	`+pscUpsert, append(p.values(), streamBatchID)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert PSC for %s: %w", p.CompanyNumber, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteStreamPSC removes a PSC record deleted upstream
+func (db *DB) DeleteStreamPSC(ctx context.Context, companyNumber, pscID string) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM staging_pscs WHERE company_number = $1 AND psc_id = $2", companyNumber, pscID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete PSC for %s: %w", companyNumber, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListCompanyPSCs returns a company's PSCs and PSC statements, current ones first
+func (db *DB) ListCompanyPSCs(ctx context.Context, companyNumber string) ([]models.PSC, error) {
+	rows, err := db.Read().Query(ctx, `
+	SELECT psc_id, kind, psc_type, name, nationality, country_of_residence,
+		to_char(date_of_birth, 'YYYY-MM'), natures_of_control, notified_on, ceased_on, statement,
+		legal_form, registration_number, place_registered, country_registered, parent_company_number,
+		address_line_1, locality, postal_code, country
+	FROM staging_pscs
+	WHERE company_number = $1
+	ORDER BY ceased_on IS NOT NULL, notified_on DESC NULLS LAST, id
+	`, companyNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PSCs: %w", err)
+	}
+	defer rows.Close()
+
+	pscs := make([]models.PSC, 0)
+	for rows.Next() {
+		var p models.PSC
+		err := rows.Scan(
+			&p.ID, &p.Kind, &p.PSCType, &p.Name, &p.Nationality, &p.CountryOfResidence,
+			&p.DateOfBirth, &p.NaturesOfControl, &p.NotifiedOn, &p.CeasedOn, &p.Statement,
+			&p.LegalForm, &p.RegistrationNumber, &p.PlaceRegistered, &p.CountryRegistered, &p.ParentCompany,
+			&p.AddressLine1, &p.Locality, &p.PostalCode, &p.Country,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PSC: %w", err)
+		}
+		pscs = append(pscs, p)
+	}
+	return pscs, rows.Err()
+}
@@ -2,12 +2,37 @@ package database
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"data-co/api/models"
+	"data-co/api/scoring"
 )
 
+// companyJoins joins each company to its precomputed search summaries.
+// The summaries are materialized views refreshed by RefreshSearchSummaries.
+const companyJoins = `
+	FROM staging_companies c
+	LEFT JOIN staging_latest_financials latest_fin ON c.company_number = latest_fin.company_number
+	LEFT JOIN staging_officer_counts officer_counts ON c.company_number = officer_counts.company_number
+	LEFT JOIN company_health_scores health ON c.company_number = health.company_number
+	LEFT JOIN company_risk_ratings risk ON c.company_number = risk.company_number
+	`
+
+// distinctCompanies keeps one row per company where staging holds a company under several
+// forms of its number (see company_number_key): the published form, or failing that the most
+// recently updated variant. Rows in the published form, nearly all of them, pass on the first test.
+const distinctCompanies = `(c.company_number = company_number_key(c.company_number) OR (
+	NOT EXISTS (SELECT 1 FROM staging_companies d WHERE d.company_number = company_number_key(c.company_number))
+	AND NOT EXISTS (
+		SELECT 1 FROM staging_companies d
+		WHERE company_number_key(d.company_number) = company_number_key(c.company_number)
+			AND d.company_number <> company_number_key(d.company_number)
+			AND (COALESCE(d.last_updated, '-infinity'), d.company_number) > (COALESCE(c.last_updated, '-infinity'), c.company_number)
+	)))`
+
 // QueryBuilder builds SQL queries based on filter criteria
 type QueryBuilder struct {
 	conditions []string
@@ -31,26 +56,94 @@ func (qb *QueryBuilder) addCondition(condition string, value interface{}) {
 	qb.args = append(qb.args, value)
 }
 
+// bucket is a named range of a filter, from min up to max (or without an upper bound when max
+// is 0)
+type bucket struct {
+	name     string
+	min, max float64
+}
+
+// Buckets of the range filters, in the order they are listed to clients
+var (
+	revenueBuckets = []bucket{
+		{"0-1m", 0, 1_000_000},
+		{"1m-10m", 1_000_000, 10_000_000},
+		{"10m-50m", 10_000_000, 50_000_000},
+		{"50m-100m", 50_000_000, 100_000_000},
+		{"50m+", 50_000_000, 0},
+		{"100m+", 100_000_000, 0},
+	}
+	revenueGrowthBuckets = []bucket{
+		{"0-10", 0, 10},
+		{"10-20", 10, 20},
+		{"20-50", 20, 50},
+		{"20+", 20, 0},
+		{"50+", 50, 0},
+		{"100+", 100, 0},
+	}
+	employeesBuckets = []bucket{
+		{"1-10", 1, 10},
+		{"11-50", 11, 50},
+		{"51-250", 51, 250},
+		{"251+", 251, 0},
+	}
+	companySizeBuckets = []bucket{
+		{"micro", 1, 10},
+		{"small", 11, 50},
+		{"medium", 51, 250},
+		{"large", 251, 0},
+	}
+	companyAgeBuckets = []bucket{
+		{"0-2", 0, 2},
+		{"3-5", 3, 5},
+		{"6-10", 6, 10},
+		{"11-20", 11, 20},
+		{"21+", 21, 0},
+	}
+	netAssetsBuckets = []bucket{
+		{"0-100k", 0, 100_000},
+		{"100k-1m", 100_000, 1_000_000},
+		{"1m-10m", 1_000_000, 10_000_000},
+		{"10m+", 10_000_000, 0},
+	}
+	debtLevelBuckets = []bucket{
+		{"none", 0, 0.01},
+		{"low", 0.01, 0.30},
+		{"medium", 0.30, 0.60},
+		{"high", 0.60, 0},
+	}
+)
+
+// findBucket returns the bucket with the given name
+func findBucket(buckets []bucket, name string) (bucket, bool) {
+	for _, b := range buckets {
+		if b.name == name {
+			return b, true
+		}
+	}
+	return bucket{}, false
+}
+
+// bucketNames returns the names of buckets, with any extra accepted values after them
+func bucketNames(buckets []bucket, extra ...string) []string {
+	names := make([]string, 0, len(buckets)+len(extra))
+	for _, b := range buckets {
+		names = append(names, b.name)
+	}
+	return append(names, extra...)
+}
+
 // AddIndustryFilter filters by industry using SIC codes
 func (qb *QueryBuilder) AddIndustryFilter(industry string) {
 	if industry == "" {
 		return
 	}
 
-	// Map industry names to SIC code prefixes
-	// See: https://resources.companieshouse.gov.uk/sic/
-	industryToSicPrefixes := map[string][]string{
-		"tech":          {"62", "63"},       // Computer programming, IT services, data processing
-		"finance":       {"64", "65", "66"}, // Financial services, insurance
-		"retail":        {"47"},             // Retail trade
-		"manufacturing": {"10", "11", "12", "13", "14", "15", "16", "17", "18", "19", "20", "21", "22", "23", "24", "25", "26", "27", "28", "29", "30", "31", "32", "33"},
-		"professional":  {"69", "70", "71", "72", "73", "74"}, // Professional, scientific and technical
-	}
-
-	prefixes, ok := industryToSicPrefixes[industry]
+	// Map industry names to SIC code prefixes (the cached industries table)
+	prefixes, ok := industrySICPrefixes(industry)
 	if !ok {
 		// If no mapping found, try to match directly against sic_codes array
-		qb.addCondition("$%d = ANY(c.sic_codes)", industry)
+		qb.addCondition("c.sic_codes @> ARRAY[$%d]::text[]", industry)
 		return
 	}
 
@@ -67,37 +160,106 @@ func (qb *QueryBuilder) AddIndustryFilter(industry string) {
 	qb.conditions = append(qb.conditions, condition)
 }
 
-// AddLocationFilter filters by location (locality or region)
-func (qb *QueryBuilder) AddLocationFilter(location string) {
-	if location == "" {
-		return
+// AddSICFilters filters by the SIC 2007 hierarchy of sic_taxonomy: companies with any SIC code
+// in one of the sections, one of the divisions and one of the classes listed, each a
+// comma-separated list. A class is a 4-digit class or a 5-digit Companies House code. The codes
+// of the taxonomy are matched with the GIN index on sic_codes, so codes it does not list never
+// match.
+func (qb *QueryBuilder) AddSICFilters(sections, divisions, classes string) {
+	if values := sicParts(sections); len(values) > 0 {
+		qb.addCondition("c.sic_codes && ARRAY(SELECT code::text FROM sic_taxonomy WHERE section = ANY($%d))", values)
 	}
-
-	locationMap := map[string]string{
-		"london":     "London",
-		"manchester": "Manchester",
-		"birmingham": "Birmingham",
-		"edinburgh":  "Edinburgh",
-		"bristol":    "Bristol",
+	if values := sicParts(divisions); len(values) > 0 {
+		qb.addCondition("c.sic_codes && ARRAY(SELECT code::text FROM sic_taxonomy WHERE division = ANY($%d))", values)
+	}
+	if values := sicParts(classes); len(values) > 0 {
+		qb.addCondition("c.sic_codes && ARRAY(SELECT code::text FROM sic_taxonomy WHERE class = ANY($%[1]d) OR code = ANY($%[1]d))", values)
 	}
+}
 
-	dbLocation := locationMap[strings.ToLower(location)]
-	if dbLocation == "" {
-		dbLocation = strings.Title(location)
+// sicParts splits a comma-separated list of SIC sections, divisions or classes, upper-cased and
+// without spaces or the dot of a class written as in the classification, e.g. "62.02"
+func sicParts(list string) []string {
+	values := postcodeParts(list)
+	for i, value := range values {
+		values[i] = strings.ReplaceAll(value, ".", "")
 	}
+	return values
+}
 
-	// Add pattern matching with wildcards for ILIKE
-	pattern := "%" + dbLocation + "%"
+// AddLocationFilter filters by location (locality or region). Names of a location in the
+// locations table, or aliases of one, match companies in that location or any location inside it
+// (so "Greater Manchester" includes Salford). Other values match locality as a substring, or
+// name the company's region (its county) whole, or the country or region of geography_areas it
+// is in.
+func (qb *QueryBuilder) AddLocationFilter(location string) {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return
+	}
 
 	qb.argCount++
-	firstArg := qb.argCount
-	qb.args = append(qb.args, pattern)
+	nameArg := qb.argCount
+	qb.args = append(qb.args, location)
 
+	// Add pattern matching with wildcards for ILIKE
 	qb.argCount++
-	secondArg := qb.argCount
-	qb.args = append(qb.args, pattern)
+	patternArg := qb.argCount
+	qb.args = append(qb.args, "%"+location+"%")
+
+	// ARRAY(...) is evaluated once, leaving the lower(locality), lower(region), country_code and
+	// region_code indexes usable
+	qb.conditions = append(qb.conditions, fmt.Sprintf(`(lower(c.locality) = ANY(ARRAY(SELECT location_names($%[1]d)))
+	OR lower(c.region) = ANY(ARRAY(SELECT location_names($%[1]d)))
+	OR (NOT EXISTS (SELECT location_names($%[1]d)) AND (c.locality ILIKE $%[2]d
+		OR lower(c.region) = lower($%[1]d)
+		OR c.country_code = ANY(ARRAY(SELECT code FROM geography_areas WHERE level = 'country' AND lower(name) = lower($%[1]d)))
+		OR c.region_code = ANY(ARRAY(SELECT code FROM geography_areas WHERE level = 'region' AND lower(name) = lower($%[1]d))))))`, nameArg, patternArg))
+}
+
+// countryNameColumn and ukRegionNameColumn are the names of the country and region a company
+// is in, from geography_areas
+const (
+	countryNameColumn  = "(SELECT g.name FROM geography_areas g WHERE g.code = c.country_code)"
+	ukRegionNameColumn = "(SELECT g.name FROM geography_areas g WHERE g.code = c.region_code)"
+)
 
-	qb.conditions = append(qb.conditions, fmt.Sprintf("(c.locality ILIKE $%d OR c.region ILIKE $%d)", firstArg, secondArg))
+// countries are the accepted values of the country filter, the slugs of the countries in
+// geography_areas
+var countries = []string{"england", "scotland", "wales", "northern-ireland"}
+
+// ukRegions are the accepted values of the uk_region filter, the slugs of the regions in
+// geography_areas
+var ukRegions = []string{
+	"north-east", "north-west", "yorkshire-and-the-humber", "east-midlands", "west-midlands",
+	"east-of-england", "london", "south-east", "south-west", "scotland", "wales", "northern-ireland",
+}
+
+// AddGeographyFilters filters by the country and region a company's registered office is in,
+// each a comma-separated list of countries or ukRegions matched on the indexed country_code and
+// region_code the geocoding job sets, and by locality, a comma-separated list of post towns
+// matched whole and case-insensitively. Unknown countries and regions are ignored.
+func (qb *QueryBuilder) AddGeographyFilters(countryList, regionList, localityList string) {
+	if values := knownParts(countryList, countries); len(values) > 0 {
+		qb.addCondition("c.country_code = ANY(ARRAY(SELECT code FROM geography_areas WHERE level = 'country' AND slug = ANY($%d)))", values)
+	}
+	if values := knownParts(regionList, ukRegions); len(values) > 0 {
+		qb.addCondition("c.region_code = ANY(ARRAY(SELECT code FROM geography_areas WHERE level = 'region' AND slug = ANY($%d)))", values)
+	}
+	if values := listParts(localityList); len(values) > 0 {
+		qb.addCondition("lower(c.locality) = ANY($%d)", values)
+	}
+}
+
+// knownParts splits a comma-separated list as listParts does, keeping only the values in known
+func knownParts(list string, known []string) []string {
+	var values []string
+	for _, value := range listParts(list) {
+		if slices.Contains(known, value) {
+			values = append(values, value)
+		}
+	}
+	return values
 }
 
 // AddRevenueFilter filters by revenue range
@@ -106,16 +268,7 @@ func (qb *QueryBuilder) AddRevenueFilter(revenueRange string) {
 		return
 	}
 
-	ranges := map[string]struct{ min, max float64 }{
-		"0-1m":      {0, 1_000_000},
-		"1m-10m":    {1_000_000, 10_000_000},
-		"10m-50m":   {10_000_000, 50_000_000},
-		"50m-100m":  {50_000_000, 100_000_000},
-		"100m+":     {100_000_000, 0},
-		"50m+":      {50_000_000, 0},
-	}
-
-	if r, ok := ranges[revenueRange]; ok {
+	if r, ok := findBucket(revenueBuckets, revenueRange); ok {
 		if r.max == 0 {
 			qb.addCondition("latest_fin.turnover >= $%d", r.min)
 		} else {
@@ -127,31 +280,63 @@ func (qb *QueryBuilder) AddRevenueFilter(revenueRange string) {
 	}
 }
 
-// AddEmployeesFilter filters by employee count (using officer count as proxy)
-func (qb *QueryBuilder) AddEmployeesFilter(employeesRange string) {
-	if employeesRange == "" {
+// AddRevenueGrowthFilter filters by year-on-year turnover growth between the latest two
+// financial periods, as a percentage
+func (qb *QueryBuilder) AddRevenueGrowthFilter(growthRange string) {
+	if growthRange == "" {
 		return
 	}
 
-	ranges := map[string]struct{ min, max int }{
-		"1-10":   {1, 10},
-		"11-50":  {11, 50},
-		"51-250": {51, 250},
-		"251+":   {251, 0},
+	if growthRange == "declining" {
+		qb.conditions = append(qb.conditions, "latest_fin.revenue_growth < 0")
+		return
 	}
 
-	if r, ok := ranges[employeesRange]; ok {
+	if r, ok := findBucket(revenueGrowthBuckets, growthRange); ok {
 		if r.max == 0 {
-			qb.addCondition("officer_counts.active_officers >= $%d", r.min)
+			qb.addCondition("latest_fin.revenue_growth >= $%d", r.min)
 		} else {
 			qb.argCount++
-			qb.conditions = append(qb.conditions, fmt.Sprintf("officer_counts.active_officers BETWEEN $%d AND $%d", qb.argCount, qb.argCount+1))
+			qb.conditions = append(qb.conditions, fmt.Sprintf("latest_fin.revenue_growth >= $%d AND latest_fin.revenue_growth < $%d", qb.argCount, qb.argCount+1))
 			qb.args = append(qb.args, r.min, r.max)
 			qb.argCount++
 		}
 	}
 }
 
+// AddEmployeeCountFilter filters by the average number of employees the latest accounts
+// report. Companies whose accounts do not report one never match.
+func (qb *QueryBuilder) AddEmployeeCountFilter(employeesRange string) {
+	qb.addEmployeeRange("latest_fin.employee_count", employeesRange)
+}
+
+// AddOfficerCountFilter filters by active officer count, a proxy for the size of companies that
+// report no employees
+func (qb *QueryBuilder) AddOfficerCountFilter(officersRange string) {
+	qb.addEmployeeRange("officer_counts.active_officers", officersRange)
+}
+
+// addEmployeeRange filters column to the employeesBuckets range named
+func (qb *QueryBuilder) addEmployeeRange(column, name string) {
+	if name == "" {
+		return
+	}
+
+	if r, ok := findBucket(employeesBuckets, name); ok {
+		if r.max == 0 {
+			qb.addCondition(column+" >= $%d", int(r.min))
+		} else {
+			qb.argCount++
+			qb.conditions = append(qb.conditions, fmt.Sprintf("%s BETWEEN $%d AND $%d", column, qb.argCount, qb.argCount+1))
+			qb.args = append(qb.args, int(r.min), int(r.max))
+			qb.argCount++
+		}
+	}
+}
+
+// profitabilityValues are the accepted values of the profitability filter
+var profitabilityValues = []string{"profitable", "loss_making", "breakeven"}
+
 // AddProfitabilityFilter filters by profitability status
 func (qb *QueryBuilder) AddProfitabilityFilter(profitability string) {
 	if profitability == "" {
@@ -171,26 +356,73 @@ func (qb *QueryBuilder) AddProfitabilityFilter(profitability string) {
 	}
 }
 
+// maxProfitableYears bounds the profitable_years filter
+const maxProfitableYears = 10
+
+// consistentGrowthYears is how many of the latest financial periods in a row must each have
+// grown turnover for consistent_growth
+const consistentGrowthYears = 3
+
+// AddProfitableYearsFilter keeps companies with a profit after tax in each of their latest years
+// financial periods. Companies with fewer periods than that never match. Numbers outside 1 to
+// maxProfitableYears are ignored.
+func (qb *QueryBuilder) AddProfitableYearsFilter(years *int) {
+	if years == nil || *years < 1 || *years > maxProfitableYears {
+		return
+	}
+	qb.addCondition("latest_fin.profitable_years >= $%d", *years)
+}
+
+// AddConsistentGrowthFilter filters by whether turnover grew in each of a company's latest
+// consistentGrowthYears financial periods. Companies without that many periods of turnover
+// only match false.
+func (qb *QueryBuilder) AddConsistentGrowthFilter(consistent *bool) {
+	qb.addBoolCondition(consistent, fmt.Sprintf("COALESCE(latest_fin.growth_years, 0) >= %d", consistentGrowthYears))
+}
+
+// AddCashFilter keeps companies with at least minCash of cash at bank, in GBP, in their latest
+// accounts. Companies whose accounts do not report cash never match.
+func (qb *QueryBuilder) AddCashFilter(minCash *float64) {
+	if minCash == nil {
+		return
+	}
+	qb.addCondition("latest_fin.cash >= $%d", *minCash)
+}
+
+// AddCurrentRatioFilter keeps companies whose latest current assets are at least minRatio times
+// their current liabilities. Companies without both, or with no current liabilities, never match.
+func (qb *QueryBuilder) AddCurrentRatioFilter(minRatio *float64) {
+	if minRatio == nil {
+		return
+	}
+	qb.addCondition("latest_fin.current_ratio >= $%d", *minRatio)
+}
+
+// AddBalanceSheetRangeFilter keeps companies whose latest figure in column is from low to high
+// inclusive, either of which may be nil for no bound. Companies whose accounts do not report
+// the figure never match a bound.
+func (qb *QueryBuilder) AddBalanceSheetRangeFilter(column string, low, high *float64) {
+	if low != nil {
+		qb.addCondition(column+" >= $%d", *low)
+	}
+	if high != nil {
+		qb.addCondition(column+" <= $%d", *high)
+	}
+}
+
 // AddCompanySizeFilter filters by company size
 func (qb *QueryBuilder) AddCompanySizeFilter(size string) {
 	if size == "" {
 		return
 	}
 
-	ranges := map[string]struct{ min, max int }{
-		"micro":  {1, 10},
-		"small":  {11, 50},
-		"medium": {51, 250},
-		"large":  {251, 0},
-	}
-
-	if r, ok := ranges[size]; ok {
+	if r, ok := findBucket(companySizeBuckets, size); ok {
 		if r.max == 0 {
-			qb.addCondition("officer_counts.active_officers >= $%d", r.min)
+			qb.addCondition("officer_counts.active_officers >= $%d", int(r.min))
 		} else {
 			qb.argCount++
 			qb.conditions = append(qb.conditions, fmt.Sprintf("officer_counts.active_officers BETWEEN $%d AND $%d", qb.argCount, qb.argCount+1))
-			qb.args = append(qb.args, r.min, r.max)
+			qb.args = append(qb.args, int(r.min), int(r.max))
 			qb.argCount++
 		}
 	}
@@ -204,21 +436,15 @@ func (qb *QueryBuilder) AddCompanyAgeFilter(ageRange string) {
 
 	currentYear := time.Now().Year()
 
-	ranges := map[string]struct{ maxYear, minYear int }{
-		"0-2":   {currentYear, currentYear - 2},
-		"3-5":   {currentYear - 3, currentYear - 5},
-		"6-10":  {currentYear - 6, currentYear - 10},
-		"11-20": {currentYear - 11, currentYear - 20},
-		"21+":   {0, currentYear - 21},
-	}
-
-	if r, ok := ranges[ageRange]; ok {
-		if r.maxYear == 0 {
-			qb.addCondition("c.incorporation_date <= $%d::date", fmt.Sprintf("%d-01-01", r.minYear))
+	// Ages are in years: incorporated from the start of the year max years ago to the end of
+	// the year min years ago
+	if r, ok := findBucket(companyAgeBuckets, ageRange); ok {
+		if r.max == 0 {
+			qb.addCondition("c.incorporation_date <= $%d::date", fmt.Sprintf("%d-01-01", currentYear-int(r.min)))
 		} else {
 			qb.argCount++
 			qb.conditions = append(qb.conditions, fmt.Sprintf("c.incorporation_date BETWEEN $%d::date AND $%d::date", qb.argCount, qb.argCount+1))
-			qb.args = append(qb.args, fmt.Sprintf("%d-01-01", r.minYear), fmt.Sprintf("%d-12-31", r.maxYear))
+			qb.args = append(qb.args, fmt.Sprintf("%d-01-01", currentYear-int(r.max)), fmt.Sprintf("%d-12-31", currentYear-int(r.min)))
 			qb.argCount++
 		}
 	}
@@ -244,14 +470,7 @@ func (qb *QueryBuilder) AddNetAssetsFilter(netAssetsRange string) {
 		return
 	}
 
-	ranges := map[string]struct{ min, max float64 }{
-		"0-100k":   {0, 100_000},
-		"100k-1m":  {100_000, 1_000_000},
-		"1m-10m":   {1_000_000, 10_000_000},
-		"10m+":     {10_000_000, 0},
-	}
-
-	if r, ok := ranges[netAssetsRange]; ok {
+	if r, ok := findBucket(netAssetsBuckets, netAssetsRange); ok {
 		if r.max == 0 {
 			qb.addCondition("latest_fin.net_worth >= $%d", r.min)
 		} else {
@@ -269,14 +488,7 @@ func (qb *QueryBuilder) AddDebtLevelFilter(debtLevel string) {
 		return
 	}
 
-	ranges := map[string]struct{ min, max float64 }{
-		"none":   {0, 0.01},
-		"low":    {0.01, 0.30},
-		"medium": {0.30, 0.60},
-		"high":   {0.60, 0},
-	}
-
-	if r, ok := ranges[debtLevel]; ok {
+	if r, ok := findBucket(debtLevelBuckets, debtLevel); ok {
 		if r.max == 0 {
 			qb.addCondition("(latest_fin.total_liabilities::numeric / NULLIF(latest_fin.total_assets, 0)) >= $%d", r.min)
 		} else {
@@ -288,86 +500,380 @@ func (qb *QueryBuilder) AddDebtLevelFilter(debtLevel string) {
 	}
 }
 
-// AddSearchTerm adds full-text search on company name
+// likeEscaper escapes the LIKE wildcards in a search term, so it is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// AddSearchTerm adds a case-insensitive search on the current and previous company names
 func (qb *QueryBuilder) AddSearchTerm(searchTerm string) {
 	if searchTerm == "" {
 		return
 	}
 
-	qb.addCondition("c.company_name ILIKE $%d", "%"+searchTerm+"%")
+	qb.addCondition("(c.company_name ILIKE $%[1]d OR c.previous_names ILIKE $%[1]d)", "%"+likeEscaper.Replace(searchTerm)+"%")
+}
+
+// SearchTermMatch reports which name a company found by AddSearchTerm matched on: "name" for
+// the current name, "previous_name" otherwise, or "" when there is no search term
+func SearchTermMatch(companyName, searchTerm string) string {
+	switch {
+	case searchTerm == "":
+		return ""
+	case strings.Contains(strings.ToLower(companyName), strings.ToLower(searchTerm)):
+		return "name"
+	default:
+		return "previous_name"
+	}
+}
+
+// AddHealthFilter filters by financial health band
+func (qb *QueryBuilder) AddHealthFilter(band string) {
+	if !scoring.ValidHealth(band) {
+		return
+	}
+
+	qb.addCondition("health.band = $%d", band)
+}
+
+// AddRiskBandFilter filters by credit risk band
+func (qb *QueryBuilder) AddRiskBandFilter(band string) {
+	if !scoring.ValidRisk(band) {
+		return
+	}
+
+	qb.addCondition("risk.band = $%d", band)
+}
+
+// pscTypes are the kinds of person with significant control the PSC type filter accepts,
+// besides "all"
+var pscTypes = []string{"individual", "corporate", "none_declared"}
+
+// AddPSCTypeFilter filters by the kind of person with significant control a company has
+// currently declared
+func (qb *QueryBuilder) AddPSCTypeFilter(pscType string) {
+	if pscType == "" || pscType == "all" {
+		return
+	}
+
+	switch pscType {
+	case "individual":
+		qb.conditions = append(qb.conditions, "EXISTS (SELECT 1 FROM staging_pscs p WHERE p.company_number = c.company_number AND p.ceased_on IS NULL AND p.psc_type = 'individual')")
+	case "corporate":
+		qb.conditions = append(qb.conditions, "EXISTS (SELECT 1 FROM staging_pscs p WHERE p.company_number = c.company_number AND p.ceased_on IS NULL AND p.psc_type IN ('corporate', 'legal_person'))")
+	case "none_declared":
+		// Companies that have filed a statement that no registrable person or entity exists.
+		// Companies House spells "significant" without the second "i" in this statement.
+		qb.conditions = append(qb.conditions, "EXISTS (SELECT 1 FROM staging_pscs p WHERE p.company_number = c.company_number AND p.ceased_on IS NULL AND p.statement = 'no-individual-or-entity-with-signficant-control')")
+	}
+}
+
+// AddOutstandingChargesFilter filters by whether a company has charges that are not fully
+// satisfied, using the bulk snapshot's mortgage counts or the charges register
+func (qb *QueryBuilder) AddOutstandingChargesFilter(hasOutstanding *bool) {
+	qb.addBoolCondition(hasOutstanding, "(COALESCE(c.num_mort_outstanding, 0) + COALESCE(c.num_mort_part_satisfied, 0) > 0 OR EXISTS (SELECT 1 FROM staging_charges ch WHERE ch.company_number = c.company_number AND ch.status IN ('outstanding', 'part-satisfied')))")
+}
+
+// AddInsolvencyFilters filters by current administration or liquidation and by any history of
+// insolvency proceedings, from the company status or the insolvency cases register
+func (qb *QueryBuilder) AddInsolvencyFilters(inAdministration, inLiquidation, hasHistory *bool) {
+	qb.addBoolCondition(inAdministration, inProceedingsCondition(administrationStatuses, administrationCaseTypes))
+	qb.addBoolCondition(inLiquidation, inProceedingsCondition(liquidationStatuses, liquidationCaseTypes))
+	qb.addBoolCondition(hasHistory, insolvencyHistoryCondition())
+}
+
+// AddPostcodeFilters filters by postcode area (the leading letters, e.g. "EC") and district
+// (the outward code, e.g. "EC1V"), each a comma-separated list of values. Both are matched on
+// the indexed postcode_area and postcode_district of postal_code.
+func (qb *QueryBuilder) AddPostcodeFilters(areas, districts string) {
+	if values := postcodeParts(areas); len(values) > 0 {
+		qb.addCondition("postcode_area(c.postal_code) = ANY($%d)", values)
+	}
+	if values := postcodeParts(districts); len(values) > 0 {
+		qb.addCondition("postcode_district(c.postal_code) = ANY($%d)", values)
+	}
+}
+
+// minAddressContainsLength is the shortest address_contains value that is applied; shorter
+// values match too many addresses for the trigram index to help
+const minAddressContainsLength = 3
+
+// AddAddressContainsFilter filters by text anywhere in the registered office address: the
+// address lines, locality, region and postcode, matched case-insensitively on the indexed
+// company_address_text of the address. Values shorter than minAddressContainsLength are ignored.
+func (qb *QueryBuilder) AddAddressContainsFilter(text string) {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	if len(text) < minAddressContainsLength {
+		return
+	}
+	qb.addCondition("company_address_text(c.address_line_1, c.address_line_2, c.locality, c.region, c.postal_code) LIKE $%d",
+		"%"+likeEscaper.Replace(text)+"%")
+}
+
+// postcodeParts splits a comma-separated list of postcode areas or districts, upper-cased and
+// without spaces
+func postcodeParts(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.ToUpper(strings.Join(strings.Fields(value), "")); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// listParts splits a comma-separated list of codes, lower-cased and trimmed, leaving out empty
+// values
+func listParts(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// companyTypes are the accepted values of the company type filter, as returned by the
+// company_type_code SQL function
+var companyTypes = []string{"ltd", "guarantee", "plc", "llp", "lp", "unlimited", "cic", "charitable-incorporated", "overseas", "other"}
+
+// AddCompanyTypeFilter filters by company type, a comma-separated list of companyTypes matched
+// on the indexed company_type_code of the company's type and number. Unknown types are ignored.
+func (qb *QueryBuilder) AddCompanyTypeFilter(list string) {
+	var types []string
+	for _, value := range listParts(list) {
+		if slices.Contains(companyTypes, value) {
+			types = append(types, value)
+		}
+	}
+	if len(types) == 0 {
+		return
+	}
+
+	qb.addCondition("company_type_code(c.company_type, c.company_number) = ANY($%d)", types)
+}
+
+// accountsCategories are the accepted values of the accounts category filter, as returned by
+// the accounts_category_code SQL function
+var accountsCategories = []string{"micro-entity", "small", "medium", "full", "group", "dormant", "none", "other"}
+
+// AddAccountsCategoryFilter filters by the type of a company's last accounts, a comma-separated
+// list of accountsCategories matched on the indexed accounts_category_code. A list starting
+// with "!" excludes those categories instead, keeping companies whose category is unknown.
+// Unknown categories are ignored.
+func (qb *QueryBuilder) AddAccountsCategoryFilter(list string) {
+	list, exclude := strings.CutPrefix(strings.TrimSpace(list), "!")
+
+	var categories []string
+	for _, value := range listParts(list) {
+		if slices.Contains(accountsCategories, value) {
+			categories = append(categories, value)
+		}
+	}
+	if len(categories) == 0 {
+		return
+	}
+
+	if exclude {
+		qb.addCondition("COALESCE(accounts_category_code(c.account_category) <> ALL($%d), TRUE)", categories)
+		return
+	}
+	qb.addCondition("accounts_category_code(c.account_category) = ANY($%d)", categories)
+}
+
+// maxAccountsDueWithinDays bounds the accounts_due_within_days filter to a year's filings
+const maxAccountsDueWithinDays = 366
+
+// AddAccountsDueFilters filters by the next accounts filing deadline: overdue companies have
+// passed it, and a number of days keeps companies whose deadline falls between today and that
+// many days from now. Companies without a deadline are never overdue or due. Negative or
+// larger than maxAccountsDueWithinDays numbers of days are ignored.
+func (qb *QueryBuilder) AddAccountsDueFilters(overdue *bool, dueWithinDays *int) {
+	if overdue != nil {
+		if *overdue {
+			qb.conditions = append(qb.conditions, "c.accounts_next_due_date < CURRENT_DATE")
+		} else {
+			qb.conditions = append(qb.conditions, "(c.accounts_next_due_date >= CURRENT_DATE OR c.accounts_next_due_date IS NULL)")
+		}
+	}
+
+	if dueWithinDays != nil && *dueWithinDays >= 0 && *dueWithinDays <= maxAccountsDueWithinDays {
+		qb.addCondition("c.accounts_next_due_date BETWEEN CURRENT_DATE AND CURRENT_DATE + $%d::int", *dueWithinDays)
+	}
+}
+
+// AddConfStmtOverdueFilter filters by whether a company has passed the deadline for its next
+// confirmation statement. Companies without a deadline are never overdue.
+func (qb *QueryBuilder) AddConfStmtOverdueFilter(overdue *bool) {
+	if overdue == nil {
+		return
+	}
+
+	if *overdue {
+		qb.conditions = append(qb.conditions, "c.conf_stm_next_due_date < CURRENT_DATE")
+	} else {
+		qb.conditions = append(qb.conditions, "(c.conf_stm_next_due_date >= CURRENT_DATE OR c.conf_stm_next_due_date IS NULL)")
+	}
+}
+
+// AddDissolvedFilter filters by dissolution date, between from and to (YYYY-MM-DD, both
+// inclusive and optional). Companies that have not been dissolved never match. Invalid dates
+// are ignored.
+func (qb *QueryBuilder) AddDissolvedFilter(from, to string) {
+	if date, err := time.Parse("2006-01-02", strings.TrimSpace(from)); err == nil {
+		qb.addCondition("c.dissolved_on >= $%d", date)
+	}
+	if date, err := time.Parse("2006-01-02", strings.TrimSpace(to)); err == nil {
+		qb.addCondition("c.dissolved_on <= $%d", date)
+	}
+}
+
+// AddVATRegisteredFilter filters by whether HMRC has confirmed the company's VAT number. Companies
+// with no VAT number, or one not checked yet, only match false.
+func (qb *QueryBuilder) AddVATRegisteredFilter(registered *bool) {
+	qb.addBoolCondition(registered, "c.vat_registered IS TRUE")
+}
+
+// dormantCondition is whether a company is dormant: its last accounts were dormant accounts,
+// its latest accounts declare it dormant, or none of its accounts show any trading (see
+// staging_latest_financials). Companies without accounts are only dormant by their category.
+const dormantCondition = "COALESCE(accounts_category_code(c.account_category) = 'dormant' OR latest_fin.declared_dormant OR latest_fin.inactive, false)"
+
+// AddExcludeDormantFilter leaves out dormant companies when exclude is set
+func (qb *QueryBuilder) AddExcludeDormantFilter(exclude bool) {
+	if exclude {
+		qb.conditions = append(qb.conditions, "NOT "+dormantCondition)
+	}
+}
+
+// AddHasWebsiteFilter filters by whether the website enrichment job matched a website to the
+// company. Companies not looked up yet only match false.
+func (qb *QueryBuilder) AddHasWebsiteFilter(hasWebsite *bool) {
+	qb.addBoolCondition(hasWebsite, "c.website_domain IS NOT NULL")
+}
+
+// Radius search bounds, in kilometres
+const (
+	defaultNearRadiusKm = 10
+	maxNearRadiusKm     = 500
+)
+
+// AddNearFilter filters to geocoded companies within a radius of a postcode or a point. The
+// latitude and longitude ranges bounding the circle let the index on coordinates narrow the
+// search before the haversine distance is checked. Postcodes not in postcode_lookup match no
+// companies.
+func (qb *QueryBuilder) AddNearFilter(near *models.NearFilter) {
+	if near == nil {
+		return
+	}
+
+	var lat, lng string
+	switch {
+	case near.Latitude != nil && near.Longitude != nil:
+		if *near.Latitude < -90 || *near.Latitude > 90 || *near.Longitude < -180 || *near.Longitude > 180 {
+			return
+		}
+		qb.argCount++
+		lat = fmt.Sprintf("$%d::float8", qb.argCount)
+		qb.argCount++
+		lng = fmt.Sprintf("$%d::float8", qb.argCount)
+		qb.args = append(qb.args, *near.Latitude, *near.Longitude)
+	case strings.TrimSpace(near.Postcode) != "":
+		// Uncorrelated subqueries are evaluated once, before the scan
+		qb.argCount++
+		postcode := fmt.Sprintf("upper(regexp_replace($%d, '\\s', '', 'g'))", qb.argCount)
+		lat = "(SELECT latitude::float8 FROM postcode_lookup WHERE postcode = " + postcode + ")"
+		lng = "(SELECT longitude::float8 FROM postcode_lookup WHERE postcode = " + postcode + ")"
+		qb.args = append(qb.args, near.Postcode)
+	default:
+		return
+	}
+
+	radius := near.RadiusKm
+	if radius <= 0 {
+		radius = defaultNearRadiusKm
+	}
+	radius = min(radius, maxNearRadiusKm)
+	qb.argCount++
+	r := fmt.Sprintf("$%d::float8", qb.argCount)
+	qb.args = append(qb.args, radius)
+
+	// A degree of latitude is at least 110.57km; a degree of longitude is 111.32km * cos(latitude)
+	// Bounds are cast to the column type (numeric) so the index can be used
+	qb.conditions = append(qb.conditions, fmt.Sprintf(`c.latitude BETWEEN (%[1]s - %[3]s / 110.574)::numeric AND (%[1]s + %[3]s / 110.574)::numeric
+	AND c.longitude BETWEEN (%[2]s - %[3]s / (111.320 * cos(radians(%[1]s))))::numeric AND (%[2]s + %[3]s / (111.320 * cos(radians(%[1]s))))::numeric
+	AND 2 * 6371 * asin(sqrt(
+		power(sin(radians(c.latitude::float8 - %[1]s) / 2), 2) +
+		cos(radians(%[1]s)) * cos(radians(c.latitude::float8)) * power(sin(radians(c.longitude::float8 - %[2]s) / 2), 2)
+	)) <= %[3]s`, lat, lng, r))
+}
+
+// addBoolCondition adds condition when value is true, its negation when false, and nothing when unset
+func (qb *QueryBuilder) addBoolCondition(value *bool, condition string) {
+	if value == nil {
+		return
+	}
+	if !*value {
+		condition = "NOT " + condition
+	}
+	qb.conditions = append(qb.conditions, condition)
 }
 
 // BuildQuery builds the complete SQL query
 func (qb *QueryBuilder) BuildQuery(filters models.CompanySearchFilters) string {
-	baseQuery := `
-	WITH latest_financials AS (
-		SELECT DISTINCT ON (staging_company_id)
-			staging_company_id as company_id,
-			turnover,
-			profit_loss as profit_after_tax,
-			total_assets,
-			total_liabilities,
-			net_worth,
-			0 as profit_margin,
-			0 as current_ratio,
-			period_end
-		FROM staging_financials
-		WHERE period_end IS NOT NULL
-		ORDER BY staging_company_id, period_end DESC
-	),
-	officer_counts AS (
-		SELECT
-			staging_company_id as company_id,
-			COUNT(*) FILTER (WHERE resigned_on IS NULL) as active_officers
-		FROM staging_officers
-		GROUP BY staging_company_id
-	)
-	SELECT
-		c.id,
-		c.company_number,
-		c.company_name,
-		c.company_status,
-		c.locality,
-		c.region,
-		c.postal_code,
-		'' as primary_sic_code,
-		'' as industry_category,
-		NULL::date as incorporation_date,
-		latest_fin.turnover,
-		latest_fin.profit_after_tax,
-		latest_fin.total_assets,
-		latest_fin.net_worth,
-		latest_fin.profit_margin,
-		latest_fin.period_end as latest_accounts_date,
-		COALESCE(officer_counts.active_officers, 0) as active_officers_count
-	FROM staging_companies c
-	LEFT JOIN latest_financials latest_fin ON c.id = latest_fin.company_id
-	LEFT JOIN officer_counts ON c.id = officer_counts.company_id
-	`
-
-	if len(qb.conditions) > 0 {
-		baseQuery += "\nWHERE " + strings.Join(qb.conditions, " AND ")
+	selectList := companySelectList(filters.Fields)
+	if len(filters.ScoreWeights) > 0 {
+		// Scanned after the fields by ScanSearchResult, and ordered on by orderAndPage
+		selectList = strings.TrimSuffix(selectList, "\n\t") + ",\n\t\t" + qb.scoreColumn(filters.ScoreWeights) + " AS score\n\t"
 	}
+	return "\n\tSELECT" + selectList + companyJoins + qb.where() + qb.orderAndPage(filters)
+}
 
+// maxSample bounds the sample option of a search
+const maxSample = 10000
+
+// orderAndPage builds the ORDER BY, LIMIT and OFFSET clauses of a search from filters, or for
+// a sample orders the matches randomly and keeps the first Sample of them
+func (qb *QueryBuilder) orderAndPage(filters models.CompanySearchFilters) string {
 	// Safe sort column mapping
 	sortMap := map[string]string{
 		"company_name":         "c.company_name",
 		"company_number":       "c.company_number",
 		"incorporation_date":   "c.incorporation_date",
 		"latest_accounts_date": "latest_fin.period_end",
+		"next_accounts_due":    "c.accounts_next_due_date",
 		"turnover":             "latest_fin.turnover",
 		"net_worth":            "latest_fin.net_worth",
-		"employees":            "active_officers_count",
+		"cash":                 "latest_fin.cash",
+		"current_ratio":        "latest_fin.current_ratio",
+		"employees":            "COALESCE(officer_counts.active_officers, 0)",
+		"employee_count":       "latest_fin.employee_count",
+		"officer_count":        "COALESCE(officer_counts.active_officers, 0)",
 		"relevance":            "c.company_name", // Default to name if no similarity score
 	}
 
+	if filters.Sample > 0 {
+		// Ordering by a hash of the seed and number shuffles the matches reproducibly
+		order := "random()"
+		if filters.SampleSeed != nil {
+			qb.argCount++
+			qb.args = append(qb.args, strconv.FormatInt(*filters.SampleSeed, 10))
+			order = fmt.Sprintf("md5($%d || ':' || c.company_number)", qb.argCount)
+		}
+		qb.argCount++
+		qb.args = append(qb.args, filters.Sample)
+		return fmt.Sprintf("\nORDER BY %s\nLIMIT $%d", order, qb.argCount)
+	}
+
 	orderBy := "c.company_name"
 	if filters.OrderBy != "" {
 		if val, ok := sortMap[filters.OrderBy]; ok {
 			orderBy = val
 		}
 	}
-	baseQuery += fmt.Sprintf("\nORDER BY %s", orderBy)
+	if len(filters.ScoreWeights) > 0 {
+		orderBy = "score DESC, c.company_number"
+	}
+	clauses := fmt.Sprintf("\nORDER BY %s", orderBy)
 
 	limit := 100
 	if filters.Limit > 0 {
@@ -380,48 +886,39 @@ func (qb *QueryBuilder) BuildQuery(filters models.CompanySearchFilters) string {
 
 	qb.argCount++
 	qb.args = append(qb.args, limit)
-	baseQuery += fmt.Sprintf("\nLIMIT $%d", qb.argCount)
+	clauses += fmt.Sprintf("\nLIMIT $%d", qb.argCount)
 
 	qb.argCount++
 	qb.args = append(qb.args, offset)
-	baseQuery += fmt.Sprintf(" OFFSET $%d", qb.argCount)
+	clauses += fmt.Sprintf(" OFFSET $%d", qb.argCount)
 
-	return baseQuery
+	return clauses
 }
 
 // BuildCountQuery builds a query to count total matching records
 func (qb *QueryBuilder) BuildCountQuery() string {
-	baseQuery := `
-	WITH latest_financials AS (
-		SELECT DISTINCT ON (staging_company_id)
-			staging_company_id as company_id,
-			turnover,
-			profit_loss as profit_after_tax,
-			total_assets,
-			total_liabilities,
-			net_worth
-		FROM staging_financials
-		WHERE period_end IS NOT NULL
-		ORDER BY staging_company_id, period_end DESC
-	),
-	officer_counts AS (
-		SELECT
-			staging_company_id as company_id,
-			COUNT(*) FILTER (WHERE resigned_on IS NULL) as active_officers
-		FROM staging_officers
-		GROUP BY staging_company_id
-	)
-	SELECT COUNT(*) as total
-	FROM staging_companies c
-	LEFT JOIN latest_financials latest_fin ON c.id = latest_fin.company_id
-	LEFT JOIN officer_counts ON c.id = officer_counts.company_id
-	`
+	return qb.buildFilteredSelect("COUNT(*) as total")
+}
 
-	if len(qb.conditions) > 0 {
-		baseQuery += "\nWHERE " + strings.Join(qb.conditions, " AND ")
+// BuildEstimateQuery builds a query whose result approximates the total matching records.
+// With no conditions the table statistics in pg_class are used directly; otherwise the
+// planner's row estimate for the filtered query is read from EXPLAIN output.
+func (qb *QueryBuilder) BuildEstimateQuery() (query string, fromExplain bool) {
+	if len(qb.conditions) == 0 {
+		return `SELECT GREATEST(reltuples, 0)::bigint FROM pg_class WHERE relname = 'staging_companies'`, false
 	}
+	return "EXPLAIN (FORMAT JSON) " + qb.buildFilteredSelect("1"), true
+}
 
-	return baseQuery
+// buildFilteredSelect builds a query selecting selectList over the filtered company joins
+func (qb *QueryBuilder) buildFilteredSelect(selectList string) string {
+	return "\n\tSELECT " + selectList + companyJoins + qb.where()
+}
+
+// where builds the WHERE clause of the filter conditions, which always collapses duplicate
+// companies
+func (qb *QueryBuilder) where() string {
+	return "\nWHERE " + strings.Join(append([]string{distinctCompanies}, qb.conditions...), " AND ")
 }
 
 // GetArgs returns the query arguments
@@ -429,20 +926,54 @@ func (qb *QueryBuilder) GetArgs() []interface{} {
 	return qb.args
 }
 
-// BuildCompanyQuery is a convenience function to build a query from filters
-func BuildCompanyQuery(filters models.CompanySearchFilters) (string, []interface{}) {
-	qb := NewQueryBuilder()
-
+// applyFilters adds every supported filter to the query builder
+func applyFilters(qb *QueryBuilder, filters models.CompanySearchFilters) {
 	qb.AddIndustryFilter(filters.Industry)
+	qb.AddSICFilters(filters.SICSection, filters.SICDivision, filters.SICClass)
 	qb.AddLocationFilter(filters.Location)
+	qb.AddGeographyFilters(filters.Country, filters.UKRegion, filters.Locality)
 	qb.AddRevenueFilter(filters.Revenue)
-	qb.AddEmployeesFilter(filters.Employees)
+	qb.AddEmployeeCountFilter(filters.EmployeeCount)
+	qb.AddOfficerCountFilter(filters.OfficerCount)
+	qb.AddOfficerCountFilter(filters.Employees)
 	qb.AddProfitabilityFilter(filters.Profitability)
+	qb.AddProfitableYearsFilter(filters.ProfitableYears)
+	qb.AddConsistentGrowthFilter(filters.ConsistentGrowth)
+	qb.AddCashFilter(filters.CashMin)
+	qb.AddCurrentRatioFilter(filters.CurrentRatioMin)
+	qb.AddBalanceSheetRangeFilter("latest_fin.fixed_assets", filters.FixedAssetsMin, filters.FixedAssetsMax)
+	qb.AddBalanceSheetRangeFilter("latest_fin.intangible_assets", filters.IntangibleAssetsMin, filters.IntangibleAssetsMax)
+	qb.AddBalanceSheetRangeFilter("latest_fin.shareholder_funds", filters.ShareholderFundsMin, filters.ShareholderFundsMax)
 	qb.AddCompanySizeFilter(filters.CompanySize)
+	qb.AddCompanyAgeFilter(filters.CompanyAge)
 	qb.AddCompanyStatusFilter(filters.CompanyStatus)
 	qb.AddNetAssetsFilter(filters.NetAssets)
 	qb.AddDebtLevelFilter(filters.DebtLevel)
 	qb.AddSearchTerm(filters.SearchTerm)
+	qb.AddRevenueGrowthFilter(filters.RevenueGrowth)
+	qb.AddHealthFilter(filters.Health)
+	qb.AddRiskBandFilter(filters.RiskBand)
+	qb.AddPSCTypeFilter(filters.PSCType)
+	qb.AddOutstandingChargesFilter(filters.HasOutstandingCharges)
+	qb.AddInsolvencyFilters(filters.InAdministration, filters.InLiquidation, filters.HasInsolvencyHistory)
+	qb.AddPostcodeFilters(filters.PostcodeArea, filters.PostcodeDistrict)
+	qb.AddAddressContainsFilter(filters.AddressContains)
+	qb.AddCompanyTypeFilter(filters.CompanyType)
+	qb.AddAccountsCategoryFilter(filters.AccountsCategory)
+	qb.AddAccountsDueFilters(filters.AccountsOverdue, filters.AccountsDueWithinDays)
+	qb.AddConfStmtOverdueFilter(filters.ConfStmtOverdue)
+	qb.AddDissolvedFilter(filters.DissolvedFrom, filters.DissolvedTo)
+	qb.AddVATRegisteredFilter(filters.VATRegistered)
+	qb.AddHasWebsiteFilter(filters.HasWebsite)
+	qb.AddExcludeDormantFilter(filters.ExcludeDormant)
+	qb.AddNearFilter(filters.Near)
+	qb.AddWhereClauses(filters.Where)
+}
+
+// BuildCompanyQuery is a convenience function to build a query from filters
+func BuildCompanyQuery(filters models.CompanySearchFilters) (string, []interface{}) {
+	qb := NewQueryBuilder()
+	applyExpression(qb, filters)
 
 	query := qb.BuildQuery(filters)
 	return query, qb.GetArgs()
@@ -451,17 +982,7 @@ func BuildCompanyQuery(filters models.CompanySearchFilters) (string, []interface
 // BuildCompanyCountQuery builds a count query from filters
 func BuildCompanyCountQuery(filters models.CompanySearchFilters) (string, []interface{}) {
 	qb := NewQueryBuilder()
-
-	qb.AddIndustryFilter(filters.Industry)
-	qb.AddLocationFilter(filters.Location)
-	qb.AddRevenueFilter(filters.Revenue)
-	qb.AddEmployeesFilter(filters.Employees)
-	qb.AddProfitabilityFilter(filters.Profitability)
-	qb.AddCompanySizeFilter(filters.CompanySize)
-	qb.AddCompanyStatusFilter(filters.CompanyStatus)
-	qb.AddNetAssetsFilter(filters.NetAssets)
-	qb.AddDebtLevelFilter(filters.DebtLevel)
-	qb.AddSearchTerm(filters.SearchTerm)
+	applyExpression(qb, filters)
 
 	query := qb.BuildCountQuery()
 	return query, qb.GetArgs()
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaPingTimeout bounds each replica health check
+const replicaPingTimeout = 5 * time.Second
+
+// Reader runs the read-only queries of search, count and detail endpoints on the read replica
+// while it is up, falling back to the primary when there is none or it cannot be reached.
+// Queries that must see the caller's own writes use the primary directly.
+type Reader struct {
+	db *DB
+}
+
+// Read returns the Reader for read-only company data queries
+func (db *DB) Read() Reader {
+	return Reader{db: db}
+}
+
+// Query runs a query on the replica, or on the primary when the replica is down
+func (r Reader) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if replica := r.db.upReplica(); replica != nil {
+		rows, err := replica.Query(ctx, sql, args...)
+		if err == nil || !r.db.replicaFailed(ctx, err) {
+			return rows, err
+		}
+	}
+	return r.db.Query(ctx, sql, args...)
+}
+
+// QueryRow runs a single-row query on the replica, or on the primary when the replica is down
+func (r Reader) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return fallbackRow{db: r.db, ctx: ctx, sql: sql, args: args}
+}
+
+// fallbackRow defers a single-row query to Scan, since QueryRow only reports errors there
+type fallbackRow struct {
+	db   *DB
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+// Scan runs the query, retrying it on the primary if the replica cannot be reached
+func (row fallbackRow) Scan(dest ...any) error {
+	if replica := row.db.upReplica(); replica != nil {
+		err := replica.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+		if err == nil || !row.db.replicaFailed(row.ctx, err) {
+			return err
+		}
+	}
+	return row.db.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+}
+
+// upReplica returns the replica pool, or nil if there is none or it is down
+func (db *DB) upReplica() *pgxpool.Pool {
+	if db.replica == nil || !db.replicaUp.Load() {
+		return nil
+	}
+	return db.replica
+}
+
+// replicaFailed reports whether err means the replica could not be reached, rather than the
+// query failing, and if so marks it down until the next successful health check
+func (db *DB) replicaFailed(ctx context.Context, err error) bool {
+	var connectErr *pgconn.ConnectError
+	if ctx.Err() != nil || !(errors.As(err, &connectErr) || pgconn.SafeToRetry(err)) {
+		return false
+	}
+	if db.replicaUp.CompareAndSwap(true, false) {
+		log.Printf("Read replica unreachable, using the primary: %v", err)
+	}
+	return true
+}
+
+// monitorReplica pings the replica every interval until stop is closed, marking it up or down
+func (db *DB) monitorReplica(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+		err := db.replica.Ping(ctx)
+		cancel()
+		switch {
+		case err == nil && db.replicaUp.CompareAndSwap(false, true):
+			log.Printf("Read replica reachable, routing read queries to it")
+		case err != nil && db.replicaUp.CompareAndSwap(true, false):
+			log.Printf("Read replica unreachable, using the primary: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
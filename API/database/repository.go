@@ -0,0 +1,207 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"data-co/api/models"
+)
+
+// CompanyRepository reads companies with their officers, financials and other records, and the
+// analytics over them. Handlers depend on it rather than on DB, so they can be given another
+// backend, or a fake in tests.
+type CompanyRepository interface {
+	// Search returns one page of the companies matching filters, which must have their
+	// CompanyStatus and Limit set
+	Search(ctx context.Context, filters models.CompanySearchFilters) ([]models.Company, error)
+	// Explain is Search, also returning the query it ran and its timings. The applied filters
+	// and the count are left for the caller to add.
+	Explain(ctx context.Context, filters models.CompanySearchFilters) ([]models.Company, *models.SearchDebug, error)
+	// Stream calls each with the companies Search would return as they are read, stopping at
+	// the first error each returns
+	Stream(ctx context.Context, filters models.CompanySearchFilters, each func(models.Company) error) error
+	// Count returns the exact number of companies matching filters
+	Count(ctx context.Context, filters models.CompanySearchFilters) (int, error)
+	// Estimate returns an approximate number of companies matching filters
+	Estimate(ctx context.Context, filters models.CompanySearchFilters) (int, error)
+	// Get returns a company by its normalized number, or nil if there is none
+	Get(ctx context.Context, companyNumber string) (*models.Company, error)
+	// GetAsOf returns a company as it stood at the end of the day asOf, or nil if it did not
+	// exist then
+	GetAsOf(ctx context.Context, companyNumber string, asOf time.Time) (*models.Company, error)
+	// Insolvency returns the insolvency status and cases of a company with companyStatus
+	Insolvency(ctx context.Context, companyNumber, companyStatus string) (*models.InsolvencySummary, error)
+	// InsolvencyAsOf returns a company's insolvency status and cases as they stood at the end
+	// of the day asOf, given its status then
+	InsolvencyAsOf(ctx context.Context, companyNumber, companyStatus string, asOf time.Time) (*models.InsolvencySummary, error)
+	// Officers returns a company's officer appointments, current ones first
+	Officers(ctx context.Context, companyNumber string) ([]models.Officer, error)
+	// Financials returns a company's financial history, most recent period first
+	Financials(ctx context.Context, companyNumber string) ([]models.FinancialPeriod, error)
+	// GetMany returns the given companies keyed by company number, without those that do not
+	// exist
+	GetMany(ctx context.Context, companyNumbers []string) (map[string]models.Company, error)
+	// Compare returns side-by-side metrics for the given companies, keyed by company number,
+	// without those that do not exist
+	Compare(ctx context.Context, companyNumbers []string) (map[string]models.CompanyComparison, error)
+	// Match returns up to limit candidates for each query, best first, in query order
+	Match(ctx context.Context, queries []models.MatchQuery, limit int) ([][]models.MatchCandidate, error)
+
+	// Charges returns a company's charges, outstanding ones first
+	Charges(ctx context.Context, companyNumber string) ([]models.Charge, error)
+	// PSCs returns a company's PSCs and PSC statements, current ones first
+	PSCs(ctx context.Context, companyNumber string) ([]models.PSC, error)
+	// PreviousNames returns a company's previous names, most recent first, or nil if the
+	// company does not exist
+	PreviousNames(ctx context.Context, companyNumber string) ([]models.PreviousName, error)
+	// Turnover returns a company's turnover for each financial period, oldest first, only
+	// those ending by asOf when it is set
+	Turnover(ctx context.Context, companyNumber string, asOf *time.Time) ([]models.TurnoverPeriod, error)
+	// Network returns the companies sharing officers with a company up to depth hops away, with
+	// at most maxNodes companies, or nil if the company does not exist
+	Network(ctx context.Context, companyNumber string, depth, maxNodes int) (*models.NetworkResponse, error)
+	// Group returns the corporate group of a company, with at most maxNodes companies, or nil
+	// if the company does not exist
+	Group(ctx context.Context, companyNumber string, maxNodes int) (*models.GroupResponse, error)
+	// Changes returns up to limit recorded changes to a company, newest first, only those after
+	// since or to field when set, or nil if the company does not exist
+	Changes(ctx context.Context, companyNumber string, since *time.Time, field string, limit int) ([]models.CompanyFieldChange, error)
+	// Changed returns up to limit companies written after since, in the order of their latest
+	// write, starting after afterNumber written at afterTime when afterNumber is set
+	Changed(ctx context.Context, since, afterTime time.Time, afterNumber string, limit int) ([]models.ChangedCompany, error)
+
+	// Top ranks the companies matching filters by metric within each group, returning the top
+	// n of each and whether there were more groups
+	Top(ctx context.Context, groupBy, metric string, n int, filters models.CompanySearchFilters) ([]models.TopGroup, bool, error)
+	// Aggregate groups the companies matching filters by dimensions and summarises each,
+	// returning the largest groups and how many there were in all
+	Aggregate(ctx context.Context, dimensions []string, filters models.CompanySearchFilters) ([]models.AggregateGroup, int, error)
+	// Histogram buckets the companies matching filters by field
+	Histogram(ctx context.Context, field string, filters models.CompanySearchFilters) (models.HistogramResponse, error)
+	// IncorporationTrend counts the companies incorporated and dissolved in each period from
+	// from to to, filtered by sic, industry and location where each is set
+	IncorporationTrend(ctx context.Context, groupBy string, from, to time.Time, sic, industry, location string) ([]models.TrendPeriod, error)
+
+	// WithTimeout derives the context the queries of a request run with, cancelled after the
+	// statement timeout or the one requested in ctx
+	WithTimeout(ctx context.Context) (context.Context, context.CancelFunc)
+}
+
+// Companies returns the CompanyRepository of the PostgreSQL database, which reads from the
+// replica when it is up
+func (db *DB) Companies() CompanyRepository {
+	return companyRepository{db: db}
+}
+
+// companyRepository implements CompanyRepository with the DB query methods
+type companyRepository struct {
+	db *DB
+}
+
+func (r companyRepository) Search(ctx context.Context, filters models.CompanySearchFilters) ([]models.Company, error) {
+	return r.db.FindCompanies(ctx, filters)
+}
+
+func (r companyRepository) Explain(ctx context.Context, filters models.CompanySearchFilters) ([]models.Company, *models.SearchDebug, error) {
+	return r.db.ExplainFindCompanies(ctx, filters)
+}
+
+func (r companyRepository) Stream(ctx context.Context, filters models.CompanySearchFilters, each func(models.Company) error) error {
+	return r.db.StreamCompanies(ctx, filters, each)
+}
+
+func (r companyRepository) Count(ctx context.Context, filters models.CompanySearchFilters) (int, error) {
+	return r.db.CountCompanies(ctx, filters)
+}
+
+func (r companyRepository) Estimate(ctx context.Context, filters models.CompanySearchFilters) (int, error) {
+	return r.db.EstimateCompanyCount(ctx, filters)
+}
+
+func (r companyRepository) Get(ctx context.Context, companyNumber string) (*models.Company, error) {
+	return r.db.GetCompanyByNumber(ctx, companyNumber)
+}
+
+func (r companyRepository) GetAsOf(ctx context.Context, companyNumber string, asOf time.Time) (*models.Company, error) {
+	return r.db.GetCompanyAsOf(ctx, companyNumber, asOf)
+}
+
+func (r companyRepository) Insolvency(ctx context.Context, companyNumber, companyStatus string) (*models.InsolvencySummary, error) {
+	return r.db.GetCompanyInsolvency(ctx, companyNumber, companyStatus)
+}
+
+func (r companyRepository) InsolvencyAsOf(ctx context.Context, companyNumber, companyStatus string, asOf time.Time) (*models.InsolvencySummary, error) {
+	return r.db.GetCompanyInsolvencyAsOf(ctx, companyNumber, companyStatus, asOf)
+}
+
+func (r companyRepository) Officers(ctx context.Context, companyNumber string) ([]models.Officer, error) {
+	return r.db.ListCompanyOfficers(ctx, companyNumber)
+}
+
+func (r companyRepository) Financials(ctx context.Context, companyNumber string) ([]models.FinancialPeriod, error) {
+	return r.db.ListCompanyFinancials(ctx, companyNumber)
+}
+
+func (r companyRepository) GetMany(ctx context.Context, companyNumbers []string) (map[string]models.Company, error) {
+	return r.db.GetCompaniesByNumber(ctx, companyNumbers)
+}
+
+func (r companyRepository) Compare(ctx context.Context, companyNumbers []string) (map[string]models.CompanyComparison, error) {
+	return r.db.CompareCompanies(ctx, companyNumbers)
+}
+
+func (r companyRepository) Match(ctx context.Context, queries []models.MatchQuery, limit int) ([][]models.MatchCandidate, error) {
+	return r.db.MatchCompanies(ctx, queries, limit)
+}
+
+func (r companyRepository) Charges(ctx context.Context, companyNumber string) ([]models.Charge, error) {
+	return r.db.ListCompanyCharges(ctx, companyNumber)
+}
+
+func (r companyRepository) PSCs(ctx context.Context, companyNumber string) ([]models.PSC, error) {
+	return r.db.ListCompanyPSCs(ctx, companyNumber)
+}
+
+func (r companyRepository) PreviousNames(ctx context.Context, companyNumber string) ([]models.PreviousName, error) {
+	return r.db.GetPreviousNames(ctx, companyNumber)
+}
+
+func (r companyRepository) Turnover(ctx context.Context, companyNumber string, asOf *time.Time) ([]models.TurnoverPeriod, error) {
+	return r.db.TurnoverSeries(ctx, companyNumber, asOf)
+}
+
+func (r companyRepository) Network(ctx context.Context, companyNumber string, depth, maxNodes int) (*models.NetworkResponse, error) {
+	return r.db.CompanyNetwork(ctx, companyNumber, depth, maxNodes)
+}
+
+func (r companyRepository) Group(ctx context.Context, companyNumber string, maxNodes int) (*models.GroupResponse, error) {
+	return r.db.CompanyGroup(ctx, companyNumber, maxNodes)
+}
+
+func (r companyRepository) Changes(ctx context.Context, companyNumber string, since *time.Time, field string, limit int) ([]models.CompanyFieldChange, error) {
+	return r.db.ListCompanyChanges(ctx, companyNumber, since, field, limit)
+}
+
+func (r companyRepository) Changed(ctx context.Context, since, afterTime time.Time, afterNumber string, limit int) ([]models.ChangedCompany, error) {
+	return r.db.ChangedCompanies(ctx, since, afterTime, afterNumber, limit)
+}
+
+func (r companyRepository) Top(ctx context.Context, groupBy, metric string, n int, filters models.CompanySearchFilters) ([]models.TopGroup, bool, error) {
+	return r.db.TopCompanies(ctx, groupBy, metric, n, filters)
+}
+
+func (r companyRepository) Aggregate(ctx context.Context, dimensions []string, filters models.CompanySearchFilters) ([]models.AggregateGroup, int, error) {
+	return r.db.AggregateCompanies(ctx, dimensions, filters)
+}
+
+func (r companyRepository) Histogram(ctx context.Context, field string, filters models.CompanySearchFilters) (models.HistogramResponse, error) {
+	return r.db.Histogram(ctx, field, filters)
+}
+
+func (r companyRepository) IncorporationTrend(ctx context.Context, groupBy string, from, to time.Time, sic, industry, location string) ([]models.TrendPeriod, error) {
+	return r.db.IncorporationTrend(ctx, groupBy, from, to, sic, industry, location)
+}
+
+func (r companyRepository) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return r.db.WithTimeout(ctx)
+}
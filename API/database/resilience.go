@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxRetryBackoff caps the wait between retries of a transient error
+const maxRetryBackoff = 2 * time.Second
+
+// UnavailableError is returned without querying while the circuit breaker is open, after
+// repeated failures to reach the primary
+type UnavailableError struct {
+	RetryAfter time.Duration // Until the primary is tried again
+}
+
+func (e *UnavailableError) Error() string {
+	return "database unavailable"
+}
+
+// Exec runs a statement on the primary through the circuit breaker, retrying errors it is
+// safe to repeat it after
+func (db *DB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	var tag pgconn.CommandTag
+	err := db.resilient(ctx, func() (err error) {
+		tag, err = db.Pool.Exec(ctx, sql, args...)
+		return err
+	})
+	return tag, err
+}
+
+// Query runs a query on the primary through the circuit breaker, retrying errors it is safe to
+// repeat it after. Errors met once rows are read are not retried.
+func (db *DB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := db.resilient(ctx, func() (err error) {
+		rows, err = db.Pool.Query(ctx, sql, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRow runs a single-row query on the primary like Query, deferring it to Scan
+func (db *DB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return resilientRow{db: db, ctx: ctx, sql: sql, args: args}
+}
+
+// Begin starts a transaction on the primary through the circuit breaker. Failures inside the
+// transaction are not retried, as its statements would need repeating.
+func (db *DB) Begin(ctx context.Context) (pgx.Tx, error) {
+	var tx pgx.Tx
+	err := db.resilient(ctx, func() (err error) {
+		tx, err = db.Pool.Begin(ctx)
+		return err
+	})
+	return tx, err
+}
+
+// resilientRow defers a single-row query to Scan, since QueryRow only reports errors there
+type resilientRow struct {
+	db   *DB
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (row resilientRow) Scan(dest ...any) error {
+	return row.db.resilient(row.ctx, func() error {
+		return row.db.Pool.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	})
+}
+
+// resilient calls fn unless the circuit is open, retrying it with exponential backoff while it
+// fails with a transient error and the circuit stays closed, and records whether the primary
+// could be reached
+func (db *DB) resilient(ctx context.Context, fn func() error) error {
+	if !db.breaker.allow() {
+		return &UnavailableError{RetryAfter: db.breaker.retryAfter()}
+	}
+	backoff := db.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		db.breaker.record(ctx, err)
+		if err == nil || attempt >= db.retryAttempts || !transient(err) || ctx.Err() != nil || db.breaker.retryAfter() > 0 {
+			return err
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// transient reports whether a statement that failed with err can be repeated as is: it was not
+// sent, as the connection failed first, or the server rolled it back on a serialization failure
+// or deadlock
+func transient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	var connectErr *pgconn.ConnectError
+	return errors.As(err, &connectErr) || pgconn.SafeToRetry(err)
+}
+
+// unreachable reports whether err means the database could not be reached or dropped the
+// connection, rather than the statement failing
+func unreachable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// connection_exception, too_many_connections, admin_shutdown, crash_shutdown,
+		// cannot_connect_now
+		return pgErr.Code[:2] == "08" || pgErr.Code == "53300" || pgErr.Code == "57P01" || pgErr.Code == "57P02" || pgErr.Code == "57P03"
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) || errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || pgconn.SafeToRetry(err)
+}
+
+// breaker opens after threshold consecutive failures to reach the database, failing calls fast
+// for cooldown. Then one call at a time is let through, and the first to reach the database
+// closes it again. A zero threshold disables it.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // Zero while closed
+	probing  bool      // A call is testing the database after the cooldown
+}
+
+// allow reports whether a call may go ahead
+func (b *breaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts the outcome of a call. Calls abandoned by their context tell nothing about the
+// database, so another call is let through in place of an abandoned probe.
+func (b *breaker) record(ctx context.Context, err error) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil && ctx.Err() != nil {
+		b.probing = false
+		return
+	}
+	if err == nil || !unreachable(err) {
+		if !b.openedAt.IsZero() {
+			log.Printf("Database reachable again, closing circuit breaker")
+		}
+		b.failures, b.openedAt, b.probing = 0, time.Time{}, false
+		return
+	}
+
+	b.failures++
+	switch {
+	case b.probing:
+		b.openedAt, b.probing = time.Now(), false
+	case b.openedAt.IsZero() && b.failures >= b.threshold:
+		b.openedAt = time.Now()
+		log.Printf("Database unreachable after %d attempts, opening circuit breaker for %s: %v", b.failures, b.cooldown, err)
+	}
+}
+
+// retryAfter returns how long until a call may be let through, or 0 if one may be now
+func (b *breaker) retryAfter() time.Duration {
+	if b.threshold <= 0 {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.openedAt.IsZero():
+		return 0
+	case b.probing:
+		return time.Second
+	}
+	return max(time.Until(b.openedAt.Add(b.cooldown)), 0)
+}
+
+// Unavailable reports whether the circuit breaker is open with no read replica up, so requests
+// would fail, and how long until the primary is tried again
+func (db *DB) Unavailable() (bool, time.Duration) {
+	wait := db.breaker.retryAfter()
+	return wait > 0 && db.upReplica() == nil, wait
+}
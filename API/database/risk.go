@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"data-co/api/scoring"
+)
+
+// RiskInput is a company's current risk signals
+type RiskInput struct {
+	CompanyNumber string
+	Signals       scoring.RiskSignals
+}
+
+// RiskRating is a computed risk rating ready to store
+type RiskRating struct {
+	CompanyNumber string
+	Points        int
+	Band          string
+	Flags         []string
+}
+
+// RiskInputs returns the risk signals of up to limit companies numbered after afterNumber,
+// in company number order, so a full pass can be made in batches
+func (db *DB) RiskInputs(ctx context.Context, afterNumber string, limit int) ([]RiskInput, error) {
+	rows, err := db.Query(ctx, `
+	SELECT
+		c.company_number,
+		COALESCE(c.accounts_next_due_date < CURRENT_DATE, false),
+		COALESCE(c.conf_stm_next_due_date < CURRENT_DATE, false),
+		latest_fin.net_worth::float8,
+		latest_fin.previous_net_worth::float8,
+		GREATEST(
+			COALESCE(c.num_mort_outstanding, 0) + COALESCE(c.num_mort_part_satisfied, 0),
+			(SELECT COUNT(*) FROM staging_charges ch WHERE ch.company_number = c.company_number AND ch.status IN ('outstanding', 'part-satisfied'))
+		)::int,
+		COALESCE(officer_counts.active_officers, 0)::int,
+		(SELECT COUNT(*) FROM staging_officers o WHERE o.company_number = c.company_number AND o.resigned_on >= CURRENT_DATE - INTERVAL '12 months')::int
+	FROM staging_companies c
+	LEFT JOIN staging_latest_financials latest_fin ON c.company_number = latest_fin.company_number
+	LEFT JOIN staging_officer_counts officer_counts ON c.company_number = officer_counts.company_number
+	WHERE c.company_number > $1
+	ORDER BY c.company_number
+	LIMIT $2
+	`, afterNumber, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list risk inputs: %w", err)
+	}
+	defer rows.Close()
+
+	inputs := make([]RiskInput, 0)
+	for rows.Next() {
+		var in RiskInput
+		s := &in.Signals
+		err := rows.Scan(
+			&in.CompanyNumber, &s.AccountsOverdue, &s.ConfirmationStmtOverdue, &s.NetWorth, &s.PreviousNetWorth,
+			&s.OutstandingCharges, &s.ActiveOfficers, &s.RecentResignations,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan risk input: %w", err)
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, rows.Err()
+}
+
+// SaveRiskRatings stores computed risk ratings, replacing any earlier rating for the company
+func (db *DB) SaveRiskRatings(ctx context.Context, ratings []RiskRating) error {
+	batch := make([]map[string]any, len(ratings))
+	for i, r := range ratings {
+		batch[i] = map[string]any{"company_number": r.CompanyNumber, "points": r.Points, "band": r.Band, "flags": r.Flags}
+	}
+
+	// Flags are text arrays of varying length, which UNNEST cannot take as a 2-D array, so the
+	// batch is passed as JSON
+	_, err := db.Exec(ctx, `
+	INSERT INTO company_risk_ratings (company_number, points, band, flags, computed_at)
+	SELECT
+		r->>'company_number',
+		(r->>'points')::int,
+		r->>'band',
+		ARRAY(SELECT jsonb_array_elements_text(r->'flags')),
+		NOW()
+	FROM jsonb_array_elements($1::jsonb) AS r
+	ON CONFLICT (company_number) DO UPDATE SET
+		points = EXCLUDED.points,
+		band = EXCLUDED.band,
+		flags = EXCLUDED.flags,
+		computed_at = EXCLUDED.computed_at
+	`, batch)
+	if err != nil {
+		return fmt.Errorf("failed to save risk ratings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// savedSearchColumns are the columns scanned by scanSavedSearch
+const savedSearchColumns = "id, name, filters, webhook_subscription_id, email_to, evaluated_at, created_at, updated_at"
+
+// scanSavedSearch scans a row of savedSearchColumns
+func scanSavedSearch(row pgx.Row) (models.SavedSearch, error) {
+	var s models.SavedSearch
+	var filters []byte
+	if err := row.Scan(&s.ID, &s.Name, &filters, &s.WebhookID, &s.EmailTo, &s.EvaluatedAt, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(filters, &s.Filters); err != nil {
+		return s, fmt.Errorf("invalid filters in saved search %d: %w", s.ID, err)
+	}
+	return s, nil
+}
+
+// CreateSavedSearch stores a search for an owner. Its matches are tracked from its first
+// evaluation.
+func (db *DB) CreateSavedSearch(ctx context.Context, ownerID, name string, filters models.CompanySearchFilters, webhookID *int, emailTo []string) (models.SavedSearch, error) {
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return models.SavedSearch{}, fmt.Errorf("failed to encode saved search filters: %w", err)
+	}
+	s, err := scanSavedSearch(db.QueryRow(ctx, `
+	INSERT INTO saved_searches (owner_id, name, filters, webhook_subscription_id, email_to)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING `+savedSearchColumns, ownerID, name, data, webhookID, emailTo))
+	if err != nil {
+		return s, fmt.Errorf("failed to create saved search: %w", err)
+	}
+	return s, nil
+}
+
+// ListSavedSearches returns an owner's saved searches
+func (db *DB) ListSavedSearches(ctx context.Context, ownerID string) ([]models.SavedSearch, error) {
+	return db.querySavedSearches(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches WHERE owner_id = $1 ORDER BY id", ownerID)
+}
+
+// ListAllSavedSearches returns every owner's saved searches, for evaluation
+func (db *DB) ListAllSavedSearches(ctx context.Context) ([]models.SavedSearch, error) {
+	return db.querySavedSearches(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches ORDER BY id")
+}
+
+// querySavedSearches runs a query selecting savedSearchColumns
+func (db *DB) querySavedSearches(ctx context.Context, query string, args ...any) ([]models.SavedSearch, error) {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	searches := make([]models.SavedSearch, 0)
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		searches = append(searches, s)
+	}
+	return searches, rows.Err()
+}
+
+// GetSavedSearch returns one of an owner's saved searches, or nil if it does not exist
+func (db *DB) GetSavedSearch(ctx context.Context, ownerID string, id int) (*models.SavedSearch, error) {
+	s, err := scanSavedSearch(db.QueryRow(ctx, "SELECT "+savedSearchColumns+" FROM saved_searches WHERE owner_id = $1 AND id = $2", ownerID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch saved search: %w", err)
+	}
+	return &s, nil
+}
+
+// DeleteSavedSearch removes one of an owner's saved searches with its matches. It returns false
+// if it did not exist.
+func (db *DB) DeleteSavedSearch(ctx context.Context, ownerID string, id int) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM saved_searches WHERE owner_id = $1 AND id = $2", ownerID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListSavedSearchMatches returns up to limit companies that newly matched a saved search after
+// since and still match it, most recent first
+func (db *DB) ListSavedSearchMatches(ctx context.Context, id int, since time.Time, limit int) ([]models.SavedSearchMatch, error) {
+	rows, err := db.Query(ctx, `
+	SELECT`+companyColumns+`, m.matched_at`+companyJoins+`
+	JOIN saved_search_matches m ON m.company_number = c.company_number
+	WHERE m.saved_search_id = $1 AND NOT m.baseline AND m.matched_at > $2
+	ORDER BY m.matched_at DESC, c.company_number
+	LIMIT $3
+	`, id, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved search matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]models.SavedSearchMatch, 0)
+	for rows.Next() {
+		var m models.SavedSearchMatch
+		if m.Company, err = scanCompany(rows, &m.MatchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search match: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// RecordSavedSearchBaseline records every company currently matching a saved search as its
+// baseline, which is not reported as new, and marks the search evaluated. Broad searches match
+// many companies, so the statement timeout is lifted.
+func (db *DB) RecordSavedSearchBaseline(ctx context.Context, s models.SavedSearch) (int64, error) {
+	qb := NewQueryBuilder()
+	applyExpression(qb, s.Filters)
+	qb.argCount++
+	qb.args = append(qb.args, s.ID)
+	query := fmt.Sprintf(`
+	INSERT INTO saved_search_matches (saved_search_id, company_number, baseline)
+	%s
+	ON CONFLICT DO NOTHING`, qb.buildFilteredSelect(fmt.Sprintf("$%d::int, c.company_number, true", qb.argCount)))
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, query, qb.GetArgs()...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record saved search baseline: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "UPDATE saved_searches SET evaluated_at = NOW() WHERE id = $1", s.ID); err != nil {
+		return 0, fmt.Errorf("failed to mark saved search evaluated: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit saved search baseline: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UpdateSavedSearchMatches re-evaluates a saved search against companyNumbers: it records
+// those that match and were not already recorded, and forgets those that no longer match so
+// they are new again if they return. It returns the newly matching company numbers.
+func (db *DB) UpdateSavedSearchMatches(ctx context.Context, s models.SavedSearch, companyNumbers []string) ([]string, error) {
+	qb := NewQueryBuilder()
+	applyExpression(qb, s.Filters)
+	qb.addCondition("c.company_number = ANY($%d)", companyNumbers)
+
+	rows, err := db.Query(ctx, qb.buildFilteredSelect("c.company_number"), qb.GetArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate saved search: %w", err)
+	}
+	matching, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan saved search match: %w", err)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+	DELETE FROM saved_search_matches
+	WHERE saved_search_id = $1 AND company_number = ANY($2) AND NOT company_number = ANY($3)
+	`, s.ID, companyNumbers, matching)
+	if err != nil {
+		return nil, fmt.Errorf("failed to forget saved search matches: %w", err)
+	}
+	rows, err = tx.Query(ctx, `
+	INSERT INTO saved_search_matches (saved_search_id, company_number)
+	SELECT $1, unnest($2::text[])
+	ON CONFLICT DO NOTHING
+	RETURNING company_number
+	`, s.ID, matching)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record saved search matches: %w", err)
+	}
+	added, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to record saved search matches: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit saved search matches: %w", err)
+	}
+	return added, nil
+}
+
+// FinishSavedSearchEvaluation marks a saved search evaluated and, when the evaluation found new
+// matches, records an alert and returns its ID and time
+func (db *DB) FinishSavedSearchEvaluation(ctx context.Context, id, newMatches int) (int64, time.Time, error) {
+	if _, err := db.Exec(ctx, "UPDATE saved_searches SET evaluated_at = NOW() WHERE id = $1", id); err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to mark saved search evaluated: %w", err)
+	}
+	if newMatches == 0 {
+		return 0, time.Time{}, nil
+	}
+
+	var alertID int64
+	var createdAt time.Time
+	err := db.QueryRow(ctx, `
+	INSERT INTO saved_search_alerts (saved_search_id, new_matches) VALUES ($1, $2)
+	RETURNING id, created_at
+	`, id, newMatches).Scan(&alertID, &createdAt)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to record saved search alert: %w", err)
+	}
+	return alertID, createdAt, nil
+}
+
+// EnqueueSavedSearchAlert queues delivery of a saved search alert to a webhook subscription,
+// unless it has been deactivated. It reports whether a delivery was queued.
+func (db *DB) EnqueueSavedSearchAlert(ctx context.Context, subscriptionID int, alertID int64, eventType string, payload []byte) (bool, error) {
+	tag, err := db.Exec(ctx, `
+	INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+	SELECT id, $2, $3, $4 FROM webhook_subscriptions WHERE id = $1 AND active
+	ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`, subscriptionID, alertID, eventType, payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue saved search alert: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// maxScoreWeights bounds how many components a custom score can weigh
+const maxScoreWeights = 10
+
+// scoreComponents map the components of a custom score to expressions over companyJoins
+// rating a company from 0 to 1. Companies without the figure rate 0.
+var scoreComponents = map[string]string{
+	// Log scale, from £1 or less up to £1bn or more
+	"turnover":  "LEAST(log(GREATEST(latest_fin.turnover, 1)::numeric) / 9, 1)",
+	"net_worth": "LEAST(log(GREATEST(latest_fin.net_worth, 1)::numeric) / 9, 1)",
+	// From halving turnover or worse up to doubling it or better
+	"growth": "(LEAST(GREATEST(latest_fin.revenue_growth, -100), 100) + 100) / 200.0",
+	// From accounts made up to today down to accounts three years old or more
+	"recency": "GREATEST(1 - (CURRENT_DATE - latest_fin.period_end) / 1095.0, 0)",
+	"health":  "CASE health.band WHEN 'strong' THEN 1 WHEN 'moderate' THEN 0.5 WHEN 'weak' THEN 0 END",
+}
+
+// ScoreComponentNames returns the components a custom score can weigh, sorted
+func ScoreComponentNames() []string {
+	names := make([]string, 0, len(scoreComponents))
+	for name := range scoreComponents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scoreColumn returns the expression of a custom score: the weighted mean of its components,
+// from 0 to 1, with the weights added to qb's arguments. Unknown components are skipped.
+func (qb *QueryBuilder) scoreColumn(weights map[string]float64) string {
+	terms := make([]string, 0, len(weights))
+	for _, name := range ScoreComponentNames() {
+		weight, ok := weights[name]
+		if !ok {
+			continue
+		}
+		qb.argCount++
+		qb.args = append(qb.args, weight)
+		terms = append(terms, fmt.Sprintf("$%d::float8 * COALESCE(%s, 0)", qb.argCount, scoreComponents[name]))
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+	qb.argCount++
+	qb.args = append(qb.args, total)
+	return fmt.Sprintf("((%s) / $%d::float8)::float8", strings.Join(terms, " + "), qb.argCount)
+}
+
+// ScanSearchResult scans a row of a search built from filters, with the company's score when
+// the search is ranked by score weights
+func ScanSearchResult(row pgx.Row, filters models.CompanySearchFilters) (models.Company, error) {
+	if len(filters.ScoreWeights) == 0 {
+		return ScanCompanyFields(row, filters.Fields)
+	}
+	var score float64
+	c, err := ScanCompanyFields(row, filters.Fields, &score)
+	c.Score = &score
+	return c, err
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StagingFinancial is a staging_financials row with the figures the API reads
+type StagingFinancial struct {
+	CompanyNumber      string
+	PeriodStart        *string // YYYY-MM-DD
+	PeriodEnd          string
+	Turnover           *float64
+	GrossProfit        *float64
+	OperatingProfit    *float64
+	ProfitLoss         *float64
+	TotalAssets        *float64
+	CurrentAssets      *float64
+	FixedAssets        *float64
+	IntangibleAssets   *float64
+	CurrentLiabilities *float64
+	TotalLiabilities   *float64
+	NetCurrentAssets   *float64
+	NetAssets          *float64
+	ShareholderFunds   *float64
+	Cash               *float64
+	Employees          *int
+	Source             string
+}
+
+// seedOfficerColumns are the staging_officers columns written by SeedOfficers
+var seedOfficerColumns = []string{
+	"company_number", "officer_name", "officer_role", "appointed_on", "resigned_on",
+	"date_of_birth", "nationality", "address_line_1", "address_line_2", "locality", "postal_code", "country",
+	"data_hash",
+}
+
+// seedFinancialColumns are the staging_financials columns written by SeedFinancials
+var seedFinancialColumns = []string{
+	"company_number", "period_start", "period_end", "turnover", "gross_profit_loss", "operating_profit_loss",
+	"profit_loss", "total_assets", "current_assets", "fixed_assets", "intangible_assets", "current_liabilities",
+	"total_liabilities", "net_current_assets_liabilities", "net_assets_liabilities", "shareholder_funds", "cash_bank_on_hand",
+	"average_number_employees_during_period", "source", "batch_id",
+}
+
+// HasCompaniesOutsideBatches reports whether any staged company was last written by a batch
+// whose ID does not start with prefix
+func (db *DB) HasCompaniesOutsideBatches(ctx context.Context, prefix string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx, `
+	SELECT EXISTS (SELECT 1 FROM staging_companies WHERE batch_id IS NULL OR NOT starts_with(batch_id, $1))
+	`, prefix).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check staged companies: %w", err)
+	}
+	return exists, nil
+}
+
+// DeleteCompanyBatches deletes the companies last written by batches whose ID starts with
+// prefix, with their officers and financials, and returns how many companies there were
+func (db *DB) DeleteCompanyBatches(ctx context.Context, prefix string) (int64, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM staging_companies WHERE starts_with(batch_id, $1)", prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete %s batches: %w", prefix, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// HasPostcodesOutsideBatches reports whether postcode_lookup holds postcodes last written by a
+// batch whose ID does not start with prefix
+func (db *DB) HasPostcodesOutsideBatches(ctx context.Context, prefix string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx, `
+	SELECT EXISTS (SELECT 1 FROM postcode_lookup WHERE batch_id IS NULL OR NOT starts_with(batch_id, $1))
+	`, prefix).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check postcodes: %w", err)
+	}
+	return exists, nil
+}
+
+// SeedOfficers COPYs officers straight into staging_officers. Unlike the ingesters it does not
+// upsert, so the officers must not already be staged.
+func (db *DB) SeedOfficers(ctx context.Context, officers []StagingOfficer) (int64, error) {
+	rows := make([][]any, len(officers))
+	for i, o := range officers {
+		rows[i] = []any{
+			o.CompanyNumber, o.OfficerName, o.OfficerRole, parseDate(o.AppointedOn), parseDate(o.ResignedOn),
+			parseDate(o.DateOfBirth), o.Nationality, o.AddressLine1, o.AddressLine2, o.Locality, o.PostalCode, o.Country,
+			o.DataHash,
+		}
+	}
+	count, err := db.CopyFrom(ctx, pgx.Identifier{"staging_officers"}, seedOfficerColumns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy officers: %w", err)
+	}
+	return count, nil
+}
+
+// SeedFinancials COPYs financial periods straight into staging_financials, recording batchID.
+// Like SeedOfficers it does not upsert.
+func (db *DB) SeedFinancials(ctx context.Context, batchID string, financials []StagingFinancial) (int64, error) {
+	rows := make([][]any, len(financials))
+	for i, f := range financials {
+		rows[i] = []any{
+			f.CompanyNumber, parseDate(f.PeriodStart), parseDate(&f.PeriodEnd), f.Turnover, f.GrossProfit, f.OperatingProfit,
+			f.ProfitLoss, f.TotalAssets, f.CurrentAssets, f.FixedAssets, f.IntangibleAssets, f.CurrentLiabilities,
+			f.TotalLiabilities, f.NetCurrentAssets, f.NetAssets, f.ShareholderFunds, f.Cash,
+			f.Employees, f.Source, batchID,
+		}
+	}
+	count, err := db.CopyFrom(ctx, pgx.Identifier{"staging_financials"}, seedFinancialColumns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy financials: %w", err)
+	}
+	return count, nil
+}
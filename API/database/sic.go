@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SICCode is a SIC 2007 code as Companies House lists it
+type SICCode struct {
+	Code        string // 5 digits, e.g. "62020"
+	Description string
+}
+
+// ImportSICCodes COPYs a batch of SIC codes into a temporary table and upserts them into
+// sic_taxonomy in one transaction, placing each in the section spanning its division. It
+// returns the number of codes inserted or changed.
+func (db *DB) ImportSICCodes(ctx context.Context, batchID string, codes []SICCode) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+	CREATE TEMP TABLE import_sic_codes (
+		code CHAR(5) NOT NULL,
+		description TEXT NOT NULL
+	) ON COMMIT DROP
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	rows := make([][]any, len(codes))
+	for i, c := range codes {
+		rows[i] = []any{c.Code, c.Description}
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_sic_codes"}, []string{"code", "description"}, pgx.CopyFromRows(rows)); err != nil {
+		return 0, fmt.Errorf("failed to copy SIC codes: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+	INSERT INTO sic_taxonomy (code, description, section, batch_id)
+	SELECT DISTINCT ON (t.code) t.code, t.description, s.section, $1
+	FROM import_sic_codes t
+	LEFT JOIN sic_sections s ON left(t.code, 2)::int BETWEEN s.first_division AND s.last_division
+	ORDER BY t.code
+	ON CONFLICT (code) DO UPDATE SET
+		description = EXCLUDED.description,
+		section = EXCLUDED.section,
+		batch_id = EXCLUDED.batch_id,
+		updated_at = NOW()
+	WHERE (sic_taxonomy.description, sic_taxonomy.section) IS DISTINCT FROM (EXCLUDED.description, EXCLUDED.section)
+	`, batchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store SIC codes of %s: %w", batchID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit import batch: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,293 @@
+package database
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// streamBatchID marks staging rows last written by the stream ingester
+const streamBatchID = "stream"
+
+// StagingCompany is a staging_companies row as written by ingesters
+type StagingCompany struct {
+	CompanyNumber          string
+	CompanyName            *string
+	CompanyStatus          *string
+	CompanyType            *string
+	Locality               *string
+	PostalCode             *string
+	AddressLine1           *string
+	AddressLine2           *string
+	Region                 *string
+	Country                *string
+	SICCodes               []string
+	IncorporationDate      *string // YYYY-MM-DD
+	DissolvedOn            *string
+	AccountsLastMadeUpDate *string
+	AccountsRefDate        *string // MM-DD
+	AccountsNextDueDate    *string
+	AccountCategory        *string
+	ReturnsNextDueDate     *string
+	ReturnsLastMadeUpDate  *string
+	NumMortCharges         *int
+	NumMortOutstanding     *int
+	NumMortPartSatisfied   *int
+	PreviousNames          *string // Pipe-separated
+	PreviousNameHistory    []StagingPreviousName
+	ConfStmtNextDueDate    *string
+	ConfStmtLastMadeUpDate *string
+	RawData                []byte
+	DataHash               string
+}
+
+// Hash computes the change detection hash over the same fields, in the same format, as the
+// Python bulk loader, so either ingestion path recognises rows the other wrote as unchanged
+func (c StagingCompany) Hash() string {
+	return hashFields(
+		c.CompanyNumber, c.CompanyName, c.CompanyStatus, c.CompanyType,
+		c.Locality, c.PostalCode, c.AddressLine1, c.AddressLine2, c.Region, c.Country,
+		pythonList(c.SICCodes), c.IncorporationDate, c.AccountsNextDueDate, c.NumMortCharges, c.PreviousNames,
+	)
+}
+
+// Company returns the company detail the row would be served as, before the derived data
+// (financials, officer counts, coordinates, health and risk) is computed for it
+func (c StagingCompany) Company() models.Company {
+	company := models.Company{
+		CompanyNumber:      c.CompanyNumber,
+		CompanyType:        nullString(c.CompanyType),
+		Locality:           nullString(c.Locality),
+		Region:             nullString(c.Region),
+		PostalCode:         nullString(c.PostalCode),
+		IncorporationDate:  parseDate(c.IncorporationDate),
+		DissolvedOn:        parseDate(c.DissolvedOn),
+		AccountsCategory:   nullString(c.AccountCategory),
+		NextAccountsDue:    parseDate(c.AccountsNextDueDate),
+		ConfStmtLastMadeUp: parseDate(c.ConfStmtLastMadeUpDate),
+		ConfStmtNextDue:    parseDate(c.ConfStmtNextDueDate),
+		// Only by its category, as in dormantCondition for a company without accounts
+		Dormant: c.AccountCategory != nil && strings.EqualFold(strings.TrimSpace(*c.AccountCategory), "dormant"),
+	}
+	if c.CompanyName != nil {
+		company.CompanyName = *c.CompanyName
+	}
+	if c.CompanyStatus != nil {
+		company.CompanyStatus = *c.CompanyStatus
+	}
+	if len(c.SICCodes) > 0 {
+		company.PrimarySICCode = sql.NullString{String: c.SICCodes[0], Valid: true}
+	}
+	return company
+}
+
+// nullString converts an optional staging value to a nullable result field
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+// StagingOfficer is a staging_officers row as written by ingesters
+type StagingOfficer struct {
+	CompanyNumber   string
+	OfficerName     *string
+	OfficerRole     *string
+	AppointedOn     *string // YYYY-MM-DD
+	ResignedOn      *string
+	DateOfBirth     *string
+	Nationality     *string
+	NatureOfControl *string // Pipe-separated
+	AddressLine1    *string
+	AddressLine2    *string
+	Locality        *string
+	PostalCode      *string
+	Country         *string
+	RawData         []byte
+	DataHash        string
+}
+
+// Hash computes the change detection hash the same way as the Python PSC loader
+func (o StagingOfficer) Hash() string {
+	return hashFields(
+		o.CompanyNumber, o.OfficerName, o.OfficerRole, o.DateOfBirth, o.Nationality, o.NatureOfControl,
+		o.ResignedOn, o.Locality, o.PostalCode, o.AddressLine1, o.AddressLine2, o.Country,
+	)
+}
+
+// hashFields computes the MD5 of the field values joined with "|", with missing values as
+// empty strings
+func hashFields(values ...any) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case string:
+			parts[i] = v
+		case *string:
+			if v != nil {
+				parts[i] = *v
+			}
+		case *int:
+			if v != nil {
+				parts[i] = strconv.Itoa(*v)
+			}
+		}
+	}
+	sum := md5.Sum([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// pythonList formats values like Python's str(list), matching how the bulk loader hashes SIC codes
+func pythonList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// UpsertStreamCompany inserts or updates a company from the streaming API. Columns the stream
+// does not carry (mortgages, returns) keep their bulk-loaded values, as does the account
+// category when a profile has no last accounts type.
+// It returns false if the stored row was already identical.
+func (db *DB) UpsertStreamCompany(ctx context.Context, c StagingCompany) (bool, error) {
+	tag, err := db.Exec(ctx, `
+	INSERT INTO staging_companies (
+		company_number, company_name, company_status, company_type,
+		locality, postal_code, address_line_1, address_line_2, region, country,
+		sic_codes, incorporation_date, accounts_last_made_up_date, accounts_ref_date,
+		accounts_next_due_date, account_category, previous_names, conf_stm_next_due_date, conf_stm_last_made_up_date,
+		dissolved_on, previous_name_history, raw_data, data_hash, change_detected, last_updated, batch_id
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, FALSE, NOW(), $24)
+	ON CONFLICT (company_number) DO UPDATE SET
+		company_name = EXCLUDED.company_name,
+		company_status = EXCLUDED.company_status,
+		company_type = EXCLUDED.company_type,
+		locality = EXCLUDED.locality,
+		postal_code = EXCLUDED.postal_code,
+		address_line_1 = EXCLUDED.address_line_1,
+		address_line_2 = EXCLUDED.address_line_2,
+		region = EXCLUDED.region,
+		country = EXCLUDED.country,
+		sic_codes = EXCLUDED.sic_codes,
+		incorporation_date = EXCLUDED.incorporation_date,
+		accounts_last_made_up_date = EXCLUDED.accounts_last_made_up_date,
+		accounts_ref_date = EXCLUDED.accounts_ref_date,
+		accounts_next_due_date = EXCLUDED.accounts_next_due_date,
+		account_category = COALESCE(EXCLUDED.account_category, staging_companies.account_category),
+		previous_names = EXCLUDED.previous_names,
+		conf_stm_next_due_date = EXCLUDED.conf_stm_next_due_date,
+		conf_stm_last_made_up_date = EXCLUDED.conf_stm_last_made_up_date,
+		dissolved_on = EXCLUDED.dissolved_on,
+		previous_name_history = EXCLUDED.previous_name_history,
+		raw_data = EXCLUDED.raw_data,
+		data_hash = EXCLUDED.data_hash,
+		last_updated = EXCLUDED.last_updated,
+		batch_id = EXCLUDED.batch_id,
+		merged_at = NULL,
+		change_detected = TRUE
+	WHERE staging_companies.data_hash IS DISTINCT FROM EXCLUDED.data_hash
+	`,
+		c.CompanyNumber, c.CompanyName, c.CompanyStatus, c.CompanyType,
+		c.Locality, c.PostalCode, c.AddressLine1, c.AddressLine2, c.Region, c.Country,
+		c.SICCodes, c.IncorporationDate, c.AccountsLastMadeUpDate, c.AccountsRefDate,
+		c.AccountsNextDueDate, c.AccountCategory, c.PreviousNames, c.ConfStmtNextDueDate, c.ConfStmtLastMadeUpDate,
+		c.DissolvedOn, previousNameHistory(c.PreviousNameHistory), c.RawData, c.DataHash, streamBatchID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert company %s: %w", c.CompanyNumber, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UpsertStreamOfficer inserts or updates an officer from the streaming API. Officers of
+// companies that are not in staging yet are skipped. It returns false if nothing was written.
+func (db *DB) UpsertStreamOfficer(ctx context.Context, o StagingOfficer) (bool, error) {
+	tag, err := db.Exec(ctx, `
+	INSERT INTO staging_officers (
+		company_number, officer_name, officer_role, appointed_on, resigned_on,
+		date_of_birth, nationality, nature_of_control,
+		address_line_1, address_line_2, locality, postal_code, country,
+		raw_data, data_hash, change_detected, last_updated
+	)
+	SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, FALSE, NOW()
+	WHERE EXISTS (SELECT 1 FROM staging_companies WHERE company_number = $1)
+	ON CONFLICT (company_number, officer_name, appointed_on, officer_role, date_of_birth) DO UPDATE SET
+		resigned_on = EXCLUDED.resigned_on,
+		nationality = EXCLUDED.nationality,
+		nature_of_control = EXCLUDED.nature_of_control,
+		address_line_1 = EXCLUDED.address_line_1,
+		address_line_2 = EXCLUDED.address_line_2,
+		locality = EXCLUDED.locality,
+		postal_code = EXCLUDED.postal_code,
+		country = EXCLUDED.country,
+		raw_data = EXCLUDED.raw_data,
+		data_hash = EXCLUDED.data_hash,
+		last_updated = EXCLUDED.last_updated,
+		change_detected = TRUE
+	WHERE staging_officers.data_hash IS DISTINCT FROM EXCLUDED.data_hash
+	`,
+		o.CompanyNumber, o.OfficerName, o.OfficerRole, o.AppointedOn, o.ResignedOn,
+		o.DateOfBirth, o.Nationality, o.NatureOfControl,
+		o.AddressLine1, o.AddressLine2, o.Locality, o.PostalCode, o.Country,
+		o.RawData, o.DataHash,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert officer for %s: %w", o.CompanyNumber, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteStreamOfficer removes an officer record deleted upstream, matched on the same
+// identity columns the upsert uses
+func (db *DB) DeleteStreamOfficer(ctx context.Context, o StagingOfficer) (bool, error) {
+	tag, err := db.Exec(ctx, `
+	DELETE FROM staging_officers
+	WHERE company_number = $1
+		AND officer_name IS NOT DISTINCT FROM $2
+		AND officer_role IS NOT DISTINCT FROM $3
+		AND appointed_on IS NOT DISTINCT FROM $4::date
+		AND date_of_birth IS NOT DISTINCT FROM $5::date
+	`, o.CompanyNumber, o.OfficerName, o.OfficerRole, o.AppointedOn, o.DateOfBirth)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete officer for %s: %w", o.CompanyNumber, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetStreamTimepoint returns the last timepoint processed for a stream, or nil if it has never run
+func (db *DB) GetStreamTimepoint(ctx context.Context, stream string) (*int64, error) {
+	var timepoint int64
+	err := db.QueryRow(ctx, "SELECT timepoint FROM stream_offsets WHERE stream = $1", stream).Scan(&timepoint)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream timepoint: %w", err)
+	}
+	return &timepoint, nil
+}
+
+// SetStreamTimepoint records the last timepoint processed for a stream
+func (db *DB) SetStreamTimepoint(ctx context.Context, stream string, timepoint int64) error {
+	_, err := db.Exec(ctx, `
+	INSERT INTO stream_offsets (stream, timepoint, updated_at)
+	VALUES ($1, $2, NOW())
+	ON CONFLICT (stream) DO UPDATE SET timepoint = EXCLUDED.timepoint, updated_at = EXCLUDED.updated_at
+	`, stream, timepoint)
+	if err != nil {
+		return fmt.Errorf("failed to set stream timepoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// DataStats reports the size of each staging table and its indexes, when each table last had
+// rows ingested, the latest ingestion batch and how many companies have financials
+func (db *DB) DataStats(ctx context.Context) (models.DataStatsResponse, error) {
+	stats := models.DataStatsResponse{
+		Tables:  make([]models.TableStats, 0),
+		Indexes: make([]models.IndexStats, 0),
+	}
+
+	rows, err := db.Query(ctx, `
+	SELECT t.relname, t.n_live_tup, pg_total_relation_size(t.relid),
+		EXISTS (
+			SELECT 1 FROM information_schema.columns col
+			WHERE col.table_schema = t.schemaname AND col.table_name = t.relname AND col.column_name = 'ingested_at'
+		)
+	FROM pg_stat_user_tables t
+	WHERE t.schemaname = current_schema() AND t.relname LIKE 'staging\_%'
+	ORDER BY t.relname
+	`)
+	if err != nil {
+		return stats, fmt.Errorf("failed to list staging tables: %w", err)
+	}
+	var ingested []int
+	for rows.Next() {
+		var table models.TableStats
+		var hasIngestedAt bool
+		if err := rows.Scan(&table.Name, &table.RowCount, &table.TotalBytes, &hasIngestedAt); err != nil {
+			rows.Close()
+			return stats, fmt.Errorf("failed to scan staging table: %w", err)
+		}
+		if hasIngestedAt {
+			ingested = append(ingested, len(stats.Tables))
+		}
+		stats.Tables = append(stats.Tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("failed to list staging tables: %w", err)
+	}
+
+	for _, i := range ingested {
+		table := &stats.Tables[i]
+		err := db.QueryRow(ctx, "SELECT MAX(ingested_at) FROM "+pgx.Identifier{table.Name}.Sanitize()).Scan(&table.LatestIngestedAt)
+		if err != nil {
+			return stats, fmt.Errorf("failed to get latest ingestion of %s: %w", table.Name, err)
+		}
+	}
+
+	rows, err = db.Query(ctx, `
+	SELECT relname, indexrelname, pg_relation_size(indexrelid)
+	FROM pg_stat_user_indexes
+	WHERE schemaname = current_schema() AND relname LIKE 'staging\_%'
+	ORDER BY relname, indexrelname
+	`)
+	if err != nil {
+		return stats, fmt.Errorf("failed to list staging indexes: %w", err)
+	}
+	for rows.Next() {
+		var index models.IndexStats
+		if err := rows.Scan(&index.Table, &index.Name, &index.Bytes); err != nil {
+			rows.Close()
+			return stats, fmt.Errorf("failed to scan staging index: %w", err)
+		}
+		stats.Indexes = append(stats.Indexes, index)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("failed to list staging indexes: %w", err)
+	}
+
+	var batch models.IngestionBatch
+	var startedAt *time.Time
+	err = db.QueryRow(ctx, `
+	SELECT batch_id, search_name, COALESCE(status, ''), COALESCE(companies_count, 0), started_at, completed_at
+	FROM staging_ingestion_log
+	ORDER BY started_at DESC NULLS LAST, id DESC
+	LIMIT 1
+	`).Scan(&batch.BatchID, &batch.SearchName, &batch.Status, &batch.CompaniesCount, &startedAt, &batch.CompletedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return stats, fmt.Errorf("failed to get latest ingestion batch: %w", err)
+	default:
+		if startedAt != nil {
+			batch.StartedAt = *startedAt
+		}
+		stats.LatestBatch = &batch
+	}
+
+	coverage := &stats.FinancialsCoverage
+	err = db.QueryRow(ctx, `
+	SELECT COUNT(*),
+		COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM staging_financials f WHERE f.company_number = c.company_number))
+	FROM staging_companies c
+	`).Scan(&coverage.Companies, &coverage.WithFinancials)
+	if err != nil {
+		return stats, fmt.Errorf("failed to get financials coverage: %w", err)
+	}
+	if coverage.Companies > 0 {
+		coverage.Percent = math.Round(float64(coverage.WithFinancials)/float64(coverage.Companies)*10000) / 100
+	}
+
+	return stats, nil
+}
+
+// DataFreshness returns the latest accounts period end and when the last ingestion batch
+// completed
+func (db *DB) DataFreshness(ctx context.Context) (models.DataFreshness, error) {
+	var f models.DataFreshness
+	err := db.QueryRow(ctx, `
+	SELECT
+		(SELECT MAX(period_end) FROM staging_financials),
+		(SELECT MAX(completed_at) FROM staging_ingestion_log WHERE status = 'completed')
+	`).Scan(&f.LatestPeriodEnd, &f.LastImportAt)
+	if err != nil {
+		return f, fmt.Errorf("failed to get data freshness: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// searchSummaryViews lists the materialized views backing search filters
+var searchSummaryViews = []string{
+	"staging_latest_financials",
+	"staging_officer_counts",
+}
+
+// RefreshSearchSummaries refreshes the materialized views used by search queries, first
+// converting financial periods ingested since the last refresh (see ConvertFinancials).
+// CONCURRENTLY keeps the views readable while they are rebuilt. The refresh runs without
+// the session statement timeout, which is sized for interactive searches.
+func (db *DB) RefreshSearchSummaries(ctx context.Context) error {
+	if _, err := db.ConvertFinancials(ctx); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin refresh: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return fmt.Errorf("failed to lift statement timeout: %w", err)
+	}
+
+	for _, view := range searchSummaryViews {
+		if _, err := tx.Exec(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+view); err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", view, err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
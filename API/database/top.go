@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"data-co/api/models"
+)
+
+// MaxTopGroups bounds how many groups a league table lists
+const MaxTopGroups = 100
+
+// topMetrics maps league table metrics to their columns
+var topMetrics = map[string]string{
+	"turnover":  "latest_fin.turnover",
+	"net_worth": "latest_fin.net_worth",
+	"growth":    "latest_fin.revenue_growth",
+}
+
+// TopMetricNames are the metrics a league table can rank by
+var TopMetricNames = []string{"turnover", "net_worth", "growth"}
+
+// TopGroupings are the groupings a league table can rank within
+var TopGroupings = []string{"industry", "region"}
+
+// TopCompanies ranks the companies matching filters by metric within each industry (of their
+// primary SIC code) or region, and returns the top n of the MaxTopGroups groups that rank the
+// most companies. Companies without a value for the metric are not ranked. It reports whether
+// there were more groups.
+func (db *DB) TopCompanies(ctx context.Context, groupBy, metric string, n int, filters models.CompanySearchFilters) ([]models.TopGroup, bool, error) {
+	column, ok := topMetrics[metric]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown metric %q", metric)
+	}
+
+	qb := NewQueryBuilder()
+	applyExpression(qb, filters)
+
+	if !slices.Contains(TopGroupings, groupBy) {
+		return nil, false, fmt.Errorf("unknown grouping %q", groupBy)
+	}
+	group, groupJoin, err := groupDimension(qb, groupBy)
+	if err != nil {
+		return nil, false, err
+	}
+	qb.conditions = append(qb.conditions, column+" IS NOT NULL", group+" IS NOT NULL")
+	qb.argCount++
+	qb.args = append(qb.args, n)
+	limitArg := qb.argCount
+
+	rows, err := db.Read().Query(ctx, fmt.Sprintf(`
+	SELECT group_name, ranked, rank, company_number, company_name, company_status, locality, value, more_groups
+	FROM (
+		SELECT ranked_companies.*,
+			DENSE_RANK() OVER (ORDER BY ranked DESC, group_name) AS group_rank,
+			COUNT(DISTINCT group_name) OVER () > %[1]d AS more_groups
+		FROM (
+			SELECT %[2]s AS group_name, c.company_number, c.company_name, c.company_status, c.locality,
+				%[3]s::float8 AS value,
+				ROW_NUMBER() OVER (PARTITION BY %[2]s ORDER BY %[3]s DESC, c.company_number) AS rank,
+				COUNT(*) OVER (PARTITION BY %[2]s) AS ranked
+			%[4]s%[5]s%[6]s
+		) ranked_companies
+		WHERE rank <= $%[7]d
+	) top
+	WHERE group_rank <= %[1]d
+	ORDER BY group_rank, rank
+	`, MaxTopGroups, group, column, companyJoins, groupJoin, qb.where(), limitArg), qb.GetArgs()...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to rank companies: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make([]models.TopGroup, 0)
+	more := false
+	for rows.Next() {
+		var name string
+		var ranked int
+		var c models.TopCompany
+		if err := rows.Scan(&name, &ranked, &c.Rank, &c.CompanyNumber, &c.CompanyName, &c.CompanyStatus, &c.Locality, &c.Value, &more); err != nil {
+			return nil, false, fmt.Errorf("failed to scan ranked company: %w", err)
+		}
+		if len(groups) == 0 || groups[len(groups)-1].Name != name {
+			groups = append(groups, models.TopGroup{Name: name, Ranked: ranked, Companies: make([]models.TopCompany, 0, n)})
+		}
+		group := &groups[len(groups)-1]
+		group.Companies = append(group.Companies, c)
+	}
+	return groups, more, rows.Err()
+}
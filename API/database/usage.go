@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// usagePeriod returns the first day of the month containing t, which keys api_usage rows
+func usagePeriod(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// GetCurrentUsage returns an API key's usage for the current month
+func (db *DB) GetCurrentUsage(ctx context.Context, keyID int) (models.UsagePeriod, error) {
+	period := usagePeriod(time.Now())
+	usage := models.UsagePeriod{Period: period.Format("2006-01")}
+
+	err := db.QueryRow(ctx,
+		"SELECT request_count, row_count FROM api_usage WHERE api_key_id = $1 AND period = $2",
+		keyID, period,
+	).Scan(&usage.RequestCount, &usage.RowCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return usage, nil
+	}
+	if err != nil {
+		return usage, fmt.Errorf("failed to read usage: %w", err)
+	}
+	return usage, nil
+}
+
+// RecordUsage adds one request and the given number of result rows to the current month
+func (db *DB) RecordUsage(ctx context.Context, keyID int, rows int64) error {
+	query := `
+	INSERT INTO api_usage (api_key_id, period, request_count, row_count)
+	VALUES ($1, $2, 1, $3)
+	ON CONFLICT (api_key_id, period) DO UPDATE SET
+		request_count = api_usage.request_count + 1,
+		row_count = api_usage.row_count + EXCLUDED.row_count,
+		updated_at = NOW()
+	`
+	if _, err := db.Exec(ctx, query, keyID, usagePeriod(time.Now()), rows); err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+// ListUsage returns an API key's usage for the most recent months, newest first
+func (db *DB) ListUsage(ctx context.Context, keyID int, months int) ([]models.UsagePeriod, error) {
+	rows, err := db.Query(ctx, `
+	SELECT to_char(period, 'YYYY-MM'), request_count, row_count
+	FROM api_usage
+	WHERE api_key_id = $1
+	ORDER BY period DESC
+	LIMIT $2
+	`, keyID, months)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]models.UsagePeriod, 0)
+	for rows.Next() {
+		var usage models.UsagePeriod
+		if err := rows.Scan(&usage.Period, &usage.RequestCount, &usage.RowCount); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		history = append(history, usage)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,344 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"data-co/api/models"
+	"data-co/api/scoring"
+)
+
+var (
+	// sicCodePattern matches the SIC codes the industry filter matches directly
+	sicCodePattern = regexp.MustCompile(`^[0-9]{5}$`)
+	// postcodeAreaPattern and postcodeDistrictPattern match the upper-cased, unspaced values
+	// of the postcode filters
+	postcodeAreaPattern     = regexp.MustCompile(`^[A-Z]{1,2}$`)
+	postcodeDistrictPattern = regexp.MustCompile(`^[A-Z]{1,2}[0-9][0-9A-Z]?$`)
+	// postcodePattern matches a full upper-cased, unspaced UK postcode
+	postcodePattern = regexp.MustCompile(`^[A-Z]{1,2}[0-9][0-9A-Z]?[0-9][A-Z]{2}$`)
+	// sicSectionPattern, sicDivisionPattern and sicClassPattern match the values of the SIC
+	// hierarchy filters, as split by sicParts
+	sicSectionPattern  = regexp.MustCompile(`^[A-U]$`)
+	sicDivisionPattern = regexp.MustCompile(`^[0-9]{2}$`)
+	sicClassPattern    = regexp.MustCompile(`^[0-9]{4,5}$`)
+)
+
+// filterValidator collects the invalid filters of a search, counting where clauses and group
+// entries across the whole expression
+type filterValidator struct {
+	invalid []models.InvalidFilter
+	clauses int
+	groups  int
+}
+
+// ValidateFilters checks every filter of a search, including the filters inside and/or groups,
+// and returns the invalid ones: values outside the set a filter accepts, malformed dates,
+// numbers and postcodes, text with leading or trailing spaces, where clauses that do not
+// compile, and expressions beyond maxFilterDepth, maxFilterGroups or maxWhereClauses. Unset
+// filters are valid.
+func ValidateFilters(filters models.CompanySearchFilters) []models.InvalidFilter {
+	v := &filterValidator{}
+	v.check("", filters, 1)
+	return v.invalid
+}
+
+// InvalidFiltersError joins the reasons of invalid filters into one error, separated by
+// semicolons, or returns nil when there are none
+func InvalidFiltersError(invalid []models.InvalidFilter) error {
+	if len(invalid) == 0 {
+		return nil
+	}
+	reasons := make([]string, len(invalid))
+	for i, f := range invalid {
+		reasons[i] = f.Field + ": " + f.Reason
+		if len(f.Accepted) > 0 {
+			reasons[i] += " (accepted: " + strings.Join(f.Accepted, ", ") + ")"
+		}
+	}
+	return errors.New(strings.Join(reasons, "; "))
+}
+
+// reject records an invalid filter
+func (v *filterValidator) reject(field string, value any, reason string, accepted ...string) {
+	v.invalid = append(v.invalid, models.InvalidFilter{Field: field, Value: value, Reason: reason, Accepted: accepted})
+}
+
+// check validates an expression node, with path the prefix of its fields
+func (v *filterValidator) check(path string, f models.CompanySearchFilters, depth int) {
+	v.oneOf(path+"revenue", f.Revenue, bucketNames(revenueBuckets))
+	v.oneOf(path+"revenue_growth", f.RevenueGrowth, bucketNames(revenueGrowthBuckets, "declining"))
+	v.oneOf(path+"employees", f.Employees, bucketNames(employeesBuckets))
+	v.oneOf(path+"employee_count", f.EmployeeCount, bucketNames(employeesBuckets))
+	v.oneOf(path+"officer_count", f.OfficerCount, bucketNames(employeesBuckets))
+	v.oneOf(path+"profitability", f.Profitability, profitabilityValues)
+	v.oneOf(path+"companySize", f.CompanySize, bucketNames(companySizeBuckets))
+	v.oneOf(path+"companyAge", f.CompanyAge, bucketNames(companyAgeBuckets))
+	v.oneOf(path+"netAssets", f.NetAssets, bucketNames(netAssetsBuckets, "negative"))
+	v.oneOf(path+"debtLevel", f.DebtLevel, bucketNames(debtLevelBuckets))
+	v.oneOf(path+"health", f.Health, []string{scoring.HealthStrong, scoring.HealthModerate, scoring.HealthWeak})
+	v.oneOf(path+"risk_band", f.RiskBand, []string{scoring.RiskLow, scoring.RiskMedium, scoring.RiskHigh})
+	v.oneOf(path+"psc_type", f.PSCType, append(slices.Clone(pscTypes), "all"))
+	v.text(path+"location", f.Location)
+	v.text(path+"companyStatus", f.CompanyStatus)
+
+	if v.text(path+"industry", f.Industry) && f.Industry != "" {
+		if _, ok := industrySICPrefixes(f.Industry); !ok && !sicCodePattern.MatchString(f.Industry) {
+			v.reject(path+"industry", f.Industry, "not an industry or a 5-digit SIC code", industryNames()...)
+		}
+	}
+
+	v.list(path+"sic_section", f.SICSection, sicParts(f.SICSection), func(section string) bool {
+		return sicSectionPattern.MatchString(section)
+	}, "is not a SIC section, A to U")
+	v.list(path+"sic_division", f.SICDivision, sicParts(f.SICDivision), func(division string) bool {
+		return sicDivisionPattern.MatchString(division)
+	}, "is not a 2-digit SIC division, e.g. 62")
+	v.list(path+"sic_class", f.SICClass, sicParts(f.SICClass), func(class string) bool {
+		return sicClassPattern.MatchString(class)
+	}, "is not a 4-digit SIC class or 5-digit SIC code, e.g. 6202 or 62020")
+
+	v.list(path+"country", f.Country, listParts(f.Country), func(country string) bool {
+		return slices.Contains(countries, country)
+	}, "is not a UK country", countries...)
+	v.list(path+"uk_region", f.UKRegion, listParts(f.UKRegion), func(region string) bool {
+		return slices.Contains(ukRegions, region)
+	}, "is not a UK region", ukRegions...)
+	v.list(path+"locality", f.Locality, listParts(f.Locality), func(string) bool { return true }, "")
+
+	v.list(path+"postcode_area", f.PostcodeArea, postcodeParts(f.PostcodeArea), func(area string) bool {
+		return postcodeAreaPattern.MatchString(area)
+	}, "is not a postcode area, e.g. EC or M")
+	v.list(path+"postcode_district", f.PostcodeDistrict, postcodeParts(f.PostcodeDistrict), func(district string) bool {
+		return postcodeDistrictPattern.MatchString(district)
+	}, "is not a postcode district, e.g. EC1V or M1")
+
+	if f.AddressContains != "" && len(strings.Join(strings.Fields(f.AddressContains), " ")) < minAddressContainsLength {
+		v.reject(path+"address_contains", f.AddressContains, fmt.Sprintf("must be at least %d characters", minAddressContainsLength))
+	}
+
+	v.list(path+"company_type", f.CompanyType, listParts(f.CompanyType), func(t string) bool {
+		return slices.Contains(companyTypes, t)
+	}, "is not a company type", companyTypes...)
+	categories, _ := strings.CutPrefix(strings.TrimSpace(f.AccountsCategory), "!")
+	v.list(path+"accounts_category", f.AccountsCategory, listParts(categories), func(c string) bool {
+		return slices.Contains(accountsCategories, c)
+	}, "is not an accounts category", accountsCategories...)
+
+	if years := f.ProfitableYears; years != nil && (*years < 1 || *years > maxProfitableYears) {
+		v.reject(path+"profitable_years", *years, fmt.Sprintf("must be between 1 and %d", maxProfitableYears))
+	}
+	if cash := f.CashMin; cash != nil && *cash < 0 {
+		v.reject(path+"cash_min", *cash, "must not be negative")
+	}
+	if ratio := f.CurrentRatioMin; ratio != nil && *ratio < 0 {
+		v.reject(path+"current_ratio_min", *ratio, "must not be negative")
+	}
+	v.numberRange(path, "fixed_assets", f.FixedAssetsMin, f.FixedAssetsMax)
+	v.numberRange(path, "intangible_assets", f.IntangibleAssetsMin, f.IntangibleAssetsMax)
+	v.numberRange(path, "shareholder_funds", f.ShareholderFundsMin, f.ShareholderFundsMax)
+	if days := f.AccountsDueWithinDays; days != nil && (*days < 0 || *days > maxAccountsDueWithinDays) {
+		v.reject(path+"accounts_due_within_days", *days, fmt.Sprintf("must be between 0 and %d", maxAccountsDueWithinDays))
+	}
+
+	from, fromOK := v.date(path+"dissolved_from", f.DissolvedFrom)
+	to, toOK := v.date(path+"dissolved_to", f.DissolvedTo)
+	if fromOK && toOK && to.Before(from) {
+		v.reject(path+"dissolved_to", f.DissolvedTo, "is before dissolved_from")
+	}
+
+	v.near(path+"near", f.Near)
+	if path == "" {
+		// Only read at the top level
+		names := CompanyFieldNames()
+		for _, field := range f.Fields {
+			if !slices.Contains(names, field) {
+				v.reject("fields", field, "not a result field", names...)
+			}
+		}
+		if f.Sample < 0 || f.Sample > maxSample {
+			v.reject("sample", f.Sample, fmt.Sprintf("must be between 1 and %d", maxSample))
+		} else if f.Sample > 0 && f.Offset > 0 {
+			v.reject("offset", f.Offset, "cannot be combined with sample")
+		}
+		if f.SampleSeed != nil && f.Sample == 0 {
+			v.reject("sample_seed", *f.SampleSeed, "requires sample")
+		}
+		v.scoreWeights(f.ScoreWeights)
+		if f.ScoreWeights != nil && f.Sample > 0 {
+			v.reject("score_weights", nil, "cannot be combined with sample")
+		}
+	}
+	v.where(path+"where", f.Where)
+
+	if len(f.And) == 0 && len(f.Or) == 0 {
+		return
+	}
+	if depth > maxFilterDepth {
+		v.reject(strings.TrimSuffix(path, "."), nil, fmt.Sprintf("and/or groups can be nested at most %d deep", maxFilterDepth))
+		return
+	}
+	for _, group := range []struct {
+		name    string
+		entries []models.CompanySearchFilters
+	}{{"and", f.And}, {"or", f.Or}} {
+		for i, sub := range group.entries {
+			if v.groups++; v.groups > maxFilterGroups {
+				if v.groups == maxFilterGroups+1 {
+					v.reject(path+group.name, nil, fmt.Sprintf("and/or groups can have at most %d entries in total", maxFilterGroups))
+				}
+				return
+			}
+			entry := fmt.Sprintf("%s%s[%d]", path, group.name, i)
+			// An entry whose filters are all invalid also collects nothing, so it is only
+			// reported as empty when none of its filters were rejected
+			invalid := len(v.invalid)
+			v.check(entry+".", sub, depth+1)
+			if len(v.invalid) == invalid && len(NewQueryBuilder().collect(sub)) == 0 {
+				v.reject(entry, nil, "has no filters")
+			}
+		}
+	}
+}
+
+// oneOf rejects a value that is set and not one of accepted
+func (v *filterValidator) oneOf(field, value string, accepted []string) {
+	if value != "" && !slices.Contains(accepted, value) {
+		v.reject(field, value, "not an accepted value", accepted...)
+	}
+}
+
+// text rejects free text that is blank or has leading or trailing spaces, and reports whether
+// the value was valid
+func (v *filterValidator) text(field, value string) bool {
+	if value != strings.TrimSpace(value) {
+		v.reject(field, value, "has leading or trailing spaces")
+		return false
+	}
+	return true
+}
+
+// list rejects a comma-separated list with no values, or with values for which valid is false
+func (v *filterValidator) list(field, value string, parts []string, valid func(string) bool, reason string, accepted ...string) {
+	if value == "" {
+		return
+	}
+	if len(parts) == 0 {
+		v.reject(field, value, "lists no values", accepted...)
+		return
+	}
+	for _, part := range parts {
+		if !valid(part) {
+			v.reject(field, value, fmt.Sprintf("%q %s", part, reason), accepted...)
+		}
+	}
+}
+
+// date parses a YYYY-MM-DD date filter, rejecting malformed dates. ok is false for unset and
+// malformed dates.
+func (v *filterValidator) date(field, value string) (date time.Time, ok bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		v.reject(field, value, "must be a YYYY-MM-DD date")
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// numberRange checks the field_min and field_max of a range filter are in order
+func (v *filterValidator) numberRange(path, name string, low, high *float64) {
+	if low != nil && high != nil && *high < *low {
+		v.reject(path+name+"_max", *high, "is below "+name+"_min")
+	}
+}
+
+// near checks a radius filter has a valid point or postcode and radius
+func (v *filterValidator) near(field string, near *models.NearFilter) {
+	if near == nil {
+		return
+	}
+
+	switch {
+	case (near.Latitude == nil) != (near.Longitude == nil):
+		v.reject(field, near, "lat and lng must be given together")
+	case near.Latitude != nil:
+		if *near.Latitude < -90 || *near.Latitude > 90 {
+			v.reject(field+".lat", *near.Latitude, "must be between -90 and 90")
+		}
+		if *near.Longitude < -180 || *near.Longitude > 180 {
+			v.reject(field+".lng", *near.Longitude, "must be between -180 and 180")
+		}
+	case strings.TrimSpace(near.Postcode) == "":
+		v.reject(field, near, "needs a postcode, or lat and lng")
+	}
+	if near.Postcode != "" && !postcodePattern.MatchString(strings.ToUpper(strings.Join(strings.Fields(near.Postcode), ""))) {
+		v.reject(field+".postcode", near.Postcode, "is not a UK postcode")
+	}
+
+	if near.RadiusKm < 0 || near.RadiusKm > maxNearRadiusKm {
+		v.reject(field+".radius_km", near.RadiusKm, fmt.Sprintf("must be between 0 and %d (0 for the default of %d)", maxNearRadiusKm, defaultNearRadiusKm))
+	}
+}
+
+// where checks each where clause compiles, and that the search has at most maxWhereClauses
+func (v *filterValidator) where(field string, clauses []models.WhereClause) {
+	for i, clause := range clauses {
+		if v.clauses++; v.clauses > maxWhereClauses {
+			if v.clauses == maxWhereClauses+1 {
+				v.reject(field, nil, fmt.Sprintf("a search can have at most %d where clauses in total", maxWhereClauses))
+			}
+			return
+		}
+		if _, _, err := compileWhere(clause); err != nil {
+			v.reject(fmt.Sprintf("%s[%d]", field, i), clause, err.Error())
+		}
+	}
+}
+
+// scoreWeights checks the weights of a custom score: known components, none negative, and not
+// all zero
+func (v *filterValidator) scoreWeights(weights map[string]float64) {
+	if weights == nil {
+		return
+	}
+	if len(weights) > maxScoreWeights {
+		v.reject("score_weights", len(weights), fmt.Sprintf("at most %d components", maxScoreWeights))
+		return
+	}
+	var total float64
+	valid := true
+	for _, name := range sortedKeys(weights) {
+		weight := weights[name]
+		switch {
+		case scoreComponents[name] == "":
+			v.reject("score_weights."+name, weight, "not a score component", ScoreComponentNames()...)
+			valid = false
+		case weight < 0:
+			v.reject("score_weights."+name, weight, "must not be negative")
+			valid = false
+		default:
+			total += weight
+		}
+	}
+	if valid && total == 0 {
+		v.reject("score_weights", nil, "at least one weight must be above 0")
+	}
+}
+
+// sortedKeys returns the keys of m in order, so validation reports them deterministically
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
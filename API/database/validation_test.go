@@ -0,0 +1,84 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"data-co/api/models"
+)
+
+// invalidFields returns the fields of the invalid filters of filters, joined with commas
+func invalidFields(filters models.CompanySearchFilters) string {
+	var fields []string
+	for _, f := range ValidateFilters(filters) {
+		fields = append(fields, f.Field)
+	}
+	return strings.Join(fields, ",")
+}
+
+// nested returns filters with and groups nested depth deep, the innermost holding a search term
+func nested(depth int) models.CompanySearchFilters {
+	f := models.CompanySearchFilters{SearchTerm: "a"}
+	for i := 0; i < depth; i++ {
+		f = models.CompanySearchFilters{And: []models.CompanySearchFilters{f}}
+	}
+	return f
+}
+
+func TestValidateFilters(t *testing.T) {
+	revenue := bucketNames(revenueBuckets)[0]
+	manyEntries := make([]models.CompanySearchFilters, maxFilterGroups+1)
+	for i := range manyEntries {
+		manyEntries[i] = models.CompanySearchFilters{SearchTerm: "a"}
+	}
+	manyClauses := make([]models.WhereClause, maxWhereClauses+1)
+	for i := range manyClauses {
+		manyClauses[i] = models.WhereClause{Field: "turnover", Op: "gt", Value: 1.0}
+	}
+
+	tests := []struct {
+		name    string
+		filters models.CompanySearchFilters
+		want    string
+	}{
+		{"unset", models.CompanySearchFilters{}, ""},
+		{"valid", models.CompanySearchFilters{Revenue: revenue, Location: "London", PostcodeArea: "ec,m", DissolvedFrom: "2020-01-01", DissolvedTo: "2021-01-01"}, ""},
+		{"bucket with an en dash", models.CompanySearchFilters{Revenue: "1m–10m"}, "revenue"},
+		{"trailing space", models.CompanySearchFilters{Location: "London "}, "location"},
+		{"malformed date", models.CompanySearchFilters{DissolvedFrom: "2020-13-01"}, "dissolved_from"},
+		{"dates out of order", models.CompanySearchFilters{DissolvedFrom: "2021-01-01", DissolvedTo: "2020-01-01"}, "dissolved_to"},
+		{"postcode area", models.CompanySearchFilters{PostcodeArea: "ec,1e"}, "postcode_area"},
+		{"where clause", models.CompanySearchFilters{Where: []models.WhereClause{{Field: "turnover", Op: "gt", Value: 1.0}, {Field: "password", Op: "eq", Value: "x"}}}, "where[1]"},
+		{"sample and offset", models.CompanySearchFilters{Sample: 10, Offset: 10}, "offset"},
+		{"in a group", models.CompanySearchFilters{Or: []models.CompanySearchFilters{{SearchTerm: "a"}, {Revenue: "lots"}}}, "or[1].revenue"},
+		{"nested group", models.CompanySearchFilters{And: []models.CompanySearchFilters{{Or: []models.CompanySearchFilters{{Location: " Leeds"}}}}}, "and[0].or[0].location"},
+		{"empty or entry", models.CompanySearchFilters{Or: []models.CompanySearchFilters{{SearchTerm: "a"}, {}}}, "or[1]"},
+		{"empty and entry", models.CompanySearchFilters{And: []models.CompanySearchFilters{{}}}, "and[0]"},
+		{"entry with only an empty group", models.CompanySearchFilters{Or: []models.CompanySearchFilters{{Or: []models.CompanySearchFilters{{}}}}}, "or[0].or[0]"},
+		{"top-level only options in a group", models.CompanySearchFilters{Or: []models.CompanySearchFilters{{SearchTerm: "a", Sample: -1}}}, ""},
+		{"deepest groups", nested(maxFilterDepth), ""},
+		{"groups too deep", nested(maxFilterDepth + 1), strings.TrimSuffix(strings.Repeat("and[0].", maxFilterDepth), ".")},
+		{"too many entries", models.CompanySearchFilters{Or: manyEntries}, "or"},
+		{"too many where clauses", models.CompanySearchFilters{Where: manyClauses}, "where"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := invalidFields(tt.filters); got != tt.want {
+				t.Errorf("invalid fields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidFiltersError(t *testing.T) {
+	if err := InvalidFiltersError(nil); err != nil {
+		t.Errorf("InvalidFiltersError(nil) = %v, want nil", err)
+	}
+	err := InvalidFiltersError([]models.InvalidFilter{
+		{Field: "revenue", Reason: "not an accepted value", Accepted: []string{"0-1m", "1m-10m"}},
+		{Field: "or[1]", Reason: "has no filters"},
+	})
+	if want := "revenue: not an accepted value (accepted: 0-1m, 1m-10m); or[1]: has no filters"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// VATNumber is the VAT registration number of a company, 9 or 12 digits without the GB prefix
+type VATNumber struct {
+	CompanyNumber string
+	VATNumber     string
+}
+
+// ImportVATNumbers COPYs a batch of VAT numbers into a temporary table and stores them on the
+// staged companies they are for, in one transaction. A company whose number changes is checked
+// with HMRC again; companies not in staging are skipped. It returns the number of companies
+// updated.
+func (db *DB) ImportVATNumbers(ctx context.Context, batchID string, numbers []VATNumber) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+	CREATE TEMP TABLE import_vat_numbers (
+		company_number TEXT NOT NULL,
+		vat_number TEXT NOT NULL
+	) ON COMMIT DROP
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	// The last number listed for a company wins, as with a later file
+	last := make(map[string]int, len(numbers))
+	for i, n := range numbers {
+		last[n.CompanyNumber] = i
+	}
+	rows := make([][]any, 0, len(last))
+	for i, n := range numbers {
+		if last[n.CompanyNumber] == i {
+			rows = append(rows, []any{n.CompanyNumber, n.VATNumber})
+		}
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_vat_numbers"}, []string{"company_number", "vat_number"}, pgx.CopyFromRows(rows)); err != nil {
+		return 0, fmt.Errorf("failed to copy VAT numbers: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+	UPDATE staging_companies c SET
+		vat_number = t.vat_number,
+		vat_registered = NULL,
+		vat_checked_at = NULL
+	FROM import_vat_numbers t
+	WHERE c.company_number = t.company_number
+		AND c.vat_number IS DISTINCT FROM t.vat_number
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store VAT numbers of %s: %w", batchID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit import batch: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CompaniesDueVATCheck returns up to limit companies whose VAT number has never been checked
+// with HMRC or was last checked before staleBefore, never checked first
+func (db *DB) CompaniesDueVATCheck(ctx context.Context, staleBefore time.Time, limit int) ([]VATNumber, error) {
+	rows, err := db.Query(ctx, `
+	SELECT company_number, vat_number
+	FROM staging_companies
+	WHERE vat_number IS NOT NULL AND (vat_checked_at IS NULL OR vat_checked_at < $1)
+	ORDER BY vat_checked_at NULLS FIRST, company_number
+	LIMIT $2
+	`, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list companies due a VAT check: %w", err)
+	}
+	numbers, err := pgx.CollectRows(rows, pgx.RowToStructByPos[VATNumber])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan VAT number: %w", err)
+	}
+	return numbers, nil
+}
+
+// RecordVATCheck stores whether HMRC has number registered. It is not stored if the company's
+// number was changed by an import during the check.
+func (db *DB) RecordVATCheck(ctx context.Context, number VATNumber, registered bool) error {
+	_, err := db.Exec(ctx, `
+	UPDATE staging_companies SET vat_registered = $3, vat_checked_at = NOW()
+	WHERE company_number = $1 AND vat_number = $2
+	`, number.CompanyNumber, number.VATNumber, registered)
+	if err != nil {
+		return fmt.Errorf("failed to record VAT check of %s: %w", number.CompanyNumber, err)
+	}
+	return nil
+}
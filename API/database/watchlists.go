@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// CreateWatchlist stores a watchlist and its companies for an owner
+func (db *DB) CreateWatchlist(ctx context.Context, ownerID, name string, companyNumbers []string) (models.Watchlist, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return models.Watchlist{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var watchlist models.Watchlist
+	err = tx.QueryRow(ctx, `
+	INSERT INTO watchlists (owner_id, name) VALUES ($1, $2)
+	RETURNING id, name, created_at, updated_at
+	`, ownerID, name).Scan(&watchlist.ID, &watchlist.Name, &watchlist.CreatedAt, &watchlist.UpdatedAt)
+	if err != nil {
+		return models.Watchlist{}, fmt.Errorf("failed to create watchlist: %w", err)
+	}
+
+	if err := addWatchlistCompanies(ctx, tx, watchlist.ID, companyNumbers); err != nil {
+		return models.Watchlist{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Watchlist{}, fmt.Errorf("failed to commit watchlist: %w", err)
+	}
+
+	watchlist.CompanyNumbers = companyNumbers
+	return watchlist, nil
+}
+
+// ListWatchlists returns an owner's watchlists with their companies
+func (db *DB) ListWatchlists(ctx context.Context, ownerID string) ([]models.Watchlist, error) {
+	rows, err := db.Query(ctx, `
+	SELECT w.id, w.name, w.slack_webhook_url IS NOT NULL, w.created_at, w.updated_at,
+		COALESCE(array_agg(wc.company_number ORDER BY wc.company_number) FILTER (WHERE wc.company_number IS NOT NULL), '{}')
+	FROM watchlists w
+	LEFT JOIN watchlist_companies wc ON wc.watchlist_id = w.id
+	WHERE w.owner_id = $1
+	GROUP BY w.id
+	ORDER BY w.id
+	`, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlists: %w", err)
+	}
+	defer rows.Close()
+
+	watchlists := make([]models.Watchlist, 0)
+	for rows.Next() {
+		var w models.Watchlist
+		if err := rows.Scan(&w.ID, &w.Name, &w.Slack, &w.CreatedAt, &w.UpdatedAt, &w.CompanyNumbers); err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist: %w", err)
+		}
+		watchlists = append(watchlists, w)
+	}
+	return watchlists, rows.Err()
+}
+
+// GetWatchlist returns one of an owner's watchlists, or nil if it does not exist
+func (db *DB) GetWatchlist(ctx context.Context, ownerID string, id int) (*models.Watchlist, error) {
+	var w models.Watchlist
+	err := db.QueryRow(ctx, `
+	SELECT w.id, w.name, w.slack_webhook_url IS NOT NULL, w.created_at, w.updated_at,
+		COALESCE(array_agg(wc.company_number ORDER BY wc.company_number) FILTER (WHERE wc.company_number IS NOT NULL), '{}')
+	FROM watchlists w
+	LEFT JOIN watchlist_companies wc ON wc.watchlist_id = w.id
+	WHERE w.owner_id = $1 AND w.id = $2
+	GROUP BY w.id
+	`, ownerID, id).Scan(&w.ID, &w.Name, &w.Slack, &w.CreatedAt, &w.UpdatedAt, &w.CompanyNumbers)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watchlist: %w", err)
+	}
+	return &w, nil
+}
+
+// DeleteWatchlist removes one of an owner's watchlists. It returns false if it did not exist.
+func (db *DB) DeleteWatchlist(ctx context.Context, ownerID string, id int) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM watchlists WHERE owner_id = $1 AND id = $2", ownerID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete watchlist: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SetWatchlistSlackWebhook sets the Slack incoming webhook a watchlist's changes are posted to,
+// or stops posting them when url is nil
+func (db *DB) SetWatchlistSlackWebhook(ctx context.Context, id int, url *string) error {
+	_, err := db.Exec(ctx, "UPDATE watchlists SET slack_webhook_url = $2, updated_at = NOW() WHERE id = $1", id, url)
+	if err != nil {
+		return fmt.Errorf("failed to update watchlist Slack webhook: %w", err)
+	}
+	return nil
+}
+
+// SlackWatchlist is a watchlist posting changes to Slack, with those of its companies asked about
+type SlackWatchlist struct {
+	ID             int
+	Name           string
+	WebhookURL     string
+	CompanyNumbers []string
+}
+
+// SlackWatchlistsFor returns the watchlists with a Slack webhook that hold any of companyNumbers
+func (db *DB) SlackWatchlistsFor(ctx context.Context, companyNumbers []string) ([]SlackWatchlist, error) {
+	rows, err := db.Query(ctx, `
+	SELECT w.id, w.name, w.slack_webhook_url, array_agg(wc.company_number ORDER BY wc.company_number)
+	FROM watchlists w
+	JOIN watchlist_companies wc ON wc.watchlist_id = w.id
+	WHERE w.slack_webhook_url IS NOT NULL AND wc.company_number = ANY($1)
+	GROUP BY w.id
+	ORDER BY w.id
+	`, companyNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Slack watchlists: %w", err)
+	}
+	defer rows.Close()
+
+	watchlists := make([]SlackWatchlist, 0)
+	for rows.Next() {
+		var w SlackWatchlist
+		if err := rows.Scan(&w.ID, &w.Name, &w.WebhookURL, &w.CompanyNumbers); err != nil {
+			return nil, fmt.Errorf("failed to scan Slack watchlist: %w", err)
+		}
+		watchlists = append(watchlists, w)
+	}
+	return watchlists, rows.Err()
+}
+
+// CompanyNames returns the names of the given companies keyed by company number
+func (db *DB) CompanyNames(ctx context.Context, companyNumbers []string) (map[string]string, error) {
+	rows, err := db.Read().Query(ctx, "SELECT company_number, COALESCE(company_name, '') FROM staging_companies WHERE company_number = ANY($1)", companyNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company names: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[string]string, len(companyNumbers))
+	for rows.Next() {
+		var number, name string
+		if err := rows.Scan(&number, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan company name: %w", err)
+		}
+		names[number] = name
+	}
+	return names, rows.Err()
+}
+
+// AddWatchlistCompanies adds companies to a watchlist, ignoring ones already on it
+func (db *DB) AddWatchlistCompanies(ctx context.Context, id int, companyNumbers []string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := addWatchlistCompanies(ctx, tx, id, companyNumbers); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE watchlists SET updated_at = NOW() WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to update watchlist: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveWatchlistCompany removes a company from a watchlist. It returns false if it was not on it.
+func (db *DB) RemoveWatchlistCompany(ctx context.Context, id int, companyNumber string) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM watchlist_companies WHERE watchlist_id = $1 AND company_number = $2", id, companyNumber)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove watchlist company: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if _, err := db.Exec(ctx, "UPDATE watchlists SET updated_at = NOW() WHERE id = $1", id); err != nil {
+		return false, fmt.Errorf("failed to update watchlist: %w", err)
+	}
+	return true, nil
+}
+
+// ListWatchlistChanges returns changes detected since a time for the companies on a watchlist
+func (db *DB) ListWatchlistChanges(ctx context.Context, id int, since time.Time, limit int) ([]models.CompanyChange, error) {
+	rows, err := db.Query(ctx, `
+	SELECT e.id, e.company_number, e.change_type, e.old_value, e.new_value, e.detected_at
+	FROM company_change_events e
+	JOIN watchlist_companies wc ON wc.company_number = e.company_number
+	WHERE wc.watchlist_id = $1 AND e.detected_at > $2
+	ORDER BY e.detected_at, e.id
+	LIMIT $3
+	`, id, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlist changes: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCompanyChanges(rows)
+}
+
+func addWatchlistCompanies(ctx context.Context, tx pgx.Tx, id int, companyNumbers []string) error {
+	if len(companyNumbers) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+	INSERT INTO watchlist_companies (watchlist_id, company_number)
+	SELECT $1, unnest($2::text[])
+	ON CONFLICT DO NOTHING
+	`, id, companyNumbers)
+	if err != nil {
+		return fmt.Errorf("failed to add watchlist companies: %w", err)
+	}
+	return nil
+}
+
+func scanCompanyChanges(rows pgx.Rows) ([]models.CompanyChange, error) {
+	changes := make([]models.CompanyChange, 0)
+	for rows.Next() {
+		var c models.CompanyChange
+		if err := rows.Scan(&c.ID, &c.CompanyNumber, &c.ChangeType, &c.OldValue, &c.NewValue, &c.DetectedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan company change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
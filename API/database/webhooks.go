@@ -0,0 +1,342 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"data-co/api/models"
+)
+
+// WebhookDelivery is a pending delivery claimed by the dispatcher
+type WebhookDelivery struct {
+	ID        int64
+	EventID   int64
+	EventType string
+	Payload   []byte
+	Attempts  int
+	URL       string
+	Secret    string
+}
+
+// SearchWebhookSubscription is an active subscription to a saved search's new matches
+type SearchWebhookSubscription struct {
+	ID      int
+	Filters models.CompanySearchFilters
+}
+
+// webhookSubscriptionColumns are the columns scanned by scanWebhookSubscription
+const webhookSubscriptionColumns = "id, url, event_types, filters, active, created_at"
+
+// scanWebhookSubscription scans a row of webhookSubscriptionColumns
+func scanWebhookSubscription(row pgx.Row) (models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	var filters []byte
+	if err := row.Scan(&sub.ID, &sub.URL, &sub.EventTypes, &filters, &sub.Active, &sub.CreatedAt); err != nil {
+		return sub, err
+	}
+	if filters != nil {
+		sub.Filters = &models.CompanySearchFilters{}
+		if err := json.Unmarshal(filters, sub.Filters); err != nil {
+			return sub, fmt.Errorf("invalid filters in webhook subscription %d: %w", sub.ID, err)
+		}
+	}
+	return sub, nil
+}
+
+// CreateWebhookSubscription registers a webhook endpoint for an owner. Subscriptions with
+// filters receive the new matches of that search rather than change events.
+func (db *DB) CreateWebhookSubscription(ctx context.Context, ownerID, url, secret string, eventTypes []string, filters *models.CompanySearchFilters) (models.WebhookSubscription, error) {
+	var data []byte
+	if filters != nil {
+		var err error
+		if data, err = json.Marshal(filters); err != nil {
+			return models.WebhookSubscription{}, fmt.Errorf("failed to encode webhook filters: %w", err)
+		}
+	}
+	sub, err := scanWebhookSubscription(db.QueryRow(ctx, `
+	INSERT INTO webhook_subscriptions (owner_id, url, secret, event_types, filters)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING `+webhookSubscriptionColumns, ownerID, url, secret, eventTypes, data))
+	if err != nil {
+		return sub, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// ListWebhookSubscriptions returns an owner's webhook subscriptions
+func (db *DB) ListWebhookSubscriptions(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	rows, err := db.Query(ctx, `
+	SELECT `+webhookSubscriptionColumns+`
+	FROM webhook_subscriptions
+	WHERE owner_id = $1
+	ORDER BY id
+	`, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]models.WebhookSubscription, 0)
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// GetWebhookSubscription returns one of an owner's subscriptions, or nil if it does not exist
+func (db *DB) GetWebhookSubscription(ctx context.Context, ownerID string, id int) (*models.WebhookSubscription, error) {
+	sub, err := scanWebhookSubscription(db.QueryRow(ctx, `
+	SELECT `+webhookSubscriptionColumns+`
+	FROM webhook_subscriptions
+	WHERE owner_id = $1 AND id = $2
+	`, ownerID, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// DeleteWebhookSubscription removes one of an owner's subscriptions along with its pending deliveries.
+// It returns false if the subscription did not exist.
+func (db *DB) DeleteWebhookSubscription(ctx context.Context, ownerID string, id int) (bool, error) {
+	tag, err := db.Exec(ctx, "DELETE FROM webhook_subscriptions WHERE owner_id = $1 AND id = $2", ownerID, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListWebhookDeadLetters returns the most recent failed deliveries of a subscription
+func (db *DB) ListWebhookDeadLetters(ctx context.Context, subscriptionID int, limit int) ([]models.WebhookDeadLetter, error) {
+	rows, err := db.Query(ctx, `
+	SELECT id, event_id, event_type, payload, attempts, last_status_code, last_error, failed_at
+	FROM webhook_dead_letters
+	WHERE subscription_id = $1
+	ORDER BY failed_at DESC
+	LIMIT $2
+	`, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := make([]models.WebhookDeadLetter, 0)
+	for rows.Next() {
+		var l models.WebhookDeadLetter
+		if err := rows.Scan(&l.ID, &l.EventID, &l.EventType, &l.Payload, &l.Attempts, &l.LastStatusCode, &l.LastError, &l.FailedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook dead letter: %w", err)
+		}
+		letters = append(letters, l)
+	}
+	return letters, rows.Err()
+}
+
+// EnqueueWebhookDeliveries queues a change event for every active subscription that wants its
+// type. Saved search subscriptions are left out.
+func (db *DB) EnqueueWebhookDeliveries(ctx context.Context, eventID int64, eventType string, payload []byte) (int64, error) {
+	tag, err := db.Exec(ctx, `
+	INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+	SELECT id, $1, $2, $3
+	FROM webhook_subscriptions
+	WHERE active AND filters IS NULL AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+	ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`, eventID, eventType, payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListSearchWebhookSubscriptions returns every active saved search subscription
+func (db *DB) ListSearchWebhookSubscriptions(ctx context.Context) ([]SearchWebhookSubscription, error) {
+	rows, err := db.Query(ctx, "SELECT id, filters FROM webhook_subscriptions WHERE active AND filters IS NOT NULL ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list search webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]SearchWebhookSubscription, 0)
+	for rows.Next() {
+		var sub SearchWebhookSubscription
+		var filters []byte
+		if err := rows.Scan(&sub.ID, &filters); err != nil {
+			return nil, fmt.Errorf("failed to scan search webhook subscription: %w", err)
+		}
+		if err := json.Unmarshal(filters, &sub.Filters); err != nil {
+			return nil, fmt.Errorf("invalid filters in webhook subscription %d: %w", sub.ID, err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// NewSearchMatches returns those of companyNumbers that match filters, were first ingested after
+// a saved search subscription was created, and have not yet been delivered to it
+func (db *DB) NewSearchMatches(ctx context.Context, subscriptionID int, filters models.CompanySearchFilters, companyNumbers []string) ([]models.Company, error) {
+	qb := NewQueryBuilder()
+	applyExpression(qb, filters)
+	qb.addCondition("c.company_number = ANY($%d)", companyNumbers)
+	qb.addCondition("c.ingested_at > (SELECT created_at FROM webhook_subscriptions WHERE id = $%d)", subscriptionID)
+	qb.addCondition(`NOT EXISTS (
+		SELECT 1 FROM webhook_search_matches m WHERE m.subscription_id = $%d AND m.company_number = c.company_number
+	)`, subscriptionID)
+	filters.OrderBy, filters.Limit, filters.Offset, filters.Fields = "company_number", len(companyNumbers), 0, nil
+	filters.Sample, filters.ScoreWeights = 0, nil
+
+	rows, err := db.Query(ctx, qb.BuildQuery(filters), qb.GetArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match saved search: %w", err)
+	}
+	defer rows.Close()
+
+	companies := make([]models.Company, 0)
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		c.MatchedOn = SearchTermMatch(c.CompanyName, filters.SearchTerm)
+		companies = append(companies, c)
+	}
+	return companies, rows.Err()
+}
+
+// SaveSearchMatches records companies as delivered to a saved search subscription and queues a
+// delivery of each, with the payload built from the match's event ID and time. Companies that
+// were already recorded are skipped. It returns how many deliveries were queued.
+func (db *DB) SaveSearchMatches(ctx context.Context, subscriptionID int, eventType string, companies []models.Company,
+	payload func(eventID int64, matchedAt time.Time, c models.Company) ([]byte, error)) (int, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queued := 0
+	for _, c := range companies {
+		var eventID int64
+		var matchedAt time.Time
+		err := tx.QueryRow(ctx, `
+		INSERT INTO webhook_search_matches (subscription_id, company_number) VALUES ($1, $2)
+		ON CONFLICT (subscription_id, company_number) DO NOTHING
+		RETURNING id, matched_at
+		`, subscriptionID, c.CompanyNumber).Scan(&eventID, &matchedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to record search match: %w", err)
+		}
+
+		data, err := payload(eventID, matchedAt, c)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload) VALUES ($1, $2, $3, $4)
+		`, subscriptionID, eventID, eventType, data); err != nil {
+			return 0, fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+		}
+		queued++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit search matches: %w", err)
+	}
+	return queued, nil
+}
+
+// ClaimWebhookDeliveries locks up to limit due deliveries for one attempt. Claimed deliveries are
+// pushed lease into the future so that a crashed dispatcher's work is retried by another.
+func (db *DB) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	rows, err := db.Query(ctx, `
+	WITH due AS (
+		SELECT d.id
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE d.delivered_at IS NULL AND d.next_attempt_at <= NOW() AND s.active
+		ORDER BY d.next_attempt_at
+		LIMIT $1
+		FOR UPDATE OF d SKIP LOCKED
+	)
+	UPDATE webhook_deliveries d
+	SET attempts = d.attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+	FROM due, webhook_subscriptions s
+	WHERE d.id = due.id AND s.id = d.subscription_id
+	RETURNING d.id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0)
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.EventID, &d.EventType, &d.Payload, &d.Attempts, &d.URL, &d.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// MarkWebhookDelivered records a successful delivery
+func (db *DB) MarkWebhookDelivered(ctx context.Context, id int64, statusCode int) error {
+	_, err := db.Exec(ctx, `
+	UPDATE webhook_deliveries SET delivered_at = NOW(), last_status_code = $2, last_error = NULL
+	WHERE id = $1
+	`, id, statusCode)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+	return nil
+}
+
+// RetryWebhookDelivery records a failed attempt and schedules the next one
+func (db *DB) RetryWebhookDelivery(ctx context.Context, id int64, statusCode *int, lastError string, nextAttempt time.Time) error {
+	_, err := db.Exec(ctx, `
+	UPDATE webhook_deliveries SET last_status_code = $2, last_error = $3, next_attempt_at = $4
+	WHERE id = $1
+	`, id, statusCode, lastError, nextAttempt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule webhook retry: %w", err)
+	}
+	return nil
+}
+
+// DeadLetterWebhookDelivery moves a delivery that exhausted its retries to the dead-letter table
+func (db *DB) DeadLetterWebhookDelivery(ctx context.Context, id int64, statusCode *int, lastError string) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+	INSERT INTO webhook_dead_letters (subscription_id, event_id, event_type, payload, attempts, last_status_code, last_error)
+	SELECT subscription_id, event_id, event_type, payload, attempts, $2, $3
+	FROM webhook_deliveries WHERE id = $1
+	`, id, statusCode, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter webhook delivery: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM webhook_deliveries WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to remove webhook delivery: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WebsiteCompany is a company the website enrichment job looks for a website for
+type WebsiteCompany struct {
+	CompanyNumber  string
+	CompanyName    string
+	NormalizedName string // As normalize_company_name, e.g. "acme widgets" for "The Acme Widgets Co. Ltd"
+	PostalCode     *string
+	Locality       *string
+}
+
+// CompaniesDueWebsiteCheck returns up to limit active companies never checked for a website or
+// last checked before staleBefore, never checked first
+func (db *DB) CompaniesDueWebsiteCheck(ctx context.Context, staleBefore time.Time, limit int) ([]WebsiteCompany, error) {
+	rows, err := db.Query(ctx, `
+	SELECT company_number, company_name, normalize_company_name(company_name), postal_code, locality
+	FROM staging_companies
+	WHERE lower(company_status) = 'active' AND company_name IS NOT NULL
+		AND (website_checked_at IS NULL OR website_checked_at < $1)
+	ORDER BY website_checked_at NULLS FIRST, company_number
+	LIMIT $2
+	`, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list companies due a website check: %w", err)
+	}
+	companies, err := pgx.CollectRows(rows, pgx.RowToStructByPos[WebsiteCompany])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan company: %w", err)
+	}
+	return companies, nil
+}
+
+// RecordWebsite stores the website found for a company by source, with how confident the match
+// is, or clears it when domain is empty, as no website was found
+func (db *DB) RecordWebsite(ctx context.Context, companyNumber, domain, source string, confidence float64) error {
+	_, err := db.Exec(ctx, `
+	UPDATE staging_companies SET
+		website_domain = NULLIF($2::text, ''),
+		website_source = CASE WHEN $2 = '' THEN NULL ELSE $3::text END,
+		website_confidence = CASE WHEN $2 = '' THEN NULL ELSE round($4::numeric, 2) END,
+		website_checked_at = NOW()
+	WHERE company_number = $1
+	`, companyNumber, domain, source, confidence)
+	if err != nil {
+		return fmt.Errorf("failed to record website of %s: %w", companyNumber, err)
+	}
+	return nil
+}
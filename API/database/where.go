@@ -0,0 +1,207 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"data-co/api/models"
+)
+
+// whereKind is the type of a field that where clauses can compare
+type whereKind int
+
+const (
+	whereNumber whereKind = iota
+	whereText
+	whereDate
+	whereArray // Text array, matched element by element
+)
+
+// whereField is a column that where clauses can compare, selected over companyJoins
+type whereField struct {
+	column string
+	kind   whereKind
+}
+
+// whereFields is the whitelist of fields where clauses can use, by the name they have in
+// search results
+var whereFields = map[string]whereField{
+	"company_name":                    {"c.company_name", whereText},
+	"company_status":                  {"c.company_status", whereText},
+	"company_type":                    {"c.company_type", whereText},
+	"locality":                        {"c.locality", whereText},
+	"region":                          {"c.region", whereText},
+	"country":                         {countryNameColumn, whereText},
+	"uk_region":                       {ukRegionNameColumn, whereText},
+	"postal_code":                     {"c.postal_code", whereText},
+	"domain":                          {"c.website_domain", whereText},
+	"primary_sic_code":                {"c.sic_codes[1]", whereText},
+	"sic_codes":                       {"c.sic_codes", whereArray},
+	"accounts_category":               {"c.account_category", whereText},
+	"incorporation_date":              {"c.incorporation_date", whereDate},
+	"dissolved_on":                    {"c.dissolved_on", whereDate},
+	"latest_accounts_date":            {"latest_fin.period_end", whereDate},
+	"next_accounts_due":               {"c.accounts_next_due_date", whereDate},
+	"confirmation_statement_next_due": {"c.conf_stm_next_due_date", whereDate},
+	"turnover":                        {"latest_fin.turnover", whereNumber},
+	"profit_after_tax":                {"latest_fin.profit_after_tax", whereNumber},
+	"total_assets":                    {"latest_fin.total_assets", whereNumber},
+	"net_worth":                       {"latest_fin.net_worth", whereNumber},
+	"cash":                            {"latest_fin.cash", whereNumber},
+	"current_assets":                  {"latest_fin.current_assets", whereNumber},
+	"current_liabilities":             {"latest_fin.current_liabilities", whereNumber},
+	"current_ratio":                   {"latest_fin.current_ratio", whereNumber},
+	"fixed_assets":                    {"latest_fin.fixed_assets", whereNumber},
+	"intangible_assets":               {"latest_fin.intangible_assets", whereNumber},
+	"shareholder_funds":               {"latest_fin.shareholder_funds", whereNumber},
+	"revenue_growth":                  {"latest_fin.revenue_growth", whereNumber},
+	"accounts_currency":               {"latest_fin.currency::text", whereText},
+	"active_officers_count":           {"COALESCE(officer_counts.active_officers, 0)", whereNumber},
+	"employee_count":                  {"latest_fin.employee_count", whereNumber},
+	"profitable_years":                {"latest_fin.profitable_years", whereNumber},
+	"growth_years":                    {"latest_fin.growth_years", whereNumber},
+	"health_score":                    {"health.score", whereNumber},
+	"health":                          {"health.band", whereText},
+	"risk_band":                       {"risk.band", whereText},
+	"risk_flags":                      {"risk.flags", whereArray},
+}
+
+// whereOperators are the operators each kind of field accepts
+var whereOperators = map[whereKind][]string{
+	whereNumber: {"eq", "neq", "gt", "gte", "lt", "lte", "in", "nin", "is_null"},
+	whereDate:   {"eq", "neq", "gt", "gte", "lt", "lte", "is_null"},
+	whereText:   {"eq", "neq", "in", "nin", "contains", "starts_with", "is_null"},
+	whereArray:  {"contains", "is_null"},
+}
+
+// comparisons are the SQL operators of the comparison operators
+var comparisons = map[string]string{"eq": "=", "neq": "<>", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+
+// maxWhereValues bounds the values of an in or nin list
+const maxWhereValues = 100
+
+// WhereFieldNames returns the fields where clauses can use, sorted
+func WhereFieldNames() []string {
+	names := make([]string, 0, len(whereFields))
+	for name := range whereFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddWhereClauses filters by each where clause. Clauses that do not compile are skipped, since
+// ValidateFilters rejects them before a query is built.
+func (qb *QueryBuilder) AddWhereClauses(clauses []models.WhereClause) {
+	for _, clause := range clauses {
+		condition, value, err := compileWhere(clause)
+		if err != nil {
+			continue
+		}
+		if value == nil {
+			qb.conditions = append(qb.conditions, condition)
+		} else {
+			qb.addCondition(condition, value)
+		}
+	}
+}
+
+// compileWhere compiles a where clause to a condition, with $%[1]d for its value, and the value
+// to bind. The value is nil for conditions that need none.
+func compileWhere(clause models.WhereClause) (string, any, error) {
+	field, ok := whereFields[clause.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown field %q; fields are %s", clause.Field, strings.Join(WhereFieldNames(), ", "))
+	}
+	if !slices.Contains(whereOperators[field.kind], clause.Op) {
+		return "", nil, fmt.Errorf("%s does not support op %q; ops are %s", clause.Field, clause.Op, strings.Join(whereOperators[field.kind], ", "))
+	}
+
+	if clause.Op == "is_null" {
+		isNull, ok := clause.Value.(bool)
+		if !ok {
+			return "", nil, fmt.Errorf("%s is_null needs true or false", clause.Field)
+		}
+		if isNull {
+			return field.column + " IS NULL", nil, nil
+		}
+		return field.column + " IS NOT NULL", nil, nil
+	}
+
+	switch field.kind {
+	case whereNumber:
+		if clause.Op == "in" || clause.Op == "nin" {
+			values, err := whereList(clause, func(v any) (float64, bool) { n, ok := v.(float64); return n, ok })
+			if err != nil {
+				return "", nil, err
+			}
+			return fmt.Sprintf("%s%s = ANY($%%[1]d::numeric[])", negation(clause.Op), field.column), values, nil
+		}
+		n, ok := clause.Value.(float64)
+		if !ok {
+			return "", nil, fmt.Errorf("%s %s needs a number", clause.Field, clause.Op)
+		}
+		return fmt.Sprintf("%s %s $%%[1]d::numeric", field.column, comparisons[clause.Op]), n, nil
+
+	case whereDate:
+		s, _ := clause.Value.(string)
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "", nil, fmt.Errorf("%s %s needs a YYYY-MM-DD date", clause.Field, clause.Op)
+		}
+		return fmt.Sprintf("%s %s $%%[1]d::date", field.column, comparisons[clause.Op]), s, nil
+
+	case whereText:
+		if clause.Op == "in" || clause.Op == "nin" {
+			values, err := whereList(clause, func(v any) (string, bool) { s, ok := v.(string); return strings.ToLower(s), ok })
+			if err != nil {
+				return "", nil, err
+			}
+			return fmt.Sprintf("%slower(%s) = ANY($%%[1]d)", negation(clause.Op), field.column), values, nil
+		}
+		s, ok := clause.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("%s %s needs a string", clause.Field, clause.Op)
+		}
+		switch clause.Op {
+		case "contains":
+			return field.column + " ILIKE $%[1]d", "%" + likeEscaper.Replace(s) + "%", nil
+		case "starts_with":
+			return field.column + " ILIKE $%[1]d", likeEscaper.Replace(s) + "%", nil
+		}
+		return fmt.Sprintf("lower(%s) %s lower($%%[1]d)", field.column, comparisons[clause.Op]), s, nil
+
+	default: // whereArray, contains
+		s, ok := clause.Value.(string)
+		if !ok {
+			return "", nil, fmt.Errorf("%s contains needs a string", clause.Field)
+		}
+		// @> rather than = ANY, so a GIN index on the array can be used
+		return field.column + " @> ARRAY[$%[1]d]::text[]", s, nil
+	}
+}
+
+// whereList converts the value of an in or nin clause to a list of values
+func whereList[T any](clause models.WhereClause, convert func(any) (T, bool)) ([]T, error) {
+	items, ok := clause.Value.([]any)
+	if !ok || len(items) == 0 || len(items) > maxWhereValues {
+		return nil, fmt.Errorf("%s %s needs a list of 1 to %d values", clause.Field, clause.Op, maxWhereValues)
+	}
+	values := make([]T, len(items))
+	for i, item := range items {
+		if values[i], ok = convert(item); !ok {
+			return nil, fmt.Errorf("%s %s has a value of the wrong type at index %d", clause.Field, clause.Op, i)
+		}
+	}
+	return values, nil
+}
+
+// negation prefixes the condition of a nin clause with NOT
+func negation(op string) string {
+	if op == "nin" {
+		return "NOT "
+	}
+	return ""
+}
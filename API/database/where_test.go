@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"data-co/api/models"
+)
+
+func TestCompileWhere(t *testing.T) {
+	tests := []struct {
+		clause    models.WhereClause
+		condition string
+		value     any
+	}{
+		{models.WhereClause{Field: "turnover", Op: "gte", Value: 1e6}, "latest_fin.turnover >= $%[1]d::numeric", 1e6},
+		{models.WhereClause{Field: "employee_count", Op: "in", Value: []any{1.0, 2.0}}, "latest_fin.employee_count = ANY($%[1]d::numeric[])", []float64{1, 2}},
+		{models.WhereClause{Field: "employee_count", Op: "nin", Value: []any{1.0}}, "NOT latest_fin.employee_count = ANY($%[1]d::numeric[])", []float64{1}},
+		{models.WhereClause{Field: "incorporation_date", Op: "lt", Value: "2020-01-31"}, "c.incorporation_date < $%[1]d::date", "2020-01-31"},
+		{models.WhereClause{Field: "locality", Op: "eq", Value: "London"}, "lower(c.locality) = lower($%[1]d)", "London"},
+		{models.WhereClause{Field: "locality", Op: "in", Value: []any{"Leeds", "YORK"}}, "lower(c.locality) = ANY($%[1]d)", []string{"leeds", "york"}},
+		{models.WhereClause{Field: "company_name", Op: "contains", Value: "50%_off"}, "c.company_name ILIKE $%[1]d", `%50\%\_off%`},
+		{models.WhereClause{Field: "company_name", Op: "starts_with", Value: "ACME"}, "c.company_name ILIKE $%[1]d", "ACME%"},
+		{models.WhereClause{Field: "sic_codes", Op: "contains", Value: "62020"}, "c.sic_codes @> ARRAY[$%[1]d]::text[]", "62020"},
+		{models.WhereClause{Field: "cash", Op: "is_null", Value: true}, "latest_fin.cash IS NULL", nil},
+		{models.WhereClause{Field: "cash", Op: "is_null", Value: false}, "latest_fin.cash IS NOT NULL", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.clause.Field+" "+tt.clause.Op, func(t *testing.T) {
+			condition, value, err := compileWhere(tt.clause)
+			if err != nil {
+				t.Fatalf("compileWhere: %v", err)
+			}
+			if condition != tt.condition {
+				t.Errorf("condition = %q, want %q", condition, tt.condition)
+			}
+			if fmt.Sprintf("%#v", value) != fmt.Sprintf("%#v", tt.value) {
+				t.Errorf("value = %#v, want %#v", value, tt.value)
+			}
+		})
+	}
+}
+
+func TestCompileWhereErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		clause models.WhereClause
+		want   string
+	}{
+		{"unknown field", models.WhereClause{Field: "password", Op: "eq", Value: "x"}, `unknown field "password"`},
+		{"unsupported op", models.WhereClause{Field: "incorporation_date", Op: "contains", Value: "2020"}, `does not support op "contains"`},
+		{"number as string", models.WhereClause{Field: "turnover", Op: "gt", Value: "1000"}, "needs a number"},
+		{"malformed date", models.WhereClause{Field: "incorporation_date", Op: "gt", Value: "31/01/2020"}, "needs a YYYY-MM-DD date"},
+		{"text as number", models.WhereClause{Field: "locality", Op: "eq", Value: 1.0}, "needs a string"},
+		{"empty list", models.WhereClause{Field: "locality", Op: "in", Value: []any{}}, "needs a list of 1 to 100 values"},
+		{"mixed list", models.WhereClause{Field: "turnover", Op: "in", Value: []any{1.0, "2"}}, "wrong type at index 1"},
+		{"is_null without a boolean", models.WhereClause{Field: "cash", Op: "is_null", Value: "yes"}, "needs true or false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := compileWhere(tt.clause); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddWhereClausesNumbersPlaceholders(t *testing.T) {
+	qb := NewQueryBuilder()
+	qb.AddSearchTerm("acme")
+	qb.AddWhereClauses([]models.WhereClause{
+		{Field: "turnover", Op: "gte", Value: 1e6},
+		{Field: "cash", Op: "is_null", Value: false},
+		{Field: "locality", Op: "eq", Value: "London"},
+		{Field: "unknown", Op: "eq", Value: "skipped"},
+	})
+	want := []string{
+		"(c.company_name ILIKE $1 OR c.previous_names ILIKE $1)",
+		"latest_fin.turnover >= $2::numeric",
+		"latest_fin.cash IS NOT NULL",
+		"lower(c.locality) = lower($3)",
+	}
+	if strings.Join(qb.conditions, "\n") != strings.Join(want, "\n") {
+		t.Errorf("conditions = %q, want %q", qb.conditions, want)
+	}
+	checkPlaceholders(t, qb)
+}
@@ -0,0 +1,244 @@
+// Package email sends plain text emails with attachments through an SMTP server
+package email
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/config"
+)
+
+// MaxRecipients bounds the recipients of one email
+const MaxRecipients = 10
+
+// Message is an email to send
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a Message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     io.Reader
+}
+
+// Sender sends emails through the configured SMTP server
+type Sender struct {
+	cfg config.MailConfig
+}
+
+// NewSender creates an email sender
+func NewSender(cfg config.MailConfig) *Sender {
+	return &Sender{cfg: cfg}
+}
+
+// Enabled reports whether an SMTP server is configured
+func (s *Sender) Enabled() bool {
+	return s != nil && s.cfg.Host != "" && s.cfg.From != ""
+}
+
+// MaxAttachment is the size of the largest file that should be attached
+func (s *Sender) MaxAttachment() int64 {
+	return s.cfg.MaxAttachment
+}
+
+// ValidateRecipients checks there are between 1 and MaxRecipients valid addresses
+func ValidateRecipients(to []string) error {
+	if len(to) == 0 {
+		return errors.New("email.to needs at least one address")
+	}
+	if len(to) > MaxRecipients {
+		return fmt.Errorf("email.to can have at most %d addresses", MaxRecipients)
+	}
+	for _, address := range to {
+		if parsed, err := mail.ParseAddress(address); err != nil || parsed.Address != address {
+			return fmt.Errorf("%q is not an email address", address)
+		}
+	}
+	return nil
+}
+
+// Send delivers msg, giving up after the configured timeout or when ctx is cancelled
+func (s *Sender) Send(ctx context.Context, msg Message) error {
+	if !s.Enabled() {
+		return errors.New("email delivery is not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if s.cfg.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.cfg.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	// net/smtp has no context support, so the connection is closed to abandon a send
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if err := s.send(c, msg); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to send email: %w", ctx.Err())
+		}
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return c.Quit()
+}
+
+// send runs an SMTP session sending msg
+func (s *Sender) send(c *smtp.Client, msg Message) error {
+	if ok, _ := c.Extension("STARTTLS"); ok && s.cfg.Port != 465 {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if err := writeMessage(w, from.String(), msg); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// writeMessage writes msg as a multipart/mixed MIME message
+func writeMessage(w io.Writer, from string, msg Message) error {
+	body := multipart.NewWriter(w)
+	header := []string{
+		"From: " + from,
+		"To: " + strings.Join(msg.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"Message-ID: " + messageID(from),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary=" + body.Boundary(),
+	}
+	if _, err := io.WriteString(w, strings.Join(header, "\r\n")+"\r\n\r\n"); err != nil {
+		return err
+	}
+
+	text, err := body.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(text)
+	if _, err := io.WriteString(qp, msg.Body); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+
+	for _, a := range msg.Attachments {
+		part, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return err
+		}
+		enc := base64.NewEncoder(base64.StdEncoding, &lineWriter{w: part})
+		if _, err := io.Copy(enc, a.Content); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+	return body.Close()
+}
+
+// messageID generates a unique Message-ID in the domain of from
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.TrimSuffix(from[at+1:], ">")
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
+
+// lineWriter breaks base64 output into the 76 character lines MIME requires
+type lineWriter struct {
+	w   io.Writer
+	col int
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), 76-l.col)
+		if _, err := l.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+		if l.col += n; l.col == 76 {
+			if _, err := io.WriteString(l.w, "\r\n"); err != nil {
+				return written, err
+			}
+			l.col = 0
+		}
+	}
+	return written, nil
+}
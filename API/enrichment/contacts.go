@@ -0,0 +1,31 @@
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"data-co/api/config"
+	"data-co/api/models"
+)
+
+// ContactEnricher finds the email addresses and phone numbers of a company, on demand. Each
+// lookup costs the tenant asking for it a contact credit, so implementations should make one
+// request to their provider per call.
+type ContactEnricher interface {
+	// Name identifies the provider in the source of stored contacts, e.g. "hunter"
+	Name() string
+	// Contacts returns what the provider knows of company, which may be nothing
+	Contacts(ctx context.Context, company models.Company) (models.Contacts, error)
+}
+
+// NewContactEnricher creates the contact enricher configured in cfg, or returns nil if none is
+func NewContactEnricher(cfg config.ContactsConfig) (ContactEnricher, error) {
+	if cfg.HunterAPIKey == "" {
+		return nil, nil
+	}
+	if _, err := url.ParseRequestURI(cfg.HunterURL); err != nil {
+		return nil, fmt.Errorf("invalid HUNTER_API_URL: %w", err)
+	}
+	return newHunter(cfg), nil
+}
@@ -0,0 +1,119 @@
+package enrichment
+
+import (
+	"context"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"data-co/api/database"
+)
+
+// maxPageSize bounds how much of a home page is read
+const maxPageSize = 512 << 10
+
+// guessedSuffixes are tried after each form of a company name, most likely first
+var guessedSuffixes = []string{".co.uk", ".com", ".uk"}
+
+var pageTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// domainGuesser proposes the domains made from a company's name, e.g. acmewidgets.co.uk and
+// acme-widgets.com, whose home page shows the company's postcode or number. Parked and
+// unrelated sites at those domains rarely do.
+type domainGuesser struct {
+	client  *http.Client
+	limiter *rate.Limiter
+}
+
+func (g *domainGuesser) Name() string { return "guess" }
+
+func (g *domainGuesser) Candidates(ctx context.Context, company database.WebsiteCompany) ([]Candidate, error) {
+	var candidates []Candidate
+	for _, domain := range guessDomains(company.NormalizedName) {
+		page, host, ok := g.homePage(ctx, domain)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !ok {
+			continue
+		}
+
+		c := Candidate{Domain: host}
+		if m := pageTitle.FindStringSubmatch(page); m != nil {
+			c.Name = strings.TrimSpace(html.UnescapeString(m[1]))
+		}
+		switch {
+		case company.PostalCode != nil && showsPostcode(page, *company.PostalCode):
+			c.Postcode = *company.PostalCode
+		case !strings.Contains(page, company.CompanyNumber):
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// homePage fetches the home page of domain, returning it with the host it was served from
+// after redirects. Domains that do not resolve or serve no page are reported as not ok.
+func (g *domainGuesser) homePage(ctx context.Context, domain string) (string, string, bool) {
+	if err := g.limiter.Wait(ctx); err != nil {
+		return "", "", false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+domain+"/", nil)
+	if err != nil {
+		return "", "", false
+	}
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", "", false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return "", "", false
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return "", "", false
+	}
+	return string(body), resp.Request.URL.Host, true
+}
+
+// guessDomains returns the domains a company with the normalised name might use: its words
+// run together and, for several words, hyphenated, under each of guessedSuffixes
+func guessDomains(normalizedName string) []string {
+	words := strings.Fields(normalizedName)
+	if len(words) == 0 {
+		return nil
+	}
+	forms := []string{strings.Join(words, "")}
+	if len(words) > 1 {
+		forms = append(forms, strings.Join(words, "-"))
+	}
+
+	domains := make([]string, 0, len(forms)*len(guessedSuffixes))
+	for _, form := range forms {
+		if len(form) > 63 || nonAlphanumeric.MatchString(strings.ReplaceAll(form, "-", "")) {
+			continue
+		}
+		for _, suffix := range guessedSuffixes {
+			domains = append(domains, form+suffix)
+		}
+	}
+	return domains
+}
+
+// showsPostcode reports whether page has postcode in it, with or without its space
+func showsPostcode(page, postcode string) bool {
+	compact := normalizePostcode(postcode)
+	if len(compact) < 5 {
+		return false
+	}
+	text := strings.ToUpper(page)
+	return strings.Contains(text, compact[:len(compact)-3]+" "+compact[len(compact)-3:]) || strings.Contains(text, compact)
+}
@@ -0,0 +1,88 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"data-co/api/database"
+)
+
+// httpProvider asks an external lookup service for a company's websites:
+//
+//	GET {url}?company_number=01234567&name=ACME+LTD&postcode=EC1V+9LT&locality=London
+//	Authorization: Bearer {apiKey}
+//
+// which answers {"results": [{"domain": "acme.co.uk", "name": "Acme", "postcode": "EC1V 9LT"}]},
+// or 404 when it knows of none
+type httpProvider struct {
+	url     string
+	apiKey  string
+	client  *http.Client
+	limiter *rate.Limiter
+}
+
+func (p *httpProvider) Name() string { return "http" }
+
+func (p *httpProvider) Candidates(ctx context.Context, company database.WebsiteCompany) ([]Candidate, error) {
+	query := url.Values{"company_number": {company.CompanyNumber}, "name": {company.CompanyName}}
+	if company.PostalCode != nil {
+		query.Set("postcode", *company.PostalCode)
+	}
+	if company.Locality != nil {
+		query.Set("locality", *company.Locality)
+	}
+	endpoint := p.url
+	if strings.Contains(endpoint, "?") {
+		endpoint += "&" + query.Encode()
+	} else {
+		endpoint += "?" + query.Encode()
+	}
+
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("lookup responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Results []struct {
+			Domain   string `json:"domain"`
+			Name     string `json:"name"`
+			Postcode string `json:"postcode"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid lookup response: %w", err)
+	}
+	candidates := make([]Candidate, len(result.Results))
+	for i, r := range result.Results {
+		candidates[i] = Candidate{Domain: r.Domain, Name: r.Name, Postcode: r.Postcode}
+	}
+	return candidates, nil
+}
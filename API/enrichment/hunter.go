@@ -0,0 +1,112 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"data-co/api/config"
+	"data-co/api/models"
+)
+
+// hunterLimit is how many email addresses are asked for per lookup
+const hunterLimit = 20
+
+// hunter finds the email addresses published on a company's domain, with the phone numbers of
+// the people they reach, using the Hunter.io domain search. Companies with no known website are
+// looked up by name, which Hunter resolves to a domain itself.
+type hunter struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func newHunter(cfg config.ContactsConfig) *hunter {
+	return &hunter{
+		url:    strings.TrimSuffix(cfg.HunterURL, "/"),
+		apiKey: cfg.HunterAPIKey,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (h *hunter) Name() string { return "hunter" }
+
+func (h *hunter) Contacts(ctx context.Context, company models.Company) (models.Contacts, error) {
+	query := url.Values{"limit": {fmt.Sprint(hunterLimit)}}
+	if company.Domain.Valid {
+		query.Set("domain", company.Domain.String)
+	} else {
+		query.Set("company", company.CompanyName)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url+"/domain-search?"+query.Encode(), nil)
+	if err != nil {
+		return models.Contacts{}, fmt.Errorf("invalid Hunter request: %w", err)
+	}
+	// In a header rather than the query, so it stays out of logged errors
+	req.Header.Set("X-API-KEY", h.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return models.Contacts{}, fmt.Errorf("Hunter request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return models.Contacts{}, nil
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return models.Contacts{}, fmt.Errorf("Hunter responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Data struct {
+			Emails []struct {
+				Value       string  `json:"value"`
+				Type        string  `json:"type"`
+				Confidence  *int    `json:"confidence"`
+				FirstName   *string `json:"first_name"`
+				LastName    *string `json:"last_name"`
+				Position    *string `json:"position"`
+				PhoneNumber *string `json:"phone_number"`
+			} `json:"emails"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return models.Contacts{}, fmt.Errorf("invalid Hunter response: %w", err)
+	}
+
+	var contacts models.Contacts
+	seenPhones := make(map[string]bool)
+	for _, e := range result.Data.Emails {
+		if e.Value == "" {
+			continue
+		}
+		name := strings.TrimSpace(deref(e.FirstName) + " " + deref(e.LastName))
+		contacts.Emails = append(contacts.Emails, models.ContactEmail{
+			Address:    e.Value,
+			Type:       e.Type,
+			Name:       name,
+			Position:   deref(e.Position),
+			Confidence: e.Confidence,
+		})
+		if phone := strings.TrimSpace(deref(e.PhoneNumber)); phone != "" && !seenPhones[phone] {
+			seenPhones[phone] = true
+			contacts.Phones = append(contacts.Phones, models.ContactPhone{Number: phone, Name: name})
+		}
+	}
+	return contacts, nil
+}
+
+// deref returns the string s points to, or "" for nil
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,261 @@
+// Package enrichment finds company data the registers do not hold. Websites are matched to
+// companies by scoring the candidates providers propose against the company's name and
+// postcode; providers are pluggable behind WebsiteProvider. Contacts are looked up on demand
+// from a provider behind ContactEnricher.
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"data-co/api/config"
+	"data-co/api/database"
+)
+
+// postcodeWeight is added to a candidate's score when the site shows the company's postcode,
+// and taken off when it shows another
+const postcodeWeight = 0.3
+
+// WebsiteProvider proposes websites that may belong to a company
+type WebsiteProvider interface {
+	// Name identifies the provider in website_source, e.g. "guess"
+	Name() string
+	// Candidates returns the provider's candidate websites for company, best first or in no
+	// order. An error stops the enrichment run; a company with no candidates is not one.
+	Candidates(ctx context.Context, company database.WebsiteCompany) ([]Candidate, error)
+}
+
+// Candidate is a website a provider proposes for a company
+type Candidate struct {
+	Domain   string // A host or URL; normalised when scored
+	Name     string // Business name the site shows, if known
+	Postcode string // Postcode the site shows, if known
+}
+
+// Match is the website found for a company. Domain is empty if none was.
+type Match struct {
+	Domain     string
+	Source     string // Name of the provider that proposed it
+	Confidence float64
+}
+
+// Enricher tries its providers in order, keeping the first website that scores at least
+// minConfidence
+type Enricher struct {
+	providers     []WebsiteProvider
+	minConfidence float64
+}
+
+// NewEnricher creates an enricher trying providers in order
+func NewEnricher(minConfidence float64, providers ...WebsiteProvider) *Enricher {
+	return &Enricher{providers: providers, minConfidence: minConfidence}
+}
+
+// Providers creates the built-in providers named in cfg.Providers, sharing one request rate
+// limit: "guess" tries domains made from the company name, and "http" asks an external lookup
+// service at cfg.ProviderURL
+func Providers(cfg config.WebsitesConfig) ([]WebsiteProvider, error) {
+	if len(cfg.Providers) == 0 {
+		return nil, nil
+	}
+	if cfg.MinConfidence < 0 || cfg.MinConfidence > 1 {
+		return nil, errors.New("WEBSITE_MIN_CONFIDENCE must be between 0 and 1")
+	}
+	if cfg.RequestsPerSecond <= 0 {
+		return nil, errors.New("WEBSITE_REQUESTS_PER_SECOND must be positive")
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	limiter := rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), 1)
+
+	providers := make([]WebsiteProvider, 0, len(cfg.Providers))
+	for _, name := range cfg.Providers {
+		switch name {
+		case "guess":
+			providers = append(providers, &domainGuesser{client: client, limiter: limiter})
+		case "http":
+			if cfg.ProviderURL == "" {
+				return nil, errors.New("WEBSITE_PROVIDER_URL is required with the http website provider")
+			}
+			providers = append(providers, &httpProvider{url: cfg.ProviderURL, apiKey: cfg.ProviderAPIKey, client: client, limiter: limiter})
+		default:
+			return nil, fmt.Errorf("WEBSITE_PROVIDERS: unknown provider %q, want guess or http", name)
+		}
+	}
+	return providers, nil
+}
+
+// Enabled reports whether the enricher has a provider to ask
+func (e *Enricher) Enabled() bool {
+	return e != nil && len(e.providers) > 0
+}
+
+// Find looks for the website of company, returning an empty Match if no provider proposes one
+// that scores well enough
+func (e *Enricher) Find(ctx context.Context, company database.WebsiteCompany) (Match, error) {
+	for _, p := range e.providers {
+		candidates, err := p.Candidates(ctx, company)
+		if err != nil {
+			return Match{}, fmt.Errorf("%s website provider: %w", p.Name(), err)
+		}
+		best := Match{}
+		for _, c := range candidates {
+			domain := normalizeDomain(c.Domain)
+			if domain == "" {
+				continue
+			}
+			if s := score(company, domain, c); s > best.Confidence {
+				best = Match{Domain: domain, Source: p.Name(), Confidence: s}
+			}
+		}
+		if best.Domain != "" && best.Confidence >= e.minConfidence {
+			return best, nil
+		}
+	}
+	return Match{}, nil
+}
+
+// score rates from 0 to 1 how well a candidate website matches company: by how closely the
+// domain, or the name the site shows, matches the company name, raised or lowered by whether
+// the site shows the company's postcode
+func score(company database.WebsiteCompany, domain string, c Candidate) float64 {
+	name := strings.ReplaceAll(company.NormalizedName, " ", "")
+	label := domainLabel(domain)
+	s := 0.0
+	if name != "" {
+		if label == name {
+			s = 1
+		} else {
+			s = dice(label, name)
+		}
+	}
+	if c.Name != "" {
+		s = max(s, jaccard(strings.Fields(company.NormalizedName), strings.Fields(normalizeName(c.Name))))
+	}
+	if c.Postcode != "" && company.PostalCode != nil {
+		if normalizePostcode(c.Postcode) == normalizePostcode(*company.PostalCode) {
+			s += postcodeWeight
+		} else {
+			s -= postcodeWeight
+		}
+	}
+	return min(max(s, 0), 1)
+}
+
+// publicSuffixes are the registry suffixes domainLabel strips, longest first
+var publicSuffixes = []string{
+	".co.uk", ".org.uk", ".ltd.uk", ".plc.uk", ".me.uk", ".net.uk",
+	".uk", ".com", ".net", ".org", ".io", ".co", ".biz", ".info", ".eu",
+}
+
+// domainLabel returns the registered name of a domain without hyphens, e.g. "acmewidgets" for
+// "shop.acme-widgets.co.uk"
+func domainLabel(domain string) string {
+	rest := domain
+	for _, suffix := range publicSuffixes {
+		if strings.HasSuffix(domain, suffix) && len(domain) > len(suffix) {
+			rest = strings.TrimSuffix(domain, suffix)
+			break
+		}
+	}
+	if rest == domain {
+		// An unlisted suffix is taken to be one label
+		if i := strings.LastIndexByte(rest, '.'); i > 0 {
+			rest = rest[:i]
+		}
+	}
+	if i := strings.LastIndexByte(rest, '.'); i >= 0 {
+		rest = rest[i+1:]
+	}
+	return strings.ReplaceAll(rest, "-", "")
+}
+
+// normalizeDomain reduces a host or URL to a lower-case host without www., port or trailing
+// dot, e.g. "acme.co.uk" for "https://www.Acme.co.uk/about". It returns "" if there is no
+// host with a dot in it.
+func normalizeDomain(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	host = strings.TrimPrefix(host, "www.")
+	if !strings.Contains(host, ".") || len(host) > 253 {
+		return ""
+	}
+	return host
+}
+
+var (
+	nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+	legalForms      = regexp.MustCompile(`\b(limited|ltd|plc|llp|lp|cic|cio|company|co|incorporated|inc|corporation|corp|uk)\b`)
+)
+
+// normalizeName reduces a business name the way normalize_company_name does, e.g. "acme" for
+// "The Acme Co. Ltd", so it can be compared with the names of companies
+func normalizeName(name string) string {
+	words := nonAlphanumeric.ReplaceAllString(strings.ReplaceAll(strings.ToLower(name), "&", " and "), " ")
+	reduced := strings.Fields(legalForms.ReplaceAllString(words, " "))
+	if len(reduced) > 0 && reduced[0] == "the" {
+		reduced = reduced[1:]
+	}
+	if len(reduced) == 0 {
+		return strings.Join(strings.Fields(words), " ")
+	}
+	return strings.Join(reduced, " ")
+}
+
+// normalizePostcode upper-cases a postcode and removes its spaces, e.g. "SW1A1AA"
+func normalizePostcode(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
+
+// jaccard returns the share of the distinct words of a and b that both have
+func jaccard(a, b []string) float64 {
+	set := make(map[string]int, len(a)+len(b))
+	for _, w := range a {
+		set[w] |= 1
+	}
+	for _, w := range b {
+		set[w] |= 2
+	}
+	if len(set) == 0 {
+		return 0
+	}
+	both := 0
+	for _, in := range set {
+		if in == 3 {
+			both++
+		}
+	}
+	return float64(both) / float64(len(set))
+}
+
+// dice returns the Sørensen–Dice coefficient of the character bigrams of a and b
+func dice(a, b string) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+	bigrams := make(map[string]int, len(a))
+	for i := 0; i+1 < len(a); i++ {
+		bigrams[a[i:i+2]]++
+	}
+	shared := 0
+	for i := 0; i+1 < len(b); i++ {
+		if bigrams[b[i:i+2]] > 0 {
+			bigrams[b[i:i+2]]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b)-2)
+}
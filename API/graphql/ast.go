@@ -0,0 +1,133 @@
+// Package graphql serves the API's company data over GraphQL. It contains a small executor for
+// queries (no mutations, subscriptions or introspection beyond __typename) and the schema built
+// on the database package.
+package graphql
+
+// document is a parsed GraphQL request document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is a query operation definition
+type operation struct {
+	kind      string // "query", "mutation" or "subscription"
+	name      string
+	variables []*variableDefinition
+	selection []selection
+}
+
+// variableDefinition declares an operation variable
+type variableDefinition struct {
+	name         string
+	typ          typeRef
+	defaultValue *value
+}
+
+// typeRef is a type as written in a variable definition, e.g. [String!]!
+type typeRef struct {
+	name    string // Set for named types
+	list    *typeRef
+	nonNull bool
+}
+
+func (t typeRef) String() string {
+	s := t.name
+	if t.list != nil {
+		s = "[" + t.list.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// fragment is a named fragment definition
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selection     []selection
+}
+
+// selection is a field, fragment spread or inline fragment
+type selection interface {
+	selectionDirectives() []*directive
+}
+
+type field struct {
+	alias      string
+	name       string
+	arguments  []*argument
+	directives []*directive
+	selection  []selection
+	pos        position
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	pos        position
+}
+
+type inlineFragment struct {
+	typeCondition string // Empty when the fragment has no type condition
+	directives    []*directive
+	selection     []selection
+}
+
+func (f *field) selectionDirectives() []*directive          { return f.directives }
+func (f *fragmentSpread) selectionDirectives() []*directive { return f.directives }
+func (f *inlineFragment) selectionDirectives() []*directive { return f.directives }
+
+// responseKey is the key a field's result is returned under
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type argument struct {
+	name  string
+	value *value
+}
+
+type directive struct {
+	name      string
+	arguments []*argument
+}
+
+// valueKind identifies the kind of a literal value
+type valueKind int
+
+const (
+	variableValue valueKind = iota
+	intValue
+	floatValue
+	stringValue
+	booleanValue
+	nullValue
+	enumValue
+	listValue
+	objectValue
+)
+
+// value is a literal or variable reference in a document
+type value struct {
+	kind   valueKind
+	raw    string // Variable name, or the literal's text for scalars and enums
+	list   []*value
+	fields []*objectField
+}
+
+type objectField struct {
+	name  string
+	value *value
+}
+
+// position is a location in the request document, reported with errors
+type position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
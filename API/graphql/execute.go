@@ -0,0 +1,608 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Request is a GraphQL request as POSTed by clients
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Response is a GraphQL response. Data is omitted when the request failed before execution.
+type Response struct {
+	Data     any      `json:"data"`
+	Errors   []*Error `json:"errors,omitempty"`
+	executed bool
+}
+
+// Error is a GraphQL error, located by its position in the document and path in the response
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []position `json:"locations,omitempty"`
+	Path      []any      `json:"path,omitempty"`
+}
+
+// MarshalJSON omits data when the request was not executed
+func (r *Response) MarshalJSON() ([]byte, error) {
+	if r.executed {
+		return json.Marshal(struct {
+			Data   any      `json:"data"`
+			Errors []*Error `json:"errors,omitempty"`
+		}{r.Data, r.Errors})
+	}
+	return json.Marshal(struct {
+		Errors []*Error `json:"errors"`
+	}{r.Errors})
+}
+
+// Schema is an executable schema
+type Schema struct {
+	Query *Object
+	types map[string]Type
+}
+
+// newSchema creates a schema rooted at the query type
+func newSchema(query *Object) (*Schema, error) {
+	s := &Schema{Query: query, types: make(map[string]Type)}
+	for _, t := range []Type{String, Int, Float, Boolean} {
+		s.types[t.String()] = t
+	}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// collect registers every named type reachable from t
+func (s *Schema) collect(t Type) error {
+	t = namedType(t)
+	if existing, ok := s.types[t.String()]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: two types are named %s", t)
+		}
+		return nil
+	}
+	s.types[t.String()] = t
+
+	switch t := t.(type) {
+	case *Object:
+		for _, f := range t.Fields {
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+			for _, arg := range f.Args {
+				if err := s.collect(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+	case *InputObject:
+		for _, f := range t.Fields {
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Execute runs a query
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		if syntaxErr, ok := err.(*SyntaxError); ok {
+			return &Response{Errors: []*Error{{Message: syntaxErr.Message, Locations: []position{syntaxErr.Pos}}}}
+		}
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{schema: s, doc: doc}
+	if errs := e.validate(op); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	if e.vars, err = s.coerceVariables(op, req.Variables); err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	data, failed := e.executeSelection(ctx, s.Query, nil, op.selection, nil)
+	resp := &Response{Errors: e.errors, executed: true}
+	if !failed {
+		resp.Data = data
+	}
+	return resp
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	var op *operation
+	switch {
+	case name != "":
+		for _, candidate := range doc.operations {
+			if candidate.name == name {
+				op = candidate
+			}
+		}
+		if op == nil {
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+	case len(doc.operations) == 1:
+		op = doc.operations[0]
+	default:
+		return nil, fmt.Errorf("operationName is required when the document contains several operations")
+	}
+	if op.kind != "query" {
+		return nil, fmt.Errorf("%s operations are not supported", op.kind)
+	}
+	return op, nil
+}
+
+// executor holds the state of executing one operation
+type executor struct {
+	schema *Schema
+	doc    *document
+	vars   map[string]any
+	errors []*Error
+}
+
+func (e *executor) addError(f *field, path []any, format string, args ...any) {
+	e.errors = append(e.errors, &Error{
+		Message:   fmt.Sprintf(format, args...),
+		Locations: []position{f.pos},
+		Path:      append([]any(nil), path...),
+	})
+}
+
+// orderedMap is a JSON object that keeps its keys in insertion order, as responses must
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fieldGroup is the fields sharing one response key, which are executed once
+type fieldGroup struct {
+	key    string
+	fields []*field
+}
+
+// collectFields flattens fragments and applies @skip and @include
+func (e *executor) collectFields(obj *Object, sel []selection, groups []*fieldGroup, visited map[string]bool) []*fieldGroup {
+	for _, s := range sel {
+		if !e.included(s.selectionDirectives()) {
+			continue
+		}
+		switch s := s.(type) {
+		case *field:
+			key := s.responseKey()
+			found := false
+			for _, g := range groups {
+				if g.key == key {
+					g.fields = append(g.fields, s)
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, &fieldGroup{key: key, fields: []*field{s}})
+			}
+		case *inlineFragment:
+			if s.typeCondition == "" || s.typeCondition == obj.Name {
+				groups = e.collectFields(obj, s.selection, groups, visited)
+			}
+		case *fragmentSpread:
+			frag := e.doc.fragments[s.name]
+			if visited[s.name] || frag == nil || frag.typeCondition != obj.Name || !e.included(frag.directives) {
+				continue
+			}
+			visited[s.name] = true
+			groups = e.collectFields(obj, frag.selection, groups, visited)
+		}
+	}
+	return groups
+}
+
+// included evaluates @skip(if:) and @include(if:)
+func (e *executor) included(directives []*directive) bool {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			continue
+		}
+		cond := false
+		for _, arg := range d.arguments {
+			if arg.name == "if" {
+				v, _ := e.literal(arg.value)
+				cond, _ = v.(bool)
+			}
+		}
+		if (d.name == "skip") == cond {
+			return false
+		}
+	}
+	return true
+}
+
+// executeSelection executes a selection set against source. It reports failed if a non-null
+// field could not be resolved, in which case the object itself is null.
+func (e *executor) executeSelection(ctx context.Context, obj *Object, source any, sel []selection, path []any) (*orderedMap, bool) {
+	result := &orderedMap{}
+	for _, group := range e.collectFields(obj, sel, nil, make(map[string]bool)) {
+		value, failed := e.executeField(ctx, obj, source, group, append(path, group.key))
+		if failed {
+			return nil, true
+		}
+		result.set(group.key, value)
+	}
+	return result, false
+}
+
+func (e *executor) executeField(ctx context.Context, obj *Object, source any, group *fieldGroup, path []any) (any, bool) {
+	f := group.fields[0]
+	if f.name == "__typename" {
+		return obj.Name, false
+	}
+
+	def := obj.field(f.name)
+	_, nonNull := def.Type.(*NonNull)
+
+	args, err := e.coerceArguments(def, f.arguments)
+	if err != nil {
+		e.addError(f, path, "%v", err)
+		return nil, nonNull
+	}
+
+	var resolved any
+	if def.Resolve == nil {
+		resolved = defaultResolve(source, def.Name)
+	} else if resolved, err = e.resolve(ctx, def, source, args); err != nil {
+		e.addError(f, path, "%v", err)
+		return nil, nonNull
+	}
+
+	var sel []selection
+	for _, f := range group.fields {
+		sel = append(sel, f.selection...)
+	}
+	return e.complete(ctx, def.Type, f, sel, resolved, path)
+}
+
+// resolve calls a resolver, reporting a panic as a field error rather than failing the request
+func (e *executor) resolve(ctx context.Context, def *Field, source any, args map[string]any) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error resolving %s: %v", def.Name, r)
+		}
+	}()
+	return def.Resolve(ResolveParams{Context: ctx, Source: source, Args: args})
+}
+
+// complete converts a resolved value to its response form according to t. It reports failed
+// when the value is null because of an error at a non-null position, which makes the nearest
+// nullable parent null.
+func (e *executor) complete(ctx context.Context, t Type, f *field, sel []selection, v any, path []any) (any, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		out, failed := e.completeNullable(ctx, nn.OfType, f, sel, v, path)
+		if failed {
+			return nil, true
+		}
+		if out == nil {
+			e.addError(f, path, "cannot return null for non-null field %s", f.name)
+			return nil, true
+		}
+		return out, false
+	}
+
+	out, failed := e.completeNullable(ctx, t, f, sel, v, path)
+	if failed {
+		return nil, false
+	}
+	return out, false
+}
+
+func (e *executor) completeNullable(ctx context.Context, t Type, f *field, sel []selection, v any, path []any) (any, bool) {
+	v = unwrap(v)
+	if v == nil {
+		return nil, false
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		out, err := t.Serialize(v)
+		if err != nil {
+			e.addError(f, path, "%v", err)
+			return nil, true
+		}
+		return out, false
+	case *Object:
+		return e.executeSelection(ctx, t, v, sel, path)
+	case *List:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.addError(f, path, "expected a list for field %s, got %T", f.name, v)
+			return nil, true
+		}
+		items := make([]any, rv.Len())
+		for i := range items {
+			item, failed := e.complete(ctx, t.OfType, f, sel, rv.Index(i).Interface(), append(path, i))
+			if failed {
+				return nil, true
+			}
+			items[i] = item
+		}
+		return items, false
+	}
+	e.addError(f, path, "field %s has an unsupported type %s", f.name, t)
+	return nil, true
+}
+
+// coerceArguments converts a field's argument literals to Go values and applies defaults
+func (e *executor) coerceArguments(def *Field, args []*argument) (map[string]any, error) {
+	coerced := make(map[string]any, len(def.Args))
+	for _, argDef := range def.Args {
+		var lit *value
+		for _, a := range args {
+			if a.name == argDef.Name {
+				lit = a.value
+			}
+		}
+
+		if lit == nil || (lit.kind == variableValue && !e.hasVar(lit.raw)) {
+			if argDef.Default != nil {
+				coerced[argDef.Name] = argDef.Default
+			} else if _, ok := argDef.Type.(*NonNull); ok {
+				return nil, fmt.Errorf("argument %s of type %s is required", argDef.Name, argDef.Type)
+			}
+			continue
+		}
+
+		if lit.kind == variableValue {
+			// Variables were coerced against their declared types already
+			coerced[argDef.Name] = e.vars[lit.raw]
+			continue
+		}
+		raw, err := e.literal(lit)
+		if err != nil {
+			return nil, err
+		}
+		v, err := coerceInput(argDef.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", argDef.Name, err)
+		}
+		coerced[argDef.Name] = v
+	}
+	return coerced, nil
+}
+
+func (e *executor) hasVar(name string) bool {
+	_, ok := e.vars[name]
+	return ok
+}
+
+// literal converts a document value to its raw input form, substituting variables
+func (e *executor) literal(v *value) (any, error) {
+	switch v.kind {
+	case variableValue:
+		val, ok := e.vars[v.raw]
+		if !ok {
+			return nil, nil
+		}
+		return val, nil
+	case intValue:
+		n, err := strconv.ParseInt(v.raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s", v.raw)
+		}
+		return n, nil
+	case floatValue:
+		n, err := strconv.ParseFloat(v.raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", v.raw)
+		}
+		return n, nil
+	case stringValue:
+		return v.raw, nil
+	case booleanValue:
+		return v.raw == "true", nil
+	case nullValue:
+		return nil, nil
+	case enumValue:
+		return enumLiteral(v.raw), nil
+	case listValue:
+		items := make([]any, len(v.list))
+		for i, item := range v.list {
+			raw, err := e.literal(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = raw
+		}
+		return items, nil
+	case objectValue:
+		obj := make(map[string]any, len(v.fields))
+		for _, f := range v.fields {
+			raw, err := e.literal(f.value)
+			if err != nil {
+				return nil, err
+			}
+			obj[f.name] = raw
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("unsupported value")
+}
+
+// coerceInput converts a raw input value (from a literal or JSON variables) to the Go value for t
+func coerceInput(t Type, v any) (any, error) {
+	if nn, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected a non-null %s", nn.OfType)
+		}
+		return coerceInput(nn.OfType, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		// JSON variables decode every number as float64
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				v = i
+			} else if f, err := n.Float64(); err == nil {
+				v = f
+			}
+		}
+		return t.Parse(v)
+	case *List:
+		items, ok := v.([]any)
+		if !ok {
+			// A single value is accepted where a list is expected
+			items = []any{v}
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			c, err := coerceInput(t.OfType, item)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			out[i] = c
+		}
+		return out, nil
+	case *InputObject:
+		fields, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected a %s object, got %s", t.Name, describeInput(v))
+		}
+		known := make(map[string]bool, len(t.Fields))
+		out := make(map[string]any, len(t.Fields))
+		for _, f := range t.Fields {
+			known[f.Name] = true
+			raw, given := fields[f.Name]
+			if !given {
+				if f.Default != nil {
+					out[f.Name] = f.Default
+				} else if _, ok := f.Type.(*NonNull); ok {
+					return nil, fmt.Errorf("field %s.%s of type %s is required", t.Name, f.Name, f.Type)
+				}
+				continue
+			}
+			c, err := coerceInput(f.Type, raw)
+			if err != nil {
+				return nil, fmt.Errorf("field %s.%s: %w", t.Name, f.Name, err)
+			}
+			out[f.Name] = c
+		}
+		for name := range fields {
+			if !known[name] {
+				return nil, fmt.Errorf("%s has no field %s", t.Name, name)
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// coerceVariables checks and converts the request's variables against the operation's definitions
+func (s *Schema) coerceVariables(op *operation, provided map[string]any) (map[string]any, error) {
+	vars := make(map[string]any, len(op.variables))
+	for _, def := range op.variables {
+		t, err := s.inputType(def.typ)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", def.name, err)
+		}
+
+		raw, given := provided[def.name]
+		if !given && def.defaultValue != nil {
+			e := &executor{}
+			if raw, err = e.literal(def.defaultValue); err != nil {
+				return nil, fmt.Errorf("variable $%s: %w", def.name, err)
+			}
+			given = true
+		}
+		if !given {
+			if def.typ.nonNull {
+				return nil, fmt.Errorf("variable $%s of type %s is required", def.name, def.typ)
+			}
+			continue
+		}
+
+		v, err := coerceInput(t, raw)
+		if err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", def.name, err)
+		}
+		vars[def.name] = v
+	}
+	return vars, nil
+}
+
+// inputType resolves a variable's declared type against the schema
+func (s *Schema) inputType(ref typeRef) (Type, error) {
+	var t Type
+	if ref.list != nil {
+		of, err := s.inputType(*ref.list)
+		if err != nil {
+			return nil, err
+		}
+		t = &List{OfType: of}
+	} else {
+		named, ok := s.types[ref.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s", ref.name)
+		}
+		if _, isObject := named.(*Object); isObject {
+			return nil, fmt.Errorf("%s is not an input type", ref.name)
+		}
+		t = named
+	}
+	if ref.nonNull {
+		t = &NonNull{OfType: t}
+	}
+	return t, nil
+}
+
+// Executed reports whether the request passed parsing and validation and was executed. A
+// response that was not executed has only errors.
+func (r *Response) Executed() bool {
+	return r.executed
+}
@@ -0,0 +1,295 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testSchema is a small schema of nodes, each with a child, for exercising the executor
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+	node := &Object{Name: "Node"}
+	node.Fields = []*Field{
+		{Name: "name", Type: &NonNull{String}},
+		{Name: "size", Type: Int},
+		{Name: "child", Type: node, Resolve: func(p ResolveParams) (any, error) {
+			parent := p.Source.(map[string]any)
+			return map[string]any{"name": parent["name"].(string) + "/child", "size": parent["size"]}, nil
+		}},
+		{Name: "broken", Type: &NonNull{String}, Resolve: func(p ResolveParams) (any, error) {
+			return nil, errors.New("broken on purpose")
+		}},
+	}
+	query := &Object{Name: "Query", Fields: []*Field{
+		{
+			Name: "node",
+			Type: node,
+			Args: []*Argument{
+				{Name: "name", Type: &NonNull{String}},
+				{Name: "size", Type: Int, Default: 1},
+			},
+			Resolve: func(p ResolveParams) (any, error) {
+				return map[string]any{"name": p.Args["name"], "size": p.Args["size"]}, nil
+			},
+		},
+		{
+			Name: "echo",
+			Type: &List{&NonNull{Int}},
+			Args: []*Argument{{Name: "values", Type: &List{&NonNull{Int}}}},
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Args["values"], nil
+			},
+		},
+	}}
+
+	s, err := newSchema(query)
+	if err != nil {
+		t.Fatalf("newSchema: %v", err)
+	}
+	return s
+}
+
+// execute runs a request and returns its response as JSON
+func execute(t *testing.T, s *Schema, req Request) (string, *Response) {
+	t.Helper()
+	resp := s.Execute(context.Background(), req)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	return string(data), resp
+}
+
+func TestExecuteFragments(t *testing.T) {
+	s := testSchema(t)
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "named fragment",
+			query: `{ node(name: "a") { ...Names } } fragment Names on Node { name child { name } }`,
+			want:  `{"data":{"node":{"name":"a","child":{"name":"a/child"}}}}`,
+		},
+		{
+			name:  "inline fragment",
+			query: `{ node(name: "a") { ... on Node { name } ... { size } } }`,
+			want:  `{"data":{"node":{"name":"a","size":1}}}`,
+		},
+		{
+			name:  "fields merged across fragments",
+			query: `{ node(name: "a") { child { name } ...Child } } fragment Child on Node { child { size } }`,
+			want:  `{"data":{"node":{"child":{"name":"a/child","size":1}}}}`,
+		},
+		{
+			name:  "aliases",
+			query: `{ a: node(name: "a") { name } b: node(name: "b", size: 2) { n: name size } }`,
+			want:  `{"data":{"a":{"name":"a"},"b":{"n":"b","size":2}}}`,
+		},
+		{
+			name:  "skip and include",
+			query: `{ node(name: "a") { name @skip(if: true) size @include(if: true) ...Names @include(if: false) } } fragment Names on Node { child { name } }`,
+			want:  `{"data":{"node":{"size":1}}}`,
+		},
+		{
+			name:  "typename",
+			query: `{ __typename node(name: "a") { __typename } }`,
+			want:  `{"data":{"__typename":"Query","node":{"__typename":"Node"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := execute(t, s, Request{Query: tt.query}); got != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteFragmentErrors(t *testing.T) {
+	s := testSchema(t)
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"unknown fragment", `{ node(name: "a") { ...Missing } }`, "unknown fragment Missing"},
+		{"wrong type", `{ ...Names } fragment Names on Node { name }`, "fragment Names on Node cannot be spread on Query"},
+		{"cycle", `{ node(name: "a") { ...A } } fragment A on Node { child { ...B } } fragment B on Node { ...A }`, "fragment A spreads itself"},
+		{"inline on wrong type", `{ ... on Node { name } }`, "fragment on Node cannot be spread on Query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, resp := execute(t, s, Request{Query: tt.query})
+			if resp.Executed() {
+				t.Fatalf("query was executed: %s", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("got %s, want an error containing %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteVariables(t *testing.T) {
+	s := testSchema(t)
+	query := `query Node($name: String!, $size: Int = 5, $values: [Int!]) {
+		node(name: $name, size: $size) { name size }
+		echo(values: $values)
+	}`
+	tests := []struct {
+		name string
+		vars map[string]any
+		want string
+	}{
+		{
+			name: "given",
+			vars: map[string]any{"name": "a", "size": float64(3), "values": []any{float64(1), float64(2)}},
+			want: `{"data":{"node":{"name":"a","size":3},"echo":[1,2]}}`,
+		},
+		{
+			name: "variable default",
+			vars: map[string]any{"name": "a"},
+			want: `{"data":{"node":{"name":"a","size":5},"echo":null}}`,
+		},
+		{
+			name: "explicit null",
+			vars: map[string]any{"name": "a", "size": nil},
+			want: `{"data":{"node":{"name":"a","size":null},"echo":null}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := execute(t, s, Request{Query: query, Variables: tt.vars}); got != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteArgumentDefault(t *testing.T) {
+	s := testSchema(t)
+	// An argument whose variable is not given falls back to the argument's default
+	got, _ := execute(t, s, Request{Query: `query($size: Int) { node(name: "a", size: $size) { size } }`})
+	if want := `{"data":{"node":{"size":1}}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestExecuteVariableErrors(t *testing.T) {
+	s := testSchema(t)
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]any
+		want  string
+	}{
+		{"required", `query($name: String!) { node(name: $name) { name } }`, nil, "variable $name of type String! is required"},
+		{"wrong type", `query($name: String!) { node(name: $name) { name } }`, map[string]any{"name": float64(1)}, "expected a String"},
+		{"not an integer", `query($v: [Int!]) { echo(values: $v) }`, map[string]any{"v": []any{1.5}}, "expected an Int"},
+		{"null in list", `query($v: [Int!]) { echo(values: $v) }`, map[string]any{"v": []any{nil}}, "expected a non-null Int"},
+		{"undeclared", `{ node(name: $name) { name } }`, nil, "variable $name is not defined"},
+		{"unknown type", `query($name: Text) { node(name: $name) { name } }`, map[string]any{"name": "a"}, "unknown type Text"},
+		{"output type", `query($n: Node) { __typename }`, map[string]any{"n": "a"}, "Node is not an input type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, resp := execute(t, s, Request{Query: tt.query, Variables: tt.vars})
+			if resp.Executed() {
+				t.Fatalf("query was executed: %s", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("got %s, want an error containing %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// nestedQuery returns a query whose selection sets nest depth levels deep
+func nestedQuery(depth int) string {
+	if depth == 1 {
+		return `{ __typename }`
+	}
+	return `{ node(name: "a") { ` + strings.Repeat("child { ", depth-2) + "name" + strings.Repeat(" }", depth-1) + " }"
+}
+
+func TestExecuteDepthLimit(t *testing.T) {
+	s := testSchema(t)
+	if got, resp := execute(t, s, Request{Query: nestedQuery(maxDepth)}); !resp.Executed() || len(resp.Errors) > 0 {
+		t.Errorf("query %d levels deep failed: %s", maxDepth, got)
+	}
+
+	got, resp := execute(t, s, Request{Query: nestedQuery(maxDepth + 1)})
+	if resp.Executed() {
+		t.Fatalf("query %d levels deep was executed: %s", maxDepth+1, got)
+	}
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "nested more than") {
+		t.Errorf("got %s, want one depth error", got)
+	}
+}
+
+func TestExecuteDepthLimitCountsFragments(t *testing.T) {
+	s := testSchema(t)
+	// The fragment is spread at depth 2, so its chain of children counts from there
+	deep := func(children int) string {
+		return `{ node(name: "a") { ...Deep } } fragment Deep on Node { ` + strings.Repeat("child { ", children) + "name" + strings.Repeat(" }", children) + " }"
+	}
+	if got, resp := execute(t, s, Request{Query: deep(maxDepth - 2)}); len(resp.Errors) > 0 {
+		t.Errorf("fragment within the limit failed: %s", got)
+	}
+	if got, resp := execute(t, s, Request{Query: deep(maxDepth - 1)}); resp.Executed() {
+		t.Errorf("fragment over the limit was executed: %s", got)
+	}
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	s := testSchema(t)
+	// A non-null field that fails makes its nullable parent null, with the error located at it
+	got, resp := execute(t, s, Request{Query: `{ node(name: "a") { name broken } }`})
+	if want := `{"data":{"node":null},"errors":[{"message":"broken on purpose","locations":[{"line":1,"column":26}],"path":["node","broken"]}]}`; got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	if !resp.Executed() {
+		t.Error("response with a field error was not executed")
+	}
+}
+
+func TestExecuteValidationErrors(t *testing.T) {
+	s := testSchema(t)
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"unknown field", `{ nodes { name } }`, "type Query has no field nodes"},
+		{"unknown argument", `{ node(id: 1) { name } }`, "field node has no argument id"},
+		{"missing selection", `{ node(name: "a") }`, "must have a selection of subfields"},
+		{"selection on scalar", `{ node(name: "a") { name { x } } }`, "cannot have a selection"},
+		{"unknown directive", `{ __typename @deprecated }`, "unknown directive @deprecated"},
+		{"mutation", `mutation { __typename }`, "mutation operations are not supported"},
+		{"operation name", `query A { __typename } query B { __typename }`, "operationName is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, resp := execute(t, s, Request{Query: tt.query})
+			if resp.Executed() {
+				t.Fatalf("query was executed: %s", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("got %s, want an error containing %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteOperationName(t *testing.T) {
+	s := testSchema(t)
+	got, _ := execute(t, s, Request{Query: `query A { a: __typename } query B { b: __typename }`, OperationName: "B"})
+	if want := `{"data":{"b":"Query"}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
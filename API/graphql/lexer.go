@@ -0,0 +1,257 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind identifies a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   position
+}
+
+// lexer splits a GraphQL document into tokens. Commas, whitespace and comments are ignored.
+type lexer struct {
+	src  string
+	i    int
+	line int
+	col  int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1, col: 1}
+}
+
+// SyntaxError reports a malformed request document
+type SyntaxError struct {
+	Message string
+	Pos     position
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+}
+
+func (l *lexer) errorf(pos position, format string, args ...any) error {
+	return &SyntaxError{Message: fmt.Sprintf(format, args...), Pos: pos}
+}
+
+func (l *lexer) advance(n int) {
+	for _, r := range l.src[l.i : l.i+n] {
+		if r == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+	}
+	l.i += n
+}
+
+func (l *lexer) skipIgnored() {
+	for l.i < len(l.src) {
+		switch c := l.src[l.i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			end := strings.IndexByte(l.src[l.i:], '\n')
+			if end < 0 {
+				end = len(l.src) - l.i
+			}
+			l.advance(end)
+		case strings.HasPrefix(l.src[l.i:], "\ufeff"):
+			l.advance(len("\ufeff"))
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next token
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	pos := position{Line: l.line, Column: l.col}
+	if l.i >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+
+	c := l.src[l.i]
+	switch {
+	case strings.HasPrefix(l.src[l.i:], "..."):
+		l.advance(3)
+		return token{kind: tokenPunctuator, value: "...", pos: pos}, nil
+	case strings.ContainsRune("!$&()/:=@[]{}|", rune(c)):
+		l.advance(1)
+		return token{kind: tokenPunctuator, value: string(c), pos: pos}, nil
+	case c == '_' || isLetter(c):
+		start := l.i
+		end := start
+		for end < len(l.src) && (l.src[end] == '_' || isLetter(l.src[end]) || isDigit(l.src[end])) {
+			end++
+		}
+		l.advance(end - start)
+		return token{kind: tokenName, value: l.src[start:end], pos: pos}, nil
+	case c == '-' || isDigit(c):
+		return l.number(pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.i:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.i:])
+	return token{}, l.errorf(pos, "unexpected character %q", r)
+}
+
+func (l *lexer) number(pos position) (token, error) {
+	start := l.i
+	end := start
+	if l.src[end] == '-' {
+		end++
+	}
+	digits := func() int {
+		n := 0
+		for end < len(l.src) && isDigit(l.src[end]) {
+			end++
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, l.errorf(pos, "invalid number")
+	}
+	kind := tokenInt
+	if end < len(l.src) && l.src[end] == '.' {
+		end++
+		kind = tokenFloat
+		if digits() == 0 {
+			return token{}, l.errorf(pos, "invalid number")
+		}
+	}
+	if end < len(l.src) && (l.src[end] == 'e' || l.src[end] == 'E') {
+		end++
+		kind = tokenFloat
+		if end < len(l.src) && (l.src[end] == '+' || l.src[end] == '-') {
+			end++
+		}
+		if digits() == 0 {
+			return token{}, l.errorf(pos, "invalid number")
+		}
+	}
+	l.advance(end - start)
+	return token{kind: kind, value: l.src[start:end], pos: pos}, nil
+}
+
+func (l *lexer) string(pos position) (token, error) {
+	var b strings.Builder
+	i := l.i + 1
+	for i < len(l.src) {
+		c := l.src[i]
+		switch {
+		case c == '"':
+			l.advance(i + 1 - l.i)
+			return token{kind: tokenString, value: b.String(), pos: pos}, nil
+		case c == '\n' || c == '\r':
+			return token{}, l.errorf(pos, "unterminated string")
+		case c == '\\':
+			if i+1 >= len(l.src) {
+				return token{}, l.errorf(pos, "unterminated string")
+			}
+			switch esc := l.src[i+1]; esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+6 > len(l.src) {
+					return token{}, l.errorf(pos, "invalid unicode escape")
+				}
+				var r rune
+				if _, err := fmt.Sscanf(l.src[i+2:i+6], "%04x", &r); err != nil {
+					return token{}, l.errorf(pos, "invalid unicode escape")
+				}
+				b.WriteRune(r)
+				i += 4
+			default:
+				return token{}, l.errorf(pos, "invalid escape \\%c", esc)
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return token{}, l.errorf(pos, "unterminated string")
+}
+
+func (l *lexer) blockString(pos position) (token, error) {
+	end := strings.Index(l.src[l.i+3:], `"""`)
+	if end < 0 {
+		return token{}, l.errorf(pos, "unterminated block string")
+	}
+	raw := l.src[l.i+3 : l.i+3+end]
+	l.advance(end + 6)
+	return token{kind: tokenString, value: blockStringValue(raw), pos: pos}, nil
+}
+
+// blockStringValue removes the common indentation and surrounding blank lines of a block string
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
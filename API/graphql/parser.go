@@ -0,0 +1,439 @@
+package graphql
+
+import "fmt"
+
+// parser builds a document from lexer tokens with one token of lookahead
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// parse parses an executable GraphQL document
+func parse(src string) (*document, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", selection: sel})
+		case p.tok.kind == tokenName && (p.tok.value == "query" || p.tok.value == "mutation" || p.tok.value == "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.tok.kind == tokenName && p.tok.value == "fragment":
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := doc.fragments[frag.name]; exists {
+				return nil, p.lex.errorf(p.tok.pos, "fragment %q is defined more than once", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, p.lex.errorf(p.tok.pos, "document contains no operations")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// peek reports whether the current token is the punctuator s
+func (p *parser) peek(s string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.value == s
+}
+
+// expect consumes the punctuator s
+func (p *parser) expect(s string) error {
+	if !p.peek(s) {
+		return p.lex.errorf(p.tok.pos, "expected %q, found %s", s, p.describe())
+	}
+	return p.advance()
+}
+
+// skip consumes the punctuator s if it is next, reporting whether it was
+func (p *parser) skip(s string) (bool, error) {
+	if !p.peek(s) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.lex.errorf(p.tok.pos, "expected a name, found %s", p.describe())
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) keyword(word string) error {
+	if p.tok.kind != tokenName || p.tok.value != word {
+		return p.lex.errorf(p.tok.pos, "expected %q, found %s", word, p.describe())
+	}
+	return p.advance()
+}
+
+func (p *parser) unexpected() error {
+	return p.lex.errorf(p.tok.pos, "unexpected %s", p.describe())
+}
+
+func (p *parser) describe() string {
+	switch p.tok.kind {
+	case tokenEOF:
+		return "end of document"
+	case tokenString:
+		return fmt.Sprintf("string %q", p.tok.value)
+	default:
+		return fmt.Sprintf("%q", p.tok.value)
+	}
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: p.tok.value}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(")") {
+			def, err := p.variableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Operation directives are parsed but have no effect
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selection = sel
+	return op, nil
+}
+
+func (p *parser) variableDefinition() (*variableDefinition, error) {
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.typeRef()
+	if err != nil {
+		return nil, err
+	}
+
+	def := &variableDefinition{name: name, typ: typ}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if def.defaultValue, err = p.value(true); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (p *parser) typeRef() (typeRef, error) {
+	var t typeRef
+	if ok, err := p.skip("["); err != nil {
+		return t, err
+	} else if ok {
+		of, err := p.typeRef()
+		if err != nil {
+			return t, err
+		}
+		if err := p.expect("]"); err != nil {
+			return t, err
+		}
+		t.list = &of
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return t, err
+		}
+		t.name = name
+	}
+
+	nonNull, err := p.skip("!")
+	t.nonNull = nonNull
+	return t, err
+}
+
+func (p *parser) fragment() (*fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, p.lex.errorf(p.tok.pos, `a fragment cannot be named "on"`)
+	}
+	if err := p.keyword("on"); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	directives, err := p.directives()
+	if err != nil {
+		return nil, err
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, typeCondition: typeCondition, directives: directives, selection: sel}, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for !p.peek("}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	if len(selections) == 0 {
+		return nil, p.lex.errorf(p.tok.pos, "selection set cannot be empty")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (selection, error) {
+	if p.peek("...") {
+		return p.fragmentSelection()
+	}
+
+	f := &field{pos: p.tok.pos}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	f.name = name
+
+	if f.arguments, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if f.selection, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) fragmentSelection() (selection, error) {
+	pos := p.tok.pos
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	// "... Name" is a spread, unless the name is the "on" of an inline fragment
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		name := p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		directives, err := p.directives()
+		if err != nil {
+			return nil, err
+		}
+		return &fragmentSpread{name: name, directives: directives, pos: pos}, nil
+	}
+
+	inline := &inlineFragment{}
+	if p.tok.kind == tokenName {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		typeCondition, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		inline.typeCondition = typeCondition
+	}
+	var err error
+	if inline.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if inline.selection, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return inline, nil
+}
+
+func (p *parser) arguments() ([]*argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	var args []*argument
+	for !p.peek(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &argument{name: name, value: v})
+	}
+	if len(args) == 0 {
+		return nil, p.lex.errorf(p.tok.pos, "argument list cannot be empty")
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var directives []*directive
+	for p.peek("@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &directive{name: name, arguments: args})
+	}
+	return directives, nil
+}
+
+// value parses a value literal. Variables are not allowed in constant contexts such as defaults.
+func (p *parser) value(constant bool) (*value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		return &value{kind: intValue, raw: tok.value}, p.advance()
+	case tokenFloat:
+		return &value{kind: floatValue, raw: tok.value}, p.advance()
+	case tokenString:
+		return &value{kind: stringValue, raw: tok.value}, p.advance()
+	case tokenName:
+		kind := enumValue
+		switch tok.value {
+		case "true", "false":
+			kind = booleanValue
+		case "null":
+			kind = nullValue
+		}
+		return &value{kind: kind, raw: tok.value}, p.advance()
+	}
+
+	switch {
+	case p.peek("$") && !constant:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		return &value{kind: variableValue, raw: name}, nil
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		v := &value{kind: listValue}
+		for !p.peek("]") {
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			v.list = append(v.list, item)
+		}
+		return v, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		v := &value{kind: objectValue}
+		for !p.peek("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			fv, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			v.fields = append(v.fields, &objectField{name: name, value: fv})
+		}
+		return v, p.advance()
+	}
+	return nil, p.unexpected()
+}
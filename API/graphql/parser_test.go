@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	doc, err := parse(`
+		query Companies($name: String!, $sizes: [Int!] = [1, 2]) {
+			first: node(name: $name) @include(if: true) {
+				...Names
+				... on Node { size }
+			}
+		}
+		fragment Names on Node { name child { name } }
+	`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if len(doc.operations) != 1 {
+		t.Fatalf("got %d operations, want 1", len(doc.operations))
+	}
+	op := doc.operations[0]
+	if op.kind != "query" || op.name != "Companies" {
+		t.Errorf("operation = %s %s, want query Companies", op.kind, op.name)
+	}
+
+	if len(op.variables) != 2 {
+		t.Fatalf("got %d variables, want 2", len(op.variables))
+	}
+	if got := op.variables[0].typ.String(); got != "String!" {
+		t.Errorf("$name type = %s, want String!", got)
+	}
+	sizes := op.variables[1]
+	if got := sizes.typ.String(); got != "[Int!]" {
+		t.Errorf("$sizes type = %s, want [Int!]", got)
+	}
+	if sizes.defaultValue == nil || sizes.defaultValue.kind != listValue || len(sizes.defaultValue.list) != 2 {
+		t.Errorf("$sizes default = %+v, want a list of 2", sizes.defaultValue)
+	}
+
+	node, ok := op.selection[0].(*field)
+	if !ok {
+		t.Fatalf("selection is %T, want a field", op.selection[0])
+	}
+	if node.alias != "first" || node.name != "node" || node.responseKey() != "first" {
+		t.Errorf("field = %s: %s, want first: node", node.alias, node.name)
+	}
+	if len(node.arguments) != 1 || node.arguments[0].value.kind != variableValue || node.arguments[0].value.raw != "name" {
+		t.Errorf("arguments = %+v, want name: $name", node.arguments)
+	}
+	if len(node.directives) != 1 || node.directives[0].name != "include" {
+		t.Errorf("directives = %+v, want @include", node.directives)
+	}
+	if spread, ok := node.selection[0].(*fragmentSpread); !ok || spread.name != "Names" {
+		t.Errorf("selection[0] = %+v, want ...Names", node.selection[0])
+	}
+	if inline, ok := node.selection[1].(*inlineFragment); !ok || inline.typeCondition != "Node" {
+		t.Errorf("selection[1] = %+v, want ... on Node", node.selection[1])
+	}
+
+	frag := doc.fragments["Names"]
+	if frag == nil || frag.typeCondition != "Node" || len(frag.selection) != 2 {
+		t.Errorf("fragment Names = %+v, want 2 fields on Node", frag)
+	}
+}
+
+func TestParseShorthandQuery(t *testing.T) {
+	doc, err := parse(`{ node(name: "a") { name } }`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(doc.operations) != 1 || doc.operations[0].kind != "query" || doc.operations[0].name != "" {
+		t.Errorf("operations = %+v, want one anonymous query", doc.operations)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	doc, err := parse(`{ f(i: -12, f: 1.5e3, s: "a\né", b: false, n: null, e: ACTIVE, l: [1, "x"], o: {k: 1}) }`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	args := doc.operations[0].selection[0].(*field).arguments
+	want := []struct {
+		name string
+		kind valueKind
+		raw  string
+	}{
+		{"i", intValue, "-12"},
+		{"f", floatValue, "1.5e3"},
+		{"s", stringValue, "a\né"},
+		{"b", booleanValue, "false"},
+		{"n", nullValue, "null"},
+		{"e", enumValue, "ACTIVE"},
+		{"l", listValue, ""},
+		{"o", objectValue, ""},
+	}
+	if len(args) != len(want) {
+		t.Fatalf("got %d arguments, want %d", len(args), len(want))
+	}
+	for i, w := range want {
+		got := args[i]
+		if got.name != w.name || got.value.kind != w.kind || (w.raw != "" && got.value.raw != w.raw) {
+			t.Errorf("argument %d = %s %d %q, want %s %d %q", i, got.name, got.value.kind, got.value.raw, w.name, w.kind, w.raw)
+		}
+	}
+}
+
+func TestParseSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"empty", ``, "no operations"},
+		{"only fragments", `fragment F on Node { name }`, "no operations"},
+		{"unclosed selection", `{ node(name: "a") { name }`, ""},
+		{"unterminated string", `{ node(name: "a) { name } }`, "unterminated string"},
+		{"bad escape", `{ node(name: "\q") { name } }`, "invalid escape"},
+		{"bad number", `{ node(size: 1.) { name } }`, "invalid number"},
+		{"bad character", `{ node # comment` + "\n" + `% }`, "unexpected character"},
+		{"duplicate fragment", `{ a } fragment F on Node { name } fragment F on Node { size }`, "more than once"},
+		{"variable in default", `query($a: Int = $b) { a }`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.query)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("parse(%q) error = %v, want a SyntaxError", tt.query, err)
+			}
+			if !strings.Contains(syntaxErr.Message, tt.want) {
+				t.Errorf("message = %q, want it to contain %q", syntaxErr.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	_, err := parse("{\n  node(name: \"a\") {\n    %\n  }\n}")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("error = %v, want a SyntaxError", err)
+	}
+	if syntaxErr.Pos != (position{Line: 3, Column: 5}) {
+		t.Errorf("position = %+v, want line 3 column 5", syntaxErr.Pos)
+	}
+}
+
+func TestBlockStringValue(t *testing.T) {
+	raw := "\n    Hello,\n      World!\n\n    Bye\n  "
+	if got, want := blockStringValue(raw), "Hello,\n  World!\n\nBye"; got != want {
+		t.Errorf("blockStringValue = %q, want %q", got, want)
+	}
+}
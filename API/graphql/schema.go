@@ -0,0 +1,475 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"data-co/api/companieshouse"
+	"data-co/api/database"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// maxCompanies caps the companies returned by one companies query
+const maxCompanies = 100
+
+// NewSchema builds the API schema: companies with their officers, financials, filings, PSCs,
+// charges and insolvency, searchable with the same filters as POST /api/companies/search
+func NewSchema(db *database.DB) (*Schema, error) {
+	repo := db.Companies()
+	near := &InputObject{
+		Name:        "NearFilter",
+		Description: "A radius around a postcode, or around a point given by lat and lng",
+		Fields: []*Argument{
+			{Name: "postcode", Type: String},
+			{Name: "lat", Type: Float},
+			{Name: "lng", Type: Float},
+			{Name: "radius_km", Type: Float, Description: "Default 10, max 500"},
+		},
+	}
+
+	jsonValue := &Scalar{
+		Name:        "JSON",
+		Description: "Any JSON value: a string, number, boolean or list.",
+		Serialize:   func(v any) (any, error) { return v, nil },
+		Parse:       func(v any) (any, error) { return v, nil },
+	}
+	whereClause := &InputObject{
+		Name:        "WhereClause",
+		Description: "A comparison of a field with a value, as in the where list of the search body",
+		Fields: []*Argument{
+			{Name: "field", Type: &NonNull{String}},
+			{Name: "op", Type: &NonNull{String}},
+			{Name: "value", Type: jsonValue},
+		},
+	}
+
+	companyFilter := &InputObject{
+		Name:        "CompanyFilter",
+		Description: "Search filters, with the same names and values as the POST /api/companies/search body. companyStatus defaults to active.",
+		Fields: []*Argument{
+			{Name: "industry", Type: String},
+			{Name: "sic_section", Type: String, Description: "SIC 2007 section letters, e.g. J"},
+			{Name: "sic_division", Type: String, Description: "2-digit SIC divisions, e.g. 62"},
+			{Name: "sic_class", Type: String, Description: "4-digit SIC classes or 5-digit codes, e.g. 62020"},
+			{Name: "location", Type: String},
+			{Name: "country", Type: String, Description: "UK countries, e.g. scotland"},
+			{Name: "uk_region", Type: String, Description: "UK regions, e.g. north-west"},
+			{Name: "locality", Type: String, Description: "Post towns, e.g. Salford"},
+			{Name: "revenue", Type: String},
+			{Name: "employees", Type: String},
+			{Name: "employee_count", Type: String},
+			{Name: "officer_count", Type: String},
+			{Name: "profitability", Type: String},
+			{Name: "profitable_years", Type: Int},
+			{Name: "consistent_growth", Type: Boolean},
+			{Name: "cash_min", Type: Float, Description: "In GBP"},
+			{Name: "current_ratio_min", Type: Float},
+			{Name: "fixed_assets_min", Type: Float, Description: "In GBP"},
+			{Name: "fixed_assets_max", Type: Float, Description: "In GBP"},
+			{Name: "intangible_assets_min", Type: Float, Description: "In GBP"},
+			{Name: "intangible_assets_max", Type: Float, Description: "In GBP"},
+			{Name: "shareholder_funds_min", Type: Float, Description: "In GBP"},
+			{Name: "shareholder_funds_max", Type: Float, Description: "In GBP"},
+			{Name: "companySize", Type: String},
+			{Name: "companyAge", Type: String},
+			{Name: "companyStatus", Type: String},
+			{Name: "netAssets", Type: String},
+			{Name: "debtLevel", Type: String},
+			{Name: "searchTerm", Type: String},
+			{Name: "revenue_growth", Type: String},
+			{Name: "health", Type: String},
+			{Name: "risk_band", Type: String},
+			{Name: "psc_type", Type: String},
+			{Name: "has_outstanding_charges", Type: Boolean},
+			{Name: "in_administration", Type: Boolean},
+			{Name: "in_liquidation", Type: Boolean},
+			{Name: "has_insolvency_history", Type: Boolean},
+			{Name: "postcode_area", Type: String},
+			{Name: "postcode_district", Type: String},
+			{Name: "address_contains", Type: String},
+			{Name: "company_type", Type: String},
+			{Name: "accounts_category", Type: String},
+			{Name: "accounts_overdue", Type: Boolean},
+			{Name: "accounts_due_within_days", Type: Int},
+			{Name: "confirmation_statement_overdue", Type: Boolean},
+			{Name: "dissolved_from", Type: String, Description: "YYYY-MM-DD"},
+			{Name: "dissolved_to", Type: String, Description: "YYYY-MM-DD"},
+			{Name: "vat_registered", Type: Boolean},
+			{Name: "has_website", Type: Boolean},
+			{Name: "exclude_dormant", Type: Boolean},
+			{Name: "near", Type: near},
+			{Name: "where", Type: &List{&NonNull{whereClause}}},
+		},
+	}
+	// and/or groups nest further filters, so they are added once the type exists
+	companyFilter.Fields = append(companyFilter.Fields,
+		&Argument{Name: "and", Type: &List{&NonNull{companyFilter}}, Description: "Each entry must match"},
+		&Argument{Name: "or", Type: &List{&NonNull{companyFilter}}, Description: "At least one entry must match"},
+	)
+
+	officer := &Object{
+		Name:        "Officer",
+		Description: "A director, secretary or other officer appointment",
+		Fields: []*Field{
+			{Name: "id", Type: &NonNull{Int}},
+			{Name: "name", Type: String},
+			{Name: "role", Type: String},
+			{Name: "appointed_on", Type: String},
+			{Name: "resigned_on", Type: String},
+			{Name: "active", Type: &NonNull{Boolean}},
+			{Name: "nationality", Type: String},
+			{Name: "date_of_birth", Type: String, Description: "Month of birth, YYYY-MM"},
+			{Name: "address_line_1", Type: String},
+			{Name: "locality", Type: String},
+			{Name: "postal_code", Type: String},
+			{Name: "country", Type: String},
+		},
+	}
+
+	financialPeriod := &Object{
+		Name:        "FinancialPeriod",
+		Description: "Headline figures from one set of accounts, in the currency they report",
+		Fields: []*Field{
+			{Name: "period_start", Type: String},
+			{Name: "period_end", Type: &NonNull{String}},
+			{Name: "currency", Type: &NonNull{String}, Description: "Of the figures, e.g. GBP"},
+			{Name: "gbp_rate", Type: Float, Description: "GBP value of one unit of currency at period_end; null until known"},
+			{Name: "turnover", Type: Float},
+			{Name: "gross_profit", Type: Float},
+			{Name: "operating_profit", Type: Float},
+			{Name: "profit_after_tax", Type: Float},
+			{Name: "total_assets", Type: Float},
+			{Name: "fixed_assets", Type: Float},
+			{Name: "intangible_assets", Type: Float},
+			{Name: "current_assets", Type: Float},
+			{Name: "current_liabilities", Type: Float},
+			{Name: "total_liabilities", Type: Float},
+			{Name: "net_worth", Type: Float},
+			{Name: "shareholder_funds", Type: Float},
+			{Name: "cash", Type: Float},
+			{Name: "employees", Type: Int},
+		},
+	}
+
+	filing := &Object{
+		Name:        "Filing",
+		Description: "A filing ingested from Companies House. Only accounts filings are ingested.",
+		Fields: []*Field{
+			{Name: "category", Type: &NonNull{String}},
+			{Name: "made_up_to", Type: &NonNull{String}},
+			{Name: "title", Type: String},
+			{Name: "source", Type: String},
+			{Name: "ingested_at", Type: String},
+		},
+	}
+
+	psc := &Object{
+		Name:        "PSC",
+		Description: "A person with significant control, or a PSC statement",
+		Fields: []*Field{
+			{Name: "id", Type: &NonNull{String}},
+			{Name: "kind", Type: &NonNull{String}},
+			{Name: "psc_type", Type: &NonNull{String}},
+			{Name: "name", Type: String},
+			{Name: "nationality", Type: String},
+			{Name: "country_of_residence", Type: String},
+			{Name: "date_of_birth", Type: String, Description: "Month of birth, YYYY-MM"},
+			{Name: "natures_of_control", Type: &List{&NonNull{String}}},
+			{Name: "notified_on", Type: String},
+			{Name: "ceased_on", Type: String},
+			{Name: "statement", Type: String},
+			{Name: "legal_form", Type: String},
+			{Name: "registration_number", Type: String},
+			{Name: "address_line_1", Type: String},
+			{Name: "locality", Type: String},
+			{Name: "postal_code", Type: String},
+			{Name: "country", Type: String},
+		},
+	}
+
+	charge := &Object{
+		Name:        "Charge",
+		Description: "A mortgage or other security registered against a company",
+		Fields: []*Field{
+			{Name: "id", Type: &NonNull{String}},
+			{Name: "charge_code", Type: String},
+			{Name: "charge_number", Type: Int},
+			{Name: "classification", Type: String},
+			{Name: "status", Type: String},
+			{Name: "outstanding", Type: &NonNull{Boolean}},
+			{Name: "created_on", Type: String},
+			{Name: "delivered_on", Type: String},
+			{Name: "satisfied_on", Type: String},
+			{Name: "persons_entitled", Type: &List{&NonNull{String}}},
+			{Name: "particulars", Type: String},
+		},
+	}
+
+	insolvencyDate := &Object{
+		Name: "InsolvencyDate",
+		Fields: []*Field{
+			{Name: "type", Type: &NonNull{String}},
+			{Name: "date", Type: &NonNull{String}},
+		},
+	}
+
+	insolvencyCase := &Object{
+		Name: "InsolvencyCase",
+		Fields: []*Field{
+			{Name: "case_number", Type: &NonNull{Int}},
+			{Name: "case_type", Type: &NonNull{String}},
+			{Name: "open", Type: &NonNull{Boolean}},
+			{Name: "started_on", Type: String},
+			{Name: "ended_on", Type: String},
+			{Name: "dates", Type: &List{&NonNull{insolvencyDate}}},
+			{Name: "practitioners", Type: &List{&NonNull{String}}},
+		},
+	}
+
+	insolvency := &Object{
+		Name: "InsolvencySummary",
+		Fields: []*Field{
+			{Name: "in_administration", Type: &NonNull{Boolean}},
+			{Name: "in_liquidation", Type: &NonNull{Boolean}},
+			{Name: "has_insolvency_history", Type: &NonNull{Boolean}},
+			{Name: "cases", Type: &List{&NonNull{insolvencyCase}}},
+		},
+	}
+
+	company := &Object{
+		Name:        "Company",
+		Description: "A company, with its latest financials and scores",
+		Fields: []*Field{
+			{Name: "company_number", Type: &NonNull{String}},
+			{Name: "company_name", Type: &NonNull{String}},
+			{Name: "company_status", Type: &NonNull{String}},
+			{Name: "company_type", Type: String, Description: "As published, e.g. Private Limited Company"},
+			{Name: "locality", Type: String},
+			{Name: "region", Type: String, Description: "County, as in the address"},
+			{Name: "country", Type: String, Description: "UK country of the registered office, e.g. Scotland"},
+			{Name: "uk_region", Type: String, Description: "UK region of the registered office, e.g. North West"},
+			{Name: "postal_code", Type: String},
+			{Name: "latitude", Type: Float, Description: "Of the postcode, when geocoded"},
+			{Name: "longitude", Type: Float, Description: "Of the postcode, when geocoded"},
+			{Name: "primary_sic_code", Type: String},
+			{Name: "incorporation_date", Type: String},
+			{Name: "dissolved_on", Type: String},
+			{Name: "turnover", Type: Float},
+			{Name: "profit_after_tax", Type: Float},
+			{Name: "total_assets", Type: Float},
+			{Name: "net_worth", Type: Float},
+			{Name: "cash", Type: Float, Description: "Cash at bank and in hand"},
+			{Name: "current_assets", Type: Float},
+			{Name: "current_liabilities", Type: Float, Description: "Creditors due within one year"},
+			{Name: "current_ratio", Type: Float, Description: "current_assets over current_liabilities"},
+			{Name: "fixed_assets", Type: Float},
+			{Name: "intangible_assets", Type: Float, Description: "Part of fixed_assets"},
+			{Name: "shareholder_funds", Type: Float, Description: "Total equity, as reported on the balance sheet"},
+			{Name: "accounts_currency", Type: String, Description: "Reporting currency of the latest accounts; turnover to shareholder_funds are converted to GBP"},
+			{Name: "turnover_original", Type: Float, Description: "In accounts_currency, as reported"},
+			{Name: "profit_after_tax_original", Type: Float, Description: "In accounts_currency, as reported"},
+			{Name: "total_assets_original", Type: Float, Description: "In accounts_currency, as reported"},
+			{Name: "net_worth_original", Type: Float, Description: "In accounts_currency, as reported"},
+			{Name: "latest_accounts_date", Type: String},
+			{Name: "accounts_category", Type: String, Description: "Type of the last accounts, as published, e.g. MICRO ENTITY"},
+			{Name: "dormant", Type: &NonNull{Boolean}, Description: "Filed dormant accounts, declares itself dormant or shows no trading"},
+			{Name: "next_accounts_due", Type: String},
+			{Name: "confirmation_statement_last_made_up_to", Type: String},
+			{Name: "confirmation_statement_next_due", Type: String},
+			{Name: "active_officers_count", Type: &NonNull{Int}},
+			{Name: "employee_count", Type: Int, Description: "Average over the latest accounts' period, as they report it"},
+			{Name: "profitable_years", Type: Int, Description: "Latest financial periods in a row with a profit after tax"},
+			{Name: "growth_years", Type: Int, Description: "Latest financial periods in a row with turnover above the period before's"},
+			{Name: "health_score", Type: Float},
+			{Name: "health", Type: String, Description: "strong, moderate or weak"},
+			{Name: "risk_band", Type: String, Description: "low, medium or high"},
+			{Name: "risk_flags", Type: &List{&NonNull{String}}},
+			{Name: "vat_number", Type: String, Description: "Without the GB prefix"},
+			{Name: "vat_registered", Type: Boolean, Description: "As last checked with HMRC; null until checked"},
+			{Name: "domain", Type: String, Description: "Of the company's website, when one was matched"},
+			{Name: "matched_on", Type: String, Description: "name or previous_name, when the filter has a searchTerm"},
+			{
+				Name:        "officers",
+				Description: "Officer appointments, current ones first",
+				Type:        &NonNull{&List{&NonNull{officer}}},
+				Args:        []*Argument{{Name: "active_only", Type: Boolean, Default: false}},
+				Resolve: func(p ResolveParams) (any, error) {
+					officers, err := repo.Officers(p.Context, source(p).CompanyNumber)
+					if err != nil {
+						return nil, err
+					}
+					if p.Args["active_only"] == true {
+						active := make([]models.Officer, 0, len(officers))
+						for _, o := range officers {
+							if o.Active {
+								active = append(active, o)
+							}
+						}
+						officers = active
+					}
+					usage.AddRows(p.Context, len(officers))
+					return officers, nil
+				},
+			},
+			{
+				Name:        "financials",
+				Description: "Financial history, most recent period first",
+				Type:        &NonNull{&List{&NonNull{financialPeriod}}},
+				Args:        []*Argument{{Name: "limit", Type: Int, Description: "Return at most this many periods"}},
+				Resolve: func(p ResolveParams) (any, error) {
+					periods, err := repo.Financials(p.Context, source(p).CompanyNumber)
+					if err != nil {
+						return nil, err
+					}
+					if limit, ok := p.Args["limit"].(int); ok && limit >= 0 && limit < len(periods) {
+						periods = periods[:limit]
+					}
+					usage.AddRows(p.Context, len(periods))
+					return periods, nil
+				},
+			},
+			{
+				Name:        "filings",
+				Description: "Accounts filings, most recent first",
+				Type:        &NonNull{&List{&NonNull{filing}}},
+				Resolve: func(p ResolveParams) (any, error) {
+					filings, err := db.ListCompanyFilings(p.Context, source(p).CompanyNumber)
+					if err != nil {
+						return nil, err
+					}
+					usage.AddRows(p.Context, len(filings))
+					return filings, nil
+				},
+			},
+			{
+				Name:        "pscs",
+				Description: "Persons with significant control and PSC statements, current ones first",
+				Type:        &NonNull{&List{&NonNull{psc}}},
+				Resolve: func(p ResolveParams) (any, error) {
+					pscs, err := db.ListCompanyPSCs(p.Context, source(p).CompanyNumber)
+					if err != nil {
+						return nil, err
+					}
+					usage.AddRows(p.Context, len(pscs))
+					return pscs, nil
+				},
+			},
+			{
+				Name:        "charges",
+				Description: "Registered charges, most recently created first",
+				Type:        &NonNull{&List{&NonNull{charge}}},
+				Resolve: func(p ResolveParams) (any, error) {
+					charges, err := db.ListCompanyCharges(p.Context, source(p).CompanyNumber)
+					if err != nil {
+						return nil, err
+					}
+					usage.AddRows(p.Context, len(charges))
+					return charges, nil
+				},
+			},
+			{
+				Name: "insolvency",
+				Type: &NonNull{insolvency},
+				Resolve: func(p ResolveParams) (any, error) {
+					c := source(p)
+					return db.GetCompanyInsolvency(p.Context, c.CompanyNumber, c.CompanyStatus)
+				},
+			},
+		},
+	}
+
+	query := &Object{
+		Name: "Query",
+		Fields: []*Field{
+			{
+				Name: "company",
+				Type: company,
+				Args: []*Argument{{Name: "company_number", Type: &NonNull{String}}},
+				Resolve: func(p ResolveParams) (any, error) {
+					c, err := repo.Get(p.Context, companieshouse.NormalizeCompanyNumber(p.Args["company_number"].(string)))
+					if err != nil || c == nil {
+						return nil, err
+					}
+					usage.AddRows(p.Context, 1)
+					return c, nil
+				},
+			},
+			{
+				Name:        "companies",
+				Description: fmt.Sprintf("Companies matching filter. At most %d are returned per query.", maxCompanies),
+				Type:        &NonNull{&List{&NonNull{company}}},
+				Args: []*Argument{
+					{Name: "filter", Type: companyFilter},
+					{Name: "limit", Type: Int, Default: 20},
+					{Name: "offset", Type: Int, Default: 0},
+					{Name: "orderBy", Type: String, Description: "The same sort keys as the REST search, e.g. turnover"},
+				},
+				Resolve: func(p ResolveParams) (any, error) {
+					filters, err := searchFilters(p.Args["filter"])
+					if err != nil {
+						return nil, err
+					}
+					filters.Limit, _ = p.Args["limit"].(int)
+					filters.Offset, _ = p.Args["offset"].(int)
+					filters.OrderBy, _ = p.Args["orderBy"].(string)
+					if filters.Limit < 1 || filters.Limit > maxCompanies {
+						return nil, fmt.Errorf("limit must be between 1 and %d", maxCompanies)
+					}
+					if filters.Offset < 0 {
+						return nil, fmt.Errorf("offset must not be negative")
+					}
+
+					companies, err := repo.Search(p.Context, filters)
+					if err != nil {
+						return nil, err
+					}
+					usage.AddRows(p.Context, len(companies))
+					return companies, nil
+				},
+			},
+			{
+				Name:        "companyCount",
+				Description: "The number of companies matching filter",
+				Type:        &NonNull{Int},
+				Args:        []*Argument{{Name: "filter", Type: companyFilter}},
+				Resolve: func(p ResolveParams) (any, error) {
+					filters, err := searchFilters(p.Args["filter"])
+					if err != nil {
+						return nil, err
+					}
+					return repo.Count(p.Context, filters)
+				},
+			},
+		},
+	}
+
+	return newSchema(query)
+}
+
+// source returns the company a Company field is resolved on
+func source(p ResolveParams) models.Company {
+	return unwrap(p.Source).(models.Company)
+}
+
+// searchFilters converts a CompanyFilter argument to search filters. The input fields share
+// their names with the JSON search body, so the conversion goes through JSON.
+func searchFilters(arg any) (models.CompanySearchFilters, error) {
+	var filters models.CompanySearchFilters
+	if arg != nil {
+		data, err := json.Marshal(arg)
+		if err != nil {
+			return filters, fmt.Errorf("invalid filter: %w", err)
+		}
+		if err := json.Unmarshal(data, &filters); err != nil {
+			return filters, fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+	if filters.CompanyStatus == "" {
+		filters.CompanyStatus = "active"
+	}
+	if err := database.InvalidFiltersError(database.ValidateFilters(filters)); err != nil {
+		return filters, fmt.Errorf("invalid filter: %w", err)
+	}
+	return filters, nil
+}
@@ -0,0 +1,81 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SDL returns the schema in the GraphQL schema definition language, for clients to generate
+// types from since introspection is not supported
+func (s *Schema) SDL() string {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n")
+	for _, name := range names {
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			if t == String || t == Int || t == Float || t == Boolean {
+				continue
+			}
+			b.WriteString("\n")
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "scalar %s\n", t.Name)
+		case *Object:
+			b.WriteString("\n")
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "type %s {\n", t.Name)
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				fmt.Fprintf(&b, "  %s%s: %s\n", f.Name, formatArgs(f.Args), f.Type)
+			}
+			b.WriteString("}\n")
+		case *InputObject:
+			b.WriteString("\n")
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "input %s {\n", t.Name)
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				fmt.Fprintf(&b, "  %s\n", formatArg(f))
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+func formatArgs(args []*Argument) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = formatArg(arg)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func formatArg(arg *Argument) string {
+	s := arg.Name + ": " + arg.Type.String()
+	if arg.Default != nil {
+		switch d := arg.Default.(type) {
+		case string:
+			s += fmt.Sprintf(" = %q", d)
+		default:
+			s += fmt.Sprintf(" = %v", d)
+		}
+	}
+	return s
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s%q\n", indent, description)
+}
@@ -0,0 +1,306 @@
+package graphql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Type is a GraphQL type: *Scalar, *Object, *InputObject, *List or *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved value to its JSON form
+	Serialize func(v any) (any, error)
+	// Parse converts an input value (nil, bool, string, int64, float64) to the Go value
+	// resolvers receive
+	Parse func(v any) (any, error)
+}
+
+// Object is an output type with fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+
+	once   sync.Once
+	byName map[string]*Field
+}
+
+// Field is a field of an object type. Fields without a resolver read the property of the
+// source value with the same JSON name.
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	Resolve     ResolveFunc
+}
+
+// Argument is a field argument. A nil Default means the argument has no default.
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type
+	Default     any
+}
+
+// InputObject is an input type with fields, such as a filter
+type InputObject struct {
+	Name        string
+	Description string
+	Fields      []*Argument
+}
+
+// List is a list of another type
+type List struct {
+	OfType Type
+}
+
+// NonNull is a non-null form of another type
+type NonNull struct {
+	OfType Type
+}
+
+func (t *Scalar) String() string      { return t.Name }
+func (t *Object) String() string      { return t.Name }
+func (t *InputObject) String() string { return t.Name }
+func (t *List) String() string        { return "[" + t.OfType.String() + "]" }
+func (t *NonNull) String() string     { return t.OfType.String() + "!" }
+
+// field returns the named field, or nil
+func (t *Object) field(name string) *Field {
+	t.once.Do(func() {
+		t.byName = make(map[string]*Field, len(t.Fields))
+		for _, f := range t.Fields {
+			t.byName[f.Name] = f
+		}
+	})
+	return t.byName[name]
+}
+
+// ResolveParams are passed to field resolvers
+type ResolveParams struct {
+	Context context.Context
+	// Source is the value of the parent object, or nil for root fields
+	Source any
+	// Args are the coerced field arguments. Arguments that were not given and have no default
+	// are absent.
+	Args map[string]any
+}
+
+// ResolveFunc resolves a field's value
+type ResolveFunc func(p ResolveParams) (any, error)
+
+// namedType strips list and non-null wrappers
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.OfType
+		case *NonNull:
+			t = w.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// Built-in scalars
+var (
+	String = &Scalar{
+		Name:        "String",
+		Description: "UTF-8 text. Dates and times are RFC 3339.",
+		Serialize: func(v any) (any, error) {
+			switch s := v.(type) {
+			case string:
+				return s, nil
+			case time.Time:
+				return s.Format(time.RFC3339), nil
+			case []byte:
+				return string(s), nil
+			case fmt.Stringer:
+				return s.String(), nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as String", v)
+		},
+		Parse: func(v any) (any, error) {
+			if s, ok := v.(string); ok {
+				return s, nil
+			}
+			return nil, fmt.Errorf("expected a String, got %s", describeInput(v))
+		},
+	}
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "A signed 32-bit integer.",
+		Serialize: func(v any) (any, error) {
+			rv := reflect.ValueOf(v)
+			switch rv.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return rv.Int(), nil
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return rv.Uint(), nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as Int", v)
+		},
+		Parse: func(v any) (any, error) {
+			switch n := v.(type) {
+			case int:
+				// Already coerced, e.g. a variable inside a list literal
+				return n, nil
+			case int64:
+				if n >= math.MinInt32 && n <= math.MaxInt32 {
+					return int(n), nil
+				}
+			case float64:
+				if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+					return int(n), nil
+				}
+			}
+			return nil, fmt.Errorf("expected an Int, got %s", describeInput(v))
+		},
+	}
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "A double-precision number.",
+		Serialize: func(v any) (any, error) {
+			rv := reflect.ValueOf(v)
+			switch rv.Kind() {
+			case reflect.Float32, reflect.Float64:
+				return rv.Float(), nil
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return float64(rv.Int()), nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as Float", v)
+		},
+		Parse: func(v any) (any, error) {
+			switch n := v.(type) {
+			case int64:
+				return float64(n), nil
+			case float64:
+				return n, nil
+			}
+			return nil, fmt.Errorf("expected a Float, got %s", describeInput(v))
+		},
+	}
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "true or false.",
+		Serialize: func(v any) (any, error) {
+			if b, ok := v.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("cannot serialize %T as Boolean", v)
+		},
+		Parse: func(v any) (any, error) {
+			if b, ok := v.(bool); ok {
+				return b, nil
+			}
+			return nil, fmt.Errorf("expected a Boolean, got %s", describeInput(v))
+		},
+	}
+)
+
+func describeInput(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	case enumLiteral:
+		return string(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// enumLiteral is an unquoted name in a document, which no input type here accepts
+type enumLiteral string
+
+// unwrap dereferences pointers and unpacks sql.Null* and other driver.Valuer values, returning
+// nil for missing values
+func unwrap(v any) any {
+	for v != nil {
+		if valuer, ok := v.(driver.Valuer); ok {
+			value, err := valuer.Value()
+			if err != nil || value == nil {
+				return nil
+			}
+			v = value
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Interface:
+			if rv.IsNil() {
+				return nil
+			}
+			v = rv.Elem().Interface()
+		case reflect.Slice, reflect.Map:
+			if rv.IsNil() {
+				return nil
+			}
+			return v
+		default:
+			return v
+		}
+	}
+	return nil
+}
+
+// jsonFields caches, per struct type, the index of each field by JSON name
+var jsonFields sync.Map // reflect.Type -> map[string][]int
+
+// defaultResolve reads the field named name from a map or from the struct field with that JSON name
+func defaultResolve(source any, name string) any {
+	source = unwrap(source)
+	if m, ok := source.(map[string]any); ok {
+		return m[name]
+	}
+
+	rv := reflect.ValueOf(source)
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	index, ok := jsonFields.Load(rv.Type())
+	if !ok {
+		index = structFields(rv.Type())
+		jsonFields.Store(rv.Type(), index)
+	}
+	if i, ok := index.(map[string][]int)[name]; ok {
+		return rv.FieldByIndex(i).Interface()
+	}
+	return nil
+}
+
+func structFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int)
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Index
+	}
+	return fields
+}
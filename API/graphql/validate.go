@@ -0,0 +1,142 @@
+package graphql
+
+import "fmt"
+
+// maxDepth caps how deeply selection sets nest, counting those expanded from fragments, so a
+// query cannot fan out through nested fields without bound
+const maxDepth = 10
+
+// validate checks an operation against the schema before anything is resolved, so a bad query
+// fails as a whole rather than field by field
+func (e *executor) validate(op *operation) []*Error {
+	v := &validator{executor: e, declared: make(map[string]bool)}
+	for _, def := range op.variables {
+		v.declared[def.name] = true
+	}
+	v.selection(e.schema.Query, op.selection, nil, 1)
+	return v.errors
+}
+
+type validator struct {
+	*executor
+	declared map[string]bool
+	errors   []*Error
+	tooDeep  bool // Reported once
+}
+
+func (v *validator) errorf(pos position, format string, args ...any) {
+	v.errors = append(v.errors, &Error{Message: fmt.Sprintf(format, args...), Locations: []position{pos}})
+}
+
+// selection validates a selection set on obj, nested depth selection sets deep. spreading holds
+// the fragments being expanded, to reject cycles.
+func (v *validator) selection(obj *Object, sel []selection, spreading []string, depth int) {
+	if depth > maxDepth {
+		if !v.tooDeep && len(sel) > 0 {
+			v.errors = append(v.errors, &Error{Message: fmt.Sprintf("query is nested more than %d levels deep", maxDepth)})
+			v.tooDeep = true
+		}
+		return
+	}
+	for _, s := range sel {
+		v.directives(s.selectionDirectives())
+		switch s := s.(type) {
+		case *field:
+			v.field(obj, s, spreading, depth)
+		case *inlineFragment:
+			if s.typeCondition != "" && s.typeCondition != obj.Name {
+				v.errors = append(v.errors, &Error{Message: fmt.Sprintf("fragment on %s cannot be spread on %s", s.typeCondition, obj.Name)})
+				continue
+			}
+			v.selection(obj, s.selection, spreading, depth)
+		case *fragmentSpread:
+			frag := v.doc.fragments[s.name]
+			if frag == nil {
+				v.errorf(s.pos, "unknown fragment %s", s.name)
+				continue
+			}
+			if frag.typeCondition != obj.Name {
+				v.errorf(s.pos, "fragment %s on %s cannot be spread on %s", s.name, frag.typeCondition, obj.Name)
+				continue
+			}
+			cycle := false
+			for _, name := range spreading {
+				cycle = cycle || name == s.name
+			}
+			if cycle {
+				v.errorf(s.pos, "fragment %s spreads itself", s.name)
+				continue
+			}
+			v.directives(frag.directives)
+			v.selection(obj, frag.selection, append(spreading, s.name), depth)
+		}
+	}
+}
+
+func (v *validator) field(obj *Object, f *field, spreading []string, depth int) {
+	if f.name == "__typename" {
+		if len(f.selection) > 0 {
+			v.errorf(f.pos, "field __typename is a String and cannot have a selection")
+		}
+		return
+	}
+
+	def := obj.field(f.name)
+	if def == nil {
+		v.errorf(f.pos, "type %s has no field %s", obj.Name, f.name)
+		return
+	}
+
+	for _, arg := range f.arguments {
+		var argDef *Argument
+		for _, a := range def.Args {
+			if a.Name == arg.name {
+				argDef = a
+			}
+		}
+		if argDef == nil {
+			v.errorf(f.pos, "field %s has no argument %s", f.name, arg.name)
+			continue
+		}
+		v.variables(arg.value, f.pos)
+	}
+
+	switch t := namedType(def.Type).(type) {
+	case *Object:
+		if len(f.selection) == 0 {
+			v.errorf(f.pos, "field %s of type %s must have a selection of subfields", f.name, def.Type)
+			return
+		}
+		v.selection(t, f.selection, spreading, depth+1)
+	default:
+		if len(f.selection) > 0 {
+			v.errorf(f.pos, "field %s of type %s cannot have a selection", f.name, def.Type)
+		}
+	}
+}
+
+func (v *validator) directives(directives []*directive) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			v.errors = append(v.errors, &Error{Message: fmt.Sprintf("unknown directive @%s", d.name)})
+		}
+	}
+}
+
+// variables checks every variable a value refers to is declared by the operation
+func (v *validator) variables(val *value, pos position) {
+	switch val.kind {
+	case variableValue:
+		if !v.declared[val.raw] {
+			v.errorf(pos, "variable $%s is not defined", val.raw)
+		}
+	case listValue:
+		for _, item := range val.list {
+			v.variables(item, pos)
+		}
+	case objectValue:
+		for _, f := range val.fields {
+			v.variables(f.value, pos)
+		}
+	}
+}
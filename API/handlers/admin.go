@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"data-co/api/database"
+	"data-co/api/models"
+	"data-co/api/ratelimit"
+)
+
+// AdminHandler handles operational HTTP requests
+type AdminHandler struct {
+	db      *database.DB
+	limiter *ratelimit.Limiter
+}
+
+// NewAdminHandler creates a new admin handler. Tiers given to keys and organizations must be
+// known to limiter.
+func NewAdminHandler(db *database.DB, limiter *ratelimit.Limiter) *AdminHandler {
+	return &AdminHandler{db: db, limiter: limiter}
+}
+
+// RefreshSummaries handles POST /api/admin/summaries/refresh
+func (h *AdminHandler) RefreshSummaries(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	if err := h.db.RefreshSearchSummaries(r.Context()); err != nil {
+		log.Printf("Summary refresh error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to refresh search summaries", err.Error())
+		return
+	}
+
+	elapsed := time.Since(start)
+	log.Printf("Refreshed search summaries in %s", elapsed)
+
+	respondWithJSON(w, http.StatusOK, models.SummaryRefreshResponse{
+		Status:     "refreshed",
+		DurationMs: elapsed.Milliseconds(),
+	})
+}
+
+// PoolStats handles GET /api/admin/pool
+func (h *AdminHandler) PoolStats(w http.ResponseWriter, r *http.Request) {
+	stat := h.db.Stat()
+
+	respondWithJSON(w, http.StatusOK, models.PoolStatsResponse{
+		MaxConns:             stat.MaxConns(),
+		TotalConns:           stat.TotalConns(),
+		IdleConns:            stat.IdleConns(),
+		AcquiredConns:        stat.AcquiredConns(),
+		AcquireCount:         stat.AcquireCount(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		AcquireDurationMs:    stat.AcquireDuration().Milliseconds(),
+	})
+}
+
+// DataStats handles GET /api/admin/stats
+func (h *AdminHandler) DataStats(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.db.WithTimeout(r.Context())
+	defer cancel()
+
+	stats, err := h.db.DataStats(ctx)
+	if err != nil {
+		log.Printf("Data stats error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to get data statistics", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, stats)
+}
+
+// DuplicateCompanies handles GET /api/admin/data-quality/duplicates
+func (h *AdminHandler) DuplicateCompanies(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", "limit must be between 1 and 1000")
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := h.db.WithTimeout(r.Context())
+	defer cancel()
+
+	duplicates, err := h.db.DuplicateCompanies(ctx, limit)
+	if err != nil {
+		log.Printf("Duplicate companies error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to find duplicate companies", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, duplicates)
+}
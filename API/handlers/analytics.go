@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"data-co/api/database"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+const (
+	// defaultTopN is how many companies a league table ranks per group by default
+	defaultTopN = 10
+	// maxTopN bounds how many companies a league table ranks per group
+	maxTopN = 100
+	// maxTrendYears is the default span of an incorporation trend, and the longest one that
+	// can be grouped by month or quarter
+	maxTrendYears = 10
+)
+
+// TopCompanies handles POST /api/analytics/top
+func (h *CompanyHandler) TopCompanies(w http.ResponseWriter, r *http.Request) {
+	var req models.TopCompaniesRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !slices.Contains(database.TopGroupings, req.GroupBy) {
+		respondWithError(w, http.StatusBadRequest, "Invalid group_by", "group_by must be one of "+strings.Join(database.TopGroupings, ", "))
+		return
+	}
+	if !slices.Contains(database.TopMetricNames, req.Metric) {
+		respondWithError(w, http.StatusBadRequest, "Invalid metric", "metric must be one of "+strings.Join(database.TopMetricNames, ", "))
+		return
+	}
+	if req.N == 0 {
+		req.N = defaultTopN
+	}
+	if req.N < 1 || req.N > maxTopN {
+		respondWithError(w, http.StatusBadRequest, "Invalid n", "n must be between 1 and 100")
+		return
+	}
+
+	// Set defaults
+	if req.Filters.CompanyStatus == "" {
+		req.Filters.CompanyStatus = "active"
+	}
+	if invalid := database.ValidateFilters(req.Filters); len(invalid) > 0 {
+		respondWithInvalidFilters(w, invalid)
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	groups, truncated, err := h.companies.Top(ctx, req.GroupBy, req.Metric, req.N, req.Filters)
+	if err != nil {
+		log.Printf("Top companies error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to rank companies", err)
+		return
+	}
+
+	rows := 0
+	for _, g := range groups {
+		rows += len(g.Companies)
+	}
+	usage.AddRows(r.Context(), rows)
+
+	respondWithJSON(w, http.StatusOK, models.TopCompaniesResponse{
+		GroupBy:         req.GroupBy,
+		Metric:          req.Metric,
+		N:               req.N,
+		Groups:          groups,
+		GroupsTruncated: truncated,
+	})
+}
+
+// AggregateCompanies handles POST /api/analytics/aggregate
+func (h *CompanyHandler) AggregateCompanies(w http.ResponseWriter, r *http.Request) {
+	var req models.AggregateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if len(req.GroupBy) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid group_by", "group_by is required")
+		return
+	}
+	for i, dimension := range req.GroupBy {
+		if !slices.Contains(database.AnalyticsDimensions, dimension) {
+			respondWithError(w, http.StatusBadRequest, "Invalid group_by", "group_by dimensions must be "+strings.Join(database.AnalyticsDimensions, ", "))
+			return
+		}
+		if slices.Contains(req.GroupBy[:i], dimension) {
+			respondWithError(w, http.StatusBadRequest, "Invalid group_by", dimension+" is listed more than once")
+			return
+		}
+	}
+
+	// Set defaults
+	if req.Filters.CompanyStatus == "" {
+		req.Filters.CompanyStatus = "active"
+	}
+	if invalid := database.ValidateFilters(req.Filters); len(invalid) > 0 {
+		respondWithInvalidFilters(w, invalid)
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	groups, total, err := h.companies.Aggregate(ctx, req.GroupBy, req.Filters)
+	if err != nil {
+		log.Printf("Aggregate error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to aggregate companies", err)
+		return
+	}
+
+	usage.AddRows(r.Context(), len(groups))
+
+	respondWithJSON(w, http.StatusOK, models.AggregateResponse{
+		GroupBy:     req.GroupBy,
+		Groups:      groups,
+		TotalGroups: total,
+	})
+}
+
+// Histogram handles POST /api/analytics/histogram
+func (h *CompanyHandler) Histogram(w http.ResponseWriter, r *http.Request) {
+	var req models.HistogramRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if !slices.Contains(database.HistogramFields, req.Field) {
+		respondWithError(w, http.StatusBadRequest, "Invalid field", "field must be one of "+strings.Join(database.HistogramFields, ", "))
+		return
+	}
+
+	// Set defaults
+	if req.Filters.CompanyStatus == "" {
+		req.Filters.CompanyStatus = "active"
+	}
+	if invalid := database.ValidateFilters(req.Filters); len(invalid) > 0 {
+		respondWithInvalidFilters(w, invalid)
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	response, err := h.companies.Histogram(ctx, req.Field, req.Filters)
+	if err != nil {
+		log.Printf("Histogram error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to bucket companies", err)
+		return
+	}
+
+	usage.AddRows(r.Context(), len(response.Buckets))
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// IncorporationTrend handles GET /api/analytics/incorporations?group_by=month&sic=62
+func (h *CompanyHandler) IncorporationTrend(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	groupBy := query.Get("group_by")
+	if groupBy == "" {
+		groupBy = "month"
+	}
+	if !slices.Contains(database.TrendGroupings, groupBy) {
+		respondWithError(w, http.StatusBadRequest, "Invalid group_by", "group_by must be one of "+strings.Join(database.TrendGroupings, ", "))
+		return
+	}
+
+	sic := strings.TrimSpace(query.Get("sic"))
+	if sic != "" && !sicPrefixPattern.MatchString(sic) {
+		respondWithError(w, http.StatusBadRequest, "Invalid sic", "sic must be a SIC code or its first digits, e.g. 62 or 62012")
+		return
+	}
+
+	// The last ten years by default
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := time.Date(now.Year()-maxTrendYears+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	for name, date := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid "+name, name+" must be a date, e.g. 2020-01-01")
+				return
+			}
+			*date = parsed
+		}
+	}
+	if to.Before(from) {
+		respondWithError(w, http.StatusBadRequest, "Invalid range", "from must not be after to")
+		return
+	}
+	if groupBy != "year" && to.After(from.AddDate(maxTrendYears, 0, 0)) {
+		respondWithError(w, http.StatusBadRequest, "Invalid range", "ranges grouped by month or quarter can be at most 10 years; group by year for longer")
+		return
+	}
+
+	response := models.IncorporationTrendResponse{
+		GroupBy:  groupBy,
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		SIC:      sic,
+		Industry: strings.TrimSpace(query.Get("industry")),
+		Location: strings.TrimSpace(query.Get("location")),
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	periods, err := h.companies.IncorporationTrend(ctx, groupBy, from, to, response.SIC, response.Industry, response.Location)
+	if err != nil {
+		log.Printf("Incorporation trend error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to count incorporations", err)
+		return
+	}
+	response.Periods = periods
+	for _, p := range periods {
+		response.Incorporations += p.Incorporations
+		response.Dissolutions += p.Dissolutions
+	}
+
+	usage.AddRows(r.Context(), len(periods))
+
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/auth"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// CreateAPIKey handles POST /api/admin/keys
+func (h *AdminHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "name is required")
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = string(auth.RoleReader)
+	}
+	if _, ok := auth.ParseRole(req.Role); !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid role", `role must be "reader", "exporter" or "admin"`)
+		return
+	}
+
+	if !validQuotas(req.MonthlyRequestQuota, req.MonthlyRowQuota) {
+		respondWithError(w, http.StatusBadRequest, "Invalid quota", "Quotas must be zero or greater")
+		return
+	}
+
+	// Organization admins can only issue keys for their own organization, which they get the
+	// tier and quotas of; only platform admins choose those
+	if org := auth.FromContext(r.Context()).Organization(); org != nil {
+		if req.OrganizationID != nil && *req.OrganizationID != *org {
+			respondWithError(w, http.StatusForbidden, "Forbidden", "Keys can only be issued for your own organization")
+			return
+		}
+		if !h.inheritOrganizationLimits(w, r, *org, &req) {
+			return
+		}
+		req.OrganizationID = org
+	}
+
+	if req.Tier == "" {
+		req.Tier = "standard"
+	}
+	if !h.limiter.KeyTier(req.Tier) {
+		respondWithError(w, http.StatusBadRequest, "Invalid tier", fmt.Sprintf("Unknown rate limit tier %q", req.Tier))
+		return
+	}
+
+	key, prefix, hash, err := auth.GenerateKey()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create API key", err.Error())
+		return
+	}
+
+	apiKey, err := h.db.CreateAPIKey(r.Context(), req, prefix, hash)
+	if errors.Is(err, database.ErrOrganizationNotFound) {
+		respondWithError(w, http.StatusBadRequest, "Organization not found", "")
+		return
+	}
+	if err != nil {
+		log.Printf("Create API key error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create API key", err.Error())
+		return
+	}
+
+	log.Printf("Created %s API key %d (%s) for %q", apiKey.Role, apiKey.ID, apiKey.KeyPrefix, apiKey.Name)
+
+	respondWithJSON(w, http.StatusCreated, models.CreateAPIKeyResponse{
+		APIKey: apiKey,
+		Key:    key,
+	})
+}
+
+// inheritOrganizationLimits applies the organization's tier and quotas to a key requested by
+// one of its admins. The admin may lower the quotas, but gets 403 for admin keys, another tier,
+// or quotas above (or, as null, beyond) the organization's. It responds and returns false when
+// the key cannot be issued.
+func (h *AdminHandler) inheritOrganizationLimits(w http.ResponseWriter, r *http.Request, orgID int, req *models.CreateAPIKeyRequest) bool {
+	if req.Role == string(auth.RoleAdmin) {
+		respondWithError(w, http.StatusForbidden, "Forbidden", "Only platform admins can issue admin keys")
+		return false
+	}
+
+	org, err := h.db.GetOrganization(r.Context(), orgID)
+	if err != nil {
+		log.Printf("Fetch organization error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create API key", err.Error())
+		return false
+	}
+	if org == nil {
+		respondWithError(w, http.StatusBadRequest, "Organization not found", "")
+		return false
+	}
+
+	if req.Tier != "" && req.Tier != org.Tier {
+		respondWithError(w, http.StatusForbidden, "Forbidden", fmt.Sprintf("Keys of your organization have the %q tier", org.Tier))
+		return false
+	}
+	req.Tier = org.Tier
+
+	var ok bool
+	if req.MonthlyRequestQuota, ok = inheritQuota(req.MonthlyRequestQuota, org.MonthlyRequestQuota); !ok {
+		respondWithError(w, http.StatusForbidden, "Forbidden", fmt.Sprintf("monthly_request_quota cannot exceed your organization's %d", *org.MonthlyRequestQuota))
+		return false
+	}
+	if req.MonthlyRowQuota, ok = inheritQuota(req.MonthlyRowQuota, org.MonthlyRowQuota); !ok {
+		respondWithError(w, http.StatusForbidden, "Forbidden", fmt.Sprintf("monthly_row_quota cannot exceed your organization's %d", *org.MonthlyRowQuota))
+		return false
+	}
+	return true
+}
+
+// inheritQuota returns the quota of a key requested under an organization's limit (nil =
+// unlimited): the limit when none is requested, else the requested quota if within the limit
+func inheritQuota(requested, limit *int64) (*int64, bool) {
+	switch {
+	case limit == nil:
+		return requested, true
+	case requested == nil:
+		return limit, true
+	default:
+		return requested, *requested <= *limit
+	}
+}
+
+// validQuotas reports whether the monthly quotas, nil for unlimited, are zero or greater
+func validQuotas(request, row *int64) bool {
+	return (request == nil || *request >= 0) && (row == nil || *row >= 0)
+}
+
+// ListAPIKeys handles GET /api/admin/keys. Organization admins only see their organization's keys.
+func (h *AdminHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.db.ListAPIKeys(r.Context(), auth.FromContext(r.Context()).Organization())
+	if err != nil {
+		log.Printf("List API keys error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list API keys", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.APIKeyListResponse{Keys: keys})
+}
+
+// RevokeAPIKey handles DELETE /api/admin/keys/{id}
+func (h *AdminHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid key ID", err.Error())
+		return
+	}
+
+	revoked, err := h.db.RevokeAPIKey(r.Context(), id, auth.FromContext(r.Context()).Organization())
+	if err != nil {
+		log.Printf("Revoke API key error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke API key", err.Error())
+		return
+	}
+	if !revoked {
+		respondWithError(w, http.StatusNotFound, "API key not found", "")
+		return
+	}
+
+	log.Printf("Revoked API key %d", id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
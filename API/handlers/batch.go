@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// maxBatchCompanies bounds how many companies can be fetched in one batch request
+const maxBatchCompanies = 500
+
+// BatchGetCompanies handles POST /api/companies/batch
+func (h *CompanyHandler) BatchGetCompanies(w http.ResponseWriter, r *http.Request) {
+	var req models.BatchRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	numbers, ok := normalizeCompanyNumbers(w, req.CompanyNumbers, maxBatchCompanies)
+	if !ok {
+		return
+	}
+	if len(numbers) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "company_numbers is required")
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	found, err := h.companies.GetMany(ctx, numbers)
+	if err != nil {
+		log.Printf("Batch query error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch companies", err)
+		return
+	}
+
+	response := models.BatchResponse{
+		Companies: make([]models.Company, 0, len(found)),
+		NotFound:  make([]string, 0),
+	}
+	for _, number := range numbers {
+		if c, ok := found[number]; ok {
+			response.Companies = append(response.Companies, c)
+		} else {
+			response.NotFound = append(response.NotFound, number)
+		}
+	}
+
+	log.Printf("Batch fetched %d of %d companies", len(response.Companies), len(numbers))
+
+	usage.AddRows(r.Context(), len(response.Companies))
+
+	respondWithJSON(w, http.StatusOK, response)
+}
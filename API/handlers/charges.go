@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/companieshouse"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// GetCompanyCharges handles GET /api/companies/{company_number}/charges
+func (h *CompanyHandler) GetCompanyCharges(w http.ResponseWriter, r *http.Request) {
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	charges, err := h.companies.Charges(ctx, number)
+	if err != nil {
+		log.Printf("List charges error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch charges", err)
+		return
+	}
+
+	outstanding := 0
+	for _, c := range charges {
+		if c.Outstanding {
+			outstanding++
+		}
+	}
+
+	usage.AddRows(r.Context(), len(charges))
+
+	respondWithJSON(w, http.StatusOK, models.ChargeListResponse{
+		CompanyNumber:    number,
+		OutstandingCount: outstanding,
+		Charges:          charges,
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"data-co/api/database"
+)
+
+// DatabaseCircuit responds 503 straight away while db is unavailable, rather than holding
+// requests until their queries fail, except to requests for the exempt paths
+func DatabaseCircuit(db *database.DB, exempt ...string) func(http.Handler) http.Handler {
+	skip := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		skip[path] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if down, wait := db.Unavailable(); down && !skip[r.URL.Path] && r.Method != http.MethodOptions {
+				respondUnavailable(w, wait)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// respondUnavailable responds 503 with a Retry-After of when the database is next tried
+func respondUnavailable(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(max(wait.Seconds(), 1)))))
+	respondWithError(w, http.StatusServiceUnavailable, "Database unavailable", "The database cannot be reached; retry later")
+}
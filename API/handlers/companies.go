@@ -1,124 +1,371 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"golang.org/x/sync/errgroup"
 
+	"data-co/api/auth"
+	"data-co/api/companieshouse"
 	"data-co/api/database"
 	"data-co/api/models"
+	"data-co/api/usage"
 )
 
+// maxRequestBody caps the size of a JSON request body
+const maxRequestBody = 1 << 20
+
 // CompanyHandler handles company-related HTTP requests
 type CompanyHandler struct {
-	db *database.DB
+	companies database.CompanyRepository // Every company query
+	maxLimit  int                        // Of a search
+	live      *LiveCompanies             // Looks up companies missing from the database; nil if off
 }
 
-// NewCompanyHandler creates a new company handler
-func NewCompanyHandler(db *database.DB) *CompanyHandler {
-	return &CompanyHandler{db: db}
+// NewCompanyHandler creates a new company handler reading companies from companies, allowing
+// searches of up to maxLimit companies and looking up companies missing from the database with
+// live, if not nil
+func NewCompanyHandler(companies database.CompanyRepository, maxLimit int, live *LiveCompanies) *CompanyHandler {
+	return &CompanyHandler{companies: companies, maxLimit: maxLimit, live: live}
 }
 
 // SearchCompanies handles POST /api/companies/search
 func (h *CompanyHandler) SearchCompanies(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var filters models.CompanySearchFilters
-	if err := json.NewDecoder(r.Body).Decode(&filters); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+	if !decodeJSON(w, r, &filters) {
 		return
 	}
+	// The offset and limit in the query string of a page link override the body's
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{{"offset", &filters.Offset}, {"limit", &filters.Limit}} {
+		if value := r.URL.Query().Get(param.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid "+param.name, param.name+" must be an integer")
+				return
+			}
+			*param.value = n
+		}
+	}
 
 	// Set defaults
+	if filters.Sample > 0 {
+		filters.Limit = filters.Sample
+	}
 	if filters.Limit == 0 {
 		filters.Limit = 100
 	}
 	if filters.CompanyStatus == "" {
 		filters.CompanyStatus = "active"
 	}
-
-	// Build query
-	query, args := database.BuildCompanyQuery(filters)
+	if !validCountMode(filters.CountMode) {
+		respondWithError(w, http.StatusBadRequest, "Invalid count_mode", `count_mode must be "exact" or "estimate"`)
+		return
+	}
+	invalid := database.ValidateFilters(filters)
+	if filters.Sample == 0 && (filters.Limit < 1 || filters.Limit > h.maxLimit) {
+		invalid = append(invalid, models.InvalidFilter{Field: "limit", Value: filters.Limit, Reason: fmt.Sprintf("must be between 1 and %d", h.maxLimit)})
+	}
+	if filters.Offset < 0 {
+		invalid = append(invalid, models.InvalidFilter{Field: "offset", Value: filters.Offset, Reason: "cannot be negative"})
+	}
+	if len(invalid) > 0 {
+		respondWithInvalidFilters(w, invalid)
+		return
+	}
+	if filters.Debug && !mayDebug(r) {
+		respondWithError(w, http.StatusForbidden, "Forbidden", "debug requires the \"admin\" role")
+		return
+	}
 
 	log.Printf("Executing search query with filters: %+v", filters)
 
-	// Execute query
-	rows, err := h.db.Query(query, args...)
-	if err != nil {
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	if acceptsNDJSON(r) {
+		h.streamCompanies(ctx, w, r, filters)
+		return
+	}
+
+	// Without a total, one more company than the page is fetched to tell whether there are more
+	includeTotal := filters.IncludeTotal == nil || *filters.IncludeTotal
+	page := filters
+	if !includeTotal && filters.Sample == 0 {
+		page.Limit++
+	}
+
+	// The page and the total are queried concurrently, and the count abandoned if the page fails
+	var companies []models.Company
+	var debug *models.SearchDebug
+	var total int
+	var isEstimate bool
+	var countErr error
+	var countMs int64
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) {
+		if filters.Debug {
+			companies, debug, err = h.debugSearch(gctx, page)
+		} else {
+			companies, err = h.companies.Search(gctx, page)
+		}
+		return err
+	})
+	if includeTotal {
+		g.Go(func() error {
+			started := time.Now()
+			total, isEstimate, countErr = h.countTotal(gctx, filters)
+			countMs = time.Since(started).Milliseconds()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
 		log.Printf("Query error: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "Failed to search companies", err.Error())
+		respondWithQueryError(ctx, w, "Failed to search companies", err)
 		return
 	}
-	defer rows.Close()
-
-	// Parse results
-	companies := make([]models.Company, 0)
-	for rows.Next() {
-		var c models.Company
-		err := rows.Scan(
-			&c.ID,
-			&c.CompanyNumber,
-			&c.CompanyName,
-			&c.CompanyStatus,
-			&c.Locality,
-			&c.Region,
-			&c.PostalCode,
-			&c.PrimarySICCode,
-			&c.IndustryCategory,
-			&c.IncorporationDate,
-			&c.Turnover,
-			&c.ProfitAfterTax,
-			&c.TotalAssets,
-			&c.NetWorth,
-			&c.ProfitMargin,
-			&c.LatestAccountsDate,
-			&c.ActiveOfficersCount,
-		)
-		if err != nil {
-			log.Printf("Row scan error: %v", err)
-			continue
+
+	// Build response
+	response := models.SearchResponse{
+		Companies:       companies,
+		Limit:           filters.Limit,
+		Offset:          filters.Offset,
+		TotalIsEstimate: isEstimate,
+		Page:            1,
+	}
+	if includeTotal {
+		if countErr != nil {
+			log.Printf("Count query error: %v", countErr)
+			total = len(companies) // Fallback to returned count
+			response.TotalIsEstimate = false
 		}
-		companies = append(companies, c)
+		totalPages := 1
+		if filters.Sample == 0 {
+			totalPages = max(1, (total+filters.Limit-1)/filters.Limit)
+		}
+		response.Total, response.TotalPages = &total, &totalPages
+		response.HasMore = filters.Sample == 0 && filters.Offset+len(companies) < total
+	} else if len(companies) > filters.Limit && filters.Sample == 0 {
+		response.Companies = companies[:filters.Limit]
+		response.HasMore = true
 	}
+	companies = response.Companies
+	if filters.Sample == 0 {
+		response.Page = filters.Offset/filters.Limit + 1
+		if response.HasMore {
+			response.Links.Next = pageLink(r, filters.Offset+filters.Limit, filters.Limit)
+		}
+		if filters.Offset > 0 {
+			response.Links.Prev = pageLink(r, max(0, filters.Offset-filters.Limit), filters.Limit)
+		}
+	}
+	if debug != nil {
+		if includeTotal {
+			debug.CountMs = countMs
+			if !isEstimate {
+				debug.CountSQL, debug.CountArgs = database.BuildCompanyCountQuery(filters)
+			}
+		}
+		response.Debug = debug
+	}
+
+	log.Printf("Returning %d companies (include_total: %t, total: %d)", len(companies), includeTotal, total)
 
-	if err := rows.Err(); err != nil {
-		log.Printf("Rows iteration error: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "Error processing results", err.Error())
+	usage.AddRows(r.Context(), len(companies))
+
+	if len(filters.Fields) > 0 {
+		respondWithJSON(w, http.StatusOK, models.SparseSearchResponse{
+			SearchResponse: response,
+			Companies:      sparseCompanies(companies, filters.Fields),
+		})
 		return
 	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// debugSearch runs a search, explaining how it ran. The count is left for the caller to add.
+func (h *CompanyHandler) debugSearch(ctx context.Context, filters models.CompanySearchFilters) ([]models.Company, *models.SearchDebug, error) {
+	companies, debug, err := h.companies.Explain(ctx, filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	debug.AppliedFilters = appliedFilters(filters)
+	return companies, debug, nil
+}
 
-	// Get total count
-	countQuery, countArgs := database.BuildCompanyCountQuery(filters)
-	var total int
-	err = h.db.QueryRow(countQuery, countArgs...).Scan(&total)
+// pageLink returns the URL the request was made to, as sent so that /api/v2/ paths are kept,
+// with offset and limit set in its query string
+func pageLink(r *http.Request, offset, limit int) string {
+	link, err := url.ParseRequestURI(r.RequestURI)
 	if err != nil {
-		log.Printf("Count query error: %v", err)
-		total = len(companies) // Fallback to returned count
+		link = &url.URL{Path: r.URL.Path}
 	}
+	query := link.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
+}
 
-	// Build response
-	response := models.SearchResponse{
-		Companies: companies,
-		Total:     total,
-		Limit:     filters.Limit,
-		Offset:    filters.Offset,
-		HasMore:   filters.Offset+len(companies) < total,
+// mayDebug reports whether a request may ask for a search to be explained, which shows the
+// SQL behind it. Requests without a principal only reach search when authentication is disabled.
+func mayDebug(r *http.Request) bool {
+	principal := auth.FromContext(r.Context())
+	return principal == nil || principal.Role.Includes(auth.RoleAdmin)
+}
+
+// appliedFilters returns the filters of a search that are set, after defaults, keyed by their
+// JSON names and including those inside filter groups. Paging, ordering and output options are
+// left out.
+func appliedFilters(filters models.CompanySearchFilters) map[string]any {
+	applied := make(map[string]any)
+	if data, err := json.Marshal(filters); err == nil {
+		json.Unmarshal(data, &applied)
 	}
+	pruneFilters(applied)
+	return applied
+}
 
-	log.Printf("Returning %d companies (total: %d)", len(companies), total)
+// pruneFilters removes the options and unset filters from a filter expression decoded from JSON
+func pruneFilters(filters map[string]any) {
+	for _, option := range []string{"fields", "limit", "offset", "orderBy", "count_mode", "include_total", "sample", "sample_seed", "debug", "score_weights"} {
+		delete(filters, option)
+	}
+	for name, value := range filters {
+		switch v := value.(type) {
+		case nil:
+			delete(filters, name)
+		case string:
+			if v == "" {
+				delete(filters, name)
+			}
+		case []any:
+			if len(v) == 0 {
+				delete(filters, name)
+			}
+			if name == "and" || name == "or" {
+				for _, entry := range v {
+					if group, ok := entry.(map[string]any); ok {
+						pruneFilters(group)
+					}
+				}
+			}
+		}
+	}
+}
 
-	respondWithJSON(w, http.StatusOK, response)
+// sparseCompanies returns each company with only the given fields
+func sparseCompanies(companies []models.Company, fields []string) []map[string]json.RawMessage {
+	sparse := make([]map[string]json.RawMessage, 0, len(companies))
+	for _, c := range companies {
+		sparse = append(sparse, sparseCompany(c, fields))
+	}
+	return sparse
+}
+
+// sparseCompany returns a company with only the given fields, leaving out empty omitempty
+// fields such as matched_on. A custom score is always kept.
+func sparseCompany(c models.Company, fields []string) map[string]json.RawMessage {
+	all := make(map[string]json.RawMessage)
+	if data, err := json.Marshal(c); err == nil {
+		json.Unmarshal(data, &all)
+	}
+	kept := make(map[string]json.RawMessage, len(fields))
+	for _, field := range append(slices.Clip(fields), "score") {
+		if value, ok := all[field]; ok {
+			kept[field] = value
+		}
+	}
+	return kept
+}
+
+// ndjsonMediaType is the Accept value that streams search results as one company per line
+const ndjsonMediaType = "application/x-ndjson"
+
+// acceptsNDJSON reports whether a request asks for search results as NDJSON
+func acceptsNDJSON(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part)); err == nil && mediaType == ndjsonMediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// streamCompanies writes the results of a search query as NDJSON, one company per line,
+// flushing each line as its row is scanned so memory use does not grow with the page size.
+// There is no total or paging envelope. Errors before the first row get the usual JSON error
+// response; after it the stream is cut short, which clients see as a missing final newline or
+// fewer lines than the limit.
+func (h *CompanyHandler) streamCompanies(ctx context.Context, w http.ResponseWriter, r *http.Request, filters models.CompanySearchFilters) {
+	flusher := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+
+	started := false
+	start := func() {
+		if !started {
+			w.Header().Set("Content-Type", ndjsonMediaType)
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+	}
+
+	written := 0
+	err := h.companies.Stream(ctx, filters, func(c models.Company) error {
+		start()
+		var line any = c
+		if len(filters.Fields) > 0 {
+			line = sparseCompany(c, filters.Fields)
+		}
+		if err := enc.Encode(line); err != nil {
+			return fmt.Errorf("stream write: %w", err)
+		}
+		if err := flusher.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return fmt.Errorf("stream flush: %w", err)
+		}
+		written++
+		return nil
+	})
+	switch {
+	case err != nil && !started:
+		log.Printf("Query error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to search companies", err)
+		return
+	case err != nil:
+		log.Printf("Stream error after %d companies: %v", written, err)
+	default:
+		start()
+	}
+
+	log.Printf("Streamed %d companies", written)
+	usage.AddRows(r.Context(), written)
 }
 
 // CountCompanies handles POST /api/companies/count
 func (h *CompanyHandler) CountCompanies(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var filters models.CompanySearchFilters
-	if err := json.NewDecoder(r.Body).Decode(&filters); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+	if !decodeJSON(w, r, &filters) {
 		return
 	}
 
@@ -126,23 +373,31 @@ func (h *CompanyHandler) CountCompanies(w http.ResponseWriter, r *http.Request)
 	if filters.CompanyStatus == "" {
 		filters.CompanyStatus = "active"
 	}
-
-	// Build count query
-	query, args := database.BuildCompanyCountQuery(filters)
+	if !validCountMode(filters.CountMode) {
+		respondWithError(w, http.StatusBadRequest, "Invalid count_mode", `count_mode must be "exact" or "estimate"`)
+		return
+	}
+	if invalid := database.ValidateFilters(filters); len(invalid) > 0 {
+		respondWithInvalidFilters(w, invalid)
+		return
+	}
 
 	log.Printf("Executing count query with filters: %+v", filters)
 
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
 	// Execute query
-	var total int
-	err := h.db.QueryRow(query, args...).Scan(&total)
+	total, isEstimate, err := h.countTotal(ctx, filters)
 	if err != nil {
 		log.Printf("Count query error: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "Failed to count companies", err.Error())
+		respondWithQueryError(ctx, w, "Failed to count companies", err)
 		return
 	}
 
 	response := models.CountResponse{
-		Total: total,
+		Total:           total,
+		TotalIsEstimate: isEstimate,
 	}
 
 	log.Printf("Total matching companies: %d", total)
@@ -150,102 +405,150 @@ func (h *CompanyHandler) CountCompanies(w http.ResponseWriter, r *http.Request)
 	respondWithJSON(w, http.StatusOK, response)
 }
 
-// GetCompany handles GET /api/companies/:id
+// GetCompany handles GET /api/companies/{company_number} and GET /api/companies/number/{company_number}
 func (h *CompanyHandler) GetCompany(w http.ResponseWriter, r *http.Request) {
-	// Get company ID from URL
-	vars := mux.Vars(r)
-	idStr := vars["id"]
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid company ID", err.Error())
+	asOf, ok := parseAsOf(w, r)
+	if !ok {
 		return
 	}
 
-	log.Printf("Fetching company with ID: %d", id)
-
-	// Query for single company
-	query := `
-	WITH latest_financial AS (
-		SELECT
-			turnover,
-			profit_loss as profit_after_tax,
-			total_assets,
-			net_worth,
-			0 as profit_margin,
-			period_end
-		FROM staging_financials
-		WHERE staging_company_id = $1
-		ORDER BY period_end DESC
-		LIMIT 1
-	),
-	officer_count AS (
-		SELECT COUNT(*) FILTER (WHERE resigned_on IS NULL) as active_officers
-		FROM staging_officers
-		WHERE staging_company_id = $1
-	)
-	SELECT
-		c.id,
-		c.company_number,
-		c.company_name,
-		c.company_status,
-		c.locality,
-		c.region,
-		c.postal_code,
-		'' as primary_sic_code,
-		'' as industry_category,
-		NULL::date as incorporation_date,
-		lf.turnover,
-		lf.profit_after_tax,
-		lf.total_assets,
-		lf.net_worth,
-		lf.profit_margin,
-		lf.period_end as latest_accounts_date,
-		COALESCE(oc.active_officers, 0) as active_officers_count
-	FROM staging_companies c
-	LEFT JOIN latest_financial lf ON true
-	LEFT JOIN officer_count oc ON true
-	WHERE c.id = $1
-	`
-
-	var company models.Company
-	err = h.db.QueryRow(query, id).Scan(
-		&company.ID,
-		&company.CompanyNumber,
-		&company.CompanyName,
-		&company.CompanyStatus,
-		&company.Locality,
-		&company.Region,
-		&company.PostalCode,
-		&company.PrimarySICCode,
-		&company.IndustryCategory,
-		&company.IncorporationDate,
-		&company.Turnover,
-		&company.ProfitAfterTax,
-		&company.TotalAssets,
-		&company.NetWorth,
-		&company.ProfitMargin,
-		&company.LatestAccountsDate,
-		&company.ActiveOfficersCount,
-	)
-
-	if err == sql.ErrNoRows {
+	log.Printf("Fetching company: %s", number)
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	var company *models.Company
+	var err error
+	if asOf != nil {
+		company, err = h.companies.GetAsOf(ctx, number, *asOf)
+	} else {
+		company, err = h.companies.Get(ctx, number)
+	}
+	if err != nil {
+		log.Printf("Query error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch company", err)
+		return
+	}
+	if company == nil && asOf == nil && h.live != nil {
+		// Falls back to a 404 if Companies House cannot be reached
+		if company, err = h.live.Get(r.Context(), number); err != nil {
+			log.Printf("Live lookup error: %v", err)
+		}
+	}
+	if company == nil {
 		respondWithError(w, http.StatusNotFound, "Company not found", "")
 		return
 	}
+
+	switch {
+	case company.Source != "":
+		// Our insolvency records cannot cover a company not ingested yet
+	case asOf != nil:
+		company.Insolvency, err = h.companies.InsolvencyAsOf(ctx, company.CompanyNumber, company.CompanyStatus, *asOf)
+	default:
+		company.Insolvency, err = h.companies.Insolvency(ctx, company.CompanyNumber, company.CompanyStatus)
+	}
 	if err != nil {
-		log.Printf("Query error: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch company", err.Error())
+		log.Printf("Insolvency query error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch company", err)
 		return
 	}
 
 	log.Printf("Found company: %s (%s)", company.CompanyName, company.CompanyNumber)
 
-	respondWithJSON(w, http.StatusOK, company)
+	if respondWithETag(w, r, company) {
+		usage.AddRows(r.Context(), 1)
+	}
+}
+
+// countTotal counts companies matching filters, using planner estimates when requested
+func (h *CompanyHandler) countTotal(ctx context.Context, filters models.CompanySearchFilters) (int, bool, error) {
+	if filters.CountMode == "estimate" {
+		total, err := h.companies.Estimate(ctx, filters)
+		return total, true, err
+	}
+
+	total, err := h.companies.Count(ctx, filters)
+	return total, false, err
 }
 
 // Helper functions
 
+// parseAsOf reads the optional as_of date of a point-in-time request, writing a 400 response and
+// returning false if it is invalid or in the future
+func parseAsOf(w http.ResponseWriter, r *http.Request) (*time.Time, bool) {
+	value := r.URL.Query().Get("as_of")
+	if value == "" {
+		return nil, true
+	}
+	asOf, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid as_of", "as_of must be a date, e.g. 2023-06-30")
+		return nil, false
+	}
+	if asOf.After(time.Now()) {
+		respondWithError(w, http.StatusBadRequest, "Invalid as_of", "as_of must not be in the future")
+		return nil, false
+	}
+	return &asOf, true
+}
+
+// decodeJSON decodes a request body holding a single JSON value into v, writing a 400 response
+// and returning false if it is malformed or has fields v does not, so a misspelled filter is
+// rejected rather than ignored, or a 413 if it is over maxRequestBody
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil && dec.Decode(&json.RawMessage{}) != io.EOF {
+		err = errors.New("body must hold a single JSON value")
+	}
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		respondWithError(w, http.StatusRequestEntityTooLarge, "Request body too large", fmt.Sprintf("The request body must be at most %d bytes", tooLarge.Limit))
+		return false
+	case err != nil:
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return false
+	}
+	return true
+}
+
+// normalizeCompanyNumbers normalizes and de-duplicates company numbers, writing a 400 response
+// and returning false if any is invalid or there are more than max
+func normalizeCompanyNumbers(w http.ResponseWriter, numbers []string, max int) ([]string, bool) {
+	if len(numbers) > max {
+		respondWithError(w, http.StatusBadRequest, "Too many company numbers", fmt.Sprintf("At most %d company numbers are accepted per request", max))
+		return nil, false
+	}
+
+	seen := make(map[string]bool, len(numbers))
+	normalized := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		n := companieshouse.NormalizeCompanyNumber(number)
+		if len(n) != 8 {
+			respondWithError(w, http.StatusBadRequest, "Invalid company number", fmt.Sprintf("%q is not an 8-character Companies House number", number))
+			return nil, false
+		}
+		if !seen[n] {
+			seen[n] = true
+			normalized = append(normalized, n)
+		}
+	}
+	return normalized, true
+}
+
+func validCountMode(mode string) bool {
+	return mode == "" || mode == "exact" || mode == "estimate"
+}
+
 func respondWithJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
@@ -259,6 +562,70 @@ func respondWithJSON(w http.ResponseWriter, statusCode int, payload interface{})
 	w.Write(response)
 }
 
+// respondWithETag writes payload as JSON like respondWithJSON, tagged with a hash of its content,
+// or just 304 Not Modified if the request's If-None-Match already holds that tag. It reports
+// whether the body was sent. The tag is weak because version 2 rewrites the body without
+// changing its meaning.
+func respondWithETag(w http.ResponseWriter, r *http.Request, payload interface{}) bool {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"Failed to marshal response"}`))
+		return false
+	}
+
+	sum := sha256.Sum256(response)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	// Clients may keep the response but must revalidate it before reuse
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, comparing weakly as
+// RFC 9110 requires
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// respondWithQueryError reports a failed query, distinguishing timeouts and an unavailable
+// database from other errors
+func respondWithQueryError(ctx context.Context, w http.ResponseWriter, error string, err error) {
+	var unavailable *database.UnavailableError
+	if errors.As(err, &unavailable) {
+		respondUnavailable(w, unavailable.RetryAfter)
+		return
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		respondWithError(w, http.StatusGatewayTimeout, "Query timed out", err.Error())
+		return
+	}
+	respondWithError(w, http.StatusInternalServerError, error, err.Error())
+}
+
+// respondWithInvalidFilters responds with a 400 listing each invalid filter
+func respondWithInvalidFilters(w http.ResponseWriter, invalid []models.InvalidFilter) {
+	respondWithJSON(w, http.StatusBadRequest, models.FilterErrorResponse{
+		Error:          "Invalid filters",
+		Message:        database.InvalidFiltersError(invalid).Error(),
+		InvalidFilters: invalid,
+	})
+}
+
 func respondWithError(w http.ResponseWriter, statusCode int, error string, message string) {
 	errorResponse := models.ErrorResponse{
 		Error:   error,
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/models"
+)
+
+func TestGetCompany(t *testing.T) {
+	h := newFakeHandler()
+	tests := []struct {
+		name   string
+		number string
+		status int
+	}{
+		{"found", "00000001", http.StatusOK},
+		{"normalized", "1", http.StatusOK},
+		{"missing", "00000009", http.StatusNotFound},
+		{"invalid", "123456789", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/companies/"+tt.number, nil), map[string]string{"company_number": tt.number})
+			w := httptest.NewRecorder()
+			h.GetCompany(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var company models.Company
+			if err := json.Unmarshal(w.Body.Bytes(), &company); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if company.CompanyNumber != "00000001" || company.CompanyName != "ACME WIDGETS LIMITED" {
+				t.Errorf("company = %s %s, want 00000001 ACME WIDGETS LIMITED", company.CompanyNumber, company.CompanyName)
+			}
+		})
+	}
+}
+
+func TestGetCompanyNotModified(t *testing.T) {
+	h := newFakeHandler()
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/companies/00000001", nil), map[string]string{"company_number": "00000001"})
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		h.GetCompany(w, r)
+		return w
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q, want 200 with an ETag", first.Code, etag)
+	}
+	if w := get(etag); w.Code != http.StatusNotModified || w.Body.Len() > 0 {
+		t.Errorf("revalidation status = %d with %d bytes, want 304 without a body", w.Code, w.Body.Len())
+	}
+}
+
+func TestSearchCompanies(t *testing.T) {
+	h := newFakeHandler()
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		numbers []string
+		total   int
+		hasMore bool
+	}{
+		{"active by default", `{}`, http.StatusOK, []string{"00000001", "00000002"}, 2, false},
+		{"all statuses", `{"companyStatus": "all"}`, http.StatusOK, []string{"00000001", "00000002", "00000003"}, 3, false},
+		{"search term", `{"searchTerm": "widgets"}`, http.StatusOK, []string{"00000001"}, 1, false},
+		{"page", `{"limit": 1}`, http.StatusOK, []string{"00000001"}, 2, true},
+		{"invalid filter", `{"revenue": "lots"}`, http.StatusBadRequest, nil, 0, false},
+		{"empty or entry", `{"or": [{"searchTerm": "acme"}, {}]}`, http.StatusBadRequest, nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.SearchCompanies(w, httptest.NewRequest(http.MethodPost, "/api/companies/search", strings.NewReader(tt.body)))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var response models.SearchResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			var numbers []string
+			for _, c := range response.Companies {
+				numbers = append(numbers, c.CompanyNumber)
+			}
+			if strings.Join(numbers, ",") != strings.Join(tt.numbers, ",") {
+				t.Errorf("companies = %v, want %v", numbers, tt.numbers)
+			}
+			if response.Total == nil || *response.Total != tt.total {
+				t.Errorf("total = %v, want %d", response.Total, tt.total)
+			}
+			if response.HasMore != tt.hasMore {
+				t.Errorf("has_more = %t, want %t", response.HasMore, tt.hasMore)
+			}
+		})
+	}
+}
+
+func TestBatchGetCompanies(t *testing.T) {
+	h := newFakeHandler()
+	w := httptest.NewRecorder()
+	h.BatchGetCompanies(w, httptest.NewRequest(http.MethodPost, "/api/companies/batch", strings.NewReader(`{"company_numbers": ["00000002", "9"]}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var response models.BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(response.Companies) != 1 || len(response.NotFound) != 1 || response.NotFound[0] != "00000009" {
+		t.Errorf("response = %+v, want 00000002 found and 00000009 not found", response)
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{`"abc"`, `"abc"`, true},
+		{`W/"abc"`, `"abc"`, true},
+		{`"abc"`, `W/"abc"`, true},
+		{`"x", "abc"`, `"abc"`, true},
+		{`"x",W/"abc"`, `"abc"`, true},
+		{`*`, `"abc"`, true},
+		{`"abcd"`, `"abc"`, false},
+		{`abc`, `"abc"`, false},
+		{`"x", "y"`, `"abc"`, false},
+		{``, `"abc"`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.ifNoneMatch, tt.etag); got != tt.want {
+			t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.ifNoneMatch, tt.etag, got, tt.want)
+		}
+	}
+}
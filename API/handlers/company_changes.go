@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/companieshouse"
+	"data-co/api/database"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// GetCompanyChanges handles GET /api/companies/{company_number}/changes
+func (h *CompanyHandler) GetCompanyChanges(w http.ResponseWriter, r *http.Request) {
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
+
+	query := r.URL.Query()
+	var since *time.Time
+	if value := query.Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid since", "since must be an RFC 3339 timestamp, e.g. 2024-01-01T00:00:00Z")
+			return
+		}
+		since = &parsed
+	}
+
+	field := query.Get("field")
+	if field != "" && !slices.Contains(database.CompanyHistoryFields, field) {
+		respondWithError(w, http.StatusBadRequest, "Invalid field", fmt.Sprintf("field must be one of %s", strings.Join(database.CompanyHistoryFields, ", ")))
+		return
+	}
+
+	limit := 1000
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 10000 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", "limit must be between 1 and 10000")
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	changes, err := h.companies.Changes(ctx, number, since, field, limit)
+	if err != nil {
+		log.Printf("Get company changes error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch changes", err)
+		return
+	}
+	if changes == nil {
+		respondWithError(w, http.StatusNotFound, "Company not found", "")
+		return
+	}
+
+	usage.AddRows(r.Context(), len(changes))
+
+	respondWithJSON(w, http.StatusOK, models.CompanyChangesResponse{
+		CompanyNumber: number,
+		Since:         since,
+		Changes:       changes,
+	})
+}
+
+// ListChangedCompanies handles GET /api/companies/changes, a feed of the companies with data
+// written since a timestamp for incremental syncs. Pages follow next_cursor, which holds the
+// since time and the position reached.
+func (h *CompanyHandler) ListChangedCompanies(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 1000
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 10000 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", "limit must be between 1 and 10000")
+			return
+		}
+		limit = parsed
+	}
+
+	var since, afterTime time.Time
+	var afterNumber string
+	if value := query.Get("cursor"); value != "" {
+		var ok bool
+		if since, afterTime, afterNumber, ok = decodeChangeCursor(value); !ok {
+			respondWithError(w, http.StatusBadRequest, "Invalid cursor", "cursor must be a next_cursor returned by this endpoint")
+			return
+		}
+	} else {
+		value := query.Get("since")
+		if value == "" {
+			respondWithError(w, http.StatusBadRequest, "Missing since", "since or cursor is required")
+			return
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid since", "since must be an RFC 3339 timestamp, e.g. 2024-01-01T00:00:00Z")
+			return
+		}
+		since = parsed
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	// One extra row tells whether there is another page
+	companies, err := h.companies.Changed(ctx, since, afterTime, afterNumber, limit+1)
+	if err != nil {
+		log.Printf("Changed companies error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to list changed companies", err)
+		return
+	}
+
+	response := models.ChangedCompaniesResponse{Since: since, Companies: companies}
+	if len(companies) > limit {
+		response.Companies, response.HasMore = companies[:limit], true
+		last := response.Companies[limit-1]
+		response.NextCursor = encodeChangeCursor(since, last.ChangedAt, last.CompanyNumber)
+	}
+
+	usage.AddRows(r.Context(), len(response.Companies))
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// encodeChangeCursor encodes a change feed position: the feed's since time, and the write time
+// and number of the last company returned
+func encodeChangeCursor(since, changedAt time.Time, companyNumber string) string {
+	raw := fmt.Sprintf("%d.%d.%s", since.UnixMicro(), changedAt.UnixMicro(), companyNumber)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeChangeCursor reverses encodeChangeCursor
+func decodeChangeCursor(cursor string) (time.Time, time.Time, string, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", false
+	}
+	parts := strings.SplitN(string(raw), ".", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return time.Time{}, time.Time{}, "", false
+	}
+	since, err1 := strconv.ParseInt(parts[0], 10, 64)
+	changedAt, err2 := strconv.ParseInt(parts[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}, "", false
+	}
+	return time.UnixMicro(since).UTC(), time.UnixMicro(changedAt).UTC(), parts[2], true
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// maxCompareCompanies bounds how many companies can be compared in one request
+const maxCompareCompanies = 10
+
+// CompareCompanies handles POST /api/companies/compare
+func (h *CompanyHandler) CompareCompanies(w http.ResponseWriter, r *http.Request) {
+	var req models.CompareRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	numbers, ok := normalizeCompanyNumbers(w, req.CompanyNumbers, maxCompareCompanies)
+	if !ok {
+		return
+	}
+	if len(numbers) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "company_numbers is required")
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	found, err := h.companies.Compare(ctx, numbers)
+	if err != nil {
+		log.Printf("Compare error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to compare companies", err)
+		return
+	}
+
+	response := models.CompareResponse{
+		Companies: make([]models.CompanyComparison, 0, len(found)),
+		NotFound:  make([]string, 0),
+	}
+	for _, number := range numbers {
+		if c, ok := found[number]; ok {
+			response.Companies = append(response.Companies, c)
+		} else {
+			response.NotFound = append(response.NotFound, number)
+		}
+	}
+
+	usage.AddRows(r.Context(), len(response.Companies))
+
+	respondWithJSON(w, http.StatusOK, response)
+}
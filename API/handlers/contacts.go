@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/auth"
+	"data-co/api/companieshouse"
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/enrichment"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// ContactHandler handles requests enriching companies with contact details
+type ContactHandler struct {
+	db       *database.DB
+	enricher enrichment.ContactEnricher
+	credits  int // Monthly allowance per tenant, 0 = unlimited
+	ttl      time.Duration
+}
+
+// NewContactHandler creates a new contact handler. A nil enricher disables enrichment.
+func NewContactHandler(db *database.DB, enricher enrichment.ContactEnricher, cfg config.ContactsConfig) *ContactHandler {
+	return &ContactHandler{db: db, enricher: enricher, credits: cfg.MonthlyCredits, ttl: cfg.CacheTTL}
+}
+
+// EnrichCompany handles POST /api/companies/{company_number}/enrich. Contacts the tenant enriched
+// the company with in the last CacheTTL are returned without a lookup unless refresh=true;
+// otherwise the lookup costs a credit, given back if it fails or finds nothing.
+func (h *ContactHandler) EnrichCompany(w http.ResponseWriter, r *http.Request) {
+	if h.enricher == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "Contact enrichment unavailable", "This server has no contact provider configured")
+		return
+	}
+
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
+	refresh := r.URL.Query().Get("refresh") == "true"
+	owner := auth.FromContext(r.Context()).OwnerID()
+
+	ctx, cancel := h.db.WithTimeout(r.Context())
+	defer cancel()
+
+	company, err := h.db.GetCompanyByNumber(ctx, number)
+	if err != nil {
+		log.Printf("Query error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch company", err)
+		return
+	}
+	if company == nil {
+		respondWithError(w, http.StatusNotFound, "Company not found", "")
+		return
+	}
+
+	if !refresh {
+		stored, err := h.db.GetCompanyContacts(ctx, owner, number)
+		if err != nil {
+			log.Printf("Contacts query error: %v", err)
+			respondWithQueryError(ctx, w, "Failed to fetch contacts", err)
+			return
+		}
+		if stored != nil && time.Since(stored.EnrichedAt) < h.ttl {
+			used, err := h.db.GetContactCredits(ctx, owner)
+			if err != nil {
+				log.Printf("Contact credits query error: %v", err)
+				respondWithQueryError(ctx, w, "Failed to fetch contact credits", err)
+				return
+			}
+			usage.AddRows(r.Context(), 1)
+			respondWithJSON(w, http.StatusOK, models.EnrichContactsResponse{CompanyContacts: *stored, Credits: h.contactCredits(used)})
+			return
+		}
+	}
+
+	used, ok, err := h.db.ChargeContactCredit(ctx, owner, h.credits)
+	if err != nil {
+		log.Printf("Contact credit error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to charge contact credit", err)
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusTooManyRequests, "Contact credits used up",
+			fmt.Sprintf("Monthly contact credit allowance of %d reached for %s; credits reset at the start of next month", h.credits, h.contactCredits(used).Period))
+		return
+	}
+	cancel()
+
+	// The lookup is bounded by the provider's timeout, and the database timeout starts afresh
+	// after it
+	found, err := h.enricher.Contacts(r.Context(), *company)
+	ctx, cancel = h.db.WithTimeout(r.Context())
+	defer cancel()
+	if err != nil || len(found.Emails)+len(found.Phones) == 0 {
+		if refundErr := h.db.RefundContactCredit(ctx, owner); refundErr != nil {
+			log.Printf("Contact credit refund error for %q: %v", owner, refundErr)
+		} else {
+			used--
+		}
+	}
+	if err != nil {
+		log.Printf("Contact enrichment error for %s: %v", number, err)
+		respondWithError(w, http.StatusBadGateway, "Failed to enrich contacts", err.Error())
+		return
+	}
+
+	charged := 1
+	if len(found.Emails)+len(found.Phones) == 0 {
+		charged = 0
+	}
+	if found.Emails == nil {
+		found.Emails = []models.ContactEmail{}
+	}
+	if found.Phones == nil {
+		found.Phones = []models.ContactPhone{}
+	}
+	contacts := models.CompanyContacts{CompanyNumber: number, Contacts: found, Source: h.enricher.Name(), EnrichedAt: time.Now().UTC()}
+	// Stored even when empty, so asking again within the TTL does not repeat the lookup
+	if err := h.db.SaveCompanyContacts(ctx, owner, contacts); err != nil {
+		log.Printf("Save contacts error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to save contacts", err)
+		return
+	}
+
+	usage.AddRows(r.Context(), 1)
+	respondWithJSON(w, http.StatusOK, models.EnrichContactsResponse{
+		CompanyContacts: contacts,
+		CreditsCharged:  charged,
+		Credits:         h.contactCredits(used),
+	})
+}
+
+// ContactCredits returns the tenant's contact credits this month, or nil when enrichment is
+// disabled
+func (h *ContactHandler) ContactCredits(r *http.Request) (*models.ContactCredits, error) {
+	if h == nil || h.enricher == nil {
+		return nil, nil
+	}
+	used, err := h.db.GetContactCredits(r.Context(), auth.FromContext(r.Context()).OwnerID())
+	if err != nil {
+		return nil, err
+	}
+	credits := h.contactCredits(used)
+	return &credits, nil
+}
+
+// contactCredits describes the current month's credits given how many are used
+func (h *ContactHandler) contactCredits(used int) models.ContactCredits {
+	credits := models.ContactCredits{Period: time.Now().UTC().Format("2006-01"), Used: used}
+	if h.credits > 0 {
+		allowance, remaining := h.credits, max(h.credits-used, 0)
+		credits.Allowance, credits.Remaining = &allowance, &remaining
+	}
+	return credits
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/auth"
+	"data-co/api/database"
+	"data-co/api/email"
+	"data-co/api/models"
+	"data-co/api/storage"
+)
+
+// ExportHandler handles background export HTTP requests
+type ExportHandler struct {
+	db     *database.DB
+	store  storage.Store
+	mailer *email.Sender
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(db *database.DB, store storage.Store, mailer *email.Sender) *ExportHandler {
+	return &ExportHandler{db: db, store: store, mailer: mailer}
+}
+
+// CreateExport handles POST /api/exports
+func (h *ExportHandler) CreateExport(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateExportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = models.ExportFormatCSV
+	}
+	if req.Format != models.ExportFormatCSV && req.Format != models.ExportFormatNDJSON {
+		respondWithError(w, http.StatusBadRequest, "Invalid format", `format must be "csv" or "ndjson"`)
+		return
+	}
+	if len(req.Filters.Fields) > 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid filters", "exports include every field; remove fields")
+		return
+	}
+	if req.Email != nil {
+		if !h.mailer.Enabled() {
+			respondWithError(w, http.StatusBadRequest, "Email delivery unavailable", "This server has no SMTP server configured")
+			return
+		}
+		if err := email.ValidateRecipients(req.Email.To); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid email", err.Error())
+			return
+		}
+	}
+
+	// Set defaults, as for search
+	filters := req.Filters
+	filters.Limit, filters.Offset, filters.Sample = 0, 0, 0
+	if filters.CompanyStatus == "" {
+		filters.CompanyStatus = "active"
+	}
+	if invalid := database.ValidateFilters(filters); len(invalid) > 0 {
+		respondWithInvalidFilters(w, invalid)
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	job, err := h.db.CreateExportJob(r.Context(), owner, filters, req.Format, req.Email)
+	if err != nil {
+		log.Printf("Create export error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create export", err.Error())
+		return
+	}
+
+	log.Printf("Queued export %d (%s)", job.ID, job.Format)
+
+	w.Header().Set("Location", fmt.Sprintf("/api/exports/%d", job.ID))
+	respondWithJSON(w, http.StatusAccepted, job)
+}
+
+// ListExports handles GET /api/exports
+func (h *ExportHandler) ListExports(w http.ResponseWriter, r *http.Request) {
+	owner := auth.FromContext(r.Context()).OwnerID()
+	jobs, err := h.db.ListExportJobs(r.Context(), owner)
+	if err != nil {
+		log.Printf("List exports error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list exports", err.Error())
+		return
+	}
+
+	for i := range jobs {
+		setDownloadURL(&jobs[i])
+	}
+	respondWithJSON(w, http.StatusOK, models.ExportListResponse{Exports: jobs})
+}
+
+// GetExport handles GET /api/exports/{id}
+func (h *ExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid export ID", err.Error())
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	job, err := h.db.GetExportJob(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Get export error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch export", err.Error())
+		return
+	}
+	if job == nil {
+		respondWithError(w, http.StatusNotFound, "Export not found", "")
+		return
+	}
+
+	setDownloadURL(job)
+	respondWithJSON(w, http.StatusOK, job)
+}
+
+// DownloadExport handles GET /api/exports/{id}/download
+func (h *ExportHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid export ID", err.Error())
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	key, err := h.db.GetExportFile(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Download export error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch export", err.Error())
+		return
+	}
+	if key == "" {
+		respondWithError(w, http.StatusNotFound, "Export not found", "Only completed exports that have not expired can be downloaded")
+		return
+	}
+
+	h.store.Serve(w, r, key, fmt.Sprintf("companies-export-%d%s", id, path.Ext(key)))
+}
+
+// setDownloadURL points a completed export at its download
+func setDownloadURL(job *models.ExportJob) {
+	if job.Status == models.ExportCompleted {
+		job.DownloadURL = fmt.Sprintf("/api/exports/%d/download", job.ID)
+	}
+}
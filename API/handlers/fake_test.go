@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// fakeCompanies is an in-memory CompanyRepository over a fixed set of companies. Searches match
+// on the search term and company status only. Methods the tests do not need are left to the
+// embedded nil interface, so calling one panics.
+type fakeCompanies struct {
+	database.CompanyRepository
+	companies []models.Company
+}
+
+// matching returns the companies matching the search term and status of filters
+func (f *fakeCompanies) matching(filters models.CompanySearchFilters) []models.Company {
+	var matched []models.Company
+	for _, c := range f.companies {
+		if filters.CompanyStatus != "all" && !strings.EqualFold(c.CompanyStatus, filters.CompanyStatus) {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(c.CompanyName), strings.ToLower(filters.SearchTerm)) {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	return matched
+}
+
+func (f *fakeCompanies) Search(ctx context.Context, filters models.CompanySearchFilters) ([]models.Company, error) {
+	matched := f.matching(filters)
+	start := min(filters.Offset, len(matched))
+	end := min(start+filters.Limit, len(matched))
+	return matched[start:end], nil
+}
+
+func (f *fakeCompanies) Count(ctx context.Context, filters models.CompanySearchFilters) (int, error) {
+	return len(f.matching(filters)), nil
+}
+
+func (f *fakeCompanies) Estimate(ctx context.Context, filters models.CompanySearchFilters) (int, error) {
+	return len(f.matching(filters)), nil
+}
+
+func (f *fakeCompanies) Get(ctx context.Context, companyNumber string) (*models.Company, error) {
+	for _, c := range f.companies {
+		if c.CompanyNumber == companyNumber {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeCompanies) Insolvency(ctx context.Context, companyNumber, companyStatus string) (*models.InsolvencySummary, error) {
+	return nil, nil
+}
+
+func (f *fakeCompanies) GetMany(ctx context.Context, companyNumbers []string) (map[string]models.Company, error) {
+	found := make(map[string]models.Company)
+	for _, number := range companyNumbers {
+		if c, _ := f.Get(ctx, number); c != nil {
+			found[number] = *c
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeCompanies) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, time.Second)
+}
+
+// newFakeHandler returns a CompanyHandler over a fake with three companies, two of them active
+func newFakeHandler() *CompanyHandler {
+	return NewCompanyHandler(&fakeCompanies{companies: []models.Company{
+		{CompanyNumber: "00000001", CompanyName: "ACME WIDGETS LIMITED", CompanyStatus: "Active"},
+		{CompanyNumber: "00000002", CompanyName: "ACME HOLDINGS LIMITED", CompanyStatus: "Active"},
+		{CompanyNumber: "00000003", CompanyName: "ACME OLD LIMITED", CompanyStatus: "Dissolved"},
+	}}, 1000, nil)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"data-co/api/database"
+	"data-co/api/graphql"
+)
+
+// maxGraphQLBody caps the size of a GraphQL request body
+const maxGraphQLBody = 1 << 20
+
+// GraphQLHandler handles GraphQL requests
+type GraphQLHandler struct {
+	db     *database.DB
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(db *database.DB) (*GraphQLHandler, error) {
+	schema, err := graphql.NewSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphQLHandler{db: db, schema: schema}, nil
+}
+
+// Query handles GET and POST /api/graphql. POST takes a JSON body of query, operationName and
+// variables; GET takes the same as query parameters, with variables JSON-encoded.
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid variables", err.Error())
+				return
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBody)).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Query == "" {
+		respondWithError(w, http.StatusBadRequest, "Missing query", "A GraphQL query document is required")
+		return
+	}
+
+	ctx, cancel := h.db.WithTimeout(r.Context())
+	defer cancel()
+
+	resp := h.schema.Execute(ctx, req)
+	if !resp.Executed() {
+		respondWithJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+	for _, err := range resp.Errors {
+		log.Printf("GraphQL error at %v: %s", err.Path, err.Message)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// Schema handles GET /api/graphql/schema, returning the schema definition
+func (h *GraphQLHandler) Schema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(h.schema.SDL()))
+}
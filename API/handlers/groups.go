@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/companieshouse"
+	"data-co/api/usage"
+)
+
+// maxGroupCompanies bounds how many companies a group's ownership tree returns
+const maxGroupCompanies = 500
+
+// GetCompanyGroup handles GET /api/companies/{company_number}/group
+func (h *CompanyHandler) GetCompanyGroup(w http.ResponseWriter, r *http.Request) {
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	group, err := h.companies.Group(ctx, number, maxGroupCompanies)
+	if err != nil {
+		log.Printf("Company group error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch company group", err)
+		return
+	}
+	if group == nil {
+		respondWithError(w, http.StatusNotFound, "Company not found", "")
+		return
+	}
+
+	usage.AddRows(r.Context(), group.CompanyCount)
+
+	respondWithJSON(w, http.StatusOK, group)
+}
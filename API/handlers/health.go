@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// healthCheckTimeout bounds the database round-trip of a deep health check, so a hung
+// database fails the check well within load balancer timeouts
+const healthCheckTimeout = 2 * time.Second
+
+// HealthHandler handles health check requests
+type HealthHandler struct {
+	db *database.DB
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(db *database.DB) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// Health handles GET /api/health. It only reports that the API is running unless deep=true is
+// given, when it also queries the database and responds 503 if that fails.
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	response := models.HealthResponse{Status: "ok", Service: "data-co-api"}
+
+	deep, _ := strconv.ParseBool(r.URL.Query().Get("deep"))
+	if !deep {
+		respondWithJSON(w, http.StatusOK, response)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	stat := h.db.Stat()
+	pool := models.PoolHealth{
+		MaxConns:      stat.MaxConns(),
+		TotalConns:    stat.TotalConns(),
+		AcquiredConns: stat.AcquiredConns(),
+	}
+	if pool.MaxConns > 0 {
+		pool.Utilization = math.Round(float64(pool.AcquiredConns)/float64(pool.MaxConns)*10000) / 100
+	}
+
+	start := time.Now()
+	freshness, err := h.db.DataFreshness(ctx)
+	response.Database = &models.DatabaseHealth{
+		Status:    "ok",
+		LatencyMs: time.Since(start).Milliseconds(),
+		Pool:      pool,
+	}
+	if configured, up := h.db.ReplicaStatus(); configured {
+		response.Database.Replica = "ok"
+		if !up {
+			response.Database.Replica = "unavailable"
+		}
+	}
+	if err != nil {
+		log.Printf("Health check failed: %v", err)
+		response.Status = "unavailable"
+		response.Database.Status = "unavailable"
+		response.Database.Error = err.Error()
+		respondWithJSON(w, http.StatusServiceUnavailable, response)
+		return
+	}
+	response.Database.Freshness = &freshness
+
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/models"
+)
+
+var (
+	// industryNamePattern matches industry names, which are used as industry filter values
+	industryNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+	// sicPrefixPattern matches a SIC 2007 code or the leading digits of one
+	sicPrefixPattern = regexp.MustCompile(`^[0-9]{1,5}$`)
+)
+
+// ListIndustries handles GET /api/admin/industries
+func (h *AdminHandler) ListIndustries(w http.ResponseWriter, r *http.Request) {
+	industries, err := h.db.ListIndustries(r.Context())
+	if err != nil {
+		log.Printf("List industries error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list industries", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.IndustryListResponse{Industries: industries})
+}
+
+// SaveIndustry handles PUT /api/admin/industries/{name}
+func (h *AdminHandler) SaveIndustry(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if !industryNamePattern.MatchString(name) {
+		respondWithError(w, http.StatusBadRequest, "Invalid industry name", "name must be 1-50 lower case letters, digits, '-' or '_'")
+		return
+	}
+
+	var req models.IndustryRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.SICPrefixes) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "sic_prefixes is required")
+		return
+	}
+	for _, prefix := range req.SICPrefixes {
+		if !sicPrefixPattern.MatchString(prefix) {
+			respondWithError(w, http.StatusBadRequest, "Invalid SIC prefix", "sic_prefixes must be 1-5 digits, e.g. \"62\" or \"62012\"")
+			return
+		}
+	}
+
+	industry, created, err := h.db.SaveIndustry(r.Context(), name, req)
+	if err != nil {
+		log.Printf("Save industry error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to save industry", err.Error())
+		return
+	}
+	h.reloadIndustries(r)
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	log.Printf("Saved industry %s with SIC prefixes %v", name, industry.SICPrefixes)
+
+	respondWithJSON(w, status, industry)
+}
+
+// DeleteIndustry handles DELETE /api/admin/industries/{name}
+func (h *AdminHandler) DeleteIndustry(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	deleted, err := h.db.DeleteIndustry(r.Context(), name)
+	if err != nil {
+		log.Printf("Delete industry error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete industry", err.Error())
+		return
+	}
+	if !deleted {
+		respondWithError(w, http.StatusNotFound, "Industry not found", "")
+		return
+	}
+	h.reloadIndustries(r)
+
+	log.Printf("Deleted industry %s", name)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reloadIndustries applies an industry change to this server's industry filter straight away.
+// Other servers pick it up on their next refresh; a failure here is left to that refresh too.
+func (h *AdminHandler) reloadIndustries(r *http.Request) {
+	if err := h.db.LoadIndustries(r.Context()); err != nil {
+		log.Printf("Reload industries error: %v", err)
+	}
+}
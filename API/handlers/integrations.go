@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/auth"
+	"data-co/api/database"
+	"data-co/api/hubspot"
+	"data-co/api/models"
+	"data-co/api/salesforce"
+	"data-co/api/usage"
+)
+
+const (
+	// maxIntegrationCompanies bounds how many companies can be pushed to a CRM in one request
+	maxIntegrationCompanies = 1000
+	// maxHubSpotSyncCompanies bounds how many search results a HubSpot sync can cover
+	maxHubSpotSyncCompanies = 10000
+)
+
+// IntegrationHandler handles requests pushing companies to CRMs
+type IntegrationHandler struct {
+	db         *database.DB
+	salesforce *salesforce.Client
+	hubspot    *hubspot.Client
+}
+
+// NewIntegrationHandler creates a new integration handler. A nil client disables its CRM.
+func NewIntegrationHandler(db *database.DB, sf *salesforce.Client, hs *hubspot.Client) *IntegrationHandler {
+	return &IntegrationHandler{db: db, salesforce: sf, hubspot: hs}
+}
+
+// PushToSalesforce handles POST /api/integrations/salesforce/push
+func (h *IntegrationHandler) PushToSalesforce(w http.ResponseWriter, r *http.Request) {
+	if !h.salesforce.Enabled() {
+		respondWithError(w, http.StatusServiceUnavailable, "Salesforce integration unavailable", "This server has no Salesforce connected app configured")
+		return
+	}
+
+	var req models.SalesforcePushRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Object == "" {
+		req.Object = salesforce.ObjectAccount
+	}
+	if req.Object != salesforce.ObjectAccount && req.Object != salesforce.ObjectLead {
+		respondWithError(w, http.StatusBadRequest, "Invalid object", `object must be "Account" or "Lead"`)
+		return
+	}
+
+	companies, ok := h.selectCompanies(r.Context(), w, req.CompanySelection)
+	if !ok {
+		return
+	}
+
+	results, err := h.salesforce.Upsert(r.Context(), req.Object, companies)
+	if err != nil {
+		log.Printf("Salesforce push error: %v", err)
+		respondWithError(w, http.StatusBadGateway, "Failed to push to Salesforce", err.Error())
+		return
+	}
+
+	response := models.SalesforcePushResponse{Object: req.Object, Results: make([]models.SalesforcePushResult, 0, len(results))}
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			response.Failed++
+		case result.Created:
+			response.Created++
+		default:
+			response.Updated++
+		}
+		response.Results = append(response.Results, models.SalesforcePushResult(result))
+	}
+
+	log.Printf("Pushed %d companies to Salesforce as %s: %d created, %d updated, %d failed",
+		len(results), req.Object, response.Created, response.Updated, response.Failed)
+
+	usage.AddRows(r.Context(), len(companies))
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// StartHubSpotSync handles POST /api/integrations/hubspot/syncs
+func (h *IntegrationHandler) StartHubSpotSync(w http.ResponseWriter, r *http.Request) {
+	if !h.hubspot.Enabled() {
+		respondWithError(w, http.StatusServiceUnavailable, "HubSpot integration unavailable", "This server has no HubSpot access token configured")
+		return
+	}
+
+	var req models.HubSpotSyncRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if (req.WatchlistID != nil) == (req.Filters != nil) {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "Give either watchlist_id or filters")
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	maxCompanies := 0
+	if req.WatchlistID != nil {
+		watchlist, err := h.db.GetWatchlist(r.Context(), owner, *req.WatchlistID)
+		if err != nil {
+			log.Printf("Get watchlist error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to fetch watchlist", err.Error())
+			return
+		}
+		if watchlist == nil {
+			respondWithError(w, http.StatusNotFound, "Watchlist not found", "")
+			return
+		}
+	} else {
+		filters := req.Filters
+		if filters.Limit == 0 {
+			filters.Limit = 1000
+		}
+		if filters.Limit < 0 || filters.Limit > maxHubSpotSyncCompanies {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", fmt.Sprintf("limit must be between 1 and %d", maxHubSpotSyncCompanies))
+			return
+		}
+		if filters.CompanyStatus == "" {
+			filters.CompanyStatus = "active"
+		}
+		if invalid := database.ValidateFilters(*filters); len(invalid) > 0 {
+			respondWithInvalidFilters(w, invalid)
+			return
+		}
+		maxCompanies = filters.Limit
+		filters.Limit, filters.Offset, filters.Fields = 0, 0, nil
+	}
+
+	sync, err := h.db.CreateHubSpotSync(r.Context(), owner, req.WatchlistID, req.Filters, maxCompanies)
+	if err != nil {
+		log.Printf("Create HubSpot sync error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start HubSpot sync", err.Error())
+		return
+	}
+
+	log.Printf("Queued HubSpot sync %d", sync.ID)
+
+	w.Header().Set("Location", fmt.Sprintf("/api/integrations/hubspot/syncs/%d", sync.ID))
+	respondWithJSON(w, http.StatusAccepted, sync)
+}
+
+// ListHubSpotSyncs handles GET /api/integrations/hubspot/syncs
+func (h *IntegrationHandler) ListHubSpotSyncs(w http.ResponseWriter, r *http.Request) {
+	owner := auth.FromContext(r.Context()).OwnerID()
+	syncs, err := h.db.ListHubSpotSyncs(r.Context(), owner)
+	if err != nil {
+		log.Printf("List HubSpot syncs error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list HubSpot syncs", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.HubSpotSyncListResponse{Syncs: syncs})
+}
+
+// GetHubSpotSync handles GET /api/integrations/hubspot/syncs/{id}
+func (h *IntegrationHandler) GetHubSpotSync(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid sync ID", err.Error())
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	sync, err := h.db.GetHubSpotSync(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Get HubSpot sync error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch HubSpot sync", err.Error())
+		return
+	}
+	if sync == nil {
+		respondWithError(w, http.StatusNotFound, "HubSpot sync not found", "")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, sync)
+}
+
+// selectCompanies fetches the companies a selection chooses, responding with an error and
+// returning false when it is invalid
+func (h *IntegrationHandler) selectCompanies(ctx context.Context, w http.ResponseWriter, sel models.CompanySelection) ([]models.Company, bool) {
+	if (len(sel.CompanyNumbers) > 0) == (sel.Filters != nil) {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "Give either company_numbers or filters")
+		return nil, false
+	}
+
+	ctx, cancel := h.db.WithTimeout(ctx)
+	defer cancel()
+
+	if sel.Filters == nil {
+		numbers, ok := normalizeCompanyNumbers(w, sel.CompanyNumbers, maxIntegrationCompanies)
+		if !ok {
+			return nil, false
+		}
+		found, err := h.db.GetCompaniesByNumber(ctx, numbers)
+		if err != nil {
+			log.Printf("Integration query error: %v", err)
+			respondWithQueryError(ctx, w, "Failed to fetch companies", err)
+			return nil, false
+		}
+		companies := make([]models.Company, 0, len(found))
+		for _, number := range numbers {
+			if c, ok := found[number]; ok {
+				companies = append(companies, c)
+			}
+		}
+		return companies, true
+	}
+
+	filters := *sel.Filters
+	if filters.Limit == 0 {
+		filters.Limit = 100
+	}
+	if filters.Limit < 0 || filters.Limit > maxIntegrationCompanies {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", fmt.Sprintf("limit must be between 1 and %d", maxIntegrationCompanies))
+		return nil, false
+	}
+	if filters.CompanyStatus == "" {
+		filters.CompanyStatus = "active"
+	}
+	filters.Fields = nil
+	if invalid := database.ValidateFilters(filters); len(invalid) > 0 {
+		respondWithInvalidFilters(w, invalid)
+		return nil, false
+	}
+
+	companies, err := h.db.FindCompanies(ctx, filters)
+	if err != nil {
+		log.Printf("Integration query error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to search companies", err)
+		return nil, false
+	}
+	return companies, true
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"data-co/api/companieshouse"
+	"data-co/api/config"
+	"data-co/api/models"
+	"data-co/api/streaming"
+)
+
+// maxLiveEntries bounds the live lookup cache. Expired entries are dropped when it fills, and
+// all of them if none have expired.
+const maxLiveEntries = 10000
+
+// LiveCompanies looks up companies missing from the database in the Companies House REST API,
+// so incorporations the ingesters have not reached yet can still be served. Results, including
+// companies Companies House does not know either, are cached for a while to stay under the API
+// key's rate limit.
+type LiveCompanies struct {
+	client  *companieshouse.Client
+	ttl     time.Duration
+	timeout time.Duration
+	group   singleflight.Group // Shares a lookup between concurrent requests for a company
+
+	mu      sync.Mutex
+	entries map[string]liveEntry
+}
+
+// liveEntry is a cached lookup; company is nil if Companies House has no such company
+type liveEntry struct {
+	company *models.Company
+	expires time.Time
+}
+
+// NewLiveCompanies creates the live lookup, or returns nil if COMPANIES_HOUSE_LIVE_FALLBACK is off
+func NewLiveCompanies(cfg config.CompaniesHouseConfig) *LiveCompanies {
+	if !cfg.LiveFallback {
+		return nil
+	}
+	return &LiveCompanies{
+		client:  companieshouse.NewClient(cfg.BaseURL, cfg.APIKey, cfg.RequestsPerSecond),
+		ttl:     cfg.LiveCacheTTL,
+		timeout: cfg.LiveTimeout,
+		entries: make(map[string]liveEntry),
+	}
+}
+
+// Get returns the company with number from the cache or Companies House, or nil if it does not
+// exist. The caller gets its own copy, which it may modify.
+func (l *LiveCompanies) Get(ctx context.Context, number string) (*models.Company, error) {
+	if company, ok := l.cached(number); ok {
+		return copyCompany(company), nil
+	}
+
+	// The lookup is shared, so one caller giving up must not fail the others
+	result := l.group.DoChan(number, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.timeout)
+		defer cancel()
+		company, err := l.fetch(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+		l.store(number, company)
+		return company, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return copyCompany(res.Val.(*models.Company)), nil
+	}
+}
+
+// fetch gets a company profile from Companies House, mapped as the stream ingester would store it
+func (l *LiveCompanies) fetch(ctx context.Context, number string) (*models.Company, error) {
+	profile, err := l.client.CompanyProfile(ctx, number)
+	if errors.Is(err, companieshouse.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch company %s from Companies House: %w", number, err)
+	}
+	staging, err := streaming.CompanyFromEvent(streaming.Event{ResourceID: number, ResourceURI: "/company/" + number, Data: profile})
+	if err != nil {
+		return nil, err
+	}
+	company := staging.Company()
+	company.Source = "companies_house"
+	return &company, nil
+}
+
+// cached returns the unexpired cache entry for number, if any
+func (l *LiveCompanies) cached(number string) (*models.Company, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.entries[number]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.company, true
+}
+
+// store caches the lookup of number
+func (l *LiveCompanies) store(number string, company *models.Company) {
+	if l.ttl <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if len(l.entries) >= maxLiveEntries {
+		for key, entry := range l.entries {
+			if now.After(entry.expires) {
+				delete(l.entries, key)
+			}
+		}
+		if len(l.entries) >= maxLiveEntries {
+			clear(l.entries)
+		}
+	}
+	l.entries[number] = liveEntry{company: company, expires: now.Add(l.ttl)}
+}
+
+// copyCompany returns a copy of a cached company, or nil for a cached miss
+func copyCompany(company *models.Company) *models.Company {
+	if company == nil {
+		return nil
+	}
+	c := *company
+	return &c
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// locationKinds are the accepted values of a location's kind
+var locationKinds = []string{"region", "county", "city", "borough", "town"}
+
+// ListLocations handles GET /api/admin/locations
+func (h *AdminHandler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.db.ListLocations(r.Context())
+	if err != nil {
+		log.Printf("List locations error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list locations", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.LocationListResponse{Locations: locations})
+}
+
+// CreateLocation handles POST /api/admin/locations
+func (h *AdminHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateLocationRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.Name = strings.Join(strings.Fields(req.Name), " ")
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "name is required")
+		return
+	}
+	if !slices.Contains(locationKinds, req.Kind) {
+		respondWithError(w, http.StatusBadRequest, "Invalid kind", `kind must be "region", "county", "city", "borough" or "town"`)
+		return
+	}
+	req.Aliases = normalizeAliases(req.Aliases)
+
+	location, err := h.db.CreateLocation(r.Context(), req)
+	switch {
+	case errors.Is(err, database.ErrLocationConflict):
+		respondWithError(w, http.StatusConflict, "Location already exists", "The name or an alias is already used by a location")
+		return
+	case errors.Is(err, database.ErrParentNotFound):
+		respondWithError(w, http.StatusBadRequest, "Parent location not found", "")
+		return
+	case err != nil:
+		log.Printf("Create location error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create location", err.Error())
+		return
+	}
+
+	log.Printf("Created location %d (%s) with %d aliases", location.ID, location.Name, len(location.Aliases))
+
+	respondWithJSON(w, http.StatusCreated, location)
+}
+
+// DeleteLocation handles DELETE /api/admin/locations/{id}
+func (h *AdminHandler) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid location ID", err.Error())
+		return
+	}
+
+	deleted, err := h.db.DeleteLocation(r.Context(), id)
+	if err != nil {
+		log.Printf("Delete location error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete location", err.Error())
+		return
+	}
+	if !deleted {
+		respondWithError(w, http.StatusNotFound, "Location not found", "")
+		return
+	}
+
+	log.Printf("Deleted location %d", id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddLocationAliases handles POST /api/admin/locations/{id}/aliases
+func (h *AdminHandler) AddLocationAliases(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid location ID", err.Error())
+		return
+	}
+
+	var req models.LocationAliasesRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	aliases := normalizeAliases(req.Aliases)
+	if len(aliases) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "aliases is required")
+		return
+	}
+
+	location, err := h.db.GetLocation(r.Context(), id)
+	if err != nil {
+		log.Printf("Get location error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to add location aliases", err.Error())
+		return
+	}
+	if location == nil {
+		respondWithError(w, http.StatusNotFound, "Location not found", "")
+		return
+	}
+
+	err = h.db.AddLocationAliases(r.Context(), id, aliases)
+	if errors.Is(err, database.ErrLocationConflict) {
+		respondWithError(w, http.StatusConflict, "Alias already exists", "An alias is already used by another location")
+		return
+	}
+	if err != nil {
+		log.Printf("Add location aliases error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to add location aliases", err.Error())
+		return
+	}
+
+	location, err = h.db.GetLocation(r.Context(), id)
+	if err != nil || location == nil {
+		log.Printf("Get location error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch location", "")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, location)
+}
+
+// RemoveLocationAlias handles DELETE /api/admin/locations/{id}/aliases/{alias}
+func (h *AdminHandler) RemoveLocationAlias(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid location ID", err.Error())
+		return
+	}
+	aliases := normalizeAliases([]string{mux.Vars(r)["alias"]})
+	if len(aliases) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid alias", "")
+		return
+	}
+
+	removed, err := h.db.RemoveLocationAlias(r.Context(), id, aliases[0])
+	if err != nil {
+		log.Printf("Remove location alias error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to remove location alias", err.Error())
+		return
+	}
+	if !removed {
+		respondWithError(w, http.StatusNotFound, "Alias not found", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// normalizeAliases lower-cases aliases, collapses their spacing and drops blanks and duplicates
+func normalizeAliases(aliases []string) []string {
+	normalized := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.ToLower(strings.Join(strings.Fields(alias), " "))
+		if alias != "" && !slices.Contains(normalized, alias) {
+			normalized = append(normalized, alias)
+		}
+	}
+	return normalized
+}
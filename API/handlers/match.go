@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+const (
+	// maxMatchQueries bounds how many names can be matched in one request
+	maxMatchQueries = 100
+	// defaultMatchCandidates and maxMatchCandidates bound the candidates returned per name
+	defaultMatchCandidates = 5
+	maxMatchCandidates     = 20
+)
+
+// MatchCompanies handles POST /api/companies/match
+func (h *CompanyHandler) MatchCompanies(w http.ResponseWriter, r *http.Request) {
+	var req models.MatchRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if len(req.Queries) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "queries is required")
+		return
+	}
+	if len(req.Queries) > maxMatchQueries {
+		respondWithError(w, http.StatusBadRequest, "Too many queries", fmt.Sprintf("At most %d names can be matched per request", maxMatchQueries))
+		return
+	}
+	for i := range req.Queries {
+		req.Queries[i].Name = strings.TrimSpace(req.Queries[i].Name)
+		req.Queries[i].Postcode = strings.TrimSpace(req.Queries[i].Postcode)
+		if req.Queries[i].Name == "" {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body", fmt.Sprintf("queries[%d].name is required", i))
+			return
+		}
+	}
+	if req.MinConfidence < 0 || req.MinConfidence > 1 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "min_confidence must be between 0 and 1")
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultMatchCandidates
+	}
+	if req.Limit > maxMatchCandidates {
+		req.Limit = maxMatchCandidates
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	matches, err := h.companies.Match(ctx, req.Queries, req.Limit)
+	if err != nil {
+		log.Printf("Match query error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to match companies", err)
+		return
+	}
+
+	response := models.MatchResponse{Results: make([]models.MatchResult, len(req.Queries))}
+	candidates := 0
+	for i, q := range req.Queries {
+		result := models.MatchResult{Query: q, Candidates: make([]models.MatchCandidate, 0, len(matches[i]))}
+		for _, m := range matches[i] {
+			if m.Confidence >= req.MinConfidence {
+				result.Candidates = append(result.Candidates, m)
+			}
+		}
+		candidates += len(result.Candidates)
+		response.Results[i] = result
+	}
+
+	log.Printf("Matched %d names to %d candidates", len(req.Queries), candidates)
+
+	usage.AddRows(r.Context(), candidates)
+
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/companieshouse"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// GetTurnoverSeries handles GET /api/companies/{company_number}/metrics/turnover
+func (h *CompanyHandler) GetTurnoverSeries(w http.ResponseWriter, r *http.Request) {
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
+
+	asOf, ok := parseAsOf(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	periods, err := h.companies.Turnover(ctx, number, asOf)
+	if err != nil {
+		log.Printf("Turnover series error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch turnover series", err)
+		return
+	}
+
+	usage.AddRows(r.Context(), len(periods))
+
+	respondWithJSON(w, http.StatusOK, models.TurnoverSeriesResponse{
+		CompanyNumber: number,
+		AsOf:          asOf,
+		Periods:       periods,
+	})
+}
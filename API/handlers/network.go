@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/companieshouse"
+	"data-co/api/usage"
+)
+
+const (
+	// defaultNetworkDepth and maxNetworkDepth bound how many shared-officer hops a network spans
+	defaultNetworkDepth = 1
+	maxNetworkDepth     = 3
+	// maxNetworkNodes bounds how many companies a network returns
+	maxNetworkNodes = 250
+)
+
+// GetCompanyNetwork handles GET /api/companies/{company_number}/network?depth=N
+func (h *CompanyHandler) GetCompanyNetwork(w http.ResponseWriter, r *http.Request) {
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
+
+	depth := defaultNetworkDepth
+	if value := r.URL.Query().Get("depth"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxNetworkDepth {
+			respondWithError(w, http.StatusBadRequest, "Invalid depth", "depth must be between 1 and 3")
+			return
+		}
+		depth = parsed
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	network, err := h.companies.Network(ctx, number, depth, maxNetworkNodes)
+	if err != nil {
+		log.Printf("Company network error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch company network", err)
+		return
+	}
+	if network == nil {
+		respondWithError(w, http.StatusNotFound, "Company not found", "")
+		return
+	}
+
+	usage.AddRows(r.Context(), len(network.Nodes)+len(network.Edges))
+
+	respondWithJSON(w, http.StatusOK, network)
+}
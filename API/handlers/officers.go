@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/database"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+const (
+	// defaultOfficerResults and maxOfficerResults bound the people returned by an officer search
+	defaultOfficerResults = 20
+	maxOfficerResults     = 100
+	// minOfficerNameLength is the shortest name an officer search accepts
+	minOfficerNameLength = 2
+)
+
+// officerIDPattern matches officer IDs, as returned by officer_person_id
+var officerIDPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// OfficerHandler handles officer-related HTTP requests
+type OfficerHandler struct {
+	db *database.DB
+}
+
+// NewOfficerHandler creates a new officer handler
+func NewOfficerHandler(db *database.DB) *OfficerHandler {
+	return &OfficerHandler{db: db}
+}
+
+// SearchOfficers handles GET /api/officers/search?name=...
+func (h *OfficerHandler) SearchOfficers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := strings.Join(strings.Fields(query.Get("name")), " ")
+	if len(name) < minOfficerNameLength {
+		respondWithError(w, http.StatusBadRequest, "Invalid name", "name is required, e.g. John Smith or SMITH, John")
+		return
+	}
+
+	limit := defaultOfficerResults
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxOfficerResults {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", "limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+	offset := 0
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset", "offset must be 0 or more")
+			return
+		}
+		offset = parsed
+	}
+
+	ctx, cancel := h.db.WithTimeout(r.Context())
+	defer cancel()
+
+	// One extra person tells us whether there is another page
+	officers, err := h.db.SearchOfficers(ctx, name, limit+1, offset)
+	if err != nil {
+		log.Printf("Officer search error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to search officers", err)
+		return
+	}
+	hasMore := len(officers) > limit
+	if hasMore {
+		officers = officers[:limit]
+	}
+
+	appointments := 0
+	for _, o := range officers {
+		appointments += len(o.Appointments)
+	}
+	usage.AddRows(r.Context(), appointments)
+
+	respondWithJSON(w, http.StatusOK, models.OfficerSearchResponse{
+		Name:     name,
+		Officers: officers,
+		Limit:    limit,
+		Offset:   offset,
+		HasMore:  hasMore,
+	})
+}
+
+// GetOfficer handles GET /api/officers/{id}
+func (h *OfficerHandler) GetOfficer(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(mux.Vars(r)["id"])
+	if !officerIDPattern.MatchString(id) {
+		respondWithError(w, http.StatusBadRequest, "Invalid officer ID", "Officer IDs are 16 hex characters, as returned by /api/officers/search")
+		return
+	}
+
+	ctx, cancel := h.db.WithTimeout(r.Context())
+	defer cancel()
+
+	officer, err := h.db.GetOfficer(ctx, id)
+	if err != nil {
+		log.Printf("Get officer error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch officer", err)
+		return
+	}
+	if officer == nil {
+		respondWithError(w, http.StatusNotFound, "Officer not found", "")
+		return
+	}
+
+	usage.AddRows(r.Context(), len(officer.Appointments))
+
+	respondWithJSON(w, http.StatusOK, officer)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/auth"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// CreateOrganization handles POST /api/admin/organizations
+func (h *AdminHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateOrganizationRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "name is required")
+		return
+	}
+
+	if req.Tier == "" {
+		req.Tier = "standard"
+	}
+	if !h.limiter.KeyTier(req.Tier) {
+		respondWithError(w, http.StatusBadRequest, "Invalid tier", fmt.Sprintf("Unknown rate limit tier %q", req.Tier))
+		return
+	}
+	if !validQuotas(req.MonthlyRequestQuota, req.MonthlyRowQuota) {
+		respondWithError(w, http.StatusBadRequest, "Invalid quota", "Quotas must be zero or greater")
+		return
+	}
+
+	org, err := h.db.CreateOrganization(r.Context(), req)
+	if err != nil {
+		log.Printf("Create organization error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create organization", err.Error())
+		return
+	}
+
+	log.Printf("Created organization %d (%q)", org.ID, org.Name)
+
+	respondWithJSON(w, http.StatusCreated, org)
+}
+
+// ListOrganizations handles GET /api/admin/organizations
+func (h *AdminHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.db.ListOrganizations(r.Context())
+	if err != nil {
+		log.Printf("List organizations error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list organizations", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.OrganizationListResponse{Organizations: orgs})
+}
+
+// CreateUser handles POST /api/admin/organizations/{id}/users
+func (h *AdminHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	org, ok := h.loadOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateUserRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.Subject = strings.TrimSpace(req.Subject)
+	if req.Subject == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "subject is required")
+		return
+	}
+
+	user, err := h.db.CreateUser(r.Context(), org.ID, req)
+	switch {
+	case errors.Is(err, database.ErrUserConflict):
+		respondWithError(w, http.StatusConflict, "User already exists", "The subject already belongs to an organization")
+		return
+	case errors.Is(err, database.ErrOrganizationNotFound):
+		respondWithError(w, http.StatusNotFound, "Organization not found", "")
+		return
+	case err != nil:
+		log.Printf("Create user error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create user", err.Error())
+		return
+	}
+
+	log.Printf("Added user %d to organization %d", user.ID, org.ID)
+
+	respondWithJSON(w, http.StatusCreated, user)
+}
+
+// ListUsers handles GET /api/admin/organizations/{id}/users
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	org, ok := h.loadOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	users, err := h.db.ListUsers(r.Context(), org.ID)
+	if err != nil {
+		log.Printf("List users error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list users", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.UserListResponse{Users: users})
+}
+
+// DeleteUser handles DELETE /api/admin/organizations/{id}/users/{user_id}
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	org, ok := h.loadOrganization(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err.Error())
+		return
+	}
+
+	deleted, err := h.db.DeleteUser(r.Context(), org.ID, id)
+	if err != nil {
+		log.Printf("Delete user error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete user", err.Error())
+		return
+	}
+	if !deleted {
+		respondWithError(w, http.StatusNotFound, "User not found", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOrganization fetches the organization named in the URL, writing an error response if it
+// is not found. Organization admins only find their own.
+func (h *AdminHandler) loadOrganization(w http.ResponseWriter, r *http.Request) (*models.Organization, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid organization ID", err.Error())
+		return nil, false
+	}
+
+	if own := auth.FromContext(r.Context()).Organization(); own != nil && *own != id {
+		respondWithError(w, http.StatusNotFound, "Organization not found", "")
+		return nil, false
+	}
+
+	org, err := h.db.GetOrganization(r.Context(), id)
+	if err != nil {
+		log.Printf("Get organization error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch organization", err.Error())
+		return nil, false
+	}
+	if org == nil {
+		respondWithError(w, http.StatusNotFound, "Organization not found", "")
+		return nil, false
+	}
+	return org, true
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/companieshouse"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// GetCompanyPreviousNames handles GET /api/companies/{company_number}/previous-names
+func (h *CompanyHandler) GetCompanyPreviousNames(w http.ResponseWriter, r *http.Request) {
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	names, err := h.companies.PreviousNames(ctx, number)
+	if err != nil {
+		log.Printf("Get previous names error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch previous names", err)
+		return
+	}
+	if names == nil {
+		respondWithError(w, http.StatusNotFound, "Company not found", "")
+		return
+	}
+
+	usage.AddRows(r.Context(), len(names))
+
+	respondWithJSON(w, http.StatusOK, models.PreviousNamesResponse{
+		CompanyNumber: number,
+		PreviousNames: names,
+	})
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/companieshouse"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// GetCompanyPSCs handles GET /api/companies/{company_number}/pscs
+func (h *CompanyHandler) GetCompanyPSCs(w http.ResponseWriter, r *http.Request) {
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	if len(number) != 8 {
+		respondWithError(w, http.StatusBadRequest, "Invalid company number", "Company numbers are 8 characters, e.g. 01234567")
+		return
+	}
+
+	ctx, cancel := h.companies.WithTimeout(r.Context())
+	defer cancel()
+
+	pscs, err := h.companies.PSCs(ctx, number)
+	if err != nil {
+		log.Printf("List PSCs error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch PSCs", err)
+		return
+	}
+
+	usage.AddRows(r.Context(), len(pscs))
+
+	respondWithJSON(w, http.StatusOK, models.PSCListResponse{
+		CompanyNumber: number,
+		PSCs:          pscs,
+	})
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"data-co/api/models"
+	"data-co/api/sic"
+)
+
+// ReferenceHandler handles requests for reference data, which is compiled into the server
+type ReferenceHandler struct{}
+
+// NewReferenceHandler creates a new reference data handler
+func NewReferenceHandler() *ReferenceHandler {
+	return &ReferenceHandler{}
+}
+
+// GetSICTaxonomy handles GET /api/reference/sic
+func (h *ReferenceHandler) GetSICTaxonomy(w http.ResponseWriter, r *http.Request) {
+	// The hierarchy only changes with a new release, so clients may cache it for a day
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	respondWithJSON(w, http.StatusOK, models.SICResponse{Sections: sic.Sections()})
+}
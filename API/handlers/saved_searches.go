@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/auth"
+	"data-co/api/database"
+	"data-co/api/email"
+	"data-co/api/models"
+	"data-co/api/usage"
+)
+
+// SavedSearchHandler handles saved search HTTP requests
+type SavedSearchHandler struct {
+	db     *database.DB
+	mailer *email.Sender
+}
+
+// NewSavedSearchHandler creates a new saved search handler
+func NewSavedSearchHandler(db *database.DB, mailer *email.Sender) *SavedSearchHandler {
+	return &SavedSearchHandler{db: db, mailer: mailer}
+}
+
+// CreateSavedSearch handles POST /api/saved-searches
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateSavedSearchRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "name is required")
+		return
+	}
+	if len(req.Name) > 200 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "name must be at most 200 characters")
+		return
+	}
+
+	// New matches are every company matching, so a sample or a ranking has no meaning here
+	req.Filters.Sample, req.Filters.SampleSeed, req.Filters.ScoreWeights, req.Filters.Debug = 0, nil, nil, false
+	filters, ok := savedSearchFilters(w, req.Filters)
+	if !ok {
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	if req.WebhookID != nil {
+		sub, err := h.db.GetWebhookSubscription(r.Context(), owner, *req.WebhookID)
+		if err != nil {
+			log.Printf("Get webhook error: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to create saved search", err.Error())
+			return
+		}
+		if sub == nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid webhook_id", "Webhook not found")
+			return
+		}
+		if sub.Filters != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid webhook_id", "Webhooks with filters only receive company.matched_search events")
+			return
+		}
+	}
+
+	emailTo := make([]string, 0, len(req.EmailTo))
+	for _, to := range req.EmailTo {
+		emailTo = append(emailTo, strings.TrimSpace(to))
+	}
+	if len(emailTo) > 0 {
+		if !h.mailer.Enabled() {
+			respondWithError(w, http.StatusBadRequest, "Email delivery unavailable", "This server has no SMTP server configured")
+			return
+		}
+		if err := email.ValidateRecipients(emailTo); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid email_to", err.Error())
+			return
+		}
+	}
+
+	search, err := h.db.CreateSavedSearch(r.Context(), owner, req.Name, filters, req.WebhookID, emailTo)
+	if err != nil {
+		log.Printf("Create saved search error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create saved search", err.Error())
+		return
+	}
+
+	log.Printf("Created saved search %d", search.ID)
+
+	respondWithJSON(w, http.StatusCreated, search)
+}
+
+// ListSavedSearches handles GET /api/saved-searches
+func (h *SavedSearchHandler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	owner := auth.FromContext(r.Context()).OwnerID()
+	searches, err := h.db.ListSavedSearches(r.Context(), owner)
+	if err != nil {
+		log.Printf("List saved searches error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list saved searches", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.SavedSearchListResponse{SavedSearches: searches})
+}
+
+// GetSavedSearch handles GET /api/saved-searches/{id}
+func (h *SavedSearchHandler) GetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	search, ok := h.loadSavedSearch(w, r)
+	if !ok {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, search)
+}
+
+// DeleteSavedSearch handles DELETE /api/saved-searches/{id}
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid saved search ID", err.Error())
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	deleted, err := h.db.DeleteSavedSearch(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Delete saved search error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete saved search", err.Error())
+		return
+	}
+	if !deleted {
+		respondWithError(w, http.StatusNotFound, "Saved search not found", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetNewMatches handles GET /api/saved-searches/{id}/new-matches
+func (h *SavedSearchHandler) GetNewMatches(w http.ResponseWriter, r *http.Request) {
+	search, ok := h.loadSavedSearch(w, r)
+	if !ok {
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -7)
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid since", "since must be an RFC 3339 timestamp, e.g. 2024-01-01T00:00:00Z")
+			return
+		}
+		since = parsed
+	}
+
+	limit := 1000
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 10000 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", "limit must be between 1 and 10000")
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := h.db.WithTimeout(r.Context())
+	defer cancel()
+	matches, err := h.db.ListSavedSearchMatches(ctx, search.ID, since, limit)
+	if err != nil {
+		log.Printf("Saved search matches error: %v", err)
+		respondWithQueryError(ctx, w, "Failed to fetch new matches", err)
+		return
+	}
+
+	usage.AddRows(r.Context(), len(matches))
+	respondWithJSON(w, http.StatusOK, models.SavedSearchMatchesResponse{
+		SavedSearchID: search.ID,
+		Since:         since,
+		Matches:       matches,
+	})
+}
+
+// loadSavedSearch fetches the saved search named in the URL, writing an error response if it is
+// not found
+func (h *SavedSearchHandler) loadSavedSearch(w http.ResponseWriter, r *http.Request) (*models.SavedSearch, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid saved search ID", err.Error())
+		return nil, false
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	search, err := h.db.GetSavedSearch(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Get saved search error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch saved search", err.Error())
+		return nil, false
+	}
+	if search == nil {
+		respondWithError(w, http.StatusNotFound, "Saved search not found", "")
+		return nil, false
+	}
+	return search, true
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"data-co/api/database"
+)
+
+// RequestTimeoutHeader sets the query timeout of a request in milliseconds, in place of the
+// configured statement timeout and up to the configured maximum and
+// database.MaxRequestedTimeout
+const RequestTimeoutHeader = "X-Request-Timeout-Ms"
+
+// RequestTimeout applies the timeout in a request's RequestTimeoutHeader, if any, to the queries
+// made for it, rejecting a value that is not a positive number of milliseconds
+func RequestTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value := r.Header.Get(RequestTimeoutHeader)
+		if value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || ms < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid "+RequestTimeoutHeader, RequestTimeoutHeader+" must be a positive number of milliseconds")
+			return
+		}
+		// Cut to the hard ceiling here too, so the duration cannot overflow
+		ms = min(ms, database.MaxRequestedTimeout.Milliseconds())
+		ctx := database.WithRequestedTimeout(r.Context(), time.Duration(ms)*time.Millisecond)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
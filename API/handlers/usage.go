@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"data-co/api/auth"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// UsageHandler handles usage-related HTTP requests
+type UsageHandler struct {
+	db       *database.DB
+	contacts *ContactHandler
+}
+
+// NewUsageHandler creates a new usage handler, reporting the contact credits of contacts
+func NewUsageHandler(db *database.DB, contacts *ContactHandler) *UsageHandler {
+	return &UsageHandler{db: db, contacts: contacts}
+}
+
+// GetUsage handles GET /api/usage
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	principal := auth.FromContext(r.Context())
+	if principal == nil || principal.KeyID == 0 {
+		respondWithError(w, http.StatusBadRequest, "Usage not available", "Usage is only tracked for requests made with an API key")
+		return
+	}
+
+	months := 12
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 36 {
+			respondWithError(w, http.StatusBadRequest, "Invalid months", "months must be between 1 and 36")
+			return
+		}
+		months = parsed
+	}
+
+	current, err := h.db.GetCurrentUsage(r.Context(), principal.KeyID)
+	if err != nil {
+		log.Printf("Usage query error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch usage", err.Error())
+		return
+	}
+
+	history, err := h.db.ListUsage(r.Context(), principal.KeyID, months)
+	if err != nil {
+		log.Printf("Usage query error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch usage", err.Error())
+		return
+	}
+
+	contactCredits, err := h.contacts.ContactCredits(r)
+	if err != nil {
+		log.Printf("Contact credits query error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch usage", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.UsageResponse{
+		KeyID:               principal.KeyID,
+		Current:             current,
+		MonthlyRequestQuota: principal.MonthlyRequestQuota,
+		MonthlyRowQuota:     principal.MonthlyRowQuota,
+		History:             history,
+		ContactCredits:      contactCredits,
+	})
+}
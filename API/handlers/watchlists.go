@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/auth"
+	"data-co/api/companieshouse"
+	"data-co/api/database"
+	"data-co/api/models"
+	"data-co/api/slack"
+)
+
+// maxWatchlistCompanies bounds how many companies can be added in one request
+const maxWatchlistCompanies = 5000
+
+// WatchlistHandler handles watchlist-related HTTP requests
+type WatchlistHandler struct {
+	db    *database.DB
+	slack *slack.Notifier
+}
+
+// NewWatchlistHandler creates a new watchlist handler
+func NewWatchlistHandler(db *database.DB, notifier *slack.Notifier) *WatchlistHandler {
+	return &WatchlistHandler{db: db, slack: notifier}
+}
+
+// CreateWatchlist handles POST /api/watchlists
+func (h *WatchlistHandler) CreateWatchlist(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWatchlistRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "name is required")
+		return
+	}
+
+	numbers, ok := normalizeCompanyNumbers(w, req.CompanyNumbers, maxWatchlistCompanies)
+	if !ok {
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	watchlist, err := h.db.CreateWatchlist(r.Context(), owner, req.Name, numbers)
+	if err != nil {
+		log.Printf("Create watchlist error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create watchlist", err.Error())
+		return
+	}
+
+	log.Printf("Created watchlist %d with %d companies", watchlist.ID, len(numbers))
+
+	respondWithJSON(w, http.StatusCreated, watchlist)
+}
+
+// ListWatchlists handles GET /api/watchlists
+func (h *WatchlistHandler) ListWatchlists(w http.ResponseWriter, r *http.Request) {
+	owner := auth.FromContext(r.Context()).OwnerID()
+	watchlists, err := h.db.ListWatchlists(r.Context(), owner)
+	if err != nil {
+		log.Printf("List watchlists error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list watchlists", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.WatchlistListResponse{Watchlists: watchlists})
+}
+
+// GetWatchlist handles GET /api/watchlists/{id}
+func (h *WatchlistHandler) GetWatchlist(w http.ResponseWriter, r *http.Request) {
+	watchlist, ok := h.loadWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, watchlist)
+}
+
+// DeleteWatchlist handles DELETE /api/watchlists/{id}
+func (h *WatchlistHandler) DeleteWatchlist(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid watchlist ID", err.Error())
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	deleted, err := h.db.DeleteWatchlist(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Delete watchlist error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete watchlist", err.Error())
+		return
+	}
+	if !deleted {
+		respondWithError(w, http.StatusNotFound, "Watchlist not found", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddCompanies handles POST /api/watchlists/{id}/companies
+func (h *WatchlistHandler) AddCompanies(w http.ResponseWriter, r *http.Request) {
+	watchlist, ok := h.loadWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.WatchlistCompaniesRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	numbers, ok := normalizeCompanyNumbers(w, req.CompanyNumbers, maxWatchlistCompanies)
+	if !ok {
+		return
+	}
+	if len(numbers) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body", "company_numbers is required")
+		return
+	}
+
+	if err := h.db.AddWatchlistCompanies(r.Context(), watchlist.ID, numbers); err != nil {
+		log.Printf("Add watchlist companies error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to add companies", err.Error())
+		return
+	}
+
+	h.GetWatchlist(w, r)
+}
+
+// RemoveCompany handles DELETE /api/watchlists/{id}/companies/{company_number}
+func (h *WatchlistHandler) RemoveCompany(w http.ResponseWriter, r *http.Request) {
+	watchlist, ok := h.loadWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	number := companieshouse.NormalizeCompanyNumber(mux.Vars(r)["company_number"])
+	removed, err := h.db.RemoveWatchlistCompany(r.Context(), watchlist.ID, number)
+	if err != nil {
+		log.Printf("Remove watchlist company error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to remove company", err.Error())
+		return
+	}
+	if !removed {
+		respondWithError(w, http.StatusNotFound, "Company not on watchlist", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetSlack handles PUT /api/watchlists/{id}/slack. It posts a confirmation to the webhook
+// first, so a wrong URL is reported rather than saved.
+func (h *WatchlistHandler) SetSlack(w http.ResponseWriter, r *http.Request) {
+	watchlist, ok := h.loadWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.WatchlistSlackRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	req.WebhookURL = strings.TrimSpace(req.WebhookURL)
+	if !slack.ValidWebhookURL(req.WebhookURL) {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook URL", "webhook_url must be a Slack incoming webhook, https://hooks.slack.com/services/...")
+		return
+	}
+
+	text := fmt.Sprintf("Changes to companies on the *%s* watchlist will be posted here.", slack.Escape(watchlist.Name))
+	if err := h.slack.Post(r.Context(), req.WebhookURL, text); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Slack webhook rejected", err.Error())
+		return
+	}
+
+	if err := h.db.SetWatchlistSlackWebhook(r.Context(), watchlist.ID, &req.WebhookURL); err != nil {
+		log.Printf("Set watchlist Slack webhook error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update watchlist", err.Error())
+		return
+	}
+
+	h.GetWatchlist(w, r)
+}
+
+// RemoveSlack handles DELETE /api/watchlists/{id}/slack
+func (h *WatchlistHandler) RemoveSlack(w http.ResponseWriter, r *http.Request) {
+	watchlist, ok := h.loadWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.SetWatchlistSlackWebhook(r.Context(), watchlist.ID, nil); err != nil {
+		log.Printf("Remove watchlist Slack webhook error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update watchlist", err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetChanges handles GET /api/watchlists/{id}/changes?since=RFC3339
+func (h *WatchlistHandler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	watchlist, ok := h.loadWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -7)
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid since", "since must be an RFC 3339 timestamp, e.g. 2024-01-01T00:00:00Z")
+			return
+		}
+		since = parsed
+	}
+
+	limit := 1000
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 10000 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", "limit must be between 1 and 10000")
+			return
+		}
+		limit = parsed
+	}
+
+	changes, err := h.db.ListWatchlistChanges(r.Context(), watchlist.ID, since, limit)
+	if err != nil {
+		log.Printf("Watchlist changes error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch changes", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.WatchlistChangesResponse{
+		WatchlistID: watchlist.ID,
+		Since:       since,
+		Changes:     changes,
+	})
+}
+
+// loadWatchlist fetches the watchlist named in the URL, writing an error response if it is not found
+func (h *WatchlistHandler) loadWatchlist(w http.ResponseWriter, r *http.Request) (*models.Watchlist, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid watchlist ID", err.Error())
+		return nil, false
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	watchlist, err := h.db.GetWatchlist(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Get watchlist error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch watchlist", err.Error())
+		return nil, false
+	}
+	if watchlist == nil {
+		respondWithError(w, http.StatusNotFound, "Watchlist not found", "")
+		return nil, false
+	}
+	return watchlist, true
+}
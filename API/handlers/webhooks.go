@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"data-co/api/auth"
+	"data-co/api/database"
+	"data-co/api/models"
+	"data-co/api/webhooks"
+)
+
+// WebhookHandler handles webhook subscription HTTP requests
+type WebhookHandler struct {
+	db *database.DB
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(db *database.DB) *WebhookHandler {
+	return &WebhookHandler{db: db}
+}
+
+// CreateWebhook handles POST /api/webhooks
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWebhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if err := webhooks.ValidateURL(r.Context(), req.URL); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid URL", err.Error())
+		return
+	}
+
+	eventTypes := make([]string, 0, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		if !webhooks.ValidEventType(t) {
+			respondWithError(w, http.StatusBadRequest, "Invalid event type", fmt.Sprintf("Unknown event type %q", t))
+			return
+		}
+		switch {
+		case t == webhooks.EventMatchedSearch && req.Filters == nil:
+			respondWithError(w, http.StatusBadRequest, "Missing filters", t+" needs the saved search filters")
+			return
+		case t != webhooks.EventMatchedSearch && req.Filters != nil:
+			respondWithError(w, http.StatusBadRequest, "Invalid event type", "Subscriptions with filters only receive "+webhooks.EventMatchedSearch)
+			return
+		}
+		eventTypes = append(eventTypes, t)
+	}
+	if req.Filters != nil {
+		filters, ok := savedSearchFilters(w, *req.Filters)
+		if !ok {
+			return
+		}
+		req.Filters = &filters
+		eventTypes = []string{webhooks.EventMatchedSearch}
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create webhook", err.Error())
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	sub, err := h.db.CreateWebhookSubscription(r.Context(), owner, req.URL, secret, eventTypes, req.Filters)
+	if err != nil {
+		log.Printf("Create webhook error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create webhook", err.Error())
+		return
+	}
+
+	log.Printf("Created webhook %d for %s", sub.ID, sub.URL)
+
+	respondWithJSON(w, http.StatusCreated, models.CreateWebhookResponse{
+		WebhookSubscription: sub,
+		Secret:              secret,
+	})
+}
+
+// SampleSearchEvents handles POST /api/webhooks/samples/matched-search. It returns up to three
+// events for companies currently matching a search, shaped as company.matched_search deliveries,
+// for tools such as Zapier that ask for sample data when a trigger is set up.
+func (h *WebhookHandler) SampleSearchEvents(w http.ResponseWriter, r *http.Request) {
+	var req models.SearchSampleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	filters, ok := savedSearchFilters(w, req.Filters)
+	if !ok {
+		return
+	}
+	filters.Limit = 3
+
+	ctx, cancel := h.db.WithTimeout(r.Context())
+	defer cancel()
+	companies, err := h.db.FindCompanies(ctx, filters)
+	if err != nil {
+		respondWithQueryError(ctx, w, "Failed to search companies", err)
+		return
+	}
+
+	events := make([]models.SearchMatchEvent, 0, len(companies))
+	now := time.Now().UTC()
+	for i, c := range companies {
+		// Negative IDs are never used by deliveries
+		event, err := webhooks.EventFromMatch(0, int64(-i-1), now, c)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to build sample events", err.Error())
+			return
+		}
+		events = append(events, event)
+	}
+
+	respondWithJSON(w, http.StatusOK, events)
+}
+
+// savedSearchFilters checks the filters of a saved search, setting defaults as for search. It
+// responds with an error and returns false when they are invalid.
+func savedSearchFilters(w http.ResponseWriter, filters models.CompanySearchFilters) (models.CompanySearchFilters, bool) {
+	if len(filters.Fields) > 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid filters", "events include every field; remove fields")
+		return filters, false
+	}
+	filters.Limit, filters.Offset, filters.OrderBy = 0, 0, ""
+	if filters.CompanyStatus == "" {
+		filters.CompanyStatus = "active"
+	}
+	if invalid := database.ValidateFilters(filters); len(invalid) > 0 {
+		respondWithInvalidFilters(w, invalid)
+		return filters, false
+	}
+	return filters, true
+}
+
+// ListWebhooks handles GET /api/webhooks
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	owner := auth.FromContext(r.Context()).OwnerID()
+	subs, err := h.db.ListWebhookSubscriptions(r.Context(), owner)
+	if err != nil {
+		log.Printf("List webhooks error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to list webhooks", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.WebhookListResponse{Webhooks: subs})
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/{id}
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID", err.Error())
+		return
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	deleted, err := h.db.DeleteWebhookSubscription(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Delete webhook error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete webhook", err.Error())
+		return
+	}
+	if !deleted {
+		respondWithError(w, http.StatusNotFound, "Webhook not found", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeadLetters handles GET /api/webhooks/{id}/dead-letters
+func (h *WebhookHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid webhook ID", err.Error())
+		return
+	}
+
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit", "limit must be between 1 and 1000")
+			return
+		}
+		limit = parsed
+	}
+
+	owner := auth.FromContext(r.Context()).OwnerID()
+	sub, err := h.db.GetWebhookSubscription(r.Context(), owner, id)
+	if err != nil {
+		log.Printf("Get webhook error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch webhook", err.Error())
+		return
+	}
+	if sub == nil {
+		respondWithError(w, http.StatusNotFound, "Webhook not found", "")
+		return
+	}
+
+	letters, err := h.db.ListWebhookDeadLetters(r.Context(), sub.ID, limit)
+	if err != nil {
+		log.Printf("Webhook dead letters error: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch dead letters", err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, models.WebhookDeadLetterListResponse{DeadLetters: letters})
+}
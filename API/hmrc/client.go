@@ -0,0 +1,187 @@
+// Package hmrc checks UK VAT registration numbers with the HMRC check a UK VAT number API,
+// authenticating as an application with the OAuth 2.0 client credentials flow.
+package hmrc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"data-co/api/config"
+)
+
+// maxRetries bounds how often a rate-limited or failed request is retried
+const maxRetries = 5
+
+// Registration is the business HMRC has a VAT number registered to
+type Registration struct {
+	VATNumber string
+	Name      string
+	Postcode  string
+}
+
+// Client checks VAT numbers with HMRC, staying under RequestsPerSecond
+type Client struct {
+	cfg     config.HMRCConfig
+	http    *http.Client
+	limiter *rate.Limiter
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewClient creates an HMRC client. It returns nil when no application is configured.
+func NewClient(cfg config.HMRCConfig) (*Client, error) {
+	if cfg.ClientID == "" {
+		return nil, nil
+	}
+	if cfg.ClientSecret == "" {
+		return nil, errors.New("HMRC_CLIENT_SECRET is required with HMRC_CLIENT_ID")
+	}
+	if cfg.RequestsPerSecond <= 0 {
+		return nil, errors.New("HMRC_REQUESTS_PER_SECOND must be positive")
+	}
+	return &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		limiter: rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), 1),
+	}, nil
+}
+
+// Enabled reports whether an application is configured
+func (c *Client) Enabled() bool {
+	return c != nil
+}
+
+// CheckVATNumber looks up a VAT number, 9 or 12 digits without the GB prefix. It returns nil if
+// HMRC has no such registration, or rejects the number as malformed. Rate-limited (429) and
+// server error responses are retried with backoff, and an expired access token is replaced once.
+func (c *Client) CheckVATNumber(ctx context.Context, vatNumber string) (*Registration, error) {
+	path := "/organisations/vat/check-vat-number/lookup/" + url.PathEscape(vatNumber)
+
+	delay := time.Second
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.cfg.BaseURL, "/")+path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/vnd.hmrc.2.0+json")
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || attempt == maxRetries {
+				return nil, fmt.Errorf("GET %s failed: %w", path, err)
+			}
+		} else {
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			switch {
+			case resp.StatusCode == http.StatusOK:
+				var result struct {
+					Target struct {
+						Name      string `json:"name"`
+						VATNumber string `json:"vatNumber"`
+						Address   struct {
+							Postcode string `json:"postcode"`
+						} `json:"address"`
+					} `json:"target"`
+				}
+				if err := json.Unmarshal(data, &result); err != nil {
+					return nil, fmt.Errorf("invalid response from %s: %w", path, err)
+				}
+				return &Registration{VATNumber: result.Target.VATNumber, Name: result.Target.Name, Postcode: result.Target.Address.Postcode}, nil
+			case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest:
+				return nil, nil
+			case resp.StatusCode == http.StatusUnauthorized && !refreshed:
+				c.clearToken(token)
+				refreshed = true
+				continue
+			case (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500) || attempt == maxRetries:
+				return nil, fmt.Errorf("GET %s responded %s: %s", path, resp.Status, strings.TrimSpace(string(data)))
+			}
+			if wait, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+				delay = time.Duration(wait) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, time.Minute)
+	}
+}
+
+// accessToken returns a cached access token, requesting one with the client credentials flow
+// when there is none or it is about to expire
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.cfg.ClientID},
+		"client_secret": {c.cfg.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.cfg.BaseURL, "/")+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("invalid token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("HMRC token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("HMRC token request responded %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"` // Seconds
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("invalid HMRC token response: %v", err)
+	}
+	// Renewed a minute early, so it does not expire in flight
+	c.token, c.expires = token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn)*time.Second-time.Minute)
+	return c.token, nil
+}
+
+// clearToken drops a rejected access token, unless another request has already replaced it
+func (c *Client) clearToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
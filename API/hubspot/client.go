@@ -0,0 +1,187 @@
+// Package hubspot creates and updates HubSpot company records through the CRM API, keyed on a
+// unique property holding the company number so repeated syncs update rather than duplicate
+// records.
+package hubspot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"data-co/api/config"
+	"data-co/api/integrations"
+	"data-co/api/models"
+)
+
+const (
+	// BatchSize is the most companies a batch upsert request can hold
+	BatchSize = 100
+	// maxRetries bounds how often a rate-limited or failed request is retried
+	maxRetries = 6
+)
+
+// Result is the outcome of upserting one company
+type Result struct {
+	CompanyNumber string
+	ID            string
+	Created       bool
+	Error         string
+}
+
+// Client upserts companies through the HubSpot CRM API with a private app access token,
+// staying under RequestsPerSecond and backing off when HubSpot rate-limits it anyway
+type Client struct {
+	cfg     config.HubSpotConfig
+	mapping integrations.Mapping
+	http    *http.Client
+	limiter *rate.Limiter
+}
+
+// NewClient creates a HubSpot client, checking its property mapping. It returns nil when no
+// access token is configured.
+func NewClient(cfg config.HubSpotConfig) (*Client, error) {
+	if cfg.AccessToken == "" {
+		return nil, nil
+	}
+	mapping, err := integrations.ParseMapping(cfg.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("HUBSPOT_FIELDS: %w", err)
+	}
+	if cfg.RequestsPerSecond <= 0 {
+		return nil, errors.New("HUBSPOT_REQUESTS_PER_SECOND must be positive")
+	}
+	return &Client{
+		cfg:     cfg,
+		mapping: mapping,
+		http:    &http.Client{Timeout: cfg.Timeout},
+		limiter: rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), 1),
+	}, nil
+}
+
+// Enabled reports whether an access token is configured
+func (c *Client) Enabled() bool {
+	return c != nil
+}
+
+// Upsert creates or updates a company record for each of up to BatchSize companies, matched on
+// the ID property. Companies HubSpot rejects are reported in their Result; an error is returned
+// only when the request fails as a whole.
+func (c *Client) Upsert(ctx context.Context, companies []models.Company) ([]Result, error) {
+	if len(companies) > BatchSize {
+		return nil, fmt.Errorf("at most %d companies can be upserted at once", BatchSize)
+	}
+
+	type input struct {
+		IDProperty string         `json:"idProperty"`
+		ID         string         `json:"id"`
+		Properties map[string]any `json:"properties"`
+	}
+	inputs := make([]input, len(companies))
+	for i, company := range companies {
+		properties := c.mapping.Record(company)
+		properties[c.cfg.IDProperty] = company.CompanyNumber
+		inputs[i] = input{IDProperty: c.cfg.IDProperty, ID: company.CompanyNumber, Properties: properties}
+	}
+
+	var response struct {
+		Results []struct {
+			ID         string            `json:"id"`
+			New        bool              `json:"new"`
+			Properties map[string]string `json:"properties"`
+		} `json:"results"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := c.post(ctx, "/crm/v3/objects/companies/batch/upsert", map[string]any{"inputs": inputs}, &response); err != nil {
+		return nil, err
+	}
+
+	saved := make(map[string]Result, len(response.Results))
+	for _, r := range response.Results {
+		number := r.Properties[c.cfg.IDProperty]
+		saved[number] = Result{CompanyNumber: number, ID: r.ID, Created: r.New}
+	}
+	// Errors of a partly successful batch do not say which input they are for
+	messages := make([]string, 0, len(response.Errors))
+	for _, e := range response.Errors {
+		messages = append(messages, e.Message)
+	}
+	failure := strings.Join(messages, "; ")
+	if failure == "" {
+		failure = "not saved by HubSpot"
+	}
+
+	results := make([]Result, len(companies))
+	for i, company := range companies {
+		if result, ok := saved[company.CompanyNumber]; ok {
+			results[i] = result
+		} else {
+			results[i] = Result{CompanyNumber: company.CompanyNumber, Error: failure}
+		}
+	}
+	return results, nil
+}
+
+// post sends a JSON request and decodes the response into v. Rate-limited (429) and server
+// error responses are retried, waiting as long as Retry-After asks when it is given.
+func (c *Client) post(ctx context.Context, path string, body, v any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.cfg.BaseURL, "/")+path, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("invalid request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.cfg.AccessToken)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || attempt == maxRetries {
+				return fmt.Errorf("POST %s failed: %w", path, err)
+			}
+		} else {
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			switch {
+			case resp.StatusCode < 300:
+				// 207 Multi-Status carries both results and errors
+				if err := json.Unmarshal(data, v); err != nil {
+					return fmt.Errorf("invalid response from %s: %w", path, err)
+				}
+				return nil
+			case (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500) || attempt == maxRetries:
+				return fmt.Errorf("POST %s responded %s: %s", path, resp.Status, strings.TrimSpace(string(data)))
+			}
+			if wait, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+				delay = time.Duration(wait) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, time.Minute)
+	}
+}
@@ -0,0 +1,107 @@
+// Package integrations holds what the CRM connectors share: mappings from CRM fields to
+// company fields, and company values in the plain form CRM APIs accept.
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"data-co/api/apiversion"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// Mapping maps CRM field names to the company fields they are set from
+type Mapping map[string]string
+
+// ParseMapping parses a mapping of the form "CrmField=company_field,...", rejecting company
+// fields that do not exist
+func ParseMapping(spec string) (Mapping, error) {
+	mapping := make(Mapping)
+	names := database.CompanyFieldNames()
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		target, field, ok := strings.Cut(entry, "=")
+		target, field = strings.TrimSpace(target), strings.TrimSpace(field)
+		if !ok || target == "" || field == "" {
+			return nil, fmt.Errorf("malformed field mapping %q (want CrmField=company_field)", entry)
+		}
+		if !slices.Contains(names, field) {
+			return nil, fmt.Errorf("field mapping %q: %q is not a company field", entry, field)
+		}
+		mapping[target] = field
+	}
+	if len(mapping) == 0 {
+		return nil, fmt.Errorf("field mapping %q maps no fields", spec)
+	}
+	return mapping, nil
+}
+
+// Record returns the mapped values of a company. Fields without a value are left out, so
+// syncing does not clear values entered in the CRM.
+func (m Mapping) Record(c models.Company) map[string]any {
+	values := Values(c)
+	record := make(map[string]any, len(m))
+	for target, field := range m {
+		if value, ok := values[field]; ok && value != nil {
+			record[target] = value
+		}
+	}
+	return record
+}
+
+// dateFields are the company fields holding dates, which CRMs take as YYYY-MM-DD
+var dateFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(models.Company{})
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Type == reflect.TypeOf(&time.Time{}) {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// Values returns a company's fields by name with plain values: nullable fields as their value
+// or nil, dates as YYYY-MM-DD and lists joined with semicolons, as multi-select CRM fields take
+// them
+func Values(c models.Company) map[string]any {
+	values := make(map[string]any)
+	data, err := json.Marshal(c)
+	if err != nil {
+		return values
+	}
+	if data, err = apiversion.Clean(data); err != nil {
+		return values
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return values
+	}
+
+	for name, value := range values {
+		switch v := value.(type) {
+		case string:
+			if dateFields[name] && len(v) >= 10 {
+				values[name] = v[:10]
+			}
+		case []any:
+			parts := make([]string, len(v))
+			for i, part := range v {
+				parts[i] = fmt.Sprint(part)
+			}
+			values[name] = strings.Join(parts, ";")
+		}
+	}
+	return values
+}
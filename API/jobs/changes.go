@@ -0,0 +1,274 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// changeBatchSize is how many companies are compared per database round trip
+const changeBatchSize = 1000
+
+// ingestionCursor names the job cursor tracking how far ingested staging rows have been scanned
+const ingestionCursor = "change_detection.ingestion"
+
+// ChangeListener is notified of changes after they have been recorded
+type ChangeListener func(ctx context.Context, changes []models.CompanyChange)
+
+// ChangeDetector compares watched and newly ingested companies against their last snapshot
+// and records changes
+type ChangeDetector struct {
+	db        *database.DB
+	listeners []ChangeListener
+}
+
+// NewChangeDetector creates a change detector
+func NewChangeDetector(db *database.DB) *ChangeDetector {
+	return &ChangeDetector{db: db}
+}
+
+// OnChanges registers a listener called with each batch of newly recorded changes
+func (d *ChangeDetector) OnChanges(listener ChangeListener) {
+	d.listeners = append(d.listeners, listener)
+}
+
+// Start runs detection every interval until ctx is cancelled. An interval of zero disables the job.
+func (d *ChangeDetector) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("Change detection job disabled")
+		return
+	}
+
+	log.Printf("Detecting watched company changes every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := d.Run(ctx)
+				if err != nil {
+					log.Printf("Change detection failed: %v", err)
+					continue
+				}
+				if count > 0 {
+					log.Printf("Detected %d company changes", count)
+				}
+			}
+		}
+	}()
+}
+
+// Run performs one detection pass over all watched companies and companies ingested since
+// the previous pass, and returns the number of changes
+func (d *ChangeDetector) Run(ctx context.Context) (int, error) {
+	numbers, err := d.db.WatchedCompanyNumbers(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// On the very first run, start tracking ingestion from now rather than replaying history
+	since, err := d.db.GetJobCursor(ctx, ingestionCursor)
+	if err != nil {
+		return 0, err
+	}
+	if since == nil {
+		now := time.Now()
+		since = &now
+	}
+
+	updated, position, err := d.db.CompaniesUpdatedSince(ctx, *since)
+	if err != nil {
+		return 0, err
+	}
+	numbers = mergeNumbers(numbers, updated)
+
+	total := 0
+	for start := 0; start < len(numbers); start += changeBatchSize {
+		end := start + changeBatchSize
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+
+		count, err := d.runBatch(ctx, numbers[start:end])
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+
+	if err := d.db.SetJobCursor(ctx, ingestionCursor, position); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+// mergeNumbers returns the union of two lists of company numbers
+func mergeNumbers(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, number := range list {
+			if !seen[number] {
+				seen[number] = true
+				merged = append(merged, number)
+			}
+		}
+	}
+	return merged
+}
+
+func (d *ChangeDetector) runBatch(ctx context.Context, numbers []string) (int, error) {
+	current, err := d.db.CurrentCompanyStates(ctx, numbers)
+	if err != nil {
+		return 0, err
+	}
+	previous, err := d.db.SnapshotCompanyStates(ctx, numbers)
+	if err != nil {
+		return 0, err
+	}
+
+	changes := make([]models.CompanyChange, 0)
+	fieldChanges := make([]database.FieldChange, 0)
+	states := make([]database.CompanyState, 0, len(current))
+	for number, cur := range current {
+		// The first observation of a company only establishes its baseline
+		if prev, ok := previous[number]; ok {
+			changes = append(changes, diffCompanyState(prev, cur)...)
+			fieldChanges = append(fieldChanges, diffCompanyFields(prev, cur)...)
+		}
+		states = append(states, cur)
+	}
+
+	saved, err := d.db.SaveCompanyChanges(ctx, changes, fieldChanges, states)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(saved) > 0 {
+		for _, listener := range d.listeners {
+			listener(ctx, saved)
+		}
+	}
+	return len(saved), nil
+}
+
+// diffCompanyState lists the changes between two observations of a company
+func diffCompanyState(prev, cur database.CompanyState) []models.CompanyChange {
+	changes := make([]models.CompanyChange, 0)
+	add := func(changeType string, oldValue, newValue *string) {
+		changes = append(changes, models.CompanyChange{
+			CompanyNumber: cur.CompanyNumber,
+			ChangeType:    changeType,
+			OldValue:      oldValue,
+			NewValue:      newValue,
+		})
+	}
+
+	if !equalStrings(prev.CompanyStatus, cur.CompanyStatus) {
+		add("status_changed", prev.CompanyStatus, cur.CompanyStatus)
+	}
+	if !equalDates(prev.AccountsLastMadeUpDate, cur.AccountsLastMadeUpDate) {
+		add("new_accounts", formatDate(prev.AccountsLastMadeUpDate), formatDate(cur.AccountsLastMadeUpDate))
+	}
+	if !equalDates(prev.LatestPeriodEnd, cur.LatestPeriodEnd) {
+		add("new_financials", formatDate(prev.LatestPeriodEnd), formatDate(cur.LatestPeriodEnd))
+	}
+	if appointed := cur.TotalOfficers - prev.TotalOfficers; appointed > 0 {
+		add("officer_appointed", formatInt(prev.TotalOfficers), formatInt(cur.TotalOfficers))
+	}
+	if resigned := cur.ResignedOfficers - prev.ResignedOfficers; resigned > 0 {
+		add("officer_resigned", formatInt(prev.ResignedOfficers), formatInt(cur.ResignedOfficers))
+	}
+
+	return changes
+}
+
+// diffCompanyFields lists the field-level changes between two observations of a company for its
+// change history. Fields a snapshot predates are only compared from the next observation.
+func diffCompanyFields(prev, cur database.CompanyState) []database.FieldChange {
+	changes := make([]database.FieldChange, 0)
+	add := func(field string, oldValue, newValue *string) {
+		changes = append(changes, database.FieldChange{
+			CompanyNumber: cur.CompanyNumber,
+			Field:         field,
+			OldValue:      oldValue,
+			NewValue:      newValue,
+		})
+	}
+
+	if prev.FieldsTracked && !equalStrings(prev.CompanyName, cur.CompanyName) {
+		add("company_name", prev.CompanyName, cur.CompanyName)
+	}
+	if !equalStrings(prev.CompanyStatus, cur.CompanyStatus) {
+		add("company_status", prev.CompanyStatus, cur.CompanyStatus)
+	}
+	if prev.FieldsTracked && !equalStrings(prev.RegisteredAddress, cur.RegisteredAddress) {
+		add("registered_address", prev.RegisteredAddress, cur.RegisteredAddress)
+	}
+	prevActive, curActive := prev.TotalOfficers-prev.ResignedOfficers, cur.TotalOfficers-cur.ResignedOfficers
+	if prevActive != curActive {
+		add("active_officers", formatInt(prevActive), formatInt(curActive))
+	}
+	if !equalDates(prev.LatestPeriodEnd, cur.LatestPeriodEnd) {
+		add("latest_period_end", formatDate(prev.LatestPeriodEnd), formatDate(cur.LatestPeriodEnd))
+	}
+	if prev.FieldsTracked && !equalFloats(prev.LatestTurnover, cur.LatestTurnover) {
+		add("turnover", formatFloat(prev.LatestTurnover), formatFloat(cur.LatestTurnover))
+	}
+	if prev.FieldsTracked && !equalFloats(prev.LatestNetAssets, cur.LatestNetAssets) {
+		add("net_assets", formatFloat(prev.LatestNetAssets), formatFloat(cur.LatestNetAssets))
+	}
+
+	return changes
+}
+
+func equalStrings(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalDates(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func equalFloats(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02")
+	return &s
+}
+
+func formatInt(n int) *string {
+	s := strconv.Itoa(n)
+	return &s
+}
+
+func formatFloat(f *float64) *string {
+	if f == nil {
+		return nil
+	}
+	s := strconv.FormatFloat(*f, 'f', -1, 64)
+	return &s
+}
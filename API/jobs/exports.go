@@ -0,0 +1,313 @@
+package jobs
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"data-co/api/apiversion"
+	"data-co/api/client"
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/email"
+	"data-co/api/models"
+	"data-co/api/storage"
+)
+
+const (
+	// partialDir is the subdirectory of the export directory files are written to before they
+	// are saved to storage
+	partialDir = ".partial"
+	// exportPageSize is how many companies are read per query while exporting
+	exportPageSize = 5000
+	// exportLease is how long a claimed export is held without progress before another worker
+	// may take it over; it is extended after every page
+	exportLease = 10 * time.Minute
+)
+
+// ExportWorker runs queued export jobs, writing their files to the export directory, saving
+// them to storage and emailing them to any recipients
+type ExportWorker struct {
+	db     *database.DB
+	store  storage.Store
+	mailer *email.Sender
+	cfg    config.ExportsConfig
+}
+
+// NewExportWorker creates an export worker
+func NewExportWorker(db *database.DB, store storage.Store, mailer *email.Sender, cfg config.ExportsConfig) *ExportWorker {
+	return &ExportWorker{db: db, store: store, mailer: mailer, cfg: cfg}
+}
+
+// Start runs Workers export workers, each polling for queued exports every PollInterval, until
+// ctx is cancelled. Zero workers or a poll interval of zero disables exports.
+func (e *ExportWorker) Start(ctx context.Context) {
+	if e.cfg.Workers <= 0 || e.cfg.PollInterval <= 0 {
+		log.Printf("Export workers disabled")
+		return
+	}
+	if err := os.MkdirAll(filepath.Join(e.cfg.Dir, partialDir), 0o755); err != nil {
+		log.Printf("Export workers disabled: %v", err)
+		return
+	}
+
+	log.Printf("Running %d export workers, writing to %s storage", e.cfg.Workers, e.cfg.Storage)
+
+	for i := 0; i < e.cfg.Workers; i++ {
+		go e.work(ctx, i == 0)
+	}
+}
+
+// work runs queued exports until none are left, then waits for the next poll. The first
+// worker also fails abandoned exports and deletes expired files.
+func (e *ExportWorker) work(ctx context.Context, cleanup bool) {
+	ticker := time.NewTicker(e.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if cleanup {
+			e.cleanup(ctx)
+		}
+		for ctx.Err() == nil {
+			task, err := e.db.ClaimExportJob(ctx, exportLease, e.cfg.MaxAttempts)
+			if err != nil {
+				log.Printf("Export claim failed: %v", err)
+				break
+			}
+			if task == nil {
+				break
+			}
+			e.run(ctx, task)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run writes one export's file, saves it to storage and records the outcome. An export
+// interrupted by shutdown is left running, so it is claimed again once its lease passes.
+func (e *ExportWorker) run(ctx context.Context, task *database.ExportTask) {
+	path := filepath.Join(e.cfg.Dir, partialDir, fmt.Sprintf("%d.%s", task.ID, task.Format))
+	key := storage.Key(task.OwnerID, task.ID, task.Format)
+	rows, err := e.write(ctx, task, path)
+	var size int64
+	if err == nil {
+		if info, statErr := os.Stat(path); statErr == nil {
+			size = info.Size()
+		}
+		err = e.store.Save(ctx, key, path)
+	}
+	if err != nil {
+		os.Remove(path)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Export %d failed: %v", task.ID, err)
+		if err := e.db.FailExportJob(ctx, task.ID, err.Error()); err != nil {
+			log.Printf("Export error: %v", err)
+		}
+		return
+	}
+
+	expiresAt := time.Now().Add(e.cfg.Retention)
+	if err := e.db.CompleteExportJob(ctx, task.ID, rows, key, expiresAt); err != nil {
+		log.Printf("Export error: %v", err)
+		return
+	}
+	log.Printf("Export %d completed: %d companies", task.ID, rows)
+
+	if task.Email != nil {
+		sendErr := e.email(ctx, task, key, rows, size, expiresAt)
+		if sendErr != nil {
+			log.Printf("Export %d email failed: %v", task.ID, sendErr)
+		}
+		if err := e.db.RecordExportEmail(ctx, task.ID, sendErr); err != nil {
+			log.Printf("Export error: %v", err)
+		}
+	}
+}
+
+// email sends a finished export to its recipients, attached if asked for and no larger than
+// the attachment limit, and otherwise as a link: a signed URL valid until the file expires
+// with bucket storage, or the API's download endpoint
+func (e *ExportWorker) email(ctx context.Context, task *database.ExportTask, key string, rows int, size int64, expiresAt time.Time) error {
+	filename := fmt.Sprintf("companies-export-%d.%s", task.ID, task.Format)
+	msg := email.Message{
+		To:      task.Email.To,
+		Subject: fmt.Sprintf("Company export %d is ready", task.ID),
+	}
+	body := fmt.Sprintf("Your export of %d companies (%s) is ready.\n\n", rows, task.Format)
+
+	if task.Email.Attach && size <= e.mailer.MaxAttachment() {
+		file, err := e.store.Open(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to read export file: %w", err)
+		}
+		defer file.Close()
+		msg.Attachments = []email.Attachment{{Filename: filename, ContentType: storage.ContentType(key), Content: file}}
+		body += "The file is attached.\n"
+	} else {
+		if task.Email.Attach {
+			body += fmt.Sprintf("The file is too large to attach (%d MB), so it can be downloaded instead.\n\n", size>>20)
+		}
+		until := expiresAt
+		if link := e.store.SignedURL(key, filename, time.Until(expiresAt)); link != "" {
+			if limit := time.Now().Add(storage.MaxURLExpiry); limit.Before(until) {
+				until = limit
+			}
+			body += "Download it from:\n" + link + "\n"
+		} else {
+			link = fmt.Sprintf("%s/api/exports/%d/download", e.cfg.PublicURL, task.ID)
+			body += "Download it with an exporter API key from:\n" + link + "\n"
+		}
+		body += fmt.Sprintf("\nThe link works until %s.\n", until.UTC().Format("2 January 2006 15:04 MST"))
+	}
+	msg.Body = body
+
+	return e.mailer.Send(ctx, msg)
+}
+
+// write pages through the companies matching an export's filters into the file at path, and
+// returns how many were written
+func (e *ExportWorker) write(ctx context.Context, task *database.ExportTask, path string) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	buf := bufio.NewWriter(file)
+	out := newExportWriter(buf, task.Format)
+	if err := out.header(); err != nil {
+		return 0, err
+	}
+
+	// The total only drives progress, so an export still runs when counting times out
+	var total *int
+	countCtx, cancel := e.db.WithTimeout(ctx)
+	if count, err := e.db.CountCompanies(countCtx, task.Filters); err == nil {
+		total = &count
+	}
+	cancel()
+	if err := e.db.UpdateExportProgress(ctx, task.ID, 0, total, exportLease); err != nil {
+		return 0, err
+	}
+
+	written, after := 0, ""
+	for {
+		pageCtx, cancel := e.db.WithTimeout(ctx)
+		companies, err := e.db.ExportCompaniesPage(pageCtx, task.Filters, after, exportPageSize)
+		cancel()
+		if err != nil {
+			return written, err
+		}
+
+		for _, c := range companies {
+			if err := out.company(c); err != nil {
+				return written, fmt.Errorf("failed to write export file: %w", err)
+			}
+		}
+		written += len(companies)
+
+		if len(companies) < exportPageSize {
+			break
+		}
+		after = companies[len(companies)-1].CompanyNumber
+		if err := e.db.UpdateExportProgress(ctx, task.ID, written, nil, exportLease); err != nil {
+			return written, err
+		}
+	}
+
+	if err := out.flush(); err != nil {
+		return written, fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := buf.Flush(); err != nil {
+		return written, fmt.Errorf("failed to write export file: %w", err)
+	}
+	return written, file.Close()
+}
+
+// cleanup fails exports that keep stopping part way and deletes the files of expired exports
+func (e *ExportWorker) cleanup(ctx context.Context) {
+	if count, err := e.db.FailAbandonedExportJobs(ctx, e.cfg.MaxAttempts); err != nil {
+		log.Printf("Export cleanup failed: %v", err)
+	} else if count > 0 {
+		log.Printf("Failed %d abandoned exports", count)
+	}
+
+	expired, err := e.db.ListExpiredExports(ctx)
+	if err != nil {
+		log.Printf("Export cleanup failed: %v", err)
+		return
+	}
+	for _, export := range expired {
+		if export.FileKey != "" {
+			if err := e.store.Delete(ctx, export.FileKey); err != nil {
+				log.Printf("Export cleanup failed: %v", err)
+				continue
+			}
+		}
+		if err := e.db.MarkExportExpired(ctx, export.ID); err != nil {
+			log.Printf("Export cleanup failed: %v", err)
+		}
+	}
+}
+
+// exportWriter writes companies in an export format
+type exportWriter struct {
+	w   io.Writer
+	csv *csv.Writer // Set for CSV exports
+}
+
+// newExportWriter creates a writer for format, "csv" or "ndjson"
+func newExportWriter(w io.Writer, format string) *exportWriter {
+	if format == models.ExportFormatCSV {
+		return &exportWriter{w: w, csv: csv.NewWriter(w)}
+	}
+	return &exportWriter{w: w}
+}
+
+// header writes the CSV header row; NDJSON has none
+func (x *exportWriter) header() error {
+	if x.csv == nil {
+		return nil
+	}
+	return x.csv.Write(client.ExportColumns)
+}
+
+// company writes a CSV row, or a JSON line with plain nullable fields as version 2 does
+func (x *exportWriter) company(c models.Company) error {
+	if x.csv != nil {
+		return x.csv.Write(client.CSVRecord(c))
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	line, err := apiversion.Clean(data)
+	if err != nil {
+		return err
+	}
+	_, err = x.w.Write(line)
+	return err
+}
+
+// flush writes any buffered CSV rows
+func (x *exportWriter) flush() error {
+	if x.csv == nil {
+		return nil
+	}
+	x.csv.Flush()
+	return x.csv.Error()
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"data-co/api/database"
+)
+
+// geocodeBatchSize is how many companies are geocoded per database round trip
+const geocodeBatchSize = 10000
+
+// StartGeocoding periodically copies postcode coordinates onto companies until ctx is
+// cancelled. Every company is checked on each run, so companies pick up coordinates after a
+// change of address or a postcode directory import. An interval of zero disables the job.
+func StartGeocoding(ctx context.Context, db *database.DB, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("Geocoding job disabled")
+		return
+	}
+
+	log.Printf("Geocoding company addresses every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				count, err := Geocode(ctx, db)
+				if err != nil {
+					log.Printf("Geocoding failed: %v", err)
+					continue
+				}
+				if count > 0 {
+					log.Printf("Geocoded %d companies in %s", count, time.Since(start))
+				}
+			}
+		}
+	}()
+}
+
+// Geocode updates the coordinates of every company whose postcode's coordinates differ from
+// those stored, and returns how many were updated
+func Geocode(ctx context.Context, db *database.DB) (int, error) {
+	total := 0
+	after := ""
+	for {
+		updated, last, err := db.GeocodeCompanies(ctx, after, geocodeBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += updated
+		if last == "" {
+			return total, nil
+		}
+		after = last
+	}
+}
@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"data-co/api/database"
+	"data-co/api/scoring"
+)
+
+// healthBatchSize is how many companies are scored per database round trip
+const healthBatchSize = 5000
+
+// StartHealthScoring periodically scores companies whose latest financial period has not been
+// scored yet, until ctx is cancelled. An interval of zero disables the job.
+func StartHealthScoring(ctx context.Context, db *database.DB, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("Health scoring job disabled")
+		return
+	}
+
+	log.Printf("Scoring company financial health every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				count, err := ScoreHealth(ctx, db)
+				if err != nil {
+					log.Printf("Health scoring failed: %v", err)
+					continue
+				}
+				if count > 0 {
+					log.Printf("Scored %d companies in %s", count, time.Since(start))
+				}
+			}
+		}
+	}()
+}
+
+// ScoreHealth scores every company with an unscored latest financial period and returns how
+// many were scored
+func ScoreHealth(ctx context.Context, db *database.DB) (int, error) {
+	total := 0
+	for {
+		inputs, err := db.PendingHealthScores(ctx, healthBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(inputs) == 0 {
+			return total, nil
+		}
+
+		scores := make([]database.HealthScore, len(inputs))
+		for i, in := range inputs {
+			scores[i] = database.HealthScore{CompanyNumber: in.CompanyNumber, PeriodEnd: in.PeriodEnd}
+			if score, band, ok := scoring.Health(in.Financials); ok {
+				score = math.Round(score*100) / 100
+				scores[i].Score, scores[i].Band = &score, &band
+			}
+		}
+		if err := db.SaveHealthScores(ctx, scores); err != nil {
+			return total, err
+		}
+		total += len(scores)
+	}
+}
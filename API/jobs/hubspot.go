@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/hubspot"
+	"data-co/api/models"
+)
+
+// hubSpotLease is how long a claimed sync is held without progress before it may be claimed
+// again; it is extended after every batch
+const hubSpotLease = 10 * time.Minute
+
+// HubSpotSyncer runs queued HubSpot syncs one at a time, since they share the API rate limit
+type HubSpotSyncer struct {
+	db     *database.DB
+	client *hubspot.Client
+	cfg    config.HubSpotConfig
+}
+
+// NewHubSpotSyncer creates a HubSpot sync worker
+func NewHubSpotSyncer(db *database.DB, client *hubspot.Client, cfg config.HubSpotConfig) *HubSpotSyncer {
+	return &HubSpotSyncer{db: db, client: client, cfg: cfg}
+}
+
+// Start polls for queued syncs every PollInterval until ctx is cancelled. It does nothing
+// without a HubSpot client, and a poll interval of zero disables it.
+func (s *HubSpotSyncer) Start(ctx context.Context) {
+	if !s.client.Enabled() || s.cfg.PollInterval <= 0 {
+		log.Printf("HubSpot sync disabled")
+		return
+	}
+
+	log.Printf("Running HubSpot syncs every %s", s.cfg.PollInterval)
+
+	go func() {
+		ticker := time.NewTicker(s.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			if count, err := s.db.FailAbandonedHubSpotSyncs(ctx, s.cfg.MaxAttempts); err != nil {
+				log.Printf("HubSpot sync cleanup failed: %v", err)
+			} else if count > 0 {
+				log.Printf("Failed %d abandoned HubSpot syncs", count)
+			}
+
+			for ctx.Err() == nil {
+				task, err := s.db.ClaimHubSpotSync(ctx, hubSpotLease, s.cfg.MaxAttempts)
+				if err != nil {
+					log.Printf("HubSpot sync claim failed: %v", err)
+					break
+				}
+				if task == nil {
+					break
+				}
+				s.run(ctx, task)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// run syncs one task and records the outcome. A sync interrupted by shutdown is left running,
+// so it is claimed again once its lease passes.
+func (s *HubSpotSyncer) run(ctx context.Context, task *database.HubSpotSyncTask) {
+	progress, err := s.sync(ctx, task)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		log.Printf("HubSpot sync %d failed: %v", task.ID, err)
+		if err := s.db.FailHubSpotSync(ctx, task.ID, err.Error()); err != nil {
+			log.Printf("HubSpot sync error: %v", err)
+		}
+		return
+	}
+
+	if err := s.db.CompleteHubSpotSync(ctx, task.ID, progress); err != nil {
+		log.Printf("HubSpot sync error: %v", err)
+		return
+	}
+	log.Printf("HubSpot sync %d completed: %d created, %d updated, %d failed", task.ID, progress.Created, progress.Updated, progress.Failed)
+}
+
+// sync upserts the task's companies in batches, recording progress after each
+func (s *HubSpotSyncer) sync(ctx context.Context, task *database.HubSpotSyncTask) (database.HubSpotSyncProgress, error) {
+	progress := database.HubSpotSyncProgress{CompanyErrors: make([]string, 0)}
+	next, err := s.batches(ctx, task, &progress)
+	if err != nil {
+		return progress, err
+	}
+
+	for {
+		companies, err := next()
+		if err != nil {
+			return progress, err
+		}
+		if len(companies) == 0 {
+			return progress, nil
+		}
+
+		results, err := s.client.Upsert(ctx, companies)
+		if err != nil {
+			return progress, err
+		}
+		for _, result := range results {
+			switch {
+			case result.Error != "":
+				progress.Fail(result.CompanyNumber, result.Error)
+			case result.Created:
+				progress.Created++
+			default:
+				progress.Updated++
+			}
+		}
+		progress.Processed += len(companies)
+
+		if err := s.db.UpdateHubSpotSyncProgress(ctx, task.ID, progress, hubSpotLease); err != nil {
+			return progress, err
+		}
+	}
+}
+
+// batches returns a function yielding the task's companies a batch at a time, and an empty
+// batch once they are exhausted. It sets the progress total.
+func (s *HubSpotSyncer) batches(ctx context.Context, task *database.HubSpotSyncTask, progress *database.HubSpotSyncProgress) (func() ([]models.Company, error), error) {
+	if task.WatchlistID != nil {
+		watchlist, err := s.db.GetWatchlist(ctx, task.OwnerID, *task.WatchlistID)
+		if err != nil {
+			return nil, err
+		}
+		if watchlist == nil {
+			return nil, errors.New("the watchlist no longer exists")
+		}
+		numbers := watchlist.CompanyNumbers
+		total := len(numbers)
+		progress.Total = &total
+
+		return func() ([]models.Company, error) {
+			for len(numbers) > 0 {
+				batch := numbers[:min(hubspot.BatchSize, len(numbers))]
+				numbers = numbers[len(batch):]
+
+				queryCtx, cancel := s.db.WithTimeout(ctx)
+				found, err := s.db.GetCompaniesByNumber(queryCtx, batch)
+				cancel()
+				if err != nil {
+					return nil, err
+				}
+				companies := make([]models.Company, 0, len(found))
+				for _, number := range batch {
+					if c, ok := found[number]; ok {
+						companies = append(companies, c)
+					} else {
+						progress.Fail(number, "company not found")
+						progress.Processed++
+					}
+				}
+				if len(companies) > 0 {
+					return companies, nil
+				}
+			}
+			return nil, nil
+		}, nil
+	}
+
+	if task.Filters == nil {
+		return nil, fmt.Errorf("sync %d has neither a watchlist nor filters", task.ID)
+	}
+	filters := *task.Filters
+	countCtx, cancel := s.db.WithTimeout(ctx)
+	if count, err := s.db.CountCompanies(countCtx, filters); err == nil {
+		total := min(count, task.MaxCompanies)
+		progress.Total = &total
+	}
+	cancel()
+
+	remaining, after := task.MaxCompanies, ""
+	return func() ([]models.Company, error) {
+		if remaining <= 0 {
+			return nil, nil
+		}
+		pageCtx, cancel := s.db.WithTimeout(ctx)
+		companies, err := s.db.ExportCompaniesPage(pageCtx, filters, after, min(hubspot.BatchSize, remaining))
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		if len(companies) > 0 {
+			after = companies[len(companies)-1].CompanyNumber
+		}
+		remaining -= len(companies)
+		if len(companies) < hubspot.BatchSize {
+			remaining = 0
+		}
+		return companies, nil
+	}, nil
+}
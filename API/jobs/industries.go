@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"data-co/api/database"
+)
+
+// StartIndustryRefresh loads the industry filter's SIC mapping from the database, then reloads
+// it periodically until ctx is cancelled, so changes made through another server are picked up.
+// An interval of zero loads the mapping once.
+func StartIndustryRefresh(ctx context.Context, db *database.DB, interval time.Duration) {
+	if err := db.LoadIndustries(ctx); err != nil {
+		log.Printf("Failed to load industries, using built-in defaults: %v", err)
+	}
+
+	if interval <= 0 {
+		log.Printf("Industry refresh job disabled")
+		return
+	}
+
+	log.Printf("Refreshing industries every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := db.LoadIndustries(ctx); err != nil {
+					log.Printf("Industry refresh failed: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"data-co/api/database"
+	"data-co/api/scoring"
+)
+
+// riskBatchSize is how many companies are rated per database round trip
+const riskBatchSize = 5000
+
+// StartRiskRating periodically re-rates every company's credit risk until ctx is cancelled.
+// Ratings depend on filing deadlines as well as ingested data, so every company is re-rated on
+// each run. An interval of zero disables the job.
+func StartRiskRating(ctx context.Context, db *database.DB, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("Risk rating job disabled")
+		return
+	}
+
+	log.Printf("Rating company credit risk every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				count, err := RateRisk(ctx, db)
+				if err != nil {
+					log.Printf("Risk rating failed: %v", err)
+					continue
+				}
+				log.Printf("Rated %d companies in %s", count, time.Since(start))
+			}
+		}
+	}()
+}
+
+// RateRisk rates every company and returns how many were rated
+func RateRisk(ctx context.Context, db *database.DB) (int, error) {
+	total := 0
+	after := ""
+	for {
+		inputs, err := db.RiskInputs(ctx, after, riskBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(inputs) == 0 {
+			return total, nil
+		}
+
+		ratings := make([]database.RiskRating, len(inputs))
+		for i, in := range inputs {
+			points, band, flags := scoring.Risk(in.Signals)
+			ratings[i] = database.RiskRating{CompanyNumber: in.CompanyNumber, Points: points, Band: band, Flags: flags}
+		}
+		if err := db.SaveRiskRatings(ctx, ratings); err != nil {
+			return total, err
+		}
+		total += len(ratings)
+		after = inputs[len(inputs)-1].CompanyNumber
+	}
+}
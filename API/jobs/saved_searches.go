@@ -0,0 +1,194 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"data-co/api/database"
+	"data-co/api/email"
+	"data-co/api/models"
+	"data-co/api/webhooks"
+)
+
+const (
+	// savedSearchCursor names the job cursor tracking how far updated companies have been
+	// evaluated against saved searches
+	savedSearchCursor = "saved_searches.new_matches"
+	// savedSearchBatchSize is how many updated companies are evaluated per query
+	savedSearchBatchSize = 1000
+	// alertCompanyNumbers bounds the company numbers in an alert webhook event
+	alertCompanyNumbers = 100
+	// alertEmailCompanies bounds the companies listed in an alert email
+	alertEmailCompanies = 20
+)
+
+// SavedSearchAlerter evaluates saved searches against new and changed companies, records the
+// companies newly matching each, and alerts the search's webhook and email recipients
+type SavedSearchAlerter struct {
+	db        *database.DB
+	mailer    *email.Sender
+	publicURL string
+}
+
+// NewSavedSearchAlerter creates a saved search alerter. publicURL is the API's base URL, linked
+// to from alert emails.
+func NewSavedSearchAlerter(db *database.DB, mailer *email.Sender, publicURL string) *SavedSearchAlerter {
+	return &SavedSearchAlerter{db: db, mailer: mailer, publicURL: publicURL}
+}
+
+// Start evaluates saved searches every interval until ctx is cancelled. An interval of zero
+// disables the job.
+func (a *SavedSearchAlerter) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("Saved search alerts disabled")
+		return
+	}
+
+	log.Printf("Evaluating saved searches every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := a.Run(ctx)
+				if err != nil {
+					log.Printf("Saved search evaluation failed: %v", err)
+					continue
+				}
+				if count > 0 {
+					log.Printf("Found %d new saved search matches", count)
+				}
+			}
+		}
+	}()
+}
+
+// Run evaluates every saved search against the companies updated since the previous run and
+// returns the number of new matches. A search's first evaluation records the companies already
+// matching as its baseline instead. A failed run is repeated by the next, which is safe because
+// each match is recorded once.
+func (a *SavedSearchAlerter) Run(ctx context.Context) (int, error) {
+	since, err := a.db.GetJobCursor(ctx, savedSearchCursor)
+	if err != nil {
+		return 0, err
+	}
+	var numbers []string
+	position := time.Now()
+	if since != nil {
+		if numbers, position, err = a.db.CompaniesUpdatedSince(ctx, *since); err != nil {
+			return 0, err
+		}
+	}
+
+	searches, err := a.db.ListAllSavedSearches(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, s := range searches {
+		if s.EvaluatedAt == nil {
+			count, err := a.db.RecordSavedSearchBaseline(ctx, s)
+			if err != nil {
+				return total, err
+			}
+			log.Printf("Saved search %d baseline: %d companies", s.ID, count)
+			continue
+		}
+
+		added := make([]string, 0)
+		for start := 0; start < len(numbers); start += savedSearchBatchSize {
+			batch := numbers[start:min(start+savedSearchBatchSize, len(numbers))]
+			matches, err := a.db.UpdateSavedSearchMatches(ctx, s, batch)
+			if err != nil {
+				return total, err
+			}
+			added = append(added, matches...)
+		}
+
+		alertID, createdAt, err := a.db.FinishSavedSearchEvaluation(ctx, s.ID, len(added))
+		if err != nil {
+			return total, err
+		}
+		total += len(added)
+		if alertID != 0 {
+			// The matches are recorded, so a failed alert is not retried by repeating the run
+			a.alert(ctx, s, alertID, createdAt, added)
+		}
+	}
+
+	if err := a.db.SetJobCursor(ctx, savedSearchCursor, position); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+// alert notifies a saved search's webhook and email recipients of its new matches, logging
+// failures
+func (a *SavedSearchAlerter) alert(ctx context.Context, s models.SavedSearch, alertID int64, createdAt time.Time, added []string) {
+	if s.WebhookID != nil {
+		event := models.SavedSearchAlertEvent{
+			ID:            alertID,
+			Type:          webhooks.EventSavedSearchMatches,
+			CreatedAt:     createdAt.UTC(),
+			SavedSearchID: s.ID,
+			Data: models.SavedSearchAlertData{
+				Name:           s.Name,
+				NewMatches:     len(added),
+				CompanyNumbers: added[:min(alertCompanyNumbers, len(added))],
+			},
+		}
+		payload, err := json.Marshal(event)
+		if err == nil {
+			_, err = a.db.EnqueueSavedSearchAlert(ctx, *s.WebhookID, alertID, event.Type, payload)
+		}
+		if err != nil {
+			log.Printf("Failed to queue saved search %d alert: %v", s.ID, err)
+		}
+	}
+
+	if len(s.EmailTo) > 0 && a.mailer.Enabled() {
+		if err := a.email(ctx, s, added); err != nil {
+			log.Printf("Failed to email saved search %d alert: %v", s.ID, err)
+		}
+	}
+}
+
+// email sends a digest of a saved search's new matches to its recipients, listing the first
+// few by name and linking to the rest
+func (a *SavedSearchAlerter) email(ctx context.Context, s models.SavedSearch, added []string) error {
+	listed := added[:min(alertEmailCompanies, len(added))]
+	names, err := a.db.CompanyNames(ctx, listed)
+	if err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d companies newly match your saved search %q.\n\n", len(added), s.Name)
+	for _, number := range listed {
+		if name := names[number]; name != "" {
+			fmt.Fprintf(&body, "- %s (%s)\n", name, number)
+		} else {
+			fmt.Fprintf(&body, "- %s\n", number)
+		}
+	}
+	if more := len(added) - len(listed); more > 0 {
+		fmt.Fprintf(&body, "- and %d more\n", more)
+	}
+	fmt.Fprintf(&body, "\nSee every new match with your API key at:\n%s/api/saved-searches/%d/new-matches\n", a.publicURL, s.ID)
+
+	return a.mailer.Send(ctx, email.Message{
+		To:      s.EmailTo,
+		Subject: fmt.Sprintf("%d new matches for %s", len(added), s.Name),
+		Body:    body.String(),
+	})
+}
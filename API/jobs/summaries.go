@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"data-co/api/database"
+)
+
+// StartSummaryRefresh periodically refreshes the search summary views until ctx is cancelled.
+// An interval of zero disables the job.
+func StartSummaryRefresh(ctx context.Context, db *database.DB, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("Search summary refresh job disabled")
+		return
+	}
+
+	log.Printf("Refreshing search summaries every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				if err := db.RefreshSearchSummaries(ctx); err != nil {
+					log.Printf("Search summary refresh failed: %v", err)
+					continue
+				}
+				log.Printf("Refreshed search summaries in %s", time.Since(start))
+			}
+		}
+	}()
+}
@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/hmrc"
+)
+
+// vatCheckBatchSize is how many VAT numbers are read per database round trip
+const vatCheckBatchSize = 100
+
+// StartVATChecks periodically checks the VAT numbers loaded from bulk lookups with HMRC until
+// ctx is cancelled, taking numbers never checked first and rechecking the rest after
+// RecheckAfter, so deregistrations are picked up. It does nothing without an HMRC client, and
+// an interval of zero disables it.
+func StartVATChecks(ctx context.Context, db *database.DB, client *hmrc.Client, cfg config.HMRCConfig) {
+	if !client.Enabled() || cfg.CheckInterval <= 0 {
+		log.Printf("VAT check job disabled")
+		return
+	}
+
+	log.Printf("Checking VAT numbers with HMRC every %s", cfg.CheckInterval)
+
+	go func() {
+		ticker := time.NewTicker(cfg.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				checked, registered, err := CheckVATNumbers(ctx, db, client, start.Add(-cfg.RecheckAfter))
+				if err != nil {
+					log.Printf("VAT check failed after %d numbers: %v", checked, err)
+					continue
+				}
+				if checked > 0 {
+					log.Printf("Checked %d VAT numbers in %s, %d registered", checked, time.Since(start), registered)
+				}
+			}
+		}
+	}()
+}
+
+// CheckVATNumbers checks every VAT number never checked or last checked before staleBefore, and
+// returns how many were checked and how many of those HMRC has registered
+func CheckVATNumbers(ctx context.Context, db *database.DB, client *hmrc.Client, staleBefore time.Time) (int, int, error) {
+	checked, registered := 0, 0
+	for {
+		due, err := db.CompaniesDueVATCheck(ctx, staleBefore, vatCheckBatchSize)
+		if err != nil || len(due) == 0 {
+			return checked, registered, err
+		}
+		for _, number := range due {
+			registration, err := client.CheckVATNumber(ctx, number.VATNumber)
+			if err != nil {
+				return checked, registered, err
+			}
+			if err := db.RecordVATCheck(ctx, number, registration != nil); err != nil {
+				return checked, registered, err
+			}
+			checked++
+			if registration != nil {
+				registered++
+			}
+		}
+	}
+}
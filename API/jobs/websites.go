@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/enrichment"
+)
+
+// websiteBatchSize is how many companies are read per database round trip
+const websiteBatchSize = 100
+
+// StartWebsiteEnrichment periodically looks for the websites of active companies until ctx is
+// cancelled, up to MaxPerRun companies a run: those never looked up first, then those looked
+// up longest ago, after RecheckAfter. It does nothing without a website provider, and an
+// interval of zero disables it.
+func StartWebsiteEnrichment(ctx context.Context, db *database.DB, enricher *enrichment.Enricher, cfg config.WebsitesConfig) {
+	if !enricher.Enabled() || cfg.Interval <= 0 || cfg.MaxPerRun <= 0 {
+		log.Printf("Website enrichment job disabled")
+		return
+	}
+
+	log.Printf("Enriching up to %d company websites every %s", cfg.MaxPerRun, cfg.Interval)
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				checked, found, err := EnrichWebsites(ctx, db, enricher, start.Add(-cfg.RecheckAfter), cfg.MaxPerRun)
+				if err != nil {
+					log.Printf("Website enrichment failed after %d companies: %v", checked, err)
+					continue
+				}
+				if checked > 0 {
+					log.Printf("Looked up %d company websites in %s, %d found", checked, time.Since(start), found)
+				}
+			}
+		}
+	}()
+}
+
+// EnrichWebsites looks up the websites of up to limit active companies never looked up or last
+// looked up before staleBefore, and returns how many were looked up and how many found
+func EnrichWebsites(ctx context.Context, db *database.DB, enricher *enrichment.Enricher, staleBefore time.Time, limit int) (int, int, error) {
+	checked, found := 0, 0
+	for checked < limit {
+		due, err := db.CompaniesDueWebsiteCheck(ctx, staleBefore, min(websiteBatchSize, limit-checked))
+		if err != nil || len(due) == 0 {
+			return checked, found, err
+		}
+		for _, company := range due {
+			match, err := enricher.Find(ctx, company)
+			if err != nil {
+				return checked, found, err
+			}
+			if err := db.RecordWebsite(ctx, company.CompanyNumber, match.Domain, match.Source, match.Confidence); err != nil {
+				return checked, found, err
+			}
+			checked++
+			if match.Domain != "" {
+				found++
+			}
+		}
+	}
+	return checked, found, nil
+}
@@ -1,18 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"github.com/rs/cors"
 
+	"data-co/api/apiversion"
+	"data-co/api/auth"
+	"data-co/api/certs"
 	"data-co/api/config"
 	"data-co/api/database"
+	"data-co/api/email"
+	"data-co/api/enrichment"
 	"data-co/api/handlers"
+	"data-co/api/hmrc"
+	"data-co/api/hubspot"
+	"data-co/api/jobs"
+	"data-co/api/migrations"
+	"data-co/api/openapi"
+	"data-co/api/ratelimit"
+	"data-co/api/salesforce"
+	"data-co/api/slack"
+	"data-co/api/storage"
+	"data-co/api/usage"
+	"data-co/api/webhooks"
 )
 
 func main() {
@@ -20,21 +41,106 @@ func main() {
 	// In Docker, environment variables are provided via docker-compose.yml
 	_ = godotenv.Load("../.env") // Ignore error, env vars may come from docker-compose
 
-
 	// Initialize configuration
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err == nil {
+		err = cfg.ValidateServer()
+	}
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	log.Printf("Effective configuration: %+v", cfg.Redacted())
+
+	// Cancelled on SIGINT/SIGTERM to begin graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Initialize database connection
 	db, err := database.NewConnection(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
+	defer db.Close() // Runs after the server has drained in-flight requests
 
 	log.Printf("Connected to database: %s", cfg.Database.Name)
 
+	// Refuse to run against a schema older than the code, unless told to migrate it
+	if err := checkSchema(ctx, db, cfg.Database.AutoMigrate); err != nil {
+		log.Fatalf("Schema check failed: %v", err)
+	}
+	if missing, err := db.MissingSearchIndexes(ctx); err != nil {
+		log.Printf("Failed to check search indexes: %v", err)
+	} else if len(missing) > 0 {
+		log.Printf("Warning: searches will be slow without these missing or invalid indexes: %s", strings.Join(missing, ", "))
+	}
+
+	// Start background jobs
+	jobs.StartSummaryRefresh(ctx, db, cfg.Jobs.SummaryRefreshInterval)
+	jobs.StartHealthScoring(ctx, db, cfg.Jobs.HealthScoreInterval)
+	jobs.StartRiskRating(ctx, db, cfg.Jobs.RiskRatingInterval)
+	jobs.StartGeocoding(ctx, db, cfg.Jobs.GeocodeInterval)
+	jobs.StartIndustryRefresh(ctx, db, cfg.Jobs.IndustryRefreshInterval)
+	hmrcClient, err := hmrc.NewClient(cfg.HMRC)
+	if err != nil {
+		log.Fatalf("Failed to configure HMRC: %v", err)
+	}
+	jobs.StartVATChecks(ctx, db, hmrcClient, cfg.HMRC)
+	websiteProviders, err := enrichment.Providers(cfg.Websites)
+	if err != nil {
+		log.Fatalf("Failed to configure website enrichment: %v", err)
+	}
+	jobs.StartWebsiteEnrichment(ctx, db, enrichment.NewEnricher(cfg.Websites.MinConfidence, websiteProviders...), cfg.Websites)
+
+	dispatcher := webhooks.NewDispatcher(db, cfg.Webhooks)
+	dispatcher.Start(ctx)
+	webhooks.NewSearchMatcher(db, cfg.Webhooks).Start(ctx)
+
+	exportStore, err := storage.New(cfg.Exports)
+	if err != nil {
+		log.Fatalf("Failed to configure export storage: %v", err)
+	}
+	mailer := email.NewSender(cfg.Mail)
+	exportWorker := jobs.NewExportWorker(db, exportStore, mailer, cfg.Exports)
+	exportWorker.Start(ctx)
+
+	changeDetector := jobs.NewChangeDetector(db)
+	changeDetector.OnChanges(dispatcher.Enqueue)
+	slackNotifier := slack.NewNotifier(db, cfg.Slack)
+	changeDetector.OnChanges(slackNotifier.Notify)
+	changeDetector.Start(ctx, cfg.Jobs.ChangeDetectionInterval)
+	jobs.NewSavedSearchAlerter(db, mailer, cfg.Exports.PublicURL).Start(ctx, cfg.Jobs.SavedSearchInterval)
+
 	// Initialize handlers
-	companyHandler := handlers.NewCompanyHandler(db)
+	companyHandler := handlers.NewCompanyHandler(db.Companies(), cfg.Server.MaxSearchLimit, handlers.NewLiveCompanies(cfg.CompaniesHouse))
+	limiter := ratelimit.NewLimiter(cfg.RateLimit, "/api/health")
+	adminHandler := handlers.NewAdminHandler(db, limiter)
+	healthHandler := handlers.NewHealthHandler(db)
+	contactEnricher, err := enrichment.NewContactEnricher(cfg.Contacts)
+	if err != nil {
+		log.Fatalf("Failed to configure contact enrichment: %v", err)
+	}
+	contactHandler := handlers.NewContactHandler(db, contactEnricher, cfg.Contacts)
+	usageHandler := handlers.NewUsageHandler(db, contactHandler)
+	officerHandler := handlers.NewOfficerHandler(db)
+	watchlistHandler := handlers.NewWatchlistHandler(db, slackNotifier)
+	webhookHandler := handlers.NewWebhookHandler(db)
+	savedSearchHandler := handlers.NewSavedSearchHandler(db, mailer)
+	exportHandler := handlers.NewExportHandler(db, exportStore, mailer)
+	salesforceClient, err := salesforce.NewClient(cfg.Salesforce)
+	if err != nil {
+		log.Fatalf("Failed to configure Salesforce: %v", err)
+	}
+	hubspotClient, err := hubspot.NewClient(cfg.HubSpot)
+	if err != nil {
+		log.Fatalf("Failed to configure HubSpot: %v", err)
+	}
+	jobs.NewHubSpotSyncer(db, hubspotClient, cfg.HubSpot).Start(ctx)
+	integrationHandler := handlers.NewIntegrationHandler(db, salesforceClient, hubspotClient)
+	referenceHandler := handlers.NewReferenceHandler()
+	graphqlHandler, err := handlers.NewGraphQLHandler(db)
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
 
 	// Setup router
 	router := mux.NewRouter()
@@ -43,11 +149,97 @@ func main() {
 	router.HandleFunc("/", rootHandler).Methods("GET")
 
 	// API routes
+	authenticator, err := auth.NewAuthenticator(db, cfg.Auth, limiter.KeyTier, "/api/health", "/api/openapi.json", "/api/docs")
+	if err != nil {
+		log.Fatalf("Failed to initialize authentication: %v", err)
+	}
+	if !cfg.Auth.Enabled {
+		log.Printf("WARNING: API authentication is disabled (set AUTH_ENABLED=true to require API keys)")
+	}
+
+	meter := usage.NewMeter(db, "/api/health", "/api/usage", "/api/openapi.json", "/api/docs")
+	circuit := handlers.DatabaseCircuit(db, "/api/health", "/api/openapi.json", "/api/docs")
+
 	api := router.PathPrefix("/api").Subrouter()
-	api.HandleFunc("/companies/search", companyHandler.SearchCompanies).Methods("POST", "OPTIONS")
-	api.HandleFunc("/companies/count", companyHandler.CountCompanies).Methods("POST", "OPTIONS")
-	api.HandleFunc("/companies/{id}", companyHandler.GetCompany).Methods("GET", "OPTIONS")
-	api.HandleFunc("/health", healthCheck).Methods("GET")
+	api.Use(circuit, authenticator.Middleware, limiter.Middleware, meter.Middleware, handlers.RequestTimeout)
+	api.HandleFunc("/companies/search", authenticator.RequireRole(auth.RoleReader, companyHandler.SearchCompanies)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/companies/count", authenticator.RequireRole(auth.RoleReader, companyHandler.CountCompanies)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/companies/compare", authenticator.RequireRole(auth.RoleReader, companyHandler.CompareCompanies)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/analytics/top", authenticator.RequireRole(auth.RoleReader, companyHandler.TopCompanies)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/analytics/aggregate", authenticator.RequireRole(auth.RoleReader, companyHandler.AggregateCompanies)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/analytics/histogram", authenticator.RequireRole(auth.RoleReader, companyHandler.Histogram)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/analytics/incorporations", authenticator.RequireRole(auth.RoleReader, companyHandler.IncorporationTrend)).Methods("GET")
+	api.HandleFunc("/companies/batch", authenticator.RequireRole(auth.RoleReader, companyHandler.BatchGetCompanies)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/companies/match", authenticator.RequireRole(auth.RoleReader, companyHandler.MatchCompanies)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/companies/changes", authenticator.RequireRole(auth.RoleReader, companyHandler.ListChangedCompanies)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/number/{company_number}", authenticator.RequireRole(auth.RoleReader, companyHandler.GetCompany)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}", authenticator.RequireRole(auth.RoleReader, companyHandler.GetCompany)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}/pscs", authenticator.RequireRole(auth.RoleReader, companyHandler.GetCompanyPSCs)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}/charges", authenticator.RequireRole(auth.RoleReader, companyHandler.GetCompanyCharges)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}/previous-names", authenticator.RequireRole(auth.RoleReader, companyHandler.GetCompanyPreviousNames)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}/changes", authenticator.RequireRole(auth.RoleReader, companyHandler.GetCompanyChanges)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}/network", authenticator.RequireRole(auth.RoleReader, companyHandler.GetCompanyNetwork)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}/group", authenticator.RequireRole(auth.RoleReader, companyHandler.GetCompanyGroup)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}/metrics/turnover", authenticator.RequireRole(auth.RoleReader, companyHandler.GetTurnoverSeries)).Methods("GET", "OPTIONS")
+	api.HandleFunc("/companies/{company_number}/enrich", authenticator.RequireRole(auth.RoleExporter, contactHandler.EnrichCompany)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/officers/search", authenticator.RequireRole(auth.RoleReader, officerHandler.SearchOfficers)).Methods("GET")
+	api.HandleFunc("/officers/{id}", authenticator.RequireRole(auth.RoleReader, officerHandler.GetOfficer)).Methods("GET")
+	api.HandleFunc("/graphql", authenticator.RequireRole(auth.RoleReader, graphqlHandler.Query)).Methods("GET", "POST", "OPTIONS")
+	api.HandleFunc("/graphql/schema", authenticator.RequireRole(auth.RoleReader, graphqlHandler.Schema)).Methods("GET")
+	api.HandleFunc("/watchlists", authenticator.RequireRole(auth.RoleReader, watchlistHandler.CreateWatchlist)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/watchlists", authenticator.RequireRole(auth.RoleReader, watchlistHandler.ListWatchlists)).Methods("GET")
+	api.HandleFunc("/watchlists/{id}", authenticator.RequireRole(auth.RoleReader, watchlistHandler.GetWatchlist)).Methods("GET")
+	api.HandleFunc("/watchlists/{id}", authenticator.RequireRole(auth.RoleReader, watchlistHandler.DeleteWatchlist)).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/watchlists/{id}/companies", authenticator.RequireRole(auth.RoleReader, watchlistHandler.AddCompanies)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/watchlists/{id}/companies/{company_number}", authenticator.RequireRole(auth.RoleReader, watchlistHandler.RemoveCompany)).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/watchlists/{id}/changes", authenticator.RequireRole(auth.RoleReader, watchlistHandler.GetChanges)).Methods("GET")
+	api.HandleFunc("/watchlists/{id}/slack", authenticator.RequireRole(auth.RoleReader, watchlistHandler.SetSlack)).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/watchlists/{id}/slack", authenticator.RequireRole(auth.RoleReader, watchlistHandler.RemoveSlack)).Methods("DELETE")
+	api.HandleFunc("/webhooks", authenticator.RequireRole(auth.RoleReader, webhookHandler.CreateWebhook)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/webhooks", authenticator.RequireRole(auth.RoleReader, webhookHandler.ListWebhooks)).Methods("GET")
+	api.HandleFunc("/webhooks/samples/matched-search", authenticator.RequireRole(auth.RoleReader, webhookHandler.SampleSearchEvents)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/webhooks/{id}", authenticator.RequireRole(auth.RoleReader, webhookHandler.DeleteWebhook)).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/webhooks/{id}/dead-letters", authenticator.RequireRole(auth.RoleReader, webhookHandler.GetDeadLetters)).Methods("GET")
+	api.HandleFunc("/saved-searches", authenticator.RequireRole(auth.RoleReader, savedSearchHandler.CreateSavedSearch)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/saved-searches", authenticator.RequireRole(auth.RoleReader, savedSearchHandler.ListSavedSearches)).Methods("GET")
+	api.HandleFunc("/saved-searches/{id}", authenticator.RequireRole(auth.RoleReader, savedSearchHandler.GetSavedSearch)).Methods("GET")
+	api.HandleFunc("/saved-searches/{id}", authenticator.RequireRole(auth.RoleReader, savedSearchHandler.DeleteSavedSearch)).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/saved-searches/{id}/new-matches", authenticator.RequireRole(auth.RoleReader, savedSearchHandler.GetNewMatches)).Methods("GET")
+	api.HandleFunc("/exports", authenticator.RequireRole(auth.RoleExporter, exportHandler.CreateExport)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/exports", authenticator.RequireRole(auth.RoleExporter, exportHandler.ListExports)).Methods("GET")
+	api.HandleFunc("/exports/{id}", authenticator.RequireRole(auth.RoleExporter, exportHandler.GetExport)).Methods("GET")
+	api.HandleFunc("/exports/{id}/download", authenticator.RequireRole(auth.RoleExporter, exportHandler.DownloadExport)).Methods("GET")
+	api.HandleFunc("/integrations/salesforce/push", authenticator.RequireRole(auth.RoleExporter, integrationHandler.PushToSalesforce)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/integrations/hubspot/syncs", authenticator.RequireRole(auth.RoleExporter, integrationHandler.StartHubSpotSync)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/integrations/hubspot/syncs", authenticator.RequireRole(auth.RoleExporter, integrationHandler.ListHubSpotSyncs)).Methods("GET")
+	api.HandleFunc("/integrations/hubspot/syncs/{id}", authenticator.RequireRole(auth.RoleExporter, integrationHandler.GetHubSpotSync)).Methods("GET")
+	api.HandleFunc("/reference/sic", authenticator.RequireRole(auth.RoleReader, referenceHandler.GetSICTaxonomy)).Methods("GET")
+	api.HandleFunc("/usage", usageHandler.GetUsage).Methods("GET")
+	api.HandleFunc("/health", healthHandler.Health).Methods("GET")
+	api.HandleFunc("/openapi.json", openapi.SpecHandler).Methods("GET")
+	api.HandleFunc("/docs", openapi.DocsHandler).Methods("GET")
+
+	// Admin routes
+	api.HandleFunc("/admin/summaries/refresh", authenticator.RequirePlatformAdmin(adminHandler.RefreshSummaries)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/admin/pool", authenticator.RequirePlatformAdmin(adminHandler.PoolStats)).Methods("GET")
+	api.HandleFunc("/admin/stats", authenticator.RequirePlatformAdmin(adminHandler.DataStats)).Methods("GET")
+	api.HandleFunc("/admin/data-quality/duplicates", authenticator.RequirePlatformAdmin(adminHandler.DuplicateCompanies)).Methods("GET")
+	api.HandleFunc("/admin/keys", authenticator.RequireRole(auth.RoleAdmin, adminHandler.CreateAPIKey)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/admin/keys", authenticator.RequireRole(auth.RoleAdmin, adminHandler.ListAPIKeys)).Methods("GET")
+	api.HandleFunc("/admin/keys/{id}", authenticator.RequireRole(auth.RoleAdmin, adminHandler.RevokeAPIKey)).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/admin/organizations", authenticator.RequirePlatformAdmin(adminHandler.CreateOrganization)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/admin/organizations", authenticator.RequirePlatformAdmin(adminHandler.ListOrganizations)).Methods("GET")
+	api.HandleFunc("/admin/organizations/{id}/users", authenticator.RequireRole(auth.RoleAdmin, adminHandler.CreateUser)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/admin/organizations/{id}/users", authenticator.RequireRole(auth.RoleAdmin, adminHandler.ListUsers)).Methods("GET")
+	api.HandleFunc("/admin/organizations/{id}/users/{user_id}", authenticator.RequireRole(auth.RoleAdmin, adminHandler.DeleteUser)).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/admin/locations", authenticator.RequirePlatformAdmin(adminHandler.ListLocations)).Methods("GET")
+	api.HandleFunc("/admin/locations", authenticator.RequirePlatformAdmin(adminHandler.CreateLocation)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/admin/locations/{id}", authenticator.RequirePlatformAdmin(adminHandler.DeleteLocation)).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/admin/locations/{id}/aliases", authenticator.RequirePlatformAdmin(adminHandler.AddLocationAliases)).Methods("POST", "OPTIONS")
+	api.HandleFunc("/admin/locations/{id}/aliases/{alias}", authenticator.RequirePlatformAdmin(adminHandler.RemoveLocationAlias)).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/admin/industries", authenticator.RequirePlatformAdmin(adminHandler.ListIndustries)).Methods("GET")
+	api.HandleFunc("/admin/industries/{name}", authenticator.RequirePlatformAdmin(adminHandler.SaveIndustry)).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/admin/industries/{name}", authenticator.RequirePlatformAdmin(adminHandler.DeleteIndustry)).Methods("DELETE", "OPTIONS")
 
 	// CORS middleware - read allowed origins from environment
 	corsOrigins := os.Getenv("CORS_ALLOWED_ORIGINS")
@@ -61,23 +253,177 @@ func main() {
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   allowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-API-Key", "If-None-Match", handlers.RequestTimeoutHeader},
+		ExposedHeaders:   []string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "ETag"},
 		AllowCredentials: true,
 	})
 
 	// Start server
-	port := os.Getenv("API_PORT")
+	port := cfg.Server.Port
+	server := &http.Server{
+		Addr:         ":" + port,
+		Handler:      corsHandler.Handler(apiversion.Handler(router)),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	// Terminate TLS with certificate files, or certificates obtained from an ACME CA
+	tlsCfg := cfg.Server.TLS
+	certManager, err := certs.NewManager(tlsCfg)
+	if err != nil {
+		log.Fatalf("Failed to configure TLS: %v", err)
+	}
+	var challengeServer *http.Server
+	if certManager != nil {
+		server.TLSConfig = certManager.TLSConfig()
+		challengeServer = certManager.ChallengeServer()
+	}
 
-	log.Printf("Starting API server on port %s...", port)
+	if certManager != nil || tlsCfg.CertFile != "" {
+		log.Printf("Starting API server with TLS on port %s...", port)
+	} else {
+		log.Printf("Starting API server on port %s...", port)
+	}
 	log.Printf("API endpoints:")
+	log.Printf("  (also under http://localhost:%s/api/v2/... with plain nullable fields)", port)
 	log.Printf("  POST   http://localhost:%s/api/companies/search", port)
 	log.Printf("  POST   http://localhost:%s/api/companies/count", port)
-	log.Printf("  GET    http://localhost:%s/api/companies/{id}", port)
+	log.Printf("  POST   http://localhost:%s/api/companies/compare", port)
+	log.Printf("  POST   http://localhost:%s/api/analytics/top", port)
+	log.Printf("  POST   http://localhost:%s/api/analytics/aggregate", port)
+	log.Printf("  POST   http://localhost:%s/api/analytics/histogram", port)
+	log.Printf("  GET    http://localhost:%s/api/analytics/incorporations", port)
+	log.Printf("  POST   http://localhost:%s/api/companies/batch", port)
+	log.Printf("  POST   http://localhost:%s/api/companies/match", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/changes", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/number/{company_number}", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/{company_number}", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/{company_number}/pscs", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/{company_number}/charges", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/{company_number}/previous-names", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/{company_number}/changes", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/{company_number}/network", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/{company_number}/group", port)
+	log.Printf("  GET    http://localhost:%s/api/companies/{company_number}/metrics/turnover", port)
+	log.Printf("  POST   http://localhost:%s/api/companies/{company_number}/enrich", port)
+	log.Printf("  GET    http://localhost:%s/api/officers/search", port)
+	log.Printf("  GET    http://localhost:%s/api/officers/{id}", port)
+	log.Printf("  POST   http://localhost:%s/api/graphql", port)
+	log.Printf("  GET    http://localhost:%s/api/graphql/schema", port)
+	log.Printf("  POST   http://localhost:%s/api/watchlists", port)
+	log.Printf("  GET    http://localhost:%s/api/watchlists", port)
+	log.Printf("  GET    http://localhost:%s/api/watchlists/{id}", port)
+	log.Printf("  DELETE http://localhost:%s/api/watchlists/{id}", port)
+	log.Printf("  POST   http://localhost:%s/api/watchlists/{id}/companies", port)
+	log.Printf("  DELETE http://localhost:%s/api/watchlists/{id}/companies/{company_number}", port)
+	log.Printf("  GET    http://localhost:%s/api/watchlists/{id}/changes", port)
+	log.Printf("  PUT    http://localhost:%s/api/watchlists/{id}/slack", port)
+	log.Printf("  DELETE http://localhost:%s/api/watchlists/{id}/slack", port)
+	log.Printf("  POST   http://localhost:%s/api/webhooks", port)
+	log.Printf("  GET    http://localhost:%s/api/webhooks", port)
+	log.Printf("  POST   http://localhost:%s/api/webhooks/samples/matched-search", port)
+	log.Printf("  DELETE http://localhost:%s/api/webhooks/{id}", port)
+	log.Printf("  GET    http://localhost:%s/api/webhooks/{id}/dead-letters", port)
+	log.Printf("  POST   http://localhost:%s/api/saved-searches", port)
+	log.Printf("  GET    http://localhost:%s/api/saved-searches", port)
+	log.Printf("  GET    http://localhost:%s/api/saved-searches/{id}", port)
+	log.Printf("  DELETE http://localhost:%s/api/saved-searches/{id}", port)
+	log.Printf("  GET    http://localhost:%s/api/saved-searches/{id}/new-matches", port)
+	log.Printf("  POST   http://localhost:%s/api/exports", port)
+	log.Printf("  GET    http://localhost:%s/api/exports", port)
+	log.Printf("  GET    http://localhost:%s/api/exports/{id}", port)
+	log.Printf("  GET    http://localhost:%s/api/exports/{id}/download", port)
+	log.Printf("  POST   http://localhost:%s/api/integrations/salesforce/push", port)
+	log.Printf("  POST   http://localhost:%s/api/integrations/hubspot/syncs", port)
+	log.Printf("  GET    http://localhost:%s/api/integrations/hubspot/syncs", port)
+	log.Printf("  GET    http://localhost:%s/api/integrations/hubspot/syncs/{id}", port)
+	log.Printf("  GET    http://localhost:%s/api/reference/sic", port)
+	log.Printf("  GET    http://localhost:%s/api/usage", port)
 	log.Printf("  GET    http://localhost:%s/api/health", port)
+	log.Printf("  GET    http://localhost:%s/api/openapi.json", port)
+	log.Printf("  GET    http://localhost:%s/api/docs", port)
+	log.Printf("  POST   http://localhost:%s/api/admin/summaries/refresh", port)
+	log.Printf("  GET    http://localhost:%s/api/admin/pool", port)
+	log.Printf("  GET    http://localhost:%s/api/admin/stats", port)
+	log.Printf("  GET    http://localhost:%s/api/admin/data-quality/duplicates", port)
+	log.Printf("  POST   http://localhost:%s/api/admin/keys", port)
+	log.Printf("  GET    http://localhost:%s/api/admin/keys", port)
+	log.Printf("  DELETE http://localhost:%s/api/admin/keys/{id}", port)
+	log.Printf("  POST   http://localhost:%s/api/admin/organizations", port)
+	log.Printf("  GET    http://localhost:%s/api/admin/organizations", port)
+	log.Printf("  POST   http://localhost:%s/api/admin/organizations/{id}/users", port)
+	log.Printf("  GET    http://localhost:%s/api/admin/organizations/{id}/users", port)
+	log.Printf("  DELETE http://localhost:%s/api/admin/organizations/{id}/users/{user_id}", port)
+	log.Printf("  GET    http://localhost:%s/api/admin/locations", port)
+	log.Printf("  POST   http://localhost:%s/api/admin/locations", port)
+	log.Printf("  DELETE http://localhost:%s/api/admin/locations/{id}", port)
+	log.Printf("  POST   http://localhost:%s/api/admin/locations/{id}/aliases", port)
+	log.Printf("  DELETE http://localhost:%s/api/admin/locations/{id}/aliases/{alias}", port)
+	log.Printf("  GET    http://localhost:%s/api/admin/industries", port)
+	log.Printf("  PUT    http://localhost:%s/api/admin/industries/{name}", port)
+	log.Printf("  DELETE http://localhost:%s/api/admin/industries/{name}", port)
+
+	serverErr := make(chan error, 2)
+	go func() {
+		switch {
+		case certManager != nil:
+			serverErr <- server.ListenAndServeTLS("", "")
+		case tlsCfg.CertFile != "":
+			serverErr <- server.ListenAndServeTLS(tlsCfg.CertFile, tlsCfg.KeyFile)
+		default:
+			serverErr <- server.ListenAndServe()
+		}
+	}()
+	if challengeServer != nil {
+		log.Printf("Answering ACME challenges on port %s...", tlsCfg.AutocertHTTPPort)
+		go func() {
+			serverErr <- challengeServer.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	case <-ctx.Done():
+		log.Printf("Shutdown signal received, draining in-flight requests...")
+	}
+
+	// Stop accepting new connections and wait for in-flight requests to finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
 
-	if err := http.ListenAndServe(":"+port, corsHandler.Handler(router)); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Graceful shutdown did not complete: %v", err)
 	}
+	if challengeServer != nil {
+		challengeServer.Shutdown(shutdownCtx)
+	}
+
+	log.Printf("Server stopped")
+}
+
+// checkSchema makes sure every embedded migration has been applied, applying pending ones when
+// autoMigrate is set
+func checkSchema(ctx context.Context, db *database.DB, autoMigrate bool) error {
+	pending, err := migrations.Pending(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if !autoMigrate {
+		return fmt.Errorf("%d schema migrations are pending, starting with %s; run the migrate command or set DB_AUTO_MIGRATE=true", len(pending), pending[0].Name)
+	}
+
+	applied, err := migrations.Apply(ctx, db)
+	for _, m := range applied {
+		log.Printf("Applied migration %s", m.Name)
+	}
+	return err
 }
 
 // this function ensures the API is running and healthy to client
@@ -90,9 +436,3 @@ func rootHandler(w http.ResponseWriter, r *http.Request) {
 		"message": "Welcome to the Data-Co API"
 	}`))
 }
-
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok","service":"data-co-api"}`))
-}
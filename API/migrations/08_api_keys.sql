@@ -0,0 +1,26 @@
+-- =====================================================
+-- API keys (owned by the Go API)
+-- Created IF NOT EXISTS so re-applying the staging
+-- schema does not revoke every issued key
+-- =====================================================
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+
+    -- Only the SHA-256 hash of the key is stored; the prefix identifies it in listings
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+
+    is_admin BOOLEAN NOT NULL DEFAULT false,
+
+    -- Metadata
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_active ON api_keys(key_hash) WHERE revoked_at IS NULL;
+
+-- Comments
+COMMENT ON TABLE api_keys IS 'API keys accepted via Authorization: Bearer or X-API-Key headers';
+COMMENT ON COLUMN api_keys.key_hash IS 'Hex-encoded SHA-256 of the plaintext key, which is only shown once at creation';
@@ -0,0 +1,22 @@
+-- =====================================================
+-- API key roles
+-- Replaces api_keys.is_admin with a role shared with
+-- JWT-authenticated callers: reader < exporter < admin
+-- =====================================================
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'reader';
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'api_keys' AND column_name = 'is_admin'
+    ) THEN
+        UPDATE api_keys SET role = 'admin' WHERE is_admin;
+        ALTER TABLE api_keys DROP COLUMN is_admin;
+    END IF;
+END $$;
+
+ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS api_keys_role_check;
+ALTER TABLE api_keys ADD CONSTRAINT api_keys_role_check CHECK (role IN ('reader', 'exporter', 'admin'));
+
+COMMENT ON COLUMN api_keys.role IS 'Access level: reader (search), exporter (reader + exports), admin (everything)';
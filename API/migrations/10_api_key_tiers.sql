@@ -0,0 +1,8 @@
+-- =====================================================
+-- API key rate limit tiers
+-- Limits per tier are configured in the API via
+-- RATE_LIMIT_TIERS
+-- =====================================================
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tier VARCHAR(20) NOT NULL DEFAULT 'standard';
+
+COMMENT ON COLUMN api_keys.tier IS 'Rate limit tier, e.g. standard or premium';
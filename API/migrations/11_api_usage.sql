@@ -0,0 +1,24 @@
+-- =====================================================
+-- API usage metering and quotas
+-- One row per API key per calendar month
+-- =====================================================
+CREATE TABLE IF NOT EXISTS api_usage (
+    api_key_id INTEGER NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    period DATE NOT NULL, -- First day of the month
+
+    request_count BIGINT NOT NULL DEFAULT 0,
+    row_count BIGINT NOT NULL DEFAULT 0,
+
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (api_key_id, period)
+);
+
+-- NULL quotas mean unlimited
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS monthly_request_quota BIGINT;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS monthly_row_quota BIGINT;
+
+-- Comments
+COMMENT ON TABLE api_usage IS 'Requests and result rows returned per API key per month';
+COMMENT ON COLUMN api_keys.monthly_request_quota IS 'Maximum requests per calendar month (NULL = unlimited)';
+COMMENT ON COLUMN api_keys.monthly_row_quota IS 'Maximum result rows returned per calendar month (NULL = unlimited)';
@@ -0,0 +1,51 @@
+-- =====================================================
+-- Watchlists and company change detection
+-- (owned by the Go API)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS watchlists (
+    id SERIAL PRIMARY KEY,
+    owner_id VARCHAR(200) NOT NULL DEFAULT '', -- Principal that created the list ('' when auth is disabled)
+    name VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlists_owner ON watchlists(owner_id);
+
+CREATE TABLE IF NOT EXISTS watchlist_companies (
+    watchlist_id INTEGER NOT NULL REFERENCES watchlists(id) ON DELETE CASCADE,
+    company_number VARCHAR(8) NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (watchlist_id, company_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_companies_number ON watchlist_companies(company_number);
+
+-- Last observed state of each tracked company, compared on every detection run
+CREATE TABLE IF NOT EXISTS company_snapshots (
+    company_number VARCHAR(8) PRIMARY KEY,
+    company_status VARCHAR(50),
+    accounts_last_made_up_date DATE,
+    latest_period_end DATE,
+    total_officers INTEGER NOT NULL DEFAULT 0,
+    resigned_officers INTEGER NOT NULL DEFAULT 0,
+    captured_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Detected changes, one row per changed field
+CREATE TABLE IF NOT EXISTS company_change_events (
+    id BIGSERIAL PRIMARY KEY,
+    company_number VARCHAR(8) NOT NULL,
+    change_type VARCHAR(50) NOT NULL, -- 'status_changed', 'new_accounts', 'new_financials', 'officer_appointed', 'officer_resigned'
+    old_value TEXT,
+    new_value TEXT,
+    detected_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_change_events_company ON company_change_events(company_number, detected_at);
+CREATE INDEX IF NOT EXISTS idx_company_change_events_detected ON company_change_events(detected_at);
+
+-- Comments
+COMMENT ON TABLE watchlists IS 'Named sets of company numbers tracked for changes';
+COMMENT ON TABLE company_snapshots IS 'Last observed state of watched companies, used for change detection';
+COMMENT ON TABLE company_change_events IS 'Changes detected between snapshots of watched companies';
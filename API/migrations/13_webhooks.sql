@@ -0,0 +1,65 @@
+-- =====================================================
+-- Webhook subscriptions and deliveries
+-- (owned by the Go API)
+-- =====================================================
+
+-- Progress markers for background jobs that scan staging tables incrementally
+CREATE TABLE IF NOT EXISTS job_cursors (
+    name VARCHAR(100) PRIMARY KEY,
+    position TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id SERIAL PRIMARY KEY,
+    owner_id VARCHAR(200) NOT NULL DEFAULT '',
+    url VARCHAR(2000) NOT NULL,
+    secret VARCHAR(100) NOT NULL, -- HMAC-SHA256 signing secret
+    event_types TEXT[] NOT NULL DEFAULT '{}', -- Empty = all event types
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_owner ON webhook_subscriptions(owner_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES company_change_events(id) ON DELETE CASCADE,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+
+    -- Retry state
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error TEXT,
+
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE(subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE delivered_at IS NULL;
+
+-- Deliveries that exhausted their retries
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_status_code INTEGER,
+    last_error TEXT,
+    failed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_subscription ON webhook_dead_letters(subscription_id, failed_at);
+
+-- Comments
+COMMENT ON TABLE job_cursors IS 'Last processed position of incremental background jobs';
+COMMENT ON TABLE webhook_subscriptions IS 'Subscriber URLs and the company event types they receive';
+COMMENT ON TABLE webhook_deliveries IS 'Outbox of signed webhook deliveries with retry state';
+COMMENT ON TABLE webhook_dead_letters IS 'Webhook deliveries that failed after all retries';
@@ -0,0 +1,14 @@
+-- =====================================================
+-- Companies House streaming API positions
+-- (owned by the Go stream ingester)
+-- =====================================================
+
+-- Last processed timepoint per stream, so the ingester resumes where it stopped
+CREATE TABLE IF NOT EXISTS stream_offsets (
+    stream VARCHAR(100) PRIMARY KEY,
+    timepoint BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Comments
+COMMENT ON TABLE stream_offsets IS 'Last Companies House streaming API timepoint processed per stream';
@@ -0,0 +1,46 @@
+-- =====================================================
+-- Persons with significant control
+-- (owned by the Go importer and stream ingester)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS staging_pscs (
+    id BIGSERIAL PRIMARY KEY,
+    company_number VARCHAR(8) NOT NULL,
+    psc_id VARCHAR(200) NOT NULL, -- Last segment of the record's Companies House self link
+
+    kind VARCHAR(100) NOT NULL, -- Companies House kind, e.g. 'individual-person-with-significant-control'
+    psc_type VARCHAR(20) NOT NULL, -- 'individual', 'corporate', 'legal_person', 'super_secure', 'statement' or 'exemption'
+
+    name VARCHAR(500),
+    nationality VARCHAR(100),
+    country_of_residence VARCHAR(100),
+    date_of_birth DATE, -- First of the month; only month and year are published
+    natures_of_control TEXT[] NOT NULL DEFAULT '{}',
+    notified_on DATE,
+    ceased_on DATE,
+    statement VARCHAR(200), -- For statements, e.g. 'no-individual-or-entity-with-signficant-control'
+
+    -- Corporate and legal person identification
+    legal_form VARCHAR(200),
+    registration_number VARCHAR(100),
+
+    -- Address
+    address_line_1 VARCHAR(500),
+    locality VARCHAR(200),
+    postal_code VARCHAR(20),
+    country VARCHAR(100),
+
+    raw_data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    data_hash VARCHAR(32),
+    batch_id VARCHAR(50),
+    last_updated TIMESTAMP NOT NULL DEFAULT NOW(),
+    ingested_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE(company_number, psc_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staging_pscs_type ON staging_pscs(company_number, psc_type) WHERE ceased_on IS NULL;
+CREATE INDEX IF NOT EXISTS idx_staging_pscs_last_updated ON staging_pscs(last_updated);
+
+-- Comments
+COMMENT ON TABLE staging_pscs IS 'Persons with significant control and PSC statements from the PSC snapshot and stream';
+COMMENT ON COLUMN staging_pscs.psc_type IS 'Simplified kind used by the psc_type search filter';
@@ -0,0 +1,39 @@
+-- =====================================================
+-- Charges (mortgages and other secured debt)
+-- (owned by the Go importer and stream ingester)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS staging_charges (
+    id BIGSERIAL PRIMARY KEY,
+    company_number VARCHAR(8) NOT NULL,
+    charge_id VARCHAR(200) NOT NULL, -- Last segment of the charge's Companies House self link
+
+    charge_code VARCHAR(50),
+    charge_number INTEGER,
+    classification VARCHAR(500),
+    status VARCHAR(50), -- 'outstanding', 'part-satisfied', 'fully-satisfied', 'satisfied'
+    created_on DATE,
+    delivered_on DATE,
+    satisfied_on DATE,
+    persons_entitled TEXT[] NOT NULL DEFAULT '{}',
+    particulars TEXT,
+
+    raw_data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    data_hash VARCHAR(32),
+    batch_id VARCHAR(50),
+    last_updated TIMESTAMP NOT NULL DEFAULT NOW(),
+    ingested_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE(company_number, charge_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staging_charges_outstanding ON staging_charges(company_number) WHERE status IN ('outstanding', 'part-satisfied');
+
+-- When each company's charges were last fetched from the Companies House API
+CREATE TABLE IF NOT EXISTS staging_charge_fetches (
+    company_number VARCHAR(8) PRIMARY KEY,
+    fetched_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Comments
+COMMENT ON TABLE staging_charges IS 'Registered charges per company from the Companies House API and charges stream';
+COMMENT ON TABLE staging_charge_fetches IS 'Last time the charges importer fetched each company, so runs can resume and refresh';
@@ -0,0 +1,36 @@
+-- =====================================================
+-- Insolvency cases (liquidations, administrations, CVAs, receiverships)
+-- (owned by the Go importer and stream ingester)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS staging_insolvency_cases (
+    id BIGSERIAL PRIMARY KEY,
+    company_number VARCHAR(8) NOT NULL,
+    case_number INTEGER NOT NULL,
+
+    case_type VARCHAR(100) NOT NULL, -- e.g. 'creditors-voluntary-liquidation', 'in-administration'
+    started_on DATE, -- Earliest non-closing date on the case
+    ended_on DATE, -- Set once the case has concluded, been discharged or the company dissolved
+    dates JSONB NOT NULL DEFAULT '[]'::jsonb, -- [{"type": "wound-up-on", "date": "2020-01-31"}, ...]
+    practitioners TEXT[] NOT NULL DEFAULT '{}',
+
+    raw_data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    data_hash VARCHAR(32),
+    batch_id VARCHAR(50),
+    last_updated TIMESTAMP NOT NULL DEFAULT NOW(),
+    ingested_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE(company_number, case_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staging_insolvency_cases_company ON staging_insolvency_cases(company_number);
+CREATE INDEX IF NOT EXISTS idx_staging_insolvency_cases_open ON staging_insolvency_cases(company_number, case_type) WHERE ended_on IS NULL;
+
+-- When each company's insolvency cases were last fetched from the Companies House API
+CREATE TABLE IF NOT EXISTS staging_insolvency_fetches (
+    company_number VARCHAR(8) PRIMARY KEY,
+    fetched_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Comments
+COMMENT ON TABLE staging_insolvency_cases IS 'Insolvency cases per company from the Companies House API and insolvency-cases stream';
+COMMENT ON TABLE staging_insolvency_fetches IS 'Last time the insolvency importer fetched each company, so runs can resume and refresh';
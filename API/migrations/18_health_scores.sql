@@ -0,0 +1,16 @@
+-- =====================================================
+-- Company financial health scores
+-- (derived by the API's health scoring job from each company's latest financials)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS company_health_scores (
+    company_number VARCHAR(8) PRIMARY KEY,
+    period_end DATE NOT NULL, -- Financial period the score was computed from
+    score NUMERIC(10, 2), -- Altman Z-score (private company variant); NULL when the accounts lack the figures to score
+    band VARCHAR(10), -- 'strong', 'moderate', 'weak'
+    computed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_health_scores_band ON company_health_scores(band);
+
+-- Comments
+COMMENT ON TABLE company_health_scores IS 'Financial health score per company from its latest accounts, rescored when a newer period is ingested';
@@ -0,0 +1,16 @@
+-- =====================================================
+-- Company credit risk ratings
+-- (derived by the API's risk rating job from filings, financials, charges and officers)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS company_risk_ratings (
+    company_number VARCHAR(8) PRIMARY KEY,
+    points INTEGER NOT NULL,
+    band VARCHAR(10) NOT NULL, -- 'low', 'medium', 'high'
+    flags TEXT[] NOT NULL DEFAULT '{}', -- e.g. {'accounts_overdue', 'officer_churn'}
+    computed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_risk_ratings_band ON company_risk_ratings(band);
+
+-- Comments
+COMMENT ON TABLE company_risk_ratings IS 'Credit risk rating per company, recomputed for every company on each risk rating job run';
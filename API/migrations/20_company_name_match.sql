@@ -0,0 +1,25 @@
+-- =====================================================
+-- Company name matching
+-- (used by POST /api/companies/match to resolve free-text names to companies)
+-- =====================================================
+
+-- Reduces a company name to the part that identifies it: lower case, "&" spelled out,
+-- punctuation dropped, and legal-form words ("limited", "ltd", "plc", "llp", ...) and a
+-- leading "the" removed, so "The Acme Co. Ltd" and "ACME COMPANY LIMITED" both become "acme".
+-- Names that are nothing but those words are kept whole rather than reduced to nothing.
+CREATE OR REPLACE FUNCTION normalize_company_name(name TEXT) RETURNS TEXT AS $$
+    SELECT COALESCE(
+        NULLIF(btrim(regexp_replace(regexp_replace(regexp_replace(
+            regexp_replace(regexp_replace(lower(name), '&', ' and ', 'g'), '[^a-z0-9 ]+', ' ', 'g'),
+            '\m(limited|ltd|plc|llp|lp|cic|cio|company|co|incorporated|inc|corporation|corp|uk)\M', ' ', 'g'),
+            '^\s*the\M', ' '),
+            '\s+', ' ', 'g')), ''),
+        btrim(regexp_replace(regexp_replace(lower(name), '[^a-z0-9]+', ' ', 'g'), '\s+', ' ', 'g'))
+    )
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_staging_companies_normalized_name_trgm
+    ON staging_companies USING gin(normalize_company_name(company_name) gin_trgm_ops);
+
+-- Comments
+COMMENT ON FUNCTION normalize_company_name(TEXT) IS 'Company name without case, punctuation or legal-form words, for matching';
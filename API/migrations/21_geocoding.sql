@@ -0,0 +1,27 @@
+-- =====================================================
+-- Postcode coordinates and company geocoding
+-- (postcode_lookup is loaded from the ONS Postcode Directory by the API's importer;
+-- the API's geocoding job copies coordinates onto staging_companies)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS postcode_lookup (
+    postcode VARCHAR(8) PRIMARY KEY, -- Upper case without spaces, e.g. 'SW1A1AA'
+    latitude NUMERIC(9, 6) NOT NULL,
+    longitude NUMERIC(9, 6) NOT NULL,
+    terminated_on DATE, -- Postcodes no longer in use keep their coordinates for older addresses
+    batch_id VARCHAR(50),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE staging_companies
+    ADD COLUMN IF NOT EXISTS latitude NUMERIC(9, 6),
+    ADD COLUMN IF NOT EXISTS longitude NUMERIC(9, 6),
+    ADD COLUMN IF NOT EXISTS geocoded_postcode VARCHAR(8), -- Normalized postal_code the coordinates were looked up for
+    ADD COLUMN IF NOT EXISTS geocoded_at TIMESTAMP;
+
+-- Radius searches bound the circle by latitude and longitude before checking the distance
+CREATE INDEX IF NOT EXISTS idx_staging_companies_lat_lng ON staging_companies(latitude, longitude) WHERE latitude IS NOT NULL;
+
+-- Comments
+COMMENT ON TABLE postcode_lookup IS 'Latitude and longitude of every UK postcode, from the ONS Postcode Directory';
+COMMENT ON COLUMN staging_companies.latitude IS 'Latitude of the registered office postcode (NULL when it is not in postcode_lookup)';
+COMMENT ON COLUMN staging_companies.geocoded_postcode IS 'Postcode last geocoded; the geocoding job reprocesses companies whose postal_code no longer matches';
@@ -0,0 +1,22 @@
+-- =====================================================
+-- Postcode area and district extraction
+-- (used by the API's postcode_area and postcode_district search filters)
+-- =====================================================
+
+-- Outward code of a UK postcode, e.g. 'EC1V 9LT' -> 'EC1V', 'm1 1ae' -> 'M1'. A bare outward
+-- code ('EC1V') is returned as is; anything else that is not a postcode gives NULL.
+CREATE OR REPLACE FUNCTION postcode_district(postcode TEXT) RETURNS TEXT AS $$
+    SELECT substring(upper(regexp_replace(postcode, '\s', '', 'g')) from '^([A-Z]{1,2}[0-9][0-9A-Z]?)(?:[0-9][A-Z]{2})?$')
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+-- Letters of the outward code, e.g. 'EC1V 9LT' -> 'EC', 'M1 1AE' -> 'M'
+CREATE OR REPLACE FUNCTION postcode_area(postcode TEXT) RETURNS TEXT AS $$
+    SELECT substring(postcode_district(postcode) from '^[A-Z]+')
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_staging_companies_postcode_district ON staging_companies(postcode_district(postal_code));
+CREATE INDEX IF NOT EXISTS idx_staging_companies_postcode_area ON staging_companies(postcode_area(postal_code));
+
+-- Comments
+COMMENT ON FUNCTION postcode_district(TEXT) IS 'Outward code (district) of a UK postcode, upper case';
+COMMENT ON FUNCTION postcode_area(TEXT) IS 'Area letters of a UK postcode, upper case';
@@ -0,0 +1,162 @@
+-- =====================================================
+-- Location canonicalization
+-- (used by the API's location search filter; aliases are managed through
+-- /api/admin/locations)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS locations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL UNIQUE, -- Canonical spelling, as it appears in locality or region
+    kind VARCHAR(20) NOT NULL, -- 'region', 'county', 'city', 'borough', 'town'
+    parent_id INTEGER REFERENCES locations(id) ON DELETE CASCADE, -- e.g. Salford -> Greater Manchester
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_locations_parent ON locations(parent_id);
+
+CREATE TABLE IF NOT EXISTS location_aliases (
+    alias VARCHAR(200) PRIMARY KEY, -- Lower case, e.g. 'greater london'
+    location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_aliases_location ON location_aliases(location_id);
+
+-- Names a location filter value matches, in lower case: the location it names or is an alias of,
+-- every location inside it (at any depth), and their aliases. Empty when the value names no
+-- location.
+CREATE OR REPLACE FUNCTION location_names(query TEXT) RETURNS SETOF TEXT AS $$
+    WITH RECURSIVE matched AS (
+        SELECT l.id FROM locations l
+        WHERE lower(l.name) = lower(btrim(query))
+            OR l.id IN (SELECT location_id FROM location_aliases WHERE alias = lower(btrim(query)))
+        UNION
+        SELECT l.id FROM locations l JOIN matched m ON l.parent_id = m.id
+    )
+    SELECT lower(l.name) FROM locations l JOIN matched m ON m.id = l.id
+    UNION
+    SELECT a.alias FROM location_aliases a JOIN matched m ON m.id = a.location_id
+$$ LANGUAGE SQL STABLE;
+
+-- Locality and region are matched case-insensitively against location_names
+CREATE INDEX IF NOT EXISTS idx_staging_companies_locality_lower ON staging_companies(lower(locality));
+CREATE INDEX IF NOT EXISTS idx_staging_companies_region_lower ON staging_companies(lower(region));
+
+-- Seed data: regions and counties, then the cities, boroughs and towns inside them, then
+-- alternative spellings. Existing rows (including admin edits) are left alone.
+INSERT INTO locations (name, kind) VALUES
+    ('London', 'region'),
+    ('Greater Manchester', 'county'),
+    ('West Midlands', 'county'),
+    ('West Yorkshire', 'county'),
+    ('South Yorkshire', 'county'),
+    ('Merseyside', 'county'),
+    ('Tyne and Wear', 'county'),
+    ('Bristol', 'city'),
+    ('Edinburgh', 'city'),
+    ('Glasgow', 'city'),
+    ('Cardiff', 'city'),
+    ('Belfast', 'city')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO locations (name, kind, parent_id)
+SELECT v.name, v.kind, p.id
+FROM (VALUES
+    ('City of London', 'borough', 'London'),
+    ('Westminster', 'borough', 'London'),
+    ('Barking', 'borough', 'London'),
+    ('Barnet', 'borough', 'London'),
+    ('Bexley', 'borough', 'London'),
+    ('Brent', 'borough', 'London'),
+    ('Bromley', 'borough', 'London'),
+    ('Camden', 'borough', 'London'),
+    ('Croydon', 'borough', 'London'),
+    ('Ealing', 'borough', 'London'),
+    ('Enfield', 'borough', 'London'),
+    ('Greenwich', 'borough', 'London'),
+    ('Hackney', 'borough', 'London'),
+    ('Hammersmith', 'borough', 'London'),
+    ('Haringey', 'borough', 'London'),
+    ('Harrow', 'borough', 'London'),
+    ('Havering', 'borough', 'London'),
+    ('Hillingdon', 'borough', 'London'),
+    ('Hounslow', 'borough', 'London'),
+    ('Islington', 'borough', 'London'),
+    ('Kensington', 'borough', 'London'),
+    ('Kingston upon Thames', 'borough', 'London'),
+    ('Lambeth', 'borough', 'London'),
+    ('Lewisham', 'borough', 'London'),
+    ('Merton', 'borough', 'London'),
+    ('Newham', 'borough', 'London'),
+    ('Redbridge', 'borough', 'London'),
+    ('Richmond upon Thames', 'borough', 'London'),
+    ('Southwark', 'borough', 'London'),
+    ('Sutton', 'borough', 'London'),
+    ('Tower Hamlets', 'borough', 'London'),
+    ('Waltham Forest', 'borough', 'London'),
+    ('Wandsworth', 'borough', 'London'),
+    ('Manchester', 'city', 'Greater Manchester'),
+    ('Salford', 'city', 'Greater Manchester'),
+    ('Bolton', 'town', 'Greater Manchester'),
+    ('Bury', 'town', 'Greater Manchester'),
+    ('Oldham', 'town', 'Greater Manchester'),
+    ('Rochdale', 'town', 'Greater Manchester'),
+    ('Stockport', 'town', 'Greater Manchester'),
+    ('Altrincham', 'town', 'Greater Manchester'),
+    ('Ashton-under-Lyne', 'town', 'Greater Manchester'),
+    ('Wigan', 'town', 'Greater Manchester'),
+    ('Birmingham', 'city', 'West Midlands'),
+    ('Coventry', 'city', 'West Midlands'),
+    ('Wolverhampton', 'city', 'West Midlands'),
+    ('Dudley', 'town', 'West Midlands'),
+    ('Walsall', 'town', 'West Midlands'),
+    ('Solihull', 'town', 'West Midlands'),
+    ('West Bromwich', 'town', 'West Midlands'),
+    ('Leeds', 'city', 'West Yorkshire'),
+    ('Bradford', 'city', 'West Yorkshire'),
+    ('Wakefield', 'city', 'West Yorkshire'),
+    ('Huddersfield', 'town', 'West Yorkshire'),
+    ('Halifax', 'town', 'West Yorkshire'),
+    ('Sheffield', 'city', 'South Yorkshire'),
+    ('Doncaster', 'city', 'South Yorkshire'),
+    ('Rotherham', 'town', 'South Yorkshire'),
+    ('Barnsley', 'town', 'South Yorkshire'),
+    ('Liverpool', 'city', 'Merseyside'),
+    ('Birkenhead', 'town', 'Merseyside'),
+    ('St Helens', 'town', 'Merseyside'),
+    ('Southport', 'town', 'Merseyside'),
+    ('Bootle', 'town', 'Merseyside'),
+    ('Newcastle upon Tyne', 'city', 'Tyne and Wear'),
+    ('Sunderland', 'city', 'Tyne and Wear'),
+    ('Gateshead', 'town', 'Tyne and Wear'),
+    ('South Shields', 'town', 'Tyne and Wear')
+) AS v(name, kind, parent)
+JOIN locations p ON p.name = v.parent
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO location_aliases (alias, location_id)
+SELECT v.alias, l.id
+FROM (VALUES
+    ('greater london', 'London'),
+    ('london city', 'City of London'),
+    ('city of westminster', 'Westminster'),
+    ('kensington and chelsea', 'Kensington'),
+    ('chelsea', 'Kensington'),
+    ('hammersmith and fulham', 'Hammersmith'),
+    ('fulham', 'Hammersmith'),
+    ('barking and dagenham', 'Barking'),
+    ('dagenham', 'Barking'),
+    ('kingston', 'Kingston upon Thames'),
+    ('richmond', 'Richmond upon Thames'),
+    ('west brom', 'West Bromwich'),
+    ('newcastle', 'Newcastle upon Tyne'),
+    ('city of bristol', 'Bristol'),
+    ('city of edinburgh', 'Edinburgh'),
+    ('glasgow city', 'Glasgow'),
+    ('city of glasgow', 'Glasgow')
+) AS v(alias, name)
+JOIN locations l ON l.name = v.name
+ON CONFLICT (alias) DO NOTHING;
+
+-- Comments
+COMMENT ON TABLE locations IS 'Canonical localities and regions, nested so a region or county filter also matches the places inside it';
+COMMENT ON TABLE location_aliases IS 'Alternative names for locations, matched by the location filter as well as the canonical name';
@@ -0,0 +1,25 @@
+-- =====================================================
+-- Industry to SIC code mapping
+-- (used by the API's industry search filter; managed through /api/admin/industries
+-- and cached in memory by the API)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS industries (
+    name VARCHAR(50) PRIMARY KEY, -- Filter value, e.g. 'tech'
+    description TEXT,
+    sic_prefixes TEXT[] NOT NULL, -- SIC 2007 code prefixes, e.g. {'62', '63'}
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Seed data: the industries the API previously had built in. Existing rows (including admin
+-- edits) are left alone.
+INSERT INTO industries (name, description, sic_prefixes) VALUES
+    ('tech', 'Computer programming, IT services, data processing', '{62,63}'),
+    ('finance', 'Financial services, insurance', '{64,65,66}'),
+    ('retail', 'Retail trade', '{47}'),
+    ('manufacturing', 'Manufacturing', '{10,11,12,13,14,15,16,17,18,19,20,21,22,23,24,25,26,27,28,29,30,31,32,33}'),
+    ('professional', 'Professional, scientific and technical', '{69,70,71,72,73,74}')
+ON CONFLICT (name) DO NOTHING;
+
+-- Comments
+COMMENT ON TABLE industries IS 'Industry filter values and the SIC code prefixes each matches';
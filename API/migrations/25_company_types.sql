@@ -0,0 +1,31 @@
+-- =====================================================
+-- Company types
+-- (used by the API's company_type search filter)
+-- =====================================================
+
+-- Reduces a company's type to a filter value: 'ltd', 'guarantee', 'plc', 'llp', 'lp',
+-- 'unlimited', 'cic', 'charitable-incorporated', 'overseas' or 'other'. company_type holds the
+-- BasicCompanyData category names (the stream ingester maps its types to the same names), and
+-- overseas companies and entities are recognised by their FC, SF, NF or OE company numbers.
+CREATE OR REPLACE FUNCTION company_type_code(company_type TEXT, company_number TEXT) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN company_number ~ '^(FC|SF|NF|OE)'
+            OR lower(company_type) IN ('overseas entity', 'oversea-company', 'registered-overseas-entity') THEN 'overseas'
+        WHEN lower(company_type) = 'private limited company' THEN 'ltd'
+        WHEN lower(company_type) LIKE 'pri/ltd by guar/nsc%' OR lower(company_type) LIKE 'pri/lbg/nsc%' THEN 'guarantee'
+        WHEN lower(company_type) IN ('public limited company', 'old public company') THEN 'plc'
+        WHEN lower(company_type) = 'limited liability partnership' THEN 'llp'
+        WHEN lower(company_type) = 'limited partnership' THEN 'lp'
+        WHEN lower(company_type) IN ('private unlimited company', 'private unlimited') THEN 'unlimited'
+        WHEN lower(company_type) = 'community interest company' THEN 'cic'
+        WHEN lower(company_type) IN ('charitable incorporated organisation', 'scottish charitable incorporated organisation') THEN 'charitable-incorporated'
+        WHEN company_type IS NULL THEN NULL
+        ELSE 'other'
+    END
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_staging_companies_type_code
+    ON staging_companies(company_type_code(company_type, company_number));
+
+-- Comments
+COMMENT ON FUNCTION company_type_code(TEXT, TEXT) IS 'Company type as a company_type filter value, e.g. ltd or llp';
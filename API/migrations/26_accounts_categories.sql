@@ -0,0 +1,33 @@
+-- =====================================================
+-- Accounts categories
+-- (used by the API's accounts_category search filter)
+-- =====================================================
+
+-- Reduces the type of a company's last accounts to a filter value: 'micro-entity', 'small',
+-- 'medium', 'full', 'group', 'dormant', 'none' or 'other'. account_category holds the
+-- BasicCompanyData names (e.g. 'TOTAL EXEMPTION FULL'), which the stream ingester also maps
+-- its accounts types to. Total exemption and abridged accounts are filed by small companies.
+CREATE OR REPLACE FUNCTION accounts_category_code(account_category TEXT) RETURNS TEXT AS $$
+    SELECT CASE regexp_replace(lower(btrim(account_category)), '[-_ ]+', ' ', 'g')
+        WHEN 'micro entity' THEN 'micro-entity'
+        WHEN 'small' THEN 'small'
+        WHEN 'total exemption small' THEN 'small'
+        WHEN 'total exemption full' THEN 'small'
+        WHEN 'unaudited abridged' THEN 'small'
+        WHEN 'audited abridged' THEN 'small'
+        WHEN 'medium' THEN 'medium'
+        WHEN 'full' THEN 'full'
+        WHEN 'group' THEN 'group'
+        WHEN 'dormant' THEN 'dormant'
+        WHEN 'no accounts filed' THEN 'none'
+        WHEN 'accounts type not available' THEN 'none'
+        WHEN '' THEN NULL
+        ELSE 'other'
+    END
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_staging_companies_accounts_category_code
+    ON staging_companies(accounts_category_code(account_category));
+
+-- Comments
+COMMENT ON FUNCTION accounts_category_code(TEXT) IS 'Type of a company''s last accounts as an accounts_category filter value, e.g. micro-entity';
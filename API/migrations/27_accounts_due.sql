@@ -0,0 +1,6 @@
+-- =====================================================
+-- Accounts filing deadlines
+-- (used by the API's accounts_overdue and accounts_due_within_days search filters)
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_staging_companies_accounts_next_due
+    ON staging_companies(accounts_next_due_date);
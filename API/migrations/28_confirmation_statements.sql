@@ -0,0 +1,6 @@
+-- =====================================================
+-- Confirmation statement deadlines
+-- (used by the API's confirmation_statement_overdue search filter)
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_staging_companies_conf_stm_next_due
+    ON staging_companies(conf_stm_next_due_date);
@@ -0,0 +1,12 @@
+-- =====================================================
+-- Dissolution dates
+-- (set by the API's snapshot importer and stream ingester; used by the dissolved_from and
+-- dissolved_to search filters)
+-- =====================================================
+ALTER TABLE staging_companies ADD COLUMN IF NOT EXISTS dissolved_on DATE;
+
+CREATE INDEX IF NOT EXISTS idx_staging_companies_dissolved_on
+    ON staging_companies(dissolved_on) WHERE dissolved_on IS NOT NULL;
+
+-- Comments
+COMMENT ON COLUMN staging_companies.dissolved_on IS 'Date the company was dissolved (DissolutionDate in the snapshot, date_of_cessation in the stream)';
@@ -0,0 +1,19 @@
+-- =====================================================
+-- Previous company names
+-- (set by the API's snapshot importer and stream ingester; served by
+-- GET /api/companies/{company_number}/previous-names and matched by the searchTerm filter)
+-- =====================================================
+
+-- Previous names with their dates, most recent first:
+-- [{"name": "...", "effective_from": "YYYY-MM-DD", "ceased_on": "YYYY-MM-DD"}, ...].
+-- previous_names keeps the pipe-separated names the change detection hash is computed over.
+ALTER TABLE staging_companies ADD COLUMN IF NOT EXISTS previous_name_history JSONB;
+
+-- searchTerm matches the current or any previous name with ILIKE, which trigram indexes serve
+CREATE INDEX IF NOT EXISTS idx_staging_companies_name_trgm
+    ON staging_companies USING gin(company_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_staging_companies_previous_names_trgm
+    ON staging_companies USING gin(previous_names gin_trgm_ops);
+
+-- Comments
+COMMENT ON COLUMN staging_companies.previous_name_history IS 'Previous names with effective_from and ceased_on dates, most recent first';
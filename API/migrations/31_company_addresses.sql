@@ -0,0 +1,23 @@
+-- =====================================================
+-- Registered office address search
+-- (used by the API's address_contains search filter)
+-- =====================================================
+
+-- The registered office address as one lower-case string, with the parts separated and runs of
+-- whitespace collapsed to a single space, e.g. '20-22 wenlock road london n1 7gu'
+CREATE OR REPLACE FUNCTION company_address_text(
+    address_line_1 TEXT, address_line_2 TEXT, locality TEXT, region TEXT, postal_code TEXT
+) RETURNS TEXT AS $$
+    SELECT btrim(regexp_replace(lower(
+        COALESCE(address_line_1, '') || ' ' || COALESCE(address_line_2, '') || ' ' ||
+        COALESCE(locality, '') || ' ' || COALESCE(region, '') || ' ' || COALESCE(postal_code, '')
+    ), '\s+', ' ', 'g'))
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_staging_companies_address_trgm
+    ON staging_companies USING gin(
+        company_address_text(address_line_1, address_line_2, locality, region, postal_code) gin_trgm_ops
+    );
+
+-- Comments
+COMMENT ON FUNCTION company_address_text(TEXT, TEXT, TEXT, TEXT, TEXT) IS 'Registered office address lines, locality, region and postcode as one lower-case string, for substring search';
@@ -0,0 +1,48 @@
+-- =====================================================
+-- Officer name matching
+-- (used by GET /api/officers/search to find a person's appointments across companies)
+-- =====================================================
+
+-- The words of an officer's name in lower case, forenames first and surname last. Names in the
+-- published "SURNAME, Forenames" form are reordered, full stops and apostrophes are dropped,
+-- other punctuation separates words, and titles and honours ("mr", "dr", "obe", ...) are
+-- removed unless they are the surname: 'SMITH, Dr John Michael' -> {john,michael,smith}.
+CREATE OR REPLACE FUNCTION officer_name_words(name TEXT) RETURNS TEXT[] AS $$
+    SELECT COALESCE(array_agg(w ORDER BY ord) FILTER (
+        WHERE ord = n OR w <> ALL (ARRAY['mr', 'mrs', 'ms', 'miss', 'mx', 'dr', 'sir', 'dame', 'lord', 'lady',
+            'prof', 'professor', 'rev', 'revd', 'reverend', 'obe', 'mbe', 'cbe', 'kbe', 'qc', 'kc', 'jr', 'jnr', 'sr', 'snr'])
+    ), '{}')
+    FROM (
+        SELECT w, ord, count(*) OVER () AS n
+        FROM unnest(string_to_array(btrim(regexp_replace(regexp_replace(lower(
+            CASE WHEN position(',' IN name) > 0
+                THEN split_part(name, ',', 2) || ' ' || split_part(name, ',', 1)
+                ELSE name
+            END), '[.''’]', '', 'g'), '[^[:alnum:]]+', ' ', 'g')), ' ')) WITH ORDINALITY AS t(w, ord)
+        WHERE w <> ''
+    ) words
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+-- Surname and first forename ('' when the name is a single word), which names are matched on
+-- so middle names can be left out and forenames given as initials
+CREATE OR REPLACE FUNCTION officer_surname(name TEXT) RETURNS TEXT AS $$
+    SELECT COALESCE((officer_name_words(name))[cardinality(officer_name_words(name))], '')
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE OR REPLACE FUNCTION officer_forename(name TEXT) RETURNS TEXT AS $$
+    SELECT CASE WHEN cardinality(officer_name_words(name)) > 1 THEN (officer_name_words(name))[1] ELSE '' END
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+-- The whole normalized name, which appointments of the same person share
+CREATE OR REPLACE FUNCTION officer_name_key(name TEXT) RETURNS TEXT AS $$
+    SELECT array_to_string(officer_name_words(name), ' ')
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_staging_officers_surname_forename
+    ON staging_officers(officer_surname(officer_name), officer_forename(officer_name) text_pattern_ops);
+
+-- Comments
+COMMENT ON FUNCTION officer_name_words(TEXT) IS 'Words of an officer name, lower case, forenames first, without titles or punctuation';
+COMMENT ON FUNCTION officer_surname(TEXT) IS 'Surname of an officer name, lower case';
+COMMENT ON FUNCTION officer_forename(TEXT) IS 'First forename of an officer name, lower case, or empty';
+COMMENT ON FUNCTION officer_name_key(TEXT) IS 'Normalized officer name, for grouping appointments of the same person';
@@ -0,0 +1,19 @@
+-- =====================================================
+-- Officer identities
+-- (used by GET /api/officers/{id} and GET /api/officers/search to treat appointments of the
+-- same person at different companies as one officer)
+-- =====================================================
+
+-- Stable ID of the person an appointment is for: 16 hex characters derived from the normalized
+-- name (officer_name_key) and month of birth, so appointments that share both are deduplicated
+-- into one officer. Officers without a date of birth, such as corporate officers, are
+-- identified by name alone.
+CREATE OR REPLACE FUNCTION officer_person_id(name TEXT, date_of_birth DATE) RETURNS TEXT AS $$
+    SELECT left(md5(officer_name_key(name) || '|' || COALESCE(to_char(date_of_birth, 'YYYY-MM'), '')), 16)
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_staging_officers_person_id
+    ON staging_officers(officer_person_id(officer_name, date_of_birth));
+
+-- Comments
+COMMENT ON FUNCTION officer_person_id(TEXT, DATE) IS 'ID shared by the appointments of one person: a hash of the normalized name and month of birth';
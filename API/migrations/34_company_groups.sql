@@ -0,0 +1,20 @@
+-- =====================================================
+-- Corporate groups
+-- (set by the API's PSC importer and stream ingester; used by
+-- GET /api/companies/{company_number}/group to link parents and subsidiaries)
+-- =====================================================
+
+-- Where a corporate PSC is registered, and its company number when that is Companies House.
+-- Adding these to the PSC change detection hash means the first PSC import after this
+-- migration rewrites every PSC once.
+ALTER TABLE staging_pscs
+    ADD COLUMN IF NOT EXISTS place_registered VARCHAR(200),
+    ADD COLUMN IF NOT EXISTS country_registered VARCHAR(200),
+    ADD COLUMN IF NOT EXISTS parent_company_number VARCHAR(8);
+
+-- A company's subsidiaries are the companies with a current PSC naming it as parent
+CREATE INDEX IF NOT EXISTS idx_staging_pscs_parent_company
+    ON staging_pscs(parent_company_number) WHERE parent_company_number IS NOT NULL AND ceased_on IS NULL;
+
+-- Comments
+COMMENT ON COLUMN staging_pscs.parent_company_number IS 'Company number of a corporate PSC registered at Companies House, linking the company to its parent';
@@ -0,0 +1,31 @@
+-- =====================================================
+-- Asynchronous export jobs
+-- (owned by the Go API; see POST /api/exports)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id VARCHAR(200) NOT NULL DEFAULT '', -- Principal that created the job ('' when auth is disabled)
+    filters JSONB NOT NULL, -- Search filters, with defaults applied
+    format VARCHAR(10) NOT NULL, -- 'csv' or 'ndjson'
+    status VARCHAR(20) NOT NULL DEFAULT 'queued', -- 'queued', 'running', 'completed', 'failed' or 'expired'
+
+    -- Progress
+    rows_written INTEGER NOT NULL DEFAULT 0,
+    total_rows INTEGER, -- Matching companies when the job started, if they could be counted
+    attempts INTEGER NOT NULL DEFAULT 0,
+    lease_until TIMESTAMP, -- A running job whose lease has passed is claimed again
+    error TEXT,
+
+    file_path TEXT, -- Storage key of the finished file, <tenant>/<id>.<format>, in the export directory or bucket
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP -- When the finished file is deleted
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_owner ON export_jobs(owner_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_export_jobs_pending ON export_jobs(created_at) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS idx_export_jobs_expiry ON export_jobs(expires_at) WHERE status = 'completed';
+
+-- Comments
+COMMENT ON TABLE export_jobs IS 'Background exports of company searches, run by the API export workers';
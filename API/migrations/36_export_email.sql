@@ -0,0 +1,13 @@
+-- =====================================================
+-- Export email delivery
+-- (owned by the Go API; see the email option of POST /api/exports)
+-- =====================================================
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS email_to TEXT[];
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS email_attach BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS email_sent_at TIMESTAMP;
+ALTER TABLE export_jobs ADD COLUMN IF NOT EXISTS email_error TEXT;
+
+-- Comments
+COMMENT ON COLUMN export_jobs.email_to IS 'Addresses the finished export is emailed to (NULL for no email)';
+COMMENT ON COLUMN export_jobs.email_attach IS 'Attach the file rather than linking to it; files over the attachment limit are linked';
+COMMENT ON COLUMN export_jobs.email_error IS 'Why the email could not be sent';
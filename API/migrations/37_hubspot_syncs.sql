@@ -0,0 +1,33 @@
+-- =====================================================
+-- HubSpot company syncs
+-- (owned by the Go API; see POST /api/integrations/hubspot/syncs)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS hubspot_syncs (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id VARCHAR(200) NOT NULL DEFAULT '', -- Principal that started the sync ('' when auth is disabled)
+    watchlist_id INTEGER, -- Companies on this watchlist, or
+    filters JSONB, -- the companies matching these search filters, with defaults applied
+    max_companies INTEGER NOT NULL, -- Most search results synced
+    status VARCHAR(20) NOT NULL DEFAULT 'queued', -- 'queued', 'running', 'completed' or 'failed'
+
+    -- Progress
+    total_companies INTEGER,
+    processed INTEGER NOT NULL DEFAULT 0,
+    created INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    company_errors TEXT[] NOT NULL DEFAULT '{}', -- The first errors for individual companies
+    attempts INTEGER NOT NULL DEFAULT 0,
+    lease_until TIMESTAMP, -- A running sync whose lease has passed is claimed again
+    error TEXT, -- Why the sync as a whole failed
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_hubspot_syncs_owner ON hubspot_syncs(owner_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_hubspot_syncs_pending ON hubspot_syncs(created_at) WHERE status IN ('queued', 'running');
+
+-- Comments
+COMMENT ON TABLE hubspot_syncs IS 'Background syncs of watchlists or search results to HubSpot companies, run by the API';
@@ -0,0 +1,21 @@
+-- =====================================================
+-- Saved search webhook triggers
+-- (owned by the Go API; see the company.matched_search webhook event)
+-- =====================================================
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS filters JSONB;
+
+-- Search match events are numbered by webhook_search_matches rather than company_change_events
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_event_id_fkey;
+
+CREATE TABLE IF NOT EXISTS webhook_search_matches (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    company_number VARCHAR(8) NOT NULL,
+    matched_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE(subscription_id, company_number)
+);
+
+-- Comments
+COMMENT ON COLUMN webhook_subscriptions.filters IS 'Saved search whose matching new companies are delivered as company.matched_search events (NULL for change events)';
+COMMENT ON TABLE webhook_search_matches IS 'Companies already delivered to a saved search subscription, so each is sent once';
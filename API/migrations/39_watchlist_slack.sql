@@ -0,0 +1,8 @@
+-- =====================================================
+-- Slack notifications for watchlists
+-- (owned by the Go API; see PUT /api/watchlists/{id}/slack)
+-- =====================================================
+ALTER TABLE watchlists ADD COLUMN IF NOT EXISTS slack_webhook_url VARCHAR(2000);
+
+-- Comments
+COMMENT ON COLUMN watchlists.slack_webhook_url IS 'Slack incoming webhook that changes to the watchlist''s companies are posted to (NULL for none)';
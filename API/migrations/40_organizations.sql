@@ -0,0 +1,33 @@
+-- =====================================================
+-- Organizations and users
+-- (owned by the Go API; see /api/admin/organizations)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- JWT subjects belonging to an organization
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL REFERENCES organizations(id),
+    subject VARCHAR(200) NOT NULL UNIQUE,
+    email VARCHAR(320),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_organization ON users(organization_id);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_organization ON api_keys(organization_id);
+
+-- Comments
+COMMENT ON TABLE organizations IS 'Tenants: stored objects of their API keys and users are shared within, and isolated between, organizations';
+COMMENT ON COLUMN users.subject IS 'JWT sub claim of the user';
+COMMENT ON COLUMN api_keys.organization_id IS 'Organization the key acts for (NULL for a key of its own, and for platform admin keys)';
+COMMENT ON COLUMN watchlists.owner_id IS 'Tenant owning the row: org:<id> for organization members, else key:<id> or sub:<subject> ('''' when auth is disabled)';
+COMMENT ON COLUMN webhook_subscriptions.owner_id IS 'Tenant owning the row, as for watchlists.owner_id';
+COMMENT ON COLUMN export_jobs.owner_id IS 'Tenant owning the row, as for watchlists.owner_id';
+COMMENT ON COLUMN hubspot_syncs.owner_id IS 'Tenant owning the row, as for watchlists.owner_id';
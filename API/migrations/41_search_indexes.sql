@@ -0,0 +1,32 @@
+-- =====================================================
+-- Search indexes
+-- (for the API's search filters and sorts that earlier schema files left unindexed; the API
+-- warns at startup when any index search relies on is missing)
+-- =====================================================
+
+-- industry with a SIC code and the sic_codes where clause, which match with @>
+CREATE INDEX IF NOT EXISTS idx_staging_companies_sic_codes
+    ON staging_companies USING gin(sic_codes);
+
+-- location values that are not a known location name match locality or region as a substring
+CREATE INDEX IF NOT EXISTS idx_staging_companies_locality_trgm
+    ON staging_companies USING gin(locality gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_staging_companies_region_trgm
+    ON staging_companies USING gin(region gin_trgm_ops);
+
+-- company_status compares case-insensitively, which the plain company_status index cannot serve
+CREATE INDEX IF NOT EXISTS idx_staging_companies_status_lower
+    ON staging_companies(lower(company_status));
+
+-- company_age ranges and the incorporation_date sort
+CREATE INDEX IF NOT EXISTS idx_staging_companies_incorporation_date
+    ON staging_companies(incorporation_date);
+
+-- The default sort, so a page of results need not sort every match
+CREATE INDEX IF NOT EXISTS idx_staging_companies_name
+    ON staging_companies(company_name);
+
+-- A company's financial history newest first, and its latest period for the summary views
+-- and health scoring
+CREATE INDEX IF NOT EXISTS idx_staging_financials_company_period
+    ON staging_financials(company_number, period_end DESC);
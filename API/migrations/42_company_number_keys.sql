@@ -0,0 +1,22 @@
+-- =====================================================
+-- Company number keys
+-- (used to collapse companies staged under several forms of their number in search results,
+-- and by the API's duplicate companies report)
+-- =====================================================
+
+-- Reduces a company number to the form Companies House publishes: trimmed, upper case and,
+-- for numbers that are all digits, zero-padded to eight (spreadsheets drop leading zeros)
+CREATE OR REPLACE FUNCTION company_number_key(company_number TEXT) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN btrim(company_number) ~ '^[0-9]{1,7}$' THEN lpad(btrim(company_number), 8, '0')
+        ELSE upper(btrim(company_number))
+    END
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+-- Only numbers not in their published form, which are few; the rest are found by primary key
+CREATE INDEX IF NOT EXISTS idx_staging_companies_number_variants
+    ON staging_companies(company_number_key(company_number))
+    WHERE company_number <> company_number_key(company_number);
+
+-- Comments
+COMMENT ON FUNCTION company_number_key(TEXT) IS 'Company number in its published form, e.g. 01234567 for 1234567';
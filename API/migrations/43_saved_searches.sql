@@ -0,0 +1,47 @@
+-- =====================================================
+-- Saved searches and their new-match alerts
+-- (owned by the Go API; see /api/saved-searches)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id SERIAL PRIMARY KEY,
+    owner_id VARCHAR(200) NOT NULL DEFAULT '',
+    name VARCHAR(200) NOT NULL,
+    filters JSONB NOT NULL,
+    webhook_subscription_id INTEGER REFERENCES webhook_subscriptions(id) ON DELETE SET NULL,
+    email_to TEXT[] NOT NULL DEFAULT '{}',
+    evaluated_at TIMESTAMP, -- NULL until the first evaluation records the baseline
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_owner ON saved_searches(owner_id);
+
+-- Companies currently matching each saved search
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    saved_search_id INTEGER NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    company_number VARCHAR(8) NOT NULL,
+    baseline BOOLEAN NOT NULL DEFAULT false,
+    matched_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (saved_search_id, company_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_new ON saved_search_matches(saved_search_id, matched_at) WHERE NOT baseline;
+
+-- One alert per evaluation that found new matches. Numbered from the change event sequence, so
+-- alert webhook deliveries never share an event ID with change events to the same subscription.
+CREATE TABLE IF NOT EXISTS saved_search_alerts (
+    id BIGINT PRIMARY KEY DEFAULT nextval('company_change_events_id_seq'),
+    saved_search_id INTEGER NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    new_matches INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_alerts_search ON saved_search_alerts(saved_search_id, created_at);
+
+-- Comments
+COMMENT ON TABLE saved_searches IS 'Searches evaluated nightly against new and changed companies, alerting their owner to new matches';
+COMMENT ON COLUMN saved_searches.owner_id IS 'Tenant owning the row, as for watchlists.owner_id';
+COMMENT ON COLUMN saved_searches.webhook_subscription_id IS 'Change event subscription sent a saved_search.new_matches event for each alert';
+COMMENT ON COLUMN saved_searches.email_to IS 'Addresses emailed each alert';
+COMMENT ON COLUMN saved_search_matches.baseline IS 'Matched when the search was first evaluated, so not a new match';
+COMMENT ON TABLE saved_search_alerts IS 'Evaluations of a saved search that found new matches';
@@ -0,0 +1,30 @@
+-- =====================================================
+-- Field-level company change history
+-- (owned by the Go API; see /api/companies/{company_number}/changes)
+-- =====================================================
+
+-- Fields the change detector snapshots for the history, beyond those it diffs for events.
+-- Snapshots taken before this migration have fields_tracked false, and their next detection
+-- run only records these fields as a baseline.
+ALTER TABLE company_snapshots ADD COLUMN IF NOT EXISTS fields_tracked BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE company_snapshots ADD COLUMN IF NOT EXISTS company_name TEXT;
+ALTER TABLE company_snapshots ADD COLUMN IF NOT EXISTS registered_address TEXT;
+ALTER TABLE company_snapshots ADD COLUMN IF NOT EXISTS latest_turnover NUMERIC;
+ALTER TABLE company_snapshots ADD COLUMN IF NOT EXISTS latest_net_assets NUMERIC;
+
+-- One row per changed field per detection run
+CREATE TABLE IF NOT EXISTS company_changes (
+    id BIGSERIAL PRIMARY KEY,
+    company_number VARCHAR(8) NOT NULL,
+    field VARCHAR(50) NOT NULL, -- 'company_name', 'company_status', 'registered_address', 'active_officers', 'latest_period_end', 'turnover', 'net_assets'
+    old_value TEXT,
+    new_value TEXT,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_changes_company ON company_changes(company_number, changed_at);
+
+-- Comments
+COMMENT ON TABLE company_changes IS 'Field-level history of changes to ingested companies, recorded by change detection';
+COMMENT ON COLUMN company_changes.changed_at IS 'When the change was detected, within CHANGE_DETECTION_INTERVAL of its ingestion';
+COMMENT ON COLUMN company_snapshots.fields_tracked IS 'Whether the snapshot holds the company_changes fields';
@@ -0,0 +1,9 @@
+-- =====================================================
+-- Change feed indexes
+-- (owned by the Go API; see /api/companies/changes, which with change detection reads each
+-- staging table's rows written since a timestamp)
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_staging_financials_ingested_at ON staging_financials(ingested_at);
+CREATE INDEX IF NOT EXISTS idx_staging_officers_last_updated ON staging_officers(last_updated);
+CREATE INDEX IF NOT EXISTS idx_staging_charges_last_updated ON staging_charges(last_updated);
+CREATE INDEX IF NOT EXISTS idx_staging_insolvency_cases_last_updated ON staging_insolvency_cases(last_updated);
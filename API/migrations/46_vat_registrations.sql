@@ -0,0 +1,20 @@
+-- =====================================================
+-- VAT registrations
+-- (vat_number is loaded by the API's snapshot importer from bulk lookups; the API's VAT check
+-- job confirms each number with HMRC; used by the vat_registered search filter)
+-- =====================================================
+ALTER TABLE staging_companies
+    ADD COLUMN IF NOT EXISTS vat_number VARCHAR(12), -- 9 digits, or 12 for a group member, without the GB prefix
+    ADD COLUMN IF NOT EXISTS vat_registered BOOLEAN, -- NULL until vat_number has been checked
+    ADD COLUMN IF NOT EXISTS vat_checked_at TIMESTAMP;
+
+-- The VAT check job takes numbers never checked first, then the longest unchecked
+CREATE INDEX IF NOT EXISTS idx_staging_companies_vat_checked_at
+    ON staging_companies(vat_checked_at NULLS FIRST) WHERE vat_number IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_staging_companies_vat_registered
+    ON staging_companies(company_number) WHERE vat_registered IS TRUE;
+
+-- Comments
+COMMENT ON COLUMN staging_companies.vat_number IS 'VAT registration number from a bulk lookup, without the GB prefix';
+COMMENT ON COLUMN staging_companies.vat_registered IS 'Whether HMRC confirmed vat_number as registered when last checked (NULL until checked)';
+COMMENT ON COLUMN staging_companies.vat_checked_at IS 'When vat_number was last checked with HMRC; cleared when a lookup changes it';
@@ -0,0 +1,20 @@
+-- =====================================================
+-- Company websites
+-- (set by the API's website enrichment job; used by the has_website search filter)
+-- =====================================================
+ALTER TABLE staging_companies
+    ADD COLUMN IF NOT EXISTS website_domain VARCHAR(253), -- Lower case without scheme or www., e.g. 'acme.co.uk'
+    ADD COLUMN IF NOT EXISTS website_source VARCHAR(50), -- Provider that found it, e.g. 'guess'
+    ADD COLUMN IF NOT EXISTS website_confidence NUMERIC(3, 2), -- From 0 to 1
+    ADD COLUMN IF NOT EXISTS website_checked_at TIMESTAMP;
+
+-- The enrichment job takes active companies never checked first, then the longest unchecked
+CREATE INDEX IF NOT EXISTS idx_staging_companies_website_checked_at
+    ON staging_companies(website_checked_at NULLS FIRST) WHERE lower(company_status) = 'active';
+CREATE INDEX IF NOT EXISTS idx_staging_companies_website_domain
+    ON staging_companies(website_domain) WHERE website_domain IS NOT NULL;
+
+-- Comments
+COMMENT ON COLUMN staging_companies.website_domain IS 'Domain of the company''s website, matched by name and postcode (NULL when none was found)';
+COMMENT ON COLUMN staging_companies.website_confidence IS 'How well the website matched the company''s name and postcode, from 0 to 1';
+COMMENT ON COLUMN staging_companies.website_checked_at IS 'When the enrichment job last looked for a website';
@@ -0,0 +1,30 @@
+-- =====================================================
+-- Contact enrichment
+-- (owned by the Go API; see POST /api/companies/{company_number}/enrich)
+-- =====================================================
+-- Contacts a tenant enriched a company with, returned again until they are stale
+CREATE TABLE IF NOT EXISTS company_contacts (
+    owner_id VARCHAR(200) NOT NULL DEFAULT '',
+    company_number VARCHAR(8) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    emails JSONB NOT NULL DEFAULT '[]',
+    phones JSONB NOT NULL DEFAULT '[]',
+    enriched_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (owner_id, company_number)
+);
+
+-- Lookups charged to each tenant per month
+CREATE TABLE IF NOT EXISTS contact_credits (
+    owner_id VARCHAR(200) NOT NULL DEFAULT '',
+    period DATE NOT NULL,
+    credits_used INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (owner_id, period)
+);
+
+-- Comments
+COMMENT ON TABLE company_contacts IS 'Email addresses and phone numbers found for a company by a contact provider, per tenant';
+COMMENT ON COLUMN company_contacts.owner_id IS 'Tenant that paid for the lookup, as for watchlists.owner_id';
+COMMENT ON COLUMN company_contacts.source IS 'Contact provider, e.g. hunter';
+COMMENT ON TABLE contact_credits IS 'Contact enrichment lookups charged to each tenant, per calendar month';
+COMMENT ON COLUMN contact_credits.period IS 'First day of the month';
@@ -0,0 +1,30 @@
+-- =====================================================
+-- FX rates for financials reported in other currencies
+-- (owned by the Go API; loaded with go run ./cmd/import -type fx)
+-- =====================================================
+-- Daily GBP value of one unit of each currency, e.g. EUR on 2024-03-28
+CREATE TABLE IF NOT EXISTS fx_rates (
+    currency CHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    gbp_rate NUMERIC(20, 10) NOT NULL,
+    batch_id VARCHAR(50),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (currency, rate_date)
+);
+
+-- Also in 04_financials.sql, for databases set up before it had them; the API sets gbp_rate
+-- (see database/fx.go), and 57_latest_financials_currency.sql recreates staging_latest_financials
+-- to convert with it
+ALTER TABLE staging_financials
+    ADD COLUMN IF NOT EXISTS currency CHAR(3), -- From the accounts parser
+    ADD COLUMN IF NOT EXISTS gbp_rate NUMERIC(20, 10);
+
+-- Periods to convert, a small share of all
+CREATE INDEX IF NOT EXISTS idx_staging_financials_currency
+    ON staging_financials(currency, period_end) WHERE currency <> 'GBP';
+
+-- Comments
+COMMENT ON TABLE fx_rates IS 'Daily exchange rates to GBP, used to convert financials reported in other currencies';
+COMMENT ON COLUMN fx_rates.gbp_rate IS 'GBP value of one unit of currency on rate_date';
+COMMENT ON COLUMN staging_financials.currency IS 'Reporting currency of the accounts (ISO 4217); NULL is read as GBP';
+COMMENT ON COLUMN staging_financials.gbp_rate IS 'GBP value of one unit of currency on period_end, from fx_rates; NULL until a rate is known';
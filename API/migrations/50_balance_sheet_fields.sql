@@ -0,0 +1,16 @@
+-- =====================================================
+-- Intangible assets and shareholder funds of financials
+-- (read by the Go API from staging_latest_financials)
+-- =====================================================
+-- Also in 04_financials.sql, for databases set up before it had them; the accounts parser fills
+-- them from the IntangibleAssets and Equity tags (IntangibleFixedAssets and ShareholderFunds in
+-- older UK GAAP accounts), and 60_latest_financials_balance_sheet.sql recreates
+-- staging_latest_financials to return them
+ALTER TABLE staging_financials
+    ADD COLUMN IF NOT EXISTS intangible_assets NUMERIC(12, 2),
+    ADD COLUMN IF NOT EXISTS shareholder_funds NUMERIC(12, 2);
+
+-- Comments
+COMMENT ON COLUMN staging_financials.fixed_assets IS 'Total fixed assets, tangible, intangible and investments';
+COMMENT ON COLUMN staging_financials.intangible_assets IS 'Intangible fixed assets, e.g. goodwill and software, net of amortisation';
+COMMENT ON COLUMN staging_financials.shareholder_funds IS 'Total equity attributable to shareholders, as reported on the balance sheet';
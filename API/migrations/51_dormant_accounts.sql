@@ -0,0 +1,12 @@
+-- =====================================================
+-- Dormancy declared in accounts
+-- (used by the API's exclude_dormant search filter)
+-- =====================================================
+-- Also in 04_financials.sql, for databases set up before it had it; the accounts parser fills it
+-- from the EntityDormantTruefalse tag (EntityDormant in older UK GAAP accounts), and
+-- 61_latest_financials_dormant.sql recreates staging_latest_financials to read it
+ALTER TABLE staging_financials
+    ADD COLUMN IF NOT EXISTS entity_dormant VARCHAR(255);
+
+-- Comments
+COMMENT ON COLUMN staging_financials.entity_dormant IS 'Whether the accounts declare the company dormant, "true" or "false"; NULL when they do not say';
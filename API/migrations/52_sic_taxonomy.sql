@@ -0,0 +1,59 @@
+-- =====================================================
+-- SIC 2007 taxonomy
+-- (used by the API's sic_section, sic_division and sic_class search filters; loaded with
+-- go run ./cmd/import -type sic)
+-- =====================================================
+-- The 21 sections of SIC 2007 and the divisions each spans, e.g. J for 58 to 63
+CREATE TABLE IF NOT EXISTS sic_sections (
+    section CHAR(1) PRIMARY KEY,
+    description TEXT NOT NULL,
+    first_division SMALLINT NOT NULL,
+    last_division SMALLINT NOT NULL
+);
+
+-- Seed data: the sections are fixed by the classification. Existing rows are left alone.
+INSERT INTO sic_sections (section, description, first_division, last_division) VALUES
+    ('A', 'Agriculture, forestry and fishing', 1, 3),
+    ('B', 'Mining and quarrying', 5, 9),
+    ('C', 'Manufacturing', 10, 33),
+    ('D', 'Electricity, gas, steam and air conditioning supply', 35, 35),
+    ('E', 'Water supply; sewerage, waste management and remediation activities', 36, 39),
+    ('F', 'Construction', 41, 43),
+    ('G', 'Wholesale and retail trade; repair of motor vehicles and motorcycles', 45, 47),
+    ('H', 'Transportation and storage', 49, 53),
+    ('I', 'Accommodation and food service activities', 55, 56),
+    ('J', 'Information and communication', 58, 63),
+    ('K', 'Financial and insurance activities', 64, 66),
+    ('L', 'Real estate activities', 68, 68),
+    ('M', 'Professional, scientific and technical activities', 69, 75),
+    ('N', 'Administrative and support service activities', 77, 82),
+    ('O', 'Public administration and defence; compulsory social security', 84, 84),
+    ('P', 'Education', 85, 85),
+    ('Q', 'Human health and social work activities', 86, 88),
+    ('R', 'Arts, entertainment and recreation', 90, 93),
+    ('S', 'Other service activities', 94, 96),
+    ('T', 'Activities of households as employers; undifferentiated goods- and services-producing activities of households for own use', 97, 98),
+    ('U', 'Activities of extraterritorial organisations and bodies', 99, 99)
+ON CONFLICT (section) DO NOTHING;
+
+-- Every SIC code companies file with, from the Companies House condensed SIC list, placed in
+-- its division, group, class and section
+CREATE TABLE IF NOT EXISTS sic_taxonomy (
+    code CHAR(5) PRIMARY KEY, -- e.g. '62020'
+    description TEXT NOT NULL,
+    section CHAR(1) REFERENCES sic_sections(section),
+    division CHAR(2) GENERATED ALWAYS AS (left(code, 2)) STORED, -- e.g. '62'
+    sic_group CHAR(3) GENERATED ALWAYS AS (left(code, 3)) STORED, -- e.g. '620'
+    class CHAR(4) GENERATED ALWAYS AS (left(code, 4)) STORED, -- e.g. '6202'
+    batch_id VARCHAR(50),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sic_taxonomy_section ON sic_taxonomy(section);
+CREATE INDEX IF NOT EXISTS idx_sic_taxonomy_division ON sic_taxonomy(division);
+CREATE INDEX IF NOT EXISTS idx_sic_taxonomy_class ON sic_taxonomy(class);
+
+-- Comments
+COMMENT ON TABLE sic_sections IS 'SIC 2007 sections and the range of divisions in each';
+COMMENT ON TABLE sic_taxonomy IS 'SIC 2007 codes used by Companies House, with the division, group, class and section of each';
+COMMENT ON COLUMN sic_taxonomy.section IS 'Section whose divisions include the code''s; NULL for a division no section spans';
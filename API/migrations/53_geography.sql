@@ -0,0 +1,72 @@
+-- =====================================================
+-- Geography hierarchy
+-- (used by the API's country, uk_region and locality search filters; postcode_lookup
+-- gets the codes from the ONS Postcode Directory, loaded with go run ./cmd/import -type
+-- postcodes, and the API's geocoding job copies them onto staging_companies)
+-- =====================================================
+-- The four countries of the UK and the regions inside them: the nine regions of England, and
+-- one region for each of Scotland, Wales and Northern Ireland, as the ONS (and ITL1) count them
+CREATE TABLE IF NOT EXISTS geography_areas (
+    code VARCHAR(9) PRIMARY KEY, -- ONS code as in the Postcode Directory, e.g. 'E12000002'
+    slug VARCHAR(40) NOT NULL, -- Filter value, e.g. 'north-west'
+    name VARCHAR(100) NOT NULL,
+    level VARCHAR(10) NOT NULL CHECK (level IN ('country', 'region')),
+    parent_code VARCHAR(9) REFERENCES geography_areas(code), -- Country of a region
+    UNIQUE (level, slug)
+);
+
+-- Seed data: countries, then regions. The Postcode Directory gives postcodes outside England
+-- the pseudo region codes ending 99999999. Existing rows are left alone.
+INSERT INTO geography_areas (code, slug, name, level) VALUES
+    ('E92000001', 'england', 'England', 'country'),
+    ('S92000003', 'scotland', 'Scotland', 'country'),
+    ('W92000004', 'wales', 'Wales', 'country'),
+    ('N92000002', 'northern-ireland', 'Northern Ireland', 'country')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO geography_areas (code, slug, name, level, parent_code) VALUES
+    ('E12000001', 'north-east', 'North East', 'region', 'E92000001'),
+    ('E12000002', 'north-west', 'North West', 'region', 'E92000001'),
+    ('E12000003', 'yorkshire-and-the-humber', 'Yorkshire and The Humber', 'region', 'E92000001'),
+    ('E12000004', 'east-midlands', 'East Midlands', 'region', 'E92000001'),
+    ('E12000005', 'west-midlands', 'West Midlands', 'region', 'E92000001'),
+    ('E12000006', 'east-of-england', 'East of England', 'region', 'E92000001'),
+    ('E12000007', 'london', 'London', 'region', 'E92000001'),
+    ('E12000008', 'south-east', 'South East', 'region', 'E92000001'),
+    ('E12000009', 'south-west', 'South West', 'region', 'E92000001'),
+    ('S99999999', 'scotland', 'Scotland', 'region', 'S92000003'),
+    ('W99999999', 'wales', 'Wales', 'region', 'W92000004'),
+    ('N99999999', 'northern-ireland', 'Northern Ireland', 'region', 'N92000002')
+ON CONFLICT (code) DO NOTHING;
+
+-- Country and region of each postcode, NULL until a directory with them is imported
+ALTER TABLE postcode_lookup
+    ADD COLUMN IF NOT EXISTS country_code VARCHAR(9),
+    ADD COLUMN IF NOT EXISTS region_code VARCHAR(9);
+
+ALTER TABLE staging_companies
+    ADD COLUMN IF NOT EXISTS country_code VARCHAR(9),
+    ADD COLUMN IF NOT EXISTS region_code VARCHAR(9);
+
+CREATE INDEX IF NOT EXISTS idx_staging_companies_country_code ON staging_companies(country_code);
+CREATE INDEX IF NOT EXISTS idx_staging_companies_region_code ON staging_companies(region_code);
+
+-- Code of the country a free-text address country names, e.g. 'ENGLAND' -> 'E92000001', or
+-- NULL for anything else, such as 'United Kingdom'. Used for companies whose postcode is not in
+-- postcode_lookup.
+CREATE OR REPLACE FUNCTION country_code_of(country TEXT) RETURNS VARCHAR AS $$
+    SELECT CASE btrim(regexp_replace(lower(country), '[^a-z]+', ' ', 'g'))
+        WHEN 'england' THEN 'E92000001'
+        WHEN 'scotland' THEN 'S92000003'
+        WHEN 'wales' THEN 'W92000004'
+        WHEN 'cymru' THEN 'W92000004'
+        WHEN 'northern ireland' THEN 'N92000002'
+        WHEN 'n ireland' THEN 'N92000002'
+    END
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+-- Comments
+COMMENT ON TABLE geography_areas IS 'UK countries and the regions inside them, keyed by ONS code';
+COMMENT ON COLUMN staging_companies.country_code IS 'ONS code of the registered office''s country, from its postcode or else its address country';
+COMMENT ON COLUMN staging_companies.region_code IS 'ONS code of the registered office''s region, from its postcode, or the one region of a country other than England';
+COMMENT ON FUNCTION country_code_of(TEXT) IS 'ONS code of the UK country an address country names';
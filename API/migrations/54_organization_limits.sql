@@ -0,0 +1,15 @@
+-- =====================================================
+-- Organization rate limit tier and quotas
+-- (set by platform admins; keys issued by an organization's own admins
+-- inherit them, see POST /api/admin/keys)
+-- =====================================================
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS tier VARCHAR(20) NOT NULL DEFAULT 'standard';
+
+-- NULL quotas mean unlimited
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS monthly_request_quota BIGINT;
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS monthly_row_quota BIGINT;
+
+-- Comments
+COMMENT ON COLUMN organizations.tier IS 'Rate limit tier of keys issued by the organization''s admins';
+COMMENT ON COLUMN organizations.monthly_request_quota IS 'Highest monthly request quota the organization''s admins can give a key (NULL = unlimited)';
+COMMENT ON COLUMN organizations.monthly_row_quota IS 'Highest monthly row quota the organization''s admins can give a key (NULL = unlimited)';
@@ -0,0 +1,56 @@
+-- =====================================================
+-- Search summaries (precomputed per-company aggregates)
+-- Refreshed by the API's summary refresh job or
+-- POST /api/admin/summaries/refresh
+-- =====================================================
+-- Both views are dropped and recreated, so the migration can be re-applied. Later changes to a
+-- view land as a new migration that recreates it in the same way.
+DROP MATERIALIZED VIEW IF EXISTS staging_latest_financials CASCADE;
+
+-- previous_turnover and previous_net_worth come from the period before the latest one;
+-- revenue_growth is the percentage change in turnover between them (NULL when either is
+-- missing or the previous is not positive)
+CREATE MATERIALIZED VIEW staging_latest_financials AS
+SELECT DISTINCT ON (company_number)
+    company_number,
+    turnover,
+    profit_loss as profit_after_tax,
+    total_assets,
+    total_liabilities,
+    net_assets_liabilities as net_worth,
+    0 as profit_margin,
+    0 as current_ratio,
+    period_end,
+    LAG(turnover) OVER periods as previous_turnover,
+    LAG(net_assets_liabilities) OVER periods as previous_net_worth,
+    CASE WHEN LAG(turnover) OVER periods > 0
+        THEN ROUND((turnover - LAG(turnover) OVER periods) / LAG(turnover) OVER periods * 100, 2)
+    END as revenue_growth
+FROM staging_financials
+WHERE period_end IS NOT NULL
+WINDOW periods AS (PARTITION BY company_number ORDER BY period_end)
+ORDER BY company_number, period_end DESC;
+
+-- Unique index is required for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX idx_staging_latest_financials_company ON staging_latest_financials(company_number);
+CREATE INDEX idx_staging_latest_financials_turnover ON staging_latest_financials(turnover);
+CREATE INDEX idx_staging_latest_financials_net_worth ON staging_latest_financials(net_worth);
+CREATE INDEX idx_staging_latest_financials_period ON staging_latest_financials(period_end);
+CREATE INDEX idx_staging_latest_financials_growth ON staging_latest_financials(revenue_growth);
+
+DROP MATERIALIZED VIEW IF EXISTS staging_officer_counts CASCADE;
+
+CREATE MATERIALIZED VIEW staging_officer_counts AS
+SELECT
+    company_number,
+    COUNT(*) FILTER (WHERE resigned_on IS NULL) as active_officers,
+    COUNT(*) as total_officers
+FROM staging_officers
+GROUP BY company_number;
+
+CREATE UNIQUE INDEX idx_staging_officer_counts_company ON staging_officer_counts(company_number);
+CREATE INDEX idx_staging_officer_counts_active ON staging_officer_counts(active_officers);
+
+-- Comments
+COMMENT ON MATERIALIZED VIEW staging_latest_financials IS 'Most recent financial period per company, used by API search filters';
+COMMENT ON MATERIALIZED VIEW staging_officer_counts IS 'Active and total officer counts per company, used by API search filters';
@@ -0,0 +1,45 @@
+-- =====================================================
+-- Employee counts in search summaries
+-- (staging_latest_financials gains employee_count, used by the API's
+-- employee_count search filter and result field)
+-- =====================================================
+-- Recreates staging_latest_financials as in 55_search_summaries.sql, with the average number of
+-- employees of the latest period
+DROP MATERIALIZED VIEW IF EXISTS staging_latest_financials CASCADE;
+
+-- previous_turnover and previous_net_worth come from the period before the latest one;
+-- revenue_growth is the percentage change in turnover between them (NULL when either is
+-- missing or the previous is not positive); employee_count is the average number of employees
+-- the accounts report, NULL when they do not
+CREATE MATERIALIZED VIEW staging_latest_financials AS
+SELECT DISTINCT ON (company_number)
+    company_number,
+    turnover,
+    profit_loss as profit_after_tax,
+    total_assets,
+    total_liabilities,
+    net_assets_liabilities as net_worth,
+    0 as profit_margin,
+    0 as current_ratio,
+    period_end,
+    average_number_employees_during_period as employee_count,
+    LAG(turnover) OVER periods as previous_turnover,
+    LAG(net_assets_liabilities) OVER periods as previous_net_worth,
+    CASE WHEN LAG(turnover) OVER periods > 0
+        THEN ROUND((turnover - LAG(turnover) OVER periods) / LAG(turnover) OVER periods * 100, 2)
+    END as revenue_growth
+FROM staging_financials
+WHERE period_end IS NOT NULL
+WINDOW periods AS (PARTITION BY company_number ORDER BY period_end)
+ORDER BY company_number, period_end DESC;
+
+-- Unique index is required for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX idx_staging_latest_financials_company ON staging_latest_financials(company_number);
+CREATE INDEX idx_staging_latest_financials_turnover ON staging_latest_financials(turnover);
+CREATE INDEX idx_staging_latest_financials_net_worth ON staging_latest_financials(net_worth);
+CREATE INDEX idx_staging_latest_financials_period ON staging_latest_financials(period_end);
+CREATE INDEX idx_staging_latest_financials_growth ON staging_latest_financials(revenue_growth);
+CREATE INDEX idx_staging_latest_financials_employees ON staging_latest_financials(employee_count);
+
+-- Comments
+COMMENT ON MATERIALIZED VIEW staging_latest_financials IS 'Most recent financial period per company, used by API search filters';
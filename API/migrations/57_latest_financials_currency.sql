@@ -0,0 +1,80 @@
+-- =====================================================
+-- GBP conversion in search summaries
+-- (staging_latest_financials converts to GBP at staging_financials.gbp_rate,
+-- see 49_fx_rates.sql)
+-- =====================================================
+-- Recreates staging_latest_financials as in 56_latest_financials_employees.sql, converting the
+-- latest period to GBP at its gbp_rate and keeping the figures as reported
+DROP MATERIALIZED VIEW IF EXISTS staging_latest_financials CASCADE;
+
+-- Figures are in GBP: periods reported in another currency are converted at gbp_rate, the rate
+-- on their period end, and are NULL until one is known. currency and the *_original columns
+-- keep the figures as reported. previous_turnover and previous_net_worth come from the period
+-- before the latest one; revenue_growth is the percentage change in turnover between them
+-- (NULL when either is missing or the previous is not positive), compared as reported when both
+-- periods share a currency so exchange rate moves are not counted as growth; employee_count is
+-- the average number of employees the accounts report, NULL when they do not
+CREATE MATERIALIZED VIEW staging_latest_financials AS
+WITH periods AS (
+    SELECT
+        company_number,
+        period_end,
+        COALESCE(currency, 'GBP') as currency,
+        CASE WHEN COALESCE(currency, 'GBP') = 'GBP' THEN 1 ELSE gbp_rate END as gbp_rate,
+        turnover,
+        profit_loss,
+        total_assets,
+        total_liabilities,
+        net_assets_liabilities,
+        average_number_employees_during_period
+    FROM staging_financials
+    WHERE period_end IS NOT NULL
+),
+history AS (
+    SELECT
+        periods.*,
+        LAG(currency) OVER w as previous_currency,
+        LAG(turnover) OVER w as previous_turnover_original,
+        LAG(turnover * gbp_rate) OVER w as previous_turnover,
+        LAG(net_assets_liabilities * gbp_rate) OVER w as previous_net_worth
+    FROM periods
+    WINDOW w AS (PARTITION BY company_number ORDER BY period_end)
+)
+SELECT DISTINCT ON (company_number)
+    company_number,
+    ROUND(turnover * gbp_rate, 2) as turnover,
+    ROUND(profit_loss * gbp_rate, 2) as profit_after_tax,
+    ROUND(total_assets * gbp_rate, 2) as total_assets,
+    ROUND(total_liabilities * gbp_rate, 2) as total_liabilities,
+    ROUND(net_assets_liabilities * gbp_rate, 2) as net_worth,
+    0 as profit_margin,
+    0 as current_ratio,
+    period_end,
+    average_number_employees_during_period as employee_count,
+    ROUND(previous_turnover, 2) as previous_turnover,
+    ROUND(previous_net_worth, 2) as previous_net_worth,
+    CASE
+        WHEN previous_currency = currency AND previous_turnover_original > 0
+            THEN ROUND((turnover - previous_turnover_original) / previous_turnover_original * 100, 2)
+        WHEN previous_currency <> currency AND previous_turnover > 0
+            THEN ROUND((turnover * gbp_rate - previous_turnover) / previous_turnover * 100, 2)
+    END as revenue_growth,
+    currency,
+    gbp_rate,
+    turnover as turnover_original,
+    profit_loss as profit_after_tax_original,
+    total_assets as total_assets_original,
+    net_assets_liabilities as net_worth_original
+FROM history
+ORDER BY company_number, period_end DESC;
+
+-- Unique index is required for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX idx_staging_latest_financials_company ON staging_latest_financials(company_number);
+CREATE INDEX idx_staging_latest_financials_turnover ON staging_latest_financials(turnover);
+CREATE INDEX idx_staging_latest_financials_net_worth ON staging_latest_financials(net_worth);
+CREATE INDEX idx_staging_latest_financials_period ON staging_latest_financials(period_end);
+CREATE INDEX idx_staging_latest_financials_growth ON staging_latest_financials(revenue_growth);
+CREATE INDEX idx_staging_latest_financials_employees ON staging_latest_financials(employee_count);
+
+-- Comments
+COMMENT ON MATERIALIZED VIEW staging_latest_financials IS 'Most recent financial period per company, used by API search filters';
@@ -0,0 +1,127 @@
+-- =====================================================
+-- Balance sheet fields in search summaries
+-- (staging_latest_financials gains fixed_assets, intangible_assets and
+-- shareholder_funds, used by the API's balance sheet range filters)
+-- =====================================================
+-- Recreates staging_latest_financials as in 59_latest_financials_liquidity.sql, with the latest
+-- period's fixed and intangible assets and shareholder funds in GBP
+DROP MATERIALIZED VIEW IF EXISTS staging_latest_financials CASCADE;
+
+-- Figures are in GBP: periods reported in another currency are converted at gbp_rate, the rate
+-- on their period end, and are NULL until one is known. currency and the *_original columns
+-- keep the figures as reported. previous_turnover and previous_net_worth come from the period
+-- before the latest one; revenue_growth is the percentage change in turnover between them
+-- (NULL when either is missing or the previous is not positive), compared as reported when both
+-- periods share a currency so exchange rate moves are not counted as growth; employee_count is
+-- the average number of employees the accounts report, NULL when they do not. profitable_years
+-- and growth_years count the latest periods in a row with a profit after tax, and with turnover
+-- above the period before's (compared as revenue_growth is). current_liabilities is current assets
+-- less net current assets where both are reported, as most accounts only tag creditors due
+-- within one year by dimension, else the current liabilities tagged; current_ratio is current
+-- assets over current liabilities, NULL unless they are positive. fixed_assets, intangible_assets
+-- and shareholder_funds are the balance sheet totals as tagged
+CREATE MATERIALIZED VIEW staging_latest_financials AS
+WITH periods AS (
+    SELECT
+        company_number,
+        period_end,
+        COALESCE(currency, 'GBP') as currency,
+        CASE WHEN COALESCE(currency, 'GBP') = 'GBP' THEN 1 ELSE gbp_rate END as gbp_rate,
+        turnover,
+        profit_loss,
+        total_assets,
+        total_liabilities,
+        net_assets_liabilities,
+        fixed_assets,
+        intangible_assets,
+        shareholder_funds,
+        cash_bank_on_hand,
+        current_assets,
+        COALESCE(current_assets - net_current_assets_liabilities, current_liabilities) as current_liabilities,
+        average_number_employees_during_period
+    FROM staging_financials
+    WHERE period_end IS NOT NULL
+),
+history AS (
+    SELECT
+        periods.*,
+        LAG(currency) OVER w as previous_currency,
+        LAG(turnover) OVER w as previous_turnover_original,
+        LAG(turnover * gbp_rate) OVER w as previous_turnover,
+        LAG(net_assets_liabilities * gbp_rate) OVER w as previous_net_worth
+    FROM periods
+    WINDOW w AS (PARTITION BY company_number ORDER BY period_end)
+),
+streaks AS (
+    -- A period is in the latest run if no period since (or itself) broke it
+    SELECT
+        company_number,
+        COUNT(*) FILTER (WHERE unprofitable_since = 0) as profitable_years,
+        COUNT(*) FILTER (WHERE not_grown_since = 0) as growth_years
+    FROM (
+        SELECT
+            company_number,
+            COUNT(*) FILTER (WHERE profit_loss IS NULL OR profit_loss <= 0) OVER latest_first as unprofitable_since,
+            COUNT(*) FILTER (WHERE NOT COALESCE(CASE
+                WHEN previous_currency = currency THEN turnover > previous_turnover_original
+                ELSE turnover * gbp_rate > previous_turnover
+            END, false)) OVER latest_first as not_grown_since
+        FROM history
+        WINDOW latest_first AS (PARTITION BY company_number ORDER BY period_end DESC)
+    ) runs
+    GROUP BY company_number
+)
+SELECT DISTINCT ON (company_number)
+    company_number,
+    ROUND(turnover * gbp_rate, 2) as turnover,
+    ROUND(profit_loss * gbp_rate, 2) as profit_after_tax,
+    ROUND(total_assets * gbp_rate, 2) as total_assets,
+    ROUND(total_liabilities * gbp_rate, 2) as total_liabilities,
+    ROUND(net_assets_liabilities * gbp_rate, 2) as net_worth,
+    0 as profit_margin,
+    CASE WHEN current_liabilities > 0 THEN ROUND(current_assets / current_liabilities, 4) END as current_ratio,
+    period_end,
+    average_number_employees_during_period as employee_count,
+    ROUND(cash_bank_on_hand * gbp_rate, 2) as cash,
+    ROUND(current_assets * gbp_rate, 2) as current_assets,
+    ROUND(current_liabilities * gbp_rate, 2) as current_liabilities,
+    ROUND(fixed_assets * gbp_rate, 2) as fixed_assets,
+    ROUND(intangible_assets * gbp_rate, 2) as intangible_assets,
+    ROUND(shareholder_funds * gbp_rate, 2) as shareholder_funds,
+    ROUND(previous_turnover, 2) as previous_turnover,
+    ROUND(previous_net_worth, 2) as previous_net_worth,
+    CASE
+        WHEN previous_currency = currency AND previous_turnover_original > 0
+            THEN ROUND((turnover - previous_turnover_original) / previous_turnover_original * 100, 2)
+        WHEN previous_currency <> currency AND previous_turnover > 0
+            THEN ROUND((turnover * gbp_rate - previous_turnover) / previous_turnover * 100, 2)
+    END as revenue_growth,
+    currency,
+    gbp_rate,
+    turnover as turnover_original,
+    profit_loss as profit_after_tax_original,
+    total_assets as total_assets_original,
+    net_assets_liabilities as net_worth_original,
+    streaks.profitable_years,
+    streaks.growth_years
+FROM history
+JOIN streaks USING (company_number)
+ORDER BY company_number, period_end DESC;
+
+-- Unique index is required for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX idx_staging_latest_financials_company ON staging_latest_financials(company_number);
+CREATE INDEX idx_staging_latest_financials_turnover ON staging_latest_financials(turnover);
+CREATE INDEX idx_staging_latest_financials_net_worth ON staging_latest_financials(net_worth);
+CREATE INDEX idx_staging_latest_financials_period ON staging_latest_financials(period_end);
+CREATE INDEX idx_staging_latest_financials_growth ON staging_latest_financials(revenue_growth);
+CREATE INDEX idx_staging_latest_financials_employees ON staging_latest_financials(employee_count);
+CREATE INDEX idx_staging_latest_financials_cash ON staging_latest_financials(cash);
+CREATE INDEX idx_staging_latest_financials_current_ratio ON staging_latest_financials(current_ratio);
+CREATE INDEX idx_staging_latest_financials_fixed_assets ON staging_latest_financials(fixed_assets);
+CREATE INDEX idx_staging_latest_financials_intangible_assets ON staging_latest_financials(intangible_assets);
+CREATE INDEX idx_staging_latest_financials_shareholder_funds ON staging_latest_financials(shareholder_funds);
+CREATE INDEX idx_staging_latest_financials_profitable_years ON staging_latest_financials(profitable_years);
+CREATE INDEX idx_staging_latest_financials_growth_years ON staging_latest_financials(growth_years);
+
+-- Comments
+COMMENT ON MATERIALIZED VIEW staging_latest_financials IS 'Most recent financial period per company, used by API search filters';
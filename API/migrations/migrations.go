@@ -0,0 +1,177 @@
+// Package migrations versions the schema owned by the API. Each NN_name.sql file in this
+// directory is a migration, embedded in the binary and applied once in version order; applied
+// versions are recorded in schema_migrations. Migrations must stay safe to re-apply (IF NOT
+// EXISTS, OR REPLACE), since databases set up before versioning and the staging setup script
+// apply them without recording them.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/database"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockID is the advisory lock held while migrating, so concurrent runs apply each migration once
+const lockID = 7462164193
+
+// Migration is one schema change
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Applied is a migration recorded in schema_migrations
+type Applied struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+// All returns the embedded migrations in version order
+func All() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %s is not named NN_name.sql", entry.Name())
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+
+		sql, err := files.ReadFile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: strings.TrimSuffix(entry.Name(), ".sql"), SQL: string(sql)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ListApplied returns the migrations recorded in the database in version order, or none if
+// it has never been migrated
+func ListApplied(ctx context.Context, db *database.DB) ([]Applied, error) {
+	var exists bool
+	if err := db.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check schema_migrations: %w", err)
+	}
+	applied := make([]Applied, 0)
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := db.Query(ctx, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+// Pending returns the embedded migrations not yet applied to the database
+func Pending(ctx context.Context, db *database.DB) ([]Migration, error) {
+	migrations, err := All()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := ListApplied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[int]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	pending := make([]Migration, 0)
+	for _, m := range migrations {
+		if !done[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Apply applies the pending migrations in version order, each in its own transaction with its
+// schema_migrations record, and returns those it applied. It stops at the first that fails.
+func Apply(ctx context.Context, db *database.DB) ([]Migration, error) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	// Migrations can take longer than an API query is allowed to
+	if _, err := conn.Exec(ctx, "SET statement_timeout = 0"); err != nil {
+		return nil, err
+	}
+	defer conn.Exec(context.Background(), "RESET statement_timeout")
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return nil, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	_, err = conn.Exec(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	// Listed under the lock, so migrations applied by a concurrent run are skipped
+	pending, err := Pending(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]Migration, 0, len(pending))
+	for _, m := range pending {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return applied, fmt.Errorf("failed to begin migration %s: %w", m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, m.SQL); err != nil {
+			tx.Rollback(ctx)
+			return applied, fmt.Errorf("migration %s failed: %w", m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+			tx.Rollback(ctx)
+			return applied, fmt.Errorf("failed to record migration %s: %w", m.Name, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return applied, fmt.Errorf("failed to commit migration %s: %w", m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// SummaryRefreshResponse represents the API response for a search summary refresh
+type SummaryRefreshResponse struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// PoolStatsResponse represents database connection pool statistics
+type PoolStatsResponse struct {
+	MaxConns             int32 `json:"max_conns"`
+	TotalConns           int32 `json:"total_conns"`
+	IdleConns            int32 `json:"idle_conns"`
+	AcquiredConns        int32 `json:"acquired_conns"`
+	AcquireCount         int64 `json:"acquire_count"`
+	EmptyAcquireCount    int64 `json:"empty_acquire_count"`
+	CanceledAcquireCount int64 `json:"canceled_acquire_count"`
+	AcquireDurationMs    int64 `json:"acquire_duration_ms"`
+}
+
+// DataStatsResponse describes the size and freshness of the staging data
+type DataStatsResponse struct {
+	Tables             []TableStats       `json:"tables"`
+	Indexes            []IndexStats       `json:"indexes"`
+	LatestBatch        *IngestionBatch    `json:"latest_batch"`
+	FinancialsCoverage FinancialsCoverage `json:"financials_coverage"`
+}
+
+// TableStats describes a staging table. RowCount is the planner's live row estimate.
+type TableStats struct {
+	Name             string     `json:"name"`
+	RowCount         int64      `json:"row_count"`
+	TotalBytes       int64      `json:"total_bytes"`
+	LatestIngestedAt *time.Time `json:"latest_ingested_at"`
+}
+
+// IndexStats describes an index on a staging table
+type IndexStats struct {
+	Table string `json:"table"`
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+}
+
+// IngestionBatch is the most recently started ingestion batch
+type IngestionBatch struct {
+	BatchID        string     `json:"batch_id"`
+	SearchName     *string    `json:"search_name"`
+	Status         string     `json:"status"`
+	CompaniesCount int        `json:"companies_count"`
+	StartedAt      time.Time  `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+}
+
+// FinancialsCoverage is how many companies have at least one set of accounts
+type FinancialsCoverage struct {
+	Companies      int64   `json:"companies"`
+	WithFinancials int64   `json:"with_financials"`
+	Percent        float64 `json:"percent"`
+}
+
+// DuplicateCompaniesResponse lists companies staged under several forms of their number
+type DuplicateCompaniesResponse struct {
+	Groups      []DuplicateCompanyGroup `json:"groups"`
+	TotalGroups int                     `json:"total_groups"`
+}
+
+// DuplicateCompanyGroup is the staged rows of one company. Search returns only the kept row.
+type DuplicateCompanyGroup struct {
+	CompanyNumber string             `json:"company_number"`
+	Rows          []DuplicateCompany `json:"rows"`
+}
+
+// DuplicateCompany is one staged row of a duplicated company
+type DuplicateCompany struct {
+	CompanyNumber string     `json:"company_number"`
+	CompanyName   *string    `json:"company_name"`
+	CompanyStatus *string    `json:"company_status"`
+	LastUpdated   *time.Time `json:"last_updated"`
+	BatchID       *string    `json:"batch_id"`
+	Kept          bool       `json:"kept"`
+}
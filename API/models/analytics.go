@@ -0,0 +1,105 @@
+package models
+
+// TopCompaniesRequest represents the request body for a league table of the top companies per
+// industry or region
+type TopCompaniesRequest struct {
+	GroupBy string               `json:"group_by"` // "industry" or "region"
+	Metric  string               `json:"metric"`   // "turnover", "net_worth" or "growth"
+	N       int                  `json:"n"`
+	Filters CompanySearchFilters `json:"filters"`
+}
+
+// TopCompaniesResponse represents the API response for a league table. Groups are ordered by
+// how many companies they rank, largest first.
+type TopCompaniesResponse struct {
+	GroupBy         string     `json:"group_by"`
+	Metric          string     `json:"metric"`
+	N               int        `json:"n"`
+	Groups          []TopGroup `json:"groups"`
+	GroupsTruncated bool       `json:"groups_truncated"`
+}
+
+// TopGroup is one industry or region of a league table
+type TopGroup struct {
+	Name      string       `json:"name"`
+	Ranked    int          `json:"ranked"` // Companies in the group with a value for the metric
+	Companies []TopCompany `json:"companies"`
+}
+
+// TopCompany is a company's place in its group
+type TopCompany struct {
+	Rank          int     `json:"rank"`
+	CompanyNumber string  `json:"company_number"`
+	CompanyName   string  `json:"company_name"`
+	CompanyStatus string  `json:"company_status"`
+	Locality      *string `json:"locality"`
+	Value         float64 `json:"value"`
+}
+
+// AggregateRequest represents the request body for grouped statistics of the companies
+// matching filters
+type AggregateRequest struct {
+	GroupBy []string             `json:"group_by"` // Any of "industry", "region", "size_band", "status"
+	Filters CompanySearchFilters `json:"filters"`
+}
+
+// AggregateResponse represents the API response for grouped statistics. Groups are ordered by
+// company count, largest first.
+type AggregateResponse struct {
+	GroupBy     []string         `json:"group_by"`
+	Groups      []AggregateGroup `json:"groups"`
+	TotalGroups int              `json:"total_groups"`
+}
+
+// AggregateGroup summarises the latest financials of one group of companies. The averages and
+// median are of the companies reporting the figure, and are null when none do.
+type AggregateGroup struct {
+	Keys           map[string]*string `json:"keys"` // Dimension values, null for companies without one
+	Companies      int64              `json:"companies"`
+	TurnoverSum    *float64           `json:"turnover_sum"`
+	TurnoverAvg    *float64           `json:"turnover_avg"`
+	NetWorthMedian *float64           `json:"net_worth_median"`
+}
+
+// HistogramRequest represents the request body for the distribution of a figure across the
+// companies matching filters
+type HistogramRequest struct {
+	Field   string               `json:"field"` // "turnover", "total_assets" or "company_age"
+	Filters CompanySearchFilters `json:"filters"`
+}
+
+// HistogramResponse represents the API response for a distribution
+type HistogramResponse struct {
+	Field   string            `json:"field"`
+	Scale   string            `json:"scale"` // "log" or "linear"
+	Buckets []HistogramBucket `json:"buckets"`
+	Missing int64             `json:"missing"` // Companies with no value for the field
+}
+
+// HistogramBucket counts the companies with a value from Min up to, but not including, Max
+type HistogramBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int64   `json:"count"`
+}
+
+// IncorporationTrendResponse represents the API response for incorporations and dissolutions
+// over time
+type IncorporationTrendResponse struct {
+	GroupBy        string        `json:"group_by"`
+	From           string        `json:"from"`
+	To             string        `json:"to"`
+	SIC            string        `json:"sic,omitempty"`
+	Industry       string        `json:"industry,omitempty"`
+	Location       string        `json:"location,omitempty"`
+	Periods        []TrendPeriod `json:"periods"`
+	Incorporations int64         `json:"incorporations"` // Totals over the range
+	Dissolutions   int64         `json:"dissolutions"`
+}
+
+// TrendPeriod counts the companies incorporated and dissolved in the period starting Period
+type TrendPeriod struct {
+	Period         string `json:"period"` // YYYY-MM-DD
+	Incorporations int64  `json:"incorporations"`
+	Dissolutions   int64  `json:"dissolutions"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey represents an issued API key (the plaintext key is never stored)
+type APIKey struct {
+	ID                  int        `json:"id"`
+	Name                string     `json:"name"`
+	KeyPrefix           string     `json:"key_prefix"`
+	Role                string     `json:"role"`
+	Tier                string     `json:"tier"`
+	OrganizationID      *int       `json:"organization_id"`
+	MonthlyRequestQuota *int64     `json:"monthly_request_quota"`
+	MonthlyRowQuota     *int64     `json:"monthly_row_quota"`
+	CreatedAt           time.Time  `json:"created_at"`
+	LastUsedAt          *time.Time `json:"last_used_at"`
+	RevokedAt           *time.Time `json:"revoked_at"`
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"` // reader (default), exporter or admin
+	Tier string `json:"tier"` // rate limit tier, "standard" by default
+
+	// Organization the key acts for; an organization admin's keys always belong to theirs
+	OrganizationID *int `json:"organization_id"`
+
+	// Monthly quotas; omitted or null means unlimited, or the organization's quotas for keys
+	// issued by an organization admin
+	MonthlyRequestQuota *int64 `json:"monthly_request_quota"`
+	MonthlyRowQuota     *int64 `json:"monthly_row_quota"`
+}
+
+// CreateAPIKeyResponse returns the new key; Key is only ever shown in this response
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyListResponse represents the API response for listing keys
+type APIKeyListResponse struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// UsagePeriod represents an API key's usage in one calendar month
+type UsagePeriod struct {
+	Period       string `json:"period"` // YYYY-MM
+	RequestCount int64  `json:"request_count"`
+	RowCount     int64  `json:"row_count"`
+}
+
+// UsageResponse represents the API response for the usage endpoint
+type UsageResponse struct {
+	KeyID               int           `json:"key_id"`
+	Current             UsagePeriod   `json:"current"`
+	MonthlyRequestQuota *int64        `json:"monthly_request_quota"`
+	MonthlyRowQuota     *int64        `json:"monthly_row_quota"`
+	History             []UsagePeriod `json:"history"`
+	// Credits of the key's tenant, when contact enrichment is enabled
+	ContactCredits *ContactCredits `json:"contact_credits,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// BatchRequest represents the request body for fetching companies in bulk
+type BatchRequest struct {
+	CompanyNumbers []string `json:"company_numbers"`
+}
+
+// BatchResponse represents the API response for a batch fetch. Companies are in request order;
+// NotFound lists the requested numbers that do not exist, also in request order.
+type BatchResponse struct {
+	Companies []Company `json:"companies"`
+	NotFound  []string  `json:"not_found"`
+}
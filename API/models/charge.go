@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Charge represents a charge (mortgage or other security) registered against a company
+type Charge struct {
+	ID              string     `json:"id"`
+	ChargeCode      *string    `json:"charge_code"`
+	ChargeNumber    *int       `json:"charge_number"`
+	Classification  *string    `json:"classification"`
+	Status          *string    `json:"status"` // outstanding, part-satisfied, fully-satisfied or satisfied
+	Outstanding     bool       `json:"outstanding"`
+	CreatedOn       *time.Time `json:"created_on"`
+	DeliveredOn     *time.Time `json:"delivered_on"`
+	SatisfiedOn     *time.Time `json:"satisfied_on"`
+	PersonsEntitled []string   `json:"persons_entitled"`
+	Particulars     *string    `json:"particulars"`
+}
+
+// ChargeListResponse represents the API response for a company's charges
+type ChargeListResponse struct {
+	CompanyNumber    string   `json:"company_number"`
+	OutstandingCount int      `json:"outstanding_count"`
+	Charges          []Charge `json:"charges"`
+}
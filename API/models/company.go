@@ -2,59 +2,200 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
-// Company represents a company record from the database
+// Company represents a company record from the database. Fields tagged db are selected in
+// searches as the result column of that name.
 type Company struct {
-	ID                   int             `json:"id"`
-	CompanyNumber        string          `json:"company_number"`
-	CompanyName          string          `json:"company_name"`
-	CompanyStatus        string          `json:"company_status"`
-	Locality             sql.NullString  `json:"locality"`
-	Region               sql.NullString  `json:"region"`
-	PostalCode           sql.NullString  `json:"postal_code"`
-	PrimarySICCode       sql.NullString  `json:"primary_sic_code"`
-	IndustryCategory     sql.NullString  `json:"industry_category"`
-	IncorporationDate    *time.Time      `json:"incorporation_date"`
-	Turnover             sql.NullFloat64 `json:"turnover"`
-	ProfitAfterTax       sql.NullFloat64 `json:"profit_after_tax"`
-	TotalAssets          sql.NullFloat64 `json:"total_assets"`
-	NetWorth             sql.NullFloat64 `json:"net_worth"`
-	ProfitMargin         sql.NullFloat64 `json:"profit_margin"`
-	LatestAccountsDate   *time.Time      `json:"latest_accounts_date"`
-	ActiveOfficersCount  int             `json:"active_officers_count"`
+	CompanyNumber       string             `json:"company_number" db:"company_number"`
+	CompanyName         string             `json:"company_name" db:"company_name"`
+	CompanyStatus       string             `json:"company_status" db:"company_status"`
+	CompanyType         sql.NullString     `json:"company_type" db:"company_type"` // As published, e.g. "Private Limited Company"
+	Locality            sql.NullString     `json:"locality" db:"locality"`
+	Region              sql.NullString     `json:"region" db:"region"`   // County, as in the address
+	Country             sql.NullString     `json:"country" db:"country"` // UK country the registered office is in, e.g. "Scotland"
+	UKRegion            sql.NullString     `json:"uk_region" db:"uk_region"`
+	PostalCode          sql.NullString     `json:"postal_code" db:"postal_code"`
+	Latitude            sql.NullFloat64    `json:"latitude" db:"latitude"`   // Of the postcode, when geocoded
+	Longitude           sql.NullFloat64    `json:"longitude" db:"longitude"` // Of the postcode, when geocoded
+	PrimarySICCode      sql.NullString     `json:"primary_sic_code" db:"primary_sic_code"`
+	IndustryCategory    sql.NullString     `json:"industry_category" db:"industry_category"`
+	IncorporationDate   *time.Time         `json:"incorporation_date" db:"incorporation_date"`
+	DissolvedOn         *time.Time         `json:"dissolved_on" db:"dissolved_on"`
+	Turnover            sql.NullFloat64    `json:"turnover" db:"turnover"`
+	ProfitAfterTax      sql.NullFloat64    `json:"profit_after_tax" db:"profit_after_tax"`
+	TotalAssets         sql.NullFloat64    `json:"total_assets" db:"total_assets"`
+	NetWorth            sql.NullFloat64    `json:"net_worth" db:"net_worth"`
+	ProfitMargin        sql.NullFloat64    `json:"profit_margin" db:"profit_margin"`
+	Cash                sql.NullFloat64    `json:"cash" db:"cash"` // Cash at bank and in hand
+	CurrentAssets       sql.NullFloat64    `json:"current_assets" db:"current_assets"`
+	CurrentLiabilities  sql.NullFloat64    `json:"current_liabilities" db:"current_liabilities"` // Creditors due within one year
+	CurrentRatio        sql.NullFloat64    `json:"current_ratio" db:"current_ratio"`             // Current assets over current liabilities
+	FixedAssets         sql.NullFloat64    `json:"fixed_assets" db:"fixed_assets"`
+	IntangibleAssets    sql.NullFloat64    `json:"intangible_assets" db:"intangible_assets"` // Part of FixedAssets
+	ShareholderFunds    sql.NullFloat64    `json:"shareholder_funds" db:"shareholder_funds"` // Total equity, as reported on the balance sheet
+	AccountsCurrency    sql.NullString     `json:"accounts_currency" db:"accounts_currency"` // Reporting currency of the latest accounts; the figures above are converted to GBP
+	TurnoverOriginal    sql.NullFloat64    `json:"turnover_original" db:"turnover_original"` // Figures as reported, in AccountsCurrency
+	ProfitOriginal      sql.NullFloat64    `json:"profit_after_tax_original" db:"profit_after_tax_original"`
+	TotalAssetsOriginal sql.NullFloat64    `json:"total_assets_original" db:"total_assets_original"`
+	NetWorthOriginal    sql.NullFloat64    `json:"net_worth_original" db:"net_worth_original"`
+	LatestAccountsDate  *time.Time         `json:"latest_accounts_date" db:"latest_accounts_date"`
+	AccountsCategory    sql.NullString     `json:"accounts_category" db:"accounts_category"` // Type of the last accounts, as published, e.g. "MICRO ENTITY"
+	Dormant             bool               `json:"dormant" db:"dormant"`                     // Filed dormant accounts, declares itself dormant or shows no trading
+	NextAccountsDue     *time.Time         `json:"next_accounts_due" db:"next_accounts_due"`
+	ConfStmtLastMadeUp  *time.Time         `json:"confirmation_statement_last_made_up_to" db:"confirmation_statement_last_made_up_to"`
+	ConfStmtNextDue     *time.Time         `json:"confirmation_statement_next_due" db:"confirmation_statement_next_due"`
+	ActiveOfficersCount int                `json:"active_officers_count" db:"active_officers_count"`
+	EmployeeCount       sql.NullInt64      `json:"employee_count" db:"employee_count"`     // Average over the latest accounts' period, as they report it
+	ProfitableYears     sql.NullInt64      `json:"profitable_years" db:"profitable_years"` // Latest financial periods in a row with a profit after tax
+	GrowthYears         sql.NullInt64      `json:"growth_years" db:"growth_years"`         // Latest financial periods in a row with turnover above the period before's
+	HealthScore         sql.NullFloat64    `json:"health_score" db:"health_score"`
+	Health              sql.NullString     `json:"health" db:"health"`       // "strong", "moderate" or "weak"
+	RiskBand            sql.NullString     `json:"risk_band" db:"risk_band"` // "low", "medium" or "high"
+	RiskFlags           []string           `json:"risk_flags" db:"risk_flags"`
+	VATNumber           sql.NullString     `json:"vat_number" db:"vat_number"`         // Without the GB prefix, from a bulk lookup
+	VATRegistered       *bool              `json:"vat_registered" db:"vat_registered"` // As last checked with HMRC; null until checked
+	Domain              sql.NullString     `json:"domain" db:"domain"`                 // Of the company's website, when one was matched
+	Insolvency          *InsolvencySummary `json:"insolvency,omitempty"`               // Company detail only
+	MatchedOn           string             `json:"matched_on,omitempty"`               // "name" or "previous_name", when searching by searchTerm
+	Score               *float64           `json:"score,omitempty"`                    // From 0 to 1, when searching with score_weights
+	AsOf                *time.Time         `json:"as_of,omitempty"`                    // Date the company was reconstructed at, when asked for as_of
+	Source              string             `json:"source,omitempty"`                   // "companies_house" when fetched live, missing from our database
 }
 
 // CompanySearchFilters represents the filter criteria from frontend
 type CompanySearchFilters struct {
-	Industry       string `json:"industry"`
-	Location       string `json:"location"`
-	Revenue        string `json:"revenue"`
-	Employees      string `json:"employees"`
-	Profitability  string `json:"profitability"`
-	CompanySize    string `json:"companySize"`
-	CompanyStatus  string `json:"companyStatus"`
-	NetAssets      string `json:"netAssets"`
-	DebtLevel      string `json:"debtLevel"`
-	SearchTerm     string `json:"searchTerm"`
-	Limit          int    `json:"limit"`
-	Offset         int    `json:"offset"`
-	OrderBy        string `json:"orderBy"`
+	Industry              string                 `json:"industry"`
+	SICSection            string                 `json:"sic_section"`  // SIC 2007 section letter, e.g. "J"; comma-separated for several
+	SICDivision           string                 `json:"sic_division"` // 2-digit SIC division, e.g. "62"; comma-separated for several
+	SICClass              string                 `json:"sic_class"`    // 4-digit class or 5-digit code, e.g. "6202" or "62020"; comma-separated for several
+	Location              string                 `json:"location"`
+	Country               string                 `json:"country"`   // e.g. "scotland"; comma-separated for several
+	UKRegion              string                 `json:"uk_region"` // e.g. "north-west"; comma-separated for several
+	Locality              string                 `json:"locality"`  // Post town, e.g. "Salford"; comma-separated for several
+	Revenue               string                 `json:"revenue"`
+	Employees             string                 `json:"employees"`      // Deprecated alias of OfficerCount
+	EmployeeCount         string                 `json:"employee_count"` // Reported in the latest accounts, e.g. "11-50"
+	OfficerCount          string                 `json:"officer_count"`  // Active officers, e.g. "1-10"; a proxy for companies that report no employees
+	Profitability         string                 `json:"profitability"`
+	ProfitableYears       *int                   `json:"profitable_years"`  // Profitable in each of the latest N financial periods, 1 to 10
+	ConsistentGrowth      *bool                  `json:"consistent_growth"` // Turnover grew in each of the latest three financial periods
+	CashMin               *float64               `json:"cash_min"`          // Cash at bank in the latest accounts, in GBP
+	CurrentRatioMin       *float64               `json:"current_ratio_min"` // Current assets over current liabilities in the latest accounts, e.g. 1.5
+	FixedAssetsMin        *float64               `json:"fixed_assets_min"`  // Ranges over the latest accounts, in GBP; either end may be left out
+	FixedAssetsMax        *float64               `json:"fixed_assets_max"`
+	IntangibleAssetsMin   *float64               `json:"intangible_assets_min"`
+	IntangibleAssetsMax   *float64               `json:"intangible_assets_max"`
+	ShareholderFundsMin   *float64               `json:"shareholder_funds_min"`
+	ShareholderFundsMax   *float64               `json:"shareholder_funds_max"`
+	CompanySize           string                 `json:"companySize"`
+	CompanyAge            string                 `json:"companyAge"` // Years since incorporation, e.g. "3-5"
+	CompanyStatus         string                 `json:"companyStatus"`
+	NetAssets             string                 `json:"netAssets"`
+	DebtLevel             string                 `json:"debtLevel"`
+	SearchTerm            string                 `json:"searchTerm"`
+	RevenueGrowth         string                 `json:"revenue_growth"` // YoY turnover growth, e.g. "20+" or "declining"
+	Health                string                 `json:"health"`         // "strong", "moderate" or "weak"
+	RiskBand              string                 `json:"risk_band"`      // "low", "medium" or "high"
+	PSCType               string                 `json:"psc_type"`       // "individual", "corporate" or "none_declared"
+	HasOutstandingCharges *bool                  `json:"has_outstanding_charges"`
+	InAdministration      *bool                  `json:"in_administration"`
+	InLiquidation         *bool                  `json:"in_liquidation"`
+	HasInsolvencyHistory  *bool                  `json:"has_insolvency_history"`
+	PostcodeArea          string                 `json:"postcode_area"`     // e.g. "EC" or "M"; comma-separated for several
+	PostcodeDistrict      string                 `json:"postcode_district"` // e.g. "EC1V"; comma-separated for several
+	AddressContains       string                 `json:"address_contains"`  // Text anywhere in the registered office address
+	CompanyType           string                 `json:"company_type"`      // e.g. "ltd" or "llp"; comma-separated for several
+	AccountsCategory      string                 `json:"accounts_category"` // e.g. "small,full", or "!micro-entity" to exclude
+	AccountsOverdue       *bool                  `json:"accounts_overdue"`
+	AccountsDueWithinDays *int                   `json:"accounts_due_within_days"` // Next accounts due in the next N days, at most 366
+	ConfStmtOverdue       *bool                  `json:"confirmation_statement_overdue"`
+	DissolvedFrom         string                 `json:"dissolved_from"` // YYYY-MM-DD, inclusive
+	DissolvedTo           string                 `json:"dissolved_to"`   // YYYY-MM-DD, inclusive
+	VATRegistered         *bool                  `json:"vat_registered"`
+	HasWebsite            *bool                  `json:"has_website"`
+	ExcludeDormant        bool                   `json:"exclude_dormant"` // Leave out companies whose dormant result field is true
+	Near                  *NearFilter            `json:"near"`
+	Where                 []WhereClause          `json:"where"`  // Each clause must match
+	And                   []CompanySearchFilters `json:"and"`    // Each entry must match
+	Or                    []CompanySearchFilters `json:"or"`     // At least one entry must match
+	Fields                []string               `json:"fields"` // Result fields to return, e.g. ["company_number", "turnover"]; all when empty
+	Limit                 int                    `json:"limit"`
+	Offset                int                    `json:"offset"`
+	Sample                int                    `json:"sample"`      // Return a uniform random sample of this many companies instead of a page
+	SampleSeed            *int64                 `json:"sample_seed"` // The same seed returns the same sample; random when unset
+	OrderBy               string                 `json:"orderBy"`
+	CountMode             string                 `json:"count_mode"`    // "exact" (default) or "estimate"
+	IncludeTotal          *bool                  `json:"include_total"` // Default true; false skips counting, leaving out total and total_pages
+	Debug                 bool                   `json:"debug"`         // Explain the search in the response; admins only
+	ScoreWeights          map[string]float64     `json:"score_weights"` // Rank by a weighted score of these components, e.g. {"turnover": 0.5, "growth": 0.5}
+}
+
+// WhereClause compares a field of a company with a value, e.g. {"field": "turnover", "op":
+// "gte", "value": 5000000}. Fields and the ops each accepts are whitelisted.
+type WhereClause struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value any    `json:"value"`
+}
+
+// NearFilter restricts a search to companies within RadiusKm of a postcode, or of a point
+// given by Latitude and Longitude
+type NearFilter struct {
+	Postcode  string   `json:"postcode"`
+	Latitude  *float64 `json:"lat"`
+	Longitude *float64 `json:"lng"`
+	RadiusKm  float64  `json:"radius_km"` // Default 10, max 500
 }
 
 // SearchResponse represents the API response for company search
 type SearchResponse struct {
-	Companies  []Company `json:"companies"`
-	Total      int       `json:"total"`
-	Limit      int       `json:"limit"`
-	Offset     int       `json:"offset"`
-	HasMore    bool      `json:"has_more"`
+	Companies       []Company    `json:"companies"`
+	Total           *int         `json:"total,omitempty"` // Left out when include_total is false
+	Limit           int          `json:"limit"`
+	Offset          int          `json:"offset"`
+	HasMore         bool         `json:"has_more"`
+	TotalIsEstimate bool         `json:"total_is_estimate"`
+	Page            int          `json:"page"`                  // 1-based page of limit companies that offset falls in
+	TotalPages      *int         `json:"total_pages,omitempty"` // An estimate too when total is
+	Links           PageLinks    `json:"links"`
+	Debug           *SearchDebug `json:"debug,omitempty"`
+}
+
+// PageLinks locate the neighbouring pages of a search. Each is the request URL with offset and
+// limit set in its query string, to be sent the same request body; it is empty on the first or
+// last page.
+type PageLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// SearchDebug explains how a search ran: the filters it applied after defaults, the SQL and
+// parameters of its queries, and how long each step took
+type SearchDebug struct {
+	AppliedFilters map[string]any `json:"applied_filters"`
+	SQL            string         `json:"sql"`
+	Args           []any          `json:"args"`
+	CountSQL       string         `json:"count_sql,omitempty"` // Empty for estimated totals
+	CountArgs      []any          `json:"count_args,omitempty"`
+	QueryMs        int64          `json:"query_ms"` // Until the first rows arrived
+	ScanMs         int64          `json:"scan_ms"`  // Reading the rest of the rows
+	CountMs        int64          `json:"count_ms"`
+}
+
+// SparseSearchResponse is the search response when fields are selected, with only those
+// fields in each company
+type SparseSearchResponse struct {
+	SearchResponse
+	Companies []map[string]json.RawMessage `json:"companies"`
 }
 
 // CountResponse represents the API response for count endpoint
 type CountResponse struct {
-	Total int `json:"total"`
+	Total           int  `json:"total"`
+	TotalIsEstimate bool `json:"total_is_estimate"`
 }
 
 // ErrorResponse represents an error response
@@ -62,3 +203,19 @@ type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 }
+
+// InvalidFilter describes a search filter that was rejected. Field is the filter's JSON name,
+// prefixed with its path inside filter groups, e.g. "or[1].revenue".
+type InvalidFilter struct {
+	Field    string   `json:"field"`
+	Value    any      `json:"value,omitempty"`
+	Reason   string   `json:"reason"`
+	Accepted []string `json:"accepted,omitempty"` // The values the filter accepts, when it takes a fixed set
+}
+
+// FilterErrorResponse is the error response of a search with invalid filters
+type FilterErrorResponse struct {
+	Error          string          `json:"error"`
+	Message        string          `json:"message"`
+	InvalidFilters []InvalidFilter `json:"invalid_filters"`
+}
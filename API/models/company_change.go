@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// CompanyFieldChange is one change to a field of a company, in its history
+type CompanyFieldChange struct {
+	ID        int64     `json:"id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  *string   `json:"new_value"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// CompanyChangesResponse represents the API response for a company's change history
+type CompanyChangesResponse struct {
+	CompanyNumber string               `json:"company_number"`
+	Since         *time.Time           `json:"since"`
+	Changes       []CompanyFieldChange `json:"changes"`
+}
+
+// ChangedCompany is a company with data written since a change feed's since time
+type ChangedCompany struct {
+	CompanyNumber string    `json:"company_number"`
+	ChangeTypes   []string  `json:"change_types"` // "company", "financials", "officers", "pscs", "charges" or "insolvency"
+	ChangedAt     time.Time `json:"changed_at"`   // Latest write
+}
+
+// ChangedCompaniesResponse represents the API response for a page of the change feed
+type ChangedCompaniesResponse struct {
+	Since      time.Time        `json:"since"`
+	Companies  []ChangedCompany `json:"companies"`
+	HasMore    bool             `json:"has_more"`
+	NextCursor string           `json:"next_cursor,omitempty"` // Pass as cursor for the next page
+}
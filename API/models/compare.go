@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// CompareRequest represents the request body for comparing companies
+type CompareRequest struct {
+	CompanyNumbers []string `json:"company_numbers"`
+}
+
+// CompanyComparison holds one company's metrics in a comparison
+type CompanyComparison struct {
+	CompanyNumber      string     `json:"company_number"`
+	CompanyName        string     `json:"company_name"`
+	CompanyStatus      string     `json:"company_status"`
+	IncorporationDate  *time.Time `json:"incorporation_date"`
+	AgeYears           *int       `json:"age_years"`
+	LatestAccountsDate *time.Time `json:"latest_accounts_date"`
+	Turnover           *float64   `json:"turnover"`
+	ProfitAfterTax     *float64   `json:"profit_after_tax"`
+	TotalAssets        *float64   `json:"total_assets"`
+	NetWorth           *float64   `json:"net_worth"`
+	Cash               *float64   `json:"cash"`
+	CurrentRatio       *float64   `json:"current_ratio"`
+	AccountsCurrency   *string    `json:"accounts_currency"` // Reporting currency; the figures are in GBP
+	EmployeeCount      *int       `json:"employee_count"`
+	ActiveOfficers     int        `json:"active_officers"`
+}
+
+// CompareResponse represents the API response for a company comparison. Companies are in the
+// requested order; requested numbers that do not exist are listed in not_found.
+type CompareResponse struct {
+	Companies []CompanyComparison `json:"companies"`
+	NotFound  []string            `json:"not_found"`
+}
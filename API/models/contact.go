@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ContactEmail represents an email address found for a company
+type ContactEmail struct {
+	Address    string `json:"address"`
+	Type       string `json:"type,omitempty"` // "personal" for a named person, "generic" for e.g. info@
+	Name       string `json:"name,omitempty"`
+	Position   string `json:"position,omitempty"`
+	Confidence *int   `json:"confidence,omitempty"` // The provider's, from 0 to 100
+}
+
+// ContactPhone represents a phone number found for a company
+type ContactPhone struct {
+	Number string `json:"number"`
+	Name   string `json:"name,omitempty"` // Person the number reaches, if known
+}
+
+// Contacts represents the email addresses and phone numbers a contact provider found
+type Contacts struct {
+	Emails []ContactEmail `json:"emails"`
+	Phones []ContactPhone `json:"phones"`
+}
+
+// CompanyContacts represents the contacts a tenant enriched a company with
+type CompanyContacts struct {
+	CompanyNumber string `json:"company_number"`
+	Contacts
+	Source     string    `json:"source"`
+	EnrichedAt time.Time `json:"enriched_at"`
+}
+
+// ContactCredits represents a tenant's contact enrichment credits in the current month
+type ContactCredits struct {
+	Period    string `json:"period"` // YYYY-MM
+	Used      int    `json:"used"`
+	Allowance *int   `json:"allowance"` // nil = unlimited
+	Remaining *int   `json:"remaining"`
+}
+
+// EnrichContactsResponse represents the API response for enriching a company's contacts
+type EnrichContactsResponse struct {
+	CompanyContacts
+	CreditsCharged int            `json:"credits_charged"` // 0 when stored contacts were returned or none were found
+	Credits        ContactCredits `json:"credits"`
+}
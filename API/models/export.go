@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// Export file formats
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// ExportCompleted is the status of an export whose file can be downloaded
+const ExportCompleted = "completed"
+
+// CreateExportRequest represents the request body for starting an export
+type CreateExportRequest struct {
+	Filters CompanySearchFilters `json:"filters"` // As for search; limit and offset are ignored
+	Format  string               `json:"format"`  // "csv" (default) or "ndjson"
+	Email   *ExportEmail         `json:"email"`   // Email the finished export
+}
+
+// ExportEmail describes who a finished export is emailed to
+type ExportEmail struct {
+	To     []string `json:"to"`
+	Attach bool     `json:"attach"` // Attach the file rather than linking to it; files over the limit are linked
+}
+
+// ExportJob represents a background export and its progress
+type ExportJob struct {
+	ID          int64      `json:"id"`
+	Status      string     `json:"status"` // "queued", "running", "completed", "failed" or "expired"
+	Format      string     `json:"format"`
+	RowsWritten int        `json:"rows_written"`
+	TotalRows   *int       `json:"total_rows"` // Matching companies, once counted
+	Progress    *float64   `json:"progress"`   // RowsWritten / TotalRows, from 0 to 1
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"` // Set once completed
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"` // When the file is deleted
+
+	Email       *ExportEmail `json:"email,omitempty"`
+	EmailSentAt *time.Time   `json:"email_sent_at,omitempty"`
+	EmailError  string       `json:"email_error,omitempty"` // Why the email could not be sent
+}
+
+// ExportListResponse represents the API response for listing exports
+type ExportListResponse struct {
+	Exports []ExportJob `json:"exports"`
+}
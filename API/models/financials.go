@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// FinancialPeriod is the headline figures from one set of accounts, in the currency they report
+type FinancialPeriod struct {
+	PeriodStart        *time.Time `json:"period_start"`
+	PeriodEnd          time.Time  `json:"period_end"`
+	Currency           string     `json:"currency"` // ISO 4217, e.g. "GBP"
+	GBPRate            *float64   `json:"gbp_rate"` // GBP value of one unit of Currency at PeriodEnd; null until known
+	Turnover           *float64   `json:"turnover"`
+	GrossProfit        *float64   `json:"gross_profit"`
+	OperatingProfit    *float64   `json:"operating_profit"`
+	ProfitAfterTax     *float64   `json:"profit_after_tax"`
+	TotalAssets        *float64   `json:"total_assets"`
+	FixedAssets        *float64   `json:"fixed_assets"`
+	IntangibleAssets   *float64   `json:"intangible_assets"`
+	CurrentAssets      *float64   `json:"current_assets"`
+	CurrentLiabilities *float64   `json:"current_liabilities"` // Current assets less net current assets where both are reported
+	TotalLiabilities   *float64   `json:"total_liabilities"`
+	NetWorth           *float64   `json:"net_worth"`
+	ShareholderFunds   *float64   `json:"shareholder_funds"`
+	Cash               *float64   `json:"cash"`
+	Employees          *int       `json:"employees"`
+}
+
+// Filing is a set of accounts filed at Companies House and ingested into staging
+type Filing struct {
+	Category   string     `json:"category"` // Always "accounts"; other filing types are not ingested
+	MadeUpTo   time.Time  `json:"made_up_to"`
+	Title      *string    `json:"title"`
+	Source     *string    `json:"source"` // xbrl, ixbrl, ocr, api or bulk_xbrl
+	IngestedAt *time.Time `json:"ingested_at"`
+}
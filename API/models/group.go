@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// GroupNode is a company in a corporate group, with the companies it controls. NaturesOfControl
+// are how its parent controls it, as declared in its PSC register; empty for the group's top.
+type GroupNode struct {
+	CompanyNumber      string       `json:"company_number"`
+	CompanyName        *string      `json:"company_name"` // null for parents not in the database
+	CompanyStatus      *string      `json:"company_status"`
+	NaturesOfControl   []string     `json:"natures_of_control"`
+	Turnover           *float64     `json:"turnover"`
+	LatestAccountsDate *time.Time   `json:"latest_accounts_date"`
+	Subsidiaries       []*GroupNode `json:"subsidiaries"`
+}
+
+// GroupResponse represents the API response for a company's corporate group: the ownership
+// tree from its ultimate parent down, and the group's turnover summed over its companies.
+// Truncated is set when the group has more companies than were returned.
+type GroupResponse struct {
+	CompanyNumber         string     `json:"company_number"`
+	UltimateParent        string     `json:"ultimate_parent"`
+	CompanyCount          int        `json:"company_count"`
+	GroupTurnover         *float64   `json:"group_turnover"` // null when no company has reported turnover
+	CompaniesWithTurnover int        `json:"companies_with_turnover"`
+	Truncated             bool       `json:"truncated"`
+	Tree                  *GroupNode `json:"tree"`
+}
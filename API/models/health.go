@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// HealthResponse represents the API response for a health check. Database is only reported
+// by deep checks.
+type HealthResponse struct {
+	Status   string          `json:"status"`
+	Service  string          `json:"service"`
+	Database *DatabaseHealth `json:"database,omitempty"`
+}
+
+// DatabaseHealth is the outcome of a database round-trip, with pool utilization and data
+// freshness when the database is reachable. Replica is only set when a read replica is
+// configured.
+type DatabaseHealth struct {
+	Status    string         `json:"status"`
+	LatencyMs int64          `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Replica   string         `json:"replica,omitempty"`
+	Pool      PoolHealth     `json:"pool"`
+	Freshness *DataFreshness `json:"freshness,omitempty"`
+}
+
+// PoolHealth is how much of the connection pool is in use
+type PoolHealth struct {
+	MaxConns      int32   `json:"max_conns"`
+	TotalConns    int32   `json:"total_conns"`
+	AcquiredConns int32   `json:"acquired_conns"`
+	Utilization   float64 `json:"utilization"`
+}
+
+// DataFreshness is how recent the staging data is
+type DataFreshness struct {
+	LatestPeriodEnd *time.Time `json:"latest_period_end"`
+	LastImportAt    *time.Time `json:"last_import_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Industry is a value of the industry search filter and the SIC codes it matches
+type Industry struct {
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	SICPrefixes []string  `json:"sic_prefixes"` // SIC 2007 code prefixes, e.g. ["62", "63"]
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IndustryRequest represents the request body for creating or replacing an industry
+type IndustryRequest struct {
+	Description *string  `json:"description"`
+	SICPrefixes []string `json:"sic_prefixes"`
+}
+
+// IndustryListResponse represents the API response for listing industries
+type IndustryListResponse struct {
+	Industries []Industry `json:"industries"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// InsolvencyCase represents an insolvency case (liquidation, administration, CVA, ...) against a company
+type InsolvencyCase struct {
+	CaseNumber    int              `json:"case_number"`
+	CaseType      string           `json:"case_type"`
+	Open          bool             `json:"open"`
+	StartedOn     *time.Time       `json:"started_on"`
+	EndedOn       *time.Time       `json:"ended_on"`
+	Dates         []InsolvencyDate `json:"dates"`
+	Practitioners []string         `json:"practitioners"`
+}
+
+// InsolvencyDate is a dated event on an insolvency case, e.g. "wound-up-on"
+type InsolvencyDate struct {
+	Type string `json:"type"`
+	Date string `json:"date"`
+}
+
+// InsolvencySummary represents a company's insolvency status on the company detail response
+type InsolvencySummary struct {
+	InAdministration     bool             `json:"in_administration"`
+	InLiquidation        bool             `json:"in_liquidation"`
+	HasInsolvencyHistory bool             `json:"has_insolvency_history"`
+	Cases                []InsolvencyCase `json:"cases"`
+}
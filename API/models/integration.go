@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+)
+
+// CompanySelection chooses the companies an integration pushes: the listed company numbers,
+// or the results of a search
+type CompanySelection struct {
+	CompanyNumbers []string              `json:"company_numbers"`
+	Filters        *CompanySearchFilters `json:"filters"` // As for search; limit defaults to 100, at most 1000
+}
+
+// SalesforcePushRequest represents the request body for pushing companies to Salesforce
+type SalesforcePushRequest struct {
+	CompanySelection
+	Object string `json:"object"` // "Account" (default) or "Lead"
+}
+
+// SalesforcePushResponse represents the outcome of a Salesforce push, with results in the
+// order the companies were selected
+type SalesforcePushResponse struct {
+	Object  string                 `json:"object"`
+	Created int                    `json:"created"`
+	Updated int                    `json:"updated"`
+	Failed  int                    `json:"failed"`
+	Results []SalesforcePushResult `json:"results"`
+}
+
+// SalesforcePushResult is the outcome of pushing one company
+type SalesforcePushResult struct {
+	CompanyNumber string `json:"company_number"`
+	ID            string `json:"id,omitempty"` // Salesforce record ID
+	Created       bool   `json:"created"`      // False when an existing record was updated
+	Error         string `json:"error,omitempty"`
+}
+
+// HubSpotSyncRequest represents the request body for starting a HubSpot sync: a watchlist, or
+// search filters
+type HubSpotSyncRequest struct {
+	WatchlistID *int                  `json:"watchlist_id"`
+	Filters     *CompanySearchFilters `json:"filters"` // As for search; limit defaults to 1000, at most 10000
+}
+
+// HubSpotSync represents a background sync of companies to HubSpot and its progress
+type HubSpotSync struct {
+	ID             int64      `json:"id"`
+	Status         string     `json:"status"` // "queued", "running", "completed" or "failed"
+	WatchlistID    *int       `json:"watchlist_id"`
+	TotalCompanies *int       `json:"total_companies"` // Once counted
+	Processed      int        `json:"processed"`
+	Created        int        `json:"created"`
+	Updated        int        `json:"updated"`
+	Failed         int        `json:"failed"`
+	CompanyErrors  []string   `json:"company_errors"` // The first errors for individual companies
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+}
+
+// HubSpotSyncListResponse represents the API response for listing HubSpot syncs
+type HubSpotSyncListResponse struct {
+	Syncs []HubSpotSync `json:"syncs"`
+}
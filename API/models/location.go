@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Location is a canonical locality or region that the location filter resolves names to. A
+// location filter matches companies in the location, in any location inside it, or under any of
+// their aliases.
+type Location struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`      // "region", "county", "city", "borough" or "town"
+	ParentID  *int      `json:"parent_id"` // The location this one is inside, e.g. Salford -> Greater Manchester
+	Aliases   []string  `json:"aliases"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateLocationRequest represents the request body for creating a location
+type CreateLocationRequest struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	ParentID *int     `json:"parent_id"`
+	Aliases  []string `json:"aliases"`
+}
+
+// LocationAliasesRequest represents the request body for adding aliases to a location
+type LocationAliasesRequest struct {
+	Aliases []string `json:"aliases"`
+}
+
+// LocationListResponse represents the API response for listing locations
+type LocationListResponse struct {
+	Locations []Location `json:"locations"`
+}
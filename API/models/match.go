@@ -0,0 +1,34 @@
+package models
+
+// MatchRequest represents the request body for matching free-text company names
+type MatchRequest struct {
+	Queries       []MatchQuery `json:"queries"`
+	Limit         int          `json:"limit"`          // Candidates per query, default 5
+	MinConfidence float64      `json:"min_confidence"` // Drop candidates scoring below this (0-1)
+}
+
+// MatchQuery is one name to resolve, optionally with the postcode it is known at
+type MatchQuery struct {
+	Name     string `json:"name"`
+	Postcode string `json:"postcode,omitempty"`
+}
+
+// MatchCandidate is a company that may be the one a query names. Confidence is the name
+// similarity (0-1), weighted towards companies at the query's postcode when one is given.
+type MatchCandidate struct {
+	Company
+	Confidence     float64 `json:"confidence"`
+	NameSimilarity float64 `json:"name_similarity"`
+	PostcodeMatch  *bool   `json:"postcode_match"` // null when either postcode is unknown
+}
+
+// MatchResult holds the candidates for one query, best first
+type MatchResult struct {
+	Query      MatchQuery       `json:"query"`
+	Candidates []MatchCandidate `json:"candidates"`
+}
+
+// MatchResponse represents the API response for a name match, one result per query in request order
+type MatchResponse struct {
+	Results []MatchResult `json:"results"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// TurnoverPeriod is one financial period in a turnover series. The YoY fields compare with the
+// previous period and are null when either turnover is missing.
+type TurnoverPeriod struct {
+	PeriodStart  *time.Time `json:"period_start"`
+	PeriodEnd    time.Time  `json:"period_end"`
+	Turnover     *float64   `json:"turnover"`
+	YoYChange    *float64   `json:"yoy_change"`
+	YoYChangePct *float64   `json:"yoy_change_pct"` // Null when the previous turnover is not positive
+}
+
+// TurnoverSeriesResponse represents the API response for a company's turnover series
+type TurnoverSeriesResponse struct {
+	CompanyNumber string           `json:"company_number"`
+	AsOf          *time.Time       `json:"as_of,omitempty"` // Only periods ending by then, when asked for as_of
+	Periods       []TurnoverPeriod `json:"periods"`
+}
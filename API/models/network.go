@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// NetworkNode is a company in a shared-officer network, Hops officers away from the company
+// the network was requested for (0 for that company itself)
+type NetworkNode struct {
+	CompanyNumber string  `json:"company_number"`
+	CompanyName   *string `json:"company_name"`
+	CompanyStatus *string `json:"company_status"`
+	Hops          int     `json:"hops"`
+}
+
+// NetworkEdge links two companies that share a current officer. From is the company nearer
+// the requested one.
+type NetworkEdge struct {
+	From            string     `json:"from"`
+	To              string     `json:"to"`
+	OfficerID       string     `json:"officer_id"` // See GET /api/officers/{id}
+	OfficerName     *string    `json:"officer_name"`
+	FromRole        *string    `json:"from_role"`
+	ToRole          *string    `json:"to_role"`
+	FromAppointedOn *time.Time `json:"from_appointed_on"`
+	ToAppointedOn   *time.Time `json:"to_appointed_on"`
+}
+
+// NetworkResponse represents the API response for a company's shared-officer network.
+// Truncated is set when MaxNodes was reached before every company Depth hops away was found.
+type NetworkResponse struct {
+	CompanyNumber string        `json:"company_number"`
+	Depth         int           `json:"depth"`
+	Nodes         []NetworkNode `json:"nodes"`
+	Edges         []NetworkEdge `json:"edges"`
+	Truncated     bool          `json:"truncated"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Officer represents a director, secretary or other officer appointment at a company
+type Officer struct {
+	ID           int        `json:"id"`
+	Name         *string    `json:"name"`
+	Role         *string    `json:"role"`
+	AppointedOn  *time.Time `json:"appointed_on"`
+	ResignedOn   *time.Time `json:"resigned_on"`
+	Active       bool       `json:"active"`
+	Nationality  *string    `json:"nationality"`
+	DateOfBirth  *string    `json:"date_of_birth"` // YYYY-MM
+	AddressLine1 *string    `json:"address_line_1"`
+	Locality     *string    `json:"locality"`
+	PostalCode   *string    `json:"postal_code"`
+	Country      *string    `json:"country"`
+}
+
+// OfficerAppointment is one of a person's officer appointments, with the company it is at
+type OfficerAppointment struct {
+	ID            int        `json:"id"`
+	CompanyNumber string     `json:"company_number"`
+	CompanyName   *string    `json:"company_name"`
+	CompanyStatus *string    `json:"company_status"`
+	Role          *string    `json:"role"`
+	AppointedOn   *time.Time `json:"appointed_on"`
+	ResignedOn    *time.Time `json:"resigned_on"`
+	Active        bool       `json:"active"`
+}
+
+// OfficerProfile is a person who is or was an officer: the appointments that share a
+// normalized name and month of birth, across companies, current ones first
+type OfficerProfile struct {
+	ID                   string               `json:"id"`
+	Name                 string               `json:"name"`          // As on the first appointment listed
+	DateOfBirth          *string              `json:"date_of_birth"` // YYYY-MM
+	Nationality          *string              `json:"nationality"`
+	ActiveAppointments   int                  `json:"active_appointments"`
+	ResignedAppointments int                  `json:"resigned_appointments"`
+	Appointments         []OfficerAppointment `json:"appointments"`
+}
+
+// OfficerSearchResponse represents the API response for an officer name search
+type OfficerSearchResponse struct {
+	Name     string           `json:"name"`
+	Officers []OfficerProfile `json:"officers"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+	HasMore  bool             `json:"has_more"`
+}
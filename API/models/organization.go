@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Organization represents a tenant whose API keys and users share stored objects
+type Organization struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+
+	// Rate limit tier and monthly quotas (nil = unlimited) of keys issued by the
+	// organization's own admins
+	Tier                string `json:"tier"`
+	MonthlyRequestQuota *int64 `json:"monthly_request_quota"`
+	MonthlyRowQuota     *int64 `json:"monthly_row_quota"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateOrganizationRequest represents the request body for creating an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+	Tier string `json:"tier"` // rate limit tier, "standard" by default
+
+	// Monthly quotas; omitted or null means unlimited
+	MonthlyRequestQuota *int64 `json:"monthly_request_quota"`
+	MonthlyRowQuota     *int64 `json:"monthly_row_quota"`
+}
+
+// OrganizationListResponse represents the API response for listing organizations
+type OrganizationListResponse struct {
+	Organizations []Organization `json:"organizations"`
+}
+
+// User represents a JWT subject belonging to an organization
+type User struct {
+	ID             int       `json:"id"`
+	OrganizationID int       `json:"organization_id"`
+	Subject        string    `json:"subject"`
+	Email          *string   `json:"email"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CreateUserRequest represents the request body for adding a user to an organization
+type CreateUserRequest struct {
+	Subject string  `json:"subject"` // The JWT sub claim the user signs in with
+	Email   *string `json:"email"`
+}
+
+// UserListResponse represents the API response for listing an organization's users
+type UserListResponse struct {
+	Users []User `json:"users"`
+}
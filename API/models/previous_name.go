@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PreviousName represents a name a company was previously registered under
+type PreviousName struct {
+	Name          string     `json:"name"`
+	EffectiveFrom *time.Time `json:"effective_from"`
+	CeasedOn      *time.Time `json:"ceased_on"`
+}
+
+// PreviousNamesResponse represents the API response for a company's previous names
+type PreviousNamesResponse struct {
+	CompanyNumber string         `json:"company_number"`
+	PreviousNames []PreviousName `json:"previous_names"` // Most recent first
+}
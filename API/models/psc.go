@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// PSC represents a person with significant control, or a PSC statement, of a company
+type PSC struct {
+	ID                 string     `json:"id"`
+	Kind               string     `json:"kind"`
+	PSCType            string     `json:"psc_type"` // individual, corporate, legal_person, super_secure, statement or exemption
+	Name               *string    `json:"name"`
+	Nationality        *string    `json:"nationality"`
+	CountryOfResidence *string    `json:"country_of_residence"`
+	DateOfBirth        *string    `json:"date_of_birth"` // YYYY-MM
+	NaturesOfControl   []string   `json:"natures_of_control"`
+	NotifiedOn         *time.Time `json:"notified_on"`
+	CeasedOn           *time.Time `json:"ceased_on"`
+	Statement          *string    `json:"statement"`
+	LegalForm          *string    `json:"legal_form"`
+	RegistrationNumber *string    `json:"registration_number"`
+	PlaceRegistered    *string    `json:"place_registered"`
+	CountryRegistered  *string    `json:"country_registered"`
+	ParentCompany      *string    `json:"parent_company_number"` // When registered at Companies House
+	AddressLine1       *string    `json:"address_line_1"`
+	Locality           *string    `json:"locality"`
+	PostalCode         *string    `json:"postal_code"`
+	Country            *string    `json:"country"`
+}
+
+// PSCListResponse represents the API response for a company's PSCs
+type PSCListResponse struct {
+	CompanyNumber string `json:"company_number"`
+	PSCs          []PSC  `json:"pscs"`
+}
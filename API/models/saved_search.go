@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// SavedSearch is a search evaluated nightly against new and changed companies, recording the
+// companies that newly match it
+type SavedSearch struct {
+	ID          int                  `json:"id"`
+	Name        string               `json:"name"`
+	Filters     CompanySearchFilters `json:"filters"`
+	WebhookID   *int                 `json:"webhook_id"` // Webhook sent a saved_search.new_matches event for each alert
+	EmailTo     []string             `json:"email_to"`   // Addresses emailed each alert
+	EvaluatedAt *time.Time           `json:"evaluated_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// CreateSavedSearchRequest represents the request body for saving a search
+type CreateSavedSearchRequest struct {
+	Name      string               `json:"name"`
+	Filters   CompanySearchFilters `json:"filters"`
+	WebhookID *int                 `json:"webhook_id"`
+	EmailTo   []string             `json:"email_to"`
+}
+
+// SavedSearchListResponse represents the API response for listing saved searches
+type SavedSearchListResponse struct {
+	SavedSearches []SavedSearch `json:"saved_searches"`
+}
+
+// SavedSearchMatch is a company that newly matched a saved search, with its current fields
+type SavedSearchMatch struct {
+	Company
+	MatchedAt time.Time `json:"matched_at"`
+}
+
+// SavedSearchMatchesResponse represents the API response for a saved search's new matches
+type SavedSearchMatchesResponse struct {
+	SavedSearchID int                `json:"saved_search_id"`
+	Since         time.Time          `json:"since"`
+	Matches       []SavedSearchMatch `json:"matches"`
+}
+
+// SavedSearchAlertEvent is the JSON body delivered to a saved search's webhook when an
+// evaluation finds new matches
+type SavedSearchAlertEvent struct {
+	ID            int64                `json:"id"`
+	Type          string               `json:"type"`
+	CreatedAt     time.Time            `json:"created_at"`
+	SavedSearchID int                  `json:"saved_search_id"`
+	Data          SavedSearchAlertData `json:"data"`
+}
+
+// SavedSearchAlertData summarises the new matches of an alert
+type SavedSearchAlertData struct {
+	Name           string   `json:"name"`
+	NewMatches     int      `json:"new_matches"`
+	CompanyNumbers []string `json:"company_numbers"` // The first 100 new matches
+}
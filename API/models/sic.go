@@ -0,0 +1,27 @@
+package models
+
+// SICSection is the top level of the SIC 2007 hierarchy, e.g. "J" (Information and communication)
+type SICSection struct {
+	Code        string        `json:"code"`
+	Description string        `json:"description"`
+	Divisions   []SICDivision `json:"divisions"`
+}
+
+// SICDivision is a 2-digit SIC 2007 code, e.g. "62"
+type SICDivision struct {
+	Code        string     `json:"code"`
+	Description string     `json:"description"`
+	Classes     []SICClass `json:"classes"`
+}
+
+// SICClass is a 4-digit SIC 2007 code, e.g. "6201". Company SIC codes are 5-digit subclasses
+// starting with their class.
+type SICClass struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// SICResponse represents the API response for the SIC 2007 hierarchy
+type SICResponse struct {
+	Sections []SICSection `json:"sections"`
+}
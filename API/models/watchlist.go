@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// Watchlist represents a named set of tracked companies
+type Watchlist struct {
+	ID             int       `json:"id"`
+	Name           string    `json:"name"`
+	CompanyNumbers []string  `json:"company_numbers"`
+	Slack          bool      `json:"slack"` // Whether changes are posted to a Slack incoming webhook
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CreateWatchlistRequest represents the request body for creating a watchlist
+type CreateWatchlistRequest struct {
+	Name           string   `json:"name"`
+	CompanyNumbers []string `json:"company_numbers"`
+}
+
+// WatchlistCompaniesRequest represents the request body for adding companies to a watchlist
+type WatchlistCompaniesRequest struct {
+	CompanyNumbers []string `json:"company_numbers"`
+}
+
+// WatchlistSlackRequest represents the request body for posting a watchlist's changes to Slack
+type WatchlistSlackRequest struct {
+	WebhookURL string `json:"webhook_url"` // A Slack incoming webhook, https://hooks.slack.com/services/...
+}
+
+// WatchlistListResponse represents the API response for listing watchlists
+type WatchlistListResponse struct {
+	Watchlists []Watchlist `json:"watchlists"`
+}
+
+// CompanyChange represents a detected change to one field of a company
+type CompanyChange struct {
+	ID            int64     `json:"id"`
+	CompanyNumber string    `json:"company_number"`
+	ChangeType    string    `json:"change_type"`
+	OldValue      *string   `json:"old_value"`
+	NewValue      *string   `json:"new_value"`
+	DetectedAt    time.Time `json:"detected_at"`
+}
+
+// WatchlistChangesResponse represents the API response for watchlist changes
+type WatchlistChangesResponse struct {
+	WatchlistID int             `json:"watchlist_id"`
+	Since       time.Time       `json:"since"`
+	Changes     []CompanyChange `json:"changes"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription represents a registered webhook endpoint
+type WebhookSubscription struct {
+	ID         int                   `json:"id"`
+	URL        string                `json:"url"`
+	EventTypes []string              `json:"event_types"`
+	Filters    *CompanySearchFilters `json:"filters,omitempty"` // Saved search of a company.matched_search subscription
+	Active     bool                  `json:"active"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+// CreateWebhookRequest represents the request body for registering a webhook
+type CreateWebhookRequest struct {
+	URL        string                `json:"url"`
+	EventTypes []string              `json:"event_types"` // empty = all event types
+	Filters    *CompanySearchFilters `json:"filters"`     // Search to deliver new matches of; only with company.matched_search
+}
+
+// CreateWebhookResponse returns the new subscription; Secret is only ever shown in this response
+type CreateWebhookResponse struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookListResponse represents the API response for listing webhooks
+type WebhookListResponse struct {
+	Webhooks []WebhookSubscription `json:"webhooks"`
+}
+
+// WebhookEvent is the JSON body delivered to webhook subscribers
+type WebhookEvent struct {
+	ID        int64            `json:"id"`
+	Type      string           `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      WebhookEventData `json:"data"`
+}
+
+// WebhookEventData describes the company change behind a webhook event
+type WebhookEventData struct {
+	CompanyNumber string  `json:"company_number"`
+	OldValue      *string `json:"old_value"`
+	NewValue      *string `json:"new_value"`
+}
+
+// SearchMatchEvent is the JSON body delivered for a company newly matching a saved search.
+// Data holds the company's plain version 2 fields.
+type SearchMatchEvent struct {
+	ID             int64           `json:"id"`
+	Type           string          `json:"type"`
+	CreatedAt      time.Time       `json:"created_at"`
+	SubscriptionID int             `json:"subscription_id"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// SearchSampleRequest represents the request body for sample company.matched_search events
+type SearchSampleRequest struct {
+	Filters CompanySearchFilters `json:"filters"`
+}
+
+// WebhookDeadLetter represents a delivery that failed after all retries
+type WebhookDeadLetter struct {
+	ID             int64           `json:"id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	FailedAt       time.Time       `json:"failed_at"`
+}
+
+// WebhookDeadLetterListResponse represents the API response for listing dead letters
+type WebhookDeadLetterListResponse struct {
+	DeadLetters []WebhookDeadLetter `json:"dead_letters"`
+}
@@ -0,0 +1,246 @@
+package openapi
+
+import (
+	"net/http"
+
+	"data-co/api/graphql"
+	"data-co/api/models"
+)
+
+// Route documents one endpoint. Keep this list in step with the routes registered in main.go.
+type Route struct {
+	Method  string
+	Path    string // As registered with the router, e.g. /api/companies/{company_number}/pscs
+	Tag     string
+	Summary string
+	Role    string // Minimum role, or empty for public endpoints
+	Query   []Param
+	// Request and Response are zero values of the body types; nil means no body
+	Request  any
+	Response any
+	Status   int    // Success status, 200 if unset
+	Content  string // Response media type, application/json if unset
+}
+
+// Param is a query parameter
+type Param struct {
+	Name        string
+	Type        string // OpenAPI primitive type
+	Description string
+}
+
+// asOfParam is the date of a point-in-time request
+var asOfParam = Param{Name: "as_of", Type: "string", Description: "Reconstruct the state at the end of this date, YYYY-MM-DD"}
+
+// Routes lists every API endpoint
+var Routes = []Route{
+	{Method: http.MethodPost, Path: "/api/companies/search", Tag: "Companies", Role: "reader",
+		Summary: "Search companies by filters", Request: models.CompanySearchFilters{}, Response: models.SearchResponse{},
+		Query: []Param{
+			{Name: "offset", Type: "integer", Description: "Overrides the offset in the body, as set by page links"},
+			{Name: "limit", Type: "integer", Description: "Overrides the limit in the body, as set by page links"},
+		}},
+	{Method: http.MethodPost, Path: "/api/companies/count", Tag: "Companies", Role: "reader",
+		Summary: "Count companies matching filters", Request: models.CompanySearchFilters{}, Response: models.CountResponse{}},
+	{Method: http.MethodPost, Path: "/api/companies/compare", Tag: "Companies", Role: "reader",
+		Summary: "Compare up to 10 companies side by side", Request: models.CompareRequest{}, Response: models.CompareResponse{}},
+	{Method: http.MethodPost, Path: "/api/analytics/top", Tag: "Analytics", Role: "reader",
+		Summary: "Rank the top companies per industry or region", Request: models.TopCompaniesRequest{}, Response: models.TopCompaniesResponse{}},
+	{Method: http.MethodPost, Path: "/api/analytics/aggregate", Tag: "Analytics", Role: "reader",
+		Summary: "Company counts and financial statistics per group", Request: models.AggregateRequest{}, Response: models.AggregateResponse{}},
+	{Method: http.MethodPost, Path: "/api/analytics/histogram", Tag: "Analytics", Role: "reader",
+		Summary: "Distribution of a figure across companies matching filters", Request: models.HistogramRequest{}, Response: models.HistogramResponse{}},
+	{Method: http.MethodGet, Path: "/api/analytics/incorporations", Tag: "Analytics", Role: "reader",
+		Summary: "Incorporations and dissolutions over time", Response: models.IncorporationTrendResponse{},
+		Query: []Param{
+			{Name: "group_by", Type: "string", Description: "month, quarter or year, default month"},
+			{Name: "from", Type: "string", Description: "First date counted, YYYY-MM-DD, default 1 January nine years ago"},
+			{Name: "to", Type: "string", Description: "Last date counted, YYYY-MM-DD, default today"},
+			{Name: "sic", Type: "string", Description: "Only companies with a SIC code starting with these digits, e.g. 62"},
+			{Name: "industry", Type: "string", Description: "Only companies in this industry"},
+			{Name: "location", Type: "string", Description: "Only companies in this locality or region, as for the search location filter"},
+		}},
+	{Method: http.MethodPost, Path: "/api/companies/batch", Tag: "Companies", Role: "reader",
+		Summary: "Get up to 500 companies by number", Request: models.BatchRequest{}, Response: models.BatchResponse{}},
+	{Method: http.MethodPost, Path: "/api/companies/match", Tag: "Companies", Role: "reader",
+		Summary: "Match free-text names to companies", Request: models.MatchRequest{}, Response: models.MatchResponse{}},
+	{Method: http.MethodGet, Path: "/api/companies/changes", Tag: "Companies", Role: "reader",
+		Summary: "List companies with data written since a timestamp, for incremental syncs", Response: models.ChangedCompaniesResponse{},
+		Query: []Param{
+			{Name: "since", Type: "string", Description: "RFC 3339 time; required without cursor"},
+			{Name: "cursor", Type: "string", Description: "next_cursor of the previous page"},
+			{Name: "limit", Type: "integer", Description: "At most 10000, default 1000"},
+		}},
+	{Method: http.MethodGet, Path: "/api/companies/number/{company_number}", Tag: "Companies", Role: "reader",
+		Summary: "Get a company by number", Response: models.Company{},
+		Query: []Param{asOfParam}},
+	{Method: http.MethodGet, Path: "/api/companies/{company_number}", Tag: "Companies", Role: "reader",
+		Summary: "Get a company by number", Response: models.Company{},
+		Query: []Param{asOfParam}},
+	{Method: http.MethodGet, Path: "/api/companies/{company_number}/pscs", Tag: "Companies", Role: "reader",
+		Summary: "List a company's persons with significant control", Response: models.PSCListResponse{}},
+	{Method: http.MethodGet, Path: "/api/companies/{company_number}/charges", Tag: "Companies", Role: "reader",
+		Summary: "List a company's registered charges", Response: models.ChargeListResponse{}},
+	{Method: http.MethodGet, Path: "/api/companies/{company_number}/previous-names", Tag: "Companies", Role: "reader",
+		Summary: "List a company's previous names", Response: models.PreviousNamesResponse{}},
+	{Method: http.MethodGet, Path: "/api/companies/{company_number}/changes", Tag: "Companies", Role: "reader",
+		Summary: "List changes to a company's key fields, newest first", Response: models.CompanyChangesResponse{},
+		Query: []Param{
+			{Name: "since", Type: "string", Description: "Only changes after this RFC 3339 time"},
+			{Name: "field", Type: "string", Description: "Only changes to this field"},
+			{Name: "limit", Type: "integer", Description: "At most 10000, default 1000"},
+		}},
+	{Method: http.MethodGet, Path: "/api/companies/{company_number}/network", Tag: "Companies", Role: "reader",
+		Summary: "Get the companies connected to a company through shared current officers", Response: models.NetworkResponse{},
+		Query: []Param{{Name: "depth", Type: "integer", Description: "Hops to follow, at most 3, default 1"}}},
+	{Method: http.MethodGet, Path: "/api/companies/{company_number}/group", Tag: "Companies", Role: "reader",
+		Summary: "Get a company's corporate group as an ownership tree, with the group's turnover", Response: models.GroupResponse{}},
+	{Method: http.MethodGet, Path: "/api/companies/{company_number}/metrics/turnover", Tag: "Companies", Role: "reader",
+		Summary: "Get a company's turnover by period with year-on-year changes", Response: models.TurnoverSeriesResponse{},
+		Query: []Param{asOfParam}},
+	{Method: http.MethodPost, Path: "/api/companies/{company_number}/enrich", Tag: "Companies", Role: "exporter",
+		Summary: "Look up a company's email addresses and phone numbers, for a contact credit", Response: models.EnrichContactsResponse{},
+		Query: []Param{{Name: "refresh", Type: "boolean", Description: "Look up again even if contacts were enriched recently"}}},
+
+	{Method: http.MethodGet, Path: "/api/officers/search", Tag: "Officers", Role: "reader",
+		Summary: "Find people by officer name, with their appointments across companies", Response: models.OfficerSearchResponse{},
+		Query: []Param{
+			{Name: "name", Type: "string", Description: "e.g. John Smith or SMITH, John; middle names are ignored and forenames can be initials"},
+			{Name: "limit", Type: "integer", Description: "People per page, at most 100, default 20"},
+			{Name: "offset", Type: "integer", Description: "People to skip, default 0"},
+		}},
+	{Method: http.MethodGet, Path: "/api/officers/{id}", Tag: "Officers", Role: "reader",
+		Summary: "Get an officer with their current and resigned appointments across companies", Response: models.OfficerProfile{}},
+
+	{Method: http.MethodPost, Path: "/api/graphql", Tag: "GraphQL", Role: "reader",
+		Summary: "Run a GraphQL query", Request: graphql.Request{}, Response: graphql.Response{}},
+	{Method: http.MethodGet, Path: "/api/graphql", Tag: "GraphQL", Role: "reader",
+		Summary: "Run a GraphQL query from query parameters", Response: graphql.Response{},
+		Query: []Param{
+			{Name: "query", Type: "string", Description: "The query document"},
+			{Name: "operationName", Type: "string"},
+			{Name: "variables", Type: "string", Description: "JSON-encoded variables"},
+		}},
+	{Method: http.MethodGet, Path: "/api/graphql/schema", Tag: "GraphQL", Role: "reader",
+		Summary: "Get the GraphQL schema definition", Response: "", Content: "text/plain"},
+
+	{Method: http.MethodPost, Path: "/api/watchlists", Tag: "Watchlists", Role: "reader",
+		Summary: "Create a watchlist", Request: models.CreateWatchlistRequest{}, Response: models.Watchlist{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/watchlists", Tag: "Watchlists", Role: "reader",
+		Summary: "List your watchlists", Response: models.WatchlistListResponse{}},
+	{Method: http.MethodGet, Path: "/api/watchlists/{id}", Tag: "Watchlists", Role: "reader",
+		Summary: "Get a watchlist", Response: models.Watchlist{}},
+	{Method: http.MethodDelete, Path: "/api/watchlists/{id}", Tag: "Watchlists", Role: "reader",
+		Summary: "Delete a watchlist", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/watchlists/{id}/companies", Tag: "Watchlists", Role: "reader",
+		Summary: "Add companies to a watchlist", Request: models.WatchlistCompaniesRequest{}, Response: models.Watchlist{}},
+	{Method: http.MethodDelete, Path: "/api/watchlists/{id}/companies/{company_number}", Tag: "Watchlists", Role: "reader",
+		Summary: "Remove a company from a watchlist", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/watchlists/{id}/changes", Tag: "Watchlists", Role: "reader",
+		Summary: "List changes to a watchlist's companies", Response: models.WatchlistChangesResponse{},
+		Query: []Param{
+			{Name: "since", Type: "string", Description: "RFC 3339 time, default 7 days ago"},
+			{Name: "limit", Type: "integer", Description: "At most 10000, default 1000"},
+		}},
+	{Method: http.MethodPut, Path: "/api/watchlists/{id}/slack", Tag: "Watchlists", Role: "reader",
+		Summary: "Post a watchlist's changes to a Slack incoming webhook", Request: models.WatchlistSlackRequest{}, Response: models.Watchlist{}},
+	{Method: http.MethodDelete, Path: "/api/watchlists/{id}/slack", Tag: "Watchlists", Role: "reader",
+		Summary: "Stop posting a watchlist's changes to Slack", Status: http.StatusNoContent},
+
+	{Method: http.MethodPost, Path: "/api/webhooks", Tag: "Webhooks", Role: "reader",
+		Summary: "Register a webhook", Request: models.CreateWebhookRequest{}, Response: models.CreateWebhookResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/webhooks", Tag: "Webhooks", Role: "reader",
+		Summary: "List your webhooks", Response: models.WebhookListResponse{}},
+	{Method: http.MethodPost, Path: "/api/webhooks/samples/matched-search", Tag: "Webhooks", Role: "reader",
+		Summary: "Sample company.matched_search events for a saved search", Request: models.SearchSampleRequest{}, Response: []models.SearchMatchEvent{}},
+	{Method: http.MethodDelete, Path: "/api/webhooks/{id}", Tag: "Webhooks", Role: "reader",
+		Summary: "Delete a webhook", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/webhooks/{id}/dead-letters", Tag: "Webhooks", Role: "reader",
+		Summary: "List deliveries that failed after all retries", Response: models.WebhookDeadLetterListResponse{},
+		Query: []Param{{Name: "limit", Type: "integer", Description: "At most 1000, default 100"}}},
+
+	{Method: http.MethodPost, Path: "/api/saved-searches", Tag: "Saved Searches", Role: "reader",
+		Summary: "Save a search to be alerted to new matches", Request: models.CreateSavedSearchRequest{}, Response: models.SavedSearch{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/saved-searches", Tag: "Saved Searches", Role: "reader",
+		Summary: "List your saved searches", Response: models.SavedSearchListResponse{}},
+	{Method: http.MethodGet, Path: "/api/saved-searches/{id}", Tag: "Saved Searches", Role: "reader",
+		Summary: "Get a saved search", Response: models.SavedSearch{}},
+	{Method: http.MethodDelete, Path: "/api/saved-searches/{id}", Tag: "Saved Searches", Role: "reader",
+		Summary: "Delete a saved search", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/saved-searches/{id}/new-matches", Tag: "Saved Searches", Role: "reader",
+		Summary: "List companies that newly matched a saved search", Response: models.SavedSearchMatchesResponse{},
+		Query: []Param{
+			{Name: "since", Type: "string", Description: "RFC 3339 time, default 7 days ago"},
+			{Name: "limit", Type: "integer", Description: "At most 10000, default 1000"},
+		}},
+
+	{Method: http.MethodPost, Path: "/api/exports", Tag: "Exports", Role: "exporter",
+		Summary: "Start a background export of a company search", Request: models.CreateExportRequest{}, Response: models.ExportJob{}, Status: http.StatusAccepted},
+	{Method: http.MethodGet, Path: "/api/exports", Tag: "Exports", Role: "exporter",
+		Summary: "List your recent exports", Response: models.ExportListResponse{}},
+	{Method: http.MethodGet, Path: "/api/exports/{id}", Tag: "Exports", Role: "exporter",
+		Summary: "Get an export's status and progress", Response: models.ExportJob{}},
+	{Method: http.MethodGet, Path: "/api/exports/{id}/download", Tag: "Exports", Role: "exporter",
+		Summary: "Download a completed export as CSV or NDJSON"},
+
+	{Method: http.MethodPost, Path: "/api/integrations/salesforce/push", Tag: "Integrations", Role: "exporter",
+		Summary: "Create or update Salesforce Accounts or Leads from companies", Request: models.SalesforcePushRequest{}, Response: models.SalesforcePushResponse{}},
+	{Method: http.MethodPost, Path: "/api/integrations/hubspot/syncs", Tag: "Integrations", Role: "exporter",
+		Summary: "Start syncing a watchlist or search results to HubSpot companies", Request: models.HubSpotSyncRequest{}, Response: models.HubSpotSync{}, Status: http.StatusAccepted},
+	{Method: http.MethodGet, Path: "/api/integrations/hubspot/syncs", Tag: "Integrations", Role: "exporter",
+		Summary: "List your recent HubSpot syncs", Response: models.HubSpotSyncListResponse{}},
+	{Method: http.MethodGet, Path: "/api/integrations/hubspot/syncs/{id}", Tag: "Integrations", Role: "exporter",
+		Summary: "Get a HubSpot sync's status and progress", Response: models.HubSpotSync{}},
+
+	{Method: http.MethodGet, Path: "/api/reference/sic", Tag: "Reference", Role: "reader",
+		Summary: "Get the SIC 2007 hierarchy of sections, divisions and classes", Response: models.SICResponse{}},
+
+	{Method: http.MethodGet, Path: "/api/usage", Tag: "Usage", Role: "reader",
+		Summary: "Get your API key's usage and quotas", Response: models.UsageResponse{},
+		Query: []Param{{Name: "months", Type: "integer", Description: "Months of history, at most 36, default 12"}}},
+	{Method: http.MethodGet, Path: "/api/health", Tag: "Health",
+		Summary: "Health check", Response: models.HealthResponse{},
+		Query: []Param{{Name: "deep", Type: "boolean", Description: "Also query the database, responding 503 if it is unreachable"}}},
+
+	{Method: http.MethodPost, Path: "/api/admin/summaries/refresh", Tag: "Admin", Role: "admin",
+		Summary: "Refresh the search summary views", Response: models.SummaryRefreshResponse{}},
+	{Method: http.MethodGet, Path: "/api/admin/pool", Tag: "Admin", Role: "admin",
+		Summary: "Get database connection pool statistics", Response: models.PoolStatsResponse{}},
+	{Method: http.MethodGet, Path: "/api/admin/stats", Tag: "Admin", Role: "admin",
+		Summary: "Get staging data row counts, freshness and index sizes", Response: models.DataStatsResponse{}},
+	{Method: http.MethodGet, Path: "/api/admin/data-quality/duplicates", Tag: "Admin", Role: "admin",
+		Summary: "List companies staged under several forms of their number, and which row search returns", Response: models.DuplicateCompaniesResponse{},
+		Query: []Param{{Name: "limit", Type: "integer", Description: "Companies to list, at most 1000, default 100"}}},
+	{Method: http.MethodPost, Path: "/api/admin/keys", Tag: "Admin", Role: "admin",
+		Summary: "Create an API key", Request: models.CreateAPIKeyRequest{}, Response: models.CreateAPIKeyResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/admin/keys", Tag: "Admin", Role: "admin",
+		Summary: "List API keys", Response: models.APIKeyListResponse{}},
+	{Method: http.MethodDelete, Path: "/api/admin/keys/{id}", Tag: "Admin", Role: "admin",
+		Summary: "Revoke an API key", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/admin/organizations", Tag: "Admin", Role: "admin",
+		Summary: "Create an organization", Request: models.CreateOrganizationRequest{}, Response: models.Organization{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/admin/organizations", Tag: "Admin", Role: "admin",
+		Summary: "List organizations", Response: models.OrganizationListResponse{}},
+	{Method: http.MethodPost, Path: "/api/admin/organizations/{id}/users", Tag: "Admin", Role: "admin",
+		Summary: "Add a user to an organization", Request: models.CreateUserRequest{}, Response: models.User{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/admin/organizations/{id}/users", Tag: "Admin", Role: "admin",
+		Summary: "List an organization's users", Response: models.UserListResponse{}},
+	{Method: http.MethodDelete, Path: "/api/admin/organizations/{id}/users/{user_id}", Tag: "Admin", Role: "admin",
+		Summary: "Remove a user from an organization", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/admin/locations", Tag: "Admin", Role: "admin",
+		Summary: "List locations and their aliases", Response: models.LocationListResponse{}},
+	{Method: http.MethodPost, Path: "/api/admin/locations", Tag: "Admin", Role: "admin",
+		Summary: "Create a location", Request: models.CreateLocationRequest{}, Response: models.Location{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/admin/locations/{id}", Tag: "Admin", Role: "admin",
+		Summary: "Delete a location and the locations inside it", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/admin/locations/{id}/aliases", Tag: "Admin", Role: "admin",
+		Summary: "Add aliases to a location", Request: models.LocationAliasesRequest{}, Response: models.Location{}},
+	{Method: http.MethodDelete, Path: "/api/admin/locations/{id}/aliases/{alias}", Tag: "Admin", Role: "admin",
+		Summary: "Remove an alias from a location", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/admin/industries", Tag: "Admin", Role: "admin",
+		Summary: "List industries and their SIC code prefixes", Response: models.IndustryListResponse{}},
+	{Method: http.MethodPut, Path: "/api/admin/industries/{name}", Tag: "Admin", Role: "admin",
+		Summary: "Create or replace an industry", Request: models.IndustryRequest{}, Response: models.Industry{}},
+	{Method: http.MethodDelete, Path: "/api/admin/industries/{name}", Tag: "Admin", Role: "admin",
+		Summary: "Delete an industry", Status: http.StatusNoContent},
+}
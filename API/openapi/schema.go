@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI 3.0 schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Types with a fixed schema rather than one derived from their fields. The sql.Null* wrappers
+// are not among them: encoding/json writes them as {"String": ..., "Valid": ...} objects.
+var knownTypes = map[reflect.Type]Schema{
+	reflect.TypeOf(time.Time{}):       {Type: "string", Format: "date-time"},
+	reflect.TypeOf(json.RawMessage{}): {Type: "object", Description: "Arbitrary JSON"},
+}
+
+// generator derives schemas from Go types. Named structs become components referenced by name.
+type generator struct {
+	components map[string]*Schema
+}
+
+// schemaFor returns the schema for the type of v
+func (g *generator) schemaFor(v any) *Schema {
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	if known, ok := knownTypes[t]; ok {
+		return &known
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			// nullable cannot sit beside $ref in OpenAPI 3.0, and a referenced object reads
+			// the same either way
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := componentName(t)
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = &Schema{} // Placeholder so self-references terminate
+			g.components[name] = g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// object builds the schema of a struct from its JSON-visible fields. Embedded structs without a
+// JSON name are flattened, as encoding/json does.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			continue // Its promoted fields are visited separately
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if len(f.Index) > 1 && s.Properties[name] != nil {
+			continue // A shallower field with the same name wins
+		}
+		s.Properties[name] = g.schema(f.Type)
+	}
+	return s
+}
+
+// componentName names a struct's component. Types outside the models package are prefixed with
+// their package name, e.g. GraphqlRequest, so names cannot collide.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	pkg := path.Base(t.PkgPath())
+	if pkg == "models" {
+		return name
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + strings.ToUpper(name[:1]) + name[1:]
+}
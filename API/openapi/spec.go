@@ -0,0 +1,197 @@
+// Package openapi describes the API as an OpenAPI 3 document, with request and response schemas
+// derived from the models package, and serves it with a Swagger UI page.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"data-co/api/models"
+)
+
+// Document is an OpenAPI 3.0 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Tags       []Tag                            `json:"tags"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// The types below mirror the OpenAPI objects of the same names, with only the fields used here
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type Operation struct {
+	Tags        []string              `json:"tags"`
+	Summary     string                `json:"summary"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Version is the API version reported in the document
+const Version = "1.0.0"
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Build generates the document for routes
+func Build(routes []Route) *Document {
+	g := &generator{components: make(map[string]*Schema)}
+	errorSchema := g.schemaFor(models.ErrorResponse{})
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Data-Co API",
+			Description: "UK Companies House data with advanced filtering. Authenticate with an API key or JWT; the role each endpoint needs is given in its description.",
+			Version:     Version,
+		},
+		Paths: make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: g.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearer": {Type: "http", Scheme: "bearer", Description: "An API key or JWT"},
+				"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			},
+		},
+	}
+
+	seenTags := make(map[string]bool)
+	for _, route := range routes {
+		if !seenTags[route.Tag] {
+			seenTags[route.Tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: route.Tag})
+		}
+
+		op := &Operation{
+			Tags:        []string{route.Tag},
+			Summary:     route.Summary,
+			OperationID: operationID(route),
+			Responses:   make(map[string]Response),
+			Security:    []map[string][]string{},
+		}
+		if route.Role != "" {
+			op.Summary += " (" + route.Role + ")"
+			op.Security = []map[string][]string{{"bearer": {}}, {"apiKey": {}}}
+		}
+
+		for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			param := Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}}
+			if match[1] == "id" {
+				param.Schema = &Schema{Type: "integer"}
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+		for _, q := range route.Query {
+			op.Parameters = append(op.Parameters, Parameter{Name: q.Name, In: "query", Description: q.Description, Schema: &Schema{Type: q.Type}})
+		}
+
+		if route.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: g.schemaFor(route.Request)}},
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status)}
+		if route.Response != nil {
+			content := route.Content
+			if content == "" {
+				content = "application/json"
+			}
+			success.Content = map[string]MediaType{content: {Schema: g.schemaFor(route.Response)}}
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+		}
+
+		if doc.Paths[route.Path] == nil {
+			doc.Paths[route.Path] = make(map[string]*Operation)
+		}
+		doc.Paths[route.Path][strings.ToLower(route.Method)] = op
+	}
+	return doc
+}
+
+// operationID derives a stable identifier such as getCompaniesCompanyNumberPscs
+func operationID(route Route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, part := range strings.FieldsFunc(strings.TrimPrefix(route.Path, "/api"), func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '_' || r == '-' || r == '.'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+var (
+	specOnce sync.Once
+	spec     []byte
+)
+
+// SpecHandler handles GET /api/openapi.json
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	specOnce.Do(func() {
+		spec, _ = json.MarshalIndent(Build(Routes), "", "  ")
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(spec)
+}
@@ -0,0 +1,19 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// docsPage is a Swagger UI page for the spec. The Swagger UI assets are pinned to one release
+// and loaded from the unpkg CDN.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// DocsHandler handles GET /api/docs
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docsPage)
+}
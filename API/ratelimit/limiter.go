@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"data-co/api/auth"
+	"data-co/api/config"
+	"data-co/api/models"
+)
+
+const (
+	anonymousTier = "anonymous"
+	defaultTier   = "standard"
+
+	// idleTimeout is how long an unused bucket is kept before it is discarded
+	idleTimeout = 10 * time.Minute
+)
+
+// bucket is a caller's token bucket and when it was last used
+type bucket struct {
+	limiter  *rate.Limiter
+	limit    config.RateLimit
+	lastSeen time.Time
+}
+
+// Limiter applies token-bucket rate limits per API key, JWT subject or client IP
+type Limiter struct {
+	enabled           bool
+	tiers             map[string]config.RateLimit
+	trustForwardedFor bool
+	exempt            map[string]bool
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter creates a rate limiter from config. Requests to exemptPaths are never limited.
+func NewLimiter(cfg config.RateLimitConfig, exemptPaths ...string) *Limiter {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	tiers := make(map[string]config.RateLimit, len(cfg.Tiers)+2)
+	tiers[anonymousTier] = config.RateLimit{RequestsPerSecond: 2, Burst: 10}
+	tiers[defaultTier] = config.RateLimit{RequestsPerSecond: 10, Burst: 20}
+	for name, limit := range cfg.Tiers {
+		tiers[name] = limit
+	}
+
+	return &Limiter{
+		enabled:           cfg.Enabled,
+		tiers:             tiers,
+		trustForwardedFor: cfg.TrustForwardedFor,
+		exempt:            exempt,
+		buckets:           make(map[string]*bucket),
+		lastSweep:         time.Now(),
+	}
+}
+
+// KeyTier reports whether tier is configured and may be given to API keys and organizations.
+// The anonymous tier is reserved for unauthenticated requests.
+func (l *Limiter) KeyTier(tier string) bool {
+	_, ok := l.tiers[tier]
+	return ok && tier != anonymousTier
+}
+
+// Middleware rejects requests over the caller's limit with 429 and sets X-RateLimit-* headers.
+// It must run after authentication so the caller's principal is known.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.enabled || r.Method == http.MethodOptions || l.exempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, tier := l.identify(r)
+		b := l.bucketFor(key, tier)
+
+		now := time.Now()
+		reservation := b.limiter.ReserveN(now, 1)
+		delay := reservation.DelayFrom(now)
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(b.limit.Burst))
+
+		if delay > 0 {
+			// Give the token back; the request is rejected rather than delayed
+			reservation.CancelAt(now)
+
+			retryAfter := int(math.Ceil(delay.Seconds()))
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(delay).Unix(), 10))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			respondWithError(w, http.StatusTooManyRequests, "Rate limit exceeded",
+				fmt.Sprintf("Limit is %g requests/second with bursts of %d; retry after %d seconds", b.limit.RequestsPerSecond, b.limit.Burst, retryAfter))
+			return
+		}
+
+		remaining := b.limiter.TokensAt(now)
+		untilFull := time.Duration((float64(b.limit.Burst) - remaining) / b.limit.RequestsPerSecond * float64(time.Second))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(math.Max(remaining, 0))))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(untilFull).Unix(), 10))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// identify returns the bucket key and tier for the caller
+func (l *Limiter) identify(r *http.Request) (key, tier string) {
+	principal := auth.FromContext(r.Context())
+	switch {
+	case principal == nil:
+		return "ip:" + l.clientIP(r), anonymousTier
+	case principal.KeyID != 0:
+		return "key:" + strconv.Itoa(principal.KeyID), principal.Tier
+	default:
+		return "principal:" + principal.Name, principal.Tier
+	}
+}
+
+// bucketFor returns the caller's bucket, creating it on first use
+func (l *Limiter) bucketFor(key, tier string) *bucket {
+	limit, ok := l.tiers[tier]
+	if !ok {
+		limit = l.tiers[defaultTier]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{
+			limiter: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst),
+			limit:   limit,
+		}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b
+}
+
+// sweep discards idle buckets. It must be called with l.mu held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTimeout {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// clientIP returns the address anonymous callers are limited by
+func (l *Limiter) clientIP(r *http.Request) string {
+	if l.trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func respondWithError(w http.ResponseWriter, statusCode int, error string, message string) {
+	response, _ := json.Marshal(models.ErrorResponse{
+		Error:   error,
+		Message: message,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(response)
+}
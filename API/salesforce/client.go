@@ -0,0 +1,248 @@
+// Package salesforce upserts companies into Salesforce as Leads or Accounts through the REST
+// API, keyed on an external ID field holding the company number so repeated pushes update
+// rather than duplicate records.
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"data-co/api/config"
+	"data-co/api/integrations"
+	"data-co/api/models"
+)
+
+const (
+	// batchSize is the most records an sObject Collections request can upsert
+	batchSize = 200
+	// maxRetries bounds how often a request failing with a server error is retried
+	maxRetries = 3
+)
+
+// Objects that companies can be pushed as
+const (
+	ObjectAccount = "Account"
+	ObjectLead    = "Lead"
+)
+
+// Result is the outcome of upserting one company
+type Result struct {
+	CompanyNumber string
+	ID            string
+	Created       bool
+	Error         string
+}
+
+// Client upserts records through the Salesforce REST API, authenticating with the OAuth 2.0
+// client credentials flow of a connected app
+type Client struct {
+	cfg      config.SalesforceConfig
+	mappings map[string]integrations.Mapping
+	http     *http.Client
+
+	mu          sync.Mutex
+	token       string
+	instanceURL string
+}
+
+// NewClient creates a Salesforce client, checking its field mappings. It returns nil when no
+// connected app is configured.
+func NewClient(cfg config.SalesforceConfig) (*Client, error) {
+	if cfg.ClientID == "" {
+		return nil, nil
+	}
+	if cfg.LoginURL == "" || cfg.ClientSecret == "" {
+		return nil, errors.New("SALESFORCE_LOGIN_URL and SALESFORCE_CLIENT_SECRET are required with SALESFORCE_CLIENT_ID")
+	}
+
+	c := &Client{
+		cfg:      cfg,
+		mappings: make(map[string]integrations.Mapping),
+		http:     &http.Client{Timeout: cfg.Timeout},
+	}
+	for object, spec := range map[string]string{ObjectAccount: cfg.AccountFields, ObjectLead: cfg.LeadFields} {
+		mapping, err := integrations.ParseMapping(spec)
+		if err != nil {
+			return nil, fmt.Errorf("Salesforce %s fields: %w", object, err)
+		}
+		c.mappings[object] = mapping
+	}
+	return c, nil
+}
+
+// Enabled reports whether a connected app is configured
+func (c *Client) Enabled() bool {
+	return c != nil
+}
+
+// Upsert creates or updates an object for each company, matched on the external ID field.
+// Companies that Salesforce rejects are reported in their Result rather than failing the rest;
+// an error is returned only when a request fails as a whole.
+func (c *Client) Upsert(ctx context.Context, object string, companies []models.Company) ([]Result, error) {
+	mapping, ok := c.mappings[object]
+	if !ok {
+		return nil, fmt.Errorf("unknown object %q", object)
+	}
+
+	results := make([]Result, 0, len(companies))
+	for start := 0; start < len(companies); start += batchSize {
+		batch := companies[start:min(start+batchSize, len(companies))]
+		records := make([]map[string]any, len(batch))
+		for i, company := range batch {
+			record := mapping.Record(company)
+			record["attributes"] = map[string]string{"type": object}
+			record[c.cfg.ExternalIDField] = company.CompanyNumber
+			records[i] = record
+		}
+
+		var saved []struct {
+			ID      string `json:"id"`
+			Success bool   `json:"success"`
+			Created bool   `json:"created"`
+			Errors  []struct {
+				StatusCode string   `json:"statusCode"`
+				Message    string   `json:"message"`
+				Fields     []string `json:"fields"`
+			} `json:"errors"`
+		}
+		path := fmt.Sprintf("/services/data/%s/composite/sobjects/%s/%s", c.cfg.APIVersion, object, url.PathEscape(c.cfg.ExternalIDField))
+		body := map[string]any{"allOrNone": false, "records": records}
+		if err := c.do(ctx, http.MethodPatch, path, body, &saved); err != nil {
+			return results, err
+		}
+		if len(saved) != len(batch) {
+			return results, fmt.Errorf("Salesforce returned %d results for %d records", len(saved), len(batch))
+		}
+
+		for i, s := range saved {
+			result := Result{CompanyNumber: batch[i].CompanyNumber, ID: s.ID, Created: s.Created}
+			if !s.Success {
+				messages := make([]string, len(s.Errors))
+				for j, e := range s.Errors {
+					messages[j] = e.StatusCode + ": " + e.Message
+					if len(e.Fields) > 0 {
+						messages[j] += " (" + strings.Join(e.Fields, ", ") + ")"
+					}
+				}
+				result.Error = strings.Join(messages, "; ")
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// do sends a JSON request to the instance and decodes the response into v. An expired access
+// token is replaced once; server errors are retried with backoff.
+func (c *Client) do(ctx context.Context, method, path string, body, v any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	delay := time.Second
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		token, instanceURL, err := c.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, instanceURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("invalid request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || attempt == maxRetries {
+				return fmt.Errorf("%s %s failed: %w", method, path, err)
+			}
+		} else {
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			switch {
+			case resp.StatusCode < 300:
+				if err := json.Unmarshal(data, v); err != nil {
+					return fmt.Errorf("invalid response from %s: %w", path, err)
+				}
+				return nil
+			case resp.StatusCode == http.StatusUnauthorized && !refreshed:
+				c.clearToken(token)
+				refreshed = true
+				continue
+			case resp.StatusCode < 500 || attempt == maxRetries:
+				return fmt.Errorf("%s %s responded %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// accessToken returns a cached access token and the instance it is for, requesting one with
+// the client credentials flow when there is none
+func (c *Client) accessToken(ctx context.Context) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" {
+		return c.token, c.instanceURL, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.cfg.ClientID},
+		"client_secret": {c.cfg.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.cfg.LoginURL, "/")+"/services/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("Salesforce token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", "", fmt.Errorf("Salesforce token request responded %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		InstanceURL string `json:"instance_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", "", fmt.Errorf("invalid Salesforce token response: %v", err)
+	}
+	c.token, c.instanceURL = token.AccessToken, strings.TrimRight(token.InstanceURL, "/")
+	return c.token, c.instanceURL, nil
+}
+
+// clearToken drops a rejected access token, unless another request has already replaced it
+func (c *Client) clearToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
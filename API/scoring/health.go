@@ -0,0 +1,76 @@
+// Package scoring derives company ratings from staged financial and filing data.
+package scoring
+
+// Health bands, strongest first
+const (
+	HealthStrong   = "strong"
+	HealthModerate = "moderate"
+	HealthWeak     = "weak"
+)
+
+// Zone boundaries of the Altman Z-score variant for private, non-manufacturing companies
+const (
+	safeZone     = 2.6
+	distressZone = 1.1
+)
+
+// Financials are the balance sheet and profit figures a health score is computed from
+type Financials struct {
+	TotalAssets      *float64
+	TotalLiabilities *float64
+	NetCurrentAssets *float64 // Working capital
+	NetAssets        *float64 // Book equity
+	OperatingProfit  *float64 // EBIT
+	ProfitAfterTax   *float64 // Used when operating profit is not filed
+}
+
+// Health computes a financial health score, using the Altman Z-score variant for private
+// non-manufacturing companies, and its band. It returns false if the accounts do not carry
+// enough figures to score, which is common for micro-entity filings.
+//
+// Z = 6.56 X1 + 3.26 X2 + 6.72 X3 + 1.05 X4, where X1 is working capital, X2 retained
+// earnings and X3 EBIT, each over total assets, and X4 is book equity over total liabilities.
+// Retained earnings are not tagged in the accounts data, so X2 uses net assets, which for most
+// small private companies is almost entirely retained earnings.
+func Health(f Financials) (float64, string, bool) {
+	if f.TotalAssets == nil || *f.TotalAssets <= 0 || f.NetCurrentAssets == nil || f.NetAssets == nil {
+		return 0, "", false
+	}
+	ebit := f.OperatingProfit
+	if ebit == nil {
+		ebit = f.ProfitAfterTax
+	}
+	if ebit == nil {
+		return 0, "", false
+	}
+
+	assets := *f.TotalAssets
+	liabilities := assets - *f.NetAssets
+	if f.TotalLiabilities != nil {
+		liabilities = *f.TotalLiabilities
+	}
+
+	x1 := *f.NetCurrentAssets / assets
+	x2 := *f.NetAssets / assets
+	x3 := *ebit / assets
+	// A company with no liabilities is as solvent as this ratio can say; cap it rather than divide by zero
+	x4 := 10.0
+	if liabilities > 0 {
+		x4 = min(*f.NetAssets/liabilities, 10)
+	}
+
+	score := 6.56*x1 + 3.26*x2 + 6.72*x3 + 1.05*x4
+	switch {
+	case score > safeZone:
+		return score, HealthStrong, true
+	case score >= distressZone:
+		return score, HealthModerate, true
+	default:
+		return score, HealthWeak, true
+	}
+}
+
+// ValidHealth reports whether band is a health band
+func ValidHealth(band string) bool {
+	return band == HealthStrong || band == HealthModerate || band == HealthWeak
+}
@@ -0,0 +1,82 @@
+package scoring
+
+// Risk bands, lowest risk first
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// Risk flags, each naming one signal that contributed to a rating
+const (
+	FlagAccountsOverdue         = "accounts_overdue"
+	FlagConfirmationStmtOverdue = "confirmation_statement_overdue"
+	FlagNetWorthFalling         = "net_worth_falling"
+	FlagNegativeNetWorth        = "negative_net_worth"
+	FlagOutstandingCharges      = "outstanding_charges"
+	FlagOfficerChurn            = "officer_churn"
+)
+
+// Points at which a rating moves into the medium and high bands
+const (
+	mediumRiskPoints = 2
+	highRiskPoints   = 4
+)
+
+// RiskSignals are the filing, financial and officer facts a risk rating is computed from
+type RiskSignals struct {
+	AccountsOverdue         bool
+	ConfirmationStmtOverdue bool
+	NetWorth                *float64 // Latest period
+	PreviousNetWorth        *float64 // Period before the latest
+	OutstandingCharges      int      // Outstanding or part-satisfied
+	ActiveOfficers          int
+	RecentResignations      int // Officers resigned in the last 12 months
+}
+
+// Risk combines late filings, falling net worth, outstanding charges and officer churn into a
+// points-based rating. It returns the points, band and the flags that were raised, most
+// serious first.
+func Risk(s RiskSignals) (int, string, []string) {
+	points := 0
+	flags := make([]string, 0)
+	raise := func(flag string, weight int) {
+		points += weight
+		flags = append(flags, flag)
+	}
+
+	if s.AccountsOverdue {
+		raise(FlagAccountsOverdue, 3)
+	}
+	if s.NetWorth != nil && *s.NetWorth < 0 {
+		raise(FlagNegativeNetWorth, 2)
+	}
+	// A fall of more than a quarter of positive net worth, so small fluctuations are ignored
+	if s.NetWorth != nil && s.PreviousNetWorth != nil && *s.PreviousNetWorth > 0 && *s.NetWorth < *s.PreviousNetWorth*0.75 {
+		raise(FlagNetWorthFalling, 2)
+	}
+	// Losing at least two officers, and as many as remain, within a year
+	if s.RecentResignations >= 2 && s.RecentResignations >= s.ActiveOfficers {
+		raise(FlagOfficerChurn, 2)
+	}
+	if s.ConfirmationStmtOverdue {
+		raise(FlagConfirmationStmtOverdue, 1)
+	}
+	if s.OutstandingCharges > 0 {
+		raise(FlagOutstandingCharges, 1)
+	}
+
+	switch {
+	case points >= highRiskPoints:
+		return points, RiskHigh, flags
+	case points >= mediumRiskPoints:
+		return points, RiskMedium, flags
+	default:
+		return points, RiskLow, flags
+	}
+}
+
+// ValidRisk reports whether band is a risk band
+func ValidRisk(band string) bool {
+	return band == RiskLow || band == RiskMedium || band == RiskHigh
+}
@@ -0,0 +1,60 @@
+// Package sic provides the UK Standard Industrial Classification (SIC 2007) hierarchy that
+// Companies House SIC codes belong to. Company SIC codes are 5-digit subclasses; their first
+// four digits are the class, and their first two the division.
+package sic
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"data-co/api/models"
+)
+
+//go:embed sic2007.tsv
+var sic2007 string
+
+// sections is the parsed hierarchy. The data is compiled in, so a malformed file fails at startup.
+var sections = mustParse(sic2007)
+
+// Sections returns the SIC 2007 sections with their divisions and classes, in code order.
+// The result is shared and must not be modified.
+func Sections() []models.SICSection {
+	return sections
+}
+
+// mustParse parses tab-separated code and description lines, where each division follows its
+// section and each class its division
+func mustParse(data string) []models.SICSection {
+	var result []models.SICSection
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		code, description, ok := strings.Cut(line, "\t")
+		if !ok {
+			panic(fmt.Sprintf("sic2007.tsv line %d: missing description", n))
+		}
+
+		switch {
+		case len(code) == 1:
+			result = append(result, models.SICSection{Code: code, Description: description, Divisions: []models.SICDivision{}})
+		case len(code) == 2 && len(result) > 0:
+			section := &result[len(result)-1]
+			section.Divisions = append(section.Divisions, models.SICDivision{Code: code, Description: description, Classes: []models.SICClass{}})
+		case len(code) == 4 && len(result) > 0 && len(result[len(result)-1].Divisions) > 0:
+			divisions := result[len(result)-1].Divisions
+			division := &divisions[len(divisions)-1]
+			if !strings.HasPrefix(code, division.Code) {
+				panic(fmt.Sprintf("sic2007.tsv line %d: class %s is not in division %s", n, code, division.Code))
+			}
+			division.Classes = append(division.Classes, models.SICClass{Code: code, Description: description})
+		default:
+			panic(fmt.Sprintf("sic2007.tsv line %d: unexpected code %q", n, code))
+		}
+	}
+	return result
+}
@@ -0,0 +1,201 @@
+// Package slack posts watchlist changes to Slack incoming webhooks, one message per watchlist for
+// each change detection run.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+const (
+	// maxLines bounds how many changes one message lists
+	maxLines = 20
+	// maxRetries bounds how often a rate-limited or failed post is retried
+	maxRetries = 3
+	// registerURL is where company names link to
+	registerURL = "https://find-and-update.company-information.service.gov.uk/company/"
+)
+
+// Notifier posts changes to the Slack incoming webhooks of the watchlists holding the companies
+type Notifier struct {
+	db   *database.DB
+	http *http.Client
+}
+
+// NewNotifier creates a Slack notifier
+func NewNotifier(db *database.DB, cfg config.SlackConfig) *Notifier {
+	return &Notifier{db: db, http: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// ValidWebhookURL reports whether raw is a Slack incoming webhook URL
+func ValidWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host == "hooks.slack.com" && strings.HasPrefix(u.Path, "/services/")
+}
+
+// Notify posts the filings, status changes and officer changes among changes to every watchlist
+// holding the companies. It is registered as a change detector listener; failed posts are
+// logged and not retried on later runs.
+func (n *Notifier) Notify(ctx context.Context, changes []models.CompanyChange) {
+	byCompany := make(map[string][]models.CompanyChange)
+	for _, change := range changes {
+		if _, ok := describe(change); ok {
+			byCompany[change.CompanyNumber] = append(byCompany[change.CompanyNumber], change)
+		}
+	}
+	if len(byCompany) == 0 {
+		return
+	}
+	numbers := make([]string, 0, len(byCompany))
+	for number := range byCompany {
+		numbers = append(numbers, number)
+	}
+
+	watchlists, err := n.db.SlackWatchlistsFor(ctx, numbers)
+	if err != nil {
+		log.Printf("Slack notification error: %v", err)
+		return
+	}
+	if len(watchlists) == 0 {
+		return
+	}
+	names, err := n.db.CompanyNames(ctx, numbers)
+	if err != nil {
+		log.Printf("Slack notification error: %v", err)
+		names = make(map[string]string)
+	}
+
+	for _, w := range watchlists {
+		var listed []models.CompanyChange
+		for _, number := range w.CompanyNumbers {
+			listed = append(listed, byCompany[number]...)
+		}
+		if err := n.Post(ctx, w.WebhookURL, message(w, listed, names)); err != nil {
+			log.Printf("Slack notification for watchlist %d failed: %v", w.ID, err)
+		}
+	}
+}
+
+// Post sends a message to an incoming webhook. Rate-limited (429) and server error responses
+// are retried, waiting as long as Retry-After asks when it is given.
+func (n *Notifier) Post(ctx context.Context, webhookURL, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("invalid request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := n.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || attempt == maxRetries {
+				return err
+			}
+		} else {
+			data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			resp.Body.Close()
+
+			switch {
+			case resp.StatusCode < 300:
+				return nil
+			case (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500) || attempt == maxRetries:
+				// Slack explains rejections in the body, e.g. "invalid_token" or "channel_is_archived"
+				return fmt.Errorf("Slack responded %s: %s", resp.Status, strings.TrimSpace(string(data)))
+			}
+			if wait, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil {
+				delay = time.Duration(wait) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// message formats a watchlist's changes in Slack markup, listing the first maxLines
+func message(w database.SlackWatchlist, changes []models.CompanyChange, names map[string]string) string {
+	var b strings.Builder
+	noun := "changes"
+	if len(changes) == 1 {
+		noun = "change"
+	}
+	fmt.Fprintf(&b, "*%s*: %d %s", Escape(w.Name), len(changes), noun)
+
+	for i, change := range changes {
+		if i == maxLines {
+			fmt.Fprintf(&b, "\n…and %d more; see GET /api/watchlists/%d/changes", len(changes)-maxLines, w.ID)
+			break
+		}
+		name := names[change.CompanyNumber]
+		if name == "" {
+			name = change.CompanyNumber
+		}
+		description, _ := describe(change)
+		fmt.Fprintf(&b, "\n• <%s%s|%s> (%s) %s", registerURL, change.CompanyNumber, Escape(name), change.CompanyNumber, description)
+	}
+	return b.String()
+}
+
+// describe says what a change is in words, or returns false for change types not posted
+func describe(change models.CompanyChange) (string, bool) {
+	value := func(s *string) string {
+		if s == nil {
+			return "none"
+		}
+		return Escape(*s)
+	}
+	count := func() int {
+		if change.OldValue == nil || change.NewValue == nil {
+			return 0
+		}
+		oldCount, _ := strconv.Atoi(*change.OldValue)
+		newCount, _ := strconv.Atoi(*change.NewValue)
+		return newCount - oldCount
+	}
+	officers := func(n int) string {
+		if n == 1 {
+			return "1 officer"
+		}
+		return strconv.Itoa(n) + " officers"
+	}
+
+	switch change.ChangeType {
+	case "new_accounts":
+		return "filed accounts made up to " + value(change.NewValue), true
+	case "status_changed":
+		return fmt.Sprintf("changed status from %s to %s", value(change.OldValue), value(change.NewValue)), true
+	case "officer_appointed":
+		return "appointed " + officers(count()), true
+	case "officer_resigned":
+		return "had " + officers(count()) + " resign", true
+	}
+	return "", false
+}
+
+// Escape escapes the characters Slack treats as markup
+func Escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
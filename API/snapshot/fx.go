@@ -0,0 +1,110 @@
+package snapshot
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/database"
+)
+
+// FXReader yields GBP exchange rates from the ECB's euro foreign exchange reference rates
+// (eurofxref-hist.csv, either plain or inside the published ZIP archive): a Date column and one
+// column per currency giving its units per euro, with N/A where none was published. Each rate
+// is crossed through the euro rate of sterling.
+type FXReader struct {
+	*csvFile
+	currencies []string // By column; "" for the date and columns that are not currencies
+	date, gbp  int
+	pending    []database.FXRate
+}
+
+// OpenFX opens an ECB reference rates file (.zip or .csv) and reads its header row
+func OpenFX(path string) (*FXReader, error) {
+	f, header, err := openCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &FXReader{csvFile: f, currencies: make([]string, len(header)), date: -1, gbp: -1}
+
+	for i, name := range header {
+		name = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch {
+		case name == "DATE":
+			r.date = i
+		case name == "GBP":
+			r.gbp = i
+		case len(name) == 3 && strings.Trim(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "":
+			r.currencies[i] = name
+		}
+	}
+	if r.date < 0 || r.gbp < 0 {
+		r.Close()
+		return nil, fmt.Errorf("%s is not an ECB reference rates file (no Date or GBP column)", path)
+	}
+
+	return r, nil
+}
+
+// Next returns the next rate. It returns io.EOF after the last row. Malformed rows are returned
+// as a *RowError, after which reading can continue.
+func (r *FXReader) Next() (database.FXRate, error) {
+	for len(r.pending) == 0 {
+		if err := r.readDay(); err != nil {
+			return database.FXRate{}, err
+		}
+	}
+	rate := r.pending[0]
+	r.pending = r.pending[1:]
+	return rate, nil
+}
+
+// readDay queues the rates of the next row: the euro's, then every other currency's published
+// that day
+func (r *FXReader) readDay() error {
+	record, err := r.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &RowError{Line: parseErr.Line, Err: parseErr.Err}
+		}
+		return err
+	}
+	line, _ := r.csv.FieldPos(0)
+
+	var date time.Time
+	if r.date < len(record) {
+		date, err = time.Parse("2006-01-02", strings.TrimSpace(record[r.date]))
+	}
+	if date.IsZero() || err != nil {
+		return &RowError{Line: line, Err: errors.New("invalid or missing date")}
+	}
+	gbp, ok := perEuro(record, r.gbp)
+	if !ok {
+		// Sterling was not published that day, so no rate can be crossed through it
+		return nil
+	}
+
+	r.pending = append(r.pending[:0], database.FXRate{Currency: "EUR", Date: date, GBPRate: gbp})
+	for i, currency := range r.currencies {
+		if currency == "" {
+			continue
+		}
+		if units, ok := perEuro(record, i); ok {
+			r.pending = append(r.pending, database.FXRate{Currency: currency, Date: date, GBPRate: gbp / units})
+		}
+	}
+	return nil
+}
+
+// perEuro parses the units per euro in column i, reporting false for N/A or a missing column
+func perEuro(record []string, i int) (float64, bool) {
+	if i >= len(record) {
+		return 0, false
+	}
+	units, err := strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+	return units, err == nil && units > 0
+}
@@ -0,0 +1,119 @@
+package snapshot
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/database"
+)
+
+// noGridReference is the latitude the ONS Postcode Directory gives postcodes without coordinates
+const noGridReference = 99.999999
+
+// PostcodeReader yields postcode coordinates, with their country and region codes, from an ONS
+// Postcode Directory CSV, either plain or inside the published ZIP archive
+type PostcodeReader struct {
+	*csvFile
+	postcode, lat, long, doterm int
+	country, region             int // -1 for directories without them
+}
+
+// OpenPostcodes opens an ONS Postcode Directory file (.zip or .csv) and reads its header row
+func OpenPostcodes(path string) (*PostcodeReader, error) {
+	f, header, err := openCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &PostcodeReader{csvFile: f, postcode: -1, lat: -1, long: -1, doterm: -1, country: -1, region: -1}
+
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "pcds":
+			r.postcode = i
+		case "pcd":
+			if r.postcode < 0 {
+				r.postcode = i
+			}
+		case "lat":
+			r.lat = i
+		case "long":
+			r.long = i
+		case "doterm":
+			r.doterm = i
+		case "ctry":
+			r.country = i
+		case "rgn":
+			r.region = i
+		}
+	}
+	if r.postcode < 0 || r.lat < 0 || r.long < 0 {
+		r.Close()
+		return nil, fmt.Errorf("%s is not an ONS Postcode Directory file (no pcds, lat or long column)", path)
+	}
+
+	return r, nil
+}
+
+// Next returns the next postcode with coordinates, skipping those the directory has none for.
+// It returns io.EOF after the last row. Malformed rows are returned as a *RowError, after
+// which reading can continue.
+func (r *PostcodeReader) Next() (database.Postcode, error) {
+	for {
+		record, err := r.csv.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return database.Postcode{}, &RowError{Line: parseErr.Line, Err: parseErr.Err}
+			}
+			return database.Postcode{}, err
+		}
+		line, _ := r.csv.FieldPos(0)
+
+		field := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		p := database.Postcode{Postcode: normalizePostcode(field(r.postcode))}
+		if p.Postcode == "" {
+			return database.Postcode{}, &RowError{Line: line, Err: errors.New("missing postcode")}
+		}
+		lat, latErr := strconv.ParseFloat(field(r.lat), 64)
+		long, longErr := strconv.ParseFloat(field(r.long), 64)
+		if latErr != nil || longErr != nil {
+			return database.Postcode{}, &RowError{Line: line, Err: fmt.Errorf("invalid coordinates for %s", p.Postcode)}
+		}
+		if lat == noGridReference {
+			continue
+		}
+		p.Latitude, p.Longitude = lat, long
+
+		// Terminations are year and month, e.g. "200012"
+		if t, err := time.Parse("200601", field(r.doterm)); err == nil {
+			p.TerminatedOn = &t
+		}
+		p.CountryCode, p.RegionCode = areaCode(field(r.country)), areaCode(field(r.region))
+		return p, nil
+	}
+}
+
+// areaCode returns an ONS area code, e.g. "E12000002", or nil if there is none
+func areaCode(s string) *string {
+	if s == "" {
+		return nil
+	}
+	code := strings.ToUpper(s)
+	return &code
+}
+
+// normalizePostcode upper-cases a postcode and removes its spaces, e.g. "sw1a 1aa" -> "SW1A1AA",
+// matching how postcode_lookup is keyed
+func normalizePostcode(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), ""))
+}
@@ -0,0 +1,295 @@
+package snapshot
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"data-co/api/companieshouse"
+	"data-co/api/database"
+)
+
+// pscTypes maps Companies House PSC kinds to the simplified psc_type stored in staging_pscs
+var pscTypes = map[string]string{
+	"individual-person-with-significant-control":       "individual",
+	"individual-beneficial-owner":                      "individual",
+	"corporate-entity-person-with-significant-control": "corporate",
+	"corporate-entity-beneficial-owner":                "corporate",
+	"legal-person-person-with-significant-control":     "legal_person",
+	"legal-person-beneficial-owner":                    "legal_person",
+	"super-secure-person-with-significant-control":     "super_secure",
+	"super-secure-beneficial-owner":                    "super_secure",
+	"persons-with-significant-control-statement":       "statement",
+	"exemptions": "exemption",
+}
+
+// pscData is the subset of a PSC record stored in staging
+type pscData struct {
+	Kind         string `json:"kind"`
+	Name         string `json:"name"`
+	NameElements struct {
+		Title      string `json:"title"`
+		Forename   string `json:"forename"`
+		MiddleName string `json:"middle_name"`
+		Surname    string `json:"surname"`
+	} `json:"name_elements"`
+	Nationality        *string `json:"nationality"`
+	CountryOfResidence *string `json:"country_of_residence"`
+	DateOfBirth        *struct {
+		Month int `json:"month"`
+		Year  int `json:"year"`
+	} `json:"date_of_birth"`
+	NaturesOfControl []string `json:"natures_of_control"`
+	NotifiedOn       *string  `json:"notified_on"`
+	CeasedOn         *string  `json:"ceased_on"`
+	Statement        *string  `json:"statement"`
+	Identification   struct {
+		LegalForm          *string `json:"legal_form"`
+		RegistrationNumber *string `json:"registration_number"`
+		PlaceRegistered    *string `json:"place_registered"`
+		CountryRegistered  *string `json:"country_registered"`
+	} `json:"identification"`
+	Address struct {
+		AddressLine1 *string `json:"address_line_1"`
+		Locality     *string `json:"locality"`
+		PostalCode   *string `json:"postal_code"`
+		Country      *string `json:"country"`
+	} `json:"address"`
+	Links struct {
+		Self string `json:"self"`
+	} `json:"links"`
+}
+
+// ParsePSC converts a PSC record, as published in both the PSC snapshot and the streaming
+// API, into a staging row. It returns false for records that are not PSCs, such as the
+// snapshot's trailing totals record.
+func ParsePSC(companyNumber string, data json.RawMessage) (database.StagingPSC, bool, error) {
+	var d pscData
+	if err := json.Unmarshal(data, &d); err != nil {
+		return database.StagingPSC{}, false, fmt.Errorf("invalid PSC record: %w", err)
+	}
+
+	pscType, ok := pscTypes[d.Kind]
+	if !ok || companyNumber == "" {
+		return database.StagingPSC{}, false, nil
+	}
+
+	pscID := path.Base(d.Links.Self)
+	if d.Links.Self == "" || pscID == "/" || pscID == "." {
+		return database.StagingPSC{}, false, fmt.Errorf("PSC record for %s has no self link", companyNumber)
+	}
+
+	p := database.StagingPSC{
+		CompanyNumber:      companyNumber,
+		PSCID:              pscID,
+		Kind:               d.Kind,
+		PSCType:            pscType,
+		Nationality:        d.Nationality,
+		CountryOfResidence: d.CountryOfResidence,
+		NaturesOfControl:   make([]string, 0, len(d.NaturesOfControl)),
+		NotifiedOn:         d.NotifiedOn,
+		CeasedOn:           d.CeasedOn,
+		Statement:          d.Statement,
+		LegalForm:          d.Identification.LegalForm,
+		RegistrationNumber: d.Identification.RegistrationNumber,
+		PlaceRegistered:    d.Identification.PlaceRegistered,
+		CountryRegistered:  d.Identification.CountryRegistered,
+		AddressLine1:       d.Address.AddressLine1,
+		Locality:           d.Address.Locality,
+		PostalCode:         d.Address.PostalCode,
+		Country:            d.Address.Country,
+		RawData:            data,
+	}
+
+	name := strings.TrimSpace(d.Name)
+	if name == "" {
+		parts := make([]string, 0, 4)
+		for _, part := range []string{d.NameElements.Title, d.NameElements.Forename, d.NameElements.MiddleName, d.NameElements.Surname} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		name = strings.Join(parts, " ")
+	}
+	if name != "" {
+		p.Name = &name
+	}
+
+	if dob := d.DateOfBirth; dob != nil && dob.Year > 0 && dob.Month > 0 {
+		date := fmt.Sprintf("%04d-%02d-01", dob.Year, dob.Month)
+		p.DateOfBirth = &date
+	}
+
+	if pscType == "corporate" {
+		p.ParentCompanyNumber = parentCompanyNumber(d.Identification.RegistrationNumber, d.Identification.PlaceRegistered, d.Identification.CountryRegistered)
+	}
+
+	for _, nature := range d.NaturesOfControl {
+		if nature != "" {
+			p.NaturesOfControl = append(p.NaturesOfControl, nature)
+		}
+	}
+
+	p.DataHash = p.Hash()
+	return p, true, nil
+}
+
+// ukCompanyNumber matches company numbers issued by Companies House: eight digits, or two
+// letters (e.g. SC for Scotland, OC for LLPs) and six digits
+var ukCompanyNumber = regexp.MustCompile(`^([0-9]{8}|[A-Z]{2}[0-9]{6})$`)
+
+// ukRegisters are words in a corporate PSC's place or country of registration that mean it is
+// on the Companies House register
+var ukRegisters = []string{
+	"companies house", "registrar of companies", "united kingdom", "great britain", "england", "wales", "scotland", "northern ireland", "uk",
+}
+
+// parentCompanyNumber returns the company number of a corporate PSC that is registered at
+// Companies House, so the company it controls can be linked to it as a subsidiary, or nil for
+// foreign entities and unrecognized registration numbers. A PSC with no place or country of
+// registration is assumed to be on the UK register if its number looks like one.
+func parentCompanyNumber(registrationNumber, placeRegistered, countryRegistered *string) *string {
+	if registrationNumber == nil {
+		return nil
+	}
+	number := companieshouse.NormalizeCompanyNumber(strings.Join(strings.Fields(*registrationNumber), ""))
+	if !ukCompanyNumber.MatchString(number) {
+		return nil
+	}
+
+	registered := strings.ToLower(strings.TrimSpace(deref(placeRegistered) + " " + deref(countryRegistered)))
+	if registered != "" {
+		words := " " + strings.Join(strings.FieldsFunc(registered, func(r rune) bool {
+			return !('a' <= r && r <= 'z')
+		}), " ") + " "
+		uk := false
+		for _, register := range ukRegisters {
+			if strings.Contains(words, " "+register+" ") {
+				uk = true
+				break
+			}
+		}
+		if !uk {
+			return nil
+		}
+	}
+	return &number
+}
+
+// PSCReader yields staging rows from a PSC snapshot: line-delimited JSON records, either
+// plain or inside the published ZIP archive
+type PSCReader struct {
+	scanner *bufio.Scanner
+	closers []io.Closer
+	line    int
+
+	size int64
+	read atomic.Int64
+}
+
+// OpenPSC opens a PSC snapshot file (.zip, .txt or .json)
+func OpenPSC(file string) (*PSCReader, error) {
+	r := &PSCReader{}
+
+	var source io.Reader
+	if strings.EqualFold(filepath.Ext(file), ".zip") {
+		archive, err := zip.OpenReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file, err)
+		}
+		r.closers = append(r.closers, archive)
+		if len(archive.File) == 0 {
+			r.Close()
+			return nil, fmt.Errorf("%s is empty", file)
+		}
+
+		entry := archive.File[0]
+		rc, err := entry.Open()
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to open %s in %s: %w", entry.Name, file, err)
+		}
+		r.closers = append(r.closers, rc)
+		r.size = int64(entry.UncompressedSize64)
+		source = rc
+	} else {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file, err)
+		}
+		r.closers = append(r.closers, f)
+		if info, err := f.Stat(); err == nil {
+			r.size = info.Size()
+		}
+		source = f
+	}
+
+	r.scanner = bufio.NewScanner(&countingReader{r: source, n: &r.read})
+	r.scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	return r, nil
+}
+
+// Next returns the next PSC. It returns io.EOF after the last record. Malformed records are
+// returned as a *RowError, after which reading can continue.
+func (r *PSCReader) Next() (database.StagingPSC, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record struct {
+			CompanyNumber string          `json:"company_number"`
+			Data          json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return database.StagingPSC{}, &RowError{Line: r.line, Err: err}
+		}
+		if len(record.Data) == 0 {
+			continue
+		}
+
+		psc, ok, err := ParsePSC(record.CompanyNumber, append(json.RawMessage(nil), record.Data...))
+		if err != nil {
+			return database.StagingPSC{}, &RowError{Line: r.line, Err: err}
+		}
+		if ok {
+			return psc, nil
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return database.StagingPSC{}, fmt.Errorf("line %d: %w", r.line+1, err)
+	}
+	return database.StagingPSC{}, io.EOF
+}
+
+// Progress returns the fraction of the file consumed, or -1 if the size is unknown
+func (r *PSCReader) Progress() float64 {
+	if r.size <= 0 {
+		return -1
+	}
+	return min(float64(r.read.Load())/float64(r.size), 1)
+}
+
+// Close releases the underlying file
+func (r *PSCReader) Close() error {
+	var first error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if err := r.closers[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	r.closers = nil
+	return first
+}
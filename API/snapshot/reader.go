@@ -0,0 +1,303 @@
+// Package snapshot parses bulk snapshot files: the Companies House BasicCompanyData CSV and PSC
+// snapshot, the ONS Postcode Directory, VAT number lookups, the ECB's reference FX rates and
+// the Companies House SIC code list.
+package snapshot
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"data-co/api/database"
+)
+
+// Reader yields staging rows from a BasicCompanyData CSV, either plain or inside the
+// published ZIP archive
+type Reader struct {
+	*csvFile
+	columns map[string]int
+}
+
+// Open opens a snapshot file (.zip or .csv) and reads its header row
+func Open(path string) (*Reader, error) {
+	f, header, err := openCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{csvFile: f}
+
+	// Column names in the published file carry leading spaces, e.g. " CompanyNumber"
+	r.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		r.columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := r.columns["CompanyNumber"]; !ok {
+		r.Close()
+		return nil, fmt.Errorf("%s is not a BasicCompanyData file (no CompanyNumber column)", path)
+	}
+
+	return r, nil
+}
+
+// csvFile is a CSV being read from disk, either plain or the largest CSV inside a ZIP archive
+type csvFile struct {
+	csv     *csv.Reader
+	closers []io.Closer
+
+	size int64        // Uncompressed size of the CSV, if known
+	read atomic.Int64 // Uncompressed bytes consumed so far
+}
+
+// openCSV opens a .zip or .csv file and reads its header row
+func openCSV(path string) (*csvFile, []string, error) {
+	r := &csvFile{}
+
+	var source io.Reader
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		r.closers = append(r.closers, archive)
+
+		// Archives such as the ONS Postcode Directory also hold smaller CSVs of lookup codes
+		var entry *zip.File
+		for _, f := range archive.File {
+			if strings.EqualFold(filepath.Ext(f.Name), ".csv") && (entry == nil || f.UncompressedSize64 > entry.UncompressedSize64) {
+				entry = f
+			}
+		}
+		if entry == nil {
+			r.Close()
+			return nil, nil, fmt.Errorf("no CSV file found in %s", path)
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			r.Close()
+			return nil, nil, fmt.Errorf("failed to open %s in %s: %w", entry.Name, path, err)
+		}
+		r.closers = append(r.closers, rc)
+		r.size = int64(entry.UncompressedSize64)
+		source = rc
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		r.closers = append(r.closers, f)
+		if info, err := f.Stat(); err == nil {
+			r.size = info.Size()
+		}
+		source = f
+	}
+
+	// A leading byte order mark would otherwise be read as part of the first header, and breaks
+	// parsing if that header is quoted, as in the Companies House SIC code list
+	buffered := bufio.NewReader(&countingReader{r: source, n: &r.read})
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\ufeff" {
+		buffered.Discard(3)
+	}
+	r.csv = csv.NewReader(buffered)
+	r.csv.ReuseRecord = true
+	r.csv.FieldsPerRecord = -1
+
+	header, err := r.csv.Read()
+	if err != nil {
+		r.Close()
+		return nil, nil, fmt.Errorf("failed to read header of %s: %w", path, err)
+	}
+	return r, header, nil
+}
+
+// Next returns the next company. It returns io.EOF after the last row. Malformed rows are
+// returned as a *RowError, after which reading can continue.
+func (r *Reader) Next() (database.StagingCompany, error) {
+	record, err := r.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return database.StagingCompany{}, &RowError{Line: parseErr.Line, Err: parseErr.Err}
+		}
+		return database.StagingCompany{}, err
+	}
+
+	company := r.company(record)
+	if company.CompanyNumber == "" {
+		line, _ := r.csv.FieldPos(0)
+		return database.StagingCompany{}, &RowError{Line: line, Err: errors.New("missing company number")}
+	}
+	return company, nil
+}
+
+// Progress returns the fraction of the file consumed, or -1 if the size is unknown
+func (r *csvFile) Progress() float64 {
+	if r.size <= 0 {
+		return -1
+	}
+	return min(float64(r.read.Load())/float64(r.size), 1)
+}
+
+// Close releases the underlying file
+func (r *csvFile) Close() error {
+	var first error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if err := r.closers[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	r.closers = nil
+	return first
+}
+
+// RowError reports a row that could not be parsed
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string { return fmt.Sprintf("line %d: %v", e.Line, e.Err) }
+
+// company maps a CSV record to a staging row, applying the same normalisation as the
+// Python CompanyDataParser so both loaders produce identical rows and hashes
+func (r *Reader) company(record []string) database.StagingCompany {
+	field := func(name string) *string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(record) || record[i] == "" {
+			return nil
+		}
+		value := record[i]
+		return &value
+	}
+
+	c := database.StagingCompany{
+		CompanyNumber:          strings.TrimSpace(record[r.columns["CompanyNumber"]]),
+		CompanyName:            field("CompanyName"),
+		CompanyStatus:          lowerTrim(field("CompanyStatus")),
+		CompanyType:            field("CompanyCategory"),
+		Locality:               field("RegAddress.PostTown"),
+		PostalCode:             field("RegAddress.PostCode"),
+		AddressLine1:           field("RegAddress.AddressLine1"),
+		AddressLine2:           field("RegAddress.AddressLine2"),
+		Region:                 field("RegAddress.County"),
+		Country:                field("RegAddress.Country"),
+		IncorporationDate:      date(field("IncorporationDate")),
+		DissolvedOn:            date(field("DissolutionDate")),
+		AccountsLastMadeUpDate: date(field("Accounts.LastMadeUpDate")),
+		AccountsNextDueDate:    date(field("Accounts.NextDueDate")),
+		AccountCategory:        field("Accounts.AccountCategory"),
+		ReturnsNextDueDate:     date(field("Returns.NextDueDate")),
+		ReturnsLastMadeUpDate:  date(field("Returns.LastMadeUpDate")),
+		NumMortCharges:         integer(field("Mortgages.NumMortCharges")),
+		NumMortOutstanding:     integer(field("Mortgages.NumMortOutstanding")),
+		NumMortPartSatisfied:   integer(field("Mortgages.NumMortPartSatisfied")),
+		ConfStmtNextDueDate:    date(field("ConfStmtNextDueDate")),
+		ConfStmtLastMadeUpDate: date(field("ConfStmtLastMadeUpDate")),
+		SICCodes:               []string{},
+	}
+
+	// "62020 - Information technology consultancy activities" -> "62020"
+	for i := 1; i <= 4; i++ {
+		if text := field(fmt.Sprintf("SICCode.SicText_%d", i)); text != nil {
+			code, _, _ := strings.Cut(*text, " - ")
+			if code = strings.TrimSpace(code); code != "" {
+				c.SICCodes = append(c.SICCodes, code)
+			}
+		}
+	}
+
+	// Accounting reference day and month -> "MM-DD"
+	day, dayErr := strconv.ParseFloat(deref(field("Accounts.AccountRefDay")), 64)
+	month, monthErr := strconv.ParseFloat(deref(field("Accounts.AccountRefMonth")), 64)
+	if dayErr == nil && monthErr == nil {
+		refDate := fmt.Sprintf("%02d-%02d", int(month), int(day))
+		c.AccountsRefDate = &refDate
+	}
+
+	// Previous names, most recent first, each with the date it was changed (CONDATE). A name
+	// took effect when the one before it was changed, or on incorporation for the first name.
+	var names []string
+	for i := 1; i <= 10; i++ {
+		if name := strings.TrimSpace(deref(field(fmt.Sprintf("PreviousName_%d.CompanyName", i)))); name != "" {
+			names = append(names, name)
+			c.PreviousNameHistory = append(c.PreviousNameHistory, database.StagingPreviousName{
+				Name:     name,
+				CeasedOn: date(field(fmt.Sprintf("PreviousName_%d.CONDATE", i))),
+			})
+		}
+	}
+	for i := range c.PreviousNameHistory {
+		if i+1 < len(c.PreviousNameHistory) {
+			c.PreviousNameHistory[i].EffectiveFrom = c.PreviousNameHistory[i+1].CeasedOn
+		} else {
+			c.PreviousNameHistory[i].EffectiveFrom = c.IncorporationDate
+		}
+	}
+	if len(names) > 0 {
+		joined := strings.Join(names, "|")
+		c.PreviousNames = &joined
+	}
+
+	c.DataHash = c.Hash()
+	return c
+}
+
+// date converts a DD/MM/YYYY snapshot date to YYYY-MM-DD, or nil if it is not a valid date
+func date(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t, err := time.Parse("02/01/2006", strings.TrimSpace(*s))
+	if err != nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02")
+	return &formatted
+}
+
+func integer(s *string) *int {
+	if s == nil {
+		return nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(*s))
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+func lowerTrim(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	l := strings.ToLower(strings.TrimSpace(*s))
+	return &l
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// countingReader counts bytes read so progress can be reported
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,72 @@
+package snapshot
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+
+	"data-co/api/database"
+)
+
+// SICReader yields SIC codes from the Companies House condensed SIC 2007 list
+// (SIC07_CH_condensed_list_en.csv, either plain or inside a ZIP archive), with SIC Code and
+// Description columns
+type SICReader struct {
+	*csvFile
+	code, description int
+}
+
+// OpenSIC opens a SIC code list (.zip or .csv) and reads its header row
+func OpenSIC(path string) (*SICReader, error) {
+	f, header, err := openCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &SICReader{csvFile: f, code: -1, description: -1}
+
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "sic code", "sic_code", "code":
+			r.code = i
+		case "description", "sic description", "sic_description":
+			r.description = i
+		}
+	}
+	if r.code < 0 || r.description < 0 {
+		r.Close()
+		return nil, fmt.Errorf("%s is not a SIC code list (no SIC Code or Description column)", path)
+	}
+
+	return r, nil
+}
+
+// Next returns the next SIC code. It returns io.EOF after the last row. Malformed rows are
+// returned as a *RowError, after which reading can continue.
+func (r *SICReader) Next() (database.SICCode, error) {
+	record, err := r.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return database.SICCode{}, &RowError{Line: parseErr.Line, Err: parseErr.Err}
+		}
+		return database.SICCode{}, err
+	}
+	line, _ := r.csv.FieldPos(0)
+
+	field := func(i int) string {
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	c := database.SICCode{Code: field(r.code), Description: field(r.description)}
+	if len(c.Code) != 5 || strings.Trim(c.Code, "0123456789") != "" {
+		return database.SICCode{}, &RowError{Line: line, Err: fmt.Errorf("invalid SIC code %q", c.Code)}
+	}
+	if c.Description == "" {
+		return database.SICCode{}, &RowError{Line: line, Err: fmt.Errorf("missing description for %s", c.Code)}
+	}
+	return c, nil
+}
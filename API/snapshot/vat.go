@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+
+	"data-co/api/companieshouse"
+	"data-co/api/database"
+)
+
+// VATReader yields company VAT numbers from a bulk lookup CSV, either plain or inside a ZIP
+// archive, with company_number and vat_number columns
+type VATReader struct {
+	*csvFile
+	company, vat int
+}
+
+// OpenVAT opens a VAT lookup file (.zip or .csv) and reads its header row
+func OpenVAT(path string) (*VATReader, error) {
+	f, header, err := openCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &VATReader{csvFile: f, company: -1, vat: -1}
+
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "company_number", "companynumber":
+			r.company = i
+		case "vat_number", "vatnumber", "vrn":
+			r.vat = i
+		}
+	}
+	if r.company < 0 || r.vat < 0 {
+		r.Close()
+		return nil, fmt.Errorf("%s is not a VAT lookup file (no company_number or vat_number column)", path)
+	}
+
+	return r, nil
+}
+
+// Next returns the next company's VAT number. It returns io.EOF after the last row. Malformed
+// rows are returned as a *RowError, after which reading can continue.
+func (r *VATReader) Next() (database.VATNumber, error) {
+	record, err := r.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return database.VATNumber{}, &RowError{Line: parseErr.Line, Err: parseErr.Err}
+		}
+		return database.VATNumber{}, err
+	}
+	line, _ := r.csv.FieldPos(0)
+
+	field := func(i int) string {
+		if i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	n := database.VATNumber{CompanyNumber: companieshouse.NormalizeCompanyNumber(field(r.company))}
+	if n.CompanyNumber == "" {
+		return database.VATNumber{}, &RowError{Line: line, Err: errors.New("missing company number")}
+	}
+	var ok bool
+	if n.VATNumber, ok = NormalizeVATNumber(field(r.vat)); !ok {
+		return database.VATNumber{}, &RowError{Line: line, Err: fmt.Errorf("invalid VAT number for %s", n.CompanyNumber)}
+	}
+	return n, nil
+}
+
+// NormalizeVATNumber removes the spaces and GB prefix of a UK VAT registration number, e.g.
+// "GB 123 4567 89" -> "123456789", reporting whether it is 9 digits, or 12 for a group member
+func NormalizeVATNumber(s string) (string, bool) {
+	s = strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	s = strings.TrimPrefix(s, "GB")
+	return s, (len(s) == 9 || len(s) == 12) && strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,396 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-co/api/config"
+)
+
+const (
+	// partSize is the size of each part of a multipart upload; files no larger are uploaded
+	// with a single PUT. S3 allows at most 10,000 parts, so files of up to ~640GB can be uploaded.
+	partSize = 64 << 20
+	// unsignedPayload skips hashing request bodies, which are sent over TLS
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// MaxURLExpiry is the longest validity SigV4 allows for a signed URL
+	MaxURLExpiry = 7 * 24 * time.Hour
+)
+
+// Bucket keeps export files in an S3 bucket, or a GCS bucket through its S3-compatible XML
+// API, signing requests with AWS Signature Version 4. Downloads are redirected to signed URLs,
+// so files never pass through the API.
+type Bucket struct {
+	name      string
+	prefix    string
+	region    string
+	endpoint  *url.URL
+	pathStyle bool // Address the bucket in the path rather than as a subdomain
+	accessKey string
+	secretKey string
+	urlExpiry time.Duration
+	client    *http.Client
+}
+
+// NewBucket creates a store for the "s3" or "gcs" bucket described by cfg
+func NewBucket(kind string, cfg config.BucketConfig) (*Bucket, error) {
+	if cfg.Name == "" {
+		return nil, errors.New("EXPORT_BUCKET is required for bucket storage")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("EXPORT_BUCKET_ACCESS_KEY_ID and EXPORT_BUCKET_SECRET_ACCESS_KEY are required for bucket storage")
+	}
+
+	b := &Bucket{
+		name:      cfg.Name,
+		prefix:    cfg.Prefix,
+		region:    cfg.Region,
+		accessKey: cfg.AccessKeyID,
+		secretKey: cfg.SecretAccessKey,
+		urlExpiry: min(cfg.URLExpiry, MaxURLExpiry),
+		client:    &http.Client{},
+	}
+	if b.urlExpiry <= 0 {
+		b.urlExpiry = 15 * time.Minute
+	}
+
+	endpoint := cfg.Endpoint
+	b.pathStyle = endpoint != ""
+	switch kind {
+	case "gcs":
+		if b.region == "" {
+			b.region = "auto"
+		}
+		if endpoint == "" {
+			endpoint, b.pathStyle = "https://storage.googleapis.com", true
+		}
+	default:
+		if b.region == "" {
+			b.region = "us-east-1"
+		}
+		if endpoint == "" {
+			endpoint = "https://s3." + b.region + ".amazonaws.com"
+		}
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid EXPORT_BUCKET_ENDPOINT %q", endpoint)
+	}
+	b.endpoint = u
+	return b, nil
+}
+
+// Save uploads localPath to key, in parts if it is larger than partSize, and removes it
+func (b *Bucket) Save(ctx context.Context, key, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{"Content-Type": {ContentType(key)}}
+	if info.Size() <= partSize {
+		resp, err := b.do(ctx, http.MethodPut, key, nil, header, f, info.Size())
+		if err != nil {
+			return fmt.Errorf("failed to upload export: %w", err)
+		}
+		resp.Body.Close()
+	} else if err := b.uploadParts(ctx, key, header, f, info.Size()); err != nil {
+		return fmt.Errorf("failed to upload export: %w", err)
+	}
+
+	f.Close()
+	return os.Remove(localPath)
+}
+
+// uploadParts uploads size bytes of r to key with a multipart upload, aborting it on failure so
+// the bucket is not charged for orphaned parts
+func (b *Bucket) uploadParts(ctx context.Context, key string, header http.Header, r io.ReaderAt, size int64) error {
+	resp, err := b.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, header, nil, 0)
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&initiated)
+	resp.Body.Close()
+	if err != nil || initiated.UploadID == "" {
+		return fmt.Errorf("invalid response starting multipart upload: %v", err)
+	}
+	upload := url.Values{"uploadId": {initiated.UploadID}}
+
+	type part struct {
+		PartNumber int
+		ETag       string
+	}
+	complete := struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{}
+
+	err = func() error {
+		for offset := int64(0); offset < size; offset += partSize {
+			number := len(complete.Parts) + 1
+			query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": upload["uploadId"]}
+			resp, err := b.do(ctx, http.MethodPut, key, query, nil, io.NewSectionReader(r, offset, partSize), min(partSize, size-offset))
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			complete.Parts = append(complete.Parts, part{PartNumber: number, ETag: resp.Header.Get("ETag")})
+		}
+
+		body, err := xml.Marshal(complete)
+		if err != nil {
+			return err
+		}
+		resp, err := b.do(ctx, http.MethodPost, key, upload, nil, bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		// Completion can fail after the 200 status has been sent
+		result, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(result, []byte("<Error>")) {
+			return fmt.Errorf("completing multipart upload: %s", result)
+		}
+		return nil
+	}()
+	if err != nil {
+		if resp, abortErr := b.do(context.WithoutCancel(ctx), http.MethodDelete, key, upload, nil, nil, 0); abortErr == nil {
+			resp.Body.Close()
+		}
+	}
+	return err
+}
+
+// Serve redirects to a signed URL downloading key as filename
+func (b *Bucket) Serve(w http.ResponseWriter, r *http.Request, key, filename string) {
+	query := url.Values{
+		"response-content-disposition": {attachment(filename)},
+		"response-content-type":        {ContentType(key)},
+	}
+	http.Redirect(w, r, b.presign(http.MethodGet, key, query, b.urlExpiry, time.Now()), http.StatusFound)
+}
+
+// Open downloads the object at key
+func (b *Bucket) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := b.do(ctx, http.MethodGet, key, nil, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// SignedURL returns a signed URL downloading key as filename, valid for expiry up to 7 days
+func (b *Bucket) SignedURL(key, filename string, expiry time.Duration) string {
+	query := url.Values{"response-content-disposition": {attachment(filename)}}
+	return b.presign(http.MethodGet, key, query, min(expiry, MaxURLExpiry), time.Now())
+}
+
+// Delete removes the object at key
+func (b *Bucket) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, key, nil, nil, nil, 0)
+	var status *statusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// statusError is an error response from the bucket
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("bucket responded %d: %s", e.code, e.body)
+}
+
+// do sends a signed request for key with size bytes of body, read from offset 0, and returns
+// the response when it succeeded
+func (b *Bucket) do(ctx context.Context, method, key string, query url.Values, header http.Header, body io.ReaderAt, size int64) (*http.Response, error) {
+	u := b.objectURL(key)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.URL = u
+	if body != nil {
+		req.Body = io.NopCloser(io.NewSectionReader(body, 0, size))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(io.NewSectionReader(body, 0, size)), nil
+		}
+		req.ContentLength = size
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	b.sign(req, time.Now())
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(message))}
+	}
+	return resp, nil
+}
+
+// objectURL returns the URL of key, under the bucket's prefix
+func (b *Bucket) objectURL(key string) *url.URL {
+	u := *b.endpoint
+	objectPath := "/" + b.prefix + key
+	if b.pathStyle {
+		objectPath = "/" + b.name + objectPath
+	} else {
+		u.Host = b.name + "." + u.Host
+	}
+	u.Path = strings.TrimSuffix(b.endpoint.Path, "/") + objectPath
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+// sign adds the SigV4 Authorization header to req, signing its host, content type and
+// x-amz-* headers
+func (b *Bucket) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, unsignedPayload,
+	}, "\n")
+	scope := b.scope(amzDate)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.accessKey, scope, signedHeaders, b.signature(amzDate, scope, canonicalRequest)))
+}
+
+// presign returns a URL for an unauthenticated request for key that is valid for expiry
+func (b *Bucket) presign(method, key string, query url.Values, expiry time.Duration, now time.Time) string {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scope := b.scope(amzDate)
+	u := b.objectURL(key)
+
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", b.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	u.RawQuery = canonicalQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		method, u.EscapedPath(), u.RawQuery, "host:" + u.Host + "\n", "host", unsignedPayload,
+	}, "\n")
+	u.RawQuery += "&X-Amz-Signature=" + b.signature(amzDate, scope, canonicalRequest)
+	return u.String()
+}
+
+// scope is the credential scope of a request signed at amzDate
+func (b *Bucket) scope(amzDate string) string {
+	return amzDate[:8] + "/" + b.region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request with the key derived for its scope
+func (b *Bucket) signature(amzDate, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + b.secretKey)
+	for _, part := range []string{amzDate[:8], b.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query sorted by name, as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters and, unless encodeSlash is
+// set, slashes
+func uriEncode(s string, encodeSlash bool) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			out.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			out.WriteByte(c)
+		default:
+			fmt.Fprintf(&out, "%%%02X", c)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Local keeps export files in a directory and serves them through the API
+type Local struct {
+	dir string
+}
+
+// NewLocal creates a store keeping files under dir
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+// path returns where the file at key is kept
+func (l *Local) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(key))
+}
+
+// Save moves localPath into the store's directory
+func (l *Local) Save(ctx context.Context, key, localPath string) error {
+	dest := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(localPath, dest)
+}
+
+// Serve sends the file at key
+func (l *Local) Serve(w http.ResponseWriter, r *http.Request, key, filename string) {
+	w.Header().Set("Content-Type", ContentType(key))
+	w.Header().Set("Content-Disposition", attachment(filename))
+	http.ServeFile(w, r, l.path(key))
+}
+
+// Open opens the file at key
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(l.path(key))
+}
+
+// SignedURL returns ""; local files are only downloaded through the API
+func (l *Local) SignedURL(key, filename string, expiry time.Duration) string {
+	return ""
+}
+
+// Delete removes the file at key
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(l.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
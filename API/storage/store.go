@@ -0,0 +1,76 @@
+// Package storage keeps finished export files, either in a local directory served by the API or
+// in an S3 or GCS bucket that clients download from directly through signed URLs.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"data-co/api/config"
+)
+
+// Store keeps export files under keys of the form <tenant>/<name>
+type Store interface {
+	// Save moves the finished file at localPath to key
+	Save(ctx context.Context, key, localPath string) error
+	// Serve responds with the file at key, or redirects to where it can be downloaded, naming
+	// the download filename
+	Serve(w http.ResponseWriter, r *http.Request, key, filename string)
+	// Open reads the file at key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// SignedURL returns a URL downloading key as filename without credentials for up to expiry,
+	// or "" if the store cannot sign URLs
+	SignedURL(key, filename string, expiry time.Duration) string
+	// Delete removes the file at key; a missing file is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the store configured by cfg.Storage
+func New(cfg config.ExportsConfig) (Store, error) {
+	switch cfg.Storage {
+	case "", "local":
+		return NewLocal(cfg.Dir), nil
+	case "s3", "gcs":
+		return NewBucket(cfg.Storage, cfg.Bucket)
+	default:
+		return nil, fmt.Errorf(`unknown export storage %q: must be "local", "s3" or "gcs"`, cfg.Storage)
+	}
+}
+
+// Key names the file of an export under its owner's prefix, so each tenant's exports are kept
+// apart in a shared bucket
+func Key(ownerID string, id int64, format string) string {
+	return path.Join(tenantPrefix(ownerID), fmt.Sprintf("%d.%s", id, format))
+}
+
+// tenantPrefix turns an owner ID such as "key:3" into a key segment such as "key-3". Exports
+// made with auth disabled have no owner and are kept under "anonymous".
+func tenantPrefix(ownerID string) string {
+	if ownerID == "" {
+		return "anonymous"
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-' || r == '@' {
+			return r
+		}
+		return '-'
+	}, ownerID)
+}
+
+// ContentType returns the media type of an export file from its extension
+func ContentType(key string) string {
+	if path.Ext(key) == ".ndjson" {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// attachment formats a Content-Disposition header downloading as filename
+func attachment(filename string) string {
+	return fmt.Sprintf(`attachment; filename="%s"`, filename)
+}
@@ -0,0 +1,110 @@
+package streaming
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTimepointOutOfRange is returned when the requested resume timepoint is older than
+// the history the streaming API keeps
+var ErrTimepointOutOfRange = errors.New("timepoint out of range")
+
+// ErrRateLimited is returned when the streaming API rejects a connection with 429
+var ErrRateLimited = errors.New("rate limited by streaming API")
+
+// Event is one change published on a Companies House stream
+type Event struct {
+	ResourceKind string          `json:"resource_kind"`
+	ResourceURI  string          `json:"resource_uri"`
+	ResourceID   string          `json:"resource_id"`
+	Data         json.RawMessage `json:"data"`
+	Event        struct {
+		Timepoint   int64  `json:"timepoint"`
+		PublishedAt string `json:"published_at"`
+		Type        string `json:"type"` // "changed" or "deleted"
+	} `json:"event"`
+}
+
+// Client connects to the Companies House streaming API
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// NewClient creates a streaming API client
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		// No overall timeout: streams stay open indefinitely
+		http: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 30 * time.Second,
+		}},
+	}
+}
+
+// Stream connects to a stream (e.g. "companies") and calls handle for each event until ctx is
+// cancelled, the connection drops, or handle returns an error. A nil timepoint starts from the
+// latest event; otherwise events after the given timepoint are replayed first.
+func (c *Client) Stream(ctx context.Context, stream string, timepoint *int64, handle func(Event) error) error {
+	endpoint := c.baseURL + "/" + url.PathEscape(stream)
+	if timepoint != nil {
+		endpoint += "?timepoint=" + strconv.FormatInt(*timepoint+1, 10)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("invalid stream request: %w", err)
+	}
+	req.SetBasicAuth(c.apiKey, "")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s stream: %w", stream, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusRequestedRangeNotSatisfiable:
+		return ErrTimepointOutOfRange
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s stream responded %s: %s", stream, resp.Status, bytes.TrimSpace(body))
+	}
+
+	// Events are newline-delimited JSON; blank lines are heartbeats
+	reader := bufio.NewReaderSize(resp.Body, 64<<10)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var event Event
+			if jsonErr := json.Unmarshal(line, &event); jsonErr != nil {
+				return fmt.Errorf("malformed %s event: %w", stream, jsonErr)
+			}
+			if handleErr := handle(event); handleErr != nil {
+				return handleErr
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("%s stream closed by server", stream)
+			}
+			return fmt.Errorf("%s stream read failed: %w", stream, err)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"time"
+
+	"data-co/api/companieshouse"
+	"data-co/api/database"
+	"data-co/api/snapshot"
+)
+
+const (
+	// checkpointEvery and checkpointInterval bound how much of a stream is replayed after a restart
+	checkpointEvery    = 500
+	checkpointInterval = 10 * time.Second
+
+	// Reconnect backoff after the stream drops
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+	// rateLimitDelay is how long to wait after the streaming API answers 429
+	rateLimitDelay = time.Minute
+)
+
+// streams lists the streaming API resources the ingester knows how to store
+var streams = map[string]bool{
+	"companies":                        true,
+	"officers":                         true,
+	"persons-with-significant-control": true,
+	"charges":                          true,
+	"insolvency-cases":                 true,
+}
+
+// ValidStream reports whether stream is one the ingester can consume
+func ValidStream(stream string) bool {
+	return streams[stream]
+}
+
+// Stats counts what an ingester has written since it started
+type Stats struct {
+	Events  int64
+	Written int64
+	Skipped int64
+	Deleted int64
+}
+
+// Ingester consumes one Companies House stream and upserts each event into staging:
+// companies into staging_companies, officers into staging_officers and PSCs into staging_pscs
+type Ingester struct {
+	db     *database.DB
+	client *Client
+	stream string
+
+	stats          Stats
+	timepoint      *int64
+	pending        int
+	lastCheckpoint time.Time
+}
+
+// NewIngester creates an ingester for a stream: "companies", "officers" or
+// "persons-with-significant-control"
+func NewIngester(db *database.DB, client *Client, stream string) *Ingester {
+	return &Ingester{db: db, client: client, stream: stream}
+}
+
+// Run consumes the stream until ctx is cancelled, resuming from the last checkpointed
+// timepoint and reconnecting with backoff whenever the connection drops
+func (in *Ingester) Run(ctx context.Context) error {
+	timepoint, err := in.db.GetStreamTimepoint(ctx, in.stream)
+	if err != nil {
+		return err
+	}
+	in.timepoint = timepoint
+	if timepoint != nil {
+		log.Printf("Resuming %s stream after timepoint %d", in.stream, *timepoint)
+	} else {
+		log.Printf("Starting %s stream from the latest event", in.stream)
+	}
+
+	delay := minReconnectDelay
+	for {
+		connectedAt := time.Now()
+		err := in.client.Stream(ctx, in.stream, in.timepoint, func(event Event) error {
+			return in.handle(ctx, event)
+		})
+		in.checkpoint(context.WithoutCancel(ctx))
+
+		if ctx.Err() != nil {
+			log.Printf("Stopped %s stream: %d events, %d written, %d unchanged, %d deleted",
+				in.stream, in.stats.Events, in.stats.Written, in.stats.Skipped, in.stats.Deleted)
+			return nil
+		}
+
+		switch {
+		case errors.Is(err, ErrTimepointOutOfRange):
+			// Too far behind to resume; the bulk snapshot import has to fill the gap
+			log.Printf("WARNING: %s stream timepoint %d is no longer available, restarting from the latest event", in.stream, *in.timepoint)
+			in.timepoint = nil
+			continue
+		case errors.Is(err, ErrRateLimited):
+			delay = rateLimitDelay
+		case time.Since(connectedAt) > maxReconnectDelay:
+			// The connection was healthy for a while, so reconnect promptly
+			delay = minReconnectDelay
+		}
+
+		log.Printf("%s stream disconnected: %v (reconnecting in %s)", in.stream, err, delay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// handle writes one event to staging. Events that cannot be mapped are logged and skipped
+// so a single bad record does not stall the stream; database errors end the connection.
+func (in *Ingester) handle(ctx context.Context, event Event) error {
+	in.stats.Events++
+
+	var (
+		written bool
+		err     error
+	)
+	switch in.stream {
+	case "companies":
+		written, err = in.handleCompany(ctx, event)
+	case "persons-with-significant-control":
+		written, err = in.handlePSC(ctx, event)
+	case "charges":
+		written, err = in.handleCharge(ctx, event)
+	case "insolvency-cases":
+		written, err = in.handleInsolvency(ctx, event)
+	default:
+		written, err = in.handleOfficer(ctx, event)
+	}
+
+	var mapErr *mappingError
+	if errors.As(err, &mapErr) {
+		log.Printf("Skipping %s event %d: %v", in.stream, event.Event.Timepoint, mapErr.err)
+	} else if err != nil {
+		return err
+	}
+
+	if written {
+		in.stats.Written++
+	} else if event.Event.Type != "deleted" {
+		in.stats.Skipped++
+	}
+
+	timepoint := event.Event.Timepoint
+	in.timepoint = &timepoint
+	in.pending++
+	if in.pending >= checkpointEvery || time.Since(in.lastCheckpoint) >= checkpointInterval {
+		in.checkpoint(ctx)
+	}
+	return nil
+}
+
+func (in *Ingester) handleCompany(ctx context.Context, event Event) (bool, error) {
+	if event.Event.Type == "deleted" {
+		// Struck-off companies stay in staging with their last known status
+		return false, nil
+	}
+
+	company, err := CompanyFromEvent(event)
+	if err != nil {
+		return false, &mappingError{err}
+	}
+	return in.db.UpsertStreamCompany(ctx, company)
+}
+
+func (in *Ingester) handleOfficer(ctx context.Context, event Event) (bool, error) {
+	officer, err := OfficerFromEvent(event)
+	if err != nil {
+		return false, &mappingError{err}
+	}
+
+	if event.Event.Type == "deleted" {
+		deleted, err := in.db.DeleteStreamOfficer(ctx, officer)
+		if deleted {
+			in.stats.Deleted++
+		}
+		return false, err
+	}
+	return in.db.UpsertStreamOfficer(ctx, officer)
+}
+
+func (in *Ingester) handlePSC(ctx context.Context, event Event) (bool, error) {
+	number := companyNumberFromURI(event.ResourceURI)
+	if number == "" {
+		return false, &mappingError{fmt.Errorf("cannot find company number in %s", event.ResourceURI)}
+	}
+
+	if event.Event.Type == "deleted" {
+		deleted, err := in.db.DeleteStreamPSC(ctx, number, path.Base(event.ResourceURI))
+		if deleted {
+			in.stats.Deleted++
+		}
+		return false, err
+	}
+
+	psc, ok, err := snapshot.ParsePSC(number, event.Data)
+	if err != nil {
+		return false, &mappingError{err}
+	}
+	if !ok {
+		return false, nil
+	}
+	return in.db.UpsertStreamPSC(ctx, psc)
+}
+
+func (in *Ingester) handleCharge(ctx context.Context, event Event) (bool, error) {
+	number := companyNumberFromURI(event.ResourceURI)
+	if number == "" {
+		return false, &mappingError{fmt.Errorf("cannot find company number in %s", event.ResourceURI)}
+	}
+
+	if event.Event.Type == "deleted" {
+		deleted, err := in.db.DeleteStreamCharge(ctx, number, path.Base(event.ResourceURI))
+		if deleted {
+			in.stats.Deleted++
+		}
+		return false, err
+	}
+
+	charge, err := companieshouse.ParseCharge(number, event.Data)
+	if err != nil {
+		return false, &mappingError{err}
+	}
+	return in.db.UpsertStreamCharge(ctx, charge)
+}
+
+// handleInsolvency replaces a company's insolvency cases, since each event carries all of them
+func (in *Ingester) handleInsolvency(ctx context.Context, event Event) (bool, error) {
+	number := companyNumberFromURI(event.ResourceURI)
+	if number == "" {
+		return false, &mappingError{fmt.Errorf("cannot find company number in %s", event.ResourceURI)}
+	}
+
+	cases := []database.StagingInsolvencyCase{}
+	if event.Event.Type != "deleted" {
+		var err error
+		if cases, err = companieshouse.ParseInsolvency(number, event.Data); err != nil {
+			return false, &mappingError{err}
+		}
+	}
+
+	written, err := in.db.ReplaceStreamInsolvency(ctx, number, cases)
+	if event.Event.Type == "deleted" && written > 0 {
+		in.stats.Deleted++
+		return false, err
+	}
+	return written > 0, err
+}
+
+// checkpoint saves the last handled timepoint
+func (in *Ingester) checkpoint(ctx context.Context) {
+	if in.pending == 0 || in.timepoint == nil {
+		return
+	}
+	if err := in.db.SetStreamTimepoint(ctx, in.stream, *in.timepoint); err != nil {
+		log.Printf("Checkpoint %s stream error: %v", in.stream, err)
+		return
+	}
+	in.pending = 0
+	in.lastCheckpoint = time.Now()
+}
+
+// mappingError marks an event whose payload could not be converted to a staging row
+type mappingError struct {
+	err error
+}
+
+func (e *mappingError) Error() string { return e.err.Error() }
@@ -0,0 +1,281 @@
+package streaming
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"data-co/api/database"
+)
+
+// companyTypes maps streaming API company types to the category names used in the
+// BasicCompanyData snapshot, so both sources agree in staging_companies.company_type.
+// Unlisted types are stored as published.
+var companyTypes = map[string]string{
+	"ltd":                         "Private Limited Company",
+	"plc":                         "Public Limited Company",
+	"llp":                         "Limited Liability Partnership",
+	"limited-partnership":         "Limited Partnership",
+	"private-unlimited":           "Private Unlimited Company",
+	"private-limited-guarant-nsc": "PRI/LTD BY GUAR/NSC (Private, limited by guarantee, no share capital)",
+	"private-limited-guarant-nsc-limited-exemption": "PRI/LBG/NSC (Private, Limited by guarantee, no share capital, use of 'Limited' exemption)",
+	"charitable-incorporated-organisation":          "Charitable Incorporated Organisation",
+	"community-interest-company":                    "Community Interest Company",
+}
+
+// accountsTypes maps streaming API accounts types to the account category names used in the
+// BasicCompanyData snapshot. Unlisted types are stored as published.
+var accountsTypes = map[string]string{
+	"micro-entity":                "MICRO ENTITY",
+	"small":                       "SMALL",
+	"medium":                      "MEDIUM",
+	"full":                        "FULL",
+	"group":                       "GROUP",
+	"dormant":                     "DORMANT",
+	"total-exemption-full":        "TOTAL EXEMPTION FULL",
+	"total-exemption-small":       "TOTAL EXEMPTION SMALL",
+	"unaudited-abridged":          "UNAUDITED ABRIDGED",
+	"audited-abridged":            "AUDITED ABRIDGED",
+	"audit-exemption-subsidiary":  "AUDIT EXEMPTION SUBSIDIARY",
+	"filing-exemption-subsidiary": "FILING EXEMPTION SUBSIDIARY",
+	"partial-exemption":           "PARTIAL EXEMPTION",
+	"initial":                     "INITIAL",
+	"null":                        "NO ACCOUNTS FILED",
+}
+
+type address struct {
+	AddressLine1 *string `json:"address_line_1"`
+	AddressLine2 *string `json:"address_line_2"`
+	Locality     *string `json:"locality"`
+	PostalCode   *string `json:"postal_code"`
+	Region       *string `json:"region"`
+	Country      *string `json:"country"`
+}
+
+type partialDate struct {
+	Day   int `json:"day"`
+	Month int `json:"month"`
+	Year  int `json:"year"`
+}
+
+// companyProfile is the subset of the company profile resource stored in staging
+type companyProfile struct {
+	CompanyNumber           string   `json:"company_number"`
+	CompanyName             *string  `json:"company_name"`
+	CompanyStatus           *string  `json:"company_status"`
+	Type                    *string  `json:"type"`
+	RegisteredOfficeAddress address  `json:"registered_office_address"`
+	SICCodes                []string `json:"sic_codes"`
+	DateOfCreation          *string  `json:"date_of_creation"`
+	DateOfCessation         *string  `json:"date_of_cessation"`
+	Accounts                struct {
+		NextDue                 *string `json:"next_due"`
+		AccountingReferenceDate struct {
+			Day   string `json:"day"`
+			Month string `json:"month"`
+		} `json:"accounting_reference_date"`
+		LastAccounts struct {
+			MadeUpTo *string `json:"made_up_to"`
+			Type     *string `json:"type"`
+		} `json:"last_accounts"`
+	} `json:"accounts"`
+	ConfirmationStatement struct {
+		NextDue      *string `json:"next_due"`
+		LastMadeUpTo *string `json:"last_made_up_to"`
+	} `json:"confirmation_statement"`
+	PreviousCompanyNames []struct {
+		Name          string  `json:"name"`
+		EffectiveFrom *string `json:"effective_from"`
+		CeasedOn      *string `json:"ceased_on"`
+	} `json:"previous_company_names"`
+}
+
+// officerResource holds the fields shared by officer appointments and PSC records
+type officerResource struct {
+	Name         *string `json:"name"`
+	NameElements struct {
+		Title      string `json:"title"`
+		Forename   string `json:"forename"`
+		MiddleName string `json:"middle_name"`
+		Surname    string `json:"surname"`
+	} `json:"name_elements"`
+	Kind             string       `json:"kind"`
+	OfficerRole      *string      `json:"officer_role"`
+	AppointedOn      *string      `json:"appointed_on"`
+	ResignedOn       *string      `json:"resigned_on"`
+	NotifiedOn       *string      `json:"notified_on"`
+	CeasedOn         *string      `json:"ceased_on"`
+	DateOfBirth      *partialDate `json:"date_of_birth"`
+	Nationality      *string      `json:"nationality"`
+	NaturesOfControl []string     `json:"natures_of_control"`
+	Address          address      `json:"address"`
+}
+
+// CompanyFromEvent converts a company-profile event into a staging row
+func CompanyFromEvent(event Event) (database.StagingCompany, error) {
+	var p companyProfile
+	if err := json.Unmarshal(event.Data, &p); err != nil {
+		return database.StagingCompany{}, fmt.Errorf("invalid company profile: %w", err)
+	}
+	if p.CompanyNumber == "" {
+		p.CompanyNumber = event.ResourceID
+	}
+	if p.CompanyNumber == "" {
+		return database.StagingCompany{}, fmt.Errorf("company profile %s has no company number", event.ResourceURI)
+	}
+
+	c := database.StagingCompany{
+		CompanyNumber:          p.CompanyNumber,
+		CompanyName:            p.CompanyName,
+		CompanyStatus:          lower(p.CompanyStatus),
+		CompanyType:            p.Type,
+		Locality:               p.RegisteredOfficeAddress.Locality,
+		PostalCode:             p.RegisteredOfficeAddress.PostalCode,
+		AddressLine1:           p.RegisteredOfficeAddress.AddressLine1,
+		AddressLine2:           p.RegisteredOfficeAddress.AddressLine2,
+		Region:                 p.RegisteredOfficeAddress.Region,
+		Country:                p.RegisteredOfficeAddress.Country,
+		SICCodes:               p.SICCodes,
+		IncorporationDate:      p.DateOfCreation,
+		DissolvedOn:            p.DateOfCessation,
+		AccountsLastMadeUpDate: p.Accounts.LastAccounts.MadeUpTo,
+		AccountsNextDueDate:    p.Accounts.NextDue,
+		ConfStmtNextDueDate:    p.ConfirmationStatement.NextDue,
+		ConfStmtLastMadeUpDate: p.ConfirmationStatement.LastMadeUpTo,
+		RawData:                event.Data,
+	}
+	if p.Type != nil {
+		if category, ok := companyTypes[*p.Type]; ok {
+			c.CompanyType = &category
+		}
+	}
+	if t := p.Accounts.LastAccounts.Type; t != nil {
+		category := *t
+		if mapped, ok := accountsTypes[category]; ok {
+			category = mapped
+		}
+		c.AccountCategory = &category
+	}
+	if c.SICCodes == nil {
+		c.SICCodes = []string{}
+	}
+
+	ref := p.Accounts.AccountingReferenceDate
+	day, dayErr := strconv.Atoi(ref.Day)
+	month, monthErr := strconv.Atoi(ref.Month)
+	if dayErr == nil && monthErr == nil {
+		refDate := fmt.Sprintf("%02d-%02d", month, day)
+		c.AccountsRefDate = &refDate
+	}
+
+	names := make([]string, 0, len(p.PreviousCompanyNames))
+	for _, prev := range p.PreviousCompanyNames {
+		if name := strings.TrimSpace(prev.Name); name != "" {
+			names = append(names, name)
+			c.PreviousNameHistory = append(c.PreviousNameHistory, database.StagingPreviousName{
+				Name:          name,
+				EffectiveFrom: prev.EffectiveFrom,
+				CeasedOn:      prev.CeasedOn,
+			})
+		}
+	}
+	if len(names) > 0 {
+		joined := strings.Join(names, "|")
+		c.PreviousNames = &joined
+	}
+
+	c.DataHash = c.Hash()
+	return c, nil
+}
+
+// OfficerFromEvent converts an officer appointment or PSC event into a staging row. The
+// raw data is stored in the same {"company_number", "data"} shape as the PSC bulk snapshot.
+func OfficerFromEvent(event Event) (database.StagingOfficer, error) {
+	number := companyNumberFromURI(event.ResourceURI)
+	if number == "" {
+		return database.StagingOfficer{}, fmt.Errorf("cannot find company number in %s", event.ResourceURI)
+	}
+
+	var r officerResource
+	if err := json.Unmarshal(event.Data, &r); err != nil {
+		return database.StagingOfficer{}, fmt.Errorf("invalid officer resource: %w", err)
+	}
+
+	raw, err := json.Marshal(map[string]any{"company_number": number, "data": event.Data})
+	if err != nil {
+		return database.StagingOfficer{}, fmt.Errorf("failed to encode officer record: %w", err)
+	}
+
+	o := database.StagingOfficer{
+		CompanyNumber: number,
+		OfficerName:   r.Name,
+		OfficerRole:   r.OfficerRole,
+		AppointedOn:   r.AppointedOn,
+		ResignedOn:    r.ResignedOn,
+		Nationality:   r.Nationality,
+		AddressLine1:  r.Address.AddressLine1,
+		AddressLine2:  r.Address.AddressLine2,
+		Locality:      r.Address.Locality,
+		PostalCode:    r.Address.PostalCode,
+		Country:       r.Address.Country,
+		RawData:       raw,
+	}
+
+	// PSC records use kind/notified_on/ceased_on where appointments use role/appointed_on/resigned_on
+	if o.OfficerRole == nil && r.Kind != "" {
+		o.OfficerRole = &r.Kind
+	}
+	if o.AppointedOn == nil {
+		o.AppointedOn = r.NotifiedOn
+	}
+	if o.ResignedOn == nil {
+		o.ResignedOn = r.CeasedOn
+	}
+	if o.OfficerName == nil {
+		parts := make([]string, 0, 4)
+		for _, part := range []string{r.NameElements.Title, r.NameElements.Forename, r.NameElements.MiddleName, r.NameElements.Surname} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > 0 {
+			name := strings.Join(parts, " ")
+			o.OfficerName = &name
+		}
+	}
+	if dob := r.DateOfBirth; dob != nil && dob.Year > 0 && dob.Month > 0 {
+		day := dob.Day
+		if day == 0 {
+			day = 1 // Only month and year are published
+		}
+		date := fmt.Sprintf("%04d-%02d-%02d", dob.Year, dob.Month, day)
+		o.DateOfBirth = &date
+	}
+	if len(r.NaturesOfControl) > 0 {
+		natures := strings.Join(r.NaturesOfControl, "|")
+		o.NatureOfControl = &natures
+	}
+
+	o.DataHash = o.Hash()
+	return o, nil
+}
+
+// companyNumberFromURI extracts the company number from a resource URI like
+// /company/01234567/appointments/abc
+func companyNumberFromURI(uri string) string {
+	parts := strings.Split(strings.Trim(uri, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "company" {
+		return parts[1]
+	}
+	return ""
+}
+
+// lower returns a lowercased copy of s, matching the bulk loader's status normalisation
+func lower(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	l := strings.ToLower(*s)
+	return &l
+}
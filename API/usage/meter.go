@@ -0,0 +1,105 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"data-co/api/auth"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+// recordTimeout bounds the usage write made after each response
+const recordTimeout = 5 * time.Second
+
+// rowCounter accumulates the result rows a handler returns
+type rowCounter struct {
+	rows atomic.Int64
+}
+
+type contextKey struct{}
+
+// AddRows records that the current request returned n result rows.
+// It is a no-op for requests that are not metered.
+func AddRows(ctx context.Context, n int) {
+	if counter, ok := ctx.Value(contextKey{}).(*rowCounter); ok {
+		counter.rows.Add(int64(n))
+	}
+}
+
+// Meter counts requests and returned rows per API key and enforces monthly quotas
+type Meter struct {
+	db     *database.DB
+	exempt map[string]bool
+}
+
+// NewMeter creates a usage meter. Requests to exemptPaths are not counted or limited.
+func NewMeter(db *database.DB, exemptPaths ...string) *Meter {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+	return &Meter{db: db, exempt: exempt}
+}
+
+// Middleware rejects key-authenticated requests once a monthly quota is used up and records
+// usage after each request. It must run after authentication.
+func (m *Meter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := auth.FromContext(r.Context())
+		if principal == nil || principal.KeyID == 0 || r.Method == http.MethodOptions || m.exempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if principal.MonthlyRequestQuota != nil || principal.MonthlyRowQuota != nil {
+			current, err := m.db.GetCurrentUsage(r.Context(), principal.KeyID)
+			if err != nil {
+				log.Printf("Usage lookup error: %v", err)
+				respondWithError(w, http.StatusInternalServerError, "Failed to check usage quota", err.Error())
+				return
+			}
+			if exceeded := quotaExceeded(principal, current); exceeded != "" {
+				respondWithError(w, http.StatusTooManyRequests, "Quota exceeded", exceeded)
+				return
+			}
+		}
+
+		counter := &rowCounter{}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, counter)))
+
+		// Record even if the client went away; the work has been done
+		ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+		defer cancel()
+		if err := m.db.RecordUsage(ctx, principal.KeyID, counter.rows.Load()); err != nil {
+			log.Printf("Usage record error for key %d: %v", principal.KeyID, err)
+		}
+	})
+}
+
+// quotaExceeded describes the first quota the caller has used up, or returns "" if none
+func quotaExceeded(principal *auth.Principal, current models.UsagePeriod) string {
+	if quota := principal.MonthlyRequestQuota; quota != nil && current.RequestCount >= *quota {
+		return fmt.Sprintf("Monthly request quota of %d reached for %s; usage resets at the start of next month", *quota, current.Period)
+	}
+	if quota := principal.MonthlyRowQuota; quota != nil && current.RowCount >= *quota {
+		return fmt.Sprintf("Monthly row quota of %d reached for %s; usage resets at the start of next month", *quota, current.Period)
+	}
+	return ""
+}
+
+func respondWithError(w http.ResponseWriter, statusCode int, error string, message string) {
+	response, _ := json.Marshal(models.ErrorResponse{
+		Error:   error,
+		Message: message,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(response)
+}
@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+const (
+	// claimBatchSize is how many due deliveries are sent per poll
+	claimBatchSize = 100
+	// baseBackoff is the delay before the first retry; it doubles with each attempt
+	baseBackoff = 30 * time.Second
+	// maxBackoff caps the delay between retries
+	maxBackoff = 6 * time.Hour
+)
+
+// Dispatcher queues company change events for subscribers and delivers them with retries
+type Dispatcher struct {
+	db     *database.DB
+	cfg    config.WebhooksConfig
+	client *http.Client
+}
+
+// NewDispatcher creates a webhook dispatcher
+func NewDispatcher(db *database.DB, cfg config.WebhooksConfig) *Dispatcher {
+	return &Dispatcher{
+		db:     db,
+		cfg:    cfg,
+		client: newDeliveryClient(cfg.Timeout),
+	}
+}
+
+// Enqueue queues deliveries of each change to matching subscriptions. It is registered
+// as a change detector listener; deliveries are sent by the polling worker.
+func (d *Dispatcher) Enqueue(ctx context.Context, changes []models.CompanyChange) {
+	for _, change := range changes {
+		event, ok := EventFromChange(change)
+		if !ok {
+			continue
+		}
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			log.Printf("Webhook payload error: %v", err)
+			continue
+		}
+
+		if _, err := d.db.EnqueueWebhookDeliveries(ctx, event.ID, event.Type, payload); err != nil {
+			log.Printf("Webhook enqueue error: %v", err)
+		}
+	}
+}
+
+// Start polls for due deliveries every PollInterval until ctx is cancelled.
+// A poll interval of zero disables delivery.
+func (d *Dispatcher) Start(ctx context.Context) {
+	if d.cfg.PollInterval <= 0 {
+		log.Printf("Webhook delivery disabled")
+		return
+	}
+
+	log.Printf("Delivering webhooks every %s", d.cfg.PollInterval)
+
+	go func() {
+		ticker := time.NewTicker(d.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.deliverDue(ctx); err != nil {
+					log.Printf("Webhook delivery failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// deliverDue claims and sends batches of due deliveries until none are left
+func (d *Dispatcher) deliverDue(ctx context.Context) error {
+	// Claimed deliveries are leased for longer than a send can take so that
+	// a crash mid-send leads to a retry rather than a lost delivery
+	lease := 2*d.cfg.Timeout + time.Minute
+
+	for {
+		deliveries, err := d.db.ClaimWebhookDeliveries(ctx, claimBatchSize, lease)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			d.deliver(ctx, delivery)
+		}
+
+		if len(deliveries) < claimBatchSize || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// deliver sends one delivery and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, delivery database.WebhookDelivery) {
+	statusCode, err := d.send(ctx, delivery)
+	if err == nil {
+		if err := d.db.MarkWebhookDelivered(ctx, delivery.ID, statusCode); err != nil {
+			log.Printf("Webhook delivery error: %v", err)
+		}
+		return
+	}
+
+	var status *int
+	if statusCode != 0 {
+		status = &statusCode
+	}
+
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		log.Printf("Webhook delivery %d to %s dead-lettered after %d attempts: %v", delivery.ID, delivery.URL, delivery.Attempts, err)
+		if err := d.db.DeadLetterWebhookDelivery(ctx, delivery.ID, status, err.Error()); err != nil {
+			log.Printf("Webhook delivery error: %v", err)
+		}
+		return
+	}
+
+	next := time.Now().Add(backoff(delivery.Attempts))
+	if err := d.db.RetryWebhookDelivery(ctx, delivery.ID, status, err.Error(), next); err != nil {
+		log.Printf("Webhook delivery error: %v", err)
+	}
+}
+
+// send POSTs the signed payload and returns the response status. Any non-2xx response is an error.
+func (d *Dispatcher) send(ctx context.Context, delivery database.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DataCo-Webhooks/1.0")
+	req.Header.Set("X-DataCo-Event", delivery.EventType)
+	req.Header.Set("X-DataCo-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-DataCo-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-DataCo-Signature", Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before retrying a delivery that has failed attempts times
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,112 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"data-co/api/apiversion"
+	"data-co/api/models"
+)
+
+// Event types delivered to subscribers
+const (
+	EventStatusChanged    = "company.status_changed"
+	EventNewFiling        = "company.new_filing"
+	EventNewFinancials    = "company.new_financials"
+	EventOfficerAppointed = "company.officer_appointed"
+	EventOfficerResigned  = "company.officer_resigned"
+
+	// EventMatchedSearch is delivered for each company newly matching the saved search of a
+	// subscription with filters. It is not a change event, so it has no recorded change type.
+	EventMatchedSearch = "company.matched_search"
+
+	// EventSavedSearchMatches is delivered to the webhook linked to a saved search each time its
+	// nightly evaluation finds new matches
+	EventSavedSearchMatches = "saved_search.new_matches"
+)
+
+// eventTypes maps recorded change types to the webhook event types they are published as
+var eventTypes = map[string]string{
+	"status_changed":    EventStatusChanged,
+	"new_accounts":      EventNewFiling,
+	"new_financials":    EventNewFinancials,
+	"officer_appointed": EventOfficerAppointed,
+	"officer_resigned":  EventOfficerResigned,
+}
+
+// ValidEventType reports whether t is an event type subscribers can ask for
+func ValidEventType(t string) bool {
+	if t == EventMatchedSearch {
+		return true
+	}
+	for _, known := range eventTypes {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// EventFromChange builds the webhook event for a recorded change. It returns false for
+// change types that are not published.
+func EventFromChange(change models.CompanyChange) (models.WebhookEvent, bool) {
+	eventType, ok := eventTypes[change.ChangeType]
+	if !ok {
+		return models.WebhookEvent{}, false
+	}
+	return models.WebhookEvent{
+		ID:        change.ID,
+		Type:      eventType,
+		CreatedAt: change.DetectedAt,
+		Data: models.WebhookEventData{
+			CompanyNumber: change.CompanyNumber,
+			OldValue:      change.OldValue,
+			NewValue:      change.NewValue,
+		},
+	}, true
+}
+
+// EventFromMatch builds the webhook event for a company newly matching a saved search, with the
+// company's plain version 2 fields as its data
+func EventFromMatch(subscriptionID int, eventID int64, matchedAt time.Time, c models.Company) (models.SearchMatchEvent, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return models.SearchMatchEvent{}, err
+	}
+	if data, err = apiversion.Clean(data); err != nil {
+		return models.SearchMatchEvent{}, err
+	}
+	return models.SearchMatchEvent{
+		ID:             eventID,
+		Type:           EventMatchedSearch,
+		CreatedAt:      matchedAt,
+		SubscriptionID: subscriptionID,
+		Data:           data,
+	}, nil
+}
+
+// Sign returns the signature sent in the X-DataCo-Signature header: the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret, prefixed with "sha256=".
+// Subscribers recompute it to verify a delivery and reject stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret returns a new random signing secret for a subscription
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"data-co/api/config"
+	"data-co/api/database"
+	"data-co/api/models"
+)
+
+const (
+	// searchCursor names the job cursor tracking how far updated companies have been matched
+	// against saved searches
+	searchCursor = "webhooks.search_matches"
+	// searchBatchSize is how many updated companies are matched per query
+	searchBatchSize = 1000
+)
+
+// SearchMatcher queues company.matched_search events for new companies matching the saved
+// search of a subscription. Deliveries are sent by the Dispatcher like any other.
+type SearchMatcher struct {
+	db  *database.DB
+	cfg config.WebhooksConfig
+}
+
+// NewSearchMatcher creates a saved search matcher
+func NewSearchMatcher(db *database.DB, cfg config.WebhooksConfig) *SearchMatcher {
+	return &SearchMatcher{db: db, cfg: cfg}
+}
+
+// Start matches companies every SearchInterval until ctx is cancelled. An interval of zero
+// disables saved search events.
+func (m *SearchMatcher) Start(ctx context.Context) {
+	if m.cfg.SearchInterval <= 0 {
+		log.Printf("Saved search webhooks disabled")
+		return
+	}
+
+	log.Printf("Matching saved search webhooks every %s", m.cfg.SearchInterval)
+
+	go func() {
+		ticker := time.NewTicker(m.cfg.SearchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				count, err := m.Run(ctx)
+				if err != nil {
+					log.Printf("Saved search matching failed: %v", err)
+					continue
+				}
+				if count > 0 {
+					log.Printf("Queued %d saved search webhook events", count)
+				}
+			}
+		}
+	}()
+}
+
+// Run matches the companies updated since the previous run against every saved search and
+// returns the number of events queued. A failed run is repeated in full by the next, which is
+// safe because each company is recorded once per subscription.
+func (m *SearchMatcher) Run(ctx context.Context) (int, error) {
+	since, err := m.db.GetJobCursor(ctx, searchCursor)
+	if err != nil {
+		return 0, err
+	}
+	if since == nil {
+		// Start from now rather than replaying history
+		return 0, m.db.SetJobCursor(ctx, searchCursor, time.Now())
+	}
+
+	numbers, position, err := m.db.CompaniesUpdatedSince(ctx, *since)
+	if err != nil {
+		return 0, err
+	}
+	subs, err := m.db.ListSearchWebhookSubscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, sub := range subs {
+		for start := 0; start < len(numbers); start += searchBatchSize {
+			batch := numbers[start:min(start+searchBatchSize, len(numbers))]
+			companies, err := m.db.NewSearchMatches(ctx, sub.ID, sub.Filters, batch)
+			if err != nil {
+				return total, err
+			}
+			if len(companies) == 0 {
+				continue
+			}
+
+			count, err := m.db.SaveSearchMatches(ctx, sub.ID, EventMatchedSearch, companies, func(eventID int64, matchedAt time.Time, c models.Company) ([]byte, error) {
+				event, err := EventFromMatch(sub.ID, eventID, matchedAt, c)
+				if err != nil {
+					return nil, err
+				}
+				return json.Marshal(event)
+			})
+			if err != nil {
+				return total, err
+			}
+			total += count
+		}
+	}
+
+	if err := m.db.SetJobCursor(ctx, searchCursor, position); err != nil {
+		return total, err
+	}
+	return total, nil
+}
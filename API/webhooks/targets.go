@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for subscriber hosts that are, or resolve to, an address that
+// is not on the public internet: loopback, private, link-local (including the cloud metadata
+// endpoint 169.254.169.254), shared or reserved ranges
+var ErrPrivateAddress = errors.New("webhook URL must resolve to public addresses only")
+
+// reservedPrefixes are the ranges not caught by the netip classification methods that are
+// still never routed on the public internet
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This network"
+	netip.MustParsePrefix("100.64.0.0/10"),   // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // Documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // Documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // Documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which can reach private IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+}
+
+// publicAddress reports whether deliveries may be sent to addr
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateURL checks a subscription URL is an absolute http or https URL whose host resolves,
+// and only to public addresses. Deliveries check the address dialled again, since DNS can
+// change after registration.
+func ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !publicAddress(addr) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("url host %s does not resolve", host)
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// dialControl refuses connections to addresses that are not public. It runs on the address
+// actually dialled, after resolution, so a host re-pointed since it was registered is caught.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("unexpected dial address %s: %w", address, err)
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// newDeliveryClient returns the HTTP client deliveries are sent with: it only connects to
+// public addresses, never through a proxy, and does not follow redirects, so a 3xx response is
+// a failed delivery rather than a request to wherever it points
+func newDeliveryClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -15,6 +15,15 @@ DB_PASS=$STAGING_DB_PASSWORD
 SCRIPT_DIR=$(dirname "$0")
 SCHEMA_DIR="."
 
+# Schema owned by the Go API lives with its code as migrations, including the search summary
+# views, so the staging schema is incomplete without them. Migrations can be re-applied, so the
+# API can still record them with its migrate command afterwards
+MIGRATIONS_DIR=${API_MIGRATIONS_DIR:-"$SCHEMA_DIR/../API/migrations"}
+if [ ! -d "$MIGRATIONS_DIR" ]; then
+    echo "Error: API migrations not found at $MIGRATIONS_DIR; check out the API alongside Data or set API_MIGRATIONS_DIR" >&2
+    exit 1
+fi
+
 # Find all SQL files, sort by FILENAME (ignore path), and apply
 echo "  Collecting and sorting schema files..."
 
@@ -22,6 +31,7 @@ find "$SCHEMA_DIR"/staging/common/schemas \
      "$SCHEMA_DIR"/staging/tables/companies/schemas \
      "$SCHEMA_DIR"/staging/tables/psc/schemas \
      "$SCHEMA_DIR"/staging/tables/accounts/schemas \
+     "$MIGRATIONS_DIR" \
      -name "*.sql" -print0 | \
      perl -0ne 'print "$_"' | \
      xargs -0 -I{} bash -c 'echo "$(basename "{}") {}"' | sort | cut -d ' ' -f2- | \
@@ -47,6 +47,18 @@ IX_CONTEXT_RE = re.compile(
     r"<(?:\w+:)?context[^>]*?id=[\"'](?P<id>[^\"']+)[\"'][^>]*?>.*?(?:<(?:\w+:)?(?:endDate|instant)>(?P<end>[^<]+)</(?:\w+:)?(?:endDate|instant)>).*?</(?:\w+:)?context>",
     re.IGNORECASE | re.DOTALL,
 )
+# The ixt:booleantrue / ixt:booleanfalse format of a nonNumeric, whose text is then free prose
+IX_BOOLEAN_FORMAT_RE = re.compile(r"format=[\"'][^\"']*?boolean(?P<value>true|false)[\"']", re.IGNORECASE)
+# A context with dimensions, e.g. of an equity component or a creditors maturity
+IX_DIMENSION_RE = re.compile(r"<(?:\w+:)?(?:explicitMember|typedMember)\b", re.IGNORECASE)
+
+# Monetary units and the facts that use them, to find the reporting currency, e.g.
+# <xbrli:unit id="EUR"><xbrli:measure>iso4217:EUR</xbrli:measure></xbrli:unit>
+XBRL_CURRENCY_UNIT_RE = re.compile(
+    r"<(?:\w+:)?unit[^>]*?id=[\"'](?P<id>[^\"']+)[\"'][^>]*?>\s*<(?:\w+:)?measure>\s*iso4217:(?P<currency>[A-Za-z]{3})\s*</(?:\w+:)?measure>\s*</(?:\w+:)?unit>",
+    re.IGNORECASE | re.DOTALL,
+)
+XBRL_UNIT_REF_RE = re.compile(r"unitRef=[\"'](?P<unit>[^\"']+)[\"']", re.IGNORECASE)
 
 
 class AccountsDataParser:
@@ -72,6 +84,7 @@ class AccountsDataParser:
         'total_assets',
         'total_liabilities',
         'net_assets_liabilities',
+        'currency',
     ]
 
     def __init__(self, file_path: Path, log_callback: Optional[Callable[[str], None]] = None):
@@ -371,6 +384,8 @@ class AccountsDataParser:
             self.log_callback(f"Skipping {filename}: No company number found")
             return []
 
+        currency = self._extract_currency(data.decode('utf-8', errors='ignore'))
+
         # Build context date mapping
         ctx_dates: dict[str, dict] = {} # id -> {'end': '...', 'start': '...'}
         
@@ -391,13 +406,16 @@ class AccountsDataParser:
             start_val = (start_el.text if start_el is not None else None)
             
             if end_val:
-                ctx_dates[ctx_id] = {'end': end_val, 'start': start_val}
+                dimensional = ctx.find('.//*{*}segment') is not None or ctx.find('.//*{*}scenario') is not None
+                ctx_dates[ctx_id] = {'end': end_val, 'start': start_val, 'dimensional': dimensional}
         
         # DEBUG: Log context dates
         self.log_callback(f"DEBUG: Found Contexts: {list(ctx_dates.keys())}")
 
-        # Collect facts
+        # Collect facts. Facts of dimensional contexts (e.g. Equity by component, Creditors by
+        # maturity) are kept apart, so they only fill in tags the undimensioned totals lack
         facts_by_period: dict[str, dict[str, float]] = {}
+        dimensional_facts_by_period: dict[str, dict[str, float]] = {}
         text_facts_by_period: dict[str, dict[str, str]] = {}
         
         for el in root.iter():
@@ -419,8 +437,10 @@ class AccountsDataParser:
             # So filtering facts by matching context date?
             
             fact_period_end = 'unknown'
+            dimensional = False
             if ctx_ref and ctx_ref in ctx_dates:
                  fact_period_end = ctx_dates[ctx_ref]['end']
+                 dimensional = ctx_dates[ctx_ref]['dimensional']
             
             # If filename period is known, and fact period doesn't match, maybe ignore?
             # Or store it and filter later.
@@ -429,11 +449,13 @@ class AccountsDataParser:
             # Numeric check
             try:
                 val = float(text.replace(',', ''))
-                bucket = facts_by_period.setdefault(fact_period_end, {})
+                numeric = dimensional_facts_by_period if dimensional else facts_by_period
+                bucket = numeric.setdefault(fact_period_end, {})
                 bucket[tag] = val # Store full tag for matching
             except ValueError:
                 bucket = text_facts_by_period.setdefault(fact_period_end, {})
                 bucket[tag] = text
+        facts_by_period = self._merge_dimensional_facts(facts_by_period, dimensional_facts_by_period)
 
         # Create record
         # Use filename period if available, otherwise use found periods
@@ -494,6 +516,7 @@ class AccountsDataParser:
                 'company_number': company_number,
                 'period_end': p_end,
                 'period_start': period_start,
+                'currency': currency,
                 'source': 'bulk_xbrl',
                 'raw_data': {} # Can populate if needed
             })
@@ -523,6 +546,8 @@ class AccountsDataParser:
             self.log_callback(f"Skipping {filename}: No company number found")
             return []
 
+        currency = self._extract_currency(text)
+
         # Build context mapping
         ctx_dates: dict[str, dict] = {}
         for m in IX_CONTEXT_RE.finditer(text):
@@ -533,10 +558,12 @@ class AccountsDataParser:
             # For iXBRL full support, we'd need a stronger parser, but staying with regex as per existing code style.
             end_val = m.group('end')
             if ctx_id and end_val:
-                ctx_dates[ctx_id] = {'end': end_val}
+                dimensional = IX_DIMENSION_RE.search(m.group(0)) is not None
+                ctx_dates[ctx_id] = {'end': end_val, 'dimensional': dimensional}
 
-        # Collect facts
+        # Collect facts, keeping those of dimensional contexts apart as for XBRL
         facts_by_period: dict[str, dict[str, float]] = {}
+        dimensional_facts_by_period: dict[str, dict[str, float]] = {}
         text_facts_by_period: dict[str, dict[str, str]] = {}
 
         # Numeric values
@@ -550,16 +577,19 @@ class AccountsDataParser:
             raw_val = re.sub(r'<.*?>', '', m.group('value') or '').strip()
             
             fact_period_end = ctx_dates.get(ctx, {}).get('end', 'unknown')
+            dimensional = ctx_dates.get(ctx, {}).get('dimensional', False)
             
             try:
                 # Handle sign? (format like (1,234) for negative?)
                 # Assuming standard float parse for now
                 val = float(raw_val.replace(',', '').replace(' ', ''))
                 # Handle sign attribute? (not parsed yet)
-                bucket = facts_by_period.setdefault(fact_period_end, {})
+                numeric = dimensional_facts_by_period if dimensional else facts_by_period
+                bucket = numeric.setdefault(fact_period_end, {})
                 bucket[name] = val
             except ValueError:
                 pass
+        facts_by_period = self._merge_dimensional_facts(facts_by_period, dimensional_facts_by_period)
         
         # Text values (for dates etc)
         # Regex for nonNumeric
@@ -567,6 +597,10 @@ class AccountsDataParser:
             name = m.group('name')
             ctx = m.group('context')
             raw_val = re.sub(r'<.*?>', '', m.group('value') or '').strip()
+            # e.g. EntityDormantTruefalse shown as "The company was dormant" reads as true
+            boolean = IX_BOOLEAN_FORMAT_RE.search(m.group(0)[:m.start('value') - m.start()])
+            if boolean:
+                raw_val = boolean.group('value').lower()
             fact_period_end = ctx_dates.get(ctx, {}).get('end', 'unknown')
             
             bucket = text_facts_by_period.setdefault(fact_period_end, {})
@@ -614,6 +648,7 @@ class AccountsDataParser:
                 'company_number': company_number,
                 'period_end': p_end,
                 'period_start': period_start,
+                'currency': currency,
                 'source': 'bulk_xbrl',
                 'raw_data': {} 
             })
@@ -621,6 +656,34 @@ class AccountsDataParser:
 
         return parsed_records
 
+    def _merge_dimensional_facts(self, facts_by_period: dict, dimensional_facts_by_period: dict) -> dict:
+        """
+        Combine the facts of undimensioned and dimensional contexts per period, the undimensioned
+        winning for a tag reported in both, so a total is not replaced by one of its breakdowns.
+        """
+        merged = {}
+        for period in facts_by_period.keys() | dimensional_facts_by_period.keys():
+            merged[period] = {**dimensional_facts_by_period.get(period, {}), **facts_by_period.get(period, {})}
+        return merged
+
+    def _extract_currency(self, text: str) -> str | None:
+        """
+        Find the reporting currency of an XBRL or iXBRL document: the ISO 4217 currency of the
+        unit most of its facts are reported in (documents may also have, say, a per-share unit).
+        """
+        units = {m.group('id'): m.group('currency').upper() for m in XBRL_CURRENCY_UNIT_RE.finditer(text)}
+        if not units:
+            return None
+
+        counts: dict[str, int] = {}
+        for m in XBRL_UNIT_REF_RE.finditer(text):
+            currency = units.get(m.group('unit'))
+            if currency:
+                counts[currency] = counts.get(currency, 0) + 1
+        if counts:
+            return max(counts, key=counts.get)
+        return next(iter(units.values()))
+
     def _localname(self, tag: str) -> str:
         """Extract local name from qualified tag."""
         if '}' in tag:
@@ -11,12 +11,18 @@ CREATE TABLE staging_financials (
     period_start DATE,
     period_end DATE NOT NULL,
 
+    -- Reporting currency (ISO 4217, read as GBP when the accounts do not say) and the GBP value
+    -- of one unit of it on period_end, set by the API from imported FX rates
+    currency CHAR(3),
+    gbp_rate NUMERIC(20, 10),
+
     -- Dynamic columns from tag_dictionary.json
     turnover NUMERIC(12, 2),
     profit_loss NUMERIC(12, 2),
     total_assets NUMERIC(12, 2),
     total_liabilities NUMERIC(12, 2),
     net_assets_liabilities NUMERIC(12, 2),
+    shareholder_funds NUMERIC(12, 2),
     
     distribution_costs NUMERIC(12, 2),
     administrative_expenses NUMERIC(12, 2),
@@ -25,6 +31,7 @@ CREATE TABLE staging_financials (
     gross_profit_loss NUMERIC(12, 2),
     
     fixed_assets NUMERIC(12, 2),
+    intangible_assets NUMERIC(12, 2),
     current_assets NUMERIC(12, 2),
     creditors NUMERIC(12, 2),
     net_current_assets_liabilities NUMERIC(12, 2),
@@ -70,6 +77,7 @@ CREATE TABLE staging_financials (
     entity_current_legal_or_registered_name VARCHAR(255),
     name_entity_officer VARCHAR(255),
     entity_trading_status VARCHAR(255),
+    entity_dormant VARCHAR(255), -- "true" or "false" as declared, e.g. by EntityDormantTruefalse
     cash_receipts_from_disposal_non_controlling_interests VARCHAR(255),
     
     administration_support_average_number_employees INTEGER,
@@ -60,13 +60,16 @@ class AccountsLoader(BaseLoader):
                     company_number VARCHAR(8) NOT NULL,
                     period_start DATE,
                     period_end DATE NOT NULL,
+                    currency CHAR(3),
                     turnover NUMERIC(15, 2),
                     profit_loss NUMERIC(15, 2),
                     total_assets NUMERIC(15, 2),
                     total_liabilities NUMERIC(15, 2),
                     net_assets_liabilities NUMERIC(15, 2),
+                    shareholder_funds NUMERIC(15, 2),
                     distribution_costs NUMERIC(15, 2), administrative_expenses NUMERIC(15, 2), other_operating_income NUMERIC(15, 2),
-                    cost_sales NUMERIC(15, 2), gross_profit_loss NUMERIC(15, 2), fixed_assets NUMERIC(15, 2), current_assets NUMERIC(15, 2),
+                    cost_sales NUMERIC(15, 2), gross_profit_loss NUMERIC(15, 2), fixed_assets NUMERIC(15, 2), intangible_assets NUMERIC(15, 2),
+                    current_assets NUMERIC(15, 2),
                     creditors NUMERIC(15, 2), net_current_assets_liabilities NUMERIC(15, 2), total_assets_less_current_liabilities NUMERIC(15, 2),
                     staff_costs_employee_benefits_expense NUMERIC(15, 2), wages_salaries NUMERIC(15, 2),
                     operating_profit_loss NUMERIC(15, 2), net_finance_income_costs NUMERIC(15, 2),
@@ -88,7 +91,7 @@ class AccountsLoader(BaseLoader):
                     production_software_name VARCHAR(255), production_software_version VARCHAR(100),
                     description_body_authorising_financial_statements TEXT, average_number_employees_during_period INTEGER,
                     report_title VARCHAR(255), entity_current_legal_or_registered_name VARCHAR(255), name_entity_officer VARCHAR(255),
-                    entity_trading_status VARCHAR(255),
+                    entity_trading_status VARCHAR(255), entity_dormant VARCHAR(255),
                     cash_receipts_from_disposal_non_controlling_interests VARCHAR(255), administration_support_average_number_employees INTEGER, production_average_number_employees INTEGER,
                     sales_marketing_distribution_average_number_employees INTEGER,
                     other_departments_average_number_employees INTEGER,
@@ -102,10 +105,10 @@ class AccountsLoader(BaseLoader):
             ''')
 
             columns = [
-                'company_number', 'period_start', 'period_end',
-                'turnover', 'profit_loss', 'total_assets', 'total_liabilities', 'net_assets_liabilities',
+                'company_number', 'period_start', 'period_end', 'currency',
+                'turnover', 'profit_loss', 'total_assets', 'total_liabilities', 'net_assets_liabilities', 'shareholder_funds',
                 'distribution_costs', 'administrative_expenses', 'other_operating_income', 'cost_sales', 'gross_profit_loss',
-                'fixed_assets', 'current_assets', 'creditors', 'net_current_assets_liabilities', 'total_assets_less_current_liabilities',
+                'fixed_assets', 'intangible_assets', 'current_assets', 'creditors', 'net_current_assets_liabilities', 'total_assets_less_current_liabilities',
                 'staff_costs_employee_benefits_expense', 'wages_salaries',
                 'operating_profit_loss', 'net_finance_income_costs',
                 'profit_loss_on_ordinary_activities_before_tax',
@@ -122,7 +125,7 @@ class AccountsLoader(BaseLoader):
                 'director_remuneration', 'production_software_name', 'production_software_version',
                 'description_body_authorising_financial_statements',
                 'average_number_employees_during_period', 'report_title', 'entity_current_legal_or_registered_name',
-                'name_entity_officer', 'entity_trading_status',
+                'name_entity_officer', 'entity_trading_status', 'entity_dormant',
                 'cash_receipts_from_disposal_non_controlling_interests', 'administration_support_average_number_employees',
                 'production_average_number_employees',
                 'sales_marketing_distribution_average_number_employees',
@@ -154,8 +157,9 @@ class AccountsLoader(BaseLoader):
             ]
             for col in integer_cols:
                 if col in df.columns:
-                     # Convert to numeric first to handle strings safely, then to nullable Int64
-                     df[col] = pd.to_numeric(df[col], errors='coerce').astype('Int64')
+                     # Convert to numeric first to handle strings safely, then to nullable Int64.
+                     # Averages are rounded, as a fractional one (e.g. 2.5) cannot be cast.
+                     df[col] = pd.to_numeric(df[col], errors='coerce').round().astype('Int64')
 
             buffer = StringIO()
             df[export_cols].to_csv(buffer, index=False, header=False, na_rep='\\N')
@@ -42,7 +42,7 @@ export default function SearchPage() {
                 industry: industry || undefined,
                 location: location || undefined,
                 revenue: revenue || undefined,
-                employees: employees || undefined,
+                employee_count: employees || undefined,
                 profitability: profitability || undefined,
                 companySize: companySize || undefined,
                 companyStatus: companyStatus || 'active',
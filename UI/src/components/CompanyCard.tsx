@@ -7,7 +7,7 @@ interface CompanyCardProps {
     industry: string;
     location: string;
     revenue: string;
-    employees: number;
+    employees: string;
     description: string;
     lastUpdated: string;
     status: 'Active' | 'Dissolved' | 'Liquidation';
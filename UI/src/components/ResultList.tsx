@@ -49,12 +49,12 @@ const ResultList: React.FC<ResultListProps> = ({
 
             {companies.map((company) => (
                 <CompanyCard
-                    key={company.id}
+                    key={company.company_number}
                     name={company.company_name}
                     industry={getString(company.industry_category, 'N/A')}
                     location={`${getString(company.locality)}, ${getString(company.region)}`}
                     revenue={formatCurrency(company.turnover)}
-                    employees={company.active_officers_count}
+                    employees={company.employee_count?.Valid ? company.employee_count.Int64.toLocaleString() : 'N/A'}
                     description={`Company Number: ${company.company_number} | Status: ${company.company_status}`}
                     lastUpdated={company.latest_accounts_date ? new Date(company.latest_accounts_date).toLocaleDateString() : 'N/A'}
                     status={company.company_status as 'Active' | 'Dissolved'}
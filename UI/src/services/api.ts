@@ -5,7 +5,9 @@ export interface CompanySearchFilters {
     industry?: string;
     location?: string;
     revenue?: string;
-    employees?: string;
+    employees?: string; // Deprecated: active officers, as for officer_count
+    employee_count?: string;
+    officer_count?: string;
     profitability?: string;
     companySize?: string;
     companyStatus?: string;
@@ -16,7 +18,6 @@ export interface CompanySearchFilters {
 }
 
 export interface Company {
-    id: number;
     company_number: string;
     company_name: string;
     company_status: string;
@@ -33,6 +34,7 @@ export interface Company {
     profit_margin: { Float64: number; Valid: boolean } | null;
     latest_accounts_date: string | null;
     active_officers_count: number;
+    employee_count: { Int64: number; Valid: boolean } | null;
 }
 
 export interface SearchResponse {
@@ -79,8 +81,8 @@ export async function countCompanies(filters: CompanySearchFilters): Promise<Cou
     return response.json();
 }
 
-export async function getCompany(id: number): Promise<Company> {
-    const response = await fetch(`${API_BASE_URL}/companies/${id}`, {
+export async function getCompany(companyNumber: string): Promise<Company> {
+    const response = await fetch(`${API_BASE_URL}/companies/${encodeURIComponent(companyNumber)}`, {
         method: 'GET',
         headers: {
             'Content-Type': 'application/json',